@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -9,7 +10,10 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 
 	"github.com/Kilat-Pet-Delivery/lib-common/auth"
 	"github.com/Kilat-Pet-Delivery/lib-common/database"
@@ -17,12 +21,29 @@ import (
 	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
 	"github.com/Kilat-Pet-Delivery/lib-common/logger"
 	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
 	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/certify"
 	"github.com/Kilat-Pet-Delivery/service-tracking/internal/config"
+	telemetryDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/telemetry"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/enrichment"
 	"github.com/Kilat-Pet-Delivery/service-tracking/internal/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/grpcserver"
 	"github.com/Kilat-Pet-Delivery/service-tracking/internal/handler"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/logcontrol"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/objectstore"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/origin"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/overload"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/probe"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ratelimit"
 	"github.com/Kilat-Pet-Delivery/service-tracking/internal/repository"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/routing"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/schema"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/slo"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/widget"
 	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+	"github.com/Kilat-Pet-Delivery/service-tracking/migrations"
 )
 
 func main() {
@@ -37,9 +58,15 @@ func main() {
 	if err != nil {
 		panic("failed to initialize logger: " + err.Error())
 	}
+	logControl := logcontrol.NewController(cfg.LogLevel)
+	log = logControl.Wrap(log)
 	defer func() { _ = log.Sync() }()
 
-	// Connect to database.
+	// In standalone mode the service runs as a single binary for local development:
+	// Kafka is replaced by an in-process event bus, Postgres by in-memory repositories,
+	// and requests without a token act as a dev identity.
+	standalone := cfg.Standalone.Enabled
+
 	dbConfig := database.PostgresConfig{
 		Host:     cfg.DBConfig.Host,
 		Port:     cfg.DBConfig.Port,
@@ -48,22 +75,44 @@ func main() {
 		DBName:   cfg.DBConfig.DBName,
 		SSLMode:  cfg.DBConfig.SSLMode,
 	}
-	db, err := database.Connect(dbConfig, log)
+	schemaVersion, err := migrations.Version()
 	if err != nil {
-		log.Fatal("failed to connect to database", zap.Error(err))
+		log.Fatal("failed to read embedded migrations", zap.Error(err))
 	}
-
-	// Run database migrations.
+	schemaMode := schema.ModeMigrations
 	if cfg.AppEnv == "development" {
-		if err := db.AutoMigrate(&repository.TripTrackModel{}, &repository.WaypointModel{}, &repository.ChatMessageModel{}, &repository.SharedTripModel{}); err != nil {
-			log.Fatal("failed to auto-migrate database", zap.Error(err))
-		}
-		log.Info("database migration completed (dev auto-migrate)")
+		schemaMode = schema.ModeAutoMigrate
+	}
+
+	var db *gorm.DB
+	var schemaChecker *schema.Checker
+	if standalone {
+		log.Warn("running in standalone mode: data is kept in memory, events stay in process and requests without a token are trusted")
 	} else {
-		dbURL := dbConfig.DatabaseURL()
-		if err := database.RunMigrations(dbURL, "migrations", log); err != nil {
-			log.Fatal("failed to run migrations", zap.Error(err))
+		// Connect to database.
+		db, err = database.Connect(dbConfig, log)
+		if err != nil {
+			log.Fatal("failed to connect to database", zap.Error(err))
+		}
+
+		// Run database migrations, then refuse to start unless the database is at exactly
+		// the schema version this build was built against.
+		schemaChecker = schema.NewChecker(db, schemaVersion, schemaMode)
+		if cfg.AppEnv == "development" {
+			if err := db.AutoMigrate(&repository.TripTrackModel{}, &repository.WaypointModel{}, &repository.WaypointChunkModel{}, &repository.ChatMessageModel{}, &repository.SharedTripModel{}, &repository.GeofenceModel{}, &repository.BookingParticipantModel{}, &repository.TemperatureThresholdModel{}, &repository.TrackTelemetryModel{}, &repository.ProcessedEventModel{}, &repository.InboxEntryModel{}, &repository.TrackingAnomalyModel{}, &repository.TrackAlertModel{}, &repository.TrackEventModel{}, &repository.ErasureRequestModel{}, &repository.SupportSessionModel{}, &repository.SupportAuditEventModel{}, &repository.TripCertificateModel{}, &repository.RunnerLocationDropModel{}, &repository.ETASubscriptionModel{}, &repository.WebhookModel{}, &repository.WebhookDeliveryModel{}); err != nil {
+				log.Fatal("failed to auto-migrate database", zap.Error(err))
+			}
+			log.Info("database migration completed (dev auto-migrate)")
+		} else {
+			dbURL := dbConfig.DatabaseURL()
+			if err := database.RunMigrations(dbURL, "migrations", log); err != nil {
+				log.Fatal("failed to run migrations", zap.Error(err))
+			}
+		}
+		if err := schemaChecker.Verify(context.Background()); err != nil {
+			log.Fatal("database schema check failed", zap.Error(err))
 		}
+		log.Info("database schema verified", zap.Uint("version", schemaVersion), zap.String("mode", schemaMode))
 	}
 
 	// Initialize JWT manager.
@@ -77,57 +126,463 @@ func main() {
 	}
 	jwtManager := auth.NewJWTManager(cfg.JWTConfig.Secret, accessExpiry, refreshExpiry)
 
-	// Initialize Kafka producer.
-	producer := kafka.NewProducer(cfg.KafkaConfig.Brokers, log)
-	defer func() { _ = producer.Close() }()
+	// Initialize the event publisher: the Kafka producer, or the in-process bus in
+	// standalone mode.
+	var publisher application.EventPublisher
+	var localBus *events.LocalBus
+	if standalone {
+		localBus = events.NewLocalBus(log)
+		publisher = localBus
+	} else {
+		producer := kafka.NewProducer(cfg.KafkaConfig.Brokers, log)
+		defer func() { _ = producer.Close() }()
+		publisher = producer
+	}
 
 	// Initialize WebSocket hub.
-	wsHub := ws.NewHub(log)
-	go wsHub.Run()
+	wsHub := ws.NewHub(cfg.Autoscaling.WSHubShards, log)
+	wsHub.UseSlowClientPolicy(ws.SlowClientPolicy{
+		CoalesceLocations: cfg.WebSocket.CoalesceLocations,
+		LaggingGrace:      cfg.WebSocket.LaggingGrace,
+		MaxDroppedFrames:  cfg.WebSocket.MaxDroppedFrames,
+	})
+	wsHub.UseConnectionLimits(ws.ConnectionLimits{
+		MaxConnections: cfg.WebSocket.MaxConnections,
+		MaxPerRoom:     cfg.WebSocket.MaxConnectionsPerRoom,
+		MaxPerUser:     cfg.WebSocket.MaxConnectionsPerUser,
+	})
+	wsHub.UseResume(ws.ResumeConfig{
+		BufferSize: cfg.WebSocket.ResumeBuffer,
+		Retention:  cfg.WebSocket.ResumeRetention,
+	})
+
+	// Initialize overload controller.
+	overloadCtl := overload.NewController(overload.Config{
+		QueueDepthThreshold: cfg.OverloadConfig.QueueDepthThreshold,
+		DBLatencyThreshold:  cfg.OverloadConfig.DBLatencyThreshold,
+		RetryAfter:          cfg.OverloadConfig.RetryAfter,
+	}, wsHub.QueueDepth, log)
+
+	// Initialize repository. Waypoint writes are buffered into multi-row inserts
+	// unless batching is disabled.
+	var trackingRepo trackingDomain.TripTrackRepository
+	var waypointBuffer *repository.BufferedTripTrackRepository
+	if standalone {
+		trackingRepo = repository.NewMemoryTripTrackRepository()
+	} else {
+		gormTrackingRepo := repository.NewGORMTripTrackRepository(db, log)
+		trackingRepo = gormTrackingRepo
+		if cfg.WaypointBatch.Size > 1 {
+			waypointBuffer = repository.NewBufferedTripTrackRepository(gormTrackingRepo, repository.WaypointBatchConfig{
+				Size:          cfg.WaypointBatch.Size,
+				FlushInterval: cfg.WaypointBatch.FlushInterval,
+			})
+			waypointBuffer.Start()
+			trackingRepo = waypointBuffer
+		}
+	}
+
+	// Migrate trip track storage to a new database when a migration mode is set.
+	// Writes are mirrored to the other backend and reads can be shadowed and compared.
+	var metricsExporters []http.Handler
+	var nextWaypointBuffer *repository.BufferedTripTrackRepository
+	migrationMode, err := repository.ParseStorageMigrationMode(cfg.StorageMigration.Mode)
+	if err != nil {
+		log.Fatal("invalid STORAGE_MIGRATION_MODE", zap.Error(err))
+	}
+	if migrationMode != repository.MigrationOff && standalone {
+		log.Fatal("storage migration is not available in standalone mode")
+	}
+	if migrationMode != repository.MigrationOff {
+		next := cfg.StorageMigration.DB
+		if next.Host == dbConfig.Host && next.Port == dbConfig.Port && next.DBName == dbConfig.DBName {
+			log.Fatal("storage migration database must differ from the current database; set STORAGE_MIGRATION_DB_*")
+		}
+		nextDBConfig := database.PostgresConfig{
+			Host:     next.Host,
+			Port:     next.Port,
+			User:     next.User,
+			Password: next.Password,
+			DBName:   next.DBName,
+			SSLMode:  next.SSLMode,
+		}
+		nextDB, err := database.Connect(nextDBConfig, log)
+		if err != nil {
+			log.Fatal("failed to connect to storage migration database", zap.Error(err))
+		}
+		if cfg.AppEnv == "development" {
+			if err := nextDB.AutoMigrate(&repository.TripTrackModel{}, &repository.WaypointModel{}, &repository.WaypointChunkModel{}); err != nil {
+				log.Fatal("failed to auto-migrate storage migration database", zap.Error(err))
+			}
+		} else if err := database.RunMigrations(nextDBConfig.DatabaseURL(), "migrations", log); err != nil {
+			log.Fatal("failed to run storage migration database migrations", zap.Error(err))
+		}
+		if err := schema.NewChecker(nextDB, schemaVersion, schemaMode).Verify(context.Background()); err != nil {
+			log.Fatal("storage migration database schema check failed", zap.Error(err))
+		}
 
-	// Initialize repository.
-	trackingRepo := repository.NewGORMTripTrackRepository(db, log)
+		gormNextRepo := repository.NewGORMTripTrackRepository(nextDB, log)
+		var nextRepo trackingDomain.TripTrackRepository = gormNextRepo
+		if cfg.WaypointBatch.Size > 1 {
+			nextWaypointBuffer = repository.NewBufferedTripTrackRepository(gormNextRepo, repository.WaypointBatchConfig{
+				Size:          cfg.WaypointBatch.Size,
+				FlushInterval: cfg.WaypointBatch.FlushInterval,
+			})
+			nextWaypointBuffer.Start()
+			nextRepo = nextWaypointBuffer
+		}
+
+		dualWriteRepo := repository.NewDualWriteTripTrackRepository(trackingRepo, nextRepo, migrationMode, log)
+		trackingRepo = dualWriteRepo
+		metricsExporters = append(metricsExporters, dualWriteRepo)
+		log.Info("storage migration enabled", zap.String("mode", string(migrationMode)))
+	}
 
 	// Initialize application service.
-	trackingService := application.NewTrackingService(trackingRepo, wsHub, producer, log)
+	trackingService := application.NewTrackingService(trackingRepo, wsHub, publisher, overloadCtl, application.TrackingConfig{
+		ETAUpdateThreshold:  cfg.ETAUpdateThreshold,
+		LocationPingTimeout: cfg.LocationPingTimeout,
+		MaxWaypoints:        cfg.MaxWaypointsPerTrack,
+	}, log)
+
+	// Send new WebSocket subscribers a snapshot of the trip's current state.
+	wsHub.SetSnapshotFunc(trackingService.Snapshot)
+
+	// Limit each user's location batches and chat messages.
+	limiter := ratelimit.NewLimiter(map[string]ratelimit.Limit{
+		ratelimit.ScopeLocations: {Rate: cfg.RateLimit.LocationsPerSecond, Burst: cfg.RateLimit.LocationsBurst},
+		ratelimit.ScopeChat:      {Rate: cfg.RateLimit.ChatPerSecond, Burst: cfg.RateLimit.ChatBurst},
+	}, log)
+
+	// Cache latest positions in Redis, relay room frames and share rate limits between
+	// instances, when configured.
+	if cfg.Redis.Addr != "" {
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		defer func() { _ = redisClient.Close() }()
+
+		if err := redisClient.Ping(context.Background()).Err(); err != nil {
+			log.Warn("redis unavailable, latest positions will be read from the database", zap.Error(err))
+		} else {
+			trackingService.UsePositionStore(repository.NewRedisLatestPositionStore(redisClient, cfg.Redis.PositionTTL))
+			if cfg.Redis.HubRelay {
+				wsHub.UseRelay(ws.NewRedisRelay(redisClient, cfg.Redis.HubChannel))
+			}
+			limiter.UseStore(ratelimit.NewRedisStore(redisClient))
+		}
+	}
+	go wsHub.Run()
 
-	// Initialize Kafka consumers.
-	groupPrefix := cfg.KafkaConfig.GroupPrefix
-	if groupPrefix == "" {
-		groupPrefix = "tracking"
+	// Initialize the remaining repositories.
+	var repos repositories
+	if standalone {
+		repos = newMemoryRepositories()
+	} else {
+		repos = newGormRepositories(db)
 	}
 
-	bookingConsumer := events.NewBookingEventConsumer(
-		cfg.KafkaConfig.Brokers,
-		groupPrefix+"-booking-consumer",
-		trackingService,
-		log,
-	)
-	defer func() { _ = bookingConsumer.Close() }()
+	// Initialize geofence service and register it for location updates.
+	geofenceRepo := repos.geofences
+	geofenceService := application.NewGeofenceService(geofenceRepo, wsHub, publisher, log)
+	trackingService.AddLocationObserver(geofenceService)
+	trackingService.UseGeofences(geofenceRepo)
+
+	// Reject null island fixes and fixes outside the trip's deployment region.
+	regionBounds := make(map[string]trackingDomain.BoundingBox, len(cfg.Coordinates.RegionBounds))
+	for region, bounds := range cfg.Coordinates.RegionBounds {
+		box, err := trackingDomain.ParseBoundingBox(bounds)
+		if err != nil {
+			log.Fatal("invalid COORDINATE_REGION_BOUNDS", zap.String("region", region), zap.Error(err))
+		}
+		regionBounds[region] = box
+	}
+	var defaultBounds *trackingDomain.BoundingBox
+	if cfg.Coordinates.DefaultBounds != "" {
+		box, err := trackingDomain.ParseBoundingBox(cfg.Coordinates.DefaultBounds)
+		if err != nil {
+			log.Fatal("invalid COORDINATE_DEFAULT_BOUNDS", zap.Error(err))
+		}
+		defaultBounds = &box
+	}
+	coordinateValidator := application.NewCoordinateValidator(regionBounds, defaultBounds)
+	coordinateValidator.UseMaxAccuracy(cfg.Coordinates.MaxAccuracyMeters)
+	trackingService.UseCoordinateValidator(coordinateValidator)
+	metricsExporters = append(metricsExporters, coordinateValidator)
+
+	// Capture start/end trip weather from the enrichment service when configured.
+	if cfg.Enrichment.URL != "" {
+		trackingService.UseWeatherProvider(enrichment.NewClient(cfg.Enrichment.URL, cfg.Enrichment.Timeout))
+	}
+
+	// Smooth GPS jitter out of live locations before broadcast and distance accumulation.
+	if cfg.GPSFilter.Enabled {
+		trackingService.UseGPSFilter(cfg.GPSFilter.AccuracyMeters, cfg.GPSFilter.ProcessNoise)
+	}
+	trackingService.UseLowBatteryWarning(cfg.Device.LowBatteryPct)
+	trackingService.UseStallDetection(application.StallConfig{
+		After:         cfg.Stall.After,
+		RadiusMeters:  cfg.Stall.RadiusMeters,
+		CheckInterval: cfg.Stall.CheckInterval,
+	})
+
+	// Estimate arrival along road routes, and snap exported routes to roads on request,
+	// when a routing provider is configured.
+	var routingProvider trackingDomain.RoutingProvider
+	var mapMatcher trackingDomain.MapMatcher
+	switch cfg.Routing.Provider {
+	case "":
+	case "osrm":
+		if cfg.Routing.URL == "" {
+			log.Fatal("ROUTING_URL is required for the osrm routing provider")
+		}
+		osrm := routing.NewOSRMClient(cfg.Routing.URL, cfg.Routing.Profile, cfg.Routing.Timeout)
+		routingProvider, mapMatcher = osrm, osrm
+	case "google":
+		if cfg.Routing.APIKey == "" {
+			log.Fatal("ROUTING_API_KEY is required for the google routing provider")
+		}
+		routingProvider = routing.NewGoogleClient(cfg.Routing.APIKey, cfg.Routing.Profile, cfg.Routing.Timeout)
+		// Snapping a whole route takes one request per 100 waypoints, so allow it longer.
+		mapMatcher = routing.NewGoogleRoadsClient(cfg.Routing.APIKey, 5*cfg.Routing.Timeout)
+	default:
+		log.Fatal("unknown routing provider", zap.String("provider", cfg.Routing.Provider))
+	}
+	if routingProvider != nil {
+		routingClient := routing.NewClient(routingProvider, routing.Config{
+			CacheTTL:         cfg.Routing.CacheTTL,
+			FailureThreshold: cfg.Routing.FailureThreshold,
+			Cooldown:         cfg.Routing.Cooldown,
+		}, log)
+		trackingService.UseRoutingProvider(routingClient)
+		metricsExporters = append(metricsExporters, routingClient)
+	}
+	if !cfg.Routing.MapMatching {
+		mapMatcher = nil
+	}
+	if mapMatcher != nil {
+		trackingService.UseMapMatcher(mapMatcher)
+	}
 
-	runnerConsumer := events.NewRunnerEventConsumer(
-		cfg.KafkaConfig.Brokers,
-		groupPrefix+"-runner-consumer",
-		trackingService,
+	// Sign completed trip summaries when a signing key is configured.
+	var certificationService *application.CertificationService
+	if cfg.Certificate.SigningKey != "" {
+		signer, err := certify.NewSigner(cfg.Certificate.KeyID, cfg.Certificate.SigningKey, cfg.Certificate.RetiredKeys)
+		if err != nil {
+			log.Fatal("invalid certificate keys", zap.Error(err))
+		}
+		certificationService = application.NewCertificationService(repos.certificates, trackingRepo, signer, log)
+		trackingService.UseCertification(certificationService)
+	}
+
+	// Only store runner locations while a trip is in progress; count the rest.
+	privacyService := application.NewLocationPrivacyService(repos.privacy, trackingRepo, log)
+	trackingService.UseLocationPrivacy(privacyService)
+	metricsExporters = append(metricsExporters, privacyService)
+
+	// Erase users' trip data on request once their trips are past retention.
+	erasureService := application.NewErasureService(repos.erasures, trackingRepo, repos.participants, repos.chat, repos.shares, publisher, application.ErasureConfig{
+		Retention:     cfg.Erasure.Retention,
+		CheckInterval: cfg.Erasure.CheckInterval,
+	}, log)
+
+	// Notify other services subscribed to a booking's ETA of significant changes.
+	etaSubscriptionService := application.NewETASubscriptionService(repos.etaSubscriptions, trackingRepo, publisher, log)
+	etaSubscriptionService.UseCallbackHosts(cfg.ETACallbackHosts)
+	trackingService.UseETASubscriptions(etaSubscriptionService)
+
+	// Deliver trip milestones to partners' webhooks, retrying failed deliveries.
+	webhookService := application.NewWebhookService(repos.webhooks, trackingService, repos.participants, application.WebhookConfig{
+		Timeout:       cfg.Webhooks.Timeout,
+		MaxAttempts:   cfg.Webhooks.MaxAttempts,
+		Backoff:       cfg.Webhooks.Backoff,
+		CheckInterval: cfg.Webhooks.CheckInterval,
+	}, log)
+	trackingService.UseWebhooks(webhookService)
+	geofenceService.UseWebhooks(webhookService)
+
+	// Track runner driving time across trips and publish break compliance events.
+	drivingTimeService := application.NewDrivingTimeService(trackingRepo, publisher, application.DrivingLimits{
+		MaxContinuous: cfg.DrivingLimits.MaxContinuous,
+		MaxDaily:      cfg.DrivingLimits.MaxDaily,
+		MinBreak:      cfg.DrivingLimits.MinBreak,
+		CheckInterval: cfg.DrivingLimits.CheckInterval,
+	}, log)
+	trackingService.AddLocationObserver(drivingTimeService)
+
+	// Record each trip's lifecycle transitions for support.
+	trackHistory := application.NewTrackHistory(repos.history, trackingRepo, log)
+	trackingService.UseTrackHistory(trackHistory)
+
+	// Detect teleports, prolonged stops and route deviations on location updates.
+	anomalyService := application.NewAnomalyService(repos.anomalies, trackingRepo, publisher, application.AnomalyConfig{
+		MaxSpeedKmh:        cfg.Anomaly.MaxSpeedKmh,
+		StopDuration:       cfg.Anomaly.StopDuration,
+		StopRadiusMeters:   cfg.Anomaly.StopRadiusMeters,
+		MaxDeviationMeters: cfg.Anomaly.MaxDeviationMeters,
+	}, log)
+	anomalyService.UseTrackHistory(trackHistory)
+	trackingService.AddLocationObserver(anomalyService)
+
+	// Alert the customer when the runner keeps exceeding the safety speed limit.
+	safetyService := application.NewSafetyService(repos.alerts, trackingRepo, wsHub, publisher, application.SafetyConfig{
+		MaxSpeedKmh:          cfg.Safety.MaxSpeedKmh,
+		ConsecutiveWaypoints: cfg.Safety.ConsecutiveWaypoints,
+	}, log)
+	trackingService.AddLocationObserver(safetyService)
+
+	participantRepo := repos.participants
+	trackingService.UseParticipants(participantRepo)
+
+	// Initialize temperature service, which checks carrier telemetry against per-species thresholds.
+	temperatureService := application.NewTemperatureService(
+		repos.temperatures,
+		repos.telemetry,
+		trackingRepo,
+		participantRepo,
+		wsHub,
+		publisher,
+		telemetryDomain.HumidityRange{MinPct: cfg.Telemetry.MinHumidityPct, MaxPct: cfg.Telemetry.MaxHumidityPct},
 		log,
 	)
-	defer func() { _ = runnerConsumer.Close() }()
+	trackingService.UseCrateTelemetry(temperatureService)
+
+	// Initialize Kafka consumers.
+	groupPrefix := cfg.KafkaConfig.GroupPrefix
+	if groupPrefix == "" {
+		groupPrefix = "tracking"
+	}
 
-	// Start consumers in background goroutines.
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go func() {
-		if err := bookingConsumer.Start(ctx); err != nil && ctx.Err() == nil {
-			log.Error("booking event consumer error", zap.Error(err))
-		}
-	}()
+	go overloadCtl.Run(ctx)
 
-	go func() {
-		if err := runnerConsumer.Start(ctx); err != nil && ctx.Err() == nil {
-			log.Error("runner event consumer error", zap.Error(err))
+	groupMigrator := events.NewGroupMigrator(cfg.KafkaConfig.Brokers, log)
+
+	// Export the signals the fleet is autoscaled on: WebSocket connections and broadcast
+	// queue saturation, plus consumer lag below.
+	metricsExporters = append(metricsExporters, wsHub)
+
+	if standalone {
+		// Booking and runner events are published to the local bus through POST /dev/events.
+		consumers := events.NewLocalConsumerSet(localBus, trackingService, log)
+		defer consumers.Close()
+		consumers.Start(ctx)
+
+		userConsumer := events.NewLocalUserEventConsumer(localBus, erasureService, log)
+		defer func() { _ = userConsumer.Close() }()
+		go func() {
+			if err := userConsumer.Start(ctx); err != nil && ctx.Err() == nil {
+				log.Error("user event consumer error", zap.Error(err))
+			}
+		}()
+	} else {
+		// Messages that keep failing are shipped to the dead-letter topic so consumers move on.
+		deadLetter := events.NewDeadLetterPublisher(cfg.KafkaConfig.Brokers, cfg.DeadLetterMaxAttempts, log)
+		defer func() { _ = deadLetter.Close() }()
+
+		// Processed event IDs are recorded so messages redelivered after a rebalance or restart
+		// are not applied twice. During a group migration the in-memory cache also covers the
+		// events consumed by both groups.
+		dedupCacheTTL := cfg.EventDedup.CacheTTL
+		if cfg.GroupMigration.TargetGroupPrefix != "" {
+			dedupCacheTTL = max(dedupCacheTTL, 2*cfg.GroupMigration.Drain)
 		}
-	}()
+		// Locations consumed long after they were recorded are broadcast as historical
+		// while the consumers catch up on a backlog.
+		trackingService.UseCatchUp(cfg.CatchUp.StaleAfter)
+
+		dedup := events.NewDeduplicator(dedupCacheTTL)
+		dedup.UseStore(repository.NewGormProcessedEventRepository(db), cfg.EventDedup.Retention, log)
+
+		consumers := events.NewConsumerSet(cfg.KafkaConfig.Brokers, groupPrefix, cfg.KafkaRegions, cfg.KafkaRunnerWorkers, trackingService, log)
+		consumers.UseDeduplicator(dedup)
+		consumers.UseDeadLetter(deadLetter)
+		defer consumers.Close()
+
+		userConsumer := events.NewUserEventConsumer(cfg.KafkaConfig.Brokers, groupPrefix+"-user-consumer", erasureService, log)
+		userConsumer.UseDeduplicator(dedup)
+		userConsumer.UseDeadLetter(deadLetter)
+		defer func() { _ = userConsumer.Close() }()
+		go func() {
+			if err := userConsumer.Start(ctx); err != nil && ctx.Err() == nil {
+				log.Error("user event consumer error", zap.Error(err))
+			}
+		}()
+
+		go dedup.Run(ctx)
+
+		// Export the lag of the groups that keep consuming as an autoscaling signal.
+		lagConsumers := consumers
+
+		if migration := cfg.GroupMigration; migration.TargetGroupPrefix != "" {
+			// Blue/green group migration: the target groups start at StartAt while the current
+			// groups keep draining for the overlap window; the shared deduplicator suppresses
+			// events consumed by both.
+			targetConsumers := events.NewConsumerSet(cfg.KafkaConfig.Brokers, migration.TargetGroupPrefix, cfg.KafkaRegions, cfg.KafkaRunnerWorkers, trackingService, log)
+			targetConsumers.UseDeduplicator(dedup)
+			targetConsumers.UseDeadLetter(deadLetter)
+			defer targetConsumers.Close()
+
+			if err := targetConsumers.Seed(ctx, groupMigrator, migration.StartAt); err != nil {
+				log.Fatal("failed to seed target consumer groups", zap.Error(err))
+			}
+			targetConsumers.Start(ctx)
+			lagConsumers = targetConsumers
+
+			drainCtx, drainCancel := context.WithTimeout(ctx, migration.Drain)
+			defer drainCancel()
+			consumers.Start(drainCtx)
+
+			go func() {
+				<-drainCtx.Done()
+				consumers.Close()
+				log.Info("consumer group migration drain finished",
+					zap.String("from_prefix", groupPrefix),
+					zap.String("to_prefix", migration.TargetGroupPrefix),
+				)
+			}()
+
+			log.Info("consumer group migration started",
+				zap.String("from_prefix", groupPrefix),
+				zap.String("to_prefix", migration.TargetGroupPrefix),
+				zap.Time("start_at", migration.StartAt),
+				zap.Duration("drain", migration.Drain),
+			)
+		} else {
+			consumers.Start(ctx)
+		}
+
+		lagMonitor := events.NewLagMonitor(groupMigrator, lagConsumers, cfg.Autoscaling.ConsumerLagInterval, log)
+		lagMonitor.UseCatchUp(int64(cfg.CatchUp.LagThreshold), trackingService.SetCatchingUp)
+		go lagMonitor.Run(ctx)
+		metricsExporters = append(metricsExporters, lagMonitor)
+	}
+
+	// Announcements are published to a topic every instance consumes, so each one can
+	// deliver them to its own WebSocket clients.
+	announcementService := application.NewAnnouncementService(trackingRepo, wsHub, publisher, log)
+	hostname, _ := os.Hostname()
+	var announcementConsumer *events.AnnouncementConsumer
+	if standalone {
+		announcementConsumer = events.NewLocalAnnouncementConsumer(localBus, announcementService, log)
+	} else {
+		announcementConsumer = events.NewAnnouncementConsumer(cfg.KafkaConfig.Brokers, groupPrefix+"-announcements-"+hostname, announcementService, log)
+	}
+	defer func() { _ = announcementConsumer.Close() }()
+	go announcementConsumer.Start(ctx)
+
+	// Measure tracking-critical endpoints against their SLOs.
+	sloClasses, err := slo.ParseClasses(cfg.SLO.Classes)
+	if err != nil {
+		log.Fatal("invalid SLO_CLASSES", zap.Error(err))
+	}
+	sloTracker := slo.NewTracker(sloClasses, cfg.SLO.Period, log)
+	handler.ClassifySLORoutes(sloTracker)
+	metricsExporters = append(metricsExporters, sloTracker)
 
 	// Initialize Gin router.
 	router := gin.New()
@@ -137,31 +592,229 @@ func main() {
 		middleware.RecoveryMiddleware(log),
 		middleware.CORSMiddleware(),
 		middleware.SecurityHeadersMiddleware(),
+		sloTracker.Middleware(),
 	)
 
-	// Register health check routes.
-	healthHandler := health.NewHandler(db, "service-tracking")
-	healthHandler.RegisterRoutes(router)
+	// Sign requests without a token as the dev identity and accept injected events.
+	if standalone {
+		devUserID, err := uuid.Parse(cfg.Standalone.DevUserID)
+		if err != nil {
+			log.Fatal("invalid DEV_USER_ID", zap.Error(err))
+		}
+		devHandler := handler.NewDevHandler(jwtManager, localBus, devUserID, auth.UserRole(cfg.Standalone.DevRole), log)
+		router.Use(devHandler.Middleware())
+		devHandler.RegisterRoutes(router)
+	}
+
+	// Register health check routes. Standalone mode has no database to check.
+	if standalone {
+		router.GET("/health", func(c *gin.Context) {
+			response.Success(c, gin.H{"status": "ok", "mode": "standalone"})
+		})
+	} else {
+		healthHandler := health.NewHandler(db, "service-tracking")
+		healthHandler.RegisterRoutes(router)
+	}
+	handler.NewLifecycleHandler(wsHub, cfg.Autoscaling.WSDrainPeriod, log).RegisterRoutes(router)
+
+	// Start the synthetic probe, which runs a fake trip through the pipeline on a schedule.
+	if cfg.Probe.Interval > 0 && !standalone {
+		probeRunnerID, err := uuid.Parse(cfg.Probe.RunnerID)
+		if err != nil {
+			log.Fatal("invalid PROBE_RUNNER_ID", zap.Error(err))
+		}
+		prober := probe.New(trackingService, trackingRepo, wsHub, probe.Config{
+			Interval: cfg.Probe.Interval,
+			Timeout:  cfg.Probe.Timeout,
+			RunnerID: probeRunnerID,
+			Brokers:  cfg.KafkaConfig.Brokers,
+			GroupID:  groupPrefix + "-probe-" + hostname,
+		}, log)
+		defer prober.Close()
+		go prober.Start(ctx)
+		metricsExporters = append(metricsExporters, prober)
+	}
+	if len(metricsExporters) > 0 {
+		router.GET("/metrics", func(c *gin.Context) {
+			for _, exporter := range metricsExporters {
+				exporter.ServeHTTP(c.Writer, c.Request)
+			}
+		})
+	}
+
+	// Publish each runner's digest of the previous day shortly after midnight.
+	digestLocation, err := time.LoadLocation(cfg.RunnerDigest.Timezone)
+	if err != nil {
+		log.Fatal("invalid RUNNER_DIGEST_TIMEZONE", zap.Error(err))
+	}
+	var digestExcluded []uuid.UUID
+	if probeRunnerID, err := uuid.Parse(cfg.Probe.RunnerID); err == nil {
+		digestExcluded = append(digestExcluded, probeRunnerID)
+	}
+	runnerDigestService := application.NewRunnerDigestService(trackingRepo, publisher, application.RunnerDigestConfig{
+		Location:       digestLocation,
+		RunAfter:       cfg.RunnerDigest.RunAfter,
+		ExcludeRunners: digestExcluded,
+	}, log)
+	go runnerDigestService.Run(ctx)
+
+	// Aggregate trips for operations, leaving the probe runner out like the digest.
+	analyticsService := application.NewAnalyticsService(trackingRepo, application.AnalyticsConfig{ExcludeRunners: digestExcluded})
+
+	// Chat messages and alerts are also kept in each recipient's inbox until synced, so
+	// users who were offline can catch up.
+	inboxService := application.NewInboxService(repos.inbox, participantRepo, cfg.Inbox.Retention, log)
+	temperatureService.UseInbox(inboxService)
+	safetyService.UseInbox(inboxService)
+	geofenceService.UseInbox(inboxService)
+	go inboxService.Run(ctx)
+	go privacyService.Run(ctx)
+	go erasureService.Run(ctx)
+	go webhookService.Run(ctx)
+	go etaSubscriptionService.Run(ctx)
+	go trackingService.RunStallDetection(ctx)
+
+	// Stream the aggregated fleet state to live ops dashboards.
+	liveOps := application.NewLiveOpsAggregator(trackingService, trackingRepo, cfg.LiveOps.Interval, log)
+	go liveOps.Run(ctx)
 
 	// Initialize chat service and handler.
-	chatRepo := repository.NewGormChatRepository(db)
-	chatService := application.NewChatService(chatRepo, wsHub, log)
-	chatHandler := handler.NewChatHandler(chatService)
+	chatRepo := repos.chat
+	chatPolicy := application.ChatPolicy{
+		MaxContentLength:     cfg.ChatPolicy.MaxContentLength,
+		MaxAttachments:       cfg.ChatPolicy.MaxAttachments,
+		MaxAttachmentBytes:   int64(cfg.ChatPolicy.MaxAttachmentBytes),
+		AllowedMimeTypes:     cfg.ChatPolicy.AllowedMimeTypes,
+		MaxMessagesPerMinute: cfg.ChatPolicy.MaxMessagesPerMinute,
+	}
+	chatService := application.NewChatService(chatRepo, wsHub, chatPolicy, log)
+	chatService.UseInbox(inboxService)
+	if cfg.ChatPolicy.MaskPII {
+		chatService.AddModerator(application.NewPIIMasker())
+	}
+	if len(cfg.ChatPolicy.ProfanityWords) > 0 {
+		chatService.AddModerator(application.NewProfanityFilter(cfg.ChatPolicy.ProfanityWords, cfg.ChatPolicy.DefaultLocale))
+	}
+	if cfg.Attachments.Bucket != "" {
+		presigner, err := objectstore.NewPresigner(objectstore.Config{
+			Endpoint:        cfg.Attachments.Endpoint,
+			Region:          cfg.Attachments.Region,
+			Bucket:          cfg.Attachments.Bucket,
+			AccessKeyID:     cfg.Attachments.AccessKeyID,
+			SecretAccessKey: cfg.Attachments.SecretAccessKey,
+			PathStyle:       cfg.Attachments.PathStyle,
+		})
+		if err != nil {
+			log.Fatal("invalid attachment storage config", zap.Error(err))
+		}
+		chatService.UseAttachmentStorage(presigner, cfg.Attachments.UploadURLTTL, cfg.Attachments.DownloadURLTTL)
+	}
+	if cfg.Push.Enabled {
+		pushFanout := application.NewPushFanout(participantRepo, wsHub, publisher, cfg.Push.Topic, log)
+		chatService.UsePushFanout(pushFanout)
+		geofenceService.UsePushFanout(pushFanout)
+	}
+	if cfg.ChatPolicy.SystemMessages {
+		timeline := application.NewChatTimeline(chatService, log)
+		trackingService.UseChatTimeline(timeline)
+		geofenceService.UseChatTimeline(timeline)
+	}
+	chatHandler := handler.NewChatHandler(chatService, trackingService, limiter)
+
+	// Describe this deployment's optional features so multi-region apps can adapt.
+	capabilitiesService := application.NewCapabilitiesService(application.CapabilitiesConfig{
+		Regions:            cfg.KafkaRegions,
+		RegionBounds:       regionBounds,
+		DefaultBounds:      defaultBounds,
+		TripWeather:        cfg.Enrichment.URL != "",
+		Certificates:       certificationService != nil,
+		ETAUpdateThreshold: cfg.ETAUpdateThreshold,
+		RoadRouting:        routingProvider != nil,
+		MapMatching:        mapMatcher != nil,
+		Chat:               chatPolicy,
+		AttachmentUploads:  cfg.Attachments.Bucket != "",
+	})
+	capabilitiesHandler := handler.NewCapabilitiesHandler(capabilitiesService)
+
+	supportService := application.NewSupportService(repos.support, trackingService, chatService, cfg.Support.SessionTTL, log)
+
+	// Restrict the origins of WebSockets and the public share and widget endpoints.
+	originPolicy, err := origin.NewPolicy(cfg.AllowedOrigins)
+	if err != nil {
+		log.Fatal("invalid ALLOWED_ORIGINS", zap.Error(err))
+	}
+	if originPolicy.AllowsAny() && cfg.AppEnv == "production" {
+		log.Fatal("ALLOWED_ORIGINS must not be * in production; set it to the sites that embed tracking")
+	}
+
+	supportHandler := handler.NewSupportHandler(supportService, wsHub, jwtManager, originPolicy, cfg.WSQueryTokens, log)
+	inboxHandler := handler.NewInboxHandler(inboxService)
 
 	// Initialize share service and handler.
-	shareRepo := repository.NewGormSharedTripRepository(db)
+	shareRepo := repos.shares
 	shareService := application.NewShareService(shareRepo, trackingRepo, log)
-	shareHandler := handler.NewShareHandler(shareService)
+	trackMergeService := application.NewTrackMergeService(trackingService, chatRepo, shareRepo, log)
+	shareHandler := handler.NewShareHandler(shareService, trackingService, wsHub, originPolicy, log)
+
+	// Initialize widget token signer and handler.
+	widgetSigner := widget.NewSigner(cfg.WidgetConfig.Secret, cfg.WidgetConfig.TokenTTL)
+	widgetHandler := handler.NewWidgetHandler(trackingService, wsHub, widgetSigner, originPolicy, log)
+
+	geofenceHandler := handler.NewGeofenceHandler(geofenceService, trackingService)
+	temperatureHandler := handler.NewTemperatureHandler(temperatureService, trackingService)
+	drivingTimeHandler := handler.NewDrivingTimeHandler(drivingTimeService)
+	runnerDigestHandler := handler.NewRunnerDigestHandler(runnerDigestService)
+	anomalyHandler := handler.NewAnomalyHandler(anomalyService)
+	historyHandler := handler.NewHistoryHandler(trackHistory)
+	erasureHandler := handler.NewErasureHandler(erasureService)
+	analyticsHandler := handler.NewAnalyticsHandler(analyticsService)
+	safetyHandler := handler.NewSafetyHandler(safetyService, trackingService)
+	announcementHandler := handler.NewAnnouncementHandler(announcementService)
 
 	// Register tracking REST API routes.
-	trackingHandler := handler.NewTrackingHandler(trackingService, wsHub, jwtManager, log)
+	adminHandler := handler.NewAdminHandler(groupMigrator, logControl, sloTracker, schemaChecker)
+	adminTrackingHandler := handler.NewAdminTrackingHandler(trackingService, trackMergeService)
+	privacyHandler := handler.NewPrivacyHandler(privacyService)
+	etaSubscriptionHandler := handler.NewETASubscriptionHandler(etaSubscriptionService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	trackingHandler := handler.NewTrackingHandler(trackingService, wsHub, jwtManager, overloadCtl, limiter, originPolicy, cfg.WSQueryTokens, log)
 	apiV1 := router.Group("/api/v1")
 	trackingHandler.RegisterRoutes(apiV1, jwtManager)
+	trackingHandler.RegisterInternalRoutes(apiV1, jwtManager)
+	etaSubscriptionHandler.RegisterInternalRoutes(apiV1, jwtManager)
+	webhookHandler.RegisterRoutes(apiV1, jwtManager)
 	chatHandler.RegisterRoutes(apiV1, jwtManager)
+	inboxHandler.RegisterRoutes(apiV1, jwtManager)
 	shareHandler.RegisterRoutes(apiV1, jwtManager)
+	widgetHandler.RegisterRoutes(apiV1, jwtManager)
+	geofenceHandler.RegisterRoutes(apiV1, jwtManager)
+	temperatureHandler.RegisterRoutes(apiV1, jwtManager)
+	drivingTimeHandler.RegisterRoutes(apiV1, jwtManager)
+	runnerDigestHandler.RegisterRoutes(apiV1, jwtManager)
+	anomalyHandler.RegisterRoutes(apiV1, jwtManager)
+	historyHandler.RegisterRoutes(apiV1, jwtManager)
+	safetyHandler.RegisterRoutes(apiV1, jwtManager)
+	announcementHandler.RegisterRoutes(apiV1, jwtManager)
+	adminHandler.RegisterRoutes(apiV1, jwtManager)
+	adminTrackingHandler.RegisterRoutes(apiV1, jwtManager)
+	supportHandler.RegisterRoutes(apiV1, jwtManager)
+	privacyHandler.RegisterRoutes(apiV1, jwtManager)
+	erasureHandler.RegisterRoutes(apiV1, jwtManager)
+	analyticsHandler.RegisterRoutes(apiV1, jwtManager)
+	capabilitiesHandler.RegisterRoutes(apiV1)
+	if certificationService != nil {
+		handler.NewCertificateHandler(certificationService, trackingService).RegisterRoutes(apiV1, jwtManager)
+	}
 
-	// Register WebSocket route.
+	// Register WebSocket routes.
 	trackingHandler.RegisterWSRoute(router, jwtManager)
+	widgetHandler.RegisterWSRoute(router)
+	shareHandler.RegisterWSRoute(router)
+	supportHandler.RegisterWSRoute(router)
+	handler.NewLiveOpsHandler(liveOps, wsHub, jwtManager, originPolicy, cfg.WSQueryTokens, log).RegisterWSRoute(router)
+
+	// Register the embeddable tracking page.
+	widgetHandler.RegisterEmbedRoute(router)
 
 	// Start HTTP server.
 	srv := &http.Server{
@@ -179,6 +832,20 @@ func main() {
 		}
 	}()
 
+	// Start gRPC server for internal service-to-service reads.
+	grpcSrv := grpcserver.NewGRPCServer(grpcserver.NewServer(trackingService, jwtManager, log))
+	grpcListener, err := net.Listen("tcp", cfg.GRPCPort)
+	if err != nil {
+		log.Fatal("failed to listen for gRPC", zap.String("port", cfg.GRPCPort), zap.Error(err))
+	}
+
+	go func() {
+		log.Info("starting gRPC server", zap.String("port", cfg.GRPCPort))
+		if err := grpcSrv.Serve(grpcListener); err != nil {
+			log.Error("gRPC server error", zap.Error(err))
+		}
+	}()
+
 	// Graceful shutdown.
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -192,9 +859,37 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
 
+	// WebSocket connections are hijacked and not closed by srv.Shutdown. Close any left
+	// after the pre-stop drain with a restart code so clients reconnect elsewhere.
+	wsDrainCtx, wsDrainCancel := context.WithTimeout(shutdownCtx, 2*time.Second)
+	wsHub.Drain(wsDrainCtx, 0)
+	wsDrainCancel()
+
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Error("server forced to shutdown", zap.Error(err))
 	}
 
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcSrv.Stop()
+	}
+
+	if waypointBuffer != nil {
+		if err := waypointBuffer.Close(shutdownCtx); err != nil {
+			log.Error("failed to flush buffered waypoints", zap.Error(err))
+		}
+	}
+	if nextWaypointBuffer != nil {
+		if err := nextWaypointBuffer.Close(shutdownCtx); err != nil {
+			log.Error("failed to flush buffered waypoints to storage migration database", zap.Error(err))
+		}
+	}
+
 	log.Info("service-tracking stopped")
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"gorm.io/gorm"
+
+	alertDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/alert"
+	anomalyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/anomaly"
+	certificateDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/certificate"
+	chatDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/chat"
+	erasureDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/erasure"
+	etaDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/eta"
+	geofenceDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/geofence"
+	historyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/history"
+	inboxDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/inbox"
+	participantDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/participant"
+	privacyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/privacy"
+	shareDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/share"
+	supportDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/support"
+	telemetryDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/telemetry"
+	temperatureDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/temperature"
+	webhookDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/webhook"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/repository"
+)
+
+// repositories are the stores of everything but trip tracks, which are set up
+// separately for waypoint batching and storage migration.
+type repositories struct {
+	geofences        geofenceDomain.GeofenceRepository
+	certificates     certificateDomain.Repository
+	privacy          privacyDomain.Repository
+	etaSubscriptions etaDomain.Repository
+	webhooks         webhookDomain.Repository
+	anomalies        anomalyDomain.Repository
+	alerts           alertDomain.Repository
+	history          historyDomain.Repository
+	erasures         erasureDomain.Repository
+	participants     participantDomain.Repository
+	temperatures     temperatureDomain.Repository
+	telemetry        telemetryDomain.Repository
+	inbox            inboxDomain.Repository
+	chat             chatDomain.ChatRepository
+	support          supportDomain.Repository
+	shares           shareDomain.SharedTripRepository
+}
+
+// newGormRepositories creates the repositories backed by the database.
+func newGormRepositories(db *gorm.DB) repositories {
+	return repositories{
+		geofences:        repository.NewGormGeofenceRepository(db),
+		certificates:     repository.NewGormCertificateRepository(db),
+		privacy:          repository.NewGormPrivacyRepository(db),
+		etaSubscriptions: repository.NewGormETASubscriptionRepository(db),
+		webhooks:         repository.NewGormWebhookRepository(db),
+		anomalies:        repository.NewGormAnomalyRepository(db),
+		alerts:           repository.NewGormTrackAlertRepository(db),
+		history:          repository.NewGormHistoryRepository(db),
+		erasures:         repository.NewGormErasureRepository(db),
+		participants:     repository.NewGormParticipantRepository(db),
+		temperatures:     repository.NewGormTemperatureThresholdRepository(db),
+		telemetry:        repository.NewGormTelemetryRepository(db),
+		inbox:            repository.NewGormInboxRepository(db),
+		chat:             repository.NewGormChatRepository(db),
+		support:          repository.NewGormSupportRepository(db),
+		shares:           repository.NewGormSharedTripRepository(db),
+	}
+}
+
+// newMemoryRepositories creates in-memory repositories for standalone mode.
+func newMemoryRepositories() repositories {
+	return repositories{
+		geofences:        repository.NewMemoryGeofenceRepository(),
+		certificates:     repository.NewMemoryCertificateRepository(),
+		privacy:          repository.NewMemoryPrivacyRepository(),
+		etaSubscriptions: repository.NewMemoryETASubscriptionRepository(),
+		webhooks:         repository.NewMemoryWebhookRepository(),
+		anomalies:        repository.NewMemoryAnomalyRepository(),
+		alerts:           repository.NewMemoryTrackAlertRepository(),
+		history:          repository.NewMemoryHistoryRepository(),
+		erasures:         repository.NewMemoryErasureRepository(),
+		participants:     repository.NewMemoryParticipantRepository(),
+		temperatures:     repository.NewMemoryTemperatureThresholdRepository(),
+		telemetry:        repository.NewMemoryTelemetryRepository(),
+		inbox:            repository.NewMemoryInboxRepository(),
+		chat:             repository.NewMemoryChatRepository(),
+		support:          repository.NewMemorySupportRepository(),
+		shares:           repository.NewMemorySharedTripRepository(),
+	}
+}
@@ -8,8 +8,11 @@ require (
 	github.com/gin-gonic/gin v1.11.0
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/websocket v1.5.3
+	github.com/redis/go-redis/v9 v9.7.0
 	github.com/segmentio/kafka-go v0.4.50
 	go.uber.org/zap v1.27.1
+	google.golang.org/grpc v1.67.1
+	google.golang.org/protobuf v1.36.9
 	gorm.io/gorm v1.31.1
 )
 
@@ -67,7 +70,6 @@ require (
 	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
 	golang.org/x/tools v0.38.0 // indirect
-	google.golang.org/protobuf v1.36.9 // indirect
 	gorm.io/driver/postgres v1.6.0 // indirect
 )
 
@@ -0,0 +1,170 @@
+// Package apperror defines the service-wide error catalog. Every error returned to
+// clients carries a stable, machine-readable Code so apps can branch on it instead of
+// matching message strings.
+package apperror
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Code is a stable, machine-readable error identifier. Codes are part of the public API:
+// add new ones freely, but never rename or repurpose an existing code.
+type Code string
+
+// Request errors.
+const (
+	CodeInvalidRequest Code = "invalid_request"
+	CodeInvalidID      Code = "invalid_id"
+	CodeUnauthorized   Code = "unauthorized"
+	CodeTokenExpired   Code = "token_expired"
+	CodeForbidden      Code = "forbidden"
+	CodeNotParticipant Code = "not_participant"
+	CodeValidation     Code = "validation_failed"
+)
+
+// Tracking errors.
+const (
+	CodeTrackingNotFound       Code = "tracking_not_found"
+	CodeTrackingNotActive      Code = "tracking_not_active"
+	CodeDestinationNotSet      Code = "destination_not_set"
+	CodePositionUnknown        Code = "position_unknown"
+	CodeGeofenceNotFound       Code = "geofence_not_found"
+	CodeCoordinateRejected     Code = "coordinate_rejected"
+	CodeMapMatchingUnavailable Code = "map_matching_unavailable"
+)
+
+// ETA subscription errors.
+const (
+	CodeSubscriptionNotFound Code = "subscription_not_found"
+)
+
+// Share link errors.
+const (
+	CodeShareLinkNotFound Code = "share_link_not_found"
+	CodeShareLinkExpired  Code = "share_link_expired"
+	CodeShareLinkRevoked  Code = "share_link_revoked"
+)
+
+// Chat policy errors.
+const (
+	CodeContentTooLong     Code = "content_too_long"
+	CodeTooManyAttachments Code = "too_many_attachments"
+	CodeMimeTypeNotAllowed Code = "mime_type_not_allowed"
+	CodeAttachmentTooLarge Code = "attachment_too_large"
+	CodeRateLimited        Code = "rate_limited"
+)
+
+// Support session errors.
+const (
+	CodeSupportSessionNotFound Code = "support_session_not_found"
+	CodeSupportSessionClosed   Code = "support_session_closed"
+)
+
+// Data erasure errors.
+const (
+	CodeErasureRequestNotFound Code = "erasure_request_not_found"
+)
+
+// Webhook errors.
+const (
+	CodeWebhookNotFound Code = "webhook_not_found"
+)
+
+// Generic errors.
+const (
+	CodeNotFound           Code = "not_found"
+	CodeOverloaded         Code = "overloaded"
+	CodeTooManyConnections Code = "too_many_connections"
+	CodeInternal           Code = "internal_error"
+)
+
+// definition is the HTTP status and short title for a code.
+type definition struct {
+	status int
+	title  string
+}
+
+var catalog = map[Code]definition{
+	CodeInvalidRequest:         {http.StatusBadRequest, "Invalid request"},
+	CodeInvalidID:              {http.StatusBadRequest, "Invalid identifier"},
+	CodeUnauthorized:           {http.StatusUnauthorized, "Unauthorized"},
+	CodeTokenExpired:           {http.StatusUnauthorized, "Token expired"},
+	CodeForbidden:              {http.StatusForbidden, "Forbidden"},
+	CodeNotParticipant:         {http.StatusForbidden, "Not a booking participant"},
+	CodeValidation:             {http.StatusUnprocessableEntity, "Validation failed"},
+	CodeTrackingNotFound:       {http.StatusNotFound, "Tracking not found"},
+	CodeTrackingNotActive:      {http.StatusConflict, "Tracking is not active"},
+	CodeDestinationNotSet:      {http.StatusNotFound, "Destination not set"},
+	CodePositionUnknown:        {http.StatusNotFound, "Position unknown"},
+	CodeGeofenceNotFound:       {http.StatusNotFound, "Geofence not found"},
+	CodeCoordinateRejected:     {http.StatusUnprocessableEntity, "Coordinates rejected"},
+	CodeMapMatchingUnavailable: {http.StatusServiceUnavailable, "Map matching unavailable"},
+	CodeSubscriptionNotFound:   {http.StatusNotFound, "ETA subscription not found"},
+	CodeShareLinkNotFound:      {http.StatusNotFound, "Share link not found"},
+	CodeShareLinkExpired:       {http.StatusGone, "Share link expired"},
+	CodeShareLinkRevoked:       {http.StatusGone, "Share link revoked"},
+	CodeContentTooLong:         {http.StatusRequestEntityTooLarge, "Message content too long"},
+	CodeTooManyAttachments:     {http.StatusUnprocessableEntity, "Too many attachments"},
+	CodeMimeTypeNotAllowed:     {http.StatusUnsupportedMediaType, "Attachment type not allowed"},
+	CodeAttachmentTooLarge:     {http.StatusRequestEntityTooLarge, "Attachment too large"},
+	CodeRateLimited:            {http.StatusTooManyRequests, "Rate limit exceeded"},
+	CodeSupportSessionNotFound: {http.StatusNotFound, "Support session not found"},
+	CodeSupportSessionClosed:   {http.StatusGone, "Support session closed"},
+	CodeErasureRequestNotFound: {http.StatusNotFound, "Erasure request not found"},
+	CodeWebhookNotFound:        {http.StatusNotFound, "Webhook not found"},
+	CodeNotFound:               {http.StatusNotFound, "Not found"},
+	CodeOverloaded:             {http.StatusTooManyRequests, "Service overloaded"},
+	CodeTooManyConnections:     {http.StatusTooManyRequests, "Too many connections"},
+	CodeInternal:               {http.StatusInternalServerError, "Internal server error"},
+}
+
+// Error is an error from the catalog with a request-specific detail message.
+type Error struct {
+	Code   Code
+	Detail string
+
+	// RetryAfter, when positive, tells the client how long to wait before retrying.
+	RetryAfter time.Duration
+
+	cause error
+}
+
+// New creates an Error with a formatted detail message.
+func New(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Detail: fmt.Sprintf(format, args...)}
+}
+
+// Wrap creates an Error whose detail is err's message, keeping err as the cause.
+func Wrap(code Code, err error) *Error {
+	return &Error{Code: code, Detail: err.Error(), cause: err}
+}
+
+// WithRetryAfter sets the retry delay and returns e.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	e.RetryAfter = d
+	return e
+}
+
+func (e *Error) Error() string {
+	return string(e.Code) + ": " + e.Detail
+}
+
+func (e *Error) Unwrap() error { return e.cause }
+
+// Status returns the HTTP status for the error's code.
+func (e *Error) Status() int {
+	if d, ok := catalog[e.Code]; ok {
+		return d.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Title returns the short, code-level summary of the error.
+func (e *Error) Title() string {
+	if d, ok := catalog[e.Code]; ok {
+		return d.title
+	}
+	return catalog[CodeInternal].title
+}
@@ -0,0 +1,66 @@
+package apperror
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+)
+
+// ProblemContentType is the media type of RFC 7807 error responses.
+const ProblemContentType = "application/problem+json"
+
+// problemTypeBase prefixes codes to form the problem "type" URI.
+const problemTypeBase = "urn:kilat-pet:tracking:error:"
+
+// Problem is an RFC 7807 problem details body extended with the catalog code.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     Code   `json:"code"`
+}
+
+// From converts any error into a catalog Error. Errors outside the catalog become
+// not_found for domain.ErrNotFound and internal_error otherwise, without leaking details.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	if errors.Is(err, domain.ErrNotFound) {
+		return &Error{Code: CodeNotFound, Detail: "resource not found", cause: err}
+	}
+	return &Error{Code: CodeInternal, Detail: "an unexpected error occurred", cause: err}
+}
+
+// Respond aborts the request with err rendered as problem+json.
+func Respond(c *gin.Context, err error) {
+	appErr := From(err)
+	if appErr.Code == CodeInternal {
+		_ = c.Error(err)
+	}
+	if appErr.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(appErr.Status(), Problem{
+		Type:     problemTypeBase + string(appErr.Code),
+		Title:    appErr.Title(),
+		Status:   appErr.Status(),
+		Detail:   appErr.Detail,
+		Instance: c.Request.URL.Path,
+		Code:     appErr.Code,
+	})
+}
+
+// Abort aborts the request with a catalog error built from code and detail.
+func Abort(c *gin.Context, code Code, detail string) {
+	Respond(c, &Error{Code: code, Detail: detail})
+}
@@ -0,0 +1,203 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+const (
+	defaultTrackListLimit = 50
+	maxTrackListLimit     = 200
+)
+
+// ListTracksRequest holds the raw query parameters of an admin track listing. Empty
+// fields leave a filter unset.
+type ListTracksRequest struct {
+	// Status is a comma-separated list of statuses.
+	Status   string
+	RunnerID string
+	// From and To bound the start time, as RFC 3339 timestamps or YYYY-MM-DD dates;
+	// a date To includes that whole day.
+	From          string
+	To            string
+	MinDistanceKm string
+	// Sort is started_at or distance, optionally prefixed with - for descending.
+	Sort   string
+	Cursor string
+	Limit  string
+}
+
+// TrackSummaryDTO is a trip track in an admin listing, without its waypoints.
+type TrackSummaryDTO struct {
+	ID              uuid.UUID        `json:"id"`
+	BookingID       uuid.UUID        `json:"booking_id"`
+	RunnerID        uuid.UUID        `json:"runner_id"`
+	Region          string           `json:"region,omitempty"`
+	Status          string           `json:"status"`
+	TotalDistanceKm float64          `json:"total_distance_km"`
+	StartedAt       time.Time        `json:"started_at"`
+	CompletedAt     *time.Time       `json:"completed_at,omitempty"`
+	DurationSeconds float64          `json:"duration_seconds"`
+	Cancellation    *CancellationDTO `json:"cancellation,omitempty"`
+}
+
+// TrackListDTO is a page of an admin track listing. NextCursor is passed as cursor to
+// fetch the next page and is empty on the last page.
+type TrackListDTO struct {
+	Tracks     []TrackSummaryDTO `json:"tracks"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// ListTracks returns a page of trip tracks matching the request's filters, for operators.
+func (s *TrackingService) ListTracks(ctx context.Context, req ListTracksRequest) (*TrackListDTO, error) {
+	opts, limit, err := parseListTracksRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra track to learn whether another page follows.
+	tracks, err := s.repo.List(ctx, append(opts, trackingDomain.WithLimit(limit+1))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracks: %w", err)
+	}
+
+	result := &TrackListDTO{Tracks: make([]TrackSummaryDTO, 0, len(tracks))}
+	if len(tracks) > limit {
+		tracks = tracks[:limit]
+		cursor, _ := json.Marshal(trackingDomain.CursorAfter(tracks[limit-1]))
+		result.NextCursor = base64.RawURLEncoding.EncodeToString(cursor)
+	}
+
+	now := s.clock.Now()
+	for _, track := range tracks {
+		summary := TrackSummaryDTO{
+			ID:              track.ID(),
+			BookingID:       track.BookingID(),
+			RunnerID:        track.RunnerID(),
+			Region:          track.Region(),
+			Status:          string(track.Status()),
+			TotalDistanceKm: track.TotalDistanceKm(),
+			StartedAt:       track.StartedAt(),
+			CompletedAt:     track.CompletedAt(),
+			DurationSeconds: track.Duration(now).Seconds(),
+		}
+		if c := track.Cancellation(); c != nil {
+			summary.Cancellation = &CancellationDTO{Reason: string(c.Reason), Note: c.Note, CancelledAt: c.CancelledAt}
+		}
+		result.Tracks = append(result.Tracks, summary)
+	}
+	return result, nil
+}
+
+// parseListTracksRequest validates an admin track listing's parameters and turns them
+// into list options and a page size.
+func parseListTracksRequest(req ListTracksRequest) ([]trackingDomain.ListOption, int, error) {
+	var opts []trackingDomain.ListOption
+
+	if req.Status != "" {
+		var statuses []trackingDomain.TrackingStatus
+		for _, s := range strings.Split(req.Status, ",") {
+			status := trackingDomain.TrackingStatus(strings.TrimSpace(s))
+			switch status {
+			case trackingDomain.TrackingActive, trackingDomain.TrackingPaused,
+				trackingDomain.TrackingCompleted, trackingDomain.TrackingCancelled:
+				statuses = append(statuses, status)
+			default:
+				return nil, 0, apperror.New(apperror.CodeInvalidRequest, "unknown status %q", s)
+			}
+		}
+		opts = append(opts, trackingDomain.WithStatuses(statuses...))
+	}
+
+	if req.RunnerID != "" {
+		runnerID, err := uuid.Parse(req.RunnerID)
+		if err != nil {
+			return nil, 0, apperror.New(apperror.CodeInvalidID, "invalid runner ID format")
+		}
+		opts = append(opts, trackingDomain.WithRunner(runnerID))
+	}
+
+	from, err := parseListTime(req.From, "from", false)
+	if err != nil {
+		return nil, 0, err
+	}
+	to, err := parseListTime(req.To, "to", true)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return nil, 0, apperror.New(apperror.CodeInvalidRequest, "from must be before to")
+	}
+	if !from.IsZero() || !to.IsZero() {
+		opts = append(opts, trackingDomain.StartedBetween(from, to))
+	}
+
+	if req.MinDistanceKm != "" {
+		km, err := strconv.ParseFloat(req.MinDistanceKm, 64)
+		if err != nil || km < 0 {
+			return nil, 0, apperror.New(apperror.CodeInvalidRequest, "min_distance_km must be a non-negative number")
+		}
+		opts = append(opts, trackingDomain.WithMinDistanceKm(km))
+	}
+
+	if req.Sort != "" {
+		desc := strings.HasPrefix(req.Sort, "-")
+		sort := trackingDomain.ListSort(strings.TrimPrefix(req.Sort, "-"))
+		if sort != trackingDomain.ListSortStartedAt && sort != trackingDomain.ListSortDistance {
+			return nil, 0, apperror.New(apperror.CodeInvalidRequest, "sort must be started_at or distance, optionally prefixed with -")
+		}
+		opts = append(opts, trackingDomain.SortedBy(sort, desc))
+	}
+
+	if req.Cursor != "" {
+		var cursor trackingDomain.ListCursor
+		raw, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+		if err != nil || json.Unmarshal(raw, &cursor) != nil || cursor.ID == uuid.Nil {
+			return nil, 0, apperror.New(apperror.CodeInvalidRequest, "invalid cursor")
+		}
+		opts = append(opts, trackingDomain.After(cursor))
+	}
+
+	limit := defaultTrackListLimit
+	if req.Limit != "" {
+		n, err := strconv.Atoi(req.Limit)
+		if err != nil || n < 1 {
+			return nil, 0, apperror.New(apperror.CodeInvalidRequest, "limit must be a positive integer")
+		}
+		limit = n
+	}
+	if limit > maxTrackListLimit {
+		limit = maxTrackListLimit
+	}
+
+	return opts, limit, nil
+}
+
+// parseListTime parses an RFC 3339 timestamp or a YYYY-MM-DD date in UTC. A date parsed
+// as an end bound is moved to the end of that day.
+func parseListTime(value, name string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(digestDateLayout, value)
+	if err != nil {
+		return time.Time{}, apperror.New(apperror.CodeInvalidRequest, "%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", name)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+const (
+	// defaultHeatmapPrecision gives cells of about 150 m by 150 m.
+	defaultHeatmapPrecision = 7
+	// defaultHeatmapWindow is the time window of a heatmap without from.
+	defaultHeatmapWindow = 7 * 24 * time.Hour
+	// maxHeatmapWindow bounds the waypoints a single heatmap reads.
+	maxHeatmapWindow = 31 * 24 * time.Hour
+	// maxHeatmapCells is the most cells returned; the sparsest are cut beyond it.
+	maxHeatmapCells = 5000
+)
+
+// HeatmapRequest holds the parameters of a heatmap. From and To are RFC 3339 timestamps
+// or YYYY-MM-DD dates; a date To includes that whole day.
+type HeatmapRequest struct {
+	Bounds    trackingDomain.BoundingBox
+	From      string
+	To        string
+	Precision string
+}
+
+// HeatmapCellDTO is the waypoint density of one geohash cell. BBox is the cell as
+// minLng,minLat,maxLng,maxLat and Latitude/Longitude its center.
+type HeatmapCellDTO struct {
+	Geohash   string     `json:"geohash"`
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	BBox      [4]float64 `json:"bbox"`
+	Waypoints int        `json:"waypoints"`
+	Trips     int        `json:"trips"`
+}
+
+// HeatmapDTO is the waypoint density of an area, densest cells first. Truncated is set
+// when sparser cells were cut.
+type HeatmapDTO struct {
+	Precision int              `json:"precision"`
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Cells     []HeatmapCellDTO `json:"cells"`
+	Truncated bool             `json:"truncated"`
+}
+
+// AnalyticsConfig tunes trip analytics.
+type AnalyticsConfig struct {
+	// ExcludeRunners are runners left out of analytics, such as the probe runner.
+	ExcludeRunners []uuid.UUID
+}
+
+// AnalyticsService aggregates trips for operations, e.g. to see where deliveries
+// concentrate and position runners accordingly.
+type AnalyticsService struct {
+	tracks trackingDomain.TripTrackRepository
+	config AnalyticsConfig
+}
+
+// NewAnalyticsService creates a new AnalyticsService.
+func NewAnalyticsService(tracks trackingDomain.TripTrackRepository, config AnalyticsConfig) *AnalyticsService {
+	return &AnalyticsService{tracks: tracks, config: config}
+}
+
+// GetHeatmap bins the waypoints recorded within the request's box and time window into
+// geohash cells.
+func (s *AnalyticsService) GetHeatmap(ctx context.Context, req HeatmapRequest) (*HeatmapDTO, error) {
+	q, err := s.parseHeatmapRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra cell to learn whether any were cut.
+	q.Limit = maxHeatmapCells + 1
+	cells, err := s.tracks.GetWaypointHeatmap(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute heatmap: %w", err)
+	}
+
+	result := &HeatmapDTO{Precision: q.Precision, From: q.From, To: q.To, Cells: make([]HeatmapCellDTO, 0, len(cells))}
+	if len(cells) > maxHeatmapCells {
+		cells = cells[:maxHeatmapCells]
+		result.Truncated = true
+	}
+	for _, cell := range cells {
+		box := trackingDomain.GeohashBounds(cell.Geohash)
+		result.Cells = append(result.Cells, HeatmapCellDTO{
+			Geohash:   cell.Geohash,
+			Latitude:  (box.MinLatitude + box.MaxLatitude) / 2,
+			Longitude: (box.MinLongitude + box.MaxLongitude) / 2,
+			BBox:      [4]float64{box.MinLongitude, box.MinLatitude, box.MaxLongitude, box.MaxLatitude},
+			Waypoints: cell.Waypoints,
+			Trips:     cell.Trips,
+		})
+	}
+	return result, nil
+}
+
+// parseHeatmapRequest validates a heatmap's parameters and applies the defaults: the
+// last seven days up to now, in cells of precision 7.
+func (s *AnalyticsService) parseHeatmapRequest(req HeatmapRequest) (trackingDomain.HeatmapQuery, error) {
+	q := trackingDomain.HeatmapQuery{
+		Bounds:         req.Bounds,
+		Precision:      defaultHeatmapPrecision,
+		ExcludeRunners: s.config.ExcludeRunners,
+	}
+
+	if req.Precision != "" {
+		p, err := strconv.Atoi(req.Precision)
+		if err != nil || p < trackingDomain.MinGeohashPrecision || p > trackingDomain.MaxGeohashPrecision {
+			return q, apperror.New(apperror.CodeInvalidRequest, "precision must be an integer from %d to %d",
+				trackingDomain.MinGeohashPrecision, trackingDomain.MaxGeohashPrecision)
+		}
+		q.Precision = p
+	}
+
+	from, err := parseListTime(req.From, "from", false)
+	if err != nil {
+		return q, err
+	}
+	to, err := parseListTime(req.To, "to", true)
+	if err != nil {
+		return q, err
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultHeatmapWindow)
+	}
+	if !from.Before(to) {
+		return q, apperror.New(apperror.CodeInvalidRequest, "from must be before to")
+	}
+	if to.Sub(from) > maxHeatmapWindow {
+		return q, apperror.New(apperror.CodeInvalidRequest, "from and to must be at most %d days apart", int(maxHeatmapWindow.Hours()/24))
+	}
+	q.From, q.To = from.UTC(), to.UTC()
+	return q, nil
+}
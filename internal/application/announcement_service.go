@@ -0,0 +1,234 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+// AnnouncementTopic carries announcements to every service instance, each of which
+// delivers them to its own WebSocket clients.
+const AnnouncementTopic = "tracking.announcements"
+
+// eventAnnouncement is the CloudEvent type of an announcement.
+const eventAnnouncement = "tracking.announcement"
+
+// Announcement severities, which clients use to style the banner.
+const (
+	AnnouncementInfo     = "info"
+	AnnouncementWarning  = "warning"
+	AnnouncementCritical = "critical"
+)
+
+const (
+	maxAnnouncementTitleLength   = 100
+	maxAnnouncementMessageLength = 500
+	maxAnnouncementBookings      = 1000
+
+	// defaultAnnouncementTTL is how long a banner is shown when no expiry is requested.
+	defaultAnnouncementTTL = time.Hour
+	// maxAnnouncementTTL is the longest a banner may be shown.
+	maxAnnouncementTTL = 24 * time.Hour
+
+	// maxAnnouncementDelay is how late an instance may receive an announcement and still
+	// deliver it, so a restarted instance does not replay old ones.
+	maxAnnouncementDelay = time.Minute
+)
+
+// announcementRoles are the audiences an announcement can be limited to.
+var announcementRoles = map[string]bool{
+	string(auth.RoleOwner):  true,
+	string(auth.RoleRunner): true,
+	string(auth.RoleShop):   true,
+	string(auth.RoleAdmin):  true,
+	ws.RoleViewer:           true,
+}
+
+// BroadcastAnnouncementRequest is an operational announcement to show in booking rooms.
+// Exactly one of Region and BookingIDs must be set.
+type BroadcastAnnouncementRequest struct {
+	// Region targets the rooms of every active trip in the region (e.g. id-jkt).
+	Region string `json:"region"`
+	// BookingIDs targets specific rooms.
+	BookingIDs []uuid.UUID `json:"booking_ids"`
+	// Roles limits the audience; empty means everyone in the targeted rooms.
+	Roles    []string `json:"roles"`
+	Severity string   `json:"severity"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message" binding:"required"`
+	// ExpiresIn is how long clients show the banner, in seconds; 0 means one hour.
+	ExpiresIn int `json:"expires_in"`
+}
+
+// AnnouncementDTO is an announcement as published and as sent to WebSocket clients.
+type AnnouncementDTO struct {
+	ID         uuid.UUID   `json:"id"`
+	Region     string      `json:"region,omitempty"`
+	BookingIDs []uuid.UUID `json:"booking_ids,omitempty"`
+	Roles      []string    `json:"roles,omitempty"`
+	Severity   string      `json:"severity"`
+	Title      string      `json:"title,omitempty"`
+	Message    string      `json:"message"`
+	SentBy     uuid.UUID   `json:"sent_by"`
+	SentAt     time.Time   `json:"sent_at"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+}
+
+// AnnouncementFrameDTO is the data of an announcement WebSocket frame.
+type AnnouncementFrameDTO struct {
+	ID        uuid.UUID `json:"id"`
+	Severity  string    `json:"severity"`
+	Title     string    `json:"title,omitempty"`
+	Message   string    `json:"message"`
+	SentAt    time.Time `json:"sent_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AnnouncementService broadcasts operational announcements into WebSocket rooms.
+type AnnouncementService struct {
+	repo     trackingDomain.TripTrackRepository
+	hub      *ws.Hub
+	producer EventPublisher
+	logger   *zap.Logger
+}
+
+// NewAnnouncementService creates a new AnnouncementService.
+func NewAnnouncementService(
+	repo trackingDomain.TripTrackRepository,
+	hub *ws.Hub,
+	producer EventPublisher,
+	logger *zap.Logger,
+) *AnnouncementService {
+	return &AnnouncementService{
+		repo:     repo,
+		hub:      hub,
+		producer: producer,
+		logger:   logger,
+	}
+}
+
+// Broadcast validates an announcement, resolves the rooms it targets and publishes it
+// to AnnouncementTopic, from which every instance delivers it.
+func (s *AnnouncementService) Broadcast(ctx context.Context, sentBy uuid.UUID, req BroadcastAnnouncementRequest) (*AnnouncementDTO, error) {
+	if err := validateAnnouncement(&req); err != nil {
+		return nil, err
+	}
+
+	bookingIDs := req.BookingIDs
+	if req.Region != "" {
+		ids, err := s.repo.FindActiveBookingIDsByRegion(ctx, req.Region)
+		if err != nil {
+			return nil, err
+		}
+		bookingIDs = ids
+	}
+
+	now := time.Now().UTC()
+	ttl := defaultAnnouncementTTL
+	if req.ExpiresIn > 0 {
+		ttl = time.Duration(req.ExpiresIn) * time.Second
+	}
+	announcement := &AnnouncementDTO{
+		ID:         uuid.New(),
+		Region:     req.Region,
+		BookingIDs: bookingIDs,
+		Roles:      req.Roles,
+		Severity:   req.Severity,
+		Title:      req.Title,
+		Message:    req.Message,
+		SentBy:     sentBy,
+		SentAt:     now,
+		ExpiresAt:  now.Add(ttl),
+	}
+
+	// A region without active trips has no rooms to send to.
+	if len(bookingIDs) == 0 {
+		s.logger.Info("announcement has no rooms to send to", zap.String("region", req.Region))
+		return announcement, nil
+	}
+
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventAnnouncement, announcement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create announcement event: %w", err)
+	}
+	if err := s.producer.PublishEvent(ctx, AnnouncementTopic, cloudEvt); err != nil {
+		return nil, fmt.Errorf("failed to publish announcement: %w", err)
+	}
+
+	s.logger.Info("announcement broadcast",
+		zap.String("announcement_id", announcement.ID.String()),
+		zap.String("region", req.Region),
+		zap.Int("rooms", len(bookingIDs)),
+		zap.Strings("roles", req.Roles),
+		zap.String("sent_by", sentBy.String()),
+	)
+	return announcement, nil
+}
+
+// Deliver sends a published announcement to this instance's WebSocket clients, unless
+// it expired or arrived too late.
+func (s *AnnouncementService) Deliver(a AnnouncementDTO) {
+	now := time.Now()
+	if now.After(a.ExpiresAt) || now.Sub(a.SentAt) > maxAnnouncementDelay {
+		s.logger.Debug("skipping stale announcement", zap.String("announcement_id", a.ID.String()))
+		return
+	}
+
+	s.hub.Announce(&ws.Announcement{
+		BookingIDs: a.BookingIDs,
+		Roles:      a.Roles,
+		Data: AnnouncementFrameDTO{
+			ID:        a.ID,
+			Severity:  a.Severity,
+			Title:     a.Title,
+			Message:   a.Message,
+			SentAt:    a.SentAt,
+			ExpiresAt: a.ExpiresAt,
+		},
+	})
+}
+
+// validateAnnouncement checks an announcement request and applies defaults.
+func validateAnnouncement(req *BroadcastAnnouncementRequest) error {
+	switch {
+	case req.Region == "" && len(req.BookingIDs) == 0:
+		return apperror.New(apperror.CodeValidation, "region or booking_ids is required")
+	case req.Region != "" && len(req.BookingIDs) > 0:
+		return apperror.New(apperror.CodeValidation, "only one of region and booking_ids may be set")
+	case len(req.BookingIDs) > maxAnnouncementBookings:
+		return apperror.New(apperror.CodeValidation, "at most %d booking_ids may be set", maxAnnouncementBookings)
+	}
+	for _, role := range req.Roles {
+		if !announcementRoles[role] {
+			return apperror.New(apperror.CodeValidation, "unknown role: %s", role)
+		}
+	}
+
+	switch req.Severity {
+	case "":
+		req.Severity = AnnouncementInfo
+	case AnnouncementInfo, AnnouncementWarning, AnnouncementCritical:
+	default:
+		return apperror.New(apperror.CodeValidation, "severity must be info, warning or critical")
+	}
+
+	if len(req.Title) > maxAnnouncementTitleLength {
+		return apperror.New(apperror.CodeValidation, "title must be at most %d characters", maxAnnouncementTitleLength)
+	}
+	if len(req.Message) > maxAnnouncementMessageLength {
+		return apperror.New(apperror.CodeValidation, "message must be at most %d characters", maxAnnouncementMessageLength)
+	}
+	if req.ExpiresIn < 0 || time.Duration(req.ExpiresIn)*time.Second > maxAnnouncementTTL {
+		return apperror.New(apperror.CodeValidation, "expires_in must be between 0 and %d seconds", int(maxAnnouncementTTL.Seconds()))
+	}
+	return nil
+}
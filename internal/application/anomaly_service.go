@@ -0,0 +1,335 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	anomalyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/anomaly"
+	historyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/history"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// eventAnomalyDetected is the CloudEvent type published when an anomaly is detected on a trip.
+const eventAnomalyDetected = "tracking.anomaly_detected"
+
+const (
+	// minTeleportMeters is the shortest jump reported as a teleport, so GPS noise between
+	// fixes taken a moment apart is ignored.
+	minTeleportMeters = 500.0
+
+	// arrivalRadiusMeters is the distance from the destination within which stops are
+	// expected (handover) and not reported.
+	arrivalRadiusMeters = 200.0
+
+	// anomalyStateTTL is how long detection state is kept for a booking without updates.
+	anomalyStateTTL = time.Hour
+)
+
+// AnomalyConfig sets the thresholds of anomaly detection.
+type AnomalyConfig struct {
+	// MaxSpeedKmh is the implied speed between consecutive waypoints above which the
+	// jump is reported as a teleport.
+	MaxSpeedKmh float64
+	// StopDuration is how long a runner may stay within StopRadiusMeters of one place
+	// before a prolonged stop is reported.
+	StopDuration     time.Duration
+	StopRadiusMeters float64
+	// MaxDeviationMeters is the distance from the line between the trip's origin and
+	// destination above which a route deviation is reported.
+	MaxDeviationMeters float64
+}
+
+// AnomalyDTO is the API representation of a detected anomaly.
+type AnomalyDTO struct {
+	ID         uuid.UUID `json:"id"`
+	Kind       string    `json:"kind"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Value      float64   `json:"value"`
+	Threshold  float64   `json:"threshold"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// TrackingAnomalyDetectedEvent is published when an anomaly is detected on a trip.
+type TrackingAnomalyDetectedEvent struct {
+	AnomalyID  uuid.UUID `json:"anomaly_id"`
+	TrackID    uuid.UUID `json:"track_id"`
+	BookingID  uuid.UUID `json:"booking_id"`
+	RunnerID   uuid.UUID `json:"runner_id"`
+	Kind       string    `json:"kind"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Value      float64   `json:"value"`
+	Threshold  float64   `json:"threshold"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// anomalyState is the detection state of one booking's trip.
+type anomalyState struct {
+	last         trackingDomain.Waypoint
+	hasLast      bool
+	origin       trackingDomain.Waypoint
+	stopAnchor   trackingDomain.Waypoint
+	stopReported bool
+	deviating    bool
+	seenAt       time.Time
+}
+
+// AnomalyService detects suspicious driving patterns from accepted waypoints, stores
+// them per trip and publishes an event for each.
+type AnomalyService struct {
+	repo         anomalyDomain.Repository
+	trackingRepo trackingDomain.TripTrackRepository
+	producer     EventPublisher
+	config       AnomalyConfig
+	history      *TrackHistory
+	logger       *zap.Logger
+
+	mu        sync.Mutex
+	states    map[uuid.UUID]*anomalyState // bookingID -> detection state
+	lastSweep time.Time
+}
+
+// NewAnomalyService creates a new AnomalyService.
+func NewAnomalyService(
+	repo anomalyDomain.Repository,
+	trackingRepo trackingDomain.TripTrackRepository,
+	producer EventPublisher,
+	config AnomalyConfig,
+	logger *zap.Logger,
+) *AnomalyService {
+	return &AnomalyService{
+		repo:         repo,
+		trackingRepo: trackingRepo,
+		producer:     producer,
+		config:       config,
+		logger:       logger.With(zap.String("component", "anomaly")),
+		states:       make(map[uuid.UUID]*anomalyState),
+	}
+}
+
+// UseTrackHistory records each detected anomaly in its trip's history.
+func (s *AnomalyService) UseTrackHistory(h *TrackHistory) {
+	s.history = h
+}
+
+// GetAnomalies returns the anomalies detected on a booking's trip, oldest first.
+func (s *AnomalyService) GetAnomalies(ctx context.Context, bookingID uuid.UUID) ([]AnomalyDTO, error) {
+	track, err := s.trackingRepo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	anomalies, err := s.repo.FindByTrackID(ctx, track.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find anomalies: %w", err)
+	}
+
+	result := make([]AnomalyDTO, len(anomalies))
+	for i, a := range anomalies {
+		result[i] = AnomalyDTO{
+			ID:         a.ID,
+			Kind:       string(a.Kind),
+			Latitude:   a.Latitude,
+			Longitude:  a.Longitude,
+			Value:      a.Value,
+			Threshold:  a.Threshold,
+			DetectedAt: a.DetectedAt,
+		}
+	}
+	return result, nil
+}
+
+// OnLocation checks a waypoint against the trip's previous ones. Detected anomalies
+// are stored and published in the background so ingestion is not slowed down.
+func (s *AnomalyService) OnLocation(ctx context.Context, track *trackingDomain.TripTrack, waypoint trackingDomain.Waypoint) {
+	s.mu.Lock()
+	_, known := s.states[track.BookingID()]
+	s.mu.Unlock()
+
+	// Reading the origin queries the database, so it is done once per trip and outside the lock.
+	origin := waypoint
+	if !known {
+		origin = s.origin(ctx, track, waypoint)
+	}
+
+	s.mu.Lock()
+	state, ok := s.states[track.BookingID()]
+	if !ok {
+		state = &anomalyState{origin: origin, stopAnchor: waypoint}
+		s.states[track.BookingID()] = state
+	}
+	found := s.detect(track, state, waypoint)
+	state.last, state.hasLast = waypoint, true
+	state.seenAt = time.Now()
+	s.sweep(state.seenAt)
+	s.mu.Unlock()
+
+	if len(found) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		for _, a := range found {
+			s.record(ctx, track, a)
+		}
+	}()
+}
+
+// detect returns the anomalies a waypoint reveals and updates the trip's state. It must
+// be called with s.mu held.
+func (s *AnomalyService) detect(track *trackingDomain.TripTrack, state *anomalyState, wp trackingDomain.Waypoint) []*anomalyDomain.Anomaly {
+	// A paused runner is on a break: nothing is reported, and the stop timer restarts
+	// from the last position seen while paused.
+	if track.IsPaused() {
+		state.stopAnchor, state.stopReported = wp, false
+		return nil
+	}
+
+	var found []*anomalyDomain.Anomaly
+	newAnomaly := func(kind anomalyDomain.Kind, value, threshold float64) {
+		found = append(found, &anomalyDomain.Anomaly{
+			ID:         uuid.New(),
+			TrackID:    track.ID(),
+			BookingID:  track.BookingID(),
+			RunnerID:   track.RunnerID(),
+			Kind:       kind,
+			Latitude:   wp.Latitude,
+			Longitude:  wp.Longitude,
+			Value:      math.Round(value*10) / 10,
+			Threshold:  threshold,
+			DetectedAt: wp.RecordedAt,
+		})
+	}
+
+	// Teleport: an impossible speed between consecutive waypoints.
+	if state.hasLast {
+		elapsed := wp.RecordedAt.Sub(state.last.RecordedAt)
+		meters := trackingDomain.DistanceMeters(state.last.Latitude, state.last.Longitude, wp.Latitude, wp.Longitude)
+		if elapsed > 0 && meters >= minTeleportMeters {
+			if kmh := meters / elapsed.Seconds() * 3.6; kmh > s.config.MaxSpeedKmh {
+				newAnomaly(anomalyDomain.KindTeleport, kmh, s.config.MaxSpeedKmh)
+			}
+		}
+	}
+
+	dest := track.Destination()
+
+	// Prolonged stop: staying near one place mid-trip, except at the destination.
+	anchor := state.stopAnchor
+	if trackingDomain.DistanceMeters(anchor.Latitude, anchor.Longitude, wp.Latitude, wp.Longitude) > s.config.StopRadiusMeters {
+		state.stopAnchor, state.stopReported = wp, false
+	} else if stopped := wp.RecordedAt.Sub(anchor.RecordedAt); !state.stopReported && stopped >= s.config.StopDuration {
+		atDestination := dest != nil &&
+			trackingDomain.DistanceMeters(dest.Latitude, dest.Longitude, wp.Latitude, wp.Longitude) <= arrivalRadiusMeters
+		if !atDestination {
+			newAnomaly(anomalyDomain.KindProlongedStop, stopped.Seconds(), s.config.StopDuration.Seconds())
+		}
+		state.stopReported = true
+	}
+
+	// Route deviation: straying from the line between origin and destination. It is
+	// reported again only after the runner comes back within half the limit.
+	if dest != nil {
+		deviation := distanceToSegmentMeters(wp, state.origin, trackingDomain.Waypoint{Latitude: dest.Latitude, Longitude: dest.Longitude})
+		switch {
+		case !state.deviating && deviation > s.config.MaxDeviationMeters:
+			newAnomaly(anomalyDomain.KindRouteDeviation, deviation, s.config.MaxDeviationMeters)
+			state.deviating = true
+		case state.deviating && deviation < s.config.MaxDeviationMeters/2:
+			state.deviating = false
+		}
+	}
+	return found
+}
+
+// origin returns the first waypoint of a trip, or wp if it cannot be read.
+func (s *AnomalyService) origin(ctx context.Context, track *trackingDomain.TripTrack, wp trackingDomain.Waypoint) trackingDomain.Waypoint {
+	waypoints, err := s.trackingRepo.GetWaypointsBetween(ctx, track.ID(), track.StartedAt(), track.StartedAt())
+	if err != nil || len(waypoints) == 0 {
+		return wp
+	}
+	return waypoints[0]
+}
+
+// sweep forgets trips without updates for anomalyStateTTL. It runs at most once per
+// TTL and must be called with s.mu held.
+func (s *AnomalyService) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < anomalyStateTTL {
+		return
+	}
+	s.lastSweep = now
+	for bookingID, state := range s.states {
+		if now.Sub(state.seenAt) >= anomalyStateTTL {
+			delete(s.states, bookingID)
+		}
+	}
+}
+
+// record stores an anomaly, adds it to the trip's history and publishes a
+// TrackingAnomalyDetectedEvent.
+func (s *AnomalyService) record(ctx context.Context, track *trackingDomain.TripTrack, a *anomalyDomain.Anomaly) {
+	s.logger.Warn("trip anomaly detected",
+		zap.String("booking_id", a.BookingID.String()),
+		zap.String("runner_id", a.RunnerID.String()),
+		zap.String("kind", string(a.Kind)),
+		zap.Float64("value", a.Value),
+		zap.Float64("threshold", a.Threshold),
+	)
+
+	if err := s.repo.Save(ctx, a); err != nil {
+		s.logger.Error("failed to save anomaly", zap.Error(err))
+	}
+	if s.history != nil {
+		s.history.Record(ctx, track, historyDomain.KindAnomaly, historyDomain.System,
+			fmt.Sprintf("%s: %.1f exceeds %.1f", a.Kind, a.Value, a.Threshold), a.DetectedAt)
+	}
+
+	evt := TrackingAnomalyDetectedEvent{
+		AnomalyID:  a.ID,
+		TrackID:    a.TrackID,
+		BookingID:  a.BookingID,
+		RunnerID:   a.RunnerID,
+		Kind:       string(a.Kind),
+		Latitude:   a.Latitude,
+		Longitude:  a.Longitude,
+		Value:      a.Value,
+		Threshold:  a.Threshold,
+		DetectedAt: a.DetectedAt,
+	}
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventAnomalyDetected, evt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish anomaly event", zap.Error(err))
+	}
+}
+
+// distanceToSegmentMeters returns the distance in meters from p to the segment a-b,
+// using an equirectangular projection around p, which is accurate at city scale.
+func distanceToSegmentMeters(p, a, b trackingDomain.Waypoint) float64 {
+	const metersPerDegree = 111320.0
+	cosLat := math.Cos(p.Latitude * math.Pi / 180)
+	project := func(w trackingDomain.Waypoint) (float64, float64) {
+		return (w.Longitude - p.Longitude) * metersPerDegree * cosLat, (w.Latitude - p.Latitude) * metersPerDegree
+	}
+
+	ax, ay := project(a)
+	bx, by := project(b)
+	dx, dy := bx-ax, by-ay
+	if dx == 0 && dy == 0 {
+		return math.Hypot(ax, ay)
+	}
+	t := math.Max(0, math.Min(1, -(ax*dx+ay*dy)/(dx*dx+dy*dy)))
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
@@ -0,0 +1,86 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	participantDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/participant"
+	temperatureDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/temperature"
+)
+
+// RoleService is the role of the tokens other Kilat services call internal APIs with,
+// such as the gRPC API and the internal REST routes.
+const RoleService auth.UserRole = "service"
+
+// BookingParticipantsEvent holds the participant IDs and pet species carried by booking
+// events. IDs an event does not carry are left as uuid.Nil.
+type BookingParticipantsEvent struct {
+	BookingID  uuid.UUID `json:"booking_id"`
+	OwnerID    uuid.UUID `json:"owner_id"`
+	RunnerID   uuid.UUID `json:"runner_id"`
+	PetSpecies string    `json:"pet_species"`
+}
+
+// UseParticipants enables recording booking participants from booking events and
+// checking them in AuthorizeBooking. Must be called before consumers start.
+func (s *TrackingService) UseParticipants(repo participantDomain.Repository) {
+	s.participants = repo
+}
+
+// HandleBookingParticipants records the owner and runner of a booking.
+func (s *TrackingService) HandleBookingParticipants(ctx context.Context, event BookingParticipantsEvent) error {
+	if s.participants == nil || event.BookingID == uuid.Nil {
+		return nil
+	}
+
+	if err := s.participants.Upsert(ctx, participantDomain.BookingParticipants{
+		BookingID:  event.BookingID,
+		OwnerID:    event.OwnerID,
+		RunnerID:   event.RunnerID,
+		PetSpecies: temperatureDomain.NormalizeSpecies(event.PetSpecies),
+	}); err != nil {
+		return fmt.Errorf("failed to record booking participants: %w", err)
+	}
+	return nil
+}
+
+// AuthorizeBooking returns a not_participant error unless the user is an admin, the booking's
+// owner or its assigned runner. The track's runner is accepted too, so runners keep
+// access to trips accepted before participants were recorded.
+func (s *TrackingService) AuthorizeBooking(ctx context.Context, bookingID, userID uuid.UUID, role auth.UserRole) error {
+	if role == auth.RoleAdmin {
+		return nil
+	}
+	return s.AuthorizeParticipant(ctx, bookingID, userID)
+}
+
+// AuthorizeParticipant returns a not_participant error unless the user is the booking's owner
+// or its assigned runner, whatever their role. It guards what only the two parties of a
+// delivery may do, such as chatting.
+func (s *TrackingService) AuthorizeParticipant(ctx context.Context, bookingID, userID uuid.UUID) error {
+	if s.participants != nil {
+		p, err := s.participants.FindByBookingID(ctx, bookingID)
+		switch {
+		case err == nil && p.Includes(userID):
+			return nil
+		case err != nil && !errors.Is(err, domain.ErrNotFound):
+			return apperror.Wrap(apperror.CodeInternal, err)
+		}
+	}
+
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	switch {
+	case err == nil && userID != uuid.Nil && track.RunnerID() == userID:
+		return nil
+	case err != nil && !errors.Is(err, domain.ErrNotFound):
+		return apperror.Wrap(apperror.CodeInternal, err)
+	}
+
+	return apperror.New(apperror.CodeNotParticipant, "no access to booking %s", bookingID)
+}
@@ -0,0 +1,142 @@
+package application
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	shareDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/share"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// CapabilitiesConfig describes how this deployment is configured, for clients that
+// adapt their UI to it.
+type CapabilitiesConfig struct {
+	// Regions are the deployment regions served, from KAFKA_REGIONS.
+	Regions []string
+	// RegionBounds are the configured coordinate bounds per region.
+	RegionBounds map[string]trackingDomain.BoundingBox
+	// DefaultBounds applies to regions without their own bounds; nil if unset.
+	DefaultBounds *trackingDomain.BoundingBox
+
+	TripWeather        bool
+	Certificates       bool
+	RoadRouting        bool
+	MapMatching        bool
+	ETAUpdateThreshold time.Duration
+	Chat               ChatPolicy
+	AttachmentUploads  bool
+}
+
+// FeatureDTO reports whether an optional feature is enabled, with any settings a
+// client needs to use it.
+type FeatureDTO struct {
+	Enabled  bool                   `json:"enabled"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+// BoundsDTO is a region's coordinate bounds.
+type BoundsDTO struct {
+	MinLatitude  float64 `json:"min_latitude"`
+	MinLongitude float64 `json:"min_longitude"`
+	MaxLatitude  float64 `json:"max_latitude"`
+	MaxLongitude float64 `json:"max_longitude"`
+}
+
+// CapabilitiesDTO describes the features of this deployment. Features missing from the
+// map are not offered and should be treated as disabled.
+type CapabilitiesDTO struct {
+	Region   string                `json:"region,omitempty"`
+	Regions  []string              `json:"regions"`
+	Bounds   *BoundsDTO            `json:"bounds,omitempty"`
+	Features map[string]FeatureDTO `json:"features"`
+}
+
+// CapabilitiesService reports which optional features are enabled in this deployment.
+type CapabilitiesService struct {
+	config CapabilitiesConfig
+}
+
+// NewCapabilitiesService creates a new CapabilitiesService.
+func NewCapabilitiesService(config CapabilitiesConfig) *CapabilitiesService {
+	regions := append([]string(nil), config.Regions...)
+	sort.Strings(regions)
+	config.Regions = regions
+	return &CapabilitiesService{config: config}
+}
+
+// GetCapabilities describes this deployment. With a region, the response also includes
+// the coordinate bounds enforced on that region's trips, if any.
+func (s *CapabilitiesService) GetCapabilities(region string) (*CapabilitiesDTO, error) {
+	c := s.config
+	result := &CapabilitiesDTO{
+		Region:  region,
+		Regions: c.Regions,
+		Features: map[string]FeatureDTO{
+			"telemetry": {Enabled: true, Settings: map[string]interface{}{
+				"temperature_alerts": true,
+				"humidity_alerts":    true,
+				"location_readings":  true,
+			}},
+			"eta": {Enabled: true, Settings: map[string]interface{}{
+				"provider":                 "gps_speed_average",
+				"road_routing":             c.RoadRouting,
+				"update_threshold_seconds": c.ETAUpdateThreshold.Seconds(),
+			}},
+			"share_links": {Enabled: true, Settings: map[string]interface{}{
+				"default_expires_in": shareDomain.DefaultExpiry.Seconds(),
+				"min_expires_in":     shareDomain.MinExpiry.Seconds(),
+				"max_expires_in":     shareDomain.MaxExpiry.Seconds(),
+				"view_limits":        true,
+			}},
+			"chat": {Enabled: true, Settings: map[string]interface{}{
+				"max_content_length":   c.Chat.MaxContentLength,
+				"max_attachments":      c.Chat.MaxAttachments,
+				"max_attachment_bytes": c.Chat.MaxAttachmentBytes,
+				"allowed_mime_types":   c.Chat.AllowedMimeTypes,
+				"attachment_uploads":   c.AttachmentUploads,
+			}},
+			"route_export": {Enabled: true, Settings: map[string]interface{}{
+				"formats":      []string{RouteFormatGeoJSON, RouteFormatGPX, RouteFormatKML, RouteFormatResampled},
+				"map_matching": c.MapMatching,
+			}},
+			"location_ping": {Enabled: true},
+			"trip_pause":    {Enabled: true},
+			"trip_weather":  {Enabled: c.TripWeather},
+			"certificates":  {Enabled: c.Certificates},
+		},
+	}
+	if result.Regions == nil {
+		result.Regions = []string{}
+	}
+
+	if region == "" {
+		return result, nil
+	}
+	box, ok := c.RegionBounds[region]
+	if !ok && !s.servesRegion(region) {
+		return nil, apperror.New(apperror.CodeInvalidRequest, "unknown region %q", region)
+	}
+	if !ok && c.DefaultBounds != nil {
+		box, ok = *c.DefaultBounds, true
+	}
+	if ok {
+		result.Bounds = &BoundsDTO{
+			MinLatitude:  box.MinLatitude,
+			MinLongitude: box.MinLongitude,
+			MaxLatitude:  box.MaxLatitude,
+			MaxLongitude: box.MaxLongitude,
+		}
+	}
+	return result, nil
+}
+
+// servesRegion reports whether region is one of the deployment's regions.
+func (s *CapabilitiesService) servesRegion(region string) bool {
+	for _, r := range s.config.Regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
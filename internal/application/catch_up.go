@@ -0,0 +1,31 @@
+package application
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// catchUpState tracks whether the Kafka consumers are working through a backlog. While
+// they are, locations consumed long after they were recorded are broadcast as historical
+// rather than live, so viewers do not see the runner replay its route as if in real time.
+type catchUpState struct {
+	staleAfter time.Duration // zero disables the check
+	active     atomic.Bool
+}
+
+// UseCatchUp marks consumed locations recorded more than staleAfter ago as historical
+// while SetCatchingUp(true) is in effect. Must be called before consumers start.
+func (s *TrackingService) UseCatchUp(staleAfter time.Duration) {
+	s.catchUp.staleAfter = staleAfter
+}
+
+// SetCatchingUp reports whether the consumers are catching up on a backlog.
+func (s *TrackingService) SetCatchingUp(active bool) {
+	s.catchUp.active.Store(active)
+}
+
+// historical reports whether a consumed location recorded at recordedAt is stale enough,
+// during a catch-up, to be broadcast as historical.
+func (s *TrackingService) historical(recordedAt time.Time) bool {
+	return s.catchUp.staleAfter > 0 && s.catchUp.active.Load() && s.clock.Now().Sub(recordedAt) > s.catchUp.staleAfter
+}
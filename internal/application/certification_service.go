@@ -0,0 +1,259 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/certify"
+	certificateDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/certificate"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// certificateVersion is the version of the certificate payload format.
+const certificateVersion = 1
+
+const (
+	// certificateDistanceTolerance is how far, in kilometers, current trip distance may
+	// differ from a certificate's before it counts as altered. Certified distances are
+	// rounded to meters.
+	certificateDistanceTolerance = 0.001
+
+	// certificateTimeTolerance absorbs the rounding of timestamps by the database.
+	certificateTimeTolerance = time.Millisecond
+)
+
+// TripCertificatePayload is the signed summary of a completed trip. RouteHash is
+// "sha256:" followed by the hex SHA-256 of the trip's waypoints, one
+// "<recorded_at RFC 3339>,<latitude>,<longitude>\n" line each, in recorded order.
+type TripCertificatePayload struct {
+	Version       int       `json:"version"`
+	TrackID       uuid.UUID `json:"track_id"`
+	BookingID     uuid.UUID `json:"booking_id"`
+	RunnerID      uuid.UUID `json:"runner_id"`
+	DistanceKm    float64   `json:"distance_km"`
+	StartedAt     time.Time `json:"started_at"`
+	CompletedAt   time.Time `json:"completed_at"`
+	WaypointCount int       `json:"waypoint_count"`
+	RouteHash     string    `json:"route_hash"`
+	KeyID         string    `json:"key_id"`
+	IssuedAt      time.Time `json:"issued_at"`
+}
+
+// TripCertificateDTO is a trip certificate. Payload is the exact JSON text that was
+// signed; Summary is the same document decoded.
+type TripCertificateDTO struct {
+	BookingID uuid.UUID              `json:"booking_id"`
+	KeyID     string                 `json:"key_id"`
+	Algorithm string                 `json:"algorithm"`
+	Payload   string                 `json:"payload"`
+	Signature string                 `json:"signature"`
+	Summary   TripCertificatePayload `json:"summary"`
+}
+
+// VerifyCertificateRequest asks whether a certificate is authentic.
+type VerifyCertificateRequest struct {
+	KeyID     string `json:"key_id" binding:"required"`
+	Payload   string `json:"payload" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
+
+// CertificateVerificationDTO is the result of verifying a certificate. When the
+// signature is valid, MatchesCurrentData tells whether the trip's stored data still
+// matches the certificate, and Mismatches lists the fields that differ.
+type CertificateVerificationDTO struct {
+	Valid              bool                    `json:"valid"`
+	Summary            *TripCertificatePayload `json:"summary,omitempty"`
+	MatchesCurrentData *bool                   `json:"matches_current_data,omitempty"`
+	Mismatches         []string                `json:"mismatches,omitempty"`
+}
+
+// CertificationService signs the summaries of completed trips and verifies them later.
+type CertificationService struct {
+	repo         certificateDomain.Repository
+	trackingRepo trackingDomain.TripTrackRepository
+	signer       *certify.Signer
+	logger       *zap.Logger
+}
+
+// NewCertificationService creates a new CertificationService.
+func NewCertificationService(
+	repo certificateDomain.Repository,
+	trackingRepo trackingDomain.TripTrackRepository,
+	signer *certify.Signer,
+	logger *zap.Logger,
+) *CertificationService {
+	return &CertificationService{
+		repo:         repo,
+		trackingRepo: trackingRepo,
+		signer:       signer,
+		logger:       logger,
+	}
+}
+
+// Issue signs and stores the certificate of a just-completed trip. Failures are logged;
+// they never fail the completion.
+func (s *CertificationService) Issue(ctx context.Context, track *trackingDomain.TripTrack) {
+	if err := s.issue(ctx, track); err != nil {
+		s.logger.Error("failed to issue trip certificate",
+			zap.String("booking_id", track.BookingID().String()),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *CertificationService) issue(ctx context.Context, track *trackingDomain.TripTrack) error {
+	if track.CompletedAt() == nil {
+		return fmt.Errorf("trip is %s", track.Status())
+	}
+	waypoints, err := s.trackingRepo.GetWaypoints(ctx, track.ID())
+	if err != nil {
+		return fmt.Errorf("failed to get waypoints: %w", err)
+	}
+
+	now := time.Now().UTC()
+	payload, err := json.Marshal(TripCertificatePayload{
+		Version:       certificateVersion,
+		TrackID:       track.ID(),
+		BookingID:     track.BookingID(),
+		RunnerID:      track.RunnerID(),
+		DistanceKm:    math.Round(track.TotalDistanceKm()*1000) / 1000,
+		StartedAt:     track.StartedAt().UTC(),
+		CompletedAt:   track.CompletedAt().UTC(),
+		WaypointCount: len(waypoints),
+		RouteHash:     routeHash(waypoints),
+		KeyID:         s.signer.KeyID(),
+		IssuedAt:      now,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Save(ctx, &certificateDomain.Certificate{
+		TrackID:   track.ID(),
+		BookingID: track.BookingID(),
+		KeyID:     s.signer.KeyID(),
+		Algorithm: certify.Algorithm,
+		Payload:   payload,
+		Signature: s.signer.Sign(payload),
+		IssuedAt:  now,
+	})
+}
+
+// GetCertificate returns the certificate of a booking's completed trip.
+func (s *CertificationService) GetCertificate(ctx context.Context, bookingID uuid.UUID) (*TripCertificateDTO, error) {
+	c, err := s.repo.FindByBookingID(ctx, bookingID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, apperror.New(apperror.CodeNotFound, "no certificate for booking %s", bookingID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find certificate: %w", err)
+	}
+
+	dto := &TripCertificateDTO{
+		BookingID: c.BookingID,
+		KeyID:     c.KeyID,
+		Algorithm: c.Algorithm,
+		Payload:   string(c.Payload),
+		Signature: c.Signature,
+	}
+	if err := json.Unmarshal(c.Payload, &dto.Summary); err != nil {
+		return nil, fmt.Errorf("failed to decode certificate: %w", err)
+	}
+	return dto, nil
+}
+
+// Verify checks a certificate's signature and, if it is valid, compares it with the
+// trip's current data.
+func (s *CertificationService) Verify(ctx context.Context, req VerifyCertificateRequest) (*CertificateVerificationDTO, error) {
+	valid, err := s.signer.Verify(req.KeyID, []byte(req.Payload), req.Signature)
+	if errors.Is(err, certify.ErrUnknownKey) {
+		return nil, apperror.New(apperror.CodeValidation, "unknown key %q", req.KeyID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return &CertificateVerificationDTO{Valid: false}, nil
+	}
+
+	var summary TripCertificatePayload
+	if err := json.Unmarshal([]byte(req.Payload), &summary); err != nil {
+		return nil, apperror.New(apperror.CodeValidation, "payload is not a trip certificate")
+	}
+	result := &CertificateVerificationDTO{Valid: true, Summary: &summary}
+
+	track, err := s.trackingRepo.FindByID(ctx, summary.TrackID)
+	if errors.Is(err, domain.ErrNotFound) {
+		// The trip no longer exists, so nothing can match.
+		matches := false
+		result.MatchesCurrentData, result.Mismatches = &matches, []string{"track_id"}
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trip: %w", err)
+	}
+	waypoints, err := s.trackingRepo.GetWaypoints(ctx, track.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waypoints: %w", err)
+	}
+
+	mismatches := []string{}
+	if track.BookingID() != summary.BookingID {
+		mismatches = append(mismatches, "booking_id")
+	}
+	if track.RunnerID() != summary.RunnerID {
+		mismatches = append(mismatches, "runner_id")
+	}
+	if math.Abs(track.TotalDistanceKm()-summary.DistanceKm) > certificateDistanceTolerance {
+		mismatches = append(mismatches, "distance_km")
+	}
+	if absDuration(track.StartedAt().Sub(summary.StartedAt)) > certificateTimeTolerance {
+		mismatches = append(mismatches, "started_at")
+	}
+	if at := track.CompletedAt(); at == nil || absDuration(at.Sub(summary.CompletedAt)) > certificateTimeTolerance {
+		mismatches = append(mismatches, "completed_at")
+	}
+	if len(waypoints) != summary.WaypointCount {
+		mismatches = append(mismatches, "waypoint_count")
+	}
+	if routeHash(waypoints) != summary.RouteHash {
+		mismatches = append(mismatches, "route_hash")
+	}
+
+	matches := len(mismatches) == 0
+	result.MatchesCurrentData = &matches
+	if !matches {
+		result.Mismatches = mismatches
+	}
+	return result, nil
+}
+
+// PublicKeys returns the keys certificates can be verified with offline.
+func (s *CertificationService) PublicKeys() []certify.PublicKey {
+	return s.signer.PublicKeys()
+}
+
+// routeHash returns the hash of a trip's route as described on TripCertificatePayload.
+func routeHash(waypoints []trackingDomain.Waypoint) string {
+	h := sha256.New()
+	for _, wp := range waypoints {
+		fmt.Fprintf(h, "%s,%s,%s\n",
+			wp.RecordedAt.UTC().Format(time.RFC3339Nano),
+			strconv.FormatFloat(wp.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(wp.Longitude, 'f', -1, 64),
+		)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
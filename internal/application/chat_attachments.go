@@ -0,0 +1,132 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	chatDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/chat"
+)
+
+// thumbnailMimeType is the type of the thumbnails clients upload for image attachments.
+const thumbnailMimeType = "image/jpeg"
+
+// AttachmentPresigner issues presigned object storage URLs. It is satisfied by
+// objectstore.Presigner.
+type AttachmentPresigner interface {
+	PresignPut(key, contentType string, expiry time.Duration) string
+	PresignGet(key string, expiry time.Duration) string
+}
+
+// CreateAttachmentUploadRequest describes a file a chat participant is about to upload.
+type CreateAttachmentUploadRequest struct {
+	MimeType  string `json:"mime_type" binding:"required"`
+	SizeBytes int64  `json:"size_bytes" binding:"required"`
+}
+
+// AttachmentUploadDTO tells the client where to upload an attachment. The file is sent
+// with an HTTP PUT to UploadURL with the given headers; images also get a URL for their
+// thumbnail. The keys are then sent as the message's attachment.
+type AttachmentUploadDTO struct {
+	Key                string            `json:"key"`
+	UploadURL          string            `json:"upload_url"`
+	Method             string            `json:"method"`
+	Headers            map[string]string `json:"headers"`
+	ThumbnailKey       string            `json:"thumbnail_key,omitempty"`
+	ThumbnailUploadURL string            `json:"thumbnail_upload_url,omitempty"`
+	ThumbnailHeaders   map[string]string `json:"thumbnail_headers,omitempty"`
+	ExpiresAt          time.Time         `json:"expires_at"`
+}
+
+// UseAttachmentStorage enables presigned attachment uploads. Upload URLs are valid for
+// uploadTTL and download URLs, issued whenever a message is served, for downloadTTL.
+func (s *ChatService) UseAttachmentStorage(p AttachmentPresigner, uploadTTL, downloadTTL time.Duration) {
+	s.storage = p
+	s.uploadTTL = uploadTTL
+	s.downloadTTL = downloadTTL
+}
+
+// CreateAttachmentUpload checks a file against the attachment limits and returns where
+// to upload it. Nothing is stored until a message references the returned keys.
+func (s *ChatService) CreateAttachmentUpload(ctx context.Context, bookingID, senderID uuid.UUID, req CreateAttachmentUploadRequest) (*AttachmentUploadDTO, error) {
+	if s.storage == nil {
+		return nil, apperror.New(apperror.CodeNotFound, "attachment uploads are not enabled")
+	}
+	if err := s.policy.validateAttachment(req.MimeType, req.SizeBytes); err != nil {
+		return nil, err
+	}
+
+	mimeType := strings.ToLower(strings.TrimSpace(req.MimeType))
+	key, thumbnailKey := attachmentKeys(bookingID, s.ids.NewID())
+	result := &AttachmentUploadDTO{
+		Key:       key,
+		UploadURL: s.storage.PresignPut(key, mimeType, s.uploadTTL),
+		Method:    "PUT",
+		Headers:   map[string]string{"Content-Type": mimeType},
+		ExpiresAt: s.clock.Now().UTC().Add(s.uploadTTL),
+	}
+	if strings.HasPrefix(mimeType, "image/") {
+		result.ThumbnailKey = thumbnailKey
+		result.ThumbnailUploadURL = s.storage.PresignPut(thumbnailKey, thumbnailMimeType, s.uploadTTL)
+		result.ThumbnailHeaders = map[string]string{"Content-Type": thumbnailMimeType}
+	}
+
+	s.logger.Info("chat attachment upload issued",
+		zap.String("booking_id", bookingID.String()),
+		zap.String("sender_id", senderID.String()),
+		zap.String("mime_type", mimeType),
+		zap.Int64("size_bytes", req.SizeBytes),
+	)
+	return result, nil
+}
+
+// attachmentKeys returns the object keys of an attachment and its thumbnail.
+func attachmentKeys(bookingID, attachmentID uuid.UUID) (string, string) {
+	prefix := fmt.Sprintf("chat/%s/%s/", bookingID, attachmentID)
+	return prefix + "original", prefix + "thumbnail"
+}
+
+// checkAttachmentKeys verifies that an attachment's keys were issued for the booking,
+// so a message cannot reference another booking's files.
+func (s *ChatService) checkAttachmentKeys(bookingID uuid.UUID, a AttachmentDTO) error {
+	if a.Key == "" {
+		if a.ThumbnailKey != "" {
+			return apperror.New(apperror.CodeValidation, "thumbnail_key requires key")
+		}
+		return nil
+	}
+	if s.storage == nil {
+		return apperror.New(apperror.CodeValidation, "attachment uploads are not enabled")
+	}
+
+	parts := strings.Split(a.Key, "/")
+	if len(parts) != 4 || parts[0] != "chat" || parts[1] != bookingID.String() || parts[3] != "original" {
+		return apperror.New(apperror.CodeValidation, "attachment key %q was not issued for booking %s", a.Key, bookingID)
+	}
+	attachmentID, err := uuid.Parse(parts[2])
+	if err != nil {
+		return apperror.New(apperror.CodeValidation, "attachment key %q was not issued for booking %s", a.Key, bookingID)
+	}
+	if _, thumbnailKey := attachmentKeys(bookingID, attachmentID); a.ThumbnailKey != "" && a.ThumbnailKey != thumbnailKey {
+		return apperror.New(apperror.CodeValidation, "thumbnail_key does not belong to attachment %q", a.Key)
+	}
+	return nil
+}
+
+// attachmentURLs returns the URLs an attachment is served with: its own URL, or fresh
+// download URLs for uploaded attachments.
+func (s *ChatService) attachmentURLs(a chatDomain.Attachment) (string, string) {
+	if a.Key == "" || s.storage == nil {
+		return a.URL, ""
+	}
+	var thumbnailURL string
+	if a.ThumbnailKey != "" {
+		thumbnailURL = s.storage.PresignGet(a.ThumbnailKey, s.downloadTTL)
+	}
+	return s.storage.PresignGet(a.Key, s.downloadTTL), thumbnailURL
+}
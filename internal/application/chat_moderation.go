@@ -0,0 +1,150 @@
+package application
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	chatDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/chat"
+)
+
+// Replacements for masked contact details.
+const (
+	maskedPhoneNumber = "[phone hidden]"
+	maskedEmail       = "[email hidden]"
+)
+
+// Phone numbers are runs of digits with common separators; a run counts when it has
+// between minPhoneDigits and maxPhoneDigits digits, so times, prices and order numbers
+// are left alone.
+const (
+	minPhoneDigits = 9
+	maxPhoneDigits = 15
+)
+
+var (
+	emailPattern          = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phoneCandidatePattern = regexp.MustCompile(`\+?\(?\d[\d\s().-]{6,}\d`)
+)
+
+// ContentModerator inspects the content of an outgoing chat message written in locale
+// and returns it with anything objectionable masked, and a flag for each kind of
+// change made. Moderators run in the order they were added.
+type ContentModerator interface {
+	Moderate(content, locale string) (string, []chatDomain.ModerationFlag)
+}
+
+// AddModerator appends a moderator to the pipeline chat content passes through before
+// it is stored.
+func (s *ChatService) AddModerator(m ContentModerator) {
+	s.moderators = append(s.moderators, m)
+}
+
+// moderate runs content through the moderation pipeline.
+func (s *ChatService) moderate(content, locale string) (string, []chatDomain.ModerationFlag) {
+	var flags []chatDomain.ModerationFlag
+	for _, m := range s.moderators {
+		var found []chatDomain.ModerationFlag
+		content, found = m.Moderate(content, locale)
+		flags = append(flags, found...)
+	}
+	return content, flags
+}
+
+// PIIMasker masks phone numbers and email addresses, so participants keep talking
+// through the booking chat rather than exchanging contact details.
+type PIIMasker struct{}
+
+// NewPIIMasker creates a PIIMasker.
+func NewPIIMasker() *PIIMasker {
+	return &PIIMasker{}
+}
+
+// Moderate masks the phone numbers and email addresses in content.
+func (PIIMasker) Moderate(content, _ string) (string, []chatDomain.ModerationFlag) {
+	var flags []chatDomain.ModerationFlag
+
+	emails := 0
+	content = emailPattern.ReplaceAllStringFunc(content, func(string) string {
+		emails++
+		return maskedEmail
+	})
+	if emails > 0 {
+		flags = append(flags, chatDomain.ModerationFlag{Kind: chatDomain.FlagEmail, Count: emails})
+	}
+
+	phones := 0
+	content = phoneCandidatePattern.ReplaceAllStringFunc(content, func(match string) string {
+		digits := 0
+		for _, r := range match {
+			if unicode.IsDigit(r) {
+				digits++
+			}
+		}
+		if digits < minPhoneDigits || digits > maxPhoneDigits {
+			return match
+		}
+		phones++
+		return maskedPhoneNumber
+	})
+	if phones > 0 {
+		flags = append(flags, chatDomain.ModerationFlag{Kind: chatDomain.FlagPhoneNumber, Count: phones})
+	}
+	return content, flags
+}
+
+// ProfanityFilter replaces listed words with asterisks. Each locale has its own word
+// list; messages in a locale without one are checked against the default locale's.
+type ProfanityFilter struct {
+	patterns      map[string]*regexp.Regexp
+	defaultLocale string
+}
+
+// NewProfanityFilter creates a ProfanityFilter from word lists keyed by locale. Words
+// match whole and case-insensitively.
+func NewProfanityFilter(words map[string][]string, defaultLocale string) *ProfanityFilter {
+	f := &ProfanityFilter{patterns: make(map[string]*regexp.Regexp), defaultLocale: normalizeLocale(defaultLocale)}
+	for locale, list := range words {
+		quoted := make([]string, 0, len(list))
+		for _, w := range list {
+			if w = strings.TrimSpace(w); w != "" {
+				quoted = append(quoted, regexp.QuoteMeta(w))
+			}
+		}
+		if len(quoted) > 0 {
+			f.patterns[normalizeLocale(locale)] = regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+		}
+	}
+	return f
+}
+
+// Moderate masks the listed words of locale in content.
+func (f *ProfanityFilter) Moderate(content, locale string) (string, []chatDomain.ModerationFlag) {
+	locale = normalizeLocale(locale)
+	pattern, ok := f.patterns[locale]
+	if !ok {
+		locale = f.defaultLocale
+		if pattern, ok = f.patterns[locale]; !ok {
+			return content, nil
+		}
+	}
+
+	count := 0
+	content = pattern.ReplaceAllStringFunc(content, func(match string) string {
+		count++
+		return strings.Repeat("*", len([]rune(match)))
+	})
+	if count == 0 {
+		return content, nil
+	}
+	return content, []chatDomain.ModerationFlag{{Kind: chatDomain.FlagProfanity, Count: count, Locale: locale}}
+}
+
+// normalizeLocale reduces a locale such as "id-ID" or "en_US" to its lower-case language.
+func normalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
@@ -0,0 +1,97 @@
+package application
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+)
+
+// ChatPolicy holds the limits enforced on outgoing chat messages.
+type ChatPolicy struct {
+	MaxContentLength     int // in characters
+	MaxAttachments       int
+	MaxAttachmentBytes   int64
+	AllowedMimeTypes     []string
+	MaxMessagesPerMinute int // per sender; 0 disables rate limiting
+}
+
+// validate checks the request against the content and attachment limits.
+func (p ChatPolicy) validate(req SendMessageRequest) error {
+	if n := utf8.RuneCountInString(req.Content); p.MaxContentLength > 0 && n > p.MaxContentLength {
+		return apperror.New(apperror.CodeContentTooLong, "content is %d characters, maximum is %d", n, p.MaxContentLength)
+	}
+	if len(req.Attachments) > p.MaxAttachments {
+		return apperror.New(apperror.CodeTooManyAttachments, "message has %d attachments, maximum is %d", len(req.Attachments), p.MaxAttachments)
+	}
+	for _, a := range req.Attachments {
+		if err := p.validateAttachment(a.MimeType, a.SizeBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAttachment checks an attachment's type and size against the limits.
+func (p ChatPolicy) validateAttachment(mimeType string, sizeBytes int64) error {
+	if !p.mimeTypeAllowed(mimeType) {
+		return apperror.New(apperror.CodeMimeTypeNotAllowed, "attachment type %q is not allowed", mimeType)
+	}
+	if p.MaxAttachmentBytes > 0 && sizeBytes > p.MaxAttachmentBytes {
+		return apperror.New(apperror.CodeAttachmentTooLarge, "attachment is %d bytes, maximum is %d", sizeBytes, p.MaxAttachmentBytes)
+	}
+	return nil
+}
+
+func (p ChatPolicy) mimeTypeAllowed(mimeType string) bool {
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	for _, allowed := range p.AllowedMimeTypes {
+		if strings.EqualFold(allowed, mimeType) {
+			return true
+		}
+	}
+	return false
+}
+
+// senderRateLimiter tracks message timestamps per sender over a sliding one-minute window.
+type senderRateLimiter struct {
+	limit int
+
+	mu   sync.Mutex
+	sent map[uuid.UUID][]time.Time
+}
+
+func newSenderRateLimiter(limit int) *senderRateLimiter {
+	return &senderRateLimiter{limit: limit, sent: make(map[uuid.UUID][]time.Time)}
+}
+
+// allow records a message for sender if it is within the limit, otherwise
+// returns how long until the oldest message in the window expires.
+func (l *senderRateLimiter) allow(sender uuid.UUID, now time.Time) (bool, time.Duration) {
+	if l.limit <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	windowStart := now.Add(-time.Minute)
+	recent := l.sent[sender][:0]
+	for _, t := range l.sent[sender] {
+		if t.After(windowStart) {
+			recent = append(recent, t)
+		}
+	}
+
+	if len(recent) >= l.limit {
+		l.sent[sender] = recent
+		return false, recent[0].Sub(windowStart)
+	}
+
+	l.sent[sender] = append(recent, now)
+	return true, 0
+}
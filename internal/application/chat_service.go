@@ -4,7 +4,10 @@ import (
 	"context"
 	"time"
 
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/clock"
 	chatDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/chat"
+	inboxDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/inbox"
 	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -13,43 +16,129 @@ import (
 // SendMessageRequest holds data to send a chat message.
 type SendMessageRequest struct {
 	MessageType string `json:"message_type" binding:"required"`
-	Content     string `json:"content" binding:"required"`
+	// Content is required except for image messages, where it is an optional caption.
+	Content     string          `json:"content"`
+	Attachments []AttachmentDTO `json:"attachments"`
+	// Locale is the language of Content, e.g. "id" or "en-US", used to pick the word
+	// list profanity is filtered with.
+	Locale string `json:"locale"`
+}
+
+// AttachmentDTO describes a media file attached to a chat message: an external URL, or
+// the keys of a file uploaded with CreateAttachmentUpload. Uploaded files are served
+// with download URLs that expire, and images with a ThumbnailURL.
+type AttachmentDTO struct {
+	URL          string `json:"url"`
+	Key          string `json:"key,omitempty"`
+	ThumbnailKey string `json:"thumbnail_key,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	MimeType     string `json:"mime_type" binding:"required"`
+	SizeBytes    int64  `json:"size_bytes"`
 }
 
 // ChatMessageDTO is the API response representation of a chat message.
 type ChatMessageDTO struct {
-	ID         uuid.UUID `json:"id"`
-	BookingID  uuid.UUID `json:"booking_id"`
-	SenderID   uuid.UUID `json:"sender_id"`
-	SenderRole string    `json:"sender_role"`
-	MsgType    string    `json:"message_type"`
-	Content    string    `json:"content"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID          uuid.UUID       `json:"id"`
+	BookingID   uuid.UUID       `json:"booking_id"`
+	SenderID    uuid.UUID       `json:"sender_id"`
+	SenderRole  string          `json:"sender_role"`
+	MsgType     string          `json:"message_type"`
+	Content     string          `json:"content"`
+	Attachments []AttachmentDTO `json:"attachments"`
+	// ModerationFlags lists what moderation masked in Content before it was stored.
+	ModerationFlags []chatDomain.ModerationFlag `json:"moderation_flags,omitempty"`
+	CreatedAt       time.Time                   `json:"created_at"`
 }
 
 // ChatService handles chat use cases.
 type ChatService struct {
-	repo   chatDomain.ChatRepository
-	hub    *ws.Hub
-	logger *zap.Logger
+	repo    chatDomain.ChatRepository
+	hub     *ws.Hub
+	policy  ChatPolicy
+	limiter *senderRateLimiter
+	inbox   *InboxService
+	logger  *zap.Logger
+	clock   clock.Clock
+	ids     clock.IDGenerator
+
+	moderators []ContentModerator
+	push       *PushFanout
+
+	storage     AttachmentPresigner
+	uploadTTL   time.Duration
+	downloadTTL time.Duration
+}
+
+// NewChatService creates a new ChatService enforcing the given policy.
+func NewChatService(repo chatDomain.ChatRepository, hub *ws.Hub, policy ChatPolicy, logger *zap.Logger) *ChatService {
+	return &ChatService{
+		repo:    repo,
+		hub:     hub,
+		policy:  policy,
+		limiter: newSenderRateLimiter(policy.MaxMessagesPerMinute),
+		logger:  logger,
+		clock:   clock.System,
+		ids:     clock.RandomIDs,
+	}
+}
+
+// UseClock replaces the wall clock, e.g. with a clock.Manual in tests.
+func (s *ChatService) UseClock(c clock.Clock) {
+	s.clock = c
+}
+
+// UseIDGenerator replaces the random ID generator for new messages.
+func (s *ChatService) UseIDGenerator(g clock.IDGenerator) {
+	s.ids = g
+}
+
+// UseInbox records sent messages in the recipients' inboxes for offline catch-up.
+func (s *ChatService) UseInbox(inbox *InboxService) {
+	s.inbox = inbox
 }
 
-// NewChatService creates a new ChatService.
-func NewChatService(repo chatDomain.ChatRepository, hub *ws.Hub, logger *zap.Logger) *ChatService {
-	return &ChatService{repo: repo, hub: hub, logger: logger}
+// UsePushFanout requests mobile pushes of sent messages for offline participants.
+func (s *ChatService) UsePushFanout(f *PushFanout) {
+	s.push = f
 }
 
 // SendMessage persists a chat message and broadcasts it via WebSocket.
 func (s *ChatService) SendMessage(ctx context.Context, bookingID, senderID uuid.UUID, senderRole string, req SendMessageRequest) (*ChatMessageDTO, error) {
+	if err := s.policy.validate(req); err != nil {
+		return nil, err
+	}
+
+	attachments := make([]chatDomain.Attachment, len(req.Attachments))
+	for i, a := range req.Attachments {
+		if err := s.checkAttachmentKeys(bookingID, a); err != nil {
+			return nil, err
+		}
+		attachments[i] = chatDomain.Attachment{URL: a.URL, Key: a.Key, ThumbnailKey: a.ThumbnailKey, MimeType: a.MimeType, SizeBytes: a.SizeBytes}
+		if a.Key != "" {
+			attachments[i].URL = ""
+		}
+	}
+
+	content, flags := s.moderate(req.Content, req.Locale)
+
+	now := s.clock.Now()
 	msg, err := chatDomain.NewChatMessage(
+		s.ids.NewID(),
 		bookingID,
 		senderID,
 		senderRole,
 		chatDomain.MessageType(req.MessageType),
-		req.Content,
+		content,
+		attachments,
+		flags,
+		now,
 	)
 	if err != nil {
-		return nil, err
+		return nil, apperror.Wrap(apperror.CodeValidation, err)
+	}
+	if ok, retryAfter := s.limiter.allow(senderID, now); !ok {
+		return nil, apperror.New(apperror.CodeRateLimited, "at most %d messages per minute", s.policy.MaxMessagesPerMinute).
+			WithRetryAfter(retryAfter)
 	}
 
 	if err := s.repo.Save(ctx, msg); err != nil {
@@ -58,22 +147,48 @@ func (s *ChatService) SendMessage(ctx context.Context, bookingID, senderID uuid.
 
 	// Broadcast to WebSocket room
 	s.hub.BroadcastChat(&ws.ChatMessage{
-		Type:       "chat_message",
-		BookingID:  bookingID,
-		MessageID:  msg.ID(),
-		SenderID:   senderID,
-		SenderRole: senderRole,
-		MsgType:    string(msg.MessageType()),
-		Content:    msg.Content(),
-		CreatedAt:  msg.CreatedAt(),
+		Type:        "chat_message",
+		BookingID:   bookingID,
+		MessageID:   msg.ID(),
+		SenderID:    senderID,
+		SenderRole:  senderRole,
+		MsgType:     string(msg.MessageType()),
+		Content:     msg.Content(),
+		Attachments: s.toWSAttachments(msg.Attachments()),
+		CreatedAt:   msg.CreatedAt(),
 	})
 
+	dto := s.toChatDTO(msg)
+	if s.inbox != nil {
+		if err := s.inbox.Record(ctx, bookingID, senderID, inboxDomain.KindChat, "chat_message", msg.ID(), dto); err != nil {
+			s.logger.Error("failed to record chat message in inbox",
+				zap.String("booking_id", bookingID.String()),
+				zap.String("message_id", msg.ID().String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	if s.push != nil {
+		body := msg.Content()
+		if body == "" {
+			body = "Sent a photo"
+		}
+		s.push.NotifyOffline(ctx, bookingID, senderID, PushNotification{
+			Kind:  PushKindChatMessage,
+			Title: "New message",
+			Body:  pushBody(body),
+			Data:  map[string]string{"booking_id": bookingID.String(), "message_id": msg.ID().String()},
+		})
+	}
+
 	s.logger.Info("chat message sent",
 		zap.String("booking_id", bookingID.String()),
 		zap.String("sender_role", senderRole),
+		zap.Int("moderation_flags", len(flags)),
 	)
 
-	return toChatDTO(msg), nil
+	return dto, nil
 }
 
 // GetMessages returns paginated chat history for a booking.
@@ -86,19 +201,46 @@ func (s *ChatService) GetMessages(ctx context.Context, bookingID uuid.UUID, page
 
 	dtos := make([]*ChatMessageDTO, len(messages))
 	for i, m := range messages {
-		dtos[i] = toChatDTO(m)
+		dtos[i] = s.toChatDTO(m)
 	}
 	return dtos, total, nil
 }
 
-func toChatDTO(m *chatDomain.ChatMessage) *ChatMessageDTO {
+func (s *ChatService) toChatDTO(m *chatDomain.ChatMessage) *ChatMessageDTO {
 	return &ChatMessageDTO{
-		ID:         m.ID(),
-		BookingID:  m.BookingID(),
-		SenderID:   m.SenderID(),
-		SenderRole: m.SenderRole(),
-		MsgType:    string(m.MessageType()),
-		Content:    m.Content(),
-		CreatedAt:  m.CreatedAt(),
+		ID:              m.ID(),
+		BookingID:       m.BookingID(),
+		SenderID:        m.SenderID(),
+		SenderRole:      m.SenderRole(),
+		MsgType:         string(m.MessageType()),
+		Content:         m.Content(),
+		Attachments:     s.toAttachmentDTOs(m.Attachments()),
+		ModerationFlags: m.ModerationFlags(),
+		CreatedAt:       m.CreatedAt(),
+	}
+}
+
+func (s *ChatService) toAttachmentDTOs(attachments []chatDomain.Attachment) []AttachmentDTO {
+	dtos := make([]AttachmentDTO, len(attachments))
+	for i, a := range attachments {
+		url, thumbnailURL := s.attachmentURLs(a)
+		dtos[i] = AttachmentDTO{
+			URL:          url,
+			Key:          a.Key,
+			ThumbnailKey: a.ThumbnailKey,
+			ThumbnailURL: thumbnailURL,
+			MimeType:     a.MimeType,
+			SizeBytes:    a.SizeBytes,
+		}
+	}
+	return dtos
+}
+
+func (s *ChatService) toWSAttachments(attachments []chatDomain.Attachment) []ws.ChatAttachment {
+	out := make([]ws.ChatAttachment, len(attachments))
+	for i, a := range attachments {
+		url, thumbnailURL := s.attachmentURLs(a)
+		out[i] = ws.ChatAttachment{URL: url, ThumbnailURL: thumbnailURL, MimeType: a.MimeType, SizeBytes: a.SizeBytes}
 	}
+	return out
 }
@@ -0,0 +1,135 @@
+package application
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	chatDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/chat"
+	inboxDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/inbox"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+// Milestone is a point in a delivery that is announced in the booking's chat.
+type Milestone string
+
+const (
+	MilestoneTrackingStarted  Milestone = "tracking_started"
+	MilestoneArrivedAtPickup  Milestone = "arrived_at_pickup"
+	MilestoneDeliveryComplete Milestone = "delivery_completed"
+)
+
+// milestoneContent is the text of the system message posted for each milestone.
+var milestoneContent = map[Milestone]string{
+	MilestoneTrackingStarted:  "Your runner is on the way. Live tracking has started.",
+	MilestoneArrivedAtPickup:  "Your runner has arrived at the pickup point.",
+	MilestoneDeliveryComplete: "Delivery completed. Thank you for choosing Kilat!",
+}
+
+// ChatTimeline posts system messages into a booking's chat as its delivery reaches
+// milestones, so the thread doubles as a delivery timeline. Each milestone is posted
+// at most once per booking while this instance tracks it.
+type ChatTimeline struct {
+	chat   *ChatService
+	logger *zap.Logger
+
+	mu     sync.Mutex
+	posted map[uuid.UUID]map[Milestone]bool
+}
+
+// NewChatTimeline creates a ChatTimeline posting through chat.
+func NewChatTimeline(chat *ChatService, logger *zap.Logger) *ChatTimeline {
+	return &ChatTimeline{chat: chat, logger: logger, posted: make(map[uuid.UUID]map[Milestone]bool)}
+}
+
+// Post announces a milestone in the booking's chat. Failures are logged, not returned,
+// since the timeline never holds up the tracking flow that reached the milestone.
+func (t *ChatTimeline) Post(ctx context.Context, bookingID uuid.UUID, milestone Milestone) {
+	if !t.claim(bookingID, milestone) {
+		return
+	}
+	if _, err := t.chat.PostSystemMessage(ctx, bookingID, milestoneContent[milestone]); err != nil {
+		t.release(bookingID, milestone)
+		t.logger.Error("failed to post chat timeline message",
+			zap.String("booking_id", bookingID.String()),
+			zap.String("milestone", string(milestone)),
+			zap.Error(err),
+		)
+	}
+}
+
+// Forget drops the milestones remembered for a booking once its trip has ended.
+func (t *ChatTimeline) Forget(bookingID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.posted, bookingID)
+}
+
+func (t *ChatTimeline) claim(bookingID uuid.UUID, milestone Milestone) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	posted := t.posted[bookingID]
+	if posted == nil {
+		posted = make(map[Milestone]bool)
+		t.posted[bookingID] = posted
+	}
+	if posted[milestone] {
+		return false
+	}
+	posted[milestone] = true
+	return true
+}
+
+func (t *ChatTimeline) release(bookingID uuid.UUID, milestone Milestone) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.posted[bookingID], milestone)
+}
+
+// PostSystemMessage stores and broadcasts a text message from the service itself, with
+// sender role "system" and a nil sender ID. It bypasses the policy, moderation and rate
+// limits that apply to participants' messages.
+func (s *ChatService) PostSystemMessage(ctx context.Context, bookingID uuid.UUID, content string) (*ChatMessageDTO, error) {
+	msg, err := chatDomain.NewChatMessage(
+		s.ids.NewID(),
+		bookingID,
+		uuid.Nil,
+		chatDomain.SenderRoleSystem,
+		chatDomain.MessageTypeText,
+		content,
+		nil,
+		nil,
+		s.clock.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	s.hub.BroadcastChat(&ws.ChatMessage{
+		Type:       "chat_message",
+		BookingID:  bookingID,
+		MessageID:  msg.ID(),
+		SenderID:   uuid.Nil,
+		SenderRole: chatDomain.SenderRoleSystem,
+		MsgType:    string(msg.MessageType()),
+		Content:    msg.Content(),
+		CreatedAt:  msg.CreatedAt(),
+	})
+
+	dto := s.toChatDTO(msg)
+	if s.inbox != nil {
+		if err := s.inbox.Record(ctx, bookingID, uuid.Nil, inboxDomain.KindChat, "chat_message", msg.ID(), dto); err != nil {
+			s.logger.Error("failed to record system chat message in inbox",
+				zap.String("booking_id", bookingID.String()),
+				zap.String("message_id", msg.ID().String()),
+				zap.Error(err),
+			)
+		}
+	}
+	return dto, nil
+}
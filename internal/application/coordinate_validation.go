@@ -0,0 +1,131 @@
+package application
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// Reasons a location fix fails coordinate validation.
+const (
+	coordinateOutOfRange    = "out_of_range"
+	coordinateNullIsland    = "null_island"
+	coordinateOutsideRegion = "outside_region"
+	coordinateLowAccuracy   = "low_accuracy"
+)
+
+// Sources of location fixes, used as a metrics label.
+const (
+	sourceKafka     = "kafka"
+	sourceREST      = "rest"
+	sourceWebSocket = "websocket"
+)
+
+// CoordinateValidator rejects location fixes that are in range but cannot be real: "null
+// island" (0,0) fixes and fixes outside the bounding box of the trip's deployment region.
+// It also rejects fixes whose reported accuracy is worse than a limit, if one is set. It
+// counts rejections by region, source and reason.
+type CoordinateValidator struct {
+	regionBounds  map[string]trackingDomain.BoundingBox
+	defaultBounds *trackingDomain.BoundingBox
+	maxAccuracyM  float64
+
+	mu         sync.Mutex
+	rejections map[coordinateRejection]uint64
+}
+
+// coordinateRejection identifies one rejection counter.
+type coordinateRejection struct {
+	region, source, reason string
+}
+
+// NewCoordinateValidator creates a validator. Trips in a region listed in regionBounds
+// must stay within its box; trips in any other region, or without one, must stay within
+// defaultBounds unless it is nil.
+func NewCoordinateValidator(regionBounds map[string]trackingDomain.BoundingBox, defaultBounds *trackingDomain.BoundingBox) *CoordinateValidator {
+	return &CoordinateValidator{
+		regionBounds:  regionBounds,
+		defaultBounds: defaultBounds,
+		rejections:    make(map[coordinateRejection]uint64),
+	}
+}
+
+// UseMaxAccuracy rejects fixes that report a horizontal accuracy worse than meters.
+// Fixes that do not report their accuracy are accepted.
+func (v *CoordinateValidator) UseMaxAccuracy(meters float64) {
+	v.maxAccuracyM = meters
+}
+
+// Validate returns the reason a fix for a trip in region is rejected, or "" if it is
+// accepted. accuracy is the fix's reported accuracy in meters, or 0 if unknown.
+// Rejections are counted under source.
+func (v *CoordinateValidator) Validate(region, source string, lat, lng, accuracy float64) string {
+	if v == nil {
+		return ""
+	}
+
+	reason := ""
+	switch {
+	case lat < -90 || lat > 90 || lng < -180 || lng > 180:
+		reason = coordinateOutOfRange
+	case trackingDomain.IsNullIsland(lat, lng):
+		reason = coordinateNullIsland
+	case v.maxAccuracyM > 0 && accuracy > v.maxAccuracyM:
+		reason = coordinateLowAccuracy
+	default:
+		if box, ok := v.bounds(region); ok && !box.Contains(lat, lng) {
+			reason = coordinateOutsideRegion
+		}
+	}
+
+	if reason != "" {
+		v.mu.Lock()
+		v.rejections[coordinateRejection{region, source, reason}]++
+		v.mu.Unlock()
+	}
+	return reason
+}
+
+// bounds returns the box fixes for a trip in region must lie within, if any.
+func (v *CoordinateValidator) bounds(region string) (trackingDomain.BoundingBox, bool) {
+	if box, ok := v.regionBounds[region]; ok {
+		return box, true
+	}
+	if v.defaultBounds != nil {
+		return *v.defaultBounds, true
+	}
+	return trackingDomain.BoundingBox{}, false
+}
+
+// ServeHTTP writes the coordinate rejection counts in the Prometheus text exposition format.
+func (v *CoordinateValidator) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	v.mu.Lock()
+	keys := make([]coordinateRejection, 0, len(v.rejections))
+	counts := make(map[coordinateRejection]uint64, len(v.rejections))
+	for k, n := range v.rejections {
+		keys = append(keys, k)
+		counts[k] = n
+	}
+	v.mu.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.region != b.region {
+			return a.region < b.region
+		}
+		if a.source != b.source {
+			return a.source < b.source
+		}
+		return a.reason < b.reason
+	})
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP tracking_coordinates_rejected_total Location fixes rejected by coordinate validation.")
+	fmt.Fprintln(w, "# TYPE tracking_coordinates_rejected_total counter")
+	for _, k := range keys {
+		fmt.Fprintf(w, "tracking_coordinates_rejected_total{region=%q,source=%q,reason=%q} %d\n", k.region, k.source, k.reason, counts[k])
+	}
+}
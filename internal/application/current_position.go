@@ -0,0 +1,202 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+// CurrentPositionDTO is the last known position of a trip. Status and ETA are only set
+// by GetCurrentPosition; ETA is omitted unless the trip is active with a destination.
+type CurrentPositionDTO struct {
+	BookingID  uuid.UUID `json:"booking_id"`
+	RunnerID   uuid.UUID `json:"runner_id"`
+	Status     string    `json:"status,omitempty"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Speed      float64   `json:"speed_kmh"`
+	Heading    float64   `json:"heading_degrees"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Accuracy   float64   `json:"accuracy_m,omitempty"`
+	Altitude   *float64  `json:"altitude_m,omitempty"`
+	Provider   string    `json:"provider,omitempty"`
+	ETA        *ETADTO   `json:"eta,omitempty"`
+}
+
+// GetCurrentPosition returns the last known position, status and ETA for a booking, for
+// clients that poll instead of holding a socket. The position is served from the position
+// cache when available and falls back to the waypoints table, refilling the cache; the ETA
+// uses the live speed samples, so the trip's waypoints are not loaded on the cached path.
+func (s *TrackingService) GetCurrentPosition(ctx context.Context, bookingID uuid.UUID) (*CurrentPositionDTO, error) {
+	pos, err := s.latestPosition(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	return &CurrentPositionDTO{
+		BookingID:  pos.BookingID,
+		RunnerID:   pos.RunnerID,
+		Status:     string(track.Status()),
+		Latitude:   pos.Waypoint.Latitude,
+		Longitude:  pos.Waypoint.Longitude,
+		Speed:      pos.Waypoint.Speed,
+		Heading:    pos.Waypoint.Heading,
+		RecordedAt: pos.Waypoint.RecordedAt,
+		Accuracy:   pos.Waypoint.Accuracy,
+		Altitude:   pos.Waypoint.Altitude,
+		Provider:   pos.Waypoint.Provider,
+		ETA:        s.liveETA(ctx, track, pos.Waypoint),
+	}, nil
+}
+
+// HistoricalPositionDTO is a trip's position at a past moment, interpolated between the
+// waypoints recorded around it.
+type HistoricalPositionDTO struct {
+	BookingID    uuid.UUID `json:"booking_id"`
+	RunnerID     uuid.UUID `json:"runner_id"`
+	At           time.Time `json:"at"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	Speed        float64   `json:"speed_kmh"`
+	Heading      float64   `json:"heading_degrees"`
+	Interpolated bool      `json:"interpolated"`
+	BeforeAt     time.Time `json:"before_recorded_at"`
+	AfterAt      time.Time `json:"after_recorded_at"`
+}
+
+// GetPositionAt returns where a booking's runner was at the given moment, interpolated
+// between the nearest recorded waypoints. The bracketing waypoint times are included so
+// callers can judge how reliable the estimate is.
+func (s *TrackingService) GetPositionAt(ctx context.Context, bookingID uuid.UUID, at time.Time) (*HistoricalPositionDTO, error) {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	waypoints, err := s.repo.GetWaypointsBetween(ctx, track.ID(), at, at)
+	if err != nil {
+		return nil, err
+	}
+
+	pos, before, after, ok := trackingDomain.PositionAt(waypoints, at)
+	if !ok {
+		return nil, apperror.New(apperror.CodePositionUnknown, "no waypoints recorded around %s for booking %s", at.Format(time.RFC3339), bookingID)
+	}
+
+	return &HistoricalPositionDTO{
+		BookingID:    track.BookingID(),
+		RunnerID:     track.RunnerID(),
+		At:           at,
+		Latitude:     pos.Latitude,
+		Longitude:    pos.Longitude,
+		Speed:        pos.Speed,
+		Heading:      pos.Heading,
+		Interpolated: !before.RecordedAt.Equal(after.RecordedAt),
+		BeforeAt:     before.RecordedAt,
+		AfterAt:      after.RecordedAt,
+	}, nil
+}
+
+// Snapshot returns the current state of a booking's trip for a newly connected WebSocket
+// client: status, last known position and up to history recent waypoints. Returns nil if
+// the booking has no trip.
+func (s *TrackingService) Snapshot(ctx context.Context, bookingID uuid.UUID, history int) *ws.Snapshot {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil
+	}
+
+	snap := &ws.Snapshot{
+		BookingID:       track.BookingID(),
+		RunnerID:        track.RunnerID(),
+		Status:          string(track.Status()),
+		TotalDistanceKm: track.TotalDistanceKm(),
+	}
+
+	if history > 0 {
+		waypoints, err := s.repo.GetWaypoints(ctx, track.ID())
+		if err != nil {
+			s.logger.Warn("failed to load waypoints for snapshot", zap.Error(err))
+		} else {
+			if len(waypoints) > history {
+				waypoints = waypoints[len(waypoints)-history:]
+			}
+			snap.RecentWaypoints = make([]ws.Position, len(waypoints))
+			for i, wp := range waypoints {
+				snap.RecentWaypoints[i] = toPosition(wp)
+			}
+			snap.Viewport = viewportFor(track, waypoints)
+		}
+	}
+
+	if n := len(snap.RecentWaypoints); n > 0 {
+		last := snap.RecentWaypoints[n-1]
+		snap.LastPosition = &last
+	} else if pos, err := s.latestPosition(ctx, bookingID); err == nil {
+		last := toPosition(pos.Waypoint)
+		snap.LastPosition = &last
+	}
+
+	return snap
+}
+
+func toPosition(wp trackingDomain.Waypoint) ws.Position {
+	return ws.Position{
+		Latitude:  wp.Latitude,
+		Longitude: wp.Longitude,
+		Speed:     wp.Speed,
+		Heading:   wp.Heading,
+		Timestamp: wp.RecordedAt,
+	}
+}
+
+func (s *TrackingService) latestPosition(ctx context.Context, bookingID uuid.UUID) (*trackingDomain.LatestPosition, error) {
+	if s.positions != nil {
+		pos, err := s.positions.GetByBooking(ctx, bookingID)
+		if err == nil {
+			return pos, nil
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			s.logger.Warn("failed to read latest position from cache", zap.Error(err))
+		}
+	}
+
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	waypoints, err := s.repo.GetWaypoints(ctx, track.ID())
+	if err != nil {
+		return nil, err
+	}
+	if len(waypoints) == 0 {
+		return nil, apperror.New(apperror.CodePositionUnknown, "no position reported yet for booking %s", bookingID)
+	}
+
+	pos := &trackingDomain.LatestPosition{
+		BookingID: track.BookingID(),
+		RunnerID:  track.RunnerID(),
+		Waypoint:  waypoints[len(waypoints)-1],
+	}
+	// Only refill for active trips, so a finished trip cannot overwrite the runner's latest position.
+	if s.positions != nil && track.IsActive() {
+		if err := s.positions.Set(ctx, *pos); err != nil {
+			s.logger.Warn("failed to cache latest position", zap.Error(err))
+		}
+	}
+	return pos, nil
+}
@@ -0,0 +1,117 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+const (
+	// frameLowBattery is the WebSocket frame type warning that the runner's phone is
+	// running out of battery.
+	frameLowBattery = "low_battery"
+
+	// lowBatteryRearmPct is how far above the threshold the battery must recover, e.g.
+	// by charging, before another warning can be sent for the trip.
+	lowBatteryRearmPct = 5
+)
+
+// DeviceStatusDTO is the runner's phone status as last reported during a trip.
+type DeviceStatusDTO struct {
+	BatteryPct *int      `json:"battery_pct,omitempty"`
+	Network    string    `json:"network,omitempty"`
+	ReportedAt time.Time `json:"reported_at"`
+}
+
+// LowBatteryWarning is pushed over WebSocket when the runner's phone battery drops below
+// the warning threshold, so the customer knows updates may stop.
+type LowBatteryWarning struct {
+	BookingID    uuid.UUID `json:"booking_id"`
+	BatteryPct   int       `json:"battery_pct"`
+	ThresholdPct int       `json:"threshold_pct"`
+	Network      string    `json:"network,omitempty"`
+	ReportedAt   time.Time `json:"reported_at"`
+}
+
+// UseLowBatteryWarning warns a booking's room once when the runner's phone battery
+// drops below pct percent. Must be called before locations are consumed.
+func (s *TrackingService) UseLowBatteryWarning(pct int) {
+	s.lowBatteryPct = pct
+}
+
+// recordDeviceStatus stores the phone status carried by a runner location event, if
+// any, and warns the booking's room when the battery runs low. An invalid status is
+// logged and ignored; the location itself has been recorded already.
+func (s *TrackingService) recordDeviceStatus(ctx context.Context, track *trackingDomain.TripTrack, update RunnerLocationUpdate, reportedAt time.Time, historical bool) {
+	status, ok, err := trackingDomain.NewDeviceStatus(update.BatteryPct, update.Network, reportedAt)
+	if err != nil {
+		s.logger.Warn("invalid device status, ignoring",
+			zap.String("runner_id", track.RunnerID().String()),
+			zap.Error(err),
+		)
+		return
+	}
+	if !ok {
+		return
+	}
+	if err := s.repo.UpdateDeviceStatus(ctx, track.ID(), status); err != nil {
+		s.logger.Error("failed to update device status", zap.String("booking_id", track.BookingID().String()), zap.Error(err))
+		return
+	}
+	if !historical {
+		s.warnLowBattery(track, status)
+	}
+}
+
+// warnLowBattery pushes a low_battery frame the first time a trip's battery level drops
+// below the threshold, and again only after it has recovered.
+func (s *TrackingService) warnLowBattery(track *trackingDomain.TripTrack, status trackingDomain.DeviceStatus) {
+	if s.lowBatteryPct <= 0 || status.BatteryPct == nil {
+		return
+	}
+	battery := *status.BatteryPct
+
+	s.liveMu.Lock()
+	state := s.liveStateLocked(track.BookingID())
+	warn := battery < s.lowBatteryPct && !state.lowBatteryWarned
+	switch {
+	case warn:
+		state.lowBatteryWarned = true
+	case battery >= s.lowBatteryPct+lowBatteryRearmPct:
+		state.lowBatteryWarned = false
+	}
+	s.liveMu.Unlock()
+	if !warn {
+		return
+	}
+
+	s.logger.Info("runner battery low",
+		zap.String("booking_id", track.BookingID().String()),
+		zap.String("runner_id", track.RunnerID().String()),
+		zap.Int("battery_pct", battery),
+	)
+	s.hub.Notify(&ws.Notification{
+		BookingID: track.BookingID(),
+		Type:      frameLowBattery,
+		Data: LowBatteryWarning{
+			BookingID:    track.BookingID(),
+			BatteryPct:   battery,
+			ThresholdPct: s.lowBatteryPct,
+			Network:      status.Network,
+			ReportedAt:   status.ReportedAt,
+		},
+	})
+}
+
+// toDeviceStatusDTO converts a trip's device status, if any, to its API representation.
+func toDeviceStatusDTO(d *trackingDomain.DeviceStatus) *DeviceStatusDTO {
+	if d == nil {
+		return nil
+	}
+	return &DeviceStatusDTO{BatteryPct: d.BatteryPct, Network: d.Network, ReportedAt: d.ReportedAt}
+}
@@ -0,0 +1,307 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// eventDrivingLimitExceeded is the CloudEvent type published when a runner exceeds a driving limit.
+const eventDrivingLimitExceeded = "tracking.driving_limit_exceeded"
+
+// drivingWindow is the rolling period daily driving time is measured over.
+const drivingWindow = 24 * time.Hour
+
+// Driving limits a runner can exceed.
+const (
+	limitContinuousDriving = "continuous_driving"
+	limitDailyDriving      = "daily_driving"
+)
+
+// DrivingLimits configures runner break compliance.
+type DrivingLimits struct {
+	// MaxContinuous is the driving time allowed without a qualifying break.
+	MaxContinuous time.Duration
+	// MaxDaily is the driving time allowed in any rolling 24 hours.
+	MaxDaily time.Duration
+	// MinBreak is how long a runner must not drive, stopped within a trip or between
+	// trips, for the break to reset continuous driving.
+	MinBreak time.Duration
+	// CheckInterval is the minimum time between compliance checks per runner.
+	CheckInterval time.Duration
+}
+
+// DrivingLimitsDTO is the API representation of the configured driving limits.
+type DrivingLimitsDTO struct {
+	MaxContinuousSeconds float64 `json:"max_continuous_seconds"`
+	MaxDailySeconds      float64 `json:"max_daily_seconds"`
+	MinBreakSeconds      float64 `json:"min_break_seconds"`
+}
+
+// DrivingTimeDTO is a runner's driving time across their recent trips.
+type DrivingTimeDTO struct {
+	RunnerID                 uuid.UUID        `json:"runner_id"`
+	WindowStart              time.Time        `json:"window_start"`
+	WindowEnd                time.Time        `json:"window_end"`
+	Trips                    int              `json:"trips"`
+	DrivingSeconds           float64          `json:"driving_seconds"`
+	ContinuousDrivingSeconds float64          `json:"continuous_driving_seconds"`
+	OnBreak                  bool             `json:"on_break"`
+	LastBreakEndedAt         *time.Time       `json:"last_break_ended_at,omitempty"`
+	Limits                   DrivingLimitsDTO `json:"limits"`
+	Exceeded                 []string         `json:"exceeded"`
+}
+
+// DrivingLimitExceededEvent is published when a runner exceeds a driving limit.
+type DrivingLimitExceededEvent struct {
+	RunnerID       uuid.UUID `json:"runner_id"`
+	BookingID      uuid.UUID `json:"booking_id"`
+	Limit          string    `json:"limit"`
+	DrivingSeconds float64   `json:"driving_seconds"`
+	LimitSeconds   float64   `json:"limit_seconds"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// drivingInterval is a period a runner was driving.
+type drivingInterval struct {
+	start, end time.Time
+}
+
+// DrivingTimeService tracks cumulative driving time per runner across consecutive trips
+// and publishes compliance events when a runner exceeds a driving limit.
+type DrivingTimeService struct {
+	repo     trackingDomain.TripTrackRepository
+	producer EventPublisher
+	limits   DrivingLimits
+	logger   *zap.Logger
+
+	mu          sync.Mutex
+	lastChecked map[uuid.UUID]time.Time       // runnerID -> last compliance check
+	exceeded    map[uuid.UUID]map[string]bool // runnerID -> limits currently exceeded
+	lastSweep   time.Time
+}
+
+// NewDrivingTimeService creates a new DrivingTimeService.
+func NewDrivingTimeService(
+	repo trackingDomain.TripTrackRepository,
+	producer EventPublisher,
+	limits DrivingLimits,
+	logger *zap.Logger,
+) *DrivingTimeService {
+	return &DrivingTimeService{
+		repo:        repo,
+		producer:    producer,
+		limits:      limits,
+		logger:      logger,
+		lastChecked: make(map[uuid.UUID]time.Time),
+		exceeded:    make(map[uuid.UUID]map[string]bool),
+	}
+}
+
+// GetDrivingTime returns a runner's driving time over the last 24 hours and since their
+// last qualifying break.
+func (s *DrivingTimeService) GetDrivingTime(ctx context.Context, runnerID uuid.UUID) (*DrivingTimeDTO, error) {
+	now := time.Now().UTC()
+	windowStart := now.Add(-drivingWindow)
+
+	tracks, err := s.repo.FindByRunnerIDSince(ctx, runnerID, windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	var intervals []drivingInterval
+	for _, track := range tracks {
+		waypoints, err := s.repo.GetWaypointsBetween(ctx, track.ID(), windowStart, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get waypoints: %w", err)
+		}
+		for _, seg := range buildSegments(waypoints, nil) {
+			if seg.Kind != segmentLeg {
+				continue
+			}
+			start, end := seg.StartedAt, seg.EndedAt
+			if start.Before(windowStart) {
+				start = windowStart
+			}
+			if end.After(start) {
+				intervals = append(intervals, drivingInterval{start: start, end: end})
+			}
+		}
+	}
+
+	driving, continuous, lastBreakEnd, onBreak := summarizeDriving(mergeIntervals(intervals), s.limits.MinBreak, now)
+
+	result := &DrivingTimeDTO{
+		RunnerID:                 runnerID,
+		WindowStart:              windowStart,
+		WindowEnd:                now,
+		Trips:                    len(tracks),
+		DrivingSeconds:           driving.Seconds(),
+		ContinuousDrivingSeconds: continuous.Seconds(),
+		OnBreak:                  onBreak,
+		LastBreakEndedAt:         lastBreakEnd,
+		Limits: DrivingLimitsDTO{
+			MaxContinuousSeconds: s.limits.MaxContinuous.Seconds(),
+			MaxDailySeconds:      s.limits.MaxDaily.Seconds(),
+			MinBreakSeconds:      s.limits.MinBreak.Seconds(),
+		},
+		Exceeded: make([]string, 0, 2),
+	}
+	if continuous > s.limits.MaxContinuous {
+		result.Exceeded = append(result.Exceeded, limitContinuousDriving)
+	}
+	if driving > s.limits.MaxDaily {
+		result.Exceeded = append(result.Exceeded, limitDailyDriving)
+	}
+	return result, nil
+}
+
+// OnLocation checks the runner's driving time at most once per check interval. The
+// check runs in the background so it does not slow down location ingestion.
+func (s *DrivingTimeService) OnLocation(_ context.Context, track *trackingDomain.TripTrack, _ trackingDomain.Waypoint) {
+	runnerID := track.RunnerID()
+	now := time.Now()
+
+	s.mu.Lock()
+	if last, ok := s.lastChecked[runnerID]; ok && now.Sub(last) < s.limits.CheckInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastChecked[runnerID] = now
+	s.sweep(now)
+	s.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		s.check(ctx, runnerID, track.BookingID())
+	}()
+}
+
+// sweep forgets runners that have not reported a location for a full driving window.
+// It runs at most once per window and must be called with s.mu held.
+func (s *DrivingTimeService) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < drivingWindow {
+		return
+	}
+	s.lastSweep = now
+	for runnerID, last := range s.lastChecked {
+		if now.Sub(last) >= drivingWindow {
+			delete(s.lastChecked, runnerID)
+			delete(s.exceeded, runnerID)
+		}
+	}
+}
+
+// check computes a runner's driving time and publishes an event for each limit newly
+// exceeded. A limit is reported again only after the runner is back within it.
+func (s *DrivingTimeService) check(ctx context.Context, runnerID, bookingID uuid.UUID) {
+	dt, err := s.GetDrivingTime(ctx, runnerID)
+	if err != nil {
+		s.logger.Warn("failed to check runner driving time",
+			zap.String("runner_id", runnerID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	now := make(map[string]bool, len(dt.Exceeded))
+	for _, limit := range dt.Exceeded {
+		now[limit] = true
+	}
+
+	s.mu.Lock()
+	previous := s.exceeded[runnerID]
+	if len(now) == 0 {
+		delete(s.exceeded, runnerID)
+	} else {
+		s.exceeded[runnerID] = now
+	}
+	s.mu.Unlock()
+
+	for _, limit := range dt.Exceeded {
+		if previous[limit] {
+			continue
+		}
+		evt := DrivingLimitExceededEvent{
+			RunnerID:   runnerID,
+			BookingID:  bookingID,
+			Limit:      limit,
+			OccurredAt: time.Now().UTC(),
+		}
+		if limit == limitContinuousDriving {
+			evt.DrivingSeconds, evt.LimitSeconds = dt.ContinuousDrivingSeconds, dt.Limits.MaxContinuousSeconds
+		} else {
+			evt.DrivingSeconds, evt.LimitSeconds = dt.DrivingSeconds, dt.Limits.MaxDailySeconds
+		}
+		s.publishExceeded(ctx, evt)
+	}
+}
+
+// publishExceeded publishes a driving limit event.
+func (s *DrivingTimeService) publishExceeded(ctx context.Context, evt DrivingLimitExceededEvent) {
+	s.logger.Warn("runner exceeded driving limit",
+		zap.String("runner_id", evt.RunnerID.String()),
+		zap.String("limit", evt.Limit),
+		zap.Float64("driving_seconds", evt.DrivingSeconds),
+		zap.Float64("limit_seconds", evt.LimitSeconds),
+	)
+
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventDrivingLimitExceeded, evt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish driving limit event", zap.Error(err))
+	}
+}
+
+// mergeIntervals sorts intervals and merges overlapping ones, so time spent on
+// concurrent trips is counted once.
+func mergeIntervals(intervals []drivingInterval) []drivingInterval {
+	if len(intervals) == 0 {
+		return nil
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start.Before(intervals[j].start) })
+
+	merged := []drivingInterval{intervals[0]}
+	for _, iv := range intervals[1:] {
+		last := &merged[len(merged)-1]
+		if !iv.start.After(last.end) {
+			if iv.end.After(last.end) {
+				last.end = iv.end
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// summarizeDriving returns the total driving time of merged intervals, the driving time
+// since the last gap of at least minBreak and when that break ended. A break still in
+// progress at now resets continuous driving and is reported as onBreak.
+func summarizeDriving(merged []drivingInterval, minBreak time.Duration, now time.Time) (total, continuous time.Duration, lastBreakEnd *time.Time, onBreak bool) {
+	for i, iv := range merged {
+		total += iv.end.Sub(iv.start)
+		if i > 0 && iv.start.Sub(merged[i-1].end) >= minBreak {
+			continuous = 0
+			end := iv.start
+			lastBreakEnd = &end
+		}
+		continuous += iv.end.Sub(iv.start)
+	}
+	if len(merged) == 0 || now.Sub(merged[len(merged)-1].end) >= minBreak {
+		continuous = 0
+		onBreak = true
+	}
+	return total, continuous, lastBreakEnd, onBreak
+}
@@ -0,0 +1,487 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/clock"
+	chatDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/chat"
+	erasureDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/erasure"
+	participantDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/participant"
+	shareDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/share"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// UserEventsTopic is the topic the account service publishes user lifecycle events to.
+const UserEventsTopic = "user.events"
+
+// EventUserDataErasureRequested is the user event type asking every service to erase a
+// user's personal data.
+const EventUserDataErasureRequested = "user.data_erasure_requested"
+
+// eventUserDataErased is the CloudEvent type published when an erasure request completes.
+const eventUserDataErased = "tracking.user_data_erased"
+
+// ErasureConfig sets when erasure requests are carried out.
+type ErasureConfig struct {
+	// Retention is how long after a trip ends its data is kept before it may be erased,
+	// e.g. for disputes and insurance claims.
+	Retention time.Duration
+	// CheckInterval is how often pending requests are retried.
+	CheckInterval time.Duration
+}
+
+// UserDataErasureRequestedEvent asks for a user's personal data to be erased.
+type UserDataErasureRequestedEvent struct {
+	UserID      uuid.UUID `json:"user_id"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// RequestErasureRequest asks for a user's data to be erased, or with DryRun for a report
+// of what would be erased now, without changing anything.
+type RequestErasureRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	DryRun bool      `json:"dry_run"`
+}
+
+// BookingErasureDTO is what was, or in a dry run would be, removed from one booking.
+type BookingErasureDTO struct {
+	BookingID   uuid.UUID  `json:"booking_id"`
+	Waypoints   int        `json:"waypoints"`
+	Messages    int        `json:"messages"`
+	SharedLinks int        `json:"shared_links"`
+	ErasedAt    *time.Time `json:"erased_at,omitempty"`
+}
+
+// DeferredBookingDTO is a booking not erased yet. EligibleAt is omitted while its trip
+// is in progress.
+type DeferredBookingDTO struct {
+	BookingID  uuid.UUID  `json:"booking_id"`
+	EligibleAt *time.Time `json:"eligible_at,omitempty"`
+}
+
+// ErasureReportDTO is the audit report of an erasure request, or of a dry run, which
+// has no ID or status.
+type ErasureReportDTO struct {
+	ID          *uuid.UUID           `json:"id,omitempty"`
+	UserID      uuid.UUID            `json:"user_id"`
+	DryRun      bool                 `json:"dry_run"`
+	Status      string               `json:"status,omitempty"`
+	Erased      []BookingErasureDTO  `json:"erased"`
+	Deferred    []DeferredBookingDTO `json:"deferred"`
+	RequestedAt *time.Time           `json:"requested_at,omitempty"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+}
+
+// UserDataErasedEvent is published when all of a user's bookings have been erased.
+type UserDataErasedEvent struct {
+	RequestID   uuid.UUID `json:"request_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Bookings    int       `json:"bookings"`
+	Waypoints   int       `json:"waypoints"`
+	Messages    int       `json:"messages"`
+	SharedLinks int       `json:"shared_links"`
+	CompletedAt time.Time `json:"completed_at"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// erasureTarget is one of a user's bookings and when its data may be erased.
+type erasureTarget struct {
+	bookingID uuid.UUID
+	// track is nil if tracking never started for the booking.
+	track *trackingDomain.TripTrack
+	// eligibleAt is nil while the trip is in progress.
+	eligibleAt *time.Time
+}
+
+// ErasureService erases a user's personal data from the trip data of their bookings on
+// request: waypoints and share links are deleted and chat messages are anonymized.
+// Bookings are erased once their trip has ended and the retention period has passed;
+// until then the request stays pending and is retried periodically.
+type ErasureService struct {
+	repo         erasureDomain.Repository
+	tracks       trackingDomain.TripTrackRepository
+	participants participantDomain.Repository
+	chats        chatDomain.ChatRepository
+	shares       shareDomain.SharedTripRepository
+	producer     EventPublisher
+	config       ErasureConfig
+	logger       *zap.Logger
+	clock        clock.Clock
+}
+
+// NewErasureService creates a new ErasureService.
+func NewErasureService(
+	repo erasureDomain.Repository,
+	tracks trackingDomain.TripTrackRepository,
+	participants participantDomain.Repository,
+	chats chatDomain.ChatRepository,
+	shares shareDomain.SharedTripRepository,
+	producer EventPublisher,
+	config ErasureConfig,
+	logger *zap.Logger,
+) *ErasureService {
+	return &ErasureService{
+		repo:         repo,
+		tracks:       tracks,
+		participants: participants,
+		chats:        chats,
+		shares:       shares,
+		producer:     producer,
+		config:       config,
+		logger:       logger.With(zap.String("component", "erasure")),
+		clock:        clock.System,
+	}
+}
+
+// UseClock replaces the wall clock, e.g. with a clock.Manual in tests.
+func (s *ErasureService) UseClock(c clock.Clock) {
+	s.clock = c
+}
+
+// HandleErasureRequested records an erasure request received as an event and erases
+// whatever is already eligible.
+func (s *ErasureService) HandleErasureRequested(ctx context.Context, evt UserDataErasureRequestedEvent) error {
+	requestedAt := evt.RequestedAt
+	if requestedAt.IsZero() {
+		requestedAt = s.clock.Now()
+	}
+	_, err := s.request(ctx, evt.UserID, nil, requestedAt)
+	return err
+}
+
+// RequestErasure records an erasure request made by an admin and erases whatever is
+// already eligible. With DryRun, nothing is recorded or changed and the report lists
+// what would be erased now.
+func (s *ErasureService) RequestErasure(ctx context.Context, adminID uuid.UUID, req RequestErasureRequest) (*ErasureReportDTO, error) {
+	if req.UserID == uuid.Nil {
+		return nil, apperror.New(apperror.CodeValidation, "user_id is required")
+	}
+	if req.DryRun {
+		return s.dryRun(ctx, req.UserID)
+	}
+	request, err := s.request(ctx, req.UserID, &adminID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	return toErasureReportDTO(request), nil
+}
+
+// GetErasure returns the report of an erasure request.
+func (s *ErasureService) GetErasure(ctx context.Context, id uuid.UUID) (*ErasureReportDTO, error) {
+	request, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, apperror.New(apperror.CodeErasureRequestNotFound, "no erasure request %s", id)
+		}
+		return nil, fmt.Errorf("failed to find erasure request: %w", err)
+	}
+	return toErasureReportDTO(request), nil
+}
+
+// Run retries pending erasure requests every CheckInterval until ctx is cancelled.
+func (s *ErasureService) Run(ctx context.Context) {
+	if s.config.CheckInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.processPending(ctx)
+		}
+	}
+}
+
+// processPending carries out every pending request as far as retention allows.
+func (s *ErasureService) processPending(ctx context.Context) {
+	requests, err := s.repo.FindPending(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("failed to find pending erasure requests", zap.Error(err))
+		}
+		return
+	}
+	for _, request := range requests {
+		if err := s.process(ctx, request); err != nil && ctx.Err() == nil {
+			s.logger.Error("failed to process erasure request",
+				zap.String("request_id", request.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// request stores a new pending request for the user, or returns the user's pending one,
+// and processes it. A failure to process is logged and left for Run to retry.
+func (s *ErasureService) request(ctx context.Context, userID uuid.UUID, requestedBy *uuid.UUID, requestedAt time.Time) (*erasureDomain.Request, error) {
+	pending, err := s.repo.FindPending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pending erasure requests: %w", err)
+	}
+	for _, request := range pending {
+		if request.UserID == userID {
+			return request, nil
+		}
+	}
+
+	now := s.clock.Now().UTC()
+	request := &erasureDomain.Request{
+		ID:          uuid.New(),
+		UserID:      userID,
+		RequestedBy: requestedBy,
+		Status:      erasureDomain.StatusPending,
+		RequestedAt: requestedAt.UTC(),
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Save(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to save erasure request: %w", err)
+	}
+	s.logger.Info("erasure requested",
+		zap.String("request_id", request.ID.String()),
+		zap.String("user_id", userID.String()),
+	)
+
+	if err := s.process(ctx, request); err != nil {
+		s.logger.Error("failed to process erasure request",
+			zap.String("request_id", request.ID.String()),
+			zap.Error(err),
+		)
+	}
+	return request, nil
+}
+
+// process erases the request's eligible bookings not erased yet and records the rest
+// as deferred. The request completes once no booking is deferred.
+func (s *ErasureService) process(ctx context.Context, request *erasureDomain.Request) error {
+	targets, err := s.targets(ctx, request.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now().UTC()
+	erased := make(map[uuid.UUID]bool, len(request.Erased))
+	for _, e := range request.Erased {
+		erased[e.BookingID] = true
+	}
+	request.Deferred = nil
+	var eraseErr error
+	for _, t := range targets {
+		if erased[t.bookingID] {
+			continue
+		}
+		if eraseErr != nil || t.eligibleAt == nil || now.Before(*t.eligibleAt) {
+			request.Deferred = append(request.Deferred, erasureDomain.DeferredBooking{BookingID: t.bookingID, EligibleAt: t.eligibleAt})
+			continue
+		}
+		e, err := s.erase(ctx, t, false, now)
+		if err != nil {
+			eraseErr = fmt.Errorf("failed to erase booking %s: %w", t.bookingID, err)
+			request.Deferred = append(request.Deferred, erasureDomain.DeferredBooking{BookingID: t.bookingID, EligibleAt: t.eligibleAt})
+			continue
+		}
+		request.Erased = append(request.Erased, e)
+		s.logger.Info("booking data erased",
+			zap.String("request_id", request.ID.String()),
+			zap.String("booking_id", t.bookingID.String()),
+			zap.Int("waypoints", e.Waypoints),
+			zap.Int("messages", e.Messages),
+			zap.Int("shared_links", e.SharedLinks),
+		)
+	}
+
+	if len(request.Deferred) == 0 {
+		request.Status = erasureDomain.StatusCompleted
+		request.CompletedAt = &now
+	}
+	request.UpdatedAt = now
+	if err := s.repo.Update(ctx, request); err != nil {
+		return fmt.Errorf("failed to update erasure request: %w", err)
+	}
+	if request.Status == erasureDomain.StatusCompleted {
+		s.publishErased(ctx, request)
+	}
+	return eraseErr
+}
+
+// dryRun reports what erasing the user's data would remove now and which bookings would
+// be deferred.
+func (s *ErasureService) dryRun(ctx context.Context, userID uuid.UUID) (*ErasureReportDTO, error) {
+	targets, err := s.targets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now().UTC()
+	report := &ErasureReportDTO{UserID: userID, DryRun: true, Erased: []BookingErasureDTO{}, Deferred: []DeferredBookingDTO{}}
+	for _, t := range targets {
+		if t.eligibleAt == nil || now.Before(*t.eligibleAt) {
+			report.Deferred = append(report.Deferred, DeferredBookingDTO{BookingID: t.bookingID, EligibleAt: t.eligibleAt})
+			continue
+		}
+		e, err := s.erase(ctx, t, true, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count data of booking %s: %w", t.bookingID, err)
+		}
+		report.Erased = append(report.Erased, BookingErasureDTO{
+			BookingID:   e.BookingID,
+			Waypoints:   e.Waypoints,
+			Messages:    e.Messages,
+			SharedLinks: e.SharedLinks,
+		})
+	}
+	return report, nil
+}
+
+// targets returns the bookings the user owns or ran, as known from booking events and
+// from the user's trips as a runner.
+func (s *ErasureService) targets(ctx context.Context, userID uuid.UUID) ([]erasureTarget, error) {
+	participants, err := s.participants.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user bookings: %w", err)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(participants))
+	targets := make([]erasureTarget, 0, len(participants))
+	for _, p := range participants {
+		seen[p.BookingID] = true
+		track, err := s.tracks.FindByBookingID(ctx, p.BookingID)
+		if err != nil {
+			if !errors.Is(err, domain.ErrNotFound) {
+				return nil, fmt.Errorf("failed to find booking track: %w", err)
+			}
+			track = nil
+		}
+		targets = append(targets, s.target(p.BookingID, track, p.UpdatedAt))
+	}
+
+	runnerTracks, err := s.tracks.FindByRunnerIDSince(ctx, userID, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find runner tracks: %w", err)
+	}
+	for _, track := range runnerTracks {
+		if !seen[track.BookingID()] {
+			seen[track.BookingID()] = true
+			targets = append(targets, s.target(track.BookingID(), track, track.UpdatedAt()))
+		}
+	}
+	return targets, nil
+}
+
+// target works out when a booking's data may be erased: Retention after its trip ended,
+// or after the booking was last reported if tracking never started.
+func (s *ErasureService) target(bookingID uuid.UUID, track *trackingDomain.TripTrack, lastSeen time.Time) erasureTarget {
+	t := erasureTarget{bookingID: bookingID, track: track}
+	endedAt := lastSeen
+	if track != nil {
+		if track.IsActive() {
+			return t
+		}
+		endedAt = track.UpdatedAt()
+		if track.CompletedAt() != nil {
+			endedAt = *track.CompletedAt()
+		} else if track.Cancellation() != nil {
+			endedAt = track.Cancellation().CancelledAt
+		}
+	}
+	eligibleAt := endedAt.Add(s.config.Retention).UTC()
+	t.eligibleAt = &eligibleAt
+	return t
+}
+
+// erase removes a booking's waypoints and share links and anonymizes its chat, or with
+// dryRun only counts what would be removed.
+func (s *ErasureService) erase(ctx context.Context, t erasureTarget, dryRun bool, now time.Time) (erasureDomain.BookingErasure, error) {
+	e := erasureDomain.BookingErasure{BookingID: t.bookingID, ErasedAt: now}
+	var err error
+	if t.track != nil {
+		if dryRun {
+			e.Waypoints, err = s.tracks.CountWaypoints(ctx, t.track.ID())
+		} else {
+			e.Waypoints, err = s.tracks.EraseLocations(ctx, t.track.ID())
+		}
+		if err != nil {
+			return e, err
+		}
+	}
+	if dryRun {
+		e.Messages, err = s.chats.CountUnerasedByBookingID(ctx, t.bookingID)
+	} else {
+		e.Messages, err = s.chats.EraseByBookingID(ctx, t.bookingID, now)
+	}
+	if err != nil {
+		return e, err
+	}
+	if dryRun {
+		e.SharedLinks, err = s.shares.CountByBookingID(ctx, t.bookingID)
+	} else {
+		e.SharedLinks, err = s.shares.DeleteByBookingID(ctx, t.bookingID)
+	}
+	return e, err
+}
+
+// publishErased publishes a UserDataErasedEvent with the totals of a completed request.
+func (s *ErasureService) publishErased(ctx context.Context, request *erasureDomain.Request) {
+	evt := UserDataErasedEvent{
+		RequestID:   request.ID,
+		UserID:      request.UserID,
+		Bookings:    len(request.Erased),
+		CompletedAt: *request.CompletedAt,
+		OccurredAt:  s.clock.Now().UTC(),
+	}
+	for _, e := range request.Erased {
+		evt.Waypoints += e.Waypoints
+		evt.Messages += e.Messages
+		evt.SharedLinks += e.SharedLinks
+	}
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventUserDataErased, evt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish user data erased event", zap.Error(err))
+	}
+	s.logger.Info("erasure request completed",
+		zap.String("request_id", request.ID.String()),
+		zap.Int("bookings", evt.Bookings),
+	)
+}
+
+// toErasureReportDTO builds the API representation of an erasure request.
+func toErasureReportDTO(r *erasureDomain.Request) *ErasureReportDTO {
+	id, requestedAt := r.ID, r.RequestedAt
+	report := &ErasureReportDTO{
+		ID:          &id,
+		UserID:      r.UserID,
+		Status:      string(r.Status),
+		Erased:      make([]BookingErasureDTO, len(r.Erased)),
+		Deferred:    make([]DeferredBookingDTO, len(r.Deferred)),
+		RequestedAt: &requestedAt,
+		CompletedAt: r.CompletedAt,
+	}
+	for i, e := range r.Erased {
+		erasedAt := e.ErasedAt
+		report.Erased[i] = BookingErasureDTO{
+			BookingID:   e.BookingID,
+			Waypoints:   e.Waypoints,
+			Messages:    e.Messages,
+			SharedLinks: e.SharedLinks,
+			ErasedAt:    &erasedAt,
+		}
+	}
+	for i, d := range r.Deferred {
+		report.Deferred[i] = DeferredBookingDTO{BookingID: d.BookingID, EligibleAt: d.EligibleAt}
+	}
+	return report
+}
@@ -0,0 +1,225 @@
+package application
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+const (
+	// defaultSpeedKmh is assumed when a runner has no usable recent speed readings.
+	defaultSpeedKmh = 25.0
+
+	// recentSpeedSamples is the number of latest waypoints averaged for speed estimation.
+	recentSpeedSamples = 10
+)
+
+// Sources of an ETA's remaining distance and travel time.
+const (
+	// etaSourceStraightLine estimates from the straight-line distance and the runner's speed.
+	etaSourceStraightLine = "straight_line"
+	// etaSourceRoad uses a road route from the routing provider.
+	etaSourceRoad = "road"
+)
+
+// ETADTO represents an estimated time of arrival in API responses.
+type ETADTO struct {
+	BookingID          uuid.UUID `json:"booking_id"`
+	RemainingKm        float64   `json:"remaining_km"`
+	SpeedKmh           float64   `json:"speed_kmh"`
+	EstimatedMinutes   float64   `json:"estimated_minutes"`
+	EstimatedArrivalAt time.Time `json:"estimated_arrival_at"`
+	ComputedAt         time.Time `json:"computed_at"`
+	// Source is "road" when the estimate follows a road route, or "straight_line".
+	Source string `json:"source"`
+}
+
+// GetETA returns the current arrival estimate for a booking's active trip.
+func (s *TrackingService) GetETA(ctx context.Context, bookingID uuid.UUID) (*ETADTO, error) {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+	if !track.IsActive() {
+		return nil, apperror.New(apperror.CodeTrackingNotActive, "tracking for booking %s is %s", bookingID, track.Status())
+	}
+	if track.Destination() == nil {
+		return nil, apperror.New(apperror.CodeDestinationNotSet, "no destination set for booking %s", bookingID)
+	}
+
+	waypoints, err := s.repo.GetWaypoints(ctx, track.ID())
+	if err != nil {
+		return nil, err
+	}
+	if len(waypoints) == 0 {
+		return nil, apperror.New(apperror.CodePositionUnknown, "no position reported yet for booking %s", bookingID)
+	}
+
+	last := waypoints[len(waypoints)-1]
+	return s.estimateArrival(ctx, track, last, averageRecentSpeed(waypoints)), nil
+}
+
+// pushETAIfChanged updates the live speed samples for a booking and broadcasts an
+// eta_update frame when the estimate moves by more than the configured threshold.
+// ETA subscribers are offered every estimate and apply their own thresholds.
+func (s *TrackingService) pushETAIfChanged(ctx context.Context, track *trackingDomain.TripTrack, latest trackingDomain.Waypoint) {
+	if track.Destination() == nil {
+		return
+	}
+
+	s.liveMu.Lock()
+	state := s.liveStateLocked(track.BookingID())
+	if latest.Speed > 0 {
+		state.recentSpeeds = append(state.recentSpeeds, latest.Speed)
+		if len(state.recentSpeeds) > recentSpeedSamples {
+			state.recentSpeeds = state.recentSpeeds[len(state.recentSpeeds)-recentSpeedSamples:]
+		}
+	}
+	speed := state.averageSpeed()
+	s.liveMu.Unlock()
+
+	// Estimated outside the lock, since a road route may come from the routing provider.
+	eta := s.estimateArrival(ctx, track, latest, speed)
+
+	s.liveMu.Lock()
+	state = s.liveStateLocked(track.BookingID())
+	changed := state.lastETA.IsZero() ||
+		absDuration(eta.EstimatedArrivalAt.Sub(state.lastETA)) > s.config.ETAUpdateThreshold
+	if changed {
+		state.lastETA = eta.EstimatedArrivalAt
+	}
+	s.liveMu.Unlock()
+
+	if s.etaWatchers != nil {
+		s.etaWatchers.Observe(ctx, eta)
+	}
+	if !changed {
+		return
+	}
+
+	s.hub.BroadcastETA(&ws.ETAUpdate{
+		BookingID:          eta.BookingID,
+		RemainingKm:        eta.RemainingKm,
+		SpeedKmh:           eta.SpeedKmh,
+		EstimatedMinutes:   eta.EstimatedMinutes,
+		EstimatedArrivalAt: eta.EstimatedArrivalAt,
+		Source:             eta.Source,
+	})
+
+	s.logger.Debug("eta updated",
+		zap.String("booking_id", eta.BookingID.String()),
+		zap.Time("estimated_arrival_at", eta.EstimatedArrivalAt),
+	)
+}
+
+// averageSpeed averages the live speed samples, falling back to defaultSpeedKmh when
+// there are none. The caller must hold liveMu.
+func (st *liveTripState) averageSpeed() float64 {
+	if len(st.recentSpeeds) == 0 {
+		return defaultSpeedKmh
+	}
+	var sum float64
+	for _, v := range st.recentSpeeds {
+		sum += v
+	}
+	return sum / float64(len(st.recentSpeeds))
+}
+
+// liveETA estimates arrival from a position using the live speed samples rather than
+// the trip's waypoints. It returns nil if the trip is not active or has no destination.
+func (s *TrackingService) liveETA(ctx context.Context, track *trackingDomain.TripTrack, from trackingDomain.Waypoint) *ETADTO {
+	if !track.IsActive() || track.Destination() == nil {
+		return nil
+	}
+
+	speed := defaultSpeedKmh
+	s.liveMu.Lock()
+	if state, ok := s.live[track.BookingID()]; ok {
+		speed = state.averageSpeed()
+	}
+	s.liveMu.Unlock()
+
+	return s.estimateArrival(ctx, track, from, speed)
+}
+
+// estimateArrival estimates arrival at the track's destination along the road route
+// when a routing provider is configured, falling back to the straight-line estimate at
+// speedKmh when there is none or it fails. The caller must ensure the track has a
+// destination.
+func (s *TrackingService) estimateArrival(ctx context.Context, track *trackingDomain.TripTrack, from trackingDomain.Waypoint, speedKmh float64) *ETADTO {
+	eta := estimateETA(track, from, speedKmh, s.clock.Now().UTC())
+	if s.routing == nil {
+		return eta
+	}
+
+	dest := track.Destination()
+	route, err := s.routing.RouteBetween(ctx, from.Latitude, from.Longitude, dest.Latitude, dest.Longitude)
+	if err != nil {
+		s.logger.Debug("road route unavailable, using straight-line eta",
+			zap.String("booking_id", track.BookingID().String()),
+			zap.Error(err),
+		)
+		return eta
+	}
+
+	eta.RemainingKm = math.Round(route.DistanceKm*1000) / 1000
+	eta.EstimatedMinutes = math.Round(route.Duration.Minutes()*10) / 10
+	eta.EstimatedArrivalAt = eta.ComputedAt.Add(route.Duration)
+	eta.Source = etaSourceRoad
+	return eta
+}
+
+// estimateETA estimates arrival at the track's destination from a position and speed.
+// The caller must ensure the track has a destination.
+func estimateETA(track *trackingDomain.TripTrack, from trackingDomain.Waypoint, speedKmh float64, now time.Time) *ETADTO {
+	dest := track.Destination()
+	remainingKm := math.Round(haversineKm(from.Latitude, from.Longitude, dest.Latitude, dest.Longitude)*1000) / 1000
+	hours := remainingKm / speedKmh
+
+	return &ETADTO{
+		BookingID:          track.BookingID(),
+		RemainingKm:        remainingKm,
+		SpeedKmh:           math.Round(speedKmh*10) / 10,
+		EstimatedMinutes:   math.Round(hours*60*10) / 10,
+		EstimatedArrivalAt: now.Add(time.Duration(hours * float64(time.Hour))),
+		ComputedAt:         now,
+		Source:             etaSourceStraightLine,
+	}
+}
+
+// averageRecentSpeed averages the non-zero speeds of the latest waypoints,
+// falling back to defaultSpeedKmh when there are none.
+func averageRecentSpeed(waypoints []trackingDomain.Waypoint) float64 {
+	start := len(waypoints) - recentSpeedSamples
+	if start < 0 {
+		start = 0
+	}
+
+	var sum float64
+	var n int
+	for _, wp := range waypoints[start:] {
+		if wp.Speed > 0 {
+			sum += wp.Speed
+			n++
+		}
+	}
+	if n == 0 {
+		return defaultSpeedKmh
+	}
+	return sum / float64(n)
+}
+
+// absDuration returns the absolute value of d.
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,431 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/clock"
+	etaDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/eta"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+const (
+	// eventETAChanged is published when a booking's ETA moves past a subscriber's threshold.
+	eventETAChanged = "tracking.eta_changed"
+
+	// defaultETASubscriptionThreshold is used when a subscription does not set one.
+	defaultETASubscriptionThreshold = 5 * time.Minute
+
+	// etaSubscriptionCacheTTL is how long a booking's subscriptions are served from memory
+	// before being reloaded, so subscriptions made through another instance are picked up.
+	etaSubscriptionCacheTTL = 30 * time.Second
+
+	// etaCallbackTimeout bounds each callback request.
+	etaCallbackTimeout = 5 * time.Second
+
+	// etaCallbackWorkers is how many callbacks are sent at once.
+	etaCallbackWorkers = 4
+
+	// etaCallbackQueueSize is how many callbacks may wait for a worker before new ones
+	// are dropped.
+	etaCallbackQueueSize = 256
+)
+
+// SubscribeETARequest registers a service's interest in a booking's ETA. Threshold is a
+// Go duration such as "5m".
+type SubscribeETARequest struct {
+	Subscriber  string `json:"subscriber" binding:"required"`
+	Threshold   string `json:"threshold"`
+	CallbackURL string `json:"callback_url"`
+}
+
+// ETASubscriptionDTO is an ETA subscription in API responses.
+type ETASubscriptionDTO struct {
+	ID               uuid.UUID  `json:"id"`
+	BookingID        uuid.UUID  `json:"booking_id"`
+	Subscriber       string     `json:"subscriber"`
+	ThresholdSeconds int        `json:"threshold_seconds"`
+	CallbackURL      string     `json:"callback_url,omitempty"`
+	LastNotifiedETA  *time.Time `json:"last_notified_eta,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// ETAChangedEvent notifies one subscriber that a booking's estimated arrival moved by
+// more than its threshold. PreviousArrivalAt is omitted on the first notification.
+type ETAChangedEvent struct {
+	SubscriptionID     uuid.UUID  `json:"subscription_id"`
+	Subscriber         string     `json:"subscriber"`
+	BookingID          uuid.UUID  `json:"booking_id"`
+	PreviousArrivalAt  *time.Time `json:"previous_arrival_at,omitempty"`
+	EstimatedArrivalAt time.Time  `json:"estimated_arrival_at"`
+	DeltaSeconds       int        `json:"delta_seconds"`
+	RemainingKm        float64    `json:"remaining_km"`
+	SpeedKmh           float64    `json:"speed_kmh"`
+	Source             string     `json:"source"`
+	OccurredAt         time.Time  `json:"occurred_at"`
+}
+
+// cachedETASubscriptions is a booking's subscriptions as last loaded.
+type cachedETASubscriptions struct {
+	subs     []*etaDomain.Subscription
+	loadedAt time.Time
+}
+
+// ETASubscriptionService lets other services subscribe to significant changes in a
+// booking's ETA instead of consuming every location update. Each ETA estimate made
+// while processing locations is compared with what each subscriber was last told;
+// when it has moved by more than the subscriber's threshold, a tracking.eta_changed
+// event is published and, if the subscriber registered one, its callback is called.
+type ETASubscriptionService struct {
+	repo         etaDomain.Repository
+	trackingRepo trackingDomain.TripTrackRepository
+	producer     EventPublisher
+	client       *http.Client
+	clock        clock.Clock
+	logger       *zap.Logger
+
+	// callbackHosts are the hosts callbacks may be sent to; a leading "*." matches any
+	// subdomain. Without any, callbacks are refused.
+	callbackHosts []string
+	callbacks     chan etaCallback
+
+	mu        sync.Mutex
+	cache     map[uuid.UUID]*cachedETASubscriptions
+	lastSweep time.Time
+}
+
+// etaCallback is a callback waiting for a worker.
+type etaCallback struct {
+	url string
+	evt ETAChangedEvent
+}
+
+// NewETASubscriptionService creates a new ETASubscriptionService.
+func NewETASubscriptionService(
+	repo etaDomain.Repository,
+	trackingRepo trackingDomain.TripTrackRepository,
+	producer EventPublisher,
+	logger *zap.Logger,
+) *ETASubscriptionService {
+	return &ETASubscriptionService{
+		repo:         repo,
+		trackingRepo: trackingRepo,
+		producer:     producer,
+		client: &http.Client{
+			Timeout: etaCallbackTimeout,
+			// Redirects could lead callbacks off the allowed hosts.
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		clock:     clock.System,
+		logger:    logger,
+		callbacks: make(chan etaCallback, etaCallbackQueueSize),
+		cache:     make(map[uuid.UUID]*cachedETASubscriptions),
+	}
+}
+
+// UseCallbackHosts sets the hosts subscribers may register callbacks on, such as
+// "service-notification" or "*.svc.cluster.local". Must be called before Run.
+func (s *ETASubscriptionService) UseCallbackHosts(hosts []string) {
+	s.callbackHosts = hosts
+}
+
+// Run sends queued callbacks with etaCallbackWorkers workers until ctx is cancelled.
+// Should be called in a goroutine.
+func (s *ETASubscriptionService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < etaCallbackWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case cb := <-s.callbacks:
+					s.callback(cb.url, cb.evt)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Subscribe registers subscriber's interest in a booking's ETA. Subscribing again with
+// the same subscriber name updates the existing subscription.
+func (s *ETASubscriptionService) Subscribe(ctx context.Context, bookingID uuid.UUID, req SubscribeETARequest) (*ETASubscriptionDTO, error) {
+	threshold := defaultETASubscriptionThreshold
+	if req.Threshold != "" {
+		d, err := time.ParseDuration(req.Threshold)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeValidation, "invalid threshold %q", req.Threshold)
+		}
+		threshold = d
+	}
+
+	if req.CallbackURL != "" && !s.callbackAllowed(req.CallbackURL) {
+		return nil, apperror.New(apperror.CodeValidation, "callback_url host is not an allowed callback host")
+	}
+
+	track, err := s.trackingRepo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+	if !track.IsActive() {
+		return nil, apperror.New(apperror.CodeTrackingNotActive, "tracking for booking %s is %s", bookingID, track.Status())
+	}
+
+	existing, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	var sub *etaDomain.Subscription
+	for _, e := range existing {
+		if e.Subscriber() == req.Subscriber {
+			sub = e
+			break
+		}
+	}
+	if sub == nil {
+		sub, err = etaDomain.NewSubscription(bookingID, req.Subscriber, threshold, req.CallbackURL, s.clock.Now().UTC())
+	} else {
+		err = sub.Configure(threshold, req.CallbackURL)
+	}
+	if err != nil {
+		return nil, apperror.New(apperror.CodeValidation, "%s", err.Error())
+	}
+
+	if err := s.repo.Save(ctx, sub); err != nil {
+		return nil, err
+	}
+	s.invalidate(bookingID)
+
+	s.logger.Info("eta subscription saved",
+		zap.String("booking_id", bookingID.String()),
+		zap.String("subscription_id", sub.ID().String()),
+		zap.String("subscriber", sub.Subscriber()),
+		zap.Duration("threshold", sub.Threshold()),
+	)
+	return toETASubscriptionDTO(sub), nil
+}
+
+// ListSubscriptions returns a booking's ETA subscriptions.
+func (s *ETASubscriptionService) ListSubscriptions(ctx context.Context, bookingID uuid.UUID) ([]*ETASubscriptionDTO, error) {
+	subs, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]*ETASubscriptionDTO, 0, len(subs))
+	for _, sub := range subs {
+		dtos = append(dtos, toETASubscriptionDTO(sub))
+	}
+	return dtos, nil
+}
+
+// Unsubscribe removes one of a booking's ETA subscriptions.
+func (s *ETASubscriptionService) Unsubscribe(ctx context.Context, bookingID, subscriptionID uuid.UUID) error {
+	if err := s.repo.Delete(ctx, bookingID, subscriptionID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return apperror.New(apperror.CodeSubscriptionNotFound, "no eta subscription %s for booking %s", subscriptionID, bookingID)
+		}
+		return err
+	}
+	s.invalidate(bookingID)
+	return nil
+}
+
+// Observe compares a new ETA estimate with each subscriber's last notification and
+// notifies those for whom it moved by more than their threshold.
+func (s *ETASubscriptionService) Observe(ctx context.Context, eta *ETADTO) {
+	subs, err := s.subscriptions(ctx, eta.BookingID)
+	if err != nil {
+		s.logger.Warn("failed to load eta subscriptions",
+			zap.String("booking_id", eta.BookingID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	for _, sub := range subs {
+		s.mu.Lock()
+		previous := sub.LastNotifiedETA()
+		significant := sub.Significant(eta.EstimatedArrivalAt)
+		if significant {
+			sub.MarkNotified(eta.EstimatedArrivalAt)
+		}
+		s.mu.Unlock()
+		if !significant {
+			continue
+		}
+
+		if err := s.repo.UpdateLastNotified(ctx, sub.ID(), eta.EstimatedArrivalAt); err != nil {
+			s.logger.Warn("failed to record eta notification", zap.String("subscription_id", sub.ID().String()), zap.Error(err))
+		}
+
+		evt := ETAChangedEvent{
+			SubscriptionID:     sub.ID(),
+			Subscriber:         sub.Subscriber(),
+			BookingID:          eta.BookingID,
+			PreviousArrivalAt:  previous,
+			EstimatedArrivalAt: eta.EstimatedArrivalAt,
+			RemainingKm:        eta.RemainingKm,
+			SpeedKmh:           eta.SpeedKmh,
+			Source:             eta.Source,
+			OccurredAt:         s.clock.Now().UTC(),
+		}
+		if previous != nil {
+			evt.DeltaSeconds = int(eta.EstimatedArrivalAt.Sub(*previous).Round(time.Second) / time.Second)
+		}
+		s.publish(ctx, evt)
+		if sub.CallbackURL() != "" {
+			s.enqueueCallback(sub.CallbackURL(), evt)
+		}
+	}
+}
+
+// subscriptions returns a booking's subscriptions, reloading them once the cached copy
+// is older than etaSubscriptionCacheTTL. Expired bookings are swept at the same pace.
+func (s *ETASubscriptionService) subscriptions(ctx context.Context, bookingID uuid.UUID) ([]*etaDomain.Subscription, error) {
+	now := s.clock.Now()
+	s.mu.Lock()
+	cached, ok := s.cache[bookingID]
+	s.mu.Unlock()
+	if ok && now.Sub(cached.loadedAt) < etaSubscriptionCacheTTL {
+		return cached.subs, nil
+	}
+
+	subs, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= etaSubscriptionCacheTTL {
+		for id, c := range s.cache {
+			if now.Sub(c.loadedAt) >= etaSubscriptionCacheTTL {
+				delete(s.cache, id)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.cache[bookingID] = &cachedETASubscriptions{subs: subs, loadedAt: now}
+	return subs, nil
+}
+
+// invalidate drops a booking's cached subscriptions after they change on this instance.
+func (s *ETASubscriptionService) invalidate(bookingID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.cache, bookingID)
+	s.mu.Unlock()
+}
+
+func (s *ETASubscriptionService) publish(ctx context.Context, evt ETAChangedEvent) {
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventETAChanged, evt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+		return
+	}
+	if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish eta changed event", zap.Error(err))
+	}
+}
+
+// enqueueCallback queues a callback for the workers, dropping it if the queue is full or
+// its host is no longer allowed.
+func (s *ETASubscriptionService) enqueueCallback(callbackURL string, evt ETAChangedEvent) {
+	if !s.callbackAllowed(callbackURL) {
+		s.logger.Warn("eta callback host not allowed", zap.String("subscription_id", evt.SubscriptionID.String()))
+		return
+	}
+	select {
+	case s.callbacks <- etaCallback{url: callbackURL, evt: evt}:
+	default:
+		s.logger.Warn("eta callback queue full, dropping callback",
+			zap.String("subscription_id", evt.SubscriptionID.String()),
+			zap.String("subscriber", evt.Subscriber),
+		)
+	}
+}
+
+// callbackAllowed reports whether a callback URL is http(s) on one of the allowed hosts.
+func (s *ETASubscriptionService) callbackAllowed(callbackURL string) bool {
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range s.callbackHosts {
+		allowed = strings.ToLower(allowed)
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok {
+			if strings.HasPrefix(suffix, ".") && strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+			continue
+		}
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// callback POSTs an ETA change to a subscriber's callback URL. Failures are logged and
+// not retried; the tracking.eta_changed event remains the durable record.
+func (s *ETASubscriptionService) callback(callbackURL string, evt ETAChangedEvent) {
+	body, err := json.Marshal(evt)
+	if err != nil {
+		s.logger.Error("failed to encode eta callback", zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), etaCallbackTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		s.logger.Warn("failed to build eta callback", zap.String("subscription_id", evt.SubscriptionID.String()), zap.Error(err))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			err = fmt.Errorf("status %d", resp.StatusCode)
+		}
+	}
+	if err != nil {
+		s.logger.Warn("eta callback failed",
+			zap.String("subscription_id", evt.SubscriptionID.String()),
+			zap.String("subscriber", evt.Subscriber),
+			zap.Error(err),
+		)
+	}
+}
+
+func toETASubscriptionDTO(sub *etaDomain.Subscription) *ETASubscriptionDTO {
+	return &ETASubscriptionDTO{
+		ID:               sub.ID(),
+		BookingID:        sub.BookingID(),
+		Subscriber:       sub.Subscriber(),
+		ThresholdSeconds: int(sub.Threshold() / time.Second),
+		CallbackURL:      sub.CallbackURL(),
+		LastNotifiedETA:  sub.LastNotifiedETA(),
+		CreatedAt:        sub.CreatedAt(),
+	}
+}
@@ -0,0 +1,269 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	geofenceDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/geofence"
+	inboxDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/inbox"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	webhookDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/webhook"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+// CloudEvent types published when a runner crosses a geofence boundary.
+const (
+	eventGeofenceEntered = "tracking.geofence_entered"
+	eventGeofenceExited  = "tracking.geofence_exited"
+)
+
+// CreateGeofenceRequest holds data to create a geofence. Circles use latitude, longitude
+// and radius_meters; polygons use vertices.
+type CreateGeofenceRequest struct {
+	Kind         string                 `json:"kind" binding:"required"`
+	Shape        string                 `json:"shape" binding:"required"`
+	Latitude     float64                `json:"latitude"`
+	Longitude    float64                `json:"longitude"`
+	RadiusMeters float64                `json:"radius_meters"`
+	Vertices     []geofenceDomain.Point `json:"vertices"`
+}
+
+// GeofenceDTO is the API representation of a geofence.
+type GeofenceDTO struct {
+	ID           uuid.UUID              `json:"id"`
+	BookingID    uuid.UUID              `json:"booking_id"`
+	Kind         string                 `json:"kind"`
+	Shape        string                 `json:"shape"`
+	Center       *geofenceDomain.Point  `json:"center,omitempty"`
+	RadiusMeters float64                `json:"radius_meters,omitempty"`
+	Vertices     []geofenceDomain.Point `json:"vertices,omitempty"`
+	Active       bool                   `json:"active"`
+	Inside       bool                   `json:"inside"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
+
+// GeofenceTransitionEvent is published and pushed over WebSocket when a runner enters or exits a geofence.
+type GeofenceTransitionEvent struct {
+	GeofenceID uuid.UUID `json:"geofence_id"`
+	TrackID    uuid.UUID `json:"track_id"`
+	BookingID  uuid.UUID `json:"booking_id"`
+	RunnerID   uuid.UUID `json:"runner_id"`
+	Kind       string    `json:"kind"`
+	Transition string    `json:"transition"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// GeofenceService handles geofence management and evaluation of runner positions.
+type GeofenceService struct {
+	repo     geofenceDomain.GeofenceRepository
+	hub      *ws.Hub
+	producer EventPublisher
+	inbox    *InboxService
+	timeline *ChatTimeline
+	push     *PushFanout
+	webhooks *WebhookService
+	logger   *zap.Logger
+}
+
+// NewGeofenceService creates a new GeofenceService.
+func NewGeofenceService(repo geofenceDomain.GeofenceRepository, hub *ws.Hub, producer EventPublisher, logger *zap.Logger) *GeofenceService {
+	return &GeofenceService{repo: repo, hub: hub, producer: producer, logger: logger}
+}
+
+// UseInbox records geofence transitions in the inboxes of the booking's participants.
+func (s *GeofenceService) UseInbox(inbox *InboxService) {
+	s.inbox = inbox
+}
+
+// UseChatTimeline posts the runner's arrival at a booking's pickup geofence into its chat.
+func (s *GeofenceService) UseChatTimeline(t *ChatTimeline) {
+	s.timeline = t
+}
+
+// UsePushFanout requests a mobile push for the owner when the runner enters a geofence
+// while the owner is offline.
+func (s *GeofenceService) UsePushFanout(f *PushFanout) {
+	s.push = f
+}
+
+// UseWebhooks delivers the runner's arrival at a booking's pickup or drop-off geofence
+// to partners' webhooks.
+func (s *GeofenceService) UseWebhooks(w *WebhookService) {
+	s.webhooks = w
+}
+
+// CreateGeofence attaches a new geofence to a booking.
+func (s *GeofenceService) CreateGeofence(ctx context.Context, bookingID uuid.UUID, req CreateGeofenceRequest) (*GeofenceDTO, error) {
+	var (
+		g   *geofenceDomain.Geofence
+		err error
+	)
+	kind := geofenceDomain.Kind(req.Kind)
+	switch geofenceDomain.Shape(req.Shape) {
+	case geofenceDomain.ShapeCircle:
+		center := geofenceDomain.Point{Latitude: req.Latitude, Longitude: req.Longitude}
+		g, err = geofenceDomain.NewCircleGeofence(bookingID, kind, center, req.RadiusMeters)
+	case geofenceDomain.ShapePolygon:
+		g, err = geofenceDomain.NewPolygonGeofence(bookingID, kind, req.Vertices)
+	default:
+		err = fmt.Errorf("invalid geofence shape: %s", req.Shape)
+	}
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeValidation, err)
+	}
+
+	if err := s.repo.Save(ctx, g); err != nil {
+		return nil, fmt.Errorf("failed to save geofence: %w", err)
+	}
+
+	s.logger.Info("geofence created",
+		zap.String("geofence_id", g.ID().String()),
+		zap.String("booking_id", bookingID.String()),
+		zap.String("kind", string(g.Kind())),
+	)
+	return toGeofenceDTO(g), nil
+}
+
+// ListGeofences returns all geofences attached to a booking.
+func (s *GeofenceService) ListGeofences(ctx context.Context, bookingID uuid.UUID) ([]*GeofenceDTO, error) {
+	fences, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]*GeofenceDTO, len(fences))
+	for i, g := range fences {
+		dtos[i] = toGeofenceDTO(g)
+	}
+	return dtos, nil
+}
+
+// DeactivateGeofence stops a booking's geofence from being evaluated.
+func (s *GeofenceService) DeactivateGeofence(ctx context.Context, bookingID, geofenceID uuid.UUID) error {
+	g, err := s.repo.FindByID(ctx, geofenceID)
+	if err != nil || g.BookingID() != bookingID {
+		return apperror.New(apperror.CodeGeofenceNotFound, "no geofence %s for booking %s", geofenceID, bookingID)
+	}
+
+	g.Deactivate()
+	return s.repo.Update(ctx, g)
+}
+
+// OnLocation evaluates a new waypoint against the booking's active geofences and
+// publishes an event and WebSocket notification for every boundary crossing.
+func (s *GeofenceService) OnLocation(ctx context.Context, track *trackingDomain.TripTrack, waypoint trackingDomain.Waypoint) {
+	fences, err := s.repo.FindActiveByBookingID(ctx, track.BookingID())
+	if err != nil {
+		s.logger.Warn("failed to load geofences", zap.Error(err))
+		return
+	}
+
+	position := geofenceDomain.Point{Latitude: waypoint.Latitude, Longitude: waypoint.Longitude}
+	for _, g := range fences {
+		transition := g.Evaluate(position)
+		if transition == geofenceDomain.TransitionNone {
+			continue
+		}
+
+		if err := s.repo.Update(ctx, g); err != nil {
+			s.logger.Error("failed to update geofence state", zap.Error(err))
+			continue
+		}
+
+		s.publishTransition(ctx, track, g, transition, waypoint)
+	}
+}
+
+// publishTransition emits a geofence transition as a CloudEvent and a WebSocket notification.
+func (s *GeofenceService) publishTransition(
+	ctx context.Context,
+	track *trackingDomain.TripTrack,
+	g *geofenceDomain.Geofence,
+	transition geofenceDomain.Transition,
+	waypoint trackingDomain.Waypoint,
+) {
+	evt := GeofenceTransitionEvent{
+		GeofenceID: g.ID(),
+		TrackID:    track.ID(),
+		BookingID:  track.BookingID(),
+		RunnerID:   track.RunnerID(),
+		Kind:       string(g.Kind()),
+		Transition: string(transition),
+		Latitude:   waypoint.Latitude,
+		Longitude:  waypoint.Longitude,
+		OccurredAt: time.Now().UTC(),
+	}
+
+	eventType, frameType := eventGeofenceEntered, "geofence_entered"
+	if transition == geofenceDomain.TransitionExited {
+		eventType, frameType = eventGeofenceExited, "geofence_exited"
+	}
+
+	s.hub.Notify(&ws.Notification{BookingID: track.BookingID(), Type: frameType, Data: evt})
+	if s.inbox != nil {
+		if err := s.inbox.Record(ctx, track.BookingID(), uuid.Nil, inboxDomain.KindSystem, frameType, uuid.New(), evt); err != nil {
+			s.logger.Error("failed to record geofence transition in inbox", zap.Error(err))
+		}
+	}
+	if s.push != nil && transition == geofenceDomain.TransitionEntered {
+		stop := "pickup point"
+		if g.Kind() == geofenceDomain.KindDropoff {
+			stop = "drop-off point"
+		}
+		s.push.NotifyOffline(ctx, track.BookingID(), track.RunnerID(), PushNotification{
+			Kind:  PushKindGeofence,
+			Title: "Your runner has arrived",
+			Body:  "Your runner has arrived at the " + stop + ".",
+			Data:  map[string]string{"booking_id": track.BookingID().String(), "geofence_id": g.ID().String(), "geofence_kind": string(g.Kind())},
+		})
+	}
+	if s.timeline != nil && g.Kind() == geofenceDomain.KindPickup && transition == geofenceDomain.TransitionEntered {
+		s.timeline.Post(ctx, track.BookingID(), MilestoneArrivedAtPickup)
+	}
+	if s.webhooks != nil && transition == geofenceDomain.TransitionEntered {
+		s.webhooks.Notify(ctx, webhookDomain.EventArrived, track, string(g.Kind()))
+	}
+
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventType, evt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish geofence event", zap.Error(err))
+	}
+
+	s.logger.Info("geofence transition",
+		zap.String("geofence_id", g.ID().String()),
+		zap.String("booking_id", track.BookingID().String()),
+		zap.String("kind", string(g.Kind())),
+		zap.String("transition", string(transition)),
+	)
+}
+
+func toGeofenceDTO(g *geofenceDomain.Geofence) *GeofenceDTO {
+	dto := &GeofenceDTO{
+		ID:        g.ID(),
+		BookingID: g.BookingID(),
+		Kind:      string(g.Kind()),
+		Shape:     string(g.Shape()),
+		Active:    g.IsActive(),
+		Inside:    g.IsInside(),
+		CreatedAt: g.CreatedAt(),
+	}
+	if g.Shape() == geofenceDomain.ShapeCircle {
+		center := g.Center()
+		dto.Center = &center
+		dto.RadiusMeters = g.RadiusMeters()
+	} else {
+		dto.Vertices = g.Vertices()
+	}
+	return dto
+}
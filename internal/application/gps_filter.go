@@ -0,0 +1,36 @@
+package application
+
+import (
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// gpsFilterConfig sets up each trip's position filter; zero noise disables filtering.
+type gpsFilterConfig struct {
+	measurementNoiseM float64
+	processNoiseMps   float64
+}
+
+// UseGPSFilter smooths each trip's locations with a Kalman filter before they are
+// broadcast and added to its distance, for GPS fixes accurate to about
+// measurementNoiseM meters and runners deviating from their reported course by about
+// processNoiseMps meters per second. Raw locations are still stored. Must be called
+// before locations are consumed.
+func (s *TrackingService) UseGPSFilter(measurementNoiseM, processNoiseMps float64) {
+	s.gpsFilter = gpsFilterConfig{measurementNoiseM: measurementNoiseM, processNoiseMps: processNoiseMps}
+}
+
+// smooth returns a live location smoothed by its trip's position filter, or unchanged
+// if filtering is disabled.
+func (s *TrackingService) smooth(track *trackingDomain.TripTrack, wp trackingDomain.Waypoint) trackingDomain.Waypoint {
+	if s.gpsFilter.measurementNoiseM <= 0 {
+		return wp
+	}
+
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+	state := s.liveStateLocked(track.BookingID())
+	if state.positionFilter == nil {
+		state.positionFilter = trackingDomain.NewPositionFilter(s.gpsFilter.measurementNoiseM, s.gpsFilter.processNoiseMps)
+	}
+	return state.positionFilter.Filter(wp)
+}
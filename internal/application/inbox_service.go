@@ -0,0 +1,191 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/clock"
+	inboxDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/inbox"
+	participantDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/participant"
+)
+
+const (
+	// DefaultInboxSyncLimit is the number of entries returned per sync when not requested.
+	DefaultInboxSyncLimit = 50
+	// MaxInboxSyncLimit is the most entries returned per sync.
+	MaxInboxSyncLimit = 200
+
+	// inboxPruneInterval is how often entries past the retention are deleted.
+	inboxPruneInterval = time.Hour
+)
+
+// InboxEntryDTO is an undelivered message in a user's inbox. Data is the message as
+// sent live in a WebSocket frame of the given type.
+type InboxEntryDTO struct {
+	Cursor    int64           `json:"cursor"`
+	BookingID uuid.UUID       `json:"booking_id"`
+	Kind      string          `json:"kind"`
+	Type      string          `json:"type"`
+	MessageID uuid.UUID       `json:"message_id"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// InboxSyncDTO is a page of a user's inbox. NextCursor is passed as since on the next
+// sync, which also acknowledges the entries returned.
+type InboxSyncDTO struct {
+	Entries    []InboxEntryDTO `json:"entries"`
+	NextCursor int64           `json:"next_cursor"`
+	HasMore    bool            `json:"has_more"`
+}
+
+// InboxService records chat and system messages in the inboxes of a booking's
+// participants until they sync them, so users who were offline can catch up.
+type InboxService struct {
+	repo         inboxDomain.Repository
+	participants participantDomain.Repository
+	retention    time.Duration
+	logger       *zap.Logger
+	clock        clock.Clock
+}
+
+// NewInboxService creates a new InboxService. Entries not synced within retention are
+// deleted.
+func NewInboxService(
+	repo inboxDomain.Repository,
+	participants participantDomain.Repository,
+	retention time.Duration,
+	logger *zap.Logger,
+) *InboxService {
+	return &InboxService{
+		repo:         repo,
+		participants: participants,
+		retention:    retention,
+		logger:       logger.With(zap.String("component", "inbox")),
+		clock:        clock.System,
+	}
+}
+
+// UseClock replaces the wall clock, e.g. with a clock.Manual in tests.
+func (s *InboxService) UseClock(c clock.Clock) {
+	s.clock = c
+}
+
+// Record adds a message to the inboxes of the booking's owner and runner, except the
+// sender's. senderID is uuid.Nil for system messages. frameType is the WebSocket frame
+// type the message is sent as live.
+func (s *InboxService) Record(
+	ctx context.Context,
+	bookingID, senderID uuid.UUID,
+	kind inboxDomain.Kind,
+	frameType string,
+	messageID uuid.UUID,
+	data interface{},
+) error {
+	p, err := s.participants.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return fmt.Errorf("failed to find booking participants: %w", err)
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal inbox message: %w", err)
+	}
+
+	now := s.clock.Now().UTC()
+	var entries []inboxDomain.Entry
+	for _, userID := range []uuid.UUID{p.OwnerID, p.RunnerID} {
+		if userID == uuid.Nil || userID == senderID {
+			continue
+		}
+		entries = append(entries, inboxDomain.Entry{
+			UserID:    userID,
+			BookingID: bookingID,
+			Kind:      kind,
+			Type:      frameType,
+			MessageID: messageID,
+			Data:      payload,
+			CreatedAt: now,
+		})
+	}
+	if err := s.repo.Append(ctx, entries); err != nil {
+		return fmt.Errorf("failed to record inbox entries: %w", err)
+	}
+	return nil
+}
+
+// Sync acknowledges a user's entries up to since and returns up to limit entries
+// after it, oldest first.
+func (s *InboxService) Sync(ctx context.Context, userID uuid.UUID, since int64, limit int) (*InboxSyncDTO, error) {
+	if since < 0 {
+		return nil, apperror.New(apperror.CodeValidation, "since must not be negative")
+	}
+	if limit <= 0 {
+		limit = DefaultInboxSyncLimit
+	}
+	if limit > MaxInboxSyncLimit {
+		limit = MaxInboxSyncLimit
+	}
+
+	if since > 0 {
+		if _, err := s.repo.DeleteUpTo(ctx, userID, since); err != nil {
+			return nil, fmt.Errorf("failed to acknowledge inbox entries: %w", err)
+		}
+	}
+
+	entries, err := s.repo.ListAfter(ctx, userID, since, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inbox entries: %w", err)
+	}
+
+	result := &InboxSyncDTO{
+		Entries:    make([]InboxEntryDTO, 0, len(entries)),
+		NextCursor: since,
+	}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		result.HasMore = true
+	}
+	for _, e := range entries {
+		result.Entries = append(result.Entries, InboxEntryDTO{
+			Cursor:    e.Cursor,
+			BookingID: e.BookingID,
+			Kind:      string(e.Kind),
+			Type:      e.Type,
+			MessageID: e.MessageID,
+			Data:      e.Data,
+			CreatedAt: e.CreatedAt,
+		})
+		result.NextCursor = e.Cursor
+	}
+	return result, nil
+}
+
+// Run deletes entries older than the retention every hour until ctx is cancelled.
+func (s *InboxService) Run(ctx context.Context) {
+	ticker := time.NewTicker(inboxPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.repo.DeleteCreatedBefore(ctx, s.clock.Now().Add(-s.retention))
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Warn("failed to prune inbox entries", zap.Error(err))
+				}
+				continue
+			}
+			if deleted > 0 {
+				s.logger.Info("pruned expired inbox entries", zap.Int64("deleted", deleted))
+			}
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+// frameFleetState is the WebSocket frame type of the live ops fleet state.
+const frameFleetState = "fleet_state"
+
+// maxLiveOpsCompletions caps the trips listed as newly completed in one fleet state.
+const maxLiveOpsCompletions = 200
+
+// RegionFleetDTO is the trips in progress in one region. Region is empty for tracks
+// created from the global topics.
+type RegionFleetDTO struct {
+	Region        string `json:"region"`
+	ActiveTracks  int    `json:"active_tracks"`
+	PausedTracks  int    `json:"paused_tracks"`
+	ActiveRunners int    `json:"active_runners"`
+}
+
+// CompletedTripDTO is a trip completed since the previous fleet state.
+type CompletedTripDTO struct {
+	TrackID         uuid.UUID `json:"track_id"`
+	BookingID       uuid.UUID `json:"booking_id"`
+	RunnerID        uuid.UUID `json:"runner_id"`
+	Region          string    `json:"region,omitempty"`
+	TotalDistanceKm float64   `json:"total_distance_km"`
+	CompletedAt     time.Time `json:"completed_at"`
+}
+
+// FleetStateDTO is the aggregated fleet state streamed to live ops dashboards. The
+// totals are summed over regions. Completed lists the trips completed since
+// CompletedSince, at most maxLiveOpsCompletions.
+type FleetStateDTO struct {
+	ActiveTracks   int                `json:"active_tracks"`
+	PausedTracks   int                `json:"paused_tracks"`
+	ActiveRunners  int                `json:"active_runners"`
+	Regions        []RegionFleetDTO   `json:"regions"`
+	StalledTrips   int                `json:"stalled_trips"`
+	Stalled        []StalledTrackDTO  `json:"stalled"`
+	CompletedSince time.Time          `json:"completed_since"`
+	Completed      []CompletedTripDTO `json:"completed"`
+	GeneratedAt    time.Time          `json:"generated_at"`
+}
+
+// fleetStateFrame frames a fleet state as {"type": "fleet_state", "data": ...}.
+type fleetStateFrame struct {
+	Type string         `json:"type"`
+	Data *FleetStateDTO `json:"data"`
+}
+
+// LiveOpsAggregator streams the aggregated fleet state to live ops dashboards connected
+// to this instance. The state is read from the database every interval, so dashboards
+// on every instance see the whole fleet; nothing is computed while none is connected.
+type LiveOpsAggregator struct {
+	tracking *TrackingService
+	repo     trackingDomain.TripTrackRepository
+	interval time.Duration
+	logger   *zap.Logger
+
+	mu          sync.Mutex
+	subscribers map[*ws.Client]struct{}
+	last        *FleetStateDTO
+	// since is when the last fleet state was built; trips completed after it are new.
+	since time.Time
+}
+
+// NewLiveOpsAggregator creates a LiveOpsAggregator refreshing the fleet state every
+// interval.
+func NewLiveOpsAggregator(tracking *TrackingService, repo trackingDomain.TripTrackRepository, interval time.Duration, logger *zap.Logger) *LiveOpsAggregator {
+	return &LiveOpsAggregator{
+		tracking:    tracking,
+		repo:        repo,
+		interval:    interval,
+		logger:      logger.With(zap.String("component", "live_ops")),
+		subscribers: make(map[*ws.Client]struct{}),
+	}
+}
+
+// Subscribe streams the fleet state to a dashboard connection, starting with the last
+// state built, without its completions, if there is one.
+func (a *LiveOpsAggregator) Subscribe(c *ws.Client) {
+	a.mu.Lock()
+	a.subscribers[c] = struct{}{}
+	last := a.last
+	a.mu.Unlock()
+
+	if last != nil {
+		state := *last
+		state.Completed = []CompletedTripDTO{}
+		c.Reply(fleetStateFrame{Type: frameFleetState, Data: &state})
+	}
+}
+
+// Unsubscribe stops streaming to a dashboard connection.
+func (a *LiveOpsAggregator) Unsubscribe(c *ws.Client) {
+	a.mu.Lock()
+	delete(a.subscribers, c)
+	a.mu.Unlock()
+}
+
+// Run refreshes the fleet state every interval until ctx is cancelled.
+func (a *LiveOpsAggregator) Run(ctx context.Context) {
+	if a.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.refresh(ctx)
+		}
+	}
+}
+
+// refresh builds the fleet state and sends it to every subscriber.
+func (a *LiveOpsAggregator) refresh(ctx context.Context) {
+	now := time.Now().UTC()
+	a.mu.Lock()
+	since := a.since
+	if len(a.subscribers) == 0 {
+		// Completions while nobody watched are not reported, and a stale state is not
+		// sent to the next subscriber.
+		a.since, a.last = now, nil
+		a.mu.Unlock()
+		return
+	}
+	a.mu.Unlock()
+	if since.IsZero() {
+		since = now.Add(-a.interval)
+	}
+
+	state, err := a.build(ctx, since, now)
+	if err != nil {
+		if ctx.Err() == nil {
+			a.logger.Error("failed to build fleet state", zap.Error(err))
+		}
+		return
+	}
+
+	a.mu.Lock()
+	a.since, a.last = now, state
+	subscribers := make([]*ws.Client, 0, len(a.subscribers))
+	for c := range a.subscribers {
+		subscribers = append(subscribers, c)
+	}
+	a.mu.Unlock()
+
+	frame := fleetStateFrame{Type: frameFleetState, Data: state}
+	for _, c := range subscribers {
+		if !c.Reply(frame) {
+			a.logger.Debug("fleet state dropped for a slow dashboard", zap.String("user_id", c.UserID.String()))
+		}
+	}
+}
+
+// build reads the fleet state, with the trips completed in [since, now).
+func (a *LiveOpsAggregator) build(ctx context.Context, since, now time.Time) (*FleetStateDTO, error) {
+	regions, err := a.repo.GetFleetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stalled, err := a.tracking.ListStalledTracks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := a.repo.FindCompletedSince(ctx, since, maxLiveOpsCompletions)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &FleetStateDTO{
+		Regions:        make([]RegionFleetDTO, len(regions)),
+		StalledTrips:   len(stalled),
+		Stalled:        stalled,
+		CompletedSince: since,
+		Completed:      make([]CompletedTripDTO, 0, len(completed)),
+		GeneratedAt:    now,
+	}
+	for i, r := range regions {
+		state.Regions[i] = RegionFleetDTO(r)
+		state.ActiveTracks += r.ActiveTracks
+		state.PausedTracks += r.PausedTracks
+		state.ActiveRunners += r.ActiveRunners
+	}
+	for _, track := range completed {
+		// Trips completed after now are reported in the next state.
+		if !track.CompletedAt().Before(now) {
+			continue
+		}
+		state.Completed = append(state.Completed, CompletedTripDTO{
+			TrackID:         track.ID(),
+			BookingID:       track.BookingID(),
+			RunnerID:        track.RunnerID(),
+			Region:          track.Region(),
+			TotalDistanceKm: track.TotalDistanceKm(),
+			CompletedAt:     *track.CompletedAt(),
+		})
+	}
+	return state, nil
+}
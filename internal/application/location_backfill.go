@@ -0,0 +1,111 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// eventWaypointsBackfilled is the CloudEvent type published when a runner uploads
+// locations recorded while offline.
+const eventWaypointsBackfilled = "tracking.waypoints_backfilled"
+
+// backfillAge is how old a submitted location may be and still be treated as live.
+const backfillAge = 30 * time.Second
+
+// WaypointsBackfilledEvent is published once per batch of backfilled waypoints, so
+// consumers that derive state from live updates can refresh the affected span.
+type WaypointsBackfilledEvent struct {
+	TrackID         uuid.UUID `json:"track_id"`
+	BookingID       uuid.UUID `json:"booking_id"`
+	RunnerID        uuid.UUID `json:"runner_id"`
+	Count           int       `json:"count"`
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	DistanceAddedKm float64   `json:"distance_added_km"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
+
+// liveHorizon returns the time before which a submitted location of the track is
+// backfilled rather than live: the track's latest recorded position, or backfillAge
+// ago if that is later.
+func (s *TrackingService) liveHorizon(ctx context.Context, track *trackingDomain.TripTrack, now time.Time) time.Time {
+	horizon := now.Add(-backfillAge)
+	if latest, ok := s.latestRecordedAt(ctx, track, now); ok && latest.After(horizon) && !latest.After(now) {
+		horizon = latest
+	}
+	return horizon
+}
+
+// latestRecordedAt returns when the track's latest waypoint was recorded, preferring
+// the latest position cache over reading the track's last chunk.
+func (s *TrackingService) latestRecordedAt(ctx context.Context, track *trackingDomain.TripTrack, now time.Time) (time.Time, bool) {
+	if s.positions != nil {
+		if pos, err := s.positions.GetByBooking(ctx, track.BookingID()); err == nil {
+			return pos.Waypoint.RecordedAt, true
+		}
+	}
+	waypoints, err := s.repo.GetWaypointsBetween(ctx, track.ID(), now, now)
+	if err != nil {
+		s.logger.Warn("failed to load latest waypoint", zap.Error(err))
+		return time.Time{}, false
+	}
+	for i := len(waypoints) - 1; i >= 0; i-- {
+		if !waypoints[i].RecordedAt.After(now) {
+			return waypoints[i].RecordedAt, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// backfillWaypoints stores waypoints, in time order, that a runner recorded while
+// offline. They are inserted among the track's waypoints and counted in its running
+// distance without touching the latest position, the WebSocket hub, ETA or the
+// location observers, and one event reports the batch with the distance it added.
+func (s *TrackingService) backfillWaypoints(ctx context.Context, track *trackingDomain.TripTrack, waypoints []trackingDomain.Waypoint) error {
+	if len(waypoints) == 0 {
+		return nil
+	}
+	writeStart := time.Now()
+	if err := s.repo.BackfillWaypoints(ctx, track.ID(), waypoints); err != nil {
+		s.logger.Error("failed to backfill waypoints", zap.Error(err))
+		return err
+	}
+	s.overload.ObserveDBLatency(time.Since(writeStart))
+	addedKm := s.accumulateDistance(ctx, track, waypoints)
+	s.enforceWaypointCap(ctx, track)
+
+	from, to := waypoints[0].RecordedAt, waypoints[len(waypoints)-1].RecordedAt
+
+	s.logger.Info("waypoints backfilled",
+		zap.String("booking_id", track.BookingID().String()),
+		zap.Int("count", len(waypoints)),
+		zap.Time("from", from),
+		zap.Time("to", to),
+		zap.Float64("distance_added_km", addedKm),
+	)
+
+	evt := WaypointsBackfilledEvent{
+		TrackID:         track.ID(),
+		BookingID:       track.BookingID(),
+		RunnerID:        track.RunnerID(),
+		Count:           len(waypoints),
+		From:            from,
+		To:              to,
+		DistanceAddedKm: addedKm,
+		OccurredAt:      s.clock.Now().UTC(),
+	}
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventWaypointsBackfilled, evt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish waypoints backfilled event", zap.Error(err))
+	}
+	return nil
+}
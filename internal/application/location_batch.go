@@ -0,0 +1,153 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	telemetryDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/telemetry"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// maxLocationBatch is the most points accepted in one location submission.
+const maxLocationBatch = 100
+
+// RejectedLocationDTO is a point of a batch that was not stored. Index is its position
+// in the submitted list.
+type RejectedLocationDTO struct {
+	Index  int    `json:"index"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// IngestLocationsResult reports how many points of a batch were stored and why the
+// others were not. Backfilled counts the accepted points stored as recorded offline.
+type IngestLocationsResult struct {
+	Accepted   int                   `json:"accepted"`
+	Backfilled int                   `json:"backfilled"`
+	Rejected   []RejectedLocationDTO `json:"rejected"`
+}
+
+// IngestLocations records a batch of locations submitted by runnerID for a booking over
+// REST, for runner apps that cannot rely on the Kafka path. The runner and trip are
+// checked once for the whole batch as for IngestWaypoint. Points are recorded in time
+// order through the same pipeline as single submissions; a point that fails validation
+// is reported without affecting the others. Points recorded before the track's latest
+// position, or more than backfillAge ago, were recorded offline: they are stored as
+// backfilled waypoints and never broadcast as live positions.
+func (s *TrackingService) IngestLocations(
+	ctx context.Context,
+	bookingID, runnerID uuid.UUID,
+	sourceIP string,
+	points []IngestWaypointRequest,
+) (*IngestLocationsResult, error) {
+	return s.ingestLocations(ctx, bookingID, runnerID, sourceIP, sourceREST, points)
+}
+
+// IngestStreamedLocations records locations a runner streams over its WebSocket
+// connection, exactly as IngestLocations does for REST submissions.
+func (s *TrackingService) IngestStreamedLocations(
+	ctx context.Context,
+	bookingID, runnerID uuid.UUID,
+	sourceIP string,
+	points []IngestWaypointRequest,
+) (*IngestLocationsResult, error) {
+	return s.ingestLocations(ctx, bookingID, runnerID, sourceIP, sourceWebSocket, points)
+}
+
+func (s *TrackingService) ingestLocations(
+	ctx context.Context,
+	bookingID, runnerID uuid.UUID,
+	sourceIP, source string,
+	points []IngestWaypointRequest,
+) (*IngestLocationsResult, error) {
+	if len(points) == 0 {
+		return nil, apperror.New(apperror.CodeValidation, "at least one location is required")
+	}
+	if len(points) > maxLocationBatch {
+		return nil, apperror.New(apperror.CodeValidation, "at most %d locations may be submitted at once", maxLocationBatch)
+	}
+
+	track, err := s.authorizeLocationSubmission(ctx, bookingID, runnerID, sourceIP, source, len(points))
+	if err != nil {
+		return nil, err
+	}
+
+	// Points without a timestamp were just taken, so they sort last.
+	now := s.clock.Now().UTC()
+	order := make([]int, len(points))
+	for i := range points {
+		order[i] = i
+		if points[i].Timestamp.IsZero() {
+			points[i].Timestamp = now
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return points[order[a]].Timestamp.Before(points[order[b]].Timestamp)
+	})
+
+	result := &IngestLocationsResult{Rejected: []RejectedLocationDTO{}}
+	reject := func(i int, err error) error {
+		var appErr *apperror.Error
+		if !errors.As(err, &appErr) {
+			return err
+		}
+		result.Rejected = append(result.Rejected, RejectedLocationDTO{Index: i, Code: string(appErr.Code), Detail: appErr.Detail})
+		return nil
+	}
+
+	horizon := s.liveHorizon(ctx, track, now)
+	var live []int
+	var backfill []trackingDomain.Waypoint
+	var readings []*telemetryDomain.Reading
+	for _, i := range order {
+		if !points[i].Timestamp.Before(horizon) {
+			live = append(live, i)
+			continue
+		}
+		waypoint, err := s.submittedWaypoint(track, source, points[i])
+		if err != nil {
+			if err := reject(i, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		reading, err := s.crateReading(track, points[i], waypoint.RecordedAt)
+		if err != nil {
+			if err := reject(i, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		backfill = append(backfill, waypoint)
+		if reading != nil {
+			readings = append(readings, reading)
+		}
+	}
+	if err := s.backfillWaypoints(ctx, track, backfill); err != nil {
+		return nil, err
+	}
+	if len(readings) > 0 {
+		if err := s.crates.StoreBackfilledTelemetry(ctx, readings); err != nil {
+			s.logger.Error("failed to store backfilled crate telemetry", zap.String("booking_id", track.BookingID().String()), zap.Error(err))
+		}
+	}
+	result.Accepted = len(backfill)
+	result.Backfilled = len(backfill)
+
+	for _, i := range live {
+		if err := s.ingestPoint(ctx, track, runnerID, source, points[i]); err != nil {
+			if err := reject(i, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		result.Accepted++
+	}
+	sort.Slice(result.Rejected, func(a, b int) bool { return result.Rejected[a].Index < result.Rejected[b].Index })
+	return result, nil
+}
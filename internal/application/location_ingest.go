@@ -0,0 +1,198 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	privacyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/privacy"
+	telemetryDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/telemetry"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// eventLocationRejected is the CloudEvent type published when a submitted location is refused.
+const eventLocationRejected = "tracking.location_rejected"
+
+// Reasons a submitted location is rejected.
+const (
+	rejectRunnerMismatch = "runner_mismatch"
+	rejectTrackNotActive = "track_not_active"
+)
+
+// IngestWaypointRequest is a location submitted by a runner over REST.
+type IngestWaypointRequest struct {
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Speed     float64   `json:"speed"`
+	Heading   float64   `json:"heading"`
+	Timestamp time.Time `json:"timestamp"`
+	// Accuracy is the horizontal accuracy of the fix in meters; 0 if unknown.
+	Accuracy float64  `json:"accuracy,omitempty"`
+	Altitude *float64 `json:"altitude,omitempty"`
+	// Provider is the location source: gps, network or fused.
+	Provider string `json:"provider,omitempty"`
+	// Telemetry is an optional carrier reading taken with the location.
+	Telemetry *CrateTelemetry `json:"telemetry,omitempty"`
+}
+
+// LocationRejectedEvent is published when a location submission fails authorization,
+// so security tooling can flag spoofing attempts.
+type LocationRejectedEvent struct {
+	TrackID     uuid.UUID `json:"track_id"`
+	BookingID   uuid.UUID `json:"booking_id"`
+	RunnerID    uuid.UUID `json:"runner_id"`
+	SubmittedBy uuid.UUID `json:"submitted_by"`
+	Reason      string    `json:"reason"`
+	SourceIP    string    `json:"source_ip,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// IngestWaypoint records a location submitted by runnerID for a booking. The submission is
+// only accepted if runnerID is the runner assigned to the booking's track and the track is
+// still active; any other submission is refused and published as a security event.
+func (s *TrackingService) IngestWaypoint(
+	ctx context.Context,
+	bookingID, runnerID uuid.UUID,
+	sourceIP string,
+	req IngestWaypointRequest,
+) error {
+	track, err := s.authorizeLocationSubmission(ctx, bookingID, runnerID, sourceIP, sourceREST, 1)
+	if err != nil {
+		return err
+	}
+	return s.ingestPoint(ctx, track, runnerID, sourceREST, req)
+}
+
+// authorizeLocationSubmission returns the booking's track if runnerID may submit
+// locations for it. Refused submissions are published as security events; when the
+// trip is no longer in progress all count submitted locations are recorded as dropped.
+func (s *TrackingService) authorizeLocationSubmission(
+	ctx context.Context,
+	bookingID, runnerID uuid.UUID,
+	sourceIP, source string,
+	count int,
+) (*trackingDomain.TripTrack, error) {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	if track.RunnerID() != runnerID {
+		s.publishLocationRejected(ctx, track, runnerID, sourceIP, rejectRunnerMismatch)
+		return nil, apperror.New(apperror.CodeNotParticipant, "runner is not assigned to booking %s", bookingID)
+	}
+	if !track.IsActive() {
+		s.publishLocationRejected(ctx, track, runnerID, sourceIP, rejectTrackNotActive)
+		for i := 0; i < count; i++ {
+			s.dropLocation(track.RunnerID(), source, privacyDomain.DropTripNotActive)
+		}
+		return nil, apperror.New(apperror.CodeTrackingNotActive, "tracking for booking %s is %s", bookingID, track.Status())
+	}
+	return track, nil
+}
+
+// ingestPoint validates one submitted location of an authorized track and records it.
+func (s *TrackingService) ingestPoint(ctx context.Context, track *trackingDomain.TripTrack, runnerID uuid.UUID, source string, req IngestWaypointRequest) error {
+	waypoint, err := s.submittedWaypoint(track, source, req)
+	if err != nil {
+		return err
+	}
+	reading, err := s.crateReading(track, req, waypoint.RecordedAt)
+	if err != nil {
+		return err
+	}
+	if err := s.recordLocation(ctx, track, waypoint, events.RunnerLocationUpdateEvent{
+		RunnerID:  runnerID,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Speed:     req.Speed,
+		Heading:   req.Heading,
+		Timestamp: waypoint.RecordedAt,
+	}, false); err != nil {
+		return err
+	}
+
+	// The location is stored by now, so a failed reading is logged rather than reported.
+	if reading != nil {
+		if _, err := s.crates.RecordLiveTelemetry(ctx, track, reading); err != nil {
+			s.logger.Error("failed to record crate telemetry", zap.String("booking_id", track.BookingID().String()), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// crateReading validates the carrier reading attached to a submitted location. It
+// returns nil if there is none or crate telemetry is not in use.
+func (s *TrackingService) crateReading(track *trackingDomain.TripTrack, req IngestWaypointRequest, recordedAt time.Time) (*telemetryDomain.Reading, error) {
+	if req.Telemetry == nil || s.crates == nil {
+		return nil, nil
+	}
+	reading, err := telemetryDomain.NewReading(track.ID(), track.BookingID(), req.Telemetry.TemperatureC, req.Telemetry.HumidityPct, recordedAt)
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeValidation, err)
+	}
+	return reading, nil
+}
+
+// submittedWaypoint validates a submitted location of an authorized track and builds
+// its waypoint. A location without a timestamp is taken to be recorded now.
+func (s *TrackingService) submittedWaypoint(track *trackingDomain.TripTrack, source string, req IngestWaypointRequest) (trackingDomain.Waypoint, error) {
+	timestamp := req.Timestamp
+	if timestamp.IsZero() {
+		timestamp = s.clock.Now().UTC()
+	}
+	// Out-of-range coordinates keep failing as validation_failed below.
+	if reason := s.coordinates.Validate(track.Region(), source, req.Latitude, req.Longitude, req.Accuracy); reason != "" && reason != coordinateOutOfRange {
+		return trackingDomain.Waypoint{}, apperror.New(apperror.CodeCoordinateRejected, "coordinates (%f, %f) rejected: %s", req.Latitude, req.Longitude, reason)
+	}
+	waypoint, err := trackingDomain.NewWaypoint(s.ids.NewID(), req.Latitude, req.Longitude, req.Speed, req.Heading, timestamp)
+	if err == nil {
+		waypoint, err = waypoint.WithFix(req.Accuracy, req.Altitude, req.Provider)
+	}
+	if err != nil {
+		return trackingDomain.Waypoint{}, apperror.Wrap(apperror.CodeValidation, err)
+	}
+	if recordedBeforeStart(track, waypoint) {
+		s.dropLocation(track.RunnerID(), source, privacyDomain.DropBeforeTripStart)
+		return trackingDomain.Waypoint{}, apperror.New(apperror.CodeValidation, "location recorded at %s, before the trip started at %s",
+			waypoint.RecordedAt.Format(time.RFC3339), track.StartedAt().Format(time.RFC3339))
+	}
+	return waypoint, nil
+}
+
+// publishLocationRejected logs and publishes a refused location submission.
+func (s *TrackingService) publishLocationRejected(
+	ctx context.Context,
+	track *trackingDomain.TripTrack,
+	submittedBy uuid.UUID,
+	sourceIP, reason string,
+) {
+	s.logger.Warn("location submission rejected",
+		zap.String("booking_id", track.BookingID().String()),
+		zap.String("runner_id", track.RunnerID().String()),
+		zap.String("submitted_by", submittedBy.String()),
+		zap.String("source_ip", sourceIP),
+		zap.String("reason", reason),
+	)
+
+	evt := LocationRejectedEvent{
+		TrackID:     track.ID(),
+		BookingID:   track.BookingID(),
+		RunnerID:    track.RunnerID(),
+		SubmittedBy: submittedBy,
+		Reason:      reason,
+		SourceIP:    sourceIP,
+		OccurredAt:  s.clock.Now().UTC(),
+	}
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventLocationRejected, evt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish location rejected event", zap.Error(err))
+	}
+}
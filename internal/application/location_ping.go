@@ -0,0 +1,211 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// eventLocationPingRequested is the CloudEvent type published to the runner topic to ask
+// the runner app for an immediate location fix.
+const eventLocationPingRequested = "tracking.location_ping_requested"
+
+// Location ping results.
+const (
+	pingStatusLocated = "located"
+	pingStatusTimeout = "timeout"
+)
+
+const (
+	// defaultLocationPingTimeout is how long a ping waits for a fresh fix when not configured.
+	defaultLocationPingTimeout = 10 * time.Second
+
+	// locationPingPollInterval is how often a pending ping checks for a new position.
+	locationPingPollInterval = 500 * time.Millisecond
+
+	// locationPingCooldown is the minimum time between ping events for one booking;
+	// pings within it wait for the fix already requested.
+	locationPingCooldown = 10 * time.Second
+)
+
+// LocationPingRequestedEvent asks the runner app to report its location now.
+type LocationPingRequestedEvent struct {
+	PingID      uuid.UUID `json:"ping_id"`
+	BookingID   uuid.UUID `json:"booking_id"`
+	RunnerID    uuid.UUID `json:"runner_id"`
+	RequestedBy uuid.UUID `json:"requested_by"`
+	RequestedAt time.Time `json:"requested_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// LocationPingDTO is the result of a location ping. Position is the fresh fix when the
+// status is located, and the last known position (if any) on timeout.
+type LocationPingDTO struct {
+	PingID      uuid.UUID           `json:"ping_id"`
+	BookingID   uuid.UUID           `json:"booking_id"`
+	Status      string              `json:"status"`
+	RequestedAt time.Time           `json:"requested_at"`
+	Position    *CurrentPositionDTO `json:"position,omitempty"`
+}
+
+// RequestLocationPing asks the runner of a booking's active trip for a fresh location and
+// waits for the next accepted fix, up to the configured ping timeout.
+func (s *TrackingService) RequestLocationPing(ctx context.Context, bookingID, requestedBy uuid.UUID) (*LocationPingDTO, error) {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+	if !track.IsActive() {
+		return nil, apperror.New(apperror.CodeTrackingNotActive, "tracking for booking %s is %s", bookingID, track.Status())
+	}
+
+	timeout := s.config.LocationPingTimeout
+	if timeout <= 0 {
+		timeout = defaultLocationPingTimeout
+	}
+
+	baseline, err := s.latestWaypoint(ctx, track)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LocationPingDTO{
+		PingID:      s.ids.NewID(),
+		BookingID:   bookingID,
+		RequestedAt: s.clock.Now().UTC(),
+	}
+
+	if s.claimPing(bookingID, result.RequestedAt) {
+		if err := s.publishLocationPing(ctx, track, result, requestedBy, timeout); err != nil {
+			return nil, err
+		}
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(locationPingPollInterval)
+	defer ticker.Stop()
+
+	latest := baseline
+	for {
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			result.Status = pingStatusTimeout
+			result.Position = toPingPosition(track, latest)
+			return result, nil
+
+		case <-ticker.C:
+			wp, err := s.latestWaypoint(waitCtx, track)
+			if err != nil {
+				s.logger.Warn("failed to poll position for location ping", zap.Error(err))
+				continue
+			}
+			latest = wp
+			if wp != nil && (baseline == nil || wp.ID != baseline.ID) {
+				result.Status = pingStatusLocated
+				result.Position = toPingPosition(track, wp)
+				return result, nil
+			}
+		}
+	}
+}
+
+// claimPing reports whether a ping event should be published for a booking, which is
+// the case unless one was published within locationPingCooldown.
+func (s *TrackingService) claimPing(bookingID uuid.UUID, now time.Time) bool {
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+
+	state := s.liveStateLocked(bookingID)
+	if now.Sub(state.lastPingAt) < locationPingCooldown {
+		return false
+	}
+	state.lastPingAt = now
+	return true
+}
+
+// publishLocationPing publishes a LocationPingRequestedEvent to the runner topic of the
+// track's region.
+func (s *TrackingService) publishLocationPing(
+	ctx context.Context,
+	track *trackingDomain.TripTrack,
+	ping *LocationPingDTO,
+	requestedBy uuid.UUID,
+	timeout time.Duration,
+) error {
+	evt := LocationPingRequestedEvent{
+		PingID:      ping.PingID,
+		BookingID:   track.BookingID(),
+		RunnerID:    track.RunnerID(),
+		RequestedBy: requestedBy,
+		RequestedAt: ping.RequestedAt,
+		ExpiresAt:   ping.RequestedAt.Add(timeout),
+	}
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventLocationPingRequested, evt)
+	if err != nil {
+		return fmt.Errorf("failed to create location ping event: %w", err)
+	}
+	topic := events.TopicRunnerEvents
+	if track.Region() != "" {
+		topic += "." + track.Region()
+	}
+	if err := s.producer.PublishEvent(ctx, topic, cloudEvt); err != nil {
+		return fmt.Errorf("failed to publish location ping event: %w", err)
+	}
+
+	s.logger.Info("location ping requested",
+		zap.String("ping_id", evt.PingID.String()),
+		zap.String("booking_id", evt.BookingID.String()),
+		zap.String("runner_id", evt.RunnerID.String()),
+	)
+	return nil
+}
+
+// latestWaypoint returns the most recent waypoint of a trip, or nil if none was
+// recorded. It reads the position cache when available and otherwise only the newest
+// waypoint chunk.
+func (s *TrackingService) latestWaypoint(ctx context.Context, track *trackingDomain.TripTrack) (*trackingDomain.Waypoint, error) {
+	if s.positions != nil {
+		pos, err := s.positions.GetByBooking(ctx, track.BookingID())
+		if err == nil {
+			return &pos.Waypoint, nil
+		}
+	}
+
+	// The window after now holds no waypoints, so only the nearest one before it is returned.
+	now := s.clock.Now().UTC()
+	waypoints, err := s.repo.GetWaypointsBetween(ctx, track.ID(), now, now.Add(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest waypoint: %w", err)
+	}
+	if len(waypoints) == 0 {
+		return nil, nil
+	}
+	return &waypoints[len(waypoints)-1], nil
+}
+
+func toPingPosition(track *trackingDomain.TripTrack, wp *trackingDomain.Waypoint) *CurrentPositionDTO {
+	if wp == nil {
+		return nil
+	}
+	return &CurrentPositionDTO{
+		BookingID:  track.BookingID(),
+		RunnerID:   track.RunnerID(),
+		Latitude:   wp.Latitude,
+		Longitude:  wp.Longitude,
+		Speed:      wp.Speed,
+		Heading:    wp.Heading,
+		RecordedAt: wp.RecordedAt,
+	}
+}
@@ -0,0 +1,285 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	privacyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/privacy"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+const (
+	// privacyFlushInterval is how often dropped-location counts are written to the database.
+	privacyFlushInterval = time.Minute
+
+	// defaultDataWindowDays is the statement period when none is requested.
+	defaultDataWindowDays = 30
+
+	// maxDataWindowDays caps the statement period.
+	maxDataWindowDays = 366
+
+	// tripStartClockSkew is how far before a trip's start a location may be recorded,
+	// allowing for runner device clocks running behind the server's.
+	tripStartClockSkew = 30 * time.Second
+)
+
+// recordedBeforeStart reports whether a waypoint was recorded before its trip started.
+func recordedBeforeStart(track *trackingDomain.TripTrack, wp trackingDomain.Waypoint) bool {
+	return wp.RecordedAt.Before(track.StartedAt().Add(-tripStartClockSkew))
+}
+
+// DataWindowDTO is one period during which a runner's locations were stored: a trip
+// from its start until it ended, or until now if it is in progress.
+type DataWindowDTO struct {
+	BookingID     uuid.UUID  `json:"booking_id"`
+	TrackID       uuid.UUID  `json:"track_id"`
+	Status        string     `json:"status"`
+	StartedAt     time.Time  `json:"started_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty"`
+	PausedSeconds float64    `json:"paused_seconds"`
+	WaypointCount int        `json:"waypoint_count"`
+	// FirstRecordedAt and LastRecordedAt bound the stored waypoints.
+	FirstRecordedAt *time.Time `json:"first_recorded_at,omitempty"`
+	LastRecordedAt  *time.Time `json:"last_recorded_at,omitempty"`
+	// OutsideWindow is true if any stored waypoint was recorded outside the window,
+	// beyond the allowance for device clock skew.
+	OutsideWindow bool `json:"outside_window"`
+}
+
+// DroppedLocationsDTO counts a runner's locations dropped on one day for one reason.
+type DroppedLocationsDTO struct {
+	Date   string `json:"date"`
+	Reason string `json:"reason"`
+	Count  int64  `json:"count"`
+}
+
+// DataWindowStatementDTO lists when a runner's locations were stored during a period,
+// and how many were received outside those windows and dropped.
+type DataWindowStatementDTO struct {
+	RunnerID    uuid.UUID             `json:"runner_id"`
+	From        time.Time             `json:"from"`
+	To          time.Time             `json:"to"`
+	Windows     []DataWindowDTO       `json:"windows"`
+	Dropped     []DroppedLocationsDTO `json:"dropped"`
+	GeneratedAt time.Time             `json:"generated_at"`
+}
+
+// locationDrop identifies one dropped-location metric counter.
+type locationDrop struct {
+	source, reason string
+}
+
+// runnerDrop identifies one stored dropped-location count.
+type runnerDrop struct {
+	runnerID uuid.UUID
+	day      time.Time
+	reason   string
+}
+
+// LocationPrivacyService enforces that runner locations are only stored while one of
+// the runner's trips is in progress. Locations received outside are dropped and counted,
+// per runner and day in the database and per source and reason as metrics, and it
+// produces per-runner statements of the data windows for privacy audits.
+type LocationPrivacyService struct {
+	repo         privacyDomain.Repository
+	trackingRepo trackingDomain.TripTrackRepository
+	logger       *zap.Logger
+
+	mu      sync.Mutex
+	pending map[runnerDrop]int64
+	totals  map[locationDrop]uint64
+}
+
+// NewLocationPrivacyService creates a new LocationPrivacyService.
+func NewLocationPrivacyService(repo privacyDomain.Repository, trackingRepo trackingDomain.TripTrackRepository, logger *zap.Logger) *LocationPrivacyService {
+	return &LocationPrivacyService{
+		repo:         repo,
+		trackingRepo: trackingRepo,
+		logger:       logger,
+		pending:      make(map[runnerDrop]int64),
+		totals:       make(map[locationDrop]uint64),
+	}
+}
+
+// Dropped counts a location of runnerID received from source that was not stored.
+func (s *LocationPrivacyService) Dropped(runnerID uuid.UUID, source, reason string) {
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+
+	s.mu.Lock()
+	s.pending[runnerDrop{runnerID: runnerID, day: day, reason: reason}]++
+	s.totals[locationDrop{source: source, reason: reason}]++
+	s.mu.Unlock()
+}
+
+// Run writes dropped-location counts to the database until ctx is cancelled, then
+// writes the remaining ones.
+func (s *LocationPrivacyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(privacyFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.flush(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+// flush writes the pending counts, keeping them for the next flush on failure.
+func (s *LocationPrivacyService) flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[runnerDrop]int64)
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	counts := make([]privacyDomain.DropCount, 0, len(pending))
+	for key, n := range pending {
+		counts = append(counts, privacyDomain.DropCount{RunnerID: key.runnerID, Day: key.day, Reason: key.reason, Count: n})
+	}
+	if err := s.repo.AddDrops(ctx, counts); err != nil {
+		s.logger.Warn("failed to store dropped location counts", zap.Int("rows", len(counts)), zap.Error(err))
+		s.mu.Lock()
+		for key, n := range pending {
+			s.pending[key] += n
+		}
+		s.mu.Unlock()
+	}
+}
+
+// GetDataWindowStatement returns a runner's data windows and dropped locations for the
+// days in [from, to). Empty dates default to the last 30 days; dates are YYYY-MM-DD in UTC.
+func (s *LocationPrivacyService) GetDataWindowStatement(ctx context.Context, runnerID uuid.UUID, fromStr, toStr string) (*DataWindowStatementDTO, error) {
+	now := time.Now().UTC()
+	to := now.Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if toStr != "" {
+		t, err := time.Parse(digestDateLayout, toStr)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeInvalidRequest, "to must be a date in YYYY-MM-DD format")
+		}
+		to = t.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -defaultDataWindowDays)
+	if fromStr != "" {
+		f, err := time.Parse(digestDateLayout, fromStr)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeInvalidRequest, "from must be a date in YYYY-MM-DD format")
+		}
+		from = f
+	}
+	if !from.Before(to) {
+		return nil, apperror.New(apperror.CodeInvalidRequest, "from must not be after to")
+	}
+	if to.Sub(from) > maxDataWindowDays*24*time.Hour {
+		return nil, apperror.New(apperror.CodeInvalidRequest, "the period must be at most %d days", maxDataWindowDays)
+	}
+
+	tracks, err := s.trackingRepo.FindByRunnerIDSince(ctx, runnerID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trips: %w", err)
+	}
+
+	statement := &DataWindowStatementDTO{
+		RunnerID:    runnerID,
+		From:        from,
+		To:          to,
+		Windows:     []DataWindowDTO{},
+		Dropped:     []DroppedLocationsDTO{},
+		GeneratedAt: now,
+	}
+	for _, track := range tracks {
+		if !track.StartedAt().Before(to) {
+			continue
+		}
+		window, err := s.dataWindow(ctx, track)
+		if err != nil {
+			return nil, err
+		}
+		statement.Windows = append(statement.Windows, *window)
+	}
+
+	drops, err := s.repo.ListDrops(ctx, runnerID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dropped locations: %w", err)
+	}
+	for _, d := range drops {
+		statement.Dropped = append(statement.Dropped, DroppedLocationsDTO{
+			Date:   d.Day.Format(digestDateLayout),
+			Reason: d.Reason,
+			Count:  d.Count,
+		})
+	}
+	return statement, nil
+}
+
+// dataWindow describes the window of one trip and checks its stored waypoints against it.
+func (s *LocationPrivacyService) dataWindow(ctx context.Context, track *trackingDomain.TripTrack) (*DataWindowDTO, error) {
+	stats, err := s.trackingRepo.GetWaypointStats(ctx, track.ID(), trackingDomain.WaypointStatsOptions{
+		StopSpeedKmh:       stopSpeedKmh,
+		PickupRadiusMeters: pickupRadiusMeters,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waypoint stats: %w", err)
+	}
+
+	window := &DataWindowDTO{
+		BookingID:     track.BookingID(),
+		TrackID:       track.ID(),
+		Status:        string(track.Status()),
+		StartedAt:     track.StartedAt(),
+		PausedSeconds: track.PausedDuration().Seconds(),
+		WaypointCount: stats.WaypointCount,
+	}
+	if at := track.CompletedAt(); at != nil {
+		window.EndedAt = at
+	} else if c := track.Cancellation(); c != nil {
+		window.EndedAt = &c.CancelledAt
+	}
+	if stats.WaypointCount > 0 {
+		first, last := stats.FirstAt, stats.LastAt
+		window.FirstRecordedAt, window.LastRecordedAt = &first, &last
+		window.OutsideWindow = first.Before(track.StartedAt().Add(-tripStartClockSkew)) ||
+			(window.EndedAt != nil && last.After(window.EndedAt.Add(tripStartClockSkew)))
+	}
+	return window, nil
+}
+
+// ServeHTTP exports dropped-location counters in the Prometheus text format.
+func (s *LocationPrivacyService) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	keys := make([]locationDrop, 0, len(s.totals))
+	counts := make(map[locationDrop]uint64, len(s.totals))
+	for k, n := range s.totals {
+		keys = append(keys, k)
+		counts[k] = n
+	}
+	s.mu.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].source != keys[j].source {
+			return keys[i].source < keys[j].source
+		}
+		return keys[i].reason < keys[j].reason
+	})
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP tracking_locations_dropped_total Runner locations dropped because no trip was in progress.")
+	fmt.Fprintln(w, "# TYPE tracking_locations_dropped_total counter")
+	for _, k := range keys {
+		fmt.Fprintf(w, "tracking_locations_dropped_total{source=%q,reason=%q} %d\n", k.source, k.reason, counts[k])
+	}
+}
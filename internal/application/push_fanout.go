@@ -0,0 +1,112 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	participantDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/participant"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+// eventNotificationRequested is the CloudEvent type asking the notification service to
+// send a mobile push.
+const eventNotificationRequested = "notification.requested"
+
+// maxPushBodyLength caps the characters of a message quoted in a push body.
+const maxPushBodyLength = 120
+
+// Push notification kinds.
+const (
+	PushKindChatMessage = "chat_message"
+	PushKindGeofence    = "geofence_entered"
+)
+
+// NotificationRequestedEvent asks the notification service to push a notification to a
+// user's mobile devices. Data holds string values only, as push payloads require.
+type NotificationRequestedEvent struct {
+	NotificationID uuid.UUID         `json:"notification_id"`
+	RecipientID    uuid.UUID         `json:"recipient_id"`
+	BookingID      uuid.UUID         `json:"booking_id"`
+	Kind           string            `json:"kind"`
+	Title          string            `json:"title"`
+	Body           string            `json:"body"`
+	Data           map[string]string `json:"data,omitempty"`
+	OccurredAt     time.Time         `json:"occurred_at"`
+}
+
+// PushNotification is the content of a push sent to the offline participants of a booking.
+type PushNotification struct {
+	Kind  string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// PushFanout requests mobile pushes for booking participants that have no WebSocket
+// connection open in the booking's room, so they learn of events they would otherwise
+// only see live. Presence is only known for connections to this instance.
+type PushFanout struct {
+	participants participantDomain.Repository
+	hub          *ws.Hub
+	producer     EventPublisher
+	topic        string
+	logger       *zap.Logger
+}
+
+// NewPushFanout creates a PushFanout publishing NotificationRequested events to topic.
+func NewPushFanout(participants participantDomain.Repository, hub *ws.Hub, producer EventPublisher, topic string, logger *zap.Logger) *PushFanout {
+	return &PushFanout{participants: participants, hub: hub, producer: producer, topic: topic, logger: logger}
+}
+
+// NotifyOffline requests a push for each participant of the booking other than exclude,
+// usually the user who caused the event, that is not connected. Failures are logged.
+func (f *PushFanout) NotifyOffline(ctx context.Context, bookingID, exclude uuid.UUID, n PushNotification) {
+	p, err := f.participants.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		f.logger.Debug("no participants known for push notification",
+			zap.String("booking_id", bookingID.String()),
+		)
+		return
+	}
+
+	for _, recipient := range []uuid.UUID{p.OwnerID, p.RunnerID} {
+		if recipient == uuid.Nil || recipient == exclude || f.hub.IsUserConnected(bookingID, recipient) {
+			continue
+		}
+		evt := NotificationRequestedEvent{
+			NotificationID: uuid.New(),
+			RecipientID:    recipient,
+			BookingID:      bookingID,
+			Kind:           n.Kind,
+			Title:          n.Title,
+			Body:           n.Body,
+			Data:           n.Data,
+			OccurredAt:     time.Now().UTC(),
+		}
+		cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventNotificationRequested, evt)
+		if err != nil {
+			f.logger.Error("failed to create cloud event", zap.Error(err))
+			continue
+		}
+		if err := f.producer.PublishEvent(ctx, f.topic, cloudEvt); err != nil {
+			f.logger.Error("failed to publish notification request",
+				zap.String("booking_id", bookingID.String()),
+				zap.String("kind", n.Kind),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// pushBody shortens content to fit a push notification.
+func pushBody(content string) string {
+	runes := []rune(content)
+	if len(runes) <= maxPushBodyLength {
+		return content
+	}
+	return string(runes[:maxPushBodyLength-1]) + "…"
+}
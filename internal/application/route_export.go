@@ -0,0 +1,229 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// Route export formats.
+const (
+	// RouteFormatGeoJSON selects the route as a GeoJSON geometry, the default.
+	RouteFormatGeoJSON = "geojson"
+	// RouteFormatResampled selects the smoothed route resampled at a fixed distance
+	// interval, for training ETA models.
+	RouteFormatResampled = "resampled"
+	// RouteFormatGPX selects the route as a GPX 1.1 track, for GIS tools.
+	RouteFormatGPX = "gpx"
+	// RouteFormatKML selects the route as a KML 2.2 track, for GIS tools.
+	RouteFormatKML = "kml"
+)
+
+// RouteDocument is an encoded route and its media type.
+type RouteDocument struct {
+	ContentType string
+	// Extension is the file extension for downloads, without a dot.
+	Extension string
+	Body      []byte
+}
+
+const (
+	// DefaultResampleInterval is the distance between resampled points when not requested.
+	DefaultResampleInterval = 50.0
+	// MinResampleInterval and MaxResampleInterval bound the requested interval, in meters.
+	MinResampleInterval = 5.0
+	MaxResampleInterval = 1000.0
+
+	// smoothingMaxTurnDeg is the course change above which a waypoint is treated as a
+	// turn and left unsmoothed.
+	smoothingMaxTurnDeg = 30.0
+)
+
+// resampledRouteProperties are the per-point attributes of a resampled route, in the
+// same order as its coordinates.
+type resampledRouteProperties struct {
+	IntervalMeters  float64   `json:"interval_meters"`
+	Timestamps      []string  `json:"timestamps"`
+	DistancesMeters []float64 `json:"distances_meters"`
+	Speeds          []float64 `json:"speeds"`
+	Headings        []float64 `json:"headings"`
+}
+
+// validateResampleOptions checks the options of a resampled route export and applies
+// the default interval.
+func validateResampleOptions(opts *RouteOptions) error {
+	if opts.Bounds != nil {
+		return apperror.New(apperror.CodeValidation, "bbox cannot be combined with format=%s", RouteFormatResampled)
+	}
+	if opts.Tolerance > 0 || opts.MaxPoints > 0 {
+		return apperror.New(apperror.CodeValidation, "tolerance and max_points cannot be combined with format=%s", RouteFormatResampled)
+	}
+	if opts.IntervalMeters == 0 {
+		opts.IntervalMeters = DefaultResampleInterval
+	}
+	if opts.IntervalMeters < MinResampleInterval || opts.IntervalMeters > MaxResampleInterval {
+		return apperror.New(apperror.CodeValidation, "interval must be between %g and %g meters", MinResampleInterval, MaxResampleInterval)
+	}
+	return nil
+}
+
+// resampledRouteGeoJSON smooths waypoints, resamples them every intervalM meters and
+// encodes the result as a GeoJSON LineString Feature whose properties hold each
+// point's time, distance along the route, speed and heading.
+func resampledRouteGeoJSON(waypoints []trackingDomain.Waypoint, intervalM float64) (string, error) {
+	points := trackingDomain.ResampleWaypoints(trackingDomain.SmoothWaypoints(waypoints, smoothingMaxTurnDeg), intervalM)
+
+	coordinates := make([][]float64, len(points))
+	props := resampledRouteProperties{
+		IntervalMeters:  intervalM,
+		Timestamps:      make([]string, len(points)),
+		DistancesMeters: make([]float64, len(points)),
+		Speeds:          make([]float64, len(points)),
+		Headings:        make([]float64, len(points)),
+	}
+	var distance float64
+	for i, p := range points {
+		if i > 0 {
+			prev := points[i-1]
+			distance += trackingDomain.DistanceMeters(prev.Latitude, prev.Longitude, p.Latitude, p.Longitude)
+		}
+		coordinates[i] = []float64{p.Longitude, p.Latitude}
+		props.Timestamps[i] = p.RecordedAt.UTC().Format("2006-01-02T15:04:05.000Z07:00")
+		props.DistancesMeters[i] = math.Round(distance*10) / 10
+		props.Speeds[i] = math.Round(p.Speed*100) / 100
+		props.Headings[i] = math.Round(p.Heading*10) / 10
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"type": "Feature",
+		"geometry": map[string]interface{}{
+			"type":        "LineString",
+			"coordinates": coordinates,
+		},
+		"properties": props,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal GeoJSON: %w", err)
+	}
+	return string(data), nil
+}
+
+// ExportRoute returns a booking's route in opts.Format. GPX and KML routes include each
+// waypoint's time, speed and heading and are sliced and simplified like GeoJSON routes;
+// a bounding box yields one track segment per contiguous run of chunks inside it.
+func (s *TrackingService) ExportRoute(ctx context.Context, bookingID uuid.UUID, opts RouteOptions) (*RouteDocument, error) {
+	if opts.Format != RouteFormatGPX && opts.Format != RouteFormatKML {
+		geoJSON, err := s.GetRouteGeoJSON(ctx, bookingID, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &RouteDocument{ContentType: "application/geo+json", Extension: "geojson", Body: []byte(geoJSON)}, nil
+	}
+
+	if opts.Bounds != nil && (!opts.From.IsZero() || !opts.To.IsZero()) {
+		return nil, apperror.New(apperror.CodeValidation, "bbox cannot be combined with from or to")
+	}
+	if !opts.From.IsZero() && !opts.To.IsZero() && opts.To.Before(opts.From) {
+		return nil, apperror.New(apperror.CodeValidation, "to must not be before from")
+	}
+	if opts.Matched && s.matcher == nil {
+		return nil, apperror.New(apperror.CodeMapMatchingUnavailable, "map matching is not configured")
+	}
+
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	var lines [][]trackingDomain.Waypoint
+	switch {
+	case opts.Bounds != nil:
+		chunks, err := s.repo.GetChunksInBounds(ctx, track.ID(), *opts.Bounds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get waypoint chunks: %w", err)
+		}
+		if lines, err = s.chunkRuns(ctx, chunks, opts); err != nil {
+			return nil, err
+		}
+	case !opts.From.IsZero() || !opts.To.IsZero():
+		from, to := opts.From, opts.To
+		if from.IsZero() {
+			from = track.StartedAt()
+		}
+		if to.IsZero() {
+			to = s.clock.Now().UTC()
+		}
+		waypoints, err := s.repo.GetWaypointsBetween(ctx, track.ID(), from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get waypoints: %w", err)
+		}
+		if waypoints, err = s.matchWaypoints(ctx, waypoints, opts); err != nil {
+			return nil, err
+		}
+		lines = [][]trackingDomain.Waypoint{trackingDomain.SimplifyWaypoints(waypoints, opts.Tolerance, opts.MaxPoints)}
+	default:
+		waypoints, err := s.repo.GetWaypoints(ctx, track.ID())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get waypoints: %w", err)
+		}
+		if waypoints, err = s.matchWaypoints(ctx, waypoints, opts); err != nil {
+			return nil, err
+		}
+		lines = [][]trackingDomain.Waypoint{trackingDomain.SimplifyWaypoints(waypoints, opts.Tolerance, opts.MaxPoints)}
+	}
+
+	name := fmt.Sprintf("Booking %s", track.BookingID())
+	if opts.Format == RouteFormatGPX {
+		body, err := trackingDomain.RouteGPX(name, track.StartedAt(), lines)
+		if err != nil {
+			return nil, err
+		}
+		return &RouteDocument{ContentType: "application/gpx+xml", Extension: "gpx", Body: body}, nil
+	}
+	body, err := trackingDomain.RouteKML(name, lines)
+	if err != nil {
+		return nil, err
+	}
+	return &RouteDocument{ContentType: "application/vnd.google-earth.kml+xml", Extension: "kml", Body: body}, nil
+}
+
+// chunkRuns joins chunks with consecutive sequence numbers into runs of waypoints,
+// each matched to roads and simplified according to opts.
+func (s *TrackingService) chunkRuns(ctx context.Context, chunks []trackingDomain.WaypointChunk, opts RouteOptions) ([][]trackingDomain.Waypoint, error) {
+	var lines [][]trackingDomain.Waypoint
+	for i, chunk := range chunks {
+		if i == 0 || chunk.Seq != chunks[i-1].Seq+1 {
+			lines = append(lines, nil)
+		}
+		lines[len(lines)-1] = append(lines[len(lines)-1], chunk.Waypoints...)
+	}
+	for i := range lines {
+		matched, err := s.matchWaypoints(ctx, lines[i], opts)
+		if err != nil {
+			return nil, err
+		}
+		lines[i] = trackingDomain.SimplifyWaypoints(matched, opts.Tolerance, opts.MaxPoints)
+	}
+	return lines, nil
+}
+
+// matchWaypoints snaps waypoints to roads if opts asks for a matched route, and returns
+// them unchanged otherwise.
+func (s *TrackingService) matchWaypoints(ctx context.Context, waypoints []trackingDomain.Waypoint, opts RouteOptions) ([]trackingDomain.Waypoint, error) {
+	if !opts.Matched || len(waypoints) < 2 {
+		return waypoints, nil
+	}
+	matched, err := s.matcher.MatchWaypoints(ctx, waypoints)
+	if err != nil {
+		s.logger.Warn("map matching failed", zap.Error(err))
+		return nil, apperror.New(apperror.CodeMapMatchingUnavailable, "map matching failed, try again later or request the raw route")
+	}
+	return matched, nil
+}
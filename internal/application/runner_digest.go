@@ -0,0 +1,344 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// eventRunnerDailyDigest is the CloudEvent type published with a runner's end-of-day digest.
+const eventRunnerDailyDigest = "tracking.runner_daily_digest"
+
+// DailySummaryTopic is the topic the analytics and payout pipelines consume runners'
+// daily tracking summaries from.
+const DailySummaryTopic = "tracking.daily-summaries"
+
+// eventTrackingDailySummary is the CloudEvent type of a runner's daily tracking summary.
+const eventTrackingDailySummary = "tracking.daily_summary"
+
+// digestDateLayout is the format of digest dates.
+const digestDateLayout = "2006-01-02"
+
+// digestNamespace derives stable digest IDs from a runner and a date, so consumers can
+// drop digests published more than once.
+var digestNamespace = uuid.MustParse("5f0b7c1e-2f43-4c41-9a36-9b1d8f6e0d52")
+
+// RunnerDigestConfig controls the end-of-day runner digest job.
+type RunnerDigestConfig struct {
+	// Location sets the day boundaries; nil means UTC.
+	Location *time.Location
+	// RunAfter is how long after midnight the previous day's digests are published.
+	RunAfter time.Duration
+	// ExcludeRunners are runners no digest is published for, such as the probe runner.
+	ExcludeRunners []uuid.UUID
+}
+
+// RunnerDigestDTO summarizes a runner's day.
+type RunnerDigestDTO struct {
+	DigestID       uuid.UUID `json:"digest_id"`
+	RunnerID       uuid.UUID `json:"runner_id"`
+	Date           string    `json:"date"`
+	Timezone       string    `json:"timezone"`
+	Trips          int       `json:"trips"`
+	CompletedTrips int       `json:"completed_trips"`
+	CancelledTrips int       `json:"cancelled_trips"`
+	// Cancellations are the day's cancelled trips with their reasons.
+	Cancellations   []CancelledTripDTO `json:"cancellations,omitempty"`
+	DistanceKm      float64            `json:"distance_km"`
+	ActiveSeconds   float64            `json:"active_seconds"`
+	MovingSeconds   float64            `json:"moving_seconds"`
+	IdleSeconds     float64            `json:"idle_seconds"`
+	FirstActivityAt *time.Time         `json:"first_activity_at,omitempty"`
+	LastActivityAt  *time.Time         `json:"last_activity_at,omitempty"`
+	GeneratedAt     time.Time          `json:"generated_at"`
+}
+
+// CancelledTripDTO is a trip cancelled during a runner's day, with its reason code and
+// note.
+type CancelledTripDTO struct {
+	BookingID uuid.UUID `json:"booking_id"`
+	CancellationDTO
+}
+
+// TrackingDailySummaryEvent is a runner's daily totals for the data warehouse. It is
+// compiled like the digest; AvgSpeedKmh is the distance over the moving time.
+type TrackingDailySummaryEvent struct {
+	SummaryID      uuid.UUID          `json:"summary_id"`
+	RunnerID       uuid.UUID          `json:"runner_id"`
+	Date           string             `json:"date"`
+	Timezone       string             `json:"timezone"`
+	Trips          int                `json:"trips"`
+	CompletedTrips int                `json:"completed_trips"`
+	CancelledTrips int                `json:"cancelled_trips"`
+	Cancellations  []CancelledTripDTO `json:"cancellations,omitempty"`
+	DistanceKm     float64            `json:"distance_km"`
+	ActiveSeconds  float64            `json:"active_seconds"`
+	MovingSeconds  float64            `json:"moving_seconds"`
+	AvgSpeedKmh    float64            `json:"avg_speed_kmh"`
+	OccurredAt     time.Time          `json:"occurred_at"`
+}
+
+// RunnerDigestService compiles each runner's daily digest for payroll and the runner
+// app, and publishes the digests and daily summaries of the previous day once a day.
+type RunnerDigestService struct {
+	repo     trackingDomain.TripTrackRepository
+	producer EventPublisher
+	config   RunnerDigestConfig
+	logger   *zap.Logger
+}
+
+// NewRunnerDigestService creates a new RunnerDigestService.
+func NewRunnerDigestService(
+	repo trackingDomain.TripTrackRepository,
+	producer EventPublisher,
+	config RunnerDigestConfig,
+	logger *zap.Logger,
+) *RunnerDigestService {
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	return &RunnerDigestService{
+		repo:     repo,
+		producer: producer,
+		config:   config,
+		logger:   logger.With(zap.String("component", "runner_digest")),
+	}
+}
+
+// GetDailyDigest returns a runner's digest for a date (YYYY-MM-DD in the digest time
+// zone). An empty date means today, which is compiled up to now.
+func (s *RunnerDigestService) GetDailyDigest(ctx context.Context, runnerID uuid.UUID, date string) (*RunnerDigestDTO, error) {
+	dayStart := startOfDay(time.Now().In(s.config.Location))
+	if date != "" {
+		d, err := time.ParseInLocation(digestDateLayout, date, s.config.Location)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeInvalidRequest, "date must be formatted as YYYY-MM-DD")
+		}
+		dayStart = d
+	}
+	if dayStart.After(time.Now()) {
+		return nil, apperror.New(apperror.CodeValidation, "date %s is in the future", date)
+	}
+	return s.compile(ctx, runnerID, dayStart)
+}
+
+// Run publishes the previous day's digests shortly after each midnight until ctx is
+// cancelled.
+func (s *RunnerDigestService) Run(ctx context.Context) {
+	for {
+		now := time.Now().In(s.config.Location)
+		next := startOfDay(now).AddDate(0, 0, 1).Add(s.config.RunAfter)
+		if today := startOfDay(now).Add(s.config.RunAfter); now.Before(today) {
+			next = today
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		day := startOfDay(next).AddDate(0, 0, -1)
+		if err := s.PublishDay(ctx, day); err != nil && ctx.Err() == nil {
+			s.logger.Error("failed to publish runner digests", zap.String("date", day.Format(digestDateLayout)), zap.Error(err))
+		}
+	}
+}
+
+// PublishDay compiles and publishes the digest and daily summary of every runner active
+// on the day starting at dayStart.
+func (s *RunnerDigestService) PublishDay(ctx context.Context, dayStart time.Time) error {
+	runnerIDs, err := s.repo.FindRunnerIDsActiveBetween(ctx, dayStart, dayStart.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	published := 0
+	for _, runnerID := range runnerIDs {
+		if s.excluded(runnerID) {
+			continue
+		}
+		digest, err := s.compile(ctx, runnerID, dayStart)
+		if err != nil {
+			s.logger.Warn("failed to compile runner digest", zap.String("runner_id", runnerID.String()), zap.Error(err))
+			continue
+		}
+		if digest.Trips == 0 {
+			continue
+		}
+
+		if err := s.publish(ctx, events.TopicTrackingEvents, eventRunnerDailyDigest, digest); err != nil {
+			s.logger.Error("failed to publish runner digest", zap.String("runner_id", runnerID.String()), zap.Error(err))
+		} else {
+			published++
+		}
+		if err := s.publish(ctx, DailySummaryTopic, eventTrackingDailySummary, toDailySummary(digest)); err != nil {
+			s.logger.Error("failed to publish daily summary", zap.String("runner_id", runnerID.String()), zap.Error(err))
+		}
+	}
+
+	s.logger.Info("runner digests published",
+		zap.String("date", dayStart.Format(digestDateLayout)),
+		zap.Int("runners", published),
+	)
+	return nil
+}
+
+// publish publishes data as a CloudEvent of the given type.
+func (s *RunnerDigestService) publish(ctx context.Context, topic, eventType string, data interface{}) error {
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventType, data)
+	if err != nil {
+		return fmt.Errorf("failed to create cloud event: %w", err)
+	}
+	return s.producer.PublishEvent(ctx, topic, cloudEvt)
+}
+
+// toDailySummary derives a runner's daily summary from their digest. The summary ID is
+// derived from the runner and date like the digest ID, so consumers can drop summaries
+// published more than once.
+func toDailySummary(digest *RunnerDigestDTO) TrackingDailySummaryEvent {
+	summary := TrackingDailySummaryEvent{
+		SummaryID:      uuid.NewSHA1(digestNamespace, []byte("summary/"+digest.RunnerID.String()+"/"+digest.Date)),
+		RunnerID:       digest.RunnerID,
+		Date:           digest.Date,
+		Timezone:       digest.Timezone,
+		Trips:          digest.Trips,
+		CompletedTrips: digest.CompletedTrips,
+		CancelledTrips: digest.CancelledTrips,
+		Cancellations:  digest.Cancellations,
+		DistanceKm:     digest.DistanceKm,
+		ActiveSeconds:  digest.ActiveSeconds,
+		MovingSeconds:  digest.MovingSeconds,
+		OccurredAt:     time.Now().UTC(),
+	}
+	if digest.MovingSeconds > 0 {
+		summary.AvgSpeedKmh = math.Round(digest.DistanceKm/(digest.MovingSeconds/3600)*10) / 10
+	}
+	return summary
+}
+
+// compile builds a runner's digest for the day starting at dayStart. Only waypoints
+// recorded during the day count, so trips spanning midnight are split between days.
+func (s *RunnerDigestService) compile(ctx context.Context, runnerID uuid.UUID, dayStart time.Time) (*RunnerDigestDTO, error) {
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	now := time.Now()
+	if dayEnd.After(now) {
+		dayEnd = now
+	}
+	date := dayStart.Format(digestDateLayout)
+
+	tracks, err := s.repo.FindByRunnerIDSince(ctx, runnerID, dayStart)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &RunnerDigestDTO{
+		DigestID:    uuid.NewSHA1(digestNamespace, []byte(runnerID.String()+"/"+date)),
+		RunnerID:    runnerID,
+		Date:        date,
+		Timezone:    s.config.Location.String(),
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	var active, moving []drivingInterval
+	var distanceKm float64
+	for _, track := range tracks {
+		if !track.StartedAt().Before(dayEnd) {
+			continue
+		}
+
+		waypoints, err := s.repo.GetWaypointsBetween(ctx, track.ID(), dayStart, dayEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get waypoints: %w", err)
+		}
+		waypoints = waypointsWithin(waypoints, dayStart, dayEnd)
+
+		startedToday := !track.StartedAt().Before(dayStart)
+		if len(waypoints) == 0 && !startedToday {
+			continue
+		}
+		digest.Trips++
+		if at := track.CompletedAt(); at != nil && !at.Before(dayStart) && at.Before(dayEnd) {
+			digest.CompletedTrips++
+		}
+		if c := track.Cancellation(); c != nil && !c.CancelledAt.Before(dayStart) && c.CancelledAt.Before(dayEnd) {
+			digest.CancelledTrips++
+			digest.Cancellations = append(digest.Cancellations, CancelledTripDTO{
+				BookingID:       track.BookingID(),
+				CancellationDTO: CancellationDTO{Reason: string(c.Reason), Note: c.Note, CancelledAt: c.CancelledAt},
+			})
+		}
+
+		if len(waypoints) < 2 {
+			continue
+		}
+		distanceKm += routeLengthKm(ctx, s.repo, s.logger, track.ID(), dayStart, dayEnd, waypoints)
+		active = append(active, drivingInterval{start: waypoints[0].RecordedAt, end: waypoints[len(waypoints)-1].RecordedAt})
+		for _, seg := range buildSegments(waypoints, nil) {
+			if seg.Kind == segmentLeg {
+				moving = append(moving, drivingInterval{start: seg.StartedAt, end: seg.EndedAt})
+			}
+		}
+	}
+
+	activeMerged := mergeIntervals(active)
+	activeTotal := totalDuration(activeMerged)
+	movingTotal := totalDuration(mergeIntervals(moving))
+
+	digest.DistanceKm = math.Round(distanceKm*1000) / 1000
+	digest.ActiveSeconds = activeTotal.Seconds()
+	digest.MovingSeconds = movingTotal.Seconds()
+	digest.IdleSeconds = math.Max(0, (activeTotal - movingTotal).Seconds())
+	if len(activeMerged) > 0 {
+		first, last := activeMerged[0].start, activeMerged[len(activeMerged)-1].end
+		digest.FirstActivityAt, digest.LastActivityAt = &first, &last
+	}
+	return digest, nil
+}
+
+// excluded reports whether no digest should be published for a runner.
+func (s *RunnerDigestService) excluded(runnerID uuid.UUID) bool {
+	for _, id := range s.config.ExcludeRunners {
+		if id == runnerID {
+			return true
+		}
+	}
+	return false
+}
+
+// waypointsWithin drops the waypoints recorded outside [from, to).
+func waypointsWithin(waypoints []trackingDomain.Waypoint, from, to time.Time) []trackingDomain.Waypoint {
+	result := waypoints[:0]
+	for _, wp := range waypoints {
+		if !wp.RecordedAt.Before(from) && wp.RecordedAt.Before(to) {
+			result = append(result, wp)
+		}
+	}
+	return result
+}
+
+// totalDuration sums the lengths of intervals.
+func totalDuration(intervals []drivingInterval) time.Duration {
+	var total time.Duration
+	for _, iv := range intervals {
+		total += iv.end.Sub(iv.start)
+	}
+	return total
+}
+
+// startOfDay returns midnight of t's day in t's location.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
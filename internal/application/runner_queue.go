@@ -0,0 +1,119 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// SetDestinationRequest holds the drop-off location for a trip.
+type SetDestinationRequest struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// QueuedTripDTO represents a runner's trip in arrival order.
+type QueuedTripDTO struct {
+	Position           int        `json:"position"`
+	TrackID            uuid.UUID  `json:"track_id"`
+	BookingID          uuid.UUID  `json:"booking_id"`
+	StartedAt          time.Time  `json:"started_at"`
+	RemainingKm        *float64   `json:"remaining_km,omitempty"`
+	EstimatedArrivalAt *time.Time `json:"estimated_arrival_at,omitempty"`
+}
+
+// RunnerQueueDTO is the ordered list of a runner's active trips.
+type RunnerQueueDTO struct {
+	RunnerID uuid.UUID       `json:"runner_id"`
+	SpeedKmh float64         `json:"assumed_speed_kmh"`
+	Trips    []QueuedTripDTO `json:"trips"`
+}
+
+// SetDestination records the drop-off location for a booking's active trip.
+func (s *TrackingService) SetDestination(ctx context.Context, bookingID uuid.UUID, req SetDestinationRequest) error {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+	if !track.IsActive() {
+		return apperror.New(apperror.CodeTrackingNotActive, "tracking for booking %s is %s", bookingID, track.Status())
+	}
+
+	dest, err := trackingDomain.NewLocation(req.Latitude, req.Longitude)
+	if err != nil {
+		return apperror.Wrap(apperror.CodeValidation, err)
+	}
+
+	if err := track.SetDestination(dest, s.clock.Now()); err != nil {
+		return err
+	}
+
+	track.IncrementVersion(s.clock.Now())
+	if err := s.repo.Update(ctx, track); err != nil {
+		return fmt.Errorf("failed to update tracking: %w", err)
+	}
+	return nil
+}
+
+// GetRunnerQueue returns a runner's active trips in arrival order with chained ETAs.
+// The first trip's ETA is measured from the runner's latest position; each following
+// trip is measured from the previous trip's destination. ETAs stop at the first trip
+// whose destination is unknown.
+func (s *TrackingService) GetRunnerQueue(ctx context.Context, runnerID uuid.UUID) (*RunnerQueueDTO, error) {
+	tracks, err := s.repo.FindAllActiveByRunnerID(ctx, runnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RunnerQueueDTO{
+		RunnerID: runnerID,
+		SpeedKmh: defaultSpeedKmh,
+		Trips:    make([]QueuedTripDTO, 0, len(tracks)),
+	}
+	if len(tracks) == 0 {
+		return result, nil
+	}
+
+	// The current position and speed come from the trip being driven right now.
+	var from *trackingDomain.Location
+	waypoints, err := s.repo.GetWaypoints(ctx, tracks[0].ID())
+	if err != nil {
+		s.logger.Warn("failed to load waypoints for runner queue", zap.Error(err))
+	}
+	if len(waypoints) > 0 {
+		last := waypoints[len(waypoints)-1]
+		from = &trackingDomain.Location{Latitude: last.Latitude, Longitude: last.Longitude}
+		result.SpeedKmh = averageRecentSpeed(waypoints)
+	}
+
+	cursor := s.clock.Now().UTC()
+	for i, track := range tracks {
+		item := QueuedTripDTO{
+			Position:  i + 1,
+			TrackID:   track.ID(),
+			BookingID: track.BookingID(),
+			StartedAt: track.StartedAt(),
+		}
+
+		dest := track.Destination()
+		if from != nil && dest != nil {
+			km := math.Round(haversineKm(from.Latitude, from.Longitude, dest.Latitude, dest.Longitude)*1000) / 1000
+			cursor = cursor.Add(time.Duration(km / result.SpeedKmh * float64(time.Hour)))
+			eta := cursor
+			item.RemainingKm = &km
+			item.EstimatedArrivalAt = &eta
+		}
+		from = dest
+
+		result.Trips = append(result.Trips, item)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,276 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	alertDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/alert"
+	inboxDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/inbox"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+const (
+	// eventSafetySpeedAlert is the CloudEvent type published when a runner keeps
+	// exceeding the safety speed limit.
+	eventSafetySpeedAlert = "tracking.safety_speed_alert"
+
+	// frameSafetyAlert is the WebSocket frame type of safety alerts.
+	frameSafetyAlert = "safety_alert"
+
+	// safetyStateTTL is how long speed state is kept for a booking without updates.
+	safetyStateTTL = time.Hour
+)
+
+// SafetyConfig sets the safety speed limit of trips with a pet on board.
+type SafetyConfig struct {
+	// MaxSpeedKmh is the speed above which a waypoint counts as speeding; zero disables
+	// safety alerts.
+	MaxSpeedKmh float64
+	// ConsecutiveWaypoints is how many speeding waypoints in a row raise an alert, so a
+	// single noisy fix does not.
+	ConsecutiveWaypoints int
+}
+
+// TrackAlertDTO is the API representation of a safety alert.
+type TrackAlertDTO struct {
+	ID        uuid.UUID `json:"id"`
+	Kind      string    `json:"kind"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Waypoints int       `json:"waypoints"`
+	RaisedAt  time.Time `json:"raised_at"`
+}
+
+// SafetySpeedAlertEvent is published and pushed over WebSocket when a runner exceeds
+// the safety speed limit for the configured number of consecutive waypoints.
+type SafetySpeedAlertEvent struct {
+	AlertID   uuid.UUID `json:"alert_id"`
+	TrackID   uuid.UUID `json:"track_id"`
+	BookingID uuid.UUID `json:"booking_id"`
+	RunnerID  uuid.UUID `json:"runner_id"`
+	SpeedKmh  float64   `json:"speed_kmh"`
+	LimitKmh  float64   `json:"limit_kmh"`
+	Waypoints int       `json:"waypoints"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	RaisedAt  time.Time `json:"raised_at"`
+}
+
+// speedState is the speeding streak of one booking's trip.
+type speedState struct {
+	last     trackingDomain.Waypoint
+	hasLast  bool
+	streak   int
+	topSpeed float64
+	alerted  bool
+	seenAt   time.Time
+}
+
+// SafetyService raises an alert when a runner keeps driving above the safety speed
+// limit. Alerts are stored per trip, published, and pushed to the booking room. One
+// alert is raised per speeding streak; the next needs the runner to slow down first.
+type SafetyService struct {
+	repo         alertDomain.Repository
+	trackingRepo trackingDomain.TripTrackRepository
+	hub          *ws.Hub
+	producer     EventPublisher
+	inbox        *InboxService
+	config       SafetyConfig
+	logger       *zap.Logger
+
+	mu        sync.Mutex
+	states    map[uuid.UUID]*speedState // bookingID -> speeding streak
+	lastSweep time.Time
+}
+
+// NewSafetyService creates a new SafetyService.
+func NewSafetyService(
+	repo alertDomain.Repository,
+	trackingRepo trackingDomain.TripTrackRepository,
+	hub *ws.Hub,
+	producer EventPublisher,
+	config SafetyConfig,
+	logger *zap.Logger,
+) *SafetyService {
+	if config.ConsecutiveWaypoints < 1 {
+		config.ConsecutiveWaypoints = 1
+	}
+	return &SafetyService{
+		repo:         repo,
+		trackingRepo: trackingRepo,
+		hub:          hub,
+		producer:     producer,
+		config:       config,
+		logger:       logger.With(zap.String("component", "safety")),
+		states:       make(map[uuid.UUID]*speedState),
+	}
+}
+
+// UseInbox records safety alerts in the inboxes of the booking's participants.
+func (s *SafetyService) UseInbox(inbox *InboxService) {
+	s.inbox = inbox
+}
+
+// GetAlerts returns the safety alerts raised on a booking's trip, oldest first.
+func (s *SafetyService) GetAlerts(ctx context.Context, bookingID uuid.UUID) ([]TrackAlertDTO, error) {
+	track, err := s.trackingRepo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	alerts, err := s.repo.FindByTrackID(ctx, track.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find track alerts: %w", err)
+	}
+
+	result := make([]TrackAlertDTO, len(alerts))
+	for i, a := range alerts {
+		result[i] = TrackAlertDTO{
+			ID:        a.ID,
+			Kind:      string(a.Kind),
+			Latitude:  a.Latitude,
+			Longitude: a.Longitude,
+			Value:     a.Value,
+			Threshold: a.Threshold,
+			Waypoints: a.Waypoints,
+			RaisedAt:  a.RaisedAt,
+		}
+	}
+	return result, nil
+}
+
+// OnLocation extends or ends the trip's speeding streak. An alert is raised in the
+// background when the streak reaches the configured length.
+func (s *SafetyService) OnLocation(_ context.Context, track *trackingDomain.TripTrack, waypoint trackingDomain.Waypoint) {
+	if s.config.MaxSpeedKmh <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	state, ok := s.states[track.BookingID()]
+	if !ok {
+		state = &speedState{}
+		s.states[track.BookingID()] = state
+	}
+	speed := s.speedOf(state, waypoint)
+	var alert *alertDomain.TrackAlert
+	if speed > s.config.MaxSpeedKmh && !track.IsPaused() {
+		state.streak++
+		state.topSpeed = math.Max(state.topSpeed, speed)
+		if !state.alerted && state.streak >= s.config.ConsecutiveWaypoints {
+			state.alerted = true
+			alert = &alertDomain.TrackAlert{
+				ID:        uuid.New(),
+				TrackID:   track.ID(),
+				BookingID: track.BookingID(),
+				RunnerID:  track.RunnerID(),
+				Kind:      alertDomain.KindSpeeding,
+				Latitude:  waypoint.Latitude,
+				Longitude: waypoint.Longitude,
+				Value:     math.Round(state.topSpeed*10) / 10,
+				Threshold: s.config.MaxSpeedKmh,
+				Waypoints: state.streak,
+				RaisedAt:  waypoint.RecordedAt,
+			}
+		}
+	} else {
+		state.streak, state.topSpeed, state.alerted = 0, 0, false
+	}
+	state.last, state.hasLast = waypoint, true
+	state.seenAt = time.Now()
+	s.sweep(state.seenAt)
+	s.mu.Unlock()
+
+	if alert == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		s.raise(ctx, alert)
+	}()
+}
+
+// speedOf returns the speed of a waypoint in km/h: the speed the device reported, or
+// the speed implied by the distance from the previous waypoint if none was reported.
+// It must be called with s.mu held.
+func (s *SafetyService) speedOf(state *speedState, wp trackingDomain.Waypoint) float64 {
+	if wp.Speed > 0 || !state.hasLast {
+		return wp.Speed
+	}
+	elapsed := wp.RecordedAt.Sub(state.last.RecordedAt)
+	if elapsed <= 0 {
+		return 0
+	}
+	meters := trackingDomain.DistanceMeters(state.last.Latitude, state.last.Longitude, wp.Latitude, wp.Longitude)
+	return meters / elapsed.Seconds() * 3.6
+}
+
+// sweep forgets trips without updates for safetyStateTTL. It runs at most once per TTL
+// and must be called with s.mu held.
+func (s *SafetyService) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < safetyStateTTL {
+		return
+	}
+	s.lastSweep = now
+	for bookingID, state := range s.states {
+		if now.Sub(state.seenAt) >= safetyStateTTL {
+			delete(s.states, bookingID)
+		}
+	}
+}
+
+// raise stores a speeding alert, pushes it to the booking room and publishes a
+// SafetySpeedAlertEvent.
+func (s *SafetyService) raise(ctx context.Context, a *alertDomain.TrackAlert) {
+	s.logger.Warn("safety speed limit exceeded",
+		zap.String("booking_id", a.BookingID.String()),
+		zap.String("runner_id", a.RunnerID.String()),
+		zap.Float64("speed_kmh", a.Value),
+		zap.Float64("limit_kmh", a.Threshold),
+		zap.Int("waypoints", a.Waypoints),
+	)
+
+	if err := s.repo.Save(ctx, a); err != nil {
+		s.logger.Error("failed to save track alert", zap.Error(err))
+	}
+
+	evt := SafetySpeedAlertEvent{
+		AlertID:   a.ID,
+		TrackID:   a.TrackID,
+		BookingID: a.BookingID,
+		RunnerID:  a.RunnerID,
+		SpeedKmh:  a.Value,
+		LimitKmh:  a.Threshold,
+		Waypoints: a.Waypoints,
+		Latitude:  a.Latitude,
+		Longitude: a.Longitude,
+		RaisedAt:  a.RaisedAt,
+	}
+
+	s.hub.Notify(&ws.Notification{BookingID: evt.BookingID, Type: frameSafetyAlert, Data: evt})
+	if s.inbox != nil {
+		if err := s.inbox.Record(ctx, evt.BookingID, uuid.Nil, inboxDomain.KindSystem, frameSafetyAlert, a.ID, evt); err != nil {
+			s.logger.Error("failed to record safety alert in inbox", zap.Error(err))
+		}
+	}
+
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventSafetySpeedAlert, evt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish safety alert event", zap.Error(err))
+	}
+}
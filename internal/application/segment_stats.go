@@ -0,0 +1,217 @@
+package application
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	geofenceDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/geofence"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+const (
+	// stopSpeedKmh is the speed at or below which a runner is considered stationary.
+	stopSpeedKmh = 3.0
+
+	// minStopDuration is how long a runner must stay stationary for it to count as a stop.
+	minStopDuration = 2 * time.Minute
+)
+
+// Segment kinds.
+const (
+	segmentLeg  = "leg"
+	segmentStop = "stop"
+)
+
+// Segment boundaries, reported as the reason a segment ended.
+const (
+	boundaryStop            = "stop"
+	boundaryDeparted        = "departed"
+	boundaryGeofenceEntered = "geofence_entered"
+	boundaryGeofenceExited  = "geofence_exited"
+	boundaryTripEnd         = "trip_end"
+)
+
+// SegmentDTO holds the statistics of one part of a trip. Legs are stretches of movement
+// and stops are periods the runner stayed stationary.
+type SegmentDTO struct {
+	Index           int       `json:"index"`
+	Kind            string    `json:"kind"`
+	StartedAt       time.Time `json:"started_at"`
+	EndedAt         time.Time `json:"ended_at"`
+	StartLatitude   float64   `json:"start_latitude"`
+	StartLongitude  float64   `json:"start_longitude"`
+	EndLatitude     float64   `json:"end_latitude"`
+	EndLongitude    float64   `json:"end_longitude"`
+	DistanceKm      float64   `json:"distance_km"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	AvgSpeedKmh     float64   `json:"avg_speed_kmh"`
+	EndedBy         string    `json:"ended_by"`
+	GeofenceKind    string    `json:"geofence_kind,omitempty"`
+}
+
+// SegmentStatsDTO is the per-segment breakdown of a trip.
+type SegmentStatsDTO struct {
+	BookingID       uuid.UUID    `json:"booking_id"`
+	TrackID         uuid.UUID    `json:"track_id"`
+	TotalDistanceKm float64      `json:"total_distance_km"`
+	Segments        []SegmentDTO `json:"segments"`
+}
+
+// segmentCut is a waypoint index where one segment ends and the next begins.
+type segmentCut struct {
+	reason       string
+	geofenceKind string
+}
+
+// GetSegmentStats splits a booking's trip into legs and stops and returns distance,
+// duration and average speed for each. Segments are cut where the runner stops or
+// resumes moving and, if geofences are configured, where the route crosses a geofence.
+func (s *TrackingService) GetSegmentStats(ctx context.Context, bookingID uuid.UUID) (*SegmentStatsDTO, error) {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	waypoints, err := s.repo.GetWaypoints(ctx, track.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	var fences []*geofenceDomain.Geofence
+	if s.geofences != nil {
+		fences, err = s.geofences.FindByBookingID(ctx, bookingID)
+		if err != nil {
+			s.logger.Warn("failed to load geofences for segment stats", zap.Error(err))
+		}
+	}
+
+	return &SegmentStatsDTO{
+		BookingID:       track.BookingID(),
+		TrackID:         track.ID(),
+		TotalDistanceKm: calculateTotalDistance(waypoints),
+		Segments:        buildSegments(waypoints, fences),
+	}, nil
+}
+
+// buildSegments cuts waypoints at stops and geofence crossings. Adjacent segments share
+// the waypoint at their boundary.
+func buildSegments(waypoints []trackingDomain.Waypoint, fences []*geofenceDomain.Geofence) []SegmentDTO {
+	segments := make([]SegmentDTO, 0)
+	if len(waypoints) < 2 {
+		return segments
+	}
+
+	cuts := make(map[int]segmentCut)
+	addCut := func(i int, cut segmentCut) {
+		if i <= 0 || i >= len(waypoints)-1 {
+			return
+		}
+		if _, ok := cuts[i]; !ok {
+			cuts[i] = cut
+		}
+	}
+
+	stops := detectStops(waypoints)
+	for _, stop := range stops {
+		addCut(stop[0], segmentCut{reason: boundaryStop})
+		addCut(stop[1], segmentCut{reason: boundaryDeparted})
+	}
+
+	for _, g := range fences {
+		inside := g.Contains(toPoint(waypoints[0]))
+		for i := 1; i < len(waypoints); i++ {
+			now := g.Contains(toPoint(waypoints[i]))
+			if now == inside {
+				continue
+			}
+			reason := boundaryGeofenceExited
+			if now {
+				reason = boundaryGeofenceEntered
+			}
+			addCut(i, segmentCut{reason: reason, geofenceKind: string(g.Kind())})
+			inside = now
+		}
+	}
+
+	bounds := []int{0}
+	for i := range cuts {
+		bounds = append(bounds, i)
+	}
+	sort.Ints(bounds[1:])
+	bounds = append(bounds, len(waypoints)-1)
+
+	for k := 0; k+1 < len(bounds); k++ {
+		from, to := bounds[k], bounds[k+1]
+		part := waypoints[from : to+1]
+
+		kind := segmentLeg
+		for _, stop := range stops {
+			if from >= stop[0] && to <= stop[1] {
+				kind = segmentStop
+				break
+			}
+		}
+
+		cut, ok := cuts[to]
+		if !ok {
+			cut = segmentCut{reason: boundaryTripEnd}
+		}
+
+		start, end := part[0], part[len(part)-1]
+		duration := end.RecordedAt.Sub(start.RecordedAt)
+		distanceKm := calculateTotalDistance(part)
+
+		var avgSpeed float64
+		if duration > 0 {
+			avgSpeed = math.Round(distanceKm/duration.Hours()*10) / 10
+		}
+
+		segments = append(segments, SegmentDTO{
+			Index:           k,
+			Kind:            kind,
+			StartedAt:       start.RecordedAt,
+			EndedAt:         end.RecordedAt,
+			StartLatitude:   start.Latitude,
+			StartLongitude:  start.Longitude,
+			EndLatitude:     end.Latitude,
+			EndLongitude:    end.Longitude,
+			DistanceKm:      distanceKm,
+			DurationSeconds: duration.Seconds(),
+			AvgSpeedKmh:     avgSpeed,
+			EndedBy:         cut.reason,
+			GeofenceKind:    cut.geofenceKind,
+		})
+	}
+	return segments
+}
+
+// detectStops returns the [first, last] waypoint indexes of each run of stationary
+// waypoints lasting at least minStopDuration.
+func detectStops(waypoints []trackingDomain.Waypoint) [][2]int {
+	var stops [][2]int
+	for i := 0; i < len(waypoints); {
+		if waypoints[i].Speed > stopSpeedKmh {
+			i++
+			continue
+		}
+		j := i
+		for j+1 < len(waypoints) && waypoints[j+1].Speed <= stopSpeedKmh {
+			j++
+		}
+		if waypoints[j].RecordedAt.Sub(waypoints[i].RecordedAt) >= minStopDuration {
+			stops = append(stops, [2]int{i, j})
+		}
+		i = j + 1
+	}
+	return stops
+}
+
+func toPoint(w trackingDomain.Waypoint) geofenceDomain.Point {
+	return geofenceDomain.Point{Latitude: w.Latitude, Longitude: w.Longitude}
+}
@@ -2,15 +2,27 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/clock"
 	shareDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/share"
 	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// CreateShareLinkRequest holds the optional limits of a new share link.
+type CreateShareLinkRequest struct {
+	// ExpiresIn is the link lifetime in seconds; 0 means the default of 24 hours.
+	ExpiresIn int `json:"expires_in"`
+	// MaxViews is the number of times the link may be opened; 0 means unlimited.
+	MaxViews int `json:"max_views"`
+}
+
 // SharedTripDTO is the API response for a shared trip link.
 type SharedTripDTO struct {
 	ID         uuid.UUID `json:"id"`
@@ -18,6 +30,15 @@ type SharedTripDTO struct {
 	ShareToken string    `json:"share_token"`
 	ShareURL   string    `json:"share_url"`
 	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	MaxViews   int       `json:"max_views,omitempty"`
+	ViewCount  int       `json:"view_count"`
+}
+
+// RevokeShareLinksDTO reports how many share links were revoked.
+type RevokeShareLinksDTO struct {
+	BookingID uuid.UUID `json:"booking_id"`
+	Revoked   int       `json:"revoked"`
 }
 
 // SharedTrackingDTO is the public tracking data for a shared trip.
@@ -28,30 +49,42 @@ type SharedTrackingDTO struct {
 	ExpiresAt  time.Time      `json:"expires_at"`
 }
 
-// WaypointDTO is the API representation of a waypoint.
-type WaypointDTO struct {
-	Latitude   float64   `json:"latitude"`
-	Longitude  float64   `json:"longitude"`
-	Speed      float64   `json:"speed_kmh"`
-	Heading    float64   `json:"heading_degrees"`
-	RecordedAt time.Time `json:"recorded_at"`
-}
-
 // ShareService handles trip sharing use cases.
 type ShareService struct {
 	shareRepo    shareDomain.SharedTripRepository
 	trackingRepo trackingDomain.TripTrackRepository
 	logger       *zap.Logger
+	clock        clock.Clock
+	ids          clock.IDGenerator
 }
 
 // NewShareService creates a new ShareService.
 func NewShareService(shareRepo shareDomain.SharedTripRepository, trackingRepo trackingDomain.TripTrackRepository, logger *zap.Logger) *ShareService {
-	return &ShareService{shareRepo: shareRepo, trackingRepo: trackingRepo, logger: logger}
+	return &ShareService{
+		shareRepo:    shareRepo,
+		trackingRepo: trackingRepo,
+		logger:       logger,
+		clock:        clock.System,
+		ids:          clock.RandomIDs,
+	}
+}
+
+// UseClock replaces the wall clock, e.g. with a clock.Manual in tests.
+func (s *ShareService) UseClock(c clock.Clock) {
+	s.clock = c
 }
 
-// CreateShareLink creates a new share link for a booking.
-func (s *ShareService) CreateShareLink(ctx context.Context, bookingID uuid.UUID) (*SharedTripDTO, error) {
-	st, err := shareDomain.NewSharedTrip(bookingID)
+// UseIDGenerator replaces the random ID generator for new share links.
+func (s *ShareService) UseIDGenerator(g clock.IDGenerator) {
+	s.ids = g
+}
+
+// CreateShareLink creates a new share link for a booking with the requested limits.
+func (s *ShareService) CreateShareLink(ctx context.Context, bookingID uuid.UUID, req CreateShareLinkRequest) (*SharedTripDTO, error) {
+	st, err := shareDomain.NewSharedTrip(s.ids.NewID(), bookingID, time.Duration(req.ExpiresIn)*time.Second, req.MaxViews, s.clock.Now())
+	if errors.Is(err, shareDomain.ErrInvalidLimits) {
+		return nil, apperror.New(apperror.CodeValidation, "%s", err.Error())
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create share link: %w", err)
 	}
@@ -65,29 +98,65 @@ func (s *ShareService) CreateShareLink(ctx context.Context, bookingID uuid.UUID)
 		zap.String("token", st.ShareToken()),
 	)
 
+	return toSharedTripDTO(st), nil
+}
+
+// ListShareLinks returns the active share links of a booking, newest first.
+func (s *ShareService) ListShareLinks(ctx context.Context, bookingID uuid.UUID) ([]SharedTripDTO, error) {
+	trips, err := s.shareRepo.FindActiveByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+
+	result := make([]SharedTripDTO, len(trips))
+	for i, st := range trips {
+		result[i] = *toSharedTripDTO(st)
+	}
+	return result, nil
+}
+
+// RevokeShareLinks revokes a booking's share links so their tokens stop working. If
+// shareID is not nil, only that link is revoked, and it is an error if it does not exist.
+func (s *ShareService) RevokeShareLinks(ctx context.Context, bookingID uuid.UUID, shareID *uuid.UUID) (*RevokeShareLinksDTO, error) {
+	n, err := s.shareRepo.Revoke(ctx, bookingID, shareID, s.clock.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke share links: %w", err)
+	}
+	if shareID != nil && n == 0 {
+		return nil, apperror.New(apperror.CodeShareLinkNotFound, "no active share link %s for booking %s", *shareID, bookingID)
+	}
+
+	s.logger.Info("share links revoked",
+		zap.String("booking_id", bookingID.String()),
+		zap.Int("count", n),
+	)
+
+	return &RevokeShareLinksDTO{BookingID: bookingID, Revoked: n}, nil
+}
+
+func toSharedTripDTO(st *shareDomain.SharedTrip) *SharedTripDTO {
 	return &SharedTripDTO{
 		ID:         st.ID(),
 		BookingID:  st.BookingID(),
 		ShareToken: st.ShareToken(),
 		ShareURL:   fmt.Sprintf("/api/v1/tracking/shared/%s", st.ShareToken()),
 		ExpiresAt:  st.ExpiresAt(),
-	}, nil
+		CreatedAt:  st.CreatedAt(),
+		MaxViews:   st.MaxViews(),
+		ViewCount:  st.ViewCount(),
+	}
 }
 
 // GetSharedTracking returns public tracking data for a shared token (no auth needed).
 func (s *ShareService) GetSharedTracking(ctx context.Context, token string) (*SharedTrackingDTO, error) {
-	st, err := s.shareRepo.FindByToken(ctx, token)
+	st, err := s.findUsable(ctx, token)
 	if err != nil {
-		return nil, fmt.Errorf("share link not found")
-	}
-
-	if st.IsExpired() {
-		return nil, fmt.Errorf("share link has expired")
+		return nil, err
 	}
 
 	track, err := s.trackingRepo.FindByBookingID(ctx, st.BookingID())
 	if err != nil {
-		return nil, fmt.Errorf("tracking data not found")
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", st.BookingID())
 	}
 
 	waypoints, err := s.trackingRepo.GetWaypoints(ctx, track.ID())
@@ -95,17 +164,13 @@ func (s *ShareService) GetSharedTracking(ctx context.Context, token string) (*Sh
 		return nil, fmt.Errorf("failed to get waypoints: %w", err)
 	}
 
-	waypointDTOs := make([]WaypointDTO, len(waypoints))
-	for i, wp := range waypoints {
-		waypointDTOs[i] = WaypointDTO{
-			Latitude:   wp.Latitude,
-			Longitude:  wp.Longitude,
-			Speed:      wp.Speed,
-			Heading:    wp.Heading,
-			RecordedAt: wp.RecordedAt,
-		}
+	// Count the view last, so failed requests do not use up the link.
+	if err := s.recordView(ctx, st); err != nil {
+		return nil, err
 	}
 
+	waypointDTOs := toWaypointDTOs(waypoints)
+
 	return &SharedTrackingDTO{
 		BookingID:  st.BookingID(),
 		Status:     string(track.Status()),
@@ -113,3 +178,81 @@ func (s *ShareService) GetSharedTracking(ctx context.Context, token string) (*Sh
 		ExpiresAt:  st.ExpiresAt(),
 	}, nil
 }
+
+// shareLinkExpired describes why a link can no longer be opened at now.
+func shareLinkExpired(st *shareDomain.SharedTrip, now time.Time) error {
+	if st.MaxViews() > 0 && (st.ViewsExhausted() || !st.IsExpired(now)) {
+		return apperror.New(apperror.CodeShareLinkExpired, "share link reached its limit of %d views", st.MaxViews())
+	}
+	return apperror.New(apperror.CodeShareLinkExpired, "share link expired at %s", st.ExpiresAt().Format(time.RFC3339))
+}
+
+// SharedStreamDTO describes the live stream a share link may watch.
+type SharedStreamDTO struct {
+	ShareID   uuid.UUID
+	BookingID uuid.UUID
+	ExpiresAt time.Time
+}
+
+// OpenSharedStream validates a share token for a live WebSocket stream and counts the
+// connection as one view.
+func (s *ShareService) OpenSharedStream(ctx context.Context, token string) (*SharedStreamDTO, error) {
+	st, err := s.findUsable(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.trackingRepo.FindByBookingID(ctx, st.BookingID()); err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", st.BookingID())
+	}
+
+	if err := s.recordView(ctx, st); err != nil {
+		return nil, err
+	}
+
+	return &SharedStreamDTO{ShareID: st.ID(), BookingID: st.BookingID(), ExpiresAt: st.ExpiresAt()}, nil
+}
+
+// IsShareLinkRevoked reports whether the link with the given token has been revoked
+// or removed, for closing streams opened with it.
+func (s *ShareService) IsShareLinkRevoked(ctx context.Context, token string) (bool, error) {
+	st, err := s.shareRepo.FindByToken(ctx, token)
+	if errors.Is(err, domain.ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to find share link: %w", err)
+	}
+	return st.IsRevoked(), nil
+}
+
+// findUsable returns the share link for a token if it is neither revoked, expired nor
+// out of views.
+func (s *ShareService) findUsable(ctx context.Context, token string) (*shareDomain.SharedTrip, error) {
+	st, err := s.shareRepo.FindByToken(ctx, token)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeShareLinkNotFound, "share link not found")
+	}
+
+	if st.IsRevoked() {
+		return nil, apperror.New(apperror.CodeShareLinkRevoked, "share link was revoked at %s", st.RevokedAt().Format(time.RFC3339))
+	}
+
+	if now := s.clock.Now(); st.IsExpired(now) || st.ViewsExhausted() {
+		return nil, shareLinkExpired(st, now)
+	}
+	return st, nil
+}
+
+// recordView counts one view of a link, failing if the link ran out of views or was
+// revoked or expired in the meantime.
+func (s *ShareService) recordView(ctx context.Context, st *shareDomain.SharedTrip) error {
+	allowed, err := s.shareRepo.RecordView(ctx, st.ID())
+	if err != nil {
+		return fmt.Errorf("failed to record share link view: %w", err)
+	}
+	if !allowed {
+		return shareLinkExpired(st, s.clock.Now())
+	}
+	return nil
+}
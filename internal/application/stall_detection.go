@@ -0,0 +1,240 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+// CloudEvent types published when a trip stalls and when its runner moves again.
+const (
+	eventTrackingStalled = "tracking.stalled"
+	eventStallCleared    = "tracking.stall_cleared"
+)
+
+// WebSocket frame types of stalled trips.
+const (
+	frameTrackingStalled = "tracking_stalled"
+	frameStallCleared    = "stall_cleared"
+)
+
+// StallConfig sets up the detection of active trips whose runner has stopped moving.
+type StallConfig struct {
+	// After is how long a runner may stay within RadiusMeters of one place before the
+	// trip is reported stalled.
+	After        time.Duration
+	RadiusMeters float64
+	// CheckInterval is how often active trips are checked.
+	CheckInterval time.Duration
+}
+
+// TrackingStalledEvent is published and pushed over WebSocket when an active trip's
+// runner has not moved for the stall duration. Latitude and Longitude are where the
+// runner last moved to.
+type TrackingStalledEvent struct {
+	TrackID        uuid.UUID `json:"track_id"`
+	BookingID      uuid.UUID `json:"booking_id"`
+	RunnerID       uuid.UUID `json:"runner_id"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	LastMovedAt    time.Time `json:"last_moved_at"`
+	StalledSeconds float64   `json:"stalled_seconds"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// StallClearedEvent is published and pushed over WebSocket when the runner of a
+// stalled trip moves again.
+type StallClearedEvent struct {
+	TrackID    uuid.UUID `json:"track_id"`
+	BookingID  uuid.UUID `json:"booking_id"`
+	RunnerID   uuid.UUID `json:"runner_id"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	StalledAt  time.Time `json:"stalled_at"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// StalledTrackDTO is a stalled trip in the dispatcher listing.
+type StalledTrackDTO struct {
+	TrackID        uuid.UUID `json:"track_id"`
+	BookingID      uuid.UUID `json:"booking_id"`
+	RunnerID       uuid.UUID `json:"runner_id"`
+	Region         string    `json:"region,omitempty"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	LastMovedAt    time.Time `json:"last_moved_at"`
+	StalledAt      time.Time `json:"stalled_at"`
+	StalledSeconds float64   `json:"stalled_seconds"`
+}
+
+// UseStallDetection reports active trips whose runner stays in one place for too long.
+// Must be called before locations are consumed; RunStallDetection runs the checks.
+func (s *TrackingService) UseStallDetection(config StallConfig) {
+	s.stall = config
+}
+
+// RunStallDetection checks active trips for stalls every CheckInterval until ctx is
+// cancelled. Every instance may run it; each stall is reported by one of them.
+func (s *TrackingService) RunStallDetection(ctx context.Context) {
+	if s.stall.After <= 0 || s.stall.CheckInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.stall.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.detectStalls(ctx)
+		}
+	}
+}
+
+// detectStalls marks and reports the active trips whose runner has not moved for the
+// stall duration. Trips are only checked once they have a location, and runners
+// waiting near the destination for the handover are not stalled.
+func (s *TrackingService) detectStalls(ctx context.Context) {
+	now := s.clock.Now().UTC()
+	movedBefore := now.Add(-s.stall.After)
+	tracks, err := s.repo.FindStallCandidates(ctx, movedBefore)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("failed to find stalled trips", zap.Error(err))
+		}
+		return
+	}
+
+	for _, track := range tracks {
+		if last, dest := track.LastMovement(), track.Destination(); last != nil && dest != nil &&
+			trackingDomain.DistanceMeters(last.Latitude, last.Longitude, dest.Latitude, dest.Longitude) <= arrivalRadiusMeters {
+			continue
+		}
+		marked, err := s.repo.MarkStalled(ctx, track.ID(), now, movedBefore)
+		if err != nil {
+			s.logger.Error("failed to mark trip stalled", zap.String("booking_id", track.BookingID().String()), zap.Error(err))
+			continue
+		}
+		if marked {
+			s.publishStalled(ctx, track, now)
+		}
+	}
+}
+
+// trackMovement records a live location as the runner's latest movement when it lies
+// outside the stall radius around the previous one, clearing a reported stall.
+func (s *TrackingService) trackMovement(ctx context.Context, track *trackingDomain.TripTrack, wp trackingDomain.Waypoint) {
+	if s.stall.After <= 0 {
+		return
+	}
+	last := track.LastMovement()
+	if last != nil && (wp.RecordedAt.Before(last.At) ||
+		trackingDomain.DistanceMeters(last.Latitude, last.Longitude, wp.Latitude, wp.Longitude) < s.stall.RadiusMeters) {
+		return
+	}
+
+	if err := s.repo.RecordMovement(ctx, track.ID(), trackingDomain.Movement{Latitude: wp.Latitude, Longitude: wp.Longitude, At: wp.RecordedAt}); err != nil {
+		s.logger.Error("failed to record movement", zap.String("booking_id", track.BookingID().String()), zap.Error(err))
+		return
+	}
+	if stalledAt := track.StalledAt(); stalledAt != nil {
+		s.publishStallCleared(ctx, track, wp, *stalledAt)
+	}
+}
+
+// restartStallClock restarts a trip's stall clock from now, e.g. when it resumes after
+// a pause, without moving the runner's last position.
+func (s *TrackingService) restartStallClock(ctx context.Context, track *trackingDomain.TripTrack, now time.Time) {
+	last := track.LastMovement()
+	if s.stall.After <= 0 || last == nil {
+		return
+	}
+	if err := s.repo.RecordMovement(ctx, track.ID(), trackingDomain.Movement{Latitude: last.Latitude, Longitude: last.Longitude, At: now.UTC()}); err != nil {
+		s.logger.Error("failed to restart stall clock", zap.String("booking_id", track.BookingID().String()), zap.Error(err))
+	}
+}
+
+// ListStalledTracks returns the active trips currently marked stalled, longest without
+// movement first, for dispatchers to follow up.
+func (s *TrackingService) ListStalledTracks(ctx context.Context) ([]StalledTrackDTO, error) {
+	tracks, err := s.repo.FindStalled(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	result := make([]StalledTrackDTO, 0, len(tracks))
+	for _, track := range tracks {
+		last, stalledAt := track.LastMovement(), track.StalledAt()
+		if last == nil || stalledAt == nil {
+			continue
+		}
+		result = append(result, StalledTrackDTO{
+			TrackID:        track.ID(),
+			BookingID:      track.BookingID(),
+			RunnerID:       track.RunnerID(),
+			Region:         track.Region(),
+			Latitude:       last.Latitude,
+			Longitude:      last.Longitude,
+			LastMovedAt:    last.At,
+			StalledAt:      *stalledAt,
+			StalledSeconds: now.Sub(last.At).Seconds(),
+		})
+	}
+	return result, nil
+}
+
+// publishStalled notifies the booking's room and publishes a TrackingStalledEvent.
+func (s *TrackingService) publishStalled(ctx context.Context, track *trackingDomain.TripTrack, now time.Time) {
+	last := track.LastMovement()
+	evt := TrackingStalledEvent{
+		TrackID:        track.ID(),
+		BookingID:      track.BookingID(),
+		RunnerID:       track.RunnerID(),
+		Latitude:       last.Latitude,
+		Longitude:      last.Longitude,
+		LastMovedAt:    last.At,
+		StalledSeconds: now.Sub(last.At).Seconds(),
+		OccurredAt:     now,
+	}
+	s.logger.Warn("trip stalled",
+		zap.String("booking_id", evt.BookingID.String()),
+		zap.String("runner_id", evt.RunnerID.String()),
+		zap.Time("last_moved_at", evt.LastMovedAt),
+	)
+	s.hub.Notify(&ws.Notification{BookingID: evt.BookingID, Type: frameTrackingStalled, Data: evt})
+	s.publishStallEvent(ctx, eventTrackingStalled, evt)
+}
+
+// publishStallCleared notifies the booking's room and publishes a StallClearedEvent.
+func (s *TrackingService) publishStallCleared(ctx context.Context, track *trackingDomain.TripTrack, wp trackingDomain.Waypoint, stalledAt time.Time) {
+	evt := StallClearedEvent{
+		TrackID:    track.ID(),
+		BookingID:  track.BookingID(),
+		RunnerID:   track.RunnerID(),
+		Latitude:   wp.Latitude,
+		Longitude:  wp.Longitude,
+		StalledAt:  stalledAt,
+		OccurredAt: s.clock.Now().UTC(),
+	}
+	s.logger.Info("stalled trip moving again", zap.String("booking_id", evt.BookingID.String()))
+	s.hub.Notify(&ws.Notification{BookingID: evt.BookingID, Type: frameStallCleared, Data: evt})
+	s.publishStallEvent(ctx, eventStallCleared, evt)
+}
+
+// publishStallEvent publishes a stall CloudEvent to the tracking events topic.
+func (s *TrackingService) publishStallEvent(ctx context.Context, eventType string, evt interface{}) {
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventType, evt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish stall event", zap.String("type", eventType), zap.Error(err))
+	}
+}
@@ -0,0 +1,282 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/clock"
+	supportDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/support"
+)
+
+// RoleSupport is the role of customer support agents, who may open support sessions.
+const RoleSupport auth.UserRole = "support"
+
+// defaultSupportSessionTTL is how long a support session stays open when not configured.
+const defaultSupportSessionTTL = time.Hour
+
+// maxSupportReasonLength caps the reason given for opening a support session.
+const maxSupportReasonLength = 500
+
+// OpenSupportSessionRequest opens a support session for a booking.
+type OpenSupportSessionRequest struct {
+	BookingID uuid.UUID `json:"booking_id" binding:"required"`
+	// Reason is why the session is opened, e.g. a ticket reference.
+	Reason string `json:"reason" binding:"required"`
+}
+
+// SupportSessionDTO is the API representation of a support session.
+type SupportSessionDTO struct {
+	ID        uuid.UUID  `json:"id"`
+	AgentID   uuid.UUID  `json:"agent_id"`
+	BookingID uuid.UUID  `json:"booking_id"`
+	Reason    string     `json:"reason"`
+	OpenedAt  time.Time  `json:"opened_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+}
+
+// SupportAuditEventDTO is the API representation of a support audit event.
+type SupportAuditEventDTO struct {
+	ID         uuid.UUID `json:"id"`
+	AgentID    uuid.UUID `json:"agent_id"`
+	BookingID  uuid.UUID `json:"booking_id"`
+	Action     string    `json:"action"`
+	Detail     string    `json:"detail,omitempty"`
+	SourceIP   string    `json:"source_ip,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// SupportService lets support agents view a booking's tracking and chat as its
+// participants see them, without being able to send anything. Sessions are opened per
+// booking with a reason, expire after a fixed time, and every view is audited. A view is
+// refused if its audit event cannot be stored.
+type SupportService struct {
+	repo     supportDomain.Repository
+	tracking *TrackingService
+	chat     *ChatService
+	ttl      time.Duration
+	logger   *zap.Logger
+	clock    clock.Clock
+}
+
+// NewSupportService creates a new SupportService. Sessions stay open for ttl.
+func NewSupportService(
+	repo supportDomain.Repository,
+	tracking *TrackingService,
+	chat *ChatService,
+	ttl time.Duration,
+	logger *zap.Logger,
+) *SupportService {
+	if ttl <= 0 {
+		ttl = defaultSupportSessionTTL
+	}
+	return &SupportService{
+		repo:     repo,
+		tracking: tracking,
+		chat:     chat,
+		ttl:      ttl,
+		logger:   logger,
+		clock:    clock.System,
+	}
+}
+
+// UseClock replaces the wall clock, e.g. with a clock.Manual in tests.
+func (s *SupportService) UseClock(c clock.Clock) {
+	s.clock = c
+}
+
+// OpenSession opens a read-only session on a booking for a support agent or admin.
+func (s *SupportService) OpenSession(ctx context.Context, agentID uuid.UUID, role auth.UserRole, sourceIP string, req OpenSupportSessionRequest) (*SupportSessionDTO, error) {
+	if role != RoleSupport && role != auth.RoleAdmin {
+		return nil, apperror.New(apperror.CodeForbidden, "support sessions require the %s role", RoleSupport)
+	}
+	if len(req.Reason) > maxSupportReasonLength {
+		return nil, apperror.New(apperror.CodeValidation, "reason must be at most %d characters", maxSupportReasonLength)
+	}
+	if _, err := s.tracking.repo.FindByBookingID(ctx, req.BookingID); err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", req.BookingID)
+	}
+
+	now := s.clock.Now().UTC()
+	session := &supportDomain.Session{
+		ID:        uuid.New(),
+		AgentID:   agentID,
+		BookingID: req.BookingID,
+		Reason:    req.Reason,
+		OpenedAt:  now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.repo.SaveSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to save support session: %w", err)
+	}
+	if err := s.audit(ctx, session, supportDomain.ActionSessionOpened, req.Reason, sourceIP); err != nil {
+		return nil, err
+	}
+	return toSupportSessionDTO(session), nil
+}
+
+// CloseSession closes an agent's session before it expires.
+func (s *SupportService) CloseSession(ctx context.Context, sessionID, agentID uuid.UUID, sourceIP string) error {
+	session, err := s.openSession(ctx, sessionID, agentID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CloseSession(ctx, session.ID, s.clock.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to close support session: %w", err)
+	}
+	return s.audit(ctx, session, supportDomain.ActionSessionClosed, "", sourceIP)
+}
+
+// GetTracking returns the tracking data of a session's booking.
+func (s *SupportService) GetTracking(ctx context.Context, sessionID, agentID uuid.UUID, sourceIP string) (*TrackingDTO, error) {
+	session, err := s.openSession(ctx, sessionID, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.audit(ctx, session, supportDomain.ActionViewTracking, "", sourceIP); err != nil {
+		return nil, err
+	}
+	return s.tracking.GetTracking(ctx, session.BookingID)
+}
+
+// GetMessages returns a page of the chat history of a session's booking.
+func (s *SupportService) GetMessages(ctx context.Context, sessionID, agentID uuid.UUID, sourceIP string, page, limit int) ([]*ChatMessageDTO, int64, error) {
+	session, err := s.openSession(ctx, sessionID, agentID)
+	if err != nil {
+		return nil, 0, err
+	}
+	detail := fmt.Sprintf("page=%d limit=%d", page, limit)
+	if err := s.audit(ctx, session, supportDomain.ActionViewChat, detail, sourceIP); err != nil {
+		return nil, 0, err
+	}
+	return s.chat.GetMessages(ctx, session.BookingID, page, limit)
+}
+
+// AuthorizeStream checks that an agent may mirror a session's booking room over
+// WebSocket and audits the connection. It returns the booking and when the session
+// expires.
+func (s *SupportService) AuthorizeStream(ctx context.Context, sessionID, agentID uuid.UUID, sourceIP string) (uuid.UUID, time.Time, error) {
+	session, err := s.openSession(ctx, sessionID, agentID)
+	if err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+	if err := s.audit(ctx, session, supportDomain.ActionStreamOpened, "", sourceIP); err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+	return session.BookingID, session.ExpiresAt, nil
+}
+
+// IsSessionOpen reports whether a session is still open, so mirrored streams can be
+// closed along with their session.
+func (s *SupportService) IsSessionOpen(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	session, err := s.repo.FindSession(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	return session.IsOpen(s.clock.Now()), nil
+}
+
+// GetAudit returns a session's audit trail, oldest first.
+func (s *SupportService) GetAudit(ctx context.Context, sessionID uuid.UUID) ([]SupportAuditEventDTO, error) {
+	if _, err := s.findSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+	events, err := s.repo.ListAudit(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list support audit events: %w", err)
+	}
+
+	result := make([]SupportAuditEventDTO, len(events))
+	for i, e := range events {
+		result[i] = SupportAuditEventDTO{
+			ID:         e.ID,
+			AgentID:    e.AgentID,
+			BookingID:  e.BookingID,
+			Action:     e.Action,
+			Detail:     e.Detail,
+			SourceIP:   e.SourceIP,
+			OccurredAt: e.OccurredAt,
+		}
+	}
+	return result, nil
+}
+
+// openSession returns a session if it belongs to agentID and is still open.
+func (s *SupportService) openSession(ctx context.Context, sessionID, agentID uuid.UUID) (*supportDomain.Session, error) {
+	session, err := s.findSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.AgentID != agentID {
+		return nil, apperror.New(apperror.CodeForbidden, "support session %s belongs to another agent", sessionID)
+	}
+	if !session.IsOpen(s.clock.Now()) {
+		return nil, apperror.New(apperror.CodeSupportSessionClosed, "support session %s is closed", sessionID)
+	}
+	return session, nil
+}
+
+// findSession loads a session, mapping a missing one to support_session_not_found.
+func (s *SupportService) findSession(ctx context.Context, sessionID uuid.UUID) (*supportDomain.Session, error) {
+	session, err := s.repo.FindSession(ctx, sessionID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, apperror.New(apperror.CodeSupportSessionNotFound, "no support session %s", sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find support session: %w", err)
+	}
+	return session, nil
+}
+
+// audit stores and logs an action taken in a session.
+func (s *SupportService) audit(ctx context.Context, session *supportDomain.Session, action, detail, sourceIP string) error {
+	event := &supportDomain.AuditEvent{
+		ID:         uuid.New(),
+		SessionID:  session.ID,
+		AgentID:    session.AgentID,
+		BookingID:  session.BookingID,
+		Action:     action,
+		Detail:     detail,
+		SourceIP:   sourceIP,
+		OccurredAt: s.clock.Now().UTC(),
+	}
+	if err := s.repo.AppendAudit(ctx, event); err != nil {
+		s.logger.Error("failed to store support audit event",
+			zap.String("session_id", session.ID.String()),
+			zap.String("action", action),
+			zap.Error(err),
+		)
+		return apperror.Wrap(apperror.CodeInternal, errors.New("support audit log unavailable"))
+	}
+
+	s.logger.Info("support session audit",
+		zap.String("session_id", session.ID.String()),
+		zap.String("agent_id", session.AgentID.String()),
+		zap.String("booking_id", session.BookingID.String()),
+		zap.String("action", action),
+		zap.String("detail", detail),
+		zap.String("source_ip", sourceIP),
+	)
+	return nil
+}
+
+// toSupportSessionDTO converts a session to its API representation.
+func toSupportSessionDTO(s *supportDomain.Session) *SupportSessionDTO {
+	return &SupportSessionDTO{
+		ID:        s.ID,
+		AgentID:   s.AgentID,
+		BookingID: s.BookingID,
+		Reason:    s.Reason,
+		OpenedAt:  s.OpenedAt,
+		ExpiresAt: s.ExpiresAt,
+		ClosedAt:  s.ClosedAt,
+	}
+}
@@ -0,0 +1,477 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	inboxDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/inbox"
+	participantDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/participant"
+	telemetryDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/telemetry"
+	temperatureDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/temperature"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+// CloudEvent types published when a carrier's temperature or humidity leaves or returns
+// to its safe range.
+const (
+	eventTemperatureAlert     = "tracking.temperature_alert"
+	eventTemperatureRecovered = "tracking.temperature_recovered"
+	eventHumidityAlert        = "tracking.humidity_alert"
+	eventHumidityRecovered    = "tracking.humidity_recovered"
+)
+
+// frameTelemetryUpdate is the WebSocket frame type carrying each live carrier reading.
+const frameTelemetryUpdate = "telemetry_update"
+
+// SetTemperatureThresholdRequest holds a species' safe carrier temperature range.
+type SetTemperatureThresholdRequest struct {
+	MinCelsius *float64 `json:"min_celsius" binding:"required"`
+	MaxCelsius *float64 `json:"max_celsius" binding:"required"`
+}
+
+// TemperatureThresholdDTO is the API representation of a temperature threshold.
+type TemperatureThresholdDTO struct {
+	Species    string    `json:"species"`
+	MinCelsius float64   `json:"min_celsius"`
+	MaxCelsius float64   `json:"max_celsius"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// IngestCarrierTelemetryRequest is a carrier reading submitted by a runner. At least one
+// of the values is required.
+type IngestCarrierTelemetryRequest struct {
+	TemperatureC *float64  `json:"temperature_c"`
+	HumidityPct  *float64  `json:"humidity_pct"`
+	RecordedAt   time.Time `json:"recorded_at"`
+}
+
+// CrateTelemetry is the carrier reading a runner may attach to a submitted location.
+type CrateTelemetry struct {
+	TemperatureC *float64 `json:"temperature_c"`
+	HumidityPct  *float64 `json:"humidity_pct"`
+}
+
+// CarrierTelemetryDTO is the evaluated carrier reading. Condition is the temperature
+// condition and HumidityCondition the humidity one; each is only set for a value that
+// was read.
+type CarrierTelemetryDTO struct {
+	BookingID         uuid.UUID `json:"booking_id"`
+	Species           string    `json:"species"`
+	TemperatureC      *float64  `json:"temperature_c,omitempty"`
+	MinCelsius        *float64  `json:"min_celsius,omitempty"`
+	MaxCelsius        *float64  `json:"max_celsius,omitempty"`
+	Condition         string    `json:"condition,omitempty"`
+	HumidityPct       *float64  `json:"humidity_pct,omitempty"`
+	MinHumidityPct    *float64  `json:"min_humidity_pct,omitempty"`
+	MaxHumidityPct    *float64  `json:"max_humidity_pct,omitempty"`
+	HumidityCondition string    `json:"humidity_condition,omitempty"`
+	RecordedAt        time.Time `json:"recorded_at"`
+}
+
+// TelemetryReadingDTO is a stored carrier reading.
+type TelemetryReadingDTO struct {
+	TemperatureC *float64  `json:"temperature_c,omitempty"`
+	HumidityPct  *float64  `json:"humidity_pct,omitempty"`
+	RecordedAt   time.Time `json:"recorded_at"`
+}
+
+// TemperatureAlertEvent is published and pushed over WebSocket when a carrier's
+// temperature condition changes.
+type TemperatureAlertEvent struct {
+	TrackID      uuid.UUID `json:"track_id"`
+	BookingID    uuid.UUID `json:"booking_id"`
+	RunnerID     uuid.UUID `json:"runner_id"`
+	Species      string    `json:"species"`
+	TemperatureC float64   `json:"temperature_c"`
+	MinCelsius   float64   `json:"min_celsius"`
+	MaxCelsius   float64   `json:"max_celsius"`
+	Condition    string    `json:"condition"`
+	RecordedAt   time.Time `json:"recorded_at"`
+}
+
+// HumidityAlertEvent is published and pushed over WebSocket when a carrier's humidity
+// condition changes.
+type HumidityAlertEvent struct {
+	TrackID     uuid.UUID `json:"track_id"`
+	BookingID   uuid.UUID `json:"booking_id"`
+	RunnerID    uuid.UUID `json:"runner_id"`
+	HumidityPct float64   `json:"humidity_pct"`
+	MinPct      float64   `json:"min_pct"`
+	MaxPct      float64   `json:"max_pct"`
+	Condition   string    `json:"condition"`
+	RecordedAt  time.Time `json:"recorded_at"`
+}
+
+// TemperatureService manages per-species temperature thresholds, stores carrier
+// telemetry and evaluates it against the thresholds and the safe humidity range.
+type TemperatureService struct {
+	repo         temperatureDomain.Repository
+	telemetry    telemetryDomain.Repository
+	trackingRepo trackingDomain.TripTrackRepository
+	participants participantDomain.Repository
+	hub          *ws.Hub
+	producer     EventPublisher
+	inbox        *InboxService
+	humidity     telemetryDomain.HumidityRange
+	logger       *zap.Logger
+
+	mu                 sync.Mutex
+	conditions         map[uuid.UUID]temperatureDomain.Condition // bookingID -> last condition
+	humidityConditions map[uuid.UUID]telemetryDomain.Condition   // bookingID -> last humidity condition
+}
+
+// NewTemperatureService creates a new TemperatureService. The pet species of a booking
+// is read from participants.
+func NewTemperatureService(
+	repo temperatureDomain.Repository,
+	telemetry telemetryDomain.Repository,
+	trackingRepo trackingDomain.TripTrackRepository,
+	participants participantDomain.Repository,
+	hub *ws.Hub,
+	producer EventPublisher,
+	humidity telemetryDomain.HumidityRange,
+	logger *zap.Logger,
+) *TemperatureService {
+	return &TemperatureService{
+		repo:               repo,
+		telemetry:          telemetry,
+		trackingRepo:       trackingRepo,
+		participants:       participants,
+		hub:                hub,
+		producer:           producer,
+		humidity:           humidity,
+		logger:             logger,
+		conditions:         make(map[uuid.UUID]temperatureDomain.Condition),
+		humidityConditions: make(map[uuid.UUID]telemetryDomain.Condition),
+	}
+}
+
+// UseInbox records temperature alerts in the inboxes of the booking's participants.
+func (s *TemperatureService) UseInbox(inbox *InboxService) {
+	s.inbox = inbox
+}
+
+// ListThresholds returns all configured thresholds.
+func (s *TemperatureService) ListThresholds(ctx context.Context) ([]TemperatureThresholdDTO, error) {
+	thresholds, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list temperature thresholds: %w", err)
+	}
+
+	result := make([]TemperatureThresholdDTO, len(thresholds))
+	for i, t := range thresholds {
+		result[i] = toThresholdDTO(t)
+	}
+	return result, nil
+}
+
+// SetThreshold creates or replaces the threshold of a species.
+func (s *TemperatureService) SetThreshold(ctx context.Context, species string, req SetTemperatureThresholdRequest) (*TemperatureThresholdDTO, error) {
+	t, err := temperatureDomain.NewThreshold(species, *req.MinCelsius, *req.MaxCelsius)
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeValidation, err)
+	}
+	if err := s.repo.Upsert(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to save temperature threshold: %w", err)
+	}
+
+	s.logger.Info("temperature threshold set",
+		zap.String("species", t.Species),
+		zap.Float64("min_celsius", t.MinCelsius),
+		zap.Float64("max_celsius", t.MaxCelsius),
+	)
+
+	dto := toThresholdDTO(t)
+	return &dto, nil
+}
+
+// DeleteThreshold removes the threshold of a species, which then falls back to the
+// default threshold.
+func (s *TemperatureService) DeleteThreshold(ctx context.Context, species string) error {
+	species = temperatureDomain.NormalizeSpecies(species)
+	if err := s.repo.Delete(ctx, species); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return apperror.New(apperror.CodeNotFound, "no temperature threshold for species %q", species)
+		}
+		return fmt.Errorf("failed to delete temperature threshold: %w", err)
+	}
+	return nil
+}
+
+// IngestCarrierTelemetry records a carrier reading submitted by runnerID on its own,
+// for crates that report separately from the runner's location. It is handled as a
+// reading attached to a location would be; see RecordLiveTelemetry.
+func (s *TemperatureService) IngestCarrierTelemetry(
+	ctx context.Context,
+	bookingID, runnerID uuid.UUID,
+	req IngestCarrierTelemetryRequest,
+) (*CarrierTelemetryDTO, error) {
+	track, err := s.trackingRepo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+	if track.RunnerID() != runnerID {
+		return nil, apperror.New(apperror.CodeNotParticipant, "runner is not assigned to booking %s", bookingID)
+	}
+	if !track.IsActive() {
+		s.forget(bookingID)
+		return nil, apperror.New(apperror.CodeTrackingNotActive, "tracking for booking %s is %s", bookingID, track.Status())
+	}
+
+	recordedAt := req.RecordedAt
+	if recordedAt.IsZero() {
+		recordedAt = time.Now().UTC()
+	}
+	reading, err := telemetryDomain.NewReading(track.ID(), bookingID, req.TemperatureC, req.HumidityPct, recordedAt)
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeValidation, err)
+	}
+	return s.RecordLiveTelemetry(ctx, track, reading)
+}
+
+// GetTelemetry returns the carrier readings stored for a booking's trip, oldest first.
+func (s *TemperatureService) GetTelemetry(ctx context.Context, bookingID uuid.UUID) ([]TelemetryReadingDTO, error) {
+	track, err := s.trackingRepo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	readings, err := s.telemetry.FindByTrackID(ctx, track.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find telemetry: %w", err)
+	}
+
+	result := make([]TelemetryReadingDTO, len(readings))
+	for i, r := range readings {
+		result[i] = TelemetryReadingDTO{
+			TemperatureC: r.TemperatureC,
+			HumidityPct:  r.HumidityPct,
+			RecordedAt:   r.RecordedAt,
+		}
+	}
+	return result, nil
+}
+
+// RecordLiveTelemetry stores a live reading of an active track, pushes it to the
+// booking room as a telemetry_update frame and evaluates it. The temperature is checked
+// against the booking's species threshold and the humidity against the safe range; an
+// alert is published and pushed when a value leaves its range, and again when it returns.
+func (s *TemperatureService) RecordLiveTelemetry(
+	ctx context.Context,
+	track *trackingDomain.TripTrack,
+	reading *telemetryDomain.Reading,
+) (*CarrierTelemetryDTO, error) {
+	if err := s.telemetry.Save(ctx, []*telemetryDomain.Reading{reading}); err != nil {
+		return nil, fmt.Errorf("failed to save telemetry: %w", err)
+	}
+
+	result := &CarrierTelemetryDTO{
+		BookingID:    track.BookingID(),
+		Species:      s.speciesOf(ctx, track.BookingID()),
+		TemperatureC: reading.TemperatureC,
+		HumidityPct:  reading.HumidityPct,
+		RecordedAt:   reading.RecordedAt,
+	}
+
+	if reading.TemperatureC != nil {
+		result.Condition = string(temperatureDomain.ConditionUnknown)
+		threshold, err := s.thresholdFor(ctx, result.Species)
+		if err != nil {
+			return nil, err
+		}
+		if threshold != nil {
+			condition := threshold.Evaluate(*reading.TemperatureC)
+			result.MinCelsius = &threshold.MinCelsius
+			result.MaxCelsius = &threshold.MaxCelsius
+			result.Condition = string(condition)
+
+			if transition(&s.mu, s.conditions, track.BookingID(), condition, temperatureDomain.ConditionNormal) {
+				s.publishCondition(ctx, track, threshold, result)
+			}
+		}
+	}
+
+	if reading.HumidityPct != nil {
+		condition := s.humidity.Evaluate(*reading.HumidityPct)
+		result.MinHumidityPct = &s.humidity.MinPct
+		result.MaxHumidityPct = &s.humidity.MaxPct
+		result.HumidityCondition = string(condition)
+
+		if transition(&s.mu, s.humidityConditions, track.BookingID(), condition, telemetryDomain.ConditionNormal) {
+			s.publishHumidityCondition(ctx, track, result)
+		}
+	}
+
+	s.hub.Notify(&ws.Notification{BookingID: track.BookingID(), Type: frameTelemetryUpdate, Data: result})
+	return result, nil
+}
+
+// StoreBackfilledTelemetry stores readings uploaded along with backfilled waypoints.
+// They describe the past, so they are neither broadcast nor evaluated.
+func (s *TemperatureService) StoreBackfilledTelemetry(ctx context.Context, readings []*telemetryDomain.Reading) error {
+	if err := s.telemetry.Save(ctx, readings); err != nil {
+		return fmt.Errorf("failed to save telemetry: %w", err)
+	}
+	return nil
+}
+
+// speciesOf returns the pet species of a booking, or DefaultSpecies if unknown.
+func (s *TemperatureService) speciesOf(ctx context.Context, bookingID uuid.UUID) string {
+	if s.participants != nil {
+		p, err := s.participants.FindByBookingID(ctx, bookingID)
+		if err == nil && p.PetSpecies != "" {
+			return p.PetSpecies
+		}
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			s.logger.Warn("failed to read booking pet species", zap.String("booking_id", bookingID.String()), zap.Error(err))
+		}
+	}
+	return temperatureDomain.DefaultSpecies
+}
+
+// thresholdFor returns the threshold of a species, falling back to the default
+// threshold. It returns nil if neither is configured.
+func (s *TemperatureService) thresholdFor(ctx context.Context, species string) (*temperatureDomain.Threshold, error) {
+	for _, candidate := range []string{species, temperatureDomain.DefaultSpecies} {
+		t, err := s.repo.FindBySpecies(ctx, candidate)
+		if err == nil {
+			return t, nil
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("failed to find temperature threshold: %w", err)
+		}
+	}
+	return nil, nil
+}
+
+// transition records a booking's condition in conditions and reports whether it changed
+// in a way worth notifying: into a breach, between breaches, or back to normal after one.
+func transition[C comparable](mu *sync.Mutex, conditions map[uuid.UUID]C, bookingID uuid.UUID, condition, normal C) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Only breaches are remembered; a missing entry means normal.
+	previous, ok := conditions[bookingID]
+	if !ok {
+		previous = normal
+	}
+	if condition == normal {
+		delete(conditions, bookingID)
+	} else {
+		conditions[bookingID] = condition
+	}
+	return condition != previous
+}
+
+// forget drops the remembered conditions of a booking that is no longer active.
+func (s *TemperatureService) forget(bookingID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.conditions, bookingID)
+	delete(s.humidityConditions, bookingID)
+	s.mu.Unlock()
+}
+
+// publishCondition publishes and broadcasts a temperature alert or recovery.
+func (s *TemperatureService) publishCondition(
+	ctx context.Context,
+	track *trackingDomain.TripTrack,
+	threshold *temperatureDomain.Threshold,
+	reading *CarrierTelemetryDTO,
+) {
+	eventType, frameType := eventTemperatureAlert, "temperature_alert"
+	if reading.Condition == string(temperatureDomain.ConditionNormal) {
+		eventType, frameType = eventTemperatureRecovered, "temperature_recovered"
+	}
+
+	evt := TemperatureAlertEvent{
+		TrackID:      track.ID(),
+		BookingID:    track.BookingID(),
+		RunnerID:     track.RunnerID(),
+		Species:      reading.Species,
+		TemperatureC: *reading.TemperatureC,
+		MinCelsius:   threshold.MinCelsius,
+		MaxCelsius:   threshold.MaxCelsius,
+		Condition:    reading.Condition,
+		RecordedAt:   reading.RecordedAt,
+	}
+
+	s.logger.Info("carrier temperature condition changed",
+		zap.String("booking_id", evt.BookingID.String()),
+		zap.String("species", evt.Species),
+		zap.Float64("temperature_c", evt.TemperatureC),
+		zap.String("condition", evt.Condition),
+	)
+
+	s.hub.Notify(&ws.Notification{BookingID: evt.BookingID, Type: frameType, Data: evt})
+	if s.inbox != nil {
+		if err := s.inbox.Record(ctx, evt.BookingID, uuid.Nil, inboxDomain.KindSystem, frameType, uuid.New(), evt); err != nil {
+			s.logger.Error("failed to record temperature alert in inbox", zap.Error(err))
+		}
+	}
+
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventType, evt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish temperature event", zap.Error(err))
+	}
+}
+
+// publishHumidityCondition publishes and broadcasts a humidity alert or recovery.
+func (s *TemperatureService) publishHumidityCondition(ctx context.Context, track *trackingDomain.TripTrack, reading *CarrierTelemetryDTO) {
+	eventType, frameType := eventHumidityAlert, "humidity_alert"
+	if reading.HumidityCondition == string(telemetryDomain.ConditionNormal) {
+		eventType, frameType = eventHumidityRecovered, "humidity_recovered"
+	}
+
+	evt := HumidityAlertEvent{
+		TrackID:     track.ID(),
+		BookingID:   track.BookingID(),
+		RunnerID:    track.RunnerID(),
+		HumidityPct: *reading.HumidityPct,
+		MinPct:      s.humidity.MinPct,
+		MaxPct:      s.humidity.MaxPct,
+		Condition:   reading.HumidityCondition,
+		RecordedAt:  reading.RecordedAt,
+	}
+
+	s.logger.Info("carrier humidity condition changed",
+		zap.String("booking_id", evt.BookingID.String()),
+		zap.Float64("humidity_pct", evt.HumidityPct),
+		zap.String("condition", evt.Condition),
+	)
+
+	s.hub.Notify(&ws.Notification{BookingID: evt.BookingID, Type: frameType, Data: evt})
+	if s.inbox != nil {
+		if err := s.inbox.Record(ctx, evt.BookingID, uuid.Nil, inboxDomain.KindSystem, frameType, uuid.New(), evt); err != nil {
+			s.logger.Error("failed to record humidity alert in inbox", zap.Error(err))
+		}
+	}
+
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventType, evt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish humidity event", zap.Error(err))
+	}
+}
+
+func toThresholdDTO(t *temperatureDomain.Threshold) TemperatureThresholdDTO {
+	return TemperatureThresholdDTO{
+		Species:    t.Species,
+		MinCelsius: t.MinCelsius,
+		MaxCelsius: t.MaxCelsius,
+		UpdatedAt:  t.UpdatedAt,
+	}
+}
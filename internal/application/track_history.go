@@ -0,0 +1,92 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	historyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/history"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// TrackEventDTO is the API representation of a lifecycle event. ActorID is omitted for
+// events caused by the system.
+type TrackEventDTO struct {
+	ID         uuid.UUID  `json:"id"`
+	TrackID    uuid.UUID  `json:"track_id"`
+	Kind       string     `json:"kind"`
+	Status     string     `json:"status"`
+	ActorType  string     `json:"actor_type"`
+	ActorID    *uuid.UUID `json:"actor_id,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
+
+// TrackHistory keeps the append-only lifecycle log of trip tracks, so support can
+// reconstruct what happened to a trip.
+type TrackHistory struct {
+	repo   historyDomain.Repository
+	tracks trackingDomain.TripTrackRepository
+	logger *zap.Logger
+}
+
+// NewTrackHistory creates a new TrackHistory.
+func NewTrackHistory(repo historyDomain.Repository, tracks trackingDomain.TripTrackRepository, logger *zap.Logger) *TrackHistory {
+	return &TrackHistory{repo: repo, tracks: tracks, logger: logger.With(zap.String("component", "track_history"))}
+}
+
+// Record appends a lifecycle event for track, which must already reflect the event.
+// Failures are logged, not returned, since the log never holds up the transition it
+// records.
+func (h *TrackHistory) Record(ctx context.Context, track *trackingDomain.TripTrack, kind historyDomain.Kind, actor historyDomain.Actor, reason string, at time.Time) {
+	event := &historyDomain.TrackEvent{
+		ID:         uuid.New(),
+		TrackID:    track.ID(),
+		BookingID:  track.BookingID(),
+		Kind:       kind,
+		Status:     string(track.Status()),
+		Actor:      actor,
+		Reason:     reason,
+		OccurredAt: at.UTC(),
+	}
+	if err := h.repo.Append(ctx, event); err != nil {
+		h.logger.Error("failed to record track event",
+			zap.String("booking_id", event.BookingID.String()),
+			zap.String("kind", string(kind)),
+			zap.Error(err),
+		)
+	}
+}
+
+// GetHistory returns a booking's lifecycle events, oldest first. The log outlives the
+// track, so a booking whose track was merged into another still has its history.
+func (h *TrackHistory) GetHistory(ctx context.Context, bookingID uuid.UUID) ([]TrackEventDTO, error) {
+	events, err := h.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find track events: %w", err)
+	}
+	if len(events) == 0 {
+		if _, err := h.tracks.FindByBookingID(ctx, bookingID); err != nil {
+			return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+		}
+	}
+
+	result := make([]TrackEventDTO, len(events))
+	for i, e := range events {
+		result[i] = TrackEventDTO{
+			ID:         e.ID,
+			TrackID:    e.TrackID,
+			Kind:       string(e.Kind),
+			Status:     e.Status,
+			ActorType:  e.Actor.Type,
+			ActorID:    e.Actor.ID,
+			Reason:     e.Reason,
+			OccurredAt: e.OccurredAt,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,205 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	chatDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/chat"
+	historyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/history"
+	shareDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/share"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// eventTrackingMerged is the CloudEvent type published when a duplicate track is merged.
+const eventTrackingMerged = "tracking.merged"
+
+// MergeTracksRequest merges the track of DuplicateBookingID into the track of BookingID.
+type MergeTracksRequest struct {
+	BookingID          uuid.UUID `json:"booking_id" binding:"required"`
+	DuplicateBookingID uuid.UUID `json:"duplicate_booking_id" binding:"required"`
+}
+
+// TrackMergedEvent is published when a duplicate track is merged into another. The
+// duplicate booking no longer has a track; consumers should treat it as void and use
+// BookingID instead.
+type TrackMergedEvent struct {
+	TrackID            uuid.UUID `json:"track_id"`
+	BookingID          uuid.UUID `json:"booking_id"`
+	RunnerID           uuid.UUID `json:"runner_id"`
+	DuplicateTrackID   uuid.UUID `json:"duplicate_track_id"`
+	DuplicateBookingID uuid.UUID `json:"duplicate_booking_id"`
+	TotalDistanceKm    float64   `json:"total_distance_km"`
+	StartedAt          time.Time `json:"started_at"`
+	MergedBy           uuid.UUID `json:"merged_by"`
+	OccurredAt         time.Time `json:"occurred_at"`
+}
+
+// TrackMergeDTO is the outcome of a merge: the merged track and what was moved to it.
+type TrackMergeDTO struct {
+	Tracking           *TrackingDTO `json:"tracking"`
+	DuplicateTrackID   uuid.UUID    `json:"duplicate_track_id"`
+	DuplicateBookingID uuid.UUID    `json:"duplicate_booking_id"`
+	WaypointsMoved     int          `json:"waypoints_moved"`
+	MessagesMoved      int          `json:"messages_moved"`
+	SharesMoved        int          `json:"shares_moved"`
+}
+
+// TrackMergeService merges trip tracks that were created twice for the same delivery,
+// e.g. from duplicated booking events.
+type TrackMergeService struct {
+	tracking *TrackingService
+	chats    chatDomain.ChatRepository
+	shares   shareDomain.SharedTripRepository
+	logger   *zap.Logger
+}
+
+// NewTrackMergeService creates a new TrackMergeService.
+func NewTrackMergeService(
+	tracking *TrackingService,
+	chats chatDomain.ChatRepository,
+	shares shareDomain.SharedTripRepository,
+	logger *zap.Logger,
+) *TrackMergeService {
+	return &TrackMergeService{tracking: tracking, chats: chats, shares: shares, logger: logger}
+}
+
+// Merge moves the duplicate booking's waypoints, chat messages and share links to the
+// booking's track, recomputes its distance, deletes the duplicate track and publishes
+// corrective events. Each step is safe to repeat, so a merge that failed part-way can
+// be retried.
+func (s *TrackMergeService) Merge(ctx context.Context, req MergeTracksRequest, adminID uuid.UUID) (*TrackMergeDTO, error) {
+	if req.BookingID == req.DuplicateBookingID {
+		return nil, apperror.New(apperror.CodeInvalidRequest, "cannot merge booking %s into itself", req.BookingID)
+	}
+	repo := s.tracking.repo
+
+	track, err := repo.FindByBookingID(ctx, req.BookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", req.BookingID)
+	}
+	duplicate, err := repo.FindByBookingID(ctx, req.DuplicateBookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", req.DuplicateBookingID)
+	}
+	if duplicate.RunnerID() != track.RunnerID() {
+		return nil, apperror.New(apperror.CodeValidation, "bookings %s and %s have different runners", req.BookingID, req.DuplicateBookingID)
+	}
+
+	moved, err := repo.GetWaypoints(ctx, duplicate.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load duplicate waypoints: %w", err)
+	}
+	if err := repo.MoveWaypoints(ctx, duplicate.ID(), track.ID()); err != nil {
+		return nil, err
+	}
+
+	waypoints, err := repo.GetWaypoints(ctx, track.ID())
+	if err != nil {
+		s.logger.Warn("failed to load merged waypoints", zap.Error(err))
+	}
+	totalDistance := routeLengthKm(ctx, repo, s.logger, track.ID(), time.Time{}, time.Time{}, waypoints)
+
+	now := s.tracking.clock.Now()
+	if err := track.Merge(duplicate, totalDistance, now); err != nil {
+		return nil, apperror.Wrap(apperror.CodeValidation, err)
+	}
+	track.IncrementVersion(now)
+	if err := repo.Update(ctx, track); err != nil {
+		return nil, fmt.Errorf("failed to update tracking: %w", err)
+	}
+	var last *trackingDomain.Waypoint
+	if len(waypoints) > 0 {
+		last = &waypoints[len(waypoints)-1]
+	}
+	if err := repo.ResetDistance(ctx, track.ID(), totalDistance, last); err != nil {
+		return nil, fmt.Errorf("failed to reset tracking distance: %w", err)
+	}
+
+	messages, err := s.chats.ReassignBooking(ctx, duplicate.BookingID(), track.BookingID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to move chat messages: %w", err)
+	}
+	shares, err := s.shares.ReassignBooking(ctx, duplicate.BookingID(), track.BookingID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to move share links: %w", err)
+	}
+
+	if err := repo.Delete(ctx, duplicate.ID()); err != nil {
+		return nil, fmt.Errorf("failed to delete duplicate track: %w", err)
+	}
+	s.tracking.forgetLiveState(duplicate.BookingID())
+	if s.tracking.history != nil {
+		s.tracking.history.Record(ctx, track, historyDomain.KindMerged, historyDomain.User(string(auth.RoleAdmin), adminID),
+			fmt.Sprintf("merged duplicate booking %s", duplicate.BookingID()), now)
+	}
+
+	s.publishCorrections(ctx, track, duplicate, adminID)
+
+	s.logger.Info("duplicate trip track merged",
+		zap.String("track_id", track.ID().String()),
+		zap.String("booking_id", track.BookingID().String()),
+		zap.String("duplicate_track_id", duplicate.ID().String()),
+		zap.String("duplicate_booking_id", duplicate.BookingID().String()),
+		zap.String("admin_id", adminID.String()),
+		zap.Int("waypoints_moved", len(moved)),
+		zap.Int("messages_moved", messages),
+		zap.Int("shares_moved", shares),
+		zap.Float64("total_distance_km", totalDistance),
+	)
+
+	return &TrackMergeDTO{
+		Tracking:           s.tracking.toTrackingDTO(ctx, track),
+		DuplicateTrackID:   duplicate.ID(),
+		DuplicateBookingID: duplicate.BookingID(),
+		WaypointsMoved:     len(moved),
+		MessagesMoved:      messages,
+		SharesMoved:        shares,
+	}, nil
+}
+
+// publishCorrections publishes a tracking.merged event and, if the merged trip has
+// already completed, a tracking.completed event carrying the corrected distance.
+func (s *TrackMergeService) publishCorrections(ctx context.Context, track, duplicate *trackingDomain.TripTrack, adminID uuid.UUID) {
+	now := s.tracking.clock.Now().UTC()
+	publish := func(eventType string, data interface{}) {
+		cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventType, data)
+		if err != nil {
+			s.logger.Error("failed to create cloud event", zap.Error(err))
+			return
+		}
+		if err := s.tracking.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+			s.logger.Error("failed to publish track merge event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	publish(eventTrackingMerged, TrackMergedEvent{
+		TrackID:            track.ID(),
+		BookingID:          track.BookingID(),
+		RunnerID:           track.RunnerID(),
+		DuplicateTrackID:   duplicate.ID(),
+		DuplicateBookingID: duplicate.BookingID(),
+		TotalDistanceKm:    track.TotalDistanceKm(),
+		StartedAt:          track.StartedAt(),
+		MergedBy:           adminID,
+		OccurredAt:         now,
+	})
+
+	if track.Status() == trackingDomain.TrackingCompleted {
+		publish(events.TrackingCompleted, events.TrackingCompletedEvent{
+			TrackID:       track.ID(),
+			BookingID:     track.BookingID(),
+			RunnerID:      track.RunnerID(),
+			TotalDistance: track.TotalDistanceKm(),
+			CompletedAt:   *track.CompletedAt(),
+			OccurredAt:    now,
+		})
+	}
+}
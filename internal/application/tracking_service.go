@@ -2,17 +2,27 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
-	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
 	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
 	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/clock"
+	geofenceDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/geofence"
+	historyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/history"
+	participantDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/participant"
+	privacyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/privacy"
 	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	webhookDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/webhook"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/overload"
 	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
 )
 
@@ -24,6 +34,66 @@ type WaypointDTO struct {
 	Speed      float64   `json:"speed_kmh"`
 	Heading    float64   `json:"heading_degrees"`
 	RecordedAt time.Time `json:"recorded_at"`
+	Backfilled bool      `json:"backfilled,omitempty"`
+	Accuracy   float64   `json:"accuracy_m,omitempty"`
+	Altitude   *float64  `json:"altitude_m,omitempty"`
+	Provider   string    `json:"provider,omitempty"`
+}
+
+// CancellationDTO represents the reason a trip was cancelled.
+type CancellationDTO struct {
+	Reason      string    `json:"reason_code"`
+	Note        string    `json:"note,omitempty"`
+	CancelledAt time.Time `json:"cancelled_at"`
+}
+
+// CancelTrackingRequest holds the structured reason for cancelling a trip.
+type CancelTrackingRequest struct {
+	ReasonCode string `json:"reason_code" binding:"required"`
+	Note       string `json:"note"`
+}
+
+// eventTrackingCancelled is the CloudEvent type published when a trip is cancelled.
+const eventTrackingCancelled = "tracking.cancelled"
+
+// TrackingCancelledEvent is published when a trip track is cancelled.
+type TrackingCancelledEvent struct {
+	TrackID     uuid.UUID `json:"track_id"`
+	BookingID   uuid.UUID `json:"booking_id"`
+	RunnerID    uuid.UUID `json:"runner_id"`
+	ReasonCode  string    `json:"reason_code"`
+	Note        string    `json:"note,omitempty"`
+	CancelledAt time.Time `json:"cancelled_at"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// WeatherDTO is the weather observed near a point of a trip.
+type WeatherDTO struct {
+	TemperatureC float64   `json:"temperature_c"`
+	FeelsLikeC   float64   `json:"feels_like_c"`
+	HumidityPct  float64   `json:"humidity_pct"`
+	Condition    string    `json:"condition"`
+	ObservedAt   time.Time `json:"observed_at"`
+}
+
+// TripWeatherDTO holds the weather at the start and end of a trip.
+type TripWeatherDTO struct {
+	Start *WeatherDTO `json:"start,omitempty"`
+	End   *WeatherDTO `json:"end,omitempty"`
+}
+
+// eventTripWeather is the CloudEvent type published with the weather captured for a completed trip.
+const eventTripWeather = "tracking.trip_weather"
+
+// TripWeatherEvent is published after a trip completes with its start and end weather.
+type TripWeatherEvent struct {
+	TrackID     uuid.UUID   `json:"track_id"`
+	BookingID   uuid.UUID   `json:"booking_id"`
+	RunnerID    uuid.UUID   `json:"runner_id"`
+	Start       *WeatherDTO `json:"start,omitempty"`
+	End         *WeatherDTO `json:"end,omitempty"`
+	CompletedAt time.Time   `json:"completed_at"`
+	OccurredAt  time.Time   `json:"occurred_at"`
 }
 
 // TrackingDTO represents tracking data in API responses.
@@ -31,41 +101,233 @@ type TrackingDTO struct {
 	ID              uuid.UUID     `json:"id"`
 	BookingID       uuid.UUID     `json:"booking_id"`
 	RunnerID        uuid.UUID     `json:"runner_id"`
+	Region          string        `json:"region,omitempty"`
 	Status          string        `json:"status"`
 	TotalDistanceKm float64      `json:"total_distance_km"`
 	StartedAt       time.Time     `json:"started_at"`
 	CompletedAt     *time.Time    `json:"completed_at,omitempty"`
+	PausedAt        *time.Time    `json:"paused_at,omitempty"`
+	PausedSeconds   float64       `json:"paused_seconds"`
+	DurationSeconds float64       `json:"duration_seconds"`
+	Cancellation    *CancellationDTO `json:"cancellation,omitempty"`
+	Weather         *TripWeatherDTO  `json:"weather,omitempty"`
+	Device          *DeviceStatusDTO `json:"device,omitempty"`
+	Viewport        *ws.ViewportHint `json:"viewport,omitempty"`
 	Waypoints       []WaypointDTO `json:"waypoints"`
+	// NextCursor is set when Waypoints is a page with more waypoints after it.
+	NextCursor      string        `json:"next_cursor,omitempty"`
 }
 
+const (
+	// viewportRouteWindow is the number of latest waypoints the suggested viewport covers.
+	viewportRouteWindow = 20
+
+	// viewportHintInterval is the minimum time between viewport hints in WS frames per booking.
+	viewportHintInterval = 30 * time.Second
+
+	// sheddingFrameInterval is the minimum time between location frames per booking while shedding load.
+	sheddingFrameInterval = 5 * time.Second
+)
+
 // TrackingService implements the application use cases for the tracking domain.
 type TrackingService struct {
 	repo     trackingDomain.TripTrackRepository
 	hub      *ws.Hub
-	producer *kafka.Producer
+	producer EventPublisher
+	overload *overload.Controller
+	config   TrackingConfig
 	logger   *zap.Logger
+	clock    clock.Clock
+	ids      clock.IDGenerator
+
+	liveMu sync.Mutex
+	live   map[uuid.UUID]*liveTripState // bookingID -> in-memory state for live WS frames
+
+	observers []LocationObserver
+	positions trackingDomain.LatestPositionStore
+	geofences geofenceDomain.GeofenceRepository
+
+	participants participantDomain.Repository
+
+	weather     trackingDomain.WeatherProvider
+	routing     trackingDomain.RoutingProvider
+	matcher     trackingDomain.MapMatcher
+	gpsFilter   gpsFilterConfig
+	coordinates *CoordinateValidator
+
+	// lowBatteryPct is the battery level below which a low_battery warning is pushed;
+	// 0 disables warnings.
+	lowBatteryPct int
+	// stall configures stall detection; a zero After disables it.
+	stall StallConfig
+
+	certificates *CertificationService
+	privacy      *LocationPrivacyService
+	etaWatchers  *ETASubscriptionService
+	crates       *TemperatureService
+	timeline     *ChatTimeline
+	history      *TrackHistory
+	webhooks     *WebhookService
+
+	catchUp catchUpState
+}
+
+// EventPublisher publishes CloudEvents to a topic. It is satisfied by the Kafka producer
+// and, in standalone mode, by the in-process event bus.
+type EventPublisher interface {
+	PublishEvent(ctx context.Context, topic string, e kafka.CloudEvent) error
+}
+
+// LocationObserver is notified of every waypoint accepted on an active trip.
+// Observers run synchronously on the ingestion path and must not block for long.
+type LocationObserver interface {
+	OnLocation(ctx context.Context, track *trackingDomain.TripTrack, waypoint trackingDomain.Waypoint)
+}
+
+// AddLocationObserver registers an observer for accepted waypoints. Must be called before consumers start.
+func (s *TrackingService) AddLocationObserver(o LocationObserver) {
+	s.observers = append(s.observers, o)
+}
+
+// UsePositionStore caches each accepted waypoint as the latest position of its booking and
+// runner and serves current-position reads from it. Must be called before consumers start.
+func (s *TrackingService) UsePositionStore(store trackingDomain.LatestPositionStore) {
+	s.positions = store
+}
+
+// UseGeofences lets segment statistics split trips at geofence boundaries.
+func (s *TrackingService) UseGeofences(repo geofenceDomain.GeofenceRepository) {
+	s.geofences = repo
+}
+
+// UseWeatherProvider captures the weather at the start and end of each completed trip.
+func (s *TrackingService) UseWeatherProvider(p trackingDomain.WeatherProvider) {
+	s.weather = p
+}
+
+// UseRoutingProvider estimates remaining distance and arrival along road routes instead
+// of straight lines.
+func (s *TrackingService) UseRoutingProvider(p trackingDomain.RoutingProvider) {
+	s.routing = p
+}
+
+// UseMapMatcher lets route exports be snapped to roads on request.
+func (s *TrackingService) UseMapMatcher(m trackingDomain.MapMatcher) {
+	s.matcher = m
+}
+
+// UseClock replaces the wall clock, e.g. with a clock.Manual in tests.
+func (s *TrackingService) UseClock(c clock.Clock) {
+	s.clock = c
+}
+
+// UseIDGenerator replaces the random ID generator for new tracks and waypoints.
+func (s *TrackingService) UseIDGenerator(g clock.IDGenerator) {
+	s.ids = g
+}
+
+// UseCoordinateValidator rejects location fixes that fail coordinate validation. Must be
+// called before consumers start.
+func (s *TrackingService) UseCoordinateValidator(v *CoordinateValidator) {
+	s.coordinates = v
+}
+
+// UseCertification signs a certificate of each completed trip.
+func (s *TrackingService) UseCertification(c *CertificationService) {
+	s.certificates = c
+}
+
+// UseLocationPrivacy counts the runner locations dropped because no trip was in progress.
+func (s *TrackingService) UseLocationPrivacy(p *LocationPrivacyService) {
+	s.privacy = p
+}
+
+// UseCrateTelemetry stores and evaluates the carrier readings runners attach to their
+// locations.
+func (s *TrackingService) UseCrateTelemetry(t *TemperatureService) {
+	s.crates = t
+}
+
+// UseChatTimeline posts the start and completion of each trip into the booking's chat.
+func (s *TrackingService) UseChatTimeline(t *ChatTimeline) {
+	s.timeline = t
+}
+
+// UseTrackHistory records each trip's lifecycle transitions in its history.
+func (s *TrackingService) UseTrackHistory(h *TrackHistory) {
+	s.history = h
+}
+
+// UseWebhooks delivers the start and completion of each trip to partners' webhooks.
+func (s *TrackingService) UseWebhooks(w *WebhookService) {
+	s.webhooks = w
+}
+
+// UseETASubscriptions notifies other services subscribed to a booking's ETA of significant changes.
+func (s *TrackingService) UseETASubscriptions(e *ETASubscriptionService) {
+	s.etaWatchers = e
+}
+
+// TrackingConfig holds tunables for TrackingService.
+type TrackingConfig struct {
+	// ETAUpdateThreshold is the minimum change in estimated arrival that triggers an eta_update frame.
+	ETAUpdateThreshold time.Duration
+	// LocationPingTimeout is how long a location ping waits for a fresh fix.
+	LocationPingTimeout time.Duration
+	// MaxWaypoints caps the waypoints stored per track; zero disables the cap.
+	MaxWaypoints int
+}
+
+// liveTripState is per-booking in-memory state used to throttle and derive live WS frames.
+type liveTripState struct {
+	lastViewportAt time.Time
+	lastFrameAt    time.Time
+	lastETA        time.Time
+	lastPingAt     time.Time
+	recentSpeeds   []float64
+
+	// positionFilter smooths the trip's live locations, when enabled.
+	positionFilter *trackingDomain.PositionFilter
+
+	// lowBatteryWarned is set once a low_battery warning was pushed, until the battery
+	// recovers.
+	lowBatteryWarned bool
+
+	// waypointCount approximates the track's stored waypoints for the waypoint cap,
+	// once loaded from the repository.
+	waypointCount   int
+	waypointCounted bool
 }
 
 // NewTrackingService creates a new TrackingService.
 func NewTrackingService(
 	repo trackingDomain.TripTrackRepository,
 	hub *ws.Hub,
-	producer *kafka.Producer,
+	producer EventPublisher,
+	overloadCtl *overload.Controller,
+	config TrackingConfig,
 	logger *zap.Logger,
 ) *TrackingService {
 	return &TrackingService{
 		repo:     repo,
 		hub:      hub,
 		producer: producer,
+		overload: overloadCtl,
+		config:   config,
 		logger:   logger,
+		clock:    clock.System,
+		ids:      clock.RandomIDs,
+		live:     make(map[uuid.UUID]*liveTripState),
 	}
 }
 
 // HandleBookingAccepted creates a new TripTrack when a booking is accepted by a runner.
-func (s *TrackingService) HandleBookingAccepted(ctx context.Context, event events.BookingAcceptedEvent) error {
+// region is the region of the topic the event was consumed from (empty for the global topic).
+func (s *TrackingService) HandleBookingAccepted(ctx context.Context, event events.BookingAcceptedEvent, region string) error {
 	s.logger.Info("handling booking accepted event",
 		zap.String("booking_id", event.BookingID.String()),
 		zap.String("runner_id", event.RunnerID.String()),
+		zap.String("region", region),
 	)
 
 	// Check if tracking already exists for this booking.
@@ -77,7 +339,7 @@ func (s *TrackingService) HandleBookingAccepted(ctx context.Context, event event
 		return nil
 	}
 
-	track := trackingDomain.NewTripTrack(event.BookingID, event.RunnerID)
+	track := trackingDomain.NewTripTrack(s.ids.NewID(), event.BookingID, event.RunnerID, region, s.clock.Now())
 
 	if err := s.repo.Save(ctx, track); err != nil {
 		s.logger.Error("failed to save trip track", zap.Error(err))
@@ -90,7 +352,7 @@ func (s *TrackingService) HandleBookingAccepted(ctx context.Context, event event
 		BookingID:  track.BookingID(),
 		RunnerID:   track.RunnerID(),
 		StartedAt:  track.StartedAt(),
-		OccurredAt: time.Now().UTC(),
+		OccurredAt: s.clock.Now().UTC(),
 	}
 	cloudEvt, err := kafka.NewCloudEvent("service-tracking", events.TrackingStarted, startedEvt)
 	if err != nil {
@@ -98,6 +360,15 @@ func (s *TrackingService) HandleBookingAccepted(ctx context.Context, event event
 	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
 		s.logger.Error("failed to publish tracking started event", zap.Error(err))
 	}
+	if s.history != nil {
+		s.history.Record(ctx, track, historyDomain.KindCreated, historyDomain.User(string(auth.RoleRunner), track.RunnerID()), "booking accepted", track.StartedAt())
+	}
+	if s.timeline != nil {
+		s.timeline.Post(ctx, track.BookingID(), MilestoneTrackingStarted)
+	}
+	if s.webhooks != nil {
+		s.webhooks.Notify(ctx, webhookDomain.EventStarted, track, "")
+	}
 
 	s.logger.Info("trip tracking started",
 		zap.String("track_id", track.ID().String()),
@@ -106,35 +377,155 @@ func (s *TrackingService) HandleBookingAccepted(ctx context.Context, event event
 	return nil
 }
 
-// HandleRunnerLocationUpdate adds a waypoint and broadcasts the update via WebSocket.
-func (s *TrackingService) HandleRunnerLocationUpdate(ctx context.Context, event events.RunnerLocationUpdateEvent) error {
-	// Find the active track for this runner.
-	track, err := s.repo.FindActiveByRunnerID(ctx, event.RunnerID)
+// RunnerLocationUpdate is a runner location event as consumed from Kafka: the shared
+// event plus the quality of the fix and the phone's status, which runner apps send when
+// the platform reports them.
+type RunnerLocationUpdate struct {
+	events.RunnerLocationUpdateEvent
+	// Accuracy is the horizontal accuracy of the fix in meters; 0 if unknown.
+	Accuracy float64  `json:"accuracy,omitempty"`
+	Altitude *float64 `json:"altitude,omitempty"`
+	Provider string   `json:"provider,omitempty"`
+	// BatteryPct and Network describe the runner's phone: wifi, cellular or none.
+	BatteryPct *int   `json:"battery_pct,omitempty"`
+	Network    string `json:"network,omitempty"`
+}
+
+// HandleRunnerLocationUpdate adds a waypoint to every active track of the runner and
+// broadcasts the update via WebSocket. A runner carrying a batched multi-pet delivery has
+// several bookings in progress at once, so a single fix is fanned out to each of them.
+func (s *TrackingService) HandleRunnerLocationUpdate(ctx context.Context, update RunnerLocationUpdate) error {
+	event := update.RunnerLocationUpdateEvent
+	// Find the active tracks for this runner.
+	tracks, err := s.repo.FindAllActiveByRunnerID(ctx, event.RunnerID)
 	if err != nil {
-		// No active tracking for this runner; ignore the location update.
+		return fmt.Errorf("failed to find active tracks for runner: %w", err)
+	}
+	if len(tracks) == 0 {
+		// No active tracking for this runner; the location must not be stored.
 		s.logger.Debug("no active tracking for runner, ignoring location update",
 			zap.String("runner_id", event.RunnerID.String()),
 		)
+		s.dropLocation(event.RunnerID, sourceKafka, privacyDomain.DropNoActiveTrip)
+		return nil
+	}
+
+	historical := s.historical(event.Timestamp)
+	var errs []error
+	for _, track := range tracks {
+		if err := s.recordRunnerLocation(ctx, track, update, historical); err != nil {
+			s.logger.Error("failed to record location for track",
+				zap.String("track_id", track.ID().String()),
+				zap.String("booking_id", track.BookingID().String()),
+				zap.Error(err),
+			)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// recordRunnerLocation validates a Kafka location fix against one of the runner's active
+// tracks and records it there. Each track gets its own waypoint so the fix is stored once
+// per booking.
+func (s *TrackingService) recordRunnerLocation(ctx context.Context, track *trackingDomain.TripTrack, update RunnerLocationUpdate, historical bool) error {
+	event := update.RunnerLocationUpdateEvent
+	if reason := s.coordinates.Validate(track.Region(), sourceKafka, event.Latitude, event.Longitude, update.Accuracy); reason != "" {
+		s.logger.Warn("location fix rejected, skipping",
+			zap.String("runner_id", event.RunnerID.String()),
+			zap.String("booking_id", track.BookingID().String()),
+			zap.String("reason", reason),
+		)
 		return nil
 	}
 
 	// Add waypoint.
 	waypoint, err := trackingDomain.NewWaypoint(
+		s.ids.NewID(),
 		event.Latitude,
 		event.Longitude,
 		event.Speed,
 		event.Heading,
 		event.Timestamp,
 	)
+	if err == nil {
+		waypoint, err = waypoint.WithFix(update.Accuracy, update.Altitude, update.Provider)
+	}
 	if err != nil {
 		s.logger.Warn("invalid waypoint data, skipping", zap.Error(err))
 		return nil
 	}
+	if recordedBeforeStart(track, waypoint) {
+		s.dropLocation(track.RunnerID(), sourceKafka, privacyDomain.DropBeforeTripStart)
+		return nil
+	}
 
+	if err := s.recordLocation(ctx, track, waypoint, event, historical); err != nil {
+		return err
+	}
+	s.recordDeviceStatus(ctx, track, update, waypoint.RecordedAt, historical)
+	return nil
+}
+
+// dropLocation counts a runner location that was not stored because no trip was in progress.
+func (s *TrackingService) dropLocation(runnerID uuid.UUID, source, reason string) {
+	if s.privacy != nil {
+		s.privacy.Dropped(runnerID, source, reason)
+	}
+}
+
+// recordLocation persists a waypoint for a track and fans it out to the cache, observers,
+// WebSocket clients and Kafka. The raw waypoint is stored and passed to observers, while
+// the trip's distance, cache, broadcast and events use it smoothed by the GPS filter.
+// Historical locations are broadcast tagged as such, without viewport hints or ETA pushes.
+func (s *TrackingService) recordLocation(
+	ctx context.Context,
+	track *trackingDomain.TripTrack,
+	waypoint trackingDomain.Waypoint,
+	event events.RunnerLocationUpdateEvent,
+	historical bool,
+) error {
+	writeStart := time.Now()
 	if err := s.repo.AddWaypoint(ctx, track.ID(), waypoint); err != nil {
 		s.logger.Error("failed to add waypoint", zap.Error(err))
 		return fmt.Errorf("failed to add waypoint: %w", err)
 	}
+	smoothed := s.smooth(track, waypoint)
+	s.accumulateDistance(ctx, track, []trackingDomain.Waypoint{smoothed})
+	s.trackMovement(ctx, track, smoothed)
+	s.overload.ObserveDBLatency(time.Since(writeStart))
+	s.enforceWaypointCap(ctx, track)
+
+	s.logger.Debug("waypoint recorded",
+		zap.String("booking_id", track.BookingID().String()),
+		zap.String("runner_id", track.RunnerID().String()),
+		zap.Float64("latitude", waypoint.Latitude),
+		zap.Float64("longitude", waypoint.Longitude),
+		zap.Float64("speed", waypoint.Speed),
+		zap.Time("recorded_at", waypoint.RecordedAt),
+	)
+
+	if s.positions != nil {
+		if err := s.positions.Set(ctx, trackingDomain.LatestPosition{
+			BookingID: track.BookingID(),
+			RunnerID:  track.RunnerID(),
+			Waypoint:  smoothed,
+		}); err != nil {
+			s.logger.Warn("failed to cache latest position", zap.Error(err))
+		}
+	}
+
+	for _, o := range s.observers {
+		o.OnLocation(ctx, track, waypoint)
+	}
+
+	event.Latitude, event.Longitude = smoothed.Latitude, smoothed.Longitude
+
+	// Under overload, keep location flowing but at a reduced frame rate and without enrichment.
+	shedding := s.overload.Shedding()
+	if shedding && !s.sheddingFrameDue(track.BookingID()) {
+		return s.publishTrackingUpdated(ctx, track, event)
+	}
 
 	// Broadcast via WebSocket hub.
 	update := &ws.TrackingUpdate{
@@ -145,10 +536,22 @@ func (s *TrackingService) HandleRunnerLocationUpdate(ctx context.Context, event
 		Speed:     event.Speed,
 		Heading:   event.Heading,
 		Timestamp: event.Timestamp,
+		Historical: historical,
+	}
+	if !shedding && !historical && s.viewportHintDue(track.BookingID()) {
+		update.Viewport = s.computeViewport(ctx, track)
 	}
 	s.hub.Broadcast(update)
 
-	// Publish TrackingUpdatedEvent.
+	if !shedding && !historical {
+		s.pushETAIfChanged(ctx, track, smoothed)
+	}
+
+	return s.publishTrackingUpdated(ctx, track, event)
+}
+
+// publishTrackingUpdated publishes a TrackingUpdatedEvent for a location update.
+func (s *TrackingService) publishTrackingUpdated(ctx context.Context, track *trackingDomain.TripTrack, event events.RunnerLocationUpdateEvent) error {
 	updatedEvt := events.TrackingUpdatedEvent{
 		TrackID:    track.ID(),
 		BookingID:  track.BookingID(),
@@ -156,7 +559,7 @@ func (s *TrackingService) HandleRunnerLocationUpdate(ctx context.Context, event
 		Latitude:   event.Latitude,
 		Longitude:  event.Longitude,
 		Speed:      event.Speed,
-		OccurredAt: time.Now().UTC(),
+		OccurredAt: s.clock.Now().UTC(),
 	}
 	cloudEvt, err := kafka.NewCloudEvent("service-tracking", events.TrackingUpdated, updatedEvt)
 	if err != nil {
@@ -188,20 +591,29 @@ func (s *TrackingService) HandleDeliveryConfirmed(ctx context.Context, event eve
 		return nil
 	}
 
-	// Calculate total distance from waypoints.
-	waypoints, err := s.repo.GetWaypoints(ctx, track.ID())
-	if err != nil {
-		s.logger.Warn("failed to get waypoints for distance calculation", zap.Error(err))
-	}
-	totalDistance := calculateTotalDistance(waypoints)
+	// The distance was accumulated as waypoints arrived.
+	totalDistance := track.TotalDistanceKm()
 
-	if err := track.Complete(totalDistance); err != nil {
+	if err := track.Complete(totalDistance, s.clock.Now()); err != nil {
 		return fmt.Errorf("failed to complete tracking: %w", err)
 	}
+	// Weather is enrichment; skip the provider call while shedding load.
+	if s.weather != nil && !s.overload.Shedding() {
+		if weather := s.captureWeather(ctx, track, s.routeEndpoints(ctx, track)); weather != nil {
+			track.RecordWeather(*weather, s.clock.Now())
+		}
+	}
 
 	if err := s.repo.Update(ctx, track); err != nil {
 		return fmt.Errorf("failed to update tracking: %w", err)
 	}
+	s.forgetLiveState(track.BookingID())
+	if s.history != nil {
+		s.history.Record(ctx, track, historyDomain.KindCompleted, historyDomain.System, "delivery confirmed", *track.CompletedAt())
+	}
+	if s.certificates != nil {
+		s.certificates.Issue(ctx, track)
+	}
 
 	// Publish TrackingCompletedEvent.
 	completedEvt := events.TrackingCompletedEvent{
@@ -210,7 +622,7 @@ func (s *TrackingService) HandleDeliveryConfirmed(ctx context.Context, event eve
 		RunnerID:      track.RunnerID(),
 		TotalDistance: totalDistance,
 		CompletedAt:  *track.CompletedAt(),
-		OccurredAt:   time.Now().UTC(),
+		OccurredAt:   s.clock.Now().UTC(),
 	}
 	cloudEvt, err := kafka.NewCloudEvent("service-tracking", events.TrackingCompleted, completedEvt)
 	if err != nil {
@@ -218,59 +630,341 @@ func (s *TrackingService) HandleDeliveryConfirmed(ctx context.Context, event eve
 	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
 		s.logger.Error("failed to publish tracking completed event", zap.Error(err))
 	}
+	s.publishTripWeather(ctx, track)
+	if s.timeline != nil {
+		s.timeline.Post(ctx, track.BookingID(), MilestoneDeliveryComplete)
+		s.timeline.Forget(track.BookingID())
+	}
+	if s.webhooks != nil {
+		s.webhooks.Notify(ctx, webhookDomain.EventCompleted, track, "")
+	}
 
 	s.logger.Info("trip tracking completed",
 		zap.String("track_id", track.ID().String()),
 		zap.String("booking_id", track.BookingID().String()),
 		zap.Float64("total_distance_km", totalDistance),
+		zap.Duration("duration", track.Duration(s.clock.Now())),
+		zap.Duration("paused", track.PausedDuration()),
 	)
 	return nil
 }
 
+// CancelTracking cancels a booking's active trip with a structured reason on behalf of
+// the user with the given ID and role, and publishes a tracking.cancelled event.
+func (s *TrackingService) CancelTracking(ctx context.Context, bookingID, userID uuid.UUID, role auth.UserRole, req CancelTrackingRequest) (*TrackingDTO, error) {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+	if !track.IsActive() {
+		return nil, apperror.New(apperror.CodeTrackingNotActive, "tracking for booking %s is %s", bookingID, track.Status())
+	}
+
+	if err := track.Cancel(trackingDomain.CancellationReason(req.ReasonCode), req.Note, s.clock.Now()); err != nil {
+		return nil, apperror.Wrap(apperror.CodeValidation, err)
+	}
+
+	track.IncrementVersion(s.clock.Now())
+	if err := s.repo.Update(ctx, track); err != nil {
+		return nil, fmt.Errorf("failed to update tracking: %w", err)
+	}
+	s.forgetLiveState(track.BookingID())
+
+	cancellation := track.Cancellation()
+	if s.history != nil {
+		reason := string(cancellation.Reason)
+		if cancellation.Note != "" {
+			reason += ": " + cancellation.Note
+		}
+		s.history.Record(ctx, track, historyDomain.KindCancelled, historyDomain.User(string(role), userID), reason, cancellation.CancelledAt)
+	}
+	cancelledEvt := TrackingCancelledEvent{
+		TrackID:     track.ID(),
+		BookingID:   track.BookingID(),
+		RunnerID:    track.RunnerID(),
+		ReasonCode:  string(cancellation.Reason),
+		Note:        cancellation.Note,
+		CancelledAt: cancellation.CancelledAt,
+		OccurredAt:  s.clock.Now().UTC(),
+	}
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventTrackingCancelled, cancelledEvt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish tracking cancelled event", zap.Error(err))
+	}
+	if s.timeline != nil {
+		s.timeline.Forget(track.BookingID())
+	}
+
+	s.logger.Info("trip tracking cancelled",
+		zap.String("track_id", track.ID().String()),
+		zap.String("booking_id", track.BookingID().String()),
+		zap.String("reason_code", string(cancellation.Reason)),
+	)
+
+	return s.GetTracking(ctx, bookingID)
+}
+
 // GetTracking returns the tracking data for a booking.
 func (s *TrackingService) GetTracking(ctx context.Context, bookingID uuid.UUID) (*TrackingDTO, error) {
 	track, err := s.repo.FindByBookingID(ctx, bookingID)
 	if err != nil {
-		return nil, domain.NewNotFoundError("tracking", bookingID.String())
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	return s.toTrackingDTO(ctx, track), nil
+}
+
+// GetActiveTrackByRunner returns the tracking data for a runner's current active trip.
+func (s *TrackingService) GetActiveTrackByRunner(ctx context.Context, runnerID uuid.UUID) (*TrackingDTO, error) {
+	track, err := s.repo.FindActiveByRunnerID(ctx, runnerID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no active tracking for runner %s", runnerID)
 	}
 
+	return s.toTrackingDTO(ctx, track), nil
+}
+
+// toTrackingDTO builds the API representation of a track, including its waypoints.
+func (s *TrackingService) toTrackingDTO(ctx context.Context, track *trackingDomain.TripTrack) *TrackingDTO {
 	waypoints, err := s.repo.GetWaypoints(ctx, track.ID())
 	if err != nil {
 		s.logger.Warn("failed to load waypoints", zap.Error(err))
 		waypoints = nil
 	}
 
+	result := s.newTrackingDTO(track, toWaypointDTOs(waypoints))
+	result.Viewport = viewportFor(track, waypoints)
+	return result
+}
+
+// toWaypointDTOs converts waypoints to their API representation.
+func toWaypointDTOs(waypoints []trackingDomain.Waypoint) []WaypointDTO {
 	waypointDTOs := make([]WaypointDTO, 0, len(waypoints))
 	for _, wp := range waypoints {
-		waypointDTOs = append(waypointDTOs, WaypointDTO{
-			ID:         wp.ID,
-			Latitude:   wp.Latitude,
-			Longitude:  wp.Longitude,
-			Speed:      wp.Speed,
-			Heading:    wp.Heading,
-			RecordedAt: wp.RecordedAt,
-		})
+		waypointDTOs = append(waypointDTOs, toWaypointDTO(wp))
 	}
+	return waypointDTOs
+}
 
+// toWaypointDTO converts a domain waypoint to its API representation.
+func toWaypointDTO(wp trackingDomain.Waypoint) WaypointDTO {
+	return WaypointDTO{
+		ID:         wp.ID,
+		Latitude:   wp.Latitude,
+		Longitude:  wp.Longitude,
+		Speed:      wp.Speed,
+		Heading:    wp.Heading,
+		RecordedAt: wp.RecordedAt,
+		Backfilled: wp.Backfilled,
+		Accuracy:   wp.Accuracy,
+		Altitude:   wp.Altitude,
+		Provider:   wp.Provider,
+	}
+}
+
+// newTrackingDTO builds the API representation of a track with the given waypoints and
+// no viewport.
+func (s *TrackingService) newTrackingDTO(track *trackingDomain.TripTrack, waypointDTOs []WaypointDTO) *TrackingDTO {
 	result := &TrackingDTO{
 		ID:              track.ID(),
 		BookingID:       track.BookingID(),
 		RunnerID:        track.RunnerID(),
+		Region:          track.Region(),
 		Status:          string(track.Status()),
 		TotalDistanceKm: track.TotalDistanceKm(),
 		StartedAt:       track.StartedAt(),
 		CompletedAt:     track.CompletedAt(),
+		PausedAt:        track.PausedAt(),
+		PausedSeconds:   track.PausedDuration().Seconds(),
+		DurationSeconds: track.Duration(s.clock.Now()).Seconds(),
+		Device:          toDeviceStatusDTO(track.DeviceStatus()),
 		Waypoints:       waypointDTOs,
 	}
+	if c := track.Cancellation(); c != nil {
+		result.Cancellation = &CancellationDTO{
+			Reason:      string(c.Reason),
+			Note:        c.Note,
+			CancelledAt: c.CancelledAt,
+		}
+	}
+	if w := track.Weather(); w != nil {
+		result.Weather = &TripWeatherDTO{Start: toWeatherDTO(w.Start), End: toWeatherDTO(w.End)}
+	}
 
-	return result, nil
+	return result
 }
 
-// GetRouteGeoJSON returns the route as a GeoJSON string.
-func (s *TrackingService) GetRouteGeoJSON(ctx context.Context, bookingID uuid.UUID) (string, error) {
+// captureWeather looks up the weather at the first waypoint when it was recorded and
+// at the last waypoint on completion. Lookup failures are logged and leave that side
+// empty; it returns nil if nothing was captured.
+func (s *TrackingService) captureWeather(ctx context.Context, track *trackingDomain.TripTrack, waypoints []trackingDomain.Waypoint) *trackingDomain.TripWeather {
+	if s.weather == nil || len(waypoints) == 0 {
+		return nil
+	}
+
+	lookup := func(side string, wp trackingDomain.Waypoint, at time.Time) *trackingDomain.Weather {
+		w, err := s.weather.WeatherAt(ctx, wp.Latitude, wp.Longitude, at)
+		if err != nil {
+			s.logger.Warn("failed to capture trip weather",
+				zap.String("booking_id", track.BookingID().String()),
+				zap.String("side", side),
+				zap.Error(err),
+			)
+			return nil
+		}
+		return w
+	}
+
+	first, last := waypoints[0], waypoints[len(waypoints)-1]
+	weather := trackingDomain.TripWeather{
+		Start: lookup("start", first, first.RecordedAt),
+		End:   lookup("end", last, *track.CompletedAt()),
+	}
+	if weather.Start == nil && weather.End == nil {
+		return nil
+	}
+	return &weather
+}
+
+// publishTripWeather publishes the weather captured for a completed trip, if any.
+func (s *TrackingService) publishTripWeather(ctx context.Context, track *trackingDomain.TripTrack) {
+	w := track.Weather()
+	if w == nil {
+		return
+	}
+
+	evt := TripWeatherEvent{
+		TrackID:     track.ID(),
+		BookingID:   track.BookingID(),
+		RunnerID:    track.RunnerID(),
+		Start:       toWeatherDTO(w.Start),
+		End:         toWeatherDTO(w.End),
+		CompletedAt: *track.CompletedAt(),
+		OccurredAt:  s.clock.Now().UTC(),
+	}
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventTripWeather, evt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish trip weather event", zap.Error(err))
+	}
+}
+
+func toWeatherDTO(w *trackingDomain.Weather) *WeatherDTO {
+	if w == nil {
+		return nil
+	}
+	return &WeatherDTO{
+		TemperatureC: w.TemperatureC,
+		FeelsLikeC:   w.FeelsLikeC,
+		HumidityPct:  w.HumidityPct,
+		Condition:    w.Condition,
+		ObservedAt:   w.ObservedAt,
+	}
+}
+
+// RouteOptions controls optional simplification and slicing of exported routes.
+type RouteOptions struct {
+	// Tolerance is the Douglas-Peucker tolerance in degrees; 0 keeps every point
+	// unless MaxPoints forces simplification.
+	Tolerance float64
+	// MaxPoints caps the number of points returned; 0 means no cap.
+	MaxPoints int
+	// From and To restrict the route to a time window; either may be zero.
+	From, To time.Time
+	// Bounds restricts the route to the chunks that cross a bounding box. It cannot be
+	// combined with a time window.
+	Bounds *trackingDomain.BoundingBox
+	// Format is empty or RouteFormatGeoJSON for a plain LineString, RouteFormatResampled,
+	// RouteFormatGPX or RouteFormatKML.
+	Format string
+	// IntervalMeters is the distance between points of a resampled route; 0 means
+	// DefaultResampleInterval.
+	IntervalMeters float64
+	// Matched snaps the route to roads before it is simplified or resampled. Stored
+	// waypoints are not changed.
+	Matched bool
+}
+
+// GetRouteGeoJSON returns the route as a GeoJSON string, simplified according to opts.
+// A bounding box yields a MultiLineString with one line per contiguous run of chunks
+// inside it.
+func (s *TrackingService) GetRouteGeoJSON(ctx context.Context, bookingID uuid.UUID, opts RouteOptions) (string, error) {
+	if opts.Bounds != nil && (!opts.From.IsZero() || !opts.To.IsZero()) {
+		return "", apperror.New(apperror.CodeValidation, "bbox cannot be combined with from or to")
+	}
+	if !opts.From.IsZero() && !opts.To.IsZero() && opts.To.Before(opts.From) {
+		return "", apperror.New(apperror.CodeValidation, "to must not be before from")
+	}
+	if opts.Format == RouteFormatResampled {
+		if err := validateResampleOptions(&opts); err != nil {
+			return "", err
+		}
+	}
+	if opts.Matched && s.matcher == nil {
+		return "", apperror.New(apperror.CodeMapMatchingUnavailable, "map matching is not configured")
+	}
+
 	track, err := s.repo.FindByBookingID(ctx, bookingID)
 	if err != nil {
-		return "", domain.NewNotFoundError("tracking", bookingID.String())
+		return "", apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	if opts.Bounds != nil {
+		chunks, err := s.repo.GetChunksInBounds(ctx, track.ID(), *opts.Bounds)
+		if err != nil {
+			return "", fmt.Errorf("failed to get waypoint chunks: %w", err)
+		}
+		lines, err := s.chunkRuns(ctx, chunks, opts)
+		if err != nil {
+			return "", err
+		}
+		return trackingDomain.MultiLineStringGeoJSON(lines)
+	}
+
+	if !opts.From.IsZero() || !opts.To.IsZero() {
+		from, to := opts.From, opts.To
+		if from.IsZero() {
+			from = track.StartedAt()
+		}
+		if to.IsZero() {
+			to = s.clock.Now().UTC()
+		}
+		waypoints, err := s.repo.GetWaypointsBetween(ctx, track.ID(), from, to)
+		if err != nil {
+			return "", fmt.Errorf("failed to get waypoints: %w", err)
+		}
+		if waypoints, err = s.matchWaypoints(ctx, waypoints, opts); err != nil {
+			return "", err
+		}
+		if opts.Format == RouteFormatResampled {
+			return resampledRouteGeoJSON(waypoints, opts.IntervalMeters)
+		}
+		return trackingDomain.LineStringGeoJSON(trackingDomain.SimplifyWaypoints(waypoints, opts.Tolerance, opts.MaxPoints))
+	}
+
+	if opts.Format == RouteFormatResampled {
+		waypoints, err := s.repo.GetWaypoints(ctx, track.ID())
+		if err != nil {
+			return "", fmt.Errorf("failed to get waypoints: %w", err)
+		}
+		if waypoints, err = s.matchWaypoints(ctx, waypoints, opts); err != nil {
+			return "", err
+		}
+		return resampledRouteGeoJSON(waypoints, opts.IntervalMeters)
+	}
+
+	if opts.Tolerance > 0 || opts.MaxPoints > 0 || opts.Matched {
+		waypoints, err := s.repo.GetWaypoints(ctx, track.ID())
+		if err != nil {
+			return "", fmt.Errorf("failed to get waypoints: %w", err)
+		}
+		if waypoints, err = s.matchWaypoints(ctx, waypoints, opts); err != nil {
+			return "", err
+		}
+		return trackingDomain.LineStringGeoJSON(trackingDomain.SimplifyWaypoints(waypoints, opts.Tolerance, opts.MaxPoints))
 	}
 
 	geoJSON, err := s.repo.GetRouteAsGeoJSON(ctx, track.ID())
@@ -281,6 +975,114 @@ func (s *TrackingService) GetRouteGeoJSON(ctx context.Context, bookingID uuid.UU
 	return geoJSON, nil
 }
 
+// viewportHintDue reports whether a viewport hint should be attached to the next
+// WS frame for a booking, and records the send time if so.
+func (s *TrackingService) viewportHintDue(bookingID uuid.UUID) bool {
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+
+	state := s.liveStateLocked(bookingID)
+	now := s.clock.Now()
+	if now.Sub(state.lastViewportAt) < viewportHintInterval {
+		return false
+	}
+	state.lastViewportAt = now
+	return true
+}
+
+// sheddingFrameDue reports whether a location frame may be sent for a booking while
+// shedding load, and records the send time if so.
+func (s *TrackingService) sheddingFrameDue(bookingID uuid.UUID) bool {
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+
+	state := s.liveStateLocked(bookingID)
+	now := s.clock.Now()
+	if now.Sub(state.lastFrameAt) < sheddingFrameInterval {
+		return false
+	}
+	state.lastFrameAt = now
+	return true
+}
+
+// liveStateLocked returns the live state for a booking, creating it if needed.
+// The caller must hold liveMu.
+func (s *TrackingService) liveStateLocked(bookingID uuid.UUID) *liveTripState {
+	state, ok := s.live[bookingID]
+	if !ok {
+		state = &liveTripState{}
+		s.live[bookingID] = state
+	}
+	return state
+}
+
+// forgetLiveState drops the in-memory live state of a booking that is no longer active.
+func (s *TrackingService) forgetLiveState(bookingID uuid.UUID) {
+	s.liveMu.Lock()
+	delete(s.live, bookingID)
+	s.liveMu.Unlock()
+}
+
+// computeViewport loads the track's waypoints and derives a viewport hint.
+func (s *TrackingService) computeViewport(ctx context.Context, track *trackingDomain.TripTrack) *ws.ViewportHint {
+	waypoints, err := s.repo.GetWaypoints(ctx, track.ID())
+	if err != nil {
+		s.logger.Warn("failed to load waypoints for viewport hint", zap.Error(err))
+		return nil
+	}
+	return viewportFor(track, waypoints)
+}
+
+// viewportFor suggests a map viewport covering the recent route (which ends at the
+// runner's latest position) and the destination, if known.
+func viewportFor(track *trackingDomain.TripTrack, waypoints []trackingDomain.Waypoint) *ws.ViewportHint {
+	start := len(waypoints) - viewportRouteWindow
+	if start < 0 {
+		start = 0
+	}
+
+	points := make([]trackingDomain.Location, 0, len(waypoints)-start+1)
+	for _, wp := range waypoints[start:] {
+		points = append(points, trackingDomain.Location{Latitude: wp.Latitude, Longitude: wp.Longitude})
+	}
+	if dest := track.Destination(); dest != nil {
+		points = append(points, *dest)
+	}
+
+	box, ok := trackingDomain.ComputeViewport(points)
+	if !ok {
+		return nil
+	}
+	return &ws.ViewportHint{
+		MinLatitude:  box.MinLatitude,
+		MinLongitude: box.MinLongitude,
+		MaxLatitude:  box.MaxLatitude,
+		MaxLongitude: box.MaxLongitude,
+	}
+}
+
+// routeLengthKm returns the length of a trip's route in [from, to), computed by PostGIS.
+// If the database cannot compute it, the length is calculated from waypoints, which
+// must cover the same window.
+func routeLengthKm(
+	ctx context.Context,
+	repo trackingDomain.TripTrackRepository,
+	logger *zap.Logger,
+	trackID uuid.UUID,
+	from, to time.Time,
+	waypoints []trackingDomain.Waypoint,
+) float64 {
+	km, err := repo.GetRouteLengthKm(ctx, trackID, from, to)
+	if err != nil {
+		logger.Warn("failed to compute route length in database, using waypoints",
+			zap.String("track_id", trackID.String()),
+			zap.Error(err),
+		)
+		return calculateTotalDistance(waypoints)
+	}
+	return math.Round(km*1000) / 1000
+}
+
 // calculateTotalDistance computes the total distance from a sequence of waypoints
 // using the Haversine formula.
 func calculateTotalDistance(waypoints []trackingDomain.Waypoint) float64 {
@@ -0,0 +1,92 @@
+package application
+
+import (
+	"context"
+	"math"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// accumulateDistance adds stored waypoints, in time order, to the track's running
+// distance and returns the distance they added. Waypoints recorded after the track's
+// last position extend the route from it; waypoints recorded before it, such as late
+// Kafka updates or offline uploads, are measured against their neighbours instead.
+func (s *TrackingService) accumulateDistance(ctx context.Context, track *trackingDomain.TripTrack, waypoints []trackingDomain.Waypoint) float64 {
+	var addedKm float64
+	var inserted []trackingDomain.Waypoint
+	for _, w := range waypoints {
+		km, advanced, err := s.repo.AdvanceDistance(ctx, track.ID(), w)
+		if err != nil {
+			s.logger.Warn("failed to advance trip distance",
+				zap.String("booking_id", track.BookingID().String()),
+				zap.Error(err),
+			)
+			return addedKm
+		}
+		if !advanced {
+			inserted = append(inserted, w)
+			continue
+		}
+		addedKm += km
+	}
+
+	if len(inserted) > 0 && track.IsActive() {
+		km, err := s.insertedDistanceKm(ctx, track, inserted)
+		if err == nil {
+			err = s.repo.AddDistance(ctx, track.ID(), km)
+		}
+		if err != nil {
+			s.logger.Warn("failed to add distance of inserted waypoints",
+				zap.String("booking_id", track.BookingID().String()),
+				zap.Error(err),
+			)
+		} else {
+			addedKm += km
+		}
+	}
+	return math.Round(addedKm*1000) / 1000
+}
+
+// insertedDistanceKm returns how much stored waypoints, in time order, lengthen the
+// route. Only the span they were inserted into is read: the route through it with and
+// without them, bounded by the nearest waypoint on either side.
+func (s *TrackingService) insertedDistanceKm(ctx context.Context, track *trackingDomain.TripTrack, inserted []trackingDomain.Waypoint) (float64, error) {
+	span, err := s.repo.GetWaypointsBetween(ctx, track.ID(), inserted[0].RecordedAt, inserted[len(inserted)-1].RecordedAt)
+	if err != nil {
+		return 0, err
+	}
+	isInserted := make(map[uuid.UUID]bool, len(inserted))
+	for _, w := range inserted {
+		isInserted[w.ID] = true
+	}
+	existing := make([]trackingDomain.Waypoint, 0, len(span))
+	for _, w := range span {
+		if !isInserted[w.ID] {
+			existing = append(existing, w)
+		}
+	}
+	return calculateTotalDistance(span) - calculateTotalDistance(existing), nil
+}
+
+// routeEndpoints returns the first and last waypoint of a track, reading only the
+// chunks that hold them, or nil if it has none.
+func (s *TrackingService) routeEndpoints(ctx context.Context, track *trackingDomain.TripTrack) []trackingDomain.Waypoint {
+	head, err := s.repo.GetWaypointsBetween(ctx, track.ID(), track.StartedAt(), track.StartedAt())
+	if err != nil {
+		s.logger.Warn("failed to load first waypoint", zap.Error(err))
+		return nil
+	}
+	now := s.clock.Now()
+	tail, err := s.repo.GetWaypointsBetween(ctx, track.ID(), now, now)
+	if err != nil {
+		s.logger.Warn("failed to load last waypoint", zap.Error(err))
+		return nil
+	}
+	if len(head) == 0 || len(tail) == 0 {
+		return nil
+	}
+	return []trackingDomain.Waypoint{head[0], tail[len(tail)-1]}
+}
@@ -0,0 +1,99 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	historyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/history"
+)
+
+// CloudEvent types published when a runner pauses or resumes a trip.
+const (
+	eventTrackingPaused  = "tracking.paused"
+	eventTrackingResumed = "tracking.resumed"
+)
+
+// PauseTrackingRequest pauses (true) or resumes (false) a trip.
+type PauseTrackingRequest struct {
+	Paused *bool `json:"paused" binding:"required"`
+}
+
+// TrackingPauseEvent is published when a trip is paused or resumed. PausedSeconds is
+// the trip's total paused time so far, including a pause that just ended.
+type TrackingPauseEvent struct {
+	TrackID       uuid.UUID `json:"track_id"`
+	BookingID     uuid.UUID `json:"booking_id"`
+	RunnerID      uuid.UUID `json:"runner_id"`
+	PausedSeconds float64   `json:"paused_seconds"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// SetTrackingPaused pauses or resumes a booking's trip on behalf of its runner, e.g.
+// for a break. Waypoints are still accepted while paused, but anomaly alerts are
+// suppressed and the paused time is excluded from the trip's duration.
+func (s *TrackingService) SetTrackingPaused(ctx context.Context, bookingID, runnerID uuid.UUID, paused bool) (*TrackingDTO, error) {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+	if track.RunnerID() != runnerID {
+		return nil, apperror.New(apperror.CodeNotParticipant, "runner is not assigned to booking %s", bookingID)
+	}
+	if !track.IsActive() {
+		return nil, apperror.New(apperror.CodeTrackingNotActive, "tracking for booking %s is %s", bookingID, track.Status())
+	}
+	if track.IsPaused() == paused {
+		return s.toTrackingDTO(ctx, track), nil
+	}
+
+	now := s.clock.Now()
+	eventType, kind := eventTrackingResumed, historyDomain.KindResumed
+	if paused {
+		eventType, kind = eventTrackingPaused, historyDomain.KindPaused
+		err = track.Pause(now)
+	} else {
+		err = track.Resume(now)
+	}
+	if err != nil {
+		return nil, apperror.Wrap(apperror.CodeTrackingNotActive, err)
+	}
+
+	track.IncrementVersion(now)
+	if err := s.repo.Update(ctx, track); err != nil {
+		return nil, fmt.Errorf("failed to update tracking: %w", err)
+	}
+	if !paused {
+		s.restartStallClock(ctx, track, now)
+	}
+	if s.history != nil {
+		s.history.Record(ctx, track, kind, historyDomain.User(string(auth.RoleRunner), runnerID), "", now)
+	}
+
+	pauseEvt := TrackingPauseEvent{
+		TrackID:       track.ID(),
+		BookingID:     track.BookingID(),
+		RunnerID:      track.RunnerID(),
+		PausedSeconds: track.PausedDuration().Seconds(),
+		OccurredAt:    now.UTC(),
+	}
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking", eventType, pauseEvt)
+	if err != nil {
+		s.logger.Error("failed to create cloud event", zap.Error(err))
+	} else if err := s.producer.PublishEvent(ctx, events.TopicTrackingEvents, cloudEvt); err != nil {
+		s.logger.Error("failed to publish tracking pause event", zap.Error(err))
+	}
+
+	s.logger.Info("trip tracking pause changed",
+		zap.String("booking_id", track.BookingID().String()),
+		zap.String("status", string(track.Status())),
+	)
+	return s.toTrackingDTO(ctx, track), nil
+}
@@ -0,0 +1,140 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+const (
+	// DefaultReplaySpeed is the playback speed used when none is requested.
+	DefaultReplaySpeed = 10.0
+	// MaxReplaySpeed is the fastest playback speed allowed.
+	MaxReplaySpeed = 1000.0
+
+	// maxReplayPause caps the real-time wait between two replayed waypoints, so long stops
+	// do not stall the replay.
+	maxReplayPause = 5 * time.Second
+)
+
+// Replay frame event names.
+const (
+	ReplayEventStart    = "start"
+	ReplayEventWaypoint = "waypoint"
+	ReplayEventEnd      = "end"
+)
+
+// ReplayStartDTO opens a trip replay.
+type ReplayStartDTO struct {
+	BookingID     uuid.UUID  `json:"booking_id"`
+	RunnerID      uuid.UUID  `json:"runner_id"`
+	Status        string     `json:"status"`
+	StartedAt     time.Time  `json:"started_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	WaypointCount int        `json:"waypoint_count"`
+	Speed         float64    `json:"speed"`
+}
+
+// ReplayWaypointDTO is one replayed waypoint. ElapsedSeconds is the trip time since the
+// first waypoint.
+type ReplayWaypointDTO struct {
+	Seq            int     `json:"seq"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+	WaypointDTO
+}
+
+// ReplayEndDTO closes a trip replay.
+type ReplayEndDTO struct {
+	WaypointCount   int     `json:"waypoint_count"`
+	TripSeconds     float64 `json:"trip_seconds"`
+	PlaybackSeconds float64 `json:"playback_seconds"`
+}
+
+// ReplayFrame is one event of a trip replay: a ReplayStartDTO, ReplayWaypointDTO or
+// ReplayEndDTO.
+type ReplayFrame struct {
+	Event string
+	Data  interface{}
+}
+
+// TripReplay is a trip's recorded waypoints, ready to be played back.
+type TripReplay struct {
+	track     *trackingDomain.TripTrack
+	waypoints []trackingDomain.Waypoint
+}
+
+// LoadReplay loads a booking's trip for playback.
+func (s *TrackingService) LoadReplay(ctx context.Context, bookingID uuid.UUID) (*TripReplay, error) {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	waypoints, err := s.repo.GetWaypoints(ctx, track.ID())
+	if err != nil {
+		return nil, err
+	}
+	if len(waypoints) == 0 {
+		return nil, apperror.New(apperror.CodePositionUnknown, "no waypoints recorded for booking %s", bookingID)
+	}
+	return &TripReplay{track: track, waypoints: waypoints}, nil
+}
+
+// Play emits the trip's waypoints with the gaps between them divided by speed, e.g. a
+// 30s gap becomes 3s at speed 10, and capped at maxReplayPause. It stops when emit
+// fails or ctx is cancelled.
+func (r *TripReplay) Play(ctx context.Context, speed float64, emit func(ReplayFrame) error) error {
+	start := &ReplayStartDTO{
+		BookingID:     r.track.BookingID(),
+		RunnerID:      r.track.RunnerID(),
+		Status:        string(r.track.Status()),
+		StartedAt:     r.track.StartedAt(),
+		CompletedAt:   r.track.CompletedAt(),
+		WaypointCount: len(r.waypoints),
+		Speed:         speed,
+	}
+	if err := emit(ReplayFrame{Event: ReplayEventStart, Data: start}); err != nil {
+		return err
+	}
+
+	playbackStart := time.Now()
+	first := r.waypoints[0].RecordedAt
+
+	for i, wp := range r.waypoints {
+		if i > 0 {
+			pause := time.Duration(float64(wp.RecordedAt.Sub(r.waypoints[i-1].RecordedAt)) / speed)
+			if pause > maxReplayPause {
+				pause = maxReplayPause
+			}
+			if pause > 0 {
+				timer := time.NewTimer(pause)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+
+		frame := &ReplayWaypointDTO{
+			Seq:            i,
+			ElapsedSeconds: wp.RecordedAt.Sub(first).Seconds(),
+			WaypointDTO:    toWaypointDTO(wp),
+		}
+		if err := emit(ReplayFrame{Event: ReplayEventWaypoint, Data: frame}); err != nil {
+			return err
+		}
+	}
+
+	end := &ReplayEndDTO{
+		WaypointCount:   len(r.waypoints),
+		TripSeconds:     r.waypoints[len(r.waypoints)-1].RecordedAt.Sub(first).Seconds(),
+		PlaybackSeconds: time.Since(playbackStart).Seconds(),
+	}
+	return emit(ReplayFrame{Event: ReplayEventEnd, Data: end})
+}
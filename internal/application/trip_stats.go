@@ -0,0 +1,71 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// pickupRadiusMeters is how far from the trip's first waypoint still counts as the pickup.
+const pickupRadiusMeters = 100.0
+
+// TripStatsDTO holds computed metrics for a booking's trip. DurationSeconds excludes
+// time spent paused; IdlePercentage is the share of the time between the first and last
+// waypoint spent stationary.
+type TripStatsDTO struct {
+	BookingID           uuid.UUID `json:"booking_id"`
+	TrackID             uuid.UUID `json:"track_id"`
+	Status              string    `json:"status"`
+	WaypointCount       int       `json:"waypoint_count"`
+	TotalDistanceKm     float64   `json:"total_distance_km"`
+	DurationSeconds     float64   `json:"duration_seconds"`
+	AverageSpeedKmh     float64   `json:"average_speed_kmh"`
+	MaxSpeedKmh         float64   `json:"max_speed_kmh"`
+	LongestStopSeconds  float64   `json:"longest_stop_seconds"`
+	TimeAtPickupSeconds float64   `json:"time_at_pickup_seconds"`
+	IdleSeconds         float64   `json:"idle_seconds"`
+	IdlePercentage      float64   `json:"idle_percentage"`
+}
+
+// GetTripStats returns metrics for a booking's trip, aggregated by the database rather
+// than from loaded waypoints.
+func (s *TrackingService) GetTripStats(ctx context.Context, bookingID uuid.UUID) (*TripStatsDTO, error) {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	stats, err := s.repo.GetWaypointStats(ctx, track.ID(), trackingDomain.WaypointStatsOptions{
+		StopSpeedKmh:       stopSpeedKmh,
+		PickupRadiusMeters: pickupRadiusMeters,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip stats: %w", err)
+	}
+
+	duration := track.Duration(s.clock.Now())
+	result := &TripStatsDTO{
+		BookingID:           track.BookingID(),
+		TrackID:             track.ID(),
+		Status:              string(track.Status()),
+		WaypointCount:       stats.WaypointCount,
+		TotalDistanceKm:     math.Round(stats.DistanceKm*1000) / 1000,
+		DurationSeconds:     duration.Seconds(),
+		MaxSpeedKmh:         stats.MaxSpeedKmh,
+		LongestStopSeconds:  stats.LongestStop.Seconds(),
+		TimeAtPickupSeconds: stats.TimeAtPickup.Seconds(),
+		IdleSeconds:         stats.Idle.Seconds(),
+	}
+	if duration > 0 {
+		result.AverageSpeedKmh = math.Round(stats.DistanceKm/duration.Hours()*10) / 10
+	}
+	if span := stats.LastAt.Sub(stats.FirstAt); span > 0 {
+		result.IdlePercentage = math.Round(stats.Idle.Seconds()/span.Seconds()*1000) / 10
+	}
+	return result, nil
+}
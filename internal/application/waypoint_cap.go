@@ -0,0 +1,72 @@
+package application
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// waypointDownsampleFactor is how much older waypoints are thinned each time a track
+// exceeds the waypoint cap: one in every factor is kept.
+const waypointDownsampleFactor = 2
+
+// enforceWaypointCap counts a stored waypoint against its track's cap. Once the cap is
+// exceeded, every waypoint but the latest half of the cap is downsampled, so a
+// misbehaving device cannot write an unbounded number of rows for one trip while the
+// recent route keeps full resolution. Failures are logged; the waypoint is already stored.
+func (s *TrackingService) enforceWaypointCap(ctx context.Context, track *trackingDomain.TripTrack) {
+	limit := s.config.MaxWaypoints
+	if limit <= 0 {
+		return
+	}
+
+	s.liveMu.Lock()
+	state := s.liveStateLocked(track.BookingID())
+	counted := state.waypointCounted
+	state.waypointCount++
+	count := state.waypointCount
+	s.liveMu.Unlock()
+
+	if !counted {
+		n, err := s.repo.CountWaypoints(ctx, track.ID())
+		if err != nil {
+			s.logger.Warn("failed to count waypoints", zap.String("booking_id", track.BookingID().String()), zap.Error(err))
+			return
+		}
+		count = s.setWaypointCount(track, n)
+	}
+	if count <= limit {
+		return
+	}
+
+	if err := s.repo.DownsampleWaypoints(ctx, track.ID(), limit/2, waypointDownsampleFactor); err != nil {
+		s.logger.Error("failed to downsample waypoints", zap.String("booking_id", track.BookingID().String()), zap.Error(err))
+		return
+	}
+	n, err := s.repo.CountWaypoints(ctx, track.ID())
+	if err != nil {
+		s.logger.Warn("failed to count waypoints", zap.String("booking_id", track.BookingID().String()), zap.Error(err))
+		return
+	}
+	s.setWaypointCount(track, n)
+
+	s.logger.Warn("waypoint cap exceeded, downsampled older waypoints",
+		zap.String("booking_id", track.BookingID().String()),
+		zap.String("runner_id", track.RunnerID().String()),
+		zap.Int("max_waypoints", limit),
+		zap.Int("before", count),
+		zap.Int("after", n),
+	)
+}
+
+// setWaypointCount records the stored waypoint count of a track and returns it.
+func (s *TrackingService) setWaypointCount(track *trackingDomain.TripTrack, n int) int {
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+	state := s.liveStateLocked(track.BookingID())
+	state.waypointCount = n
+	state.waypointCounted = true
+	return n
+}
@@ -0,0 +1,72 @@
+package application
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// waypointExportBatchSize is how many waypoints are read and written per CSV chunk.
+const waypointExportBatchSize = 1000
+
+// waypointCSVHeader is the header row of a waypoint CSV export.
+var waypointCSVHeader = []string{"lat", "lng", "speed", "heading", "recorded_at"}
+
+// WaypointCSVExport streams one trip's raw waypoints as CSV.
+type WaypointCSVExport struct {
+	repo  trackingDomain.TripTrackRepository
+	track *trackingDomain.TripTrack
+}
+
+// ExportWaypointsCSV prepares a CSV export of a booking's waypoints. It fails before
+// anything is written if the booking has no track.
+func (s *TrackingService) ExportWaypointsCSV(ctx context.Context, bookingID uuid.UUID) (*WaypointCSVExport, error) {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+	return &WaypointCSVExport{repo: s.repo, track: track}, nil
+}
+
+// Write writes the header and every waypoint of the trip in time order, reading them in
+// batches and calling flush after each batch, so memory stays bounded and the client
+// receives rows as they are read.
+func (e *WaypointCSVExport) Write(ctx context.Context, w io.Writer, flush func()) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(waypointCSVHeader); err != nil {
+		return err
+	}
+
+	record := make([]string, len(waypointCSVHeader))
+	err := e.repo.EachWaypointBatch(ctx, e.track.ID(), waypointExportBatchSize, func(batch []trackingDomain.Waypoint) error {
+		for _, wp := range batch {
+			record[0] = strconv.FormatFloat(wp.Latitude, 'f', -1, 64)
+			record[1] = strconv.FormatFloat(wp.Longitude, 'f', -1, 64)
+			record[2] = strconv.FormatFloat(wp.Speed, 'f', -1, 64)
+			record[3] = strconv.FormatFloat(wp.Heading, 'f', -1, 64)
+			record[4] = wp.RecordedAt.UTC().Format("2006-01-02T15:04:05.000Z07:00")
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		flush()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export waypoints: %w", err)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
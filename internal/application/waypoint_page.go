@@ -0,0 +1,132 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+const (
+	defaultWaypointPageLimit = 500
+	maxWaypointPageLimit     = 2000
+)
+
+// WaypointPageRequest holds the raw query parameters selecting a page of a trip's
+// waypoints. From and To bound the recording time, as RFC 3339 timestamps or YYYY-MM-DD
+// dates; To is exclusive, and a date To includes that whole day.
+type WaypointPageRequest struct {
+	From   string
+	To     string
+	Cursor string
+	Limit  string
+}
+
+// IsZero reports whether no paging parameter was given.
+func (r WaypointPageRequest) IsZero() bool {
+	return r == WaypointPageRequest{}
+}
+
+// WaypointPageDTO is a page of a trip's waypoints. NextCursor is passed as cursor to
+// fetch the next page and is empty on the last page.
+type WaypointPageDTO struct {
+	Waypoints  []WaypointDTO `json:"waypoints"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// ListWaypoints returns a page of a booking's waypoints in time order.
+func (s *TrackingService) ListWaypoints(ctx context.Context, bookingID uuid.UUID, req WaypointPageRequest) (*WaypointPageDTO, error) {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+	return s.waypointPage(ctx, track, req)
+}
+
+// GetTrackingPage returns the tracking data for a booking like GetTracking, but with a
+// page of its waypoints instead of all of them. Paged responses carry no viewport, since
+// a page need not end at the runner's latest position.
+func (s *TrackingService) GetTrackingPage(ctx context.Context, bookingID uuid.UUID, req WaypointPageRequest) (*TrackingDTO, error) {
+	track, err := s.repo.FindByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeTrackingNotFound, "no tracking for booking %s", bookingID)
+	}
+
+	page, err := s.waypointPage(ctx, track, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.newTrackingDTO(track, page.Waypoints)
+	result.NextCursor = page.NextCursor
+	return result, nil
+}
+
+// waypointPage reads the page of a track's waypoints selected by req.
+func (s *TrackingService) waypointPage(ctx context.Context, track *trackingDomain.TripTrack, req WaypointPageRequest) (*WaypointPageDTO, error) {
+	q, err := parseWaypointPageRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra waypoint to learn whether another page follows.
+	limit := q.Limit
+	q.Limit++
+	waypoints, err := s.repo.ListWaypoints(ctx, track.ID(), q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &WaypointPageDTO{}
+	if len(waypoints) > limit {
+		waypoints = waypoints[:limit]
+		cursor, _ := json.Marshal(trackingDomain.CursorAfterWaypoint(waypoints[limit-1]))
+		page.NextCursor = base64.RawURLEncoding.EncodeToString(cursor)
+	}
+	page.Waypoints = toWaypointDTOs(waypoints)
+	return page, nil
+}
+
+// parseWaypointPageRequest validates a waypoint page's parameters and turns them into a
+// query.
+func parseWaypointPageRequest(req WaypointPageRequest) (trackingDomain.WaypointPageQuery, error) {
+	var q trackingDomain.WaypointPageQuery
+
+	from, err := parseListTime(req.From, "from", false)
+	if err != nil {
+		return q, err
+	}
+	to, err := parseListTime(req.To, "to", true)
+	if err != nil {
+		return q, err
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return q, apperror.New(apperror.CodeInvalidRequest, "from must be before to")
+	}
+	q.From, q.To = from, to
+
+	if req.Cursor != "" {
+		var cursor trackingDomain.WaypointCursor
+		raw, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+		if err != nil || json.Unmarshal(raw, &cursor) != nil || cursor.ID == uuid.Nil {
+			return q, apperror.New(apperror.CodeInvalidRequest, "invalid cursor")
+		}
+		q.After = &cursor
+	}
+
+	q.Limit = defaultWaypointPageLimit
+	if req.Limit != "" {
+		n, err := strconv.Atoi(req.Limit)
+		if err != nil || n < 1 {
+			return q, apperror.New(apperror.CodeInvalidRequest, "limit must be a positive integer")
+		}
+		q.Limit = min(n, maxWaypointPageLimit)
+	}
+
+	return q, nil
+}
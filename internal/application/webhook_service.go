@@ -0,0 +1,550 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/clock"
+	participantDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/participant"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	webhookDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/webhook"
+)
+
+// Headers of webhook deliveries.
+const (
+	// headerWebhookSignature is "t=<unix seconds>,v1=<hex HMAC-SHA256>", the HMAC of
+	// "<unix seconds>.<body>" under the webhook's secret.
+	headerWebhookSignature = "X-Webhook-Signature"
+	headerWebhookEvent     = "X-Webhook-Event"
+	// headerWebhookDelivery is the delivery ID, the same on every attempt, so partners
+	// can drop repeated deliveries.
+	headerWebhookDelivery = "X-Webhook-Delivery"
+)
+
+const (
+	// maxWebhooksPerAccount caps the webhooks one account may register.
+	maxWebhooksPerAccount = 20
+	// maxWebhookBackoff caps the delay between two attempts of a delivery.
+	maxWebhookBackoff = time.Hour
+	// webhookClaimMargin is added to the request timeout to hold a delivery while it is
+	// attempted; a delivery whose instance died is retried after it.
+	webhookClaimMargin = 30 * time.Second
+	// webhookRetryBatch caps the deliveries retried per check.
+	webhookRetryBatch = 100
+	// webhookDeliveryRetention is how long delivered and failed deliveries are kept for
+	// partners to inspect.
+	webhookDeliveryRetention = 7 * 24 * time.Hour
+	// webhookPruneInterval is how often finished deliveries past retention are removed.
+	webhookPruneInterval = time.Hour
+	// webhookDeliveriesListed is how many of a webhook's latest deliveries are listed.
+	webhookDeliveriesListed = 50
+)
+
+// WebhookConfig controls the delivery of webhooks.
+type WebhookConfig struct {
+	// Timeout bounds each delivery attempt.
+	Timeout time.Duration
+	// MaxAttempts is how many times a delivery is attempted before it is given up on.
+	MaxAttempts int
+	// Backoff is the delay before the second attempt; it doubles per attempt, up to an
+	// hour.
+	Backoff time.Duration
+	// CheckInterval is how often deliveries due for a retry are looked for.
+	CheckInterval time.Duration
+}
+
+// RegisterWebhookRequest registers a callback URL. With a BookingID the webhook covers
+// that booking; without, every booking the account owns. AccountID registers an
+// account webhook for another account and is reserved to admins. Events defaults to all
+// events.
+type RegisterWebhookRequest struct {
+	URL       string     `json:"url" binding:"required"`
+	BookingID *uuid.UUID `json:"booking_id"`
+	AccountID *uuid.UUID `json:"account_id"`
+	Events    []string   `json:"events"`
+}
+
+// WebhookDTO is a webhook in API responses. Secret is only returned when the webhook is
+// registered.
+type WebhookDTO struct {
+	ID        uuid.UUID  `json:"id"`
+	AccountID uuid.UUID  `json:"account_id"`
+	BookingID *uuid.UUID `json:"booking_id,omitempty"`
+	URL       string     `json:"url"`
+	Events    []string   `json:"events"`
+	Secret    string     `json:"secret,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// WebhookDeliveryDTO is a delivery of an event to a webhook. NextAttemptAt is set while
+// the delivery is pending.
+type WebhookDeliveryDTO struct {
+	ID             uuid.UUID  `json:"id"`
+	Event          string     `json:"event"`
+	BookingID      uuid.UUID  `json:"booking_id"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// WebhookPayload is the JSON body posted to a webhook. ID is the delivery ID. Stop is
+// "pickup" or "dropoff" for tracking.arrived; TotalDistanceKm is set for
+// tracking.completed.
+type WebhookPayload struct {
+	ID              uuid.UUID `json:"id"`
+	Type            string    `json:"type"`
+	BookingID       uuid.UUID `json:"booking_id"`
+	TrackID         uuid.UUID `json:"track_id"`
+	Stop            string    `json:"stop,omitempty"`
+	TotalDistanceKm *float64  `json:"total_distance_km,omitempty"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
+
+// WebhookService lets partners such as pet hotels and vets register callback URLs for a
+// booking's tracking milestones. Each milestone is stored as a delivery per matching
+// webhook and posted with an HMAC signature; failed deliveries are retried with
+// exponential backoff by whichever instance finds them due, so they survive restarts.
+type WebhookService struct {
+	repo         webhookDomain.Repository
+	tracking     *TrackingService
+	participants participantDomain.Repository
+	client       *http.Client
+	config       WebhookConfig
+	clock        clock.Clock
+	logger       *zap.Logger
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// NewWebhookService creates a new WebhookService. tracking authorizes booking webhooks
+// and participants resolves the owner whose account webhooks a booking's events go to.
+func NewWebhookService(
+	repo webhookDomain.Repository,
+	tracking *TrackingService,
+	participants participantDomain.Repository,
+	config WebhookConfig,
+	logger *zap.Logger,
+) *WebhookService {
+	return &WebhookService{
+		repo:         repo,
+		tracking:     tracking,
+		participants: participants,
+		client:       newWebhookClient(config.Timeout),
+		config:       config,
+		clock:        clock.System,
+		logger:       logger.With(zap.String("component", "webhooks")),
+	}
+}
+
+// newWebhookClient returns a client that only connects to public addresses, so partner
+// URLs cannot reach the service's own network.
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: timeout},
+	}
+}
+
+// Register registers a webhook for userID, returning it with its signing secret.
+func (s *WebhookService) Register(ctx context.Context, userID uuid.UUID, role auth.UserRole, req RegisterWebhookRequest) (*WebhookDTO, error) {
+	accountID := userID
+	if req.AccountID != nil && *req.AccountID != userID {
+		if role != auth.RoleAdmin {
+			return nil, apperror.New(apperror.CodeForbidden, "only admins may register webhooks for another account")
+		}
+		accountID = *req.AccountID
+	}
+	if req.BookingID != nil {
+		if err := s.tracking.AuthorizeBooking(ctx, *req.BookingID, userID, role); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := s.repo.FindByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxWebhooksPerAccount {
+		return nil, apperror.New(apperror.CodeValidation, "an account may register at most %d webhooks", maxWebhooksPerAccount)
+	}
+
+	events := make([]webhookDomain.Event, len(req.Events))
+	for i, e := range req.Events {
+		events[i] = webhookDomain.Event(e)
+	}
+	w, err := webhookDomain.New(accountID, req.BookingID, req.URL, events, s.clock.Now().UTC())
+	if err != nil {
+		return nil, apperror.New(apperror.CodeValidation, "%s", err.Error())
+	}
+	if err := s.repo.Save(ctx, w); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("webhook registered",
+		zap.String("webhook_id", w.ID.String()),
+		zap.String("account_id", accountID.String()),
+		zap.String("registered_by", userID.String()),
+	)
+	dto := toWebhookDTO(w)
+	dto.Secret = w.Secret
+	return dto, nil
+}
+
+// List returns the webhooks an account owns.
+func (s *WebhookService) List(ctx context.Context, accountID uuid.UUID) ([]*WebhookDTO, error) {
+	webhooks, err := s.repo.FindByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]*WebhookDTO, 0, len(webhooks))
+	for _, w := range webhooks {
+		dtos = append(dtos, toWebhookDTO(w))
+	}
+	return dtos, nil
+}
+
+// Delete removes a webhook and its deliveries.
+func (s *WebhookService) Delete(ctx context.Context, id, userID uuid.UUID, role auth.UserRole) error {
+	if _, err := s.owned(ctx, id, userID, role); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return apperror.New(apperror.CodeWebhookNotFound, "webhook %s not found", id)
+		}
+		return err
+	}
+	return nil
+}
+
+// ListDeliveries returns a webhook's latest deliveries, newest first.
+func (s *WebhookService) ListDeliveries(ctx context.Context, id, userID uuid.UUID, role auth.UserRole) ([]*WebhookDeliveryDTO, error) {
+	if _, err := s.owned(ctx, id, userID, role); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.repo.FindDeliveries(ctx, id, webhookDeliveriesListed)
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]*WebhookDeliveryDTO, 0, len(deliveries))
+	for _, d := range deliveries {
+		dtos = append(dtos, toWebhookDeliveryDTO(d))
+	}
+	return dtos, nil
+}
+
+// owned returns a webhook of userID's account, or any webhook for admins. Other
+// accounts' webhooks are reported as not found.
+func (s *WebhookService) owned(ctx context.Context, id, userID uuid.UUID, role auth.UserRole) (*webhookDomain.Webhook, error) {
+	w, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, apperror.New(apperror.CodeWebhookNotFound, "webhook %s not found", id)
+		}
+		return nil, err
+	}
+	if w.AccountID != userID && role != auth.RoleAdmin {
+		return nil, apperror.New(apperror.CodeWebhookNotFound, "webhook %s not found", id)
+	}
+	return w, nil
+}
+
+// Notify delivers a tracking milestone of track to the webhooks of its booking and of
+// the booking owner's account that receive event. stop is the geofence entered for
+// tracking.arrived.
+func (s *WebhookService) Notify(ctx context.Context, event webhookDomain.Event, track *trackingDomain.TripTrack, stop string) {
+	ownerID := uuid.Nil
+	p, err := s.participants.FindByBookingID(ctx, track.BookingID())
+	switch {
+	case err == nil:
+		ownerID = p.OwnerID
+	case !errors.Is(err, domain.ErrNotFound):
+		s.logger.Warn("failed to load booking owner for webhooks", zap.String("booking_id", track.BookingID().String()), zap.Error(err))
+	}
+
+	webhooks, err := s.repo.FindForBooking(ctx, track.BookingID(), ownerID)
+	if err != nil {
+		s.logger.Error("failed to load booking webhooks", zap.String("booking_id", track.BookingID().String()), zap.Error(err))
+		return
+	}
+
+	now := s.clock.Now().UTC()
+	for _, w := range webhooks {
+		if !w.Subscribes(event) {
+			continue
+		}
+		payload := WebhookPayload{
+			ID:         uuid.New(),
+			Type:       string(event),
+			BookingID:  track.BookingID(),
+			TrackID:    track.ID(),
+			Stop:       stop,
+			OccurredAt: now,
+		}
+		if event == webhookDomain.EventCompleted {
+			distance := track.TotalDistanceKm()
+			payload.TotalDistanceKm = &distance
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			s.logger.Error("failed to encode webhook payload", zap.Error(err))
+			return
+		}
+
+		// The delivery is stored already claimed, so retries do not pick it up while
+		// the first attempt is in flight.
+		d := &webhookDomain.Delivery{
+			ID:            payload.ID,
+			WebhookID:     w.ID,
+			BookingID:     track.BookingID(),
+			Event:         event,
+			Payload:       body,
+			Status:        webhookDomain.DeliveryPending,
+			NextAttemptAt: now.Add(s.claim()),
+			CreatedAt:     now,
+		}
+		if err := s.repo.SaveDelivery(ctx, d); err != nil {
+			s.logger.Error("failed to save webhook delivery", zap.String("webhook_id", w.ID.String()), zap.Error(err))
+			continue
+		}
+		go s.deliver(w, d)
+	}
+}
+
+// Run retries the deliveries that are due every CheckInterval and removes finished
+// deliveries past retention, until ctx is cancelled.
+func (s *WebhookService) Run(ctx context.Context) {
+	if s.config.CheckInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.retryDue(ctx)
+			s.prune(ctx)
+		}
+	}
+}
+
+// retryDue claims the deliveries due for a retry and attempts them.
+func (s *WebhookService) retryDue(ctx context.Context) {
+	now := s.clock.Now().UTC()
+	due, err := s.repo.FindDueDeliveries(ctx, now, webhookRetryBatch)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("failed to find due webhook deliveries", zap.Error(err))
+		}
+		return
+	}
+
+	for _, d := range due {
+		claimed, err := s.repo.ClaimDelivery(ctx, d.ID, d.Attempts, now, now.Add(s.claim()))
+		if err != nil {
+			s.logger.Error("failed to claim webhook delivery", zap.String("delivery_id", d.ID.String()), zap.Error(err))
+			continue
+		}
+		if !claimed {
+			// Another instance is attempting it.
+			continue
+		}
+		w, err := s.repo.FindByID(ctx, d.WebhookID)
+		if err != nil {
+			// A webhook deleted meanwhile took its deliveries with it.
+			if !errors.Is(err, domain.ErrNotFound) {
+				s.logger.Error("failed to load webhook", zap.String("webhook_id", d.WebhookID.String()), zap.Error(err))
+			}
+			continue
+		}
+		d.NextAttemptAt = now.Add(s.claim())
+		go s.deliver(w, d)
+	}
+}
+
+// prune removes the finished deliveries past retention, at most every
+// webhookPruneInterval.
+func (s *WebhookService) prune(ctx context.Context) {
+	now := s.clock.Now().UTC()
+	s.mu.Lock()
+	if now.Sub(s.lastPrune) < webhookPruneInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPrune = now
+	s.mu.Unlock()
+
+	removed, err := s.repo.DeleteFinishedDeliveries(ctx, now.Add(-webhookDeliveryRetention))
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("failed to prune webhook deliveries", zap.Error(err))
+		}
+		return
+	}
+	if removed > 0 {
+		s.logger.Info("pruned webhook deliveries", zap.Int64("removed", removed))
+	}
+}
+
+// deliver makes one attempt of a claimed delivery and records its outcome: delivered,
+// due for a retry after the backoff, or failed after the last attempt.
+func (s *WebhookService) deliver(w *webhookDomain.Webhook, d *webhookDomain.Delivery) {
+	status, err := s.post(w, d)
+
+	now := s.clock.Now().UTC()
+	d.Attempts++
+	d.LastStatusCode = status
+	switch {
+	case err == nil:
+		d.Status = webhookDomain.DeliveryDelivered
+		d.LastError = ""
+		d.DeliveredAt = &now
+	case d.Attempts >= s.config.MaxAttempts:
+		d.Status = webhookDomain.DeliveryFailed
+		d.LastError = err.Error()
+	default:
+		d.LastError = err.Error()
+		d.NextAttemptAt = now.Add(s.backoff(d.Attempts))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+	if err := s.repo.UpdateDelivery(ctx, d); err != nil {
+		s.logger.Error("failed to record webhook delivery", zap.String("delivery_id", d.ID.String()), zap.Error(err))
+	}
+	if err != nil {
+		s.logger.Warn("webhook delivery failed",
+			zap.String("webhook_id", w.ID.String()),
+			zap.String("delivery_id", d.ID.String()),
+			zap.String("event", string(d.Event)),
+			zap.Int("attempt", d.Attempts),
+			zap.String("status", string(d.Status)),
+			zap.Error(err),
+		)
+	}
+}
+
+// post sends a delivery's payload signed with the webhook's secret, returning the
+// response status. Any status outside 2xx is an error.
+func (s *WebhookService) post(w *webhookDomain.Webhook, d *webhookDomain.Delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(s.clock.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerWebhookSignature, "t="+timestamp+",v1="+signWebhook(w.Secret, timestamp, d.Payload))
+	req.Header.Set(headerWebhookEvent, string(d.Event))
+	req.Header.Set(headerWebhookDelivery, d.ID.String())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay after a delivery's attempts-th failed attempt.
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	d := s.config.Backoff
+	for i := 1; i < attempts && d < maxWebhookBackoff; i++ {
+		d *= 2
+	}
+	if d > maxWebhookBackoff {
+		d = maxWebhookBackoff
+	}
+	return d
+}
+
+// claim is how long a delivery is held while it is attempted.
+func (s *WebhookService) claim() time.Duration {
+	return s.config.Timeout + webhookClaimMargin
+}
+
+// signWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>" under secret.
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func toWebhookDTO(w *webhookDomain.Webhook) *WebhookDTO {
+	events := make([]string, len(w.Events))
+	for i, e := range w.Events {
+		events[i] = string(e)
+	}
+	return &WebhookDTO{
+		ID:        w.ID,
+		AccountID: w.AccountID,
+		BookingID: w.BookingID,
+		URL:       w.URL,
+		Events:    events,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+func toWebhookDeliveryDTO(d *webhookDomain.Delivery) *WebhookDeliveryDTO {
+	dto := &WebhookDeliveryDTO{
+		ID:             d.ID,
+		Event:          string(d.Event),
+		BookingID:      d.BookingID,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+	if d.Status == webhookDomain.DeliveryPending {
+		next := d.NextAttemptAt
+		dto.NextAttemptAt = &next
+	}
+	return dto
+}
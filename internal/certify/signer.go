@@ -0,0 +1,103 @@
+// Package certify signs trip certificates with the service's Ed25519 key, so billing
+// and partner systems can check that certified trip data was not altered.
+package certify
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Algorithm is the signature algorithm of certificates.
+const Algorithm = "Ed25519"
+
+// ErrUnknownKey is returned when a signature names a key the signer does not know.
+var ErrUnknownKey = errors.New("unknown certificate key")
+
+// PublicKey is a verification key published for offline verification.
+type PublicKey struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	// Key is the base64-encoded Ed25519 public key.
+	Key string `json:"key"`
+	// Active is true for the key new certificates are signed with.
+	Active bool `json:"active"`
+}
+
+// Signer signs payloads with one active key and verifies them with it or with retired
+// keys kept for certificates signed before a rotation.
+type Signer struct {
+	keyID      string
+	privateKey ed25519.PrivateKey
+	publicKeys map[string]ed25519.PublicKey
+}
+
+// NewSigner creates a Signer from a base64-encoded 32-byte Ed25519 seed. retired maps
+// the IDs of previous keys to their base64-encoded public keys.
+func NewSigner(keyID, seed string, retired map[string]string) (*Signer, error) {
+	raw, err := base64.StdEncoding.DecodeString(seed)
+	if err != nil || len(raw) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key must be a base64-encoded %d-byte Ed25519 seed", ed25519.SeedSize)
+	}
+	privateKey := ed25519.NewKeyFromSeed(raw)
+
+	s := &Signer{
+		keyID:      keyID,
+		privateKey: privateKey,
+		publicKeys: map[string]ed25519.PublicKey{keyID: privateKey.Public().(ed25519.PublicKey)},
+	}
+	for id, encoded := range retired {
+		if id == keyID {
+			return nil, fmt.Errorf("retired key %q has the ID of the active key", id)
+		}
+		pub, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(pub) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("retired key %q must be a base64-encoded %d-byte Ed25519 public key", id, ed25519.PublicKeySize)
+		}
+		s.publicKeys[id] = ed25519.PublicKey(pub)
+	}
+	return s, nil
+}
+
+// KeyID returns the ID of the active key.
+func (s *Signer) KeyID() string { return s.keyID }
+
+// Sign signs payload with the active key and returns the base64-encoded signature.
+func (s *Signer) Sign(payload []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.privateKey, payload))
+}
+
+// Verify reports whether signature is a valid signature of payload by key keyID.
+func (s *Signer) Verify(keyID string, payload []byte, signature string) (bool, error) {
+	pub, ok := s.publicKeys[keyID]
+	if !ok {
+		return false, ErrUnknownKey
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, nil
+	}
+	return ed25519.Verify(pub, payload, sig), nil
+}
+
+// PublicKeys returns the verification keys, active key first.
+func (s *Signer) PublicKeys() []PublicKey {
+	keys := make([]PublicKey, 0, len(s.publicKeys))
+	for id, pub := range s.publicKeys {
+		keys = append(keys, PublicKey{
+			KeyID:     id,
+			Algorithm: Algorithm,
+			Key:       base64.StdEncoding.EncodeToString(pub),
+			Active:    id == s.keyID,
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Active != keys[j].Active {
+			return keys[i].Active
+		}
+		return keys[i].KeyID < keys[j].KeyID
+	})
+	return keys
+}
@@ -0,0 +1,87 @@
+// Package clock provides the time and ID sources that domain objects and services are
+// built with. Production code uses System and RandomIDs; tests can substitute Manual and
+// Sequential to make time-dependent logic deterministic.
+package clock
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Clock tells the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// IDGenerator generates unique IDs for new entities.
+type IDGenerator interface {
+	NewID() uuid.UUID
+}
+
+// System is the wall clock.
+var System Clock = systemClock{}
+
+// RandomIDs generates random (version 4) UUIDs.
+var RandomIDs IDGenerator = randomIDs{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+type randomIDs struct{}
+
+func (randomIDs) NewID() uuid.UUID { return uuid.New() }
+
+// Manual is a Clock that only moves when it is set or advanced.
+type Manual struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManual creates a Manual clock reading t.
+func NewManual(t time.Time) *Manual {
+	return &Manual{now: t}
+}
+
+// Now returns the clock's current time.
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// Set moves the clock to t.
+func (m *Manual) Set(t time.Time) {
+	m.mu.Lock()
+	m.now = t
+	m.mu.Unlock()
+}
+
+// Advance moves the clock forward by d.
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	m.now = m.now.Add(d)
+	m.mu.Unlock()
+}
+
+// Sequential generates predictable IDs counting up from
+// 00000000-0000-0000-0000-000000000001.
+type Sequential struct {
+	mu sync.Mutex
+	n  uint64
+}
+
+// NewID returns the next ID in the sequence.
+func (s *Sequential) NewID() uuid.UUID {
+	s.mu.Lock()
+	s.n++
+	n := s.n
+	s.mu.Unlock()
+
+	var id uuid.UUID
+	binary.BigEndian.PutUint64(id[8:], n)
+	return id
+}
@@ -1,16 +1,426 @@
 package config
 
 import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
 	"github.com/Kilat-Pet-Delivery/lib-common/config"
 )
 
 // ServiceConfig holds all configuration for the tracking service.
 type ServiceConfig struct {
-	Port        string
-	AppEnv      string
-	DBConfig    config.DatabaseConfig
-	JWTConfig   config.JWTConfig
-	KafkaConfig config.KafkaConfig
+	Port         string
+	GRPCPort     string
+	AppEnv       string
+	LogLevel     zapcore.Level
+	DBConfig     config.DatabaseConfig
+	JWTConfig    config.JWTConfig
+	KafkaConfig  config.KafkaConfig
+	WidgetConfig WidgetConfig
+
+	// KafkaRegions lists the regions whose suffixed topics are consumed (e.g. id-jkt).
+	// Empty means the global, unsuffixed topics.
+	KafkaRegions []string
+	// KafkaRunnerWorkers is how many runner events each runner consumer processes at once.
+	KafkaRunnerWorkers int
+
+	// DeadLetterMaxAttempts is how many times a consumed message is processed before it
+	// is shipped to the dead-letter topic.
+	DeadLetterMaxAttempts int
+
+	// ETAUpdateThreshold is the minimum ETA change that triggers an eta_update WS frame.
+	ETAUpdateThreshold time.Duration
+	// ETACallbackHosts are the hosts ETA subscribers may register callbacks on; a
+	// leading "*." matches any subdomain. Empty disables callbacks.
+	ETACallbackHosts []string
+
+	// LocationPingTimeout is how long a location ping waits for a fresh fix. It must stay
+	// below the HTTP write timeout.
+	LocationPingTimeout time.Duration
+
+	// MaxWaypointsPerTrack caps the waypoints stored for one trip; beyond it, older
+	// waypoints are downsampled.
+	MaxWaypointsPerTrack int
+
+	// AllowedOrigins are the browser origins that may open WebSockets, call the share
+	// and widget endpoints and frame tracking embeds: "*", origins, or origins with a
+	// wildcard subdomain such as https://*.kilat.id. Empty allows only the service's own
+	// host.
+	AllowedOrigins []string
+
+	// WSQueryTokens keeps accepting access tokens in the WebSocket token query parameter.
+	// Deprecated: tokens in URLs leak into logs; clients should send the Authorization
+	// header or a bearer subprotocol.
+	WSQueryTokens bool
+
+	OverloadConfig OverloadConfig
+	GroupMigration GroupMigrationConfig
+	WaypointBatch  WaypointBatchConfig
+	ChatPolicy     ChatPolicyConfig
+	Attachments    AttachmentStorageConfig
+	Redis          RedisConfig
+	Probe          ProbeConfig
+
+	StorageMigration StorageMigrationConfig
+	Enrichment       EnrichmentConfig
+	Routing          RoutingConfig
+	DrivingLimits    DrivingLimitsConfig
+	RunnerDigest     RunnerDigestConfig
+	EventDedup       EventDedupConfig
+	Coordinates      CoordinateConfig
+	Inbox            InboxConfig
+	Anomaly          AnomalyConfig
+	Safety           SafetyConfig
+	Telemetry        TelemetryConfig
+	SLO              SLOConfig
+	Support          SupportConfig
+	Push             PushConfig
+	Certificate      CertificateConfig
+	Autoscaling      AutoscalingConfig
+	WebSocket        WebSocketConfig
+	RateLimit        RateLimitConfig
+	CatchUp          CatchUpConfig
+	GPSFilter        GPSFilterConfig
+	Device           DeviceConfig
+	Stall            StallConfig
+	Erasure          ErasureConfig
+	LiveOps          LiveOpsConfig
+	Webhooks         WebhooksConfig
+	Standalone       StandaloneConfig
+}
+
+// AutoscalingConfig controls the signals and shutdown behavior that let the fleet be
+// scaled safely.
+type AutoscalingConfig struct {
+	// ConsumerLagInterval is how often consumer group lag is measured.
+	ConsumerLagInterval time.Duration
+	// WSDrainPeriod is how long the pre-stop hook spreads WebSocket closes over. It must
+	// stay a few seconds below the HTTP write timeout.
+	WSDrainPeriod time.Duration
+	// WSHubShards is how many event loops the WebSocket hub spreads rooms over; zero
+	// means one per CPU.
+	WSHubShards int
+}
+
+// CatchUpConfig controls how runner locations are broadcast while the consumers work
+// through a backlog.
+type CatchUpConfig struct {
+	// LagThreshold is the consumer group lag, in messages, at which catching up starts.
+	LagThreshold int
+	// StaleAfter is how old a location consumed while catching up must be to be
+	// broadcast as historical.
+	StaleAfter time.Duration
+}
+
+// GPSFilterConfig controls the Kalman filter that smooths live locations before they
+// are broadcast and added to a trip's distance.
+type GPSFilterConfig struct {
+	Enabled bool
+	// AccuracyMeters is the typical error of a GPS fix.
+	AccuracyMeters float64
+	// ProcessNoise is how far, in meters per second, runners typically stray from their
+	// reported speed and heading.
+	ProcessNoise float64
+}
+
+// DeviceConfig controls the handling of the phone status runners report with locations.
+type DeviceConfig struct {
+	// LowBatteryPct is the battery level below which customers are warned.
+	LowBatteryPct int
+}
+
+// StallConfig controls the detection of active trips whose runner has stopped moving.
+type StallConfig struct {
+	// After is how long a runner may stay within RadiusMeters of one place before the
+	// trip is reported stalled.
+	After        time.Duration
+	RadiusMeters float64
+	// CheckInterval is how often active trips are checked.
+	CheckInterval time.Duration
+}
+
+// ErasureConfig controls the erasure of users' personal data on request.
+type ErasureConfig struct {
+	// Retention is how long after a trip ends its data is kept before it may be erased.
+	Retention time.Duration
+	// CheckInterval is how often pending erasure requests are retried.
+	CheckInterval time.Duration
+}
+
+// LiveOpsConfig controls the live ops dashboard stream.
+type LiveOpsConfig struct {
+	// Interval is how often the fleet state is refreshed while a dashboard is connected.
+	Interval time.Duration
+}
+
+// WebhooksConfig controls the delivery of partners' tracking webhooks.
+type WebhooksConfig struct {
+	// Timeout bounds each delivery attempt.
+	Timeout time.Duration
+	// MaxAttempts is how many times a delivery is attempted before it is given up on.
+	MaxAttempts int
+	// Backoff is the delay before the second attempt; it doubles per attempt.
+	Backoff time.Duration
+	// CheckInterval is how often deliveries due for a retry are looked for.
+	CheckInterval time.Duration
+}
+
+// RateLimitConfig holds the token buckets limiting each user's location batches and
+// chat messages: a burst, refilled at a rate per second. Buckets are shared through
+// Redis when it is configured.
+type RateLimitConfig struct {
+	LocationsPerSecond float64
+	LocationsBurst     int
+	ChatPerSecond      float64
+	ChatBurst          int
+}
+
+// WebSocketConfig holds the hub's connection limits, how it treats clients that do not
+// keep up with their frames and how much history reconnecting clients can resume from.
+type WebSocketConfig struct {
+	// MaxConnections, MaxConnectionsPerRoom and MaxConnectionsPerUser cap the connections
+	// of the instance, of one booking and of one user.
+	MaxConnections        int
+	MaxConnectionsPerRoom int
+	MaxConnectionsPerUser int
+
+	// CoalesceLocations keeps only the latest location frame for a backed-up client.
+	CoalesceLocations bool
+	// LaggingGrace is how long a warned client may keep dropping frames before it is
+	// disconnected.
+	LaggingGrace time.Duration
+	// MaxDroppedFrames disconnects a lagging client after this many dropped frames.
+	MaxDroppedFrames int
+
+	// ResumeBuffer is how many recent frames each room keeps for reconnecting clients,
+	// and ResumeRetention how long they are kept after the room's last client left.
+	ResumeBuffer    int
+	ResumeRetention time.Duration
+}
+
+// StandaloneConfig controls standalone mode, which runs the service as a single binary
+// for local development: Kafka is replaced by an in-process event bus, Postgres by
+// in-memory repositories, and requests without a token act as a dev identity.
+type StandaloneConfig struct {
+	Enabled bool
+	// DevUserID and DevRole are the identity of requests that carry no token.
+	DevUserID string
+	DevRole   string
+}
+
+// CertificateConfig holds the keys trip certificates are signed with. Certificates are
+// only issued when SigningKey is set.
+type CertificateConfig struct {
+	// SigningKey is the base64-encoded 32-byte Ed25519 seed of the active key.
+	SigningKey string
+	KeyID      string
+	// RetiredKeys maps previous key IDs to their base64-encoded public keys, so
+	// certificates signed before a rotation still verify.
+	RetiredKeys map[string]string
+}
+
+// PushConfig controls push notification requests for participants who are offline.
+type PushConfig struct {
+	Enabled bool
+	// Topic is the Kafka topic NotificationRequested events are published to.
+	Topic string
+}
+
+// SupportConfig controls read-only support sessions.
+type SupportConfig struct {
+	// SessionTTL is how long a support session stays open.
+	SessionTTL time.Duration
+}
+
+// SLOConfig holds the endpoint SLO classes.
+type SLOConfig struct {
+	// Classes maps a class name to its "latency:objective", e.g. critical -> 300ms:0.999.
+	Classes map[string]string
+	// Period is the window error budgets are measured over.
+	Period time.Duration
+}
+
+// AnomalyConfig holds the thresholds of trip anomaly detection.
+type AnomalyConfig struct {
+	MaxSpeedKmh        float64
+	StopDuration       time.Duration
+	StopRadiusMeters   float64
+	MaxDeviationMeters float64
+}
+
+// SafetyConfig holds the safety speed limit of trips with a pet on board.
+type SafetyConfig struct {
+	MaxSpeedKmh float64
+	// ConsecutiveWaypoints is how many waypoints in a row must exceed MaxSpeedKmh
+	// before an alert is raised.
+	ConsecutiveWaypoints int
+}
+
+// TelemetryConfig holds the safe relative humidity range inside pet carriers, in percent.
+type TelemetryConfig struct {
+	MinHumidityPct float64
+	MaxHumidityPct float64
+}
+
+// InboxConfig controls the per-user inbox of undelivered messages.
+type InboxConfig struct {
+	// Retention is how long messages wait in an inbox before they are dropped unsynced.
+	Retention time.Duration
+}
+
+// CoordinateConfig holds the bounding boxes location fixes are validated against, each
+// formatted as minLat,minLng,maxLat,maxLng.
+type CoordinateConfig struct {
+	// RegionBounds maps a region (e.g. id-jkt) to the box its trips must stay within.
+	RegionBounds map[string]string
+	// DefaultBounds applies to trips in other regions; empty disables the check for them.
+	DefaultBounds string
+	// MaxAccuracyMeters rejects fixes reporting a worse horizontal accuracy.
+	MaxAccuracyMeters float64
+}
+
+// EventDedupConfig controls the detection of Kafka events delivered more than once.
+type EventDedupConfig struct {
+	// Retention is how long processed event IDs are kept in the database.
+	Retention time.Duration
+	// CacheTTL is how long processed event IDs are also cached in memory.
+	CacheTTL time.Duration
+}
+
+// RunnerDigestConfig controls the end-of-day runner digest job.
+type RunnerDigestConfig struct {
+	// Timezone is the IANA zone days are counted in.
+	Timezone string
+	// RunAfter is how long after midnight the previous day's digests are published.
+	RunAfter time.Duration
+}
+
+// DrivingLimitsConfig holds the runner break compliance limits.
+type DrivingLimitsConfig struct {
+	MaxContinuous time.Duration
+	MaxDaily      time.Duration
+	MinBreak      time.Duration
+	CheckInterval time.Duration
+}
+
+// EnrichmentConfig holds the connection settings for the enrichment service.
+type EnrichmentConfig struct {
+	// URL is the service's base URL; empty disables trip weather capture.
+	URL     string
+	Timeout time.Duration
+}
+
+// RoutingConfig selects the routing provider for road-based arrival estimates.
+type RoutingConfig struct {
+	// Provider is osrm or google; empty keeps straight-line estimates.
+	Provider string
+	// URL is the OSRM server's base URL.
+	URL string
+	// APIKey authenticates with the Google Directions API.
+	APIKey string
+	// Profile is the OSRM profile or Google travel mode, e.g. driving.
+	Profile          string
+	Timeout          time.Duration
+	CacheTTL         time.Duration
+	FailureThreshold int
+	Cooldown         time.Duration
+	// MapMatching lets route exports be snapped to roads with ?matched=true.
+	MapMatching bool
+}
+
+// StorageMigrationConfig controls migrating trip track storage to a new database.
+type StorageMigrationConfig struct {
+	// Mode is off, dual_write, shadow_read or cutover.
+	Mode string
+	// DB is the new backend. Unset fields fall back to the current database's settings.
+	DB config.DatabaseConfig
+}
+
+// ProbeConfig controls the synthetic monitoring probe.
+type ProbeConfig struct {
+	// Interval is the time between probe trips; zero disables the probe.
+	Interval time.Duration
+	Timeout  time.Duration
+	// RunnerID is the reserved runner probe trips are assigned to.
+	RunnerID string
+}
+
+// RedisConfig holds the connection settings for the latest-position cache.
+type RedisConfig struct {
+	// Addr is host:port; empty disables the cache.
+	Addr        string
+	Password    string
+	DB          int
+	PositionTTL time.Duration
+	// HubRelay relays WebSocket room frames between instances over pub/sub on HubChannel.
+	HubRelay   bool
+	HubChannel string
+}
+
+// ChatPolicyConfig holds the limits enforced on chat messages.
+type ChatPolicyConfig struct {
+	MaxContentLength     int
+	MaxAttachments       int
+	MaxAttachmentBytes   int
+	AllowedMimeTypes     []string
+	MaxMessagesPerMinute int
+	// MaskPII masks phone numbers and email addresses in message content.
+	MaskPII bool
+	// ProfanityWords maps a locale to the words filtered from its messages.
+	ProfanityWords map[string][]string
+	// DefaultLocale's word list applies to messages in locales without their own.
+	DefaultLocale string
+	// SystemMessages posts delivery milestones into each booking's chat.
+	SystemMessages bool
+}
+
+// AttachmentStorageConfig locates the S3-compatible bucket chat attachments are uploaded
+// to. Presigned uploads are only offered when Bucket is set.
+type AttachmentStorageConfig struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle addresses the bucket as a path, as MinIO expects.
+	PathStyle bool
+	// UploadURLTTL and DownloadURLTTL are how long presigned URLs stay valid.
+	UploadURLTTL   time.Duration
+	DownloadURLTTL time.Duration
+}
+
+// WaypointBatchConfig controls buffered waypoint writes.
+type WaypointBatchConfig struct {
+	// Size is the number of waypoints per multi-row insert; 1 or less writes each waypoint directly.
+	Size int
+	// FlushInterval is the maximum time a waypoint stays buffered.
+	FlushInterval time.Duration
+}
+
+// GroupMigrationConfig enables the blue/green consumer group migration startup mode.
+type GroupMigrationConfig struct {
+	// TargetGroupPrefix is the new group prefix to migrate to; empty disables migration.
+	TargetGroupPrefix string
+	// StartAt is the point in time the new groups start consuming from.
+	StartAt time.Time
+	// Drain is how long the current groups keep consuming alongside the new ones.
+	Drain time.Duration
+}
+
+// OverloadConfig holds the thresholds for load-shedding mode.
+type OverloadConfig struct {
+	QueueDepthThreshold int
+	DBLatencyThreshold  time.Duration
+	RetryAfter          time.Duration
+}
+
+// WidgetConfig holds settings for embeddable tracking widget tokens.
+type WidgetConfig struct {
+	Secret   string
+	TokenTTL time.Duration
 }
 
 // Load reads configuration from environment variables and returns ServiceConfig.
@@ -20,11 +430,320 @@ func Load() (*ServiceConfig, error) {
 		return nil, err
 	}
 
+	jwtConfig := config.LoadJWTConfig(v)
+	standalone := v.GetBool("STANDALONE")
+	if standalone && jwtConfig.Secret == "" {
+		jwtConfig.Secret = "standalone-dev-secret"
+	}
+	dbConfig := config.LoadDatabaseConfig(v, "DB_NAME")
+
+	allowedMimeTypes := splitList(v.GetString("CHAT_ALLOWED_MIME_TYPES"))
+	if len(allowedMimeTypes) == 0 {
+		allowedMimeTypes = []string{"image/jpeg", "image/png", "image/webp"}
+	}
+
+	profanityWords := make(map[string][]string)
+	for locale, words := range splitPairs(v.GetString("CHAT_PROFANITY_WORDS")) {
+		profanityWords[locale] = splitList(words)
+	}
+
+	// Widget tokens are embedded in partner pages, so they are signed with their own
+	// secret; only standalone mode falls back to the JWT secret.
+	widgetSecret := v.GetString("WIDGET_TOKEN_SECRET")
+	if widgetSecret == "" && standalone {
+		widgetSecret = jwtConfig.Secret
+	}
+	if !standalone && (widgetSecret == "" || widgetSecret == jwtConfig.Secret) {
+		return nil, errors.New("WIDGET_TOKEN_SECRET must be set and differ from the JWT secret")
+	}
+
 	return &ServiceConfig{
-		Port:        config.GetServicePort(v, "SERVICE_PORT"),
-		AppEnv:      config.GetAppEnv(v),
-		DBConfig:    config.LoadDatabaseConfig(v, "DB_NAME"),
-		JWTConfig:   config.LoadJWTConfig(v),
-		KafkaConfig: config.LoadKafkaConfig(v),
+		Port:               config.GetServicePort(v, "SERVICE_PORT"),
+		GRPCPort:           listenAddr(v.GetString("GRPC_PORT"), ":9005"),
+		AppEnv:             config.GetAppEnv(v),
+		LogLevel:           levelOrDefault(v.GetString("LOG_LEVEL"), zapcore.InfoLevel),
+		DBConfig:           dbConfig,
+		JWTConfig:          jwtConfig,
+		KafkaConfig:        config.LoadKafkaConfig(v),
+		KafkaRegions:       splitList(v.GetString("KAFKA_REGIONS")),
+		KafkaRunnerWorkers: intOrDefault(v.GetInt("KAFKA_RUNNER_WORKERS"), 8),
+		WidgetConfig: WidgetConfig{
+			Secret:   widgetSecret,
+			TokenTTL: durationOrDefault(v.GetString("WIDGET_TOKEN_TTL"), 30*time.Minute),
+		},
+		ETAUpdateThreshold:    durationOrDefault(v.GetString("ETA_UPDATE_THRESHOLD"), time.Minute),
+		ETACallbackHosts:      splitList(v.GetString("ETA_CALLBACK_HOSTS")),
+		DeadLetterMaxAttempts: intOrDefault(v.GetInt("KAFKA_DLQ_MAX_ATTEMPTS"), 3),
+		LocationPingTimeout:   durationOrDefault(v.GetString("LOCATION_PING_TIMEOUT"), 10*time.Second),
+		MaxWaypointsPerTrack:  intOrDefault(v.GetInt("TRACKING_MAX_WAYPOINTS"), 20000),
+		AllowedOrigins:        splitList(v.GetString("ALLOWED_ORIGINS")),
+		WSQueryTokens:         v.GetString("WS_QUERY_TOKENS") != "false",
+		OverloadConfig: OverloadConfig{
+			QueueDepthThreshold: intOrDefault(v.GetInt("OVERLOAD_QUEUE_DEPTH"), 200),
+			DBLatencyThreshold:  durationOrDefault(v.GetString("OVERLOAD_DB_LATENCY"), 500*time.Millisecond),
+			RetryAfter:          durationOrDefault(v.GetString("OVERLOAD_RETRY_AFTER"), 30*time.Second),
+		},
+		GroupMigration: GroupMigrationConfig{
+			TargetGroupPrefix: v.GetString("KAFKA_MIGRATION_GROUP_PREFIX"),
+			StartAt:           timeOrNow(v.GetString("KAFKA_MIGRATION_START_AT")),
+			Drain:             durationOrDefault(v.GetString("KAFKA_MIGRATION_DRAIN"), 15*time.Minute),
+		},
+		WaypointBatch: WaypointBatchConfig{
+			Size:          intOrDefault(v.GetInt("WAYPOINT_BATCH_SIZE"), 200),
+			FlushInterval: durationOrDefault(v.GetString("WAYPOINT_FLUSH_INTERVAL"), 500*time.Millisecond),
+		},
+		ChatPolicy: ChatPolicyConfig{
+			MaxContentLength:     intOrDefault(v.GetInt("CHAT_MAX_CONTENT_LENGTH"), 2000),
+			MaxAttachments:       intOrDefault(v.GetInt("CHAT_MAX_ATTACHMENTS"), 4),
+			MaxAttachmentBytes:   intOrDefault(v.GetInt("CHAT_MAX_ATTACHMENT_BYTES"), 10<<20),
+			AllowedMimeTypes:     allowedMimeTypes,
+			MaxMessagesPerMinute: intOrDefault(v.GetInt("CHAT_MAX_MESSAGES_PER_MINUTE"), 30),
+			MaskPII:              v.GetString("CHAT_MASK_PII") != "false",
+			ProfanityWords:       profanityWords,
+			DefaultLocale:        stringOrDefault(v.GetString("CHAT_DEFAULT_LOCALE"), "id"),
+			SystemMessages:       v.GetString("CHAT_SYSTEM_MESSAGES") != "false",
+		},
+		Attachments: AttachmentStorageConfig{
+			Endpoint:        stringOrDefault(v.GetString("ATTACHMENT_S3_ENDPOINT"), "https://s3.amazonaws.com"),
+			Region:          stringOrDefault(v.GetString("ATTACHMENT_S3_REGION"), "us-east-1"),
+			Bucket:          v.GetString("ATTACHMENT_S3_BUCKET"),
+			AccessKeyID:     v.GetString("ATTACHMENT_S3_ACCESS_KEY_ID"),
+			SecretAccessKey: v.GetString("ATTACHMENT_S3_SECRET_ACCESS_KEY"),
+			PathStyle:       v.GetBool("ATTACHMENT_S3_PATH_STYLE"),
+			UploadURLTTL:    durationOrDefault(v.GetString("ATTACHMENT_UPLOAD_URL_TTL"), 15*time.Minute),
+			DownloadURLTTL:  durationOrDefault(v.GetString("ATTACHMENT_DOWNLOAD_URL_TTL"), time.Hour),
+		},
+		Redis: RedisConfig{
+			Addr:        v.GetString("REDIS_ADDR"),
+			Password:    v.GetString("REDIS_PASSWORD"),
+			DB:          v.GetInt("REDIS_DB"),
+			PositionTTL: durationOrDefault(v.GetString("POSITION_CACHE_TTL"), 24*time.Hour),
+			HubRelay:    v.GetString("REDIS_HUB_RELAY") != "false",
+			HubChannel:  stringOrDefault(v.GetString("REDIS_HUB_CHANNEL"), "tracking:ws:frames"),
+		},
+		Probe: ProbeConfig{
+			Interval: durationOrDefault(v.GetString("PROBE_INTERVAL"), 0),
+			Timeout:  durationOrDefault(v.GetString("PROBE_TIMEOUT"), 30*time.Second),
+			RunnerID: stringOrDefault(v.GetString("PROBE_RUNNER_ID"), "00000000-0000-4000-8000-00000000f00d"),
+		},
+		StorageMigration: StorageMigrationConfig{
+			Mode: stringOrDefault(v.GetString("STORAGE_MIGRATION_MODE"), "off"),
+			DB: config.DatabaseConfig{
+				Host:     stringOrDefault(v.GetString("STORAGE_MIGRATION_DB_HOST"), dbConfig.Host),
+				Port:     stringOrDefault(v.GetString("STORAGE_MIGRATION_DB_PORT"), dbConfig.Port),
+				User:     stringOrDefault(v.GetString("STORAGE_MIGRATION_DB_USER"), dbConfig.User),
+				Password: stringOrDefault(v.GetString("STORAGE_MIGRATION_DB_PASSWORD"), dbConfig.Password),
+				DBName:   stringOrDefault(v.GetString("STORAGE_MIGRATION_DB_NAME"), dbConfig.DBName),
+				SSLMode:  stringOrDefault(v.GetString("STORAGE_MIGRATION_DB_SSLMODE"), dbConfig.SSLMode),
+			},
+		},
+		Enrichment: EnrichmentConfig{
+			URL:     v.GetString("ENRICHMENT_URL"),
+			Timeout: durationOrDefault(v.GetString("ENRICHMENT_TIMEOUT"), 2*time.Second),
+		},
+		Routing: RoutingConfig{
+			Provider:         v.GetString("ROUTING_PROVIDER"),
+			URL:              v.GetString("ROUTING_URL"),
+			APIKey:           v.GetString("ROUTING_API_KEY"),
+			Profile:          stringOrDefault(v.GetString("ROUTING_PROFILE"), "driving"),
+			Timeout:          durationOrDefault(v.GetString("ROUTING_TIMEOUT"), time.Second),
+			CacheTTL:         durationOrDefault(v.GetString("ROUTING_CACHE_TTL"), time.Minute),
+			FailureThreshold: intOrDefault(v.GetInt("ROUTING_BREAKER_FAILURES"), 5),
+			Cooldown:         durationOrDefault(v.GetString("ROUTING_BREAKER_COOLDOWN"), 30*time.Second),
+			MapMatching:      v.GetString("ROUTING_MAP_MATCHING") != "false",
+		},
+		DrivingLimits: DrivingLimitsConfig{
+			MaxContinuous: durationOrDefault(v.GetString("DRIVING_MAX_CONTINUOUS"), 4*time.Hour+30*time.Minute),
+			MaxDaily:      durationOrDefault(v.GetString("DRIVING_MAX_DAILY"), 9*time.Hour),
+			MinBreak:      durationOrDefault(v.GetString("DRIVING_MIN_BREAK"), 45*time.Minute),
+			CheckInterval: durationOrDefault(v.GetString("DRIVING_CHECK_INTERVAL"), 5*time.Minute),
+		},
+		RunnerDigest: RunnerDigestConfig{
+			Timezone: stringOrDefault(v.GetString("RUNNER_DIGEST_TIMEZONE"), "UTC"),
+			RunAfter: durationOrDefault(v.GetString("RUNNER_DIGEST_RUN_AFTER"), 15*time.Minute),
+		},
+		EventDedup: EventDedupConfig{
+			Retention: durationOrDefault(v.GetString("EVENT_DEDUP_RETENTION"), 7*24*time.Hour),
+			CacheTTL:  durationOrDefault(v.GetString("EVENT_DEDUP_CACHE_TTL"), 10*time.Minute),
+		},
+		Coordinates: CoordinateConfig{
+			RegionBounds:      splitPairs(v.GetString("COORDINATE_REGION_BOUNDS")),
+			DefaultBounds:     v.GetString("COORDINATE_DEFAULT_BOUNDS"),
+			MaxAccuracyMeters: floatOrDefault(v.GetFloat64("COORDINATE_MAX_ACCURACY_METERS"), 100),
+		},
+		Inbox: InboxConfig{
+			Retention: durationOrDefault(v.GetString("INBOX_RETENTION"), 30*24*time.Hour),
+		},
+		Certificate: CertificateConfig{
+			SigningKey:  v.GetString("CERTIFICATE_SIGNING_KEY"),
+			KeyID:       stringOrDefault(v.GetString("CERTIFICATE_KEY_ID"), "tracking-1"),
+			RetiredKeys: splitPairs(v.GetString("CERTIFICATE_RETIRED_KEYS")),
+		},
+		Support: SupportConfig{
+			SessionTTL: durationOrDefault(v.GetString("SUPPORT_SESSION_TTL"), time.Hour),
+		},
+		Push: PushConfig{
+			Enabled: v.GetString("PUSH_NOTIFICATIONS_ENABLED") != "false",
+			Topic:   stringOrDefault(v.GetString("PUSH_NOTIFICATION_TOPIC"), "notification.requests"),
+		},
+		SLO: SLOConfig{
+			Classes: splitPairs(stringOrDefault(v.GetString("SLO_CLASSES"), "critical=300ms:0.999;standard=1s:0.995")),
+			Period:  durationOrDefault(v.GetString("SLO_PERIOD"), 30*24*time.Hour),
+		},
+		Autoscaling: AutoscalingConfig{
+			ConsumerLagInterval: durationOrDefault(v.GetString("CONSUMER_LAG_INTERVAL"), 15*time.Second),
+			WSDrainPeriod:       durationOrDefault(v.GetString("WS_DRAIN_PERIOD"), 10*time.Second),
+			WSHubShards:         v.GetInt("WS_HUB_SHARDS"),
+		},
+		CatchUp: CatchUpConfig{
+			LagThreshold: intOrDefault(v.GetInt("KAFKA_CATCHUP_LAG"), 1000),
+			StaleAfter:   durationOrDefault(v.GetString("KAFKA_STALE_LOCATION_AGE"), 30*time.Second),
+		},
+		GPSFilter: GPSFilterConfig{
+			Enabled:        v.GetString("GPS_FILTER_ENABLED") != "false",
+			AccuracyMeters: floatOrDefault(v.GetFloat64("GPS_FILTER_ACCURACY_METERS"), 15),
+			ProcessNoise:   floatOrDefault(v.GetFloat64("GPS_FILTER_PROCESS_NOISE"), 3),
+		},
+		Device: DeviceConfig{
+			LowBatteryPct: intOrDefault(v.GetInt("DEVICE_LOW_BATTERY_PCT"), 15),
+		},
+		Stall: StallConfig{
+			After:         durationOrDefault(v.GetString("STALL_AFTER"), 10*time.Minute),
+			RadiusMeters:  floatOrDefault(v.GetFloat64("STALL_RADIUS_METERS"), 100),
+			CheckInterval: durationOrDefault(v.GetString("STALL_CHECK_INTERVAL"), time.Minute),
+		},
+		Erasure: ErasureConfig{
+			Retention:     durationOrDefault(v.GetString("ERASURE_RETENTION"), 30*24*time.Hour),
+			CheckInterval: durationOrDefault(v.GetString("ERASURE_CHECK_INTERVAL"), time.Hour),
+		},
+		LiveOps: LiveOpsConfig{
+			Interval: durationOrDefault(v.GetString("LIVE_OPS_INTERVAL"), 5*time.Second),
+		},
+		Webhooks: WebhooksConfig{
+			Timeout:       durationOrDefault(v.GetString("WEBHOOK_TIMEOUT"), 10*time.Second),
+			MaxAttempts:   intOrDefault(v.GetInt("WEBHOOK_MAX_ATTEMPTS"), 8),
+			Backoff:       durationOrDefault(v.GetString("WEBHOOK_BACKOFF"), 30*time.Second),
+			CheckInterval: durationOrDefault(v.GetString("WEBHOOK_CHECK_INTERVAL"), 15*time.Second),
+		},
+		RateLimit: RateLimitConfig{
+			LocationsPerSecond: floatOrDefault(v.GetFloat64("RATE_LIMIT_LOCATIONS_PER_SECOND"), 1),
+			LocationsBurst:     intOrDefault(v.GetInt("RATE_LIMIT_LOCATIONS_BURST"), 10),
+			ChatPerSecond:      floatOrDefault(v.GetFloat64("RATE_LIMIT_CHAT_PER_SECOND"), 1),
+			ChatBurst:          intOrDefault(v.GetInt("RATE_LIMIT_CHAT_BURST"), 5),
+		},
+		WebSocket: WebSocketConfig{
+			MaxConnections:        intOrDefault(v.GetInt("WS_MAX_CONNECTIONS"), 10000),
+			MaxConnectionsPerRoom: intOrDefault(v.GetInt("WS_MAX_CONNECTIONS_PER_ROOM"), 200),
+			MaxConnectionsPerUser: intOrDefault(v.GetInt("WS_MAX_CONNECTIONS_PER_USER"), 10),
+			CoalesceLocations:     v.GetString("WS_COALESCE_LOCATIONS") != "false",
+			LaggingGrace:          durationOrDefault(v.GetString("WS_LAGGING_GRACE"), 10*time.Second),
+			MaxDroppedFrames:      intOrDefault(v.GetInt("WS_MAX_DROPPED_FRAMES"), 256),
+			ResumeBuffer:          intOrDefault(v.GetInt("WS_RESUME_BUFFER"), 64),
+			ResumeRetention:       durationOrDefault(v.GetString("WS_RESUME_RETENTION"), 2*time.Minute),
+		},
+		Standalone: StandaloneConfig{
+			Enabled:   standalone,
+			DevUserID: stringOrDefault(v.GetString("DEV_USER_ID"), "00000000-0000-0000-0000-000000000001"),
+			DevRole:   stringOrDefault(v.GetString("DEV_USER_ROLE"), "admin"),
+		},
+		Anomaly: AnomalyConfig{
+			MaxSpeedKmh:        floatOrDefault(v.GetFloat64("ANOMALY_MAX_SPEED_KMH"), 150),
+			StopDuration:       durationOrDefault(v.GetString("ANOMALY_STOP_DURATION"), 10*time.Minute),
+			StopRadiusMeters:   floatOrDefault(v.GetFloat64("ANOMALY_STOP_RADIUS_METERS"), 50),
+			MaxDeviationMeters: floatOrDefault(v.GetFloat64("ANOMALY_MAX_DEVIATION_METERS"), 3000),
+		},
+		Telemetry: TelemetryConfig{
+			MinHumidityPct: floatOrDefault(v.GetFloat64("TELEMETRY_MIN_HUMIDITY_PCT"), 30),
+			MaxHumidityPct: floatOrDefault(v.GetFloat64("TELEMETRY_MAX_HUMIDITY_PCT"), 70),
+		},
+		Safety: SafetyConfig{
+			MaxSpeedKmh:          floatOrDefault(v.GetFloat64("SAFETY_MAX_SPEED_KMH"), 80),
+			ConsecutiveWaypoints: intOrDefault(v.GetInt("SAFETY_CONSECUTIVE_WAYPOINTS"), 3),
+		},
 	}, nil
 }
+
+// durationOrDefault parses a duration string, returning def if it is empty or invalid.
+func durationOrDefault(s string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// stringOrDefault returns s, or def if it is empty.
+func stringOrDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// intOrDefault returns n, or def if n is not positive.
+func intOrDefault(n, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return n
+}
+
+// levelOrDefault parses a log level name, returning def if it is empty or invalid.
+// floatOrDefault returns f, or def if f is not positive.
+func floatOrDefault(f, def float64) float64 {
+	if f <= 0 {
+		return def
+	}
+	return f
+}
+
+func levelOrDefault(s string, def zapcore.Level) zapcore.Level {
+	level, err := zapcore.ParseLevel(s)
+	if err != nil || s == "" {
+		return def
+	}
+	return level
+}
+
+// listenAddr returns port as a listen address (":port"), or def if it is empty.
+func listenAddr(port, def string) string {
+	if port == "" {
+		return def
+	}
+	if !strings.Contains(port, ":") {
+		return ":" + port
+	}
+	return port
+}
+
+// splitList splits a comma-separated list, trimming spaces and dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitPairs parses a semicolon-separated list of key=value pairs, skipping malformed entries.
+func splitPairs(s string) map[string]string {
+	pairs := make(map[string]string)
+	for _, item := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(item, "=")
+		if key, value = strings.TrimSpace(key), strings.TrimSpace(value); ok && key != "" && value != "" {
+			pairs[key] = value
+		}
+	}
+	return pairs
+}
+
+// timeOrNow parses an RFC 3339 timestamp, returning the current time if it is empty or invalid.
+func timeOrNow(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Now().UTC()
+	}
+	return t
+}
@@ -0,0 +1,45 @@
+// Package alert holds the safety alerts raised on trips and shown to the customer,
+// such as a runner driving too fast with a pet on board.
+package alert
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind is the kind of safety alert.
+type Kind string
+
+// Alert kinds.
+const (
+	// KindSpeeding is a runner exceeding the safety speed limit for several
+	// consecutive waypoints.
+	KindSpeeding Kind = "speeding"
+)
+
+// TrackAlert is a safety alert raised on a trip.
+type TrackAlert struct {
+	ID        uuid.UUID
+	TrackID   uuid.UUID
+	BookingID uuid.UUID
+	RunnerID  uuid.UUID
+	Kind      Kind
+	// Latitude and Longitude are where the alert was raised.
+	Latitude  float64
+	Longitude float64
+	// Value is the measured quantity (km/h for speeding) and Threshold the limit it
+	// exceeded. Waypoints is how many consecutive waypoints exceeded it.
+	Value     float64
+	Threshold float64
+	Waypoints int
+	RaisedAt  time.Time
+}
+
+// Repository defines persistence operations for track alerts.
+type Repository interface {
+	Save(ctx context.Context, a *TrackAlert) error
+	// FindByTrackID returns a trip's alerts, oldest first.
+	FindByTrackID(ctx context.Context, trackID uuid.UUID) ([]*TrackAlert, error)
+}
@@ -0,0 +1,48 @@
+// Package anomaly holds suspicious driving patterns detected on trips: GPS teleports,
+// prolonged stops in transit and large deviations from the route.
+package anomaly
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind is the kind of anomaly detected.
+type Kind string
+
+// Anomaly kinds.
+const (
+	// KindTeleport is a jump between consecutive waypoints at an impossible speed.
+	KindTeleport Kind = "teleport"
+	// KindProlongedStop is a runner staying in one place for too long mid-trip.
+	KindProlongedStop Kind = "prolonged_stop"
+	// KindRouteDeviation is a runner straying far from the line between the trip's
+	// origin and destination.
+	KindRouteDeviation Kind = "route_deviation"
+)
+
+// Anomaly is a suspicious driving pattern detected on a trip.
+type Anomaly struct {
+	ID        uuid.UUID
+	TrackID   uuid.UUID
+	BookingID uuid.UUID
+	RunnerID  uuid.UUID
+	Kind      Kind
+	// Latitude and Longitude are where the anomaly was detected.
+	Latitude  float64
+	Longitude float64
+	// Value is the measured quantity: km/h for teleports, seconds for prolonged stops
+	// and meters for route deviations. Threshold is the limit it exceeded.
+	Value      float64
+	Threshold  float64
+	DetectedAt time.Time
+}
+
+// Repository defines persistence operations for anomalies.
+type Repository interface {
+	Save(ctx context.Context, a *Anomaly) error
+	// FindByTrackID returns a trip's anomalies, oldest first.
+	FindByTrackID(ctx context.Context, trackID uuid.UUID) ([]*Anomaly, error)
+}
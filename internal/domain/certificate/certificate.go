@@ -0,0 +1,28 @@
+// Package certificate holds signed summaries of completed trips, used to settle billing
+// disputes and partner settlements.
+package certificate
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Certificate is a completed trip's summary as signed when the trip completed.
+type Certificate struct {
+	TrackID   uuid.UUID
+	BookingID uuid.UUID
+	KeyID     string
+	Algorithm string
+	// Payload is the exact JSON document that was signed.
+	Payload   []byte
+	Signature string
+	IssuedAt  time.Time
+}
+
+// Repository defines persistence operations for certificates.
+type Repository interface {
+	Save(ctx context.Context, c *Certificate) error
+	FindByBookingID(ctx context.Context, bookingID uuid.UUID) (*Certificate, error)
+}
@@ -2,6 +2,7 @@ package chat
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,6 +26,36 @@ func (m MessageType) IsValid() bool {
 	return false
 }
 
+// SenderRoleSystem is the sender role of messages posted by the service itself, such as
+// delivery milestones.
+const SenderRoleSystem = "system"
+
+// Attachment is a media file referenced by a chat message. It is either an external
+// URL or an object uploaded to the service's attachment storage under Key, optionally
+// with a thumbnail under ThumbnailKey.
+type Attachment struct {
+	URL          string `json:"url,omitempty"`
+	Key          string `json:"key,omitempty"`
+	ThumbnailKey string `json:"thumbnail_key,omitempty"`
+	MimeType     string `json:"mime_type"`
+	SizeBytes    int64  `json:"size_bytes"`
+}
+
+// Moderation flag kinds.
+const (
+	FlagPhoneNumber = "phone_number"
+	FlagEmail       = "email"
+	FlagProfanity   = "profanity"
+)
+
+// ModerationFlag records that content moderation changed a message before it was
+// stored: what kind of content was masked and how many times.
+type ModerationFlag struct {
+	Kind   string `json:"kind"`
+	Count  int    `json:"count"`
+	Locale string `json:"locale,omitempty"`
+}
+
 // ChatMessage is the aggregate root for chat messages.
 type ChatMessage struct {
 	id         uuid.UUID
@@ -33,31 +64,51 @@ type ChatMessage struct {
 	senderRole string
 	msgType    MessageType
 	content    string
+	attachments []Attachment
+	moderationFlags []ModerationFlag
 	createdAt  time.Time
 }
 
-// NewChatMessage creates a new chat message.
-func NewChatMessage(bookingID, senderID uuid.UUID, senderRole string, msgType MessageType, content string) (*ChatMessage, error) {
+// NewChatMessage creates a new chat message sent at now. flags records how moderation
+// changed content, if at all.
+func NewChatMessage(id, bookingID, senderID uuid.UUID, senderRole string, msgType MessageType, content string, attachments []Attachment, flags []ModerationFlag, now time.Time) (*ChatMessage, error) {
 	if !msgType.IsValid() {
 		return nil, fmt.Errorf("invalid message type: %s", msgType)
 	}
-	if content == "" {
+	// Image messages carry their photos as attachments; the content is an optional caption.
+	if msgType == MessageTypeImage {
+		if len(attachments) == 0 {
+			return nil, fmt.Errorf("image messages need at least one attachment")
+		}
+		for _, a := range attachments {
+			if !strings.HasPrefix(strings.ToLower(a.MimeType), "image/") {
+				return nil, fmt.Errorf("image message attachments must be images, got %s", a.MimeType)
+			}
+		}
+	} else if content == "" {
 		return nil, fmt.Errorf("message content is required")
 	}
+	for _, a := range attachments {
+		if (a.URL == "" && a.Key == "") || a.MimeType == "" {
+			return nil, fmt.Errorf("attachment url or key and mime type are required")
+		}
+	}
 
 	return &ChatMessage{
-		id:         uuid.New(),
+		id:         id,
 		bookingID:  bookingID,
 		senderID:   senderID,
 		senderRole: senderRole,
 		msgType:    msgType,
 		content:    content,
-		createdAt:  time.Now().UTC(),
+		attachments: attachments,
+		moderationFlags: flags,
+		createdAt:  now.UTC(),
 	}, nil
 }
 
 // Reconstruct rebuilds a ChatMessage from persistence.
-func Reconstruct(id, bookingID, senderID uuid.UUID, senderRole string, msgType MessageType, content string, createdAt time.Time) *ChatMessage {
+func Reconstruct(id, bookingID, senderID uuid.UUID, senderRole string, msgType MessageType, content string, attachments []Attachment, flags []ModerationFlag, createdAt time.Time) *ChatMessage {
 	return &ChatMessage{
 		id:         id,
 		bookingID:  bookingID,
@@ -65,6 +116,8 @@ func Reconstruct(id, bookingID, senderID uuid.UUID, senderRole string, msgType M
 		senderRole: senderRole,
 		msgType:    msgType,
 		content:    content,
+		attachments: attachments,
+		moderationFlags: flags,
 		createdAt:  createdAt,
 	}
 }
@@ -76,4 +129,6 @@ func (m *ChatMessage) SenderID() uuid.UUID    { return m.senderID }
 func (m *ChatMessage) SenderRole() string     { return m.senderRole }
 func (m *ChatMessage) MessageType() MessageType { return m.msgType }
 func (m *ChatMessage) Content() string        { return m.content }
+func (m *ChatMessage) Attachments() []Attachment { return m.attachments }
+func (m *ChatMessage) ModerationFlags() []ModerationFlag { return m.moderationFlags }
 func (m *ChatMessage) CreatedAt() time.Time   { return m.createdAt }
@@ -2,6 +2,7 @@ package chat
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -10,4 +11,13 @@ import (
 type ChatRepository interface {
 	Save(ctx context.Context, msg *ChatMessage) error
 	FindByBookingID(ctx context.Context, bookingID uuid.UUID, limit, offset int) ([]*ChatMessage, int64, error)
+	// ReassignBooking moves all messages of one booking to another and returns how many
+	// were moved.
+	ReassignBooking(ctx context.Context, fromBookingID, toBookingID uuid.UUID) (int, error)
+	// EraseByBookingID anonymizes a booking's messages not yet erased: their content
+	// and attachments are cleared and they are marked erased at the given time, but kept
+	// so the thread's shape and moderation record survive. It returns how many were erased.
+	EraseByBookingID(ctx context.Context, bookingID uuid.UUID, at time.Time) (int, error)
+	// CountUnerasedByBookingID returns how many of a booking's messages are not erased.
+	CountUnerasedByBookingID(ctx context.Context, bookingID uuid.UUID) (int, error)
 }
@@ -0,0 +1,65 @@
+// Package erasure holds requests to erase a user's personal data from the trip data of
+// their bookings, and the report of what each request removed.
+package erasure
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the state of an erasure request.
+type Status string
+
+// Erasure request statuses.
+const (
+	// StatusPending is a request with bookings still to erase, either because their trip
+	// is in progress or because they are within the retention period.
+	StatusPending Status = "pending"
+	// StatusCompleted is a request whose bookings have all been erased.
+	StatusCompleted Status = "completed"
+)
+
+// BookingErasure is what was removed from one booking.
+type BookingErasure struct {
+	BookingID uuid.UUID `json:"booking_id"`
+	// Waypoints and SharedLinks were deleted; Messages were anonymized.
+	Waypoints   int       `json:"waypoints"`
+	Messages    int       `json:"messages"`
+	SharedLinks int       `json:"shared_links"`
+	ErasedAt    time.Time `json:"erased_at"`
+}
+
+// DeferredBooking is a booking not erased yet.
+type DeferredBooking struct {
+	BookingID uuid.UUID `json:"booking_id"`
+	// EligibleAt is when the booking's retention period ends; nil while its trip is in
+	// progress.
+	EligibleAt *time.Time `json:"eligible_at,omitempty"`
+}
+
+// Request is a request to erase a user's data from all of their bookings.
+type Request struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	// RequestedBy is the admin who made the request; nil for requests received as events.
+	RequestedBy *uuid.UUID
+	Status      Status
+	// Erased lists the bookings erased so far and Deferred those still waiting, as of
+	// the last time the request was processed.
+	Erased      []BookingErasure
+	Deferred    []DeferredBooking
+	RequestedAt time.Time
+	CompletedAt *time.Time
+	UpdatedAt   time.Time
+}
+
+// Repository defines persistence operations for erasure requests.
+type Repository interface {
+	Save(ctx context.Context, r *Request) error
+	Update(ctx context.Context, r *Request) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Request, error)
+	// FindPending returns the pending requests, oldest first.
+	FindPending(ctx context.Context) ([]*Request, error)
+}
@@ -0,0 +1,18 @@
+package eta
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository defines persistence operations for ETA subscriptions.
+type Repository interface {
+	// Save creates the subscription or replaces the stored one with the same ID.
+	Save(ctx context.Context, s *Subscription) error
+	FindByBookingID(ctx context.Context, bookingID uuid.UUID) ([]*Subscription, error)
+	// Delete removes a booking's subscription, returning domain.ErrNotFound if it does not exist.
+	Delete(ctx context.Context, bookingID, id uuid.UUID) error
+	UpdateLastNotified(ctx context.Context, id uuid.UUID, arrival time.Time) error
+}
@@ -0,0 +1,101 @@
+package eta
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MinThreshold and MaxThreshold bound how far an estimate must move before a
+	// subscriber is notified.
+	MinThreshold = time.Minute
+	MaxThreshold = 2 * time.Hour
+
+	// maxSubscriberLength caps the subscribing service's name.
+	maxSubscriberLength = 64
+)
+
+// Subscription is another service's interest in significant changes to a booking's
+// estimated arrival time.
+type Subscription struct {
+	id              uuid.UUID
+	bookingID       uuid.UUID
+	subscriber      string
+	threshold       time.Duration
+	callbackURL     string
+	lastNotifiedETA *time.Time
+	createdAt       time.Time
+}
+
+// NewSubscription creates a subscription for subscriber to a booking's ETA. callbackURL
+// is optional; when set it must be an absolute http or https URL.
+func NewSubscription(bookingID uuid.UUID, subscriber string, threshold time.Duration, callbackURL string, now time.Time) (*Subscription, error) {
+	s := &Subscription{
+		id:         uuid.New(),
+		bookingID:  bookingID,
+		subscriber: subscriber,
+		createdAt:  now,
+	}
+	if subscriber == "" || len(subscriber) > maxSubscriberLength {
+		return nil, fmt.Errorf("subscriber must be 1 to %d characters", maxSubscriberLength)
+	}
+	if err := s.Configure(threshold, callbackURL); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reconstruct rebuilds a Subscription from persistence.
+func Reconstruct(id, bookingID uuid.UUID, subscriber string, threshold time.Duration, callbackURL string, lastNotifiedETA *time.Time, createdAt time.Time) *Subscription {
+	return &Subscription{
+		id:              id,
+		bookingID:       bookingID,
+		subscriber:      subscriber,
+		threshold:       threshold,
+		callbackURL:     callbackURL,
+		lastNotifiedETA: lastNotifiedETA,
+		createdAt:       createdAt,
+	}
+}
+
+func (s *Subscription) ID() uuid.UUID               { return s.id }
+func (s *Subscription) BookingID() uuid.UUID        { return s.bookingID }
+func (s *Subscription) Subscriber() string          { return s.subscriber }
+func (s *Subscription) Threshold() time.Duration    { return s.threshold }
+func (s *Subscription) CallbackURL() string         { return s.callbackURL }
+func (s *Subscription) LastNotifiedETA() *time.Time { return s.lastNotifiedETA }
+func (s *Subscription) CreatedAt() time.Time        { return s.createdAt }
+
+// Configure changes the threshold and callback URL of the subscription.
+func (s *Subscription) Configure(threshold time.Duration, callbackURL string) error {
+	if threshold < MinThreshold || threshold > MaxThreshold {
+		return fmt.Errorf("threshold must be between %s and %s, got %s", MinThreshold, MaxThreshold, threshold)
+	}
+	if callbackURL != "" {
+		u, err := url.Parse(callbackURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("callback_url must be an absolute http or https URL")
+		}
+	}
+	s.threshold = threshold
+	s.callbackURL = callbackURL
+	return nil
+}
+
+// Significant reports whether an estimated arrival differs from the last one notified by
+// more than the threshold. The first estimate after subscribing is always significant.
+func (s *Subscription) Significant(arrival time.Time) bool {
+	if s.lastNotifiedETA == nil {
+		return true
+	}
+	delta := arrival.Sub(*s.lastNotifiedETA)
+	return delta > s.threshold || delta < -s.threshold
+}
+
+// MarkNotified records the estimated arrival the subscriber was last notified of.
+func (s *Subscription) MarkNotified(arrival time.Time) {
+	s.lastNotifiedETA = &arrival
+}
@@ -0,0 +1,233 @@
+package geofence
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind identifies which stop of a booking a geofence guards.
+type Kind string
+
+const (
+	KindPickup  Kind = "pickup"
+	KindDropoff Kind = "dropoff"
+)
+
+// IsValid returns true if the kind is recognized.
+func (k Kind) IsValid() bool {
+	return k == KindPickup || k == KindDropoff
+}
+
+// Shape identifies the geometry of a geofence.
+type Shape string
+
+const (
+	ShapeCircle  Shape = "circle"
+	ShapePolygon Shape = "polygon"
+)
+
+// Transition is the result of evaluating a position against a geofence.
+type Transition string
+
+const (
+	TransitionNone    Transition = ""
+	TransitionEntered Transition = "entered"
+	TransitionExited  Transition = "exited"
+)
+
+const (
+	// maxRadiusMeters caps circular geofences to keep them meaningful for a single stop.
+	maxRadiusMeters = 5000
+
+	// minPolygonVertices is the minimum number of vertices for a polygon geofence.
+	minPolygonVertices = 3
+)
+
+// Point is a geographic coordinate pair.
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (p Point) validate() error {
+	if p.Latitude < -90 || p.Latitude > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90, got %f", p.Latitude)
+	}
+	if p.Longitude < -180 || p.Longitude > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180, got %f", p.Longitude)
+	}
+	return nil
+}
+
+// Geofence is the aggregate root for a zone around a booking's pickup or drop-off.
+type Geofence struct {
+	id           uuid.UUID
+	bookingID    uuid.UUID
+	kind         Kind
+	shape        Shape
+	center       Point
+	radiusMeters float64
+	vertices     []Point
+	active       bool
+	inside       bool
+	createdAt    time.Time
+	updatedAt    time.Time
+}
+
+// NewCircleGeofence creates an active circular geofence.
+func NewCircleGeofence(bookingID uuid.UUID, kind Kind, center Point, radiusMeters float64) (*Geofence, error) {
+	if !kind.IsValid() {
+		return nil, fmt.Errorf("invalid geofence kind: %s", kind)
+	}
+	if err := center.validate(); err != nil {
+		return nil, err
+	}
+	if radiusMeters <= 0 || radiusMeters > maxRadiusMeters {
+		return nil, fmt.Errorf("radius must be between 0 and %d meters, got %f", maxRadiusMeters, radiusMeters)
+	}
+
+	now := time.Now().UTC()
+	return &Geofence{
+		id:           uuid.New(),
+		bookingID:    bookingID,
+		kind:         kind,
+		shape:        ShapeCircle,
+		center:       center,
+		radiusMeters: radiusMeters,
+		active:       true,
+		createdAt:    now,
+		updatedAt:    now,
+	}, nil
+}
+
+// NewPolygonGeofence creates an active polygon geofence from its vertices.
+func NewPolygonGeofence(bookingID uuid.UUID, kind Kind, vertices []Point) (*Geofence, error) {
+	if !kind.IsValid() {
+		return nil, fmt.Errorf("invalid geofence kind: %s", kind)
+	}
+	if len(vertices) < minPolygonVertices {
+		return nil, fmt.Errorf("polygon must have at least %d vertices, got %d", minPolygonVertices, len(vertices))
+	}
+	for _, v := range vertices {
+		if err := v.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	return &Geofence{
+		id:        uuid.New(),
+		bookingID: bookingID,
+		kind:      kind,
+		shape:     ShapePolygon,
+		vertices:  append([]Point(nil), vertices...),
+		active:    true,
+		createdAt: now,
+		updatedAt: now,
+	}, nil
+}
+
+// Reconstruct rebuilds a Geofence from persistence.
+func Reconstruct(
+	id, bookingID uuid.UUID,
+	kind Kind,
+	shape Shape,
+	center Point,
+	radiusMeters float64,
+	vertices []Point,
+	active, inside bool,
+	createdAt, updatedAt time.Time,
+) *Geofence {
+	return &Geofence{
+		id:           id,
+		bookingID:    bookingID,
+		kind:         kind,
+		shape:        shape,
+		center:       center,
+		radiusMeters: radiusMeters,
+		vertices:     vertices,
+		active:       active,
+		inside:       inside,
+		createdAt:    createdAt,
+		updatedAt:    updatedAt,
+	}
+}
+
+// Getters.
+func (g *Geofence) ID() uuid.UUID         { return g.id }
+func (g *Geofence) BookingID() uuid.UUID  { return g.bookingID }
+func (g *Geofence) Kind() Kind            { return g.kind }
+func (g *Geofence) Shape() Shape          { return g.shape }
+func (g *Geofence) Center() Point         { return g.center }
+func (g *Geofence) RadiusMeters() float64 { return g.radiusMeters }
+func (g *Geofence) Vertices() []Point     { return g.vertices }
+func (g *Geofence) IsActive() bool        { return g.active }
+func (g *Geofence) IsInside() bool        { return g.inside }
+func (g *Geofence) CreatedAt() time.Time  { return g.createdAt }
+func (g *Geofence) UpdatedAt() time.Time  { return g.updatedAt }
+
+// Contains reports whether a position lies within the geofence.
+func (g *Geofence) Contains(p Point) bool {
+	switch g.shape {
+	case ShapeCircle:
+		return distanceMeters(g.center, p) <= g.radiusMeters
+	case ShapePolygon:
+		return pointInPolygon(p, g.vertices)
+	}
+	return false
+}
+
+// Evaluate updates the inside/outside state for a new position and returns the transition, if any.
+func (g *Geofence) Evaluate(p Point) Transition {
+	if !g.active {
+		return TransitionNone
+	}
+
+	inside := g.Contains(p)
+	if inside == g.inside {
+		return TransitionNone
+	}
+
+	g.inside = inside
+	g.updatedAt = time.Now().UTC()
+	if inside {
+		return TransitionEntered
+	}
+	return TransitionExited
+}
+
+// Deactivate stops the geofence from being evaluated.
+func (g *Geofence) Deactivate() {
+	g.active = false
+	g.updatedAt = time.Now().UTC()
+}
+
+// distanceMeters calculates the great-circle distance in meters between two points.
+func distanceMeters(a, b Point) float64 {
+	const earthRadiusM = 6371000.0
+
+	dLat := (b.Latitude - a.Latitude) * math.Pi / 180
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusM * 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+// pointInPolygon uses ray casting to test whether p lies inside the polygon.
+func pointInPolygon(p Point, vertices []Point) bool {
+	inside := false
+	for i, j := 0, len(vertices)-1; i < len(vertices); j, i = i, i+1 {
+		vi, vj := vertices[i], vertices[j]
+		if (vi.Latitude > p.Latitude) != (vj.Latitude > p.Latitude) &&
+			p.Longitude < (vj.Longitude-vi.Longitude)*(p.Latitude-vi.Latitude)/(vj.Latitude-vi.Latitude)+vi.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
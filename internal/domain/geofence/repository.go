@@ -0,0 +1,16 @@
+package geofence
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// GeofenceRepository defines persistence operations for geofences.
+type GeofenceRepository interface {
+	Save(ctx context.Context, g *Geofence) error
+	Update(ctx context.Context, g *Geofence) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Geofence, error)
+	FindByBookingID(ctx context.Context, bookingID uuid.UUID) ([]*Geofence, error)
+	FindActiveByBookingID(ctx context.Context, bookingID uuid.UUID) ([]*Geofence, error)
+}
@@ -0,0 +1,70 @@
+// Package history holds the lifecycle log of trip tracks: an append-only record of
+// every state transition, who caused it and why, from which support reconstructs what
+// happened to a trip.
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind is the kind of lifecycle event.
+type Kind string
+
+// Lifecycle event kinds.
+const (
+	KindCreated   Kind = "created"
+	KindPaused    Kind = "paused"
+	KindResumed   Kind = "resumed"
+	KindCancelled Kind = "cancelled"
+	KindCompleted Kind = "completed"
+	// KindMerged is a duplicate track merged into this one.
+	KindMerged Kind = "merged"
+	// KindAnomaly is a suspicious driving pattern detected on the trip. It does not
+	// change the trip's status.
+	KindAnomaly Kind = "anomaly"
+)
+
+// ActorSystem is the actor type of events caused by the service itself or by other
+// platform services through Kafka. Events caused by a user carry the user's role.
+const ActorSystem = "system"
+
+// Actor is who caused a lifecycle event.
+type Actor struct {
+	// Type is ActorSystem or the role of the user, e.g. runner or admin.
+	Type string
+	// ID is the user's ID; nil for the system.
+	ID *uuid.UUID
+}
+
+// System is the actor of events not caused by a user.
+var System = Actor{Type: ActorSystem}
+
+// User returns the actor of an event caused by a user with the given role.
+func User(role string, id uuid.UUID) Actor {
+	return Actor{Type: role, ID: &id}
+}
+
+// TrackEvent is one entry in a trip's lifecycle log.
+type TrackEvent struct {
+	ID        uuid.UUID
+	TrackID   uuid.UUID
+	BookingID uuid.UUID
+	Kind      Kind
+	// Status is the trip's status after the event.
+	Status string
+	Actor  Actor
+	// Reason is why the event happened, e.g. a cancellation reason code and note.
+	Reason     string
+	OccurredAt time.Time
+}
+
+// Repository defines persistence operations for the lifecycle log. Events are never
+// updated or deleted.
+type Repository interface {
+	Append(ctx context.Context, e *TrackEvent) error
+	// FindByBookingID returns a booking's events, oldest first.
+	FindByBookingID(ctx context.Context, bookingID uuid.UUID) ([]*TrackEvent, error)
+}
@@ -0,0 +1,51 @@
+// Package inbox holds each user's undelivered chat and system messages, which clients
+// fetch with a cursor to catch up after being offline.
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind is the kind of message an inbox entry holds.
+type Kind string
+
+// Inbox entry kinds.
+const (
+	KindChat   Kind = "chat"
+	KindSystem Kind = "system"
+)
+
+// Entry is a message waiting to be delivered to a user.
+type Entry struct {
+	// Cursor orders a user's entries; it increases with every entry recorded.
+	Cursor    int64
+	UserID    uuid.UUID
+	BookingID uuid.UUID
+	Kind      Kind
+	// Type is the WebSocket frame type the message is sent as live, e.g. chat_message.
+	Type string
+	// MessageID identifies the message, so clients can drop copies also received live.
+	MessageID uuid.UUID
+	Data      json.RawMessage
+	CreatedAt time.Time
+}
+
+// Repository defines persistence operations for inbox entries.
+type Repository interface {
+	// Append records entries, ignoring those already recorded for the same user and
+	// message. Cursors are assigned on insert.
+	Append(ctx context.Context, entries []Entry) error
+	// ListAfter returns up to limit of a user's entries with a cursor after the given
+	// one, oldest first.
+	ListAfter(ctx context.Context, userID uuid.UUID, cursor int64, limit int) ([]Entry, error)
+	// DeleteUpTo deletes a user's entries with a cursor up to and including the given
+	// one, which the user has received.
+	DeleteUpTo(ctx context.Context, userID uuid.UUID, cursor int64) (int64, error)
+	// DeleteCreatedBefore deletes the entries recorded before a point in time and
+	// returns how many were deleted.
+	DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error)
+}
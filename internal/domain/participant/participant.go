@@ -0,0 +1,40 @@
+// Package participant holds the users taking part in a booking, as reported by the
+// booking service, for authorizing access to its tracking data. It also keeps the
+// booking's pet species, which selects the carrier temperature range.
+package participant
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookingParticipants are the owner and assigned runner of a booking. Either may be
+// uuid.Nil until the booking service reports it.
+type BookingParticipants struct {
+	BookingID uuid.UUID
+	OwnerID   uuid.UUID
+	RunnerID  uuid.UUID
+	// PetSpecies is the species of the transported pet, empty until reported.
+	PetSpecies string
+	UpdatedAt  time.Time
+}
+
+// Includes reports whether userID is the booking's owner or assigned runner.
+func (p *BookingParticipants) Includes(userID uuid.UUID) bool {
+	if userID == uuid.Nil {
+		return false
+	}
+	return userID == p.OwnerID || userID == p.RunnerID
+}
+
+// Repository defines persistence operations for booking participants.
+type Repository interface {
+	// Upsert stores the participants of a booking, keeping previously known IDs that
+	// are uuid.Nil in p and a previously known species if p has none.
+	Upsert(ctx context.Context, p BookingParticipants) error
+	FindByBookingID(ctx context.Context, bookingID uuid.UUID) (*BookingParticipants, error)
+	// FindByUserID returns the participants of every booking the user owns or runs.
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]BookingParticipants, error)
+}
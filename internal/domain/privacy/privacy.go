@@ -0,0 +1,37 @@
+// Package privacy holds the record of runner locations the service refused to store
+// because no trip of the runner was in progress.
+package privacy
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reasons a location was dropped.
+const (
+	// DropNoActiveTrip is a location received while the runner had no trip in progress.
+	DropNoActiveTrip = "no_active_trip"
+	// DropTripNotActive is a location submitted for a trip that has ended.
+	DropTripNotActive = "trip_not_active"
+	// DropBeforeTripStart is a location recorded before its trip started.
+	DropBeforeTripStart = "before_trip_start"
+)
+
+// DropCount is the number of a runner's locations dropped for one reason on one day.
+type DropCount struct {
+	RunnerID uuid.UUID
+	// Day is the UTC day the locations were received.
+	Day    time.Time
+	Reason string
+	Count  int64
+}
+
+// Repository defines persistence operations for dropped-location counts.
+type Repository interface {
+	// AddDrops adds counts to the stored ones.
+	AddDrops(ctx context.Context, counts []DropCount) error
+	// ListDrops returns a runner's counts for the days in [from, to), oldest first.
+	ListDrops(ctx context.Context, runnerID uuid.UUID, from, to time.Time) ([]DropCount, error)
+}
@@ -2,6 +2,7 @@ package share
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -11,4 +12,21 @@ type SharedTripRepository interface {
 	Save(ctx context.Context, st *SharedTrip) error
 	FindByToken(ctx context.Context, token string) (*SharedTrip, error)
 	FindByBookingID(ctx context.Context, bookingID uuid.UUID) (*SharedTrip, error)
+	// FindActiveByBookingID returns the unrevoked, unexpired links of a booking, newest first.
+	FindActiveByBookingID(ctx context.Context, bookingID uuid.UUID) ([]*SharedTrip, error)
+	// Revoke marks a booking's unrevoked links as revoked at the given time and returns
+	// how many were revoked. If shareID is not nil, only that link is revoked.
+	Revoke(ctx context.Context, bookingID uuid.UUID, shareID *uuid.UUID, at time.Time) (int, error)
+	// RecordView atomically counts one view of a link if it is unrevoked, unexpired and
+	// below its view limit, and reports whether the view was allowed. The view that
+	// reaches the limit also expires the link.
+	RecordView(ctx context.Context, id uuid.UUID) (bool, error)
+	// ReassignBooking moves all links of one booking to another and returns how many
+	// were moved.
+	ReassignBooking(ctx context.Context, fromBookingID, toBookingID uuid.UUID) (int, error)
+	// DeleteByBookingID removes all links of a booking, revoked or not, and returns how
+	// many were removed. Their tokens stop resolving.
+	DeleteByBookingID(ctx context.Context, bookingID uuid.UUID) (int, error)
+	// CountByBookingID returns how many links a booking has, revoked or not.
+	CountByBookingID(ctx context.Context, bookingID uuid.UUID) (int, error)
 }
@@ -3,11 +3,16 @@ package share
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrInvalidLimits is returned when a share link's expiry or view limit is out of range.
+var ErrInvalidLimits = errors.New("invalid share link limits")
+
 // SharedTrip represents a publicly shareable link to a booking's tracking.
 type SharedTrip struct {
 	id         uuid.UUID
@@ -15,39 +20,75 @@ type SharedTrip struct {
 	shareToken string
 	expiresAt  time.Time
 	createdAt  time.Time
+	revokedAt  *time.Time
+	maxViews   int
+	viewCount  int
 }
 
-// NewSharedTrip creates a new shared trip with a random token and 24h expiry.
-func NewSharedTrip(bookingID uuid.UUID) (*SharedTrip, error) {
+// Share link lifetime limits.
+const (
+	DefaultExpiry = 24 * time.Hour
+	MinExpiry     = time.Minute
+	MaxExpiry     = 7 * 24 * time.Hour
+)
+
+// NewSharedTrip creates a new shared trip created at now with a random token that
+// expires after expiresIn (DefaultExpiry if zero) and allows at most maxViews views
+// (0 for unlimited).
+func NewSharedTrip(id, bookingID uuid.UUID, expiresIn time.Duration, maxViews int, now time.Time) (*SharedTrip, error) {
+	if expiresIn == 0 {
+		expiresIn = DefaultExpiry
+	}
+	if expiresIn < MinExpiry || expiresIn > MaxExpiry {
+		return nil, fmt.Errorf("%w: expiry must be between %s and %s", ErrInvalidLimits, MinExpiry, MaxExpiry)
+	}
+	if maxViews < 0 {
+		return nil, fmt.Errorf("%w: max views must not be negative", ErrInvalidLimits)
+	}
+
 	token, err := generateToken()
 	if err != nil {
 		return nil, err
 	}
 
-	now := time.Now().UTC()
+	now = now.UTC()
 	return &SharedTrip{
-		id:         uuid.New(),
+		id:         id,
 		bookingID:  bookingID,
 		shareToken: token,
-		expiresAt:  now.Add(24 * time.Hour),
+		expiresAt:  now.Add(expiresIn),
 		createdAt:  now,
+		maxViews:   maxViews,
 	}, nil
 }
 
 // Reconstruct rebuilds a SharedTrip from persistence.
-func Reconstruct(id, bookingID uuid.UUID, shareToken string, expiresAt, createdAt time.Time) *SharedTrip {
+func Reconstruct(id, bookingID uuid.UUID, shareToken string, expiresAt, createdAt time.Time, revokedAt *time.Time, maxViews, viewCount int) *SharedTrip {
 	return &SharedTrip{
 		id:         id,
 		bookingID:  bookingID,
 		shareToken: shareToken,
 		expiresAt:  expiresAt,
 		createdAt:  createdAt,
+		revokedAt:  revokedAt,
+		maxViews:   maxViews,
+		viewCount:  viewCount,
 	}
 }
 
-// IsExpired returns true if the share link has expired.
-func (s *SharedTrip) IsExpired() bool {
-	return time.Now().UTC().After(s.expiresAt)
+// IsExpired returns true if the share link has expired at now.
+func (s *SharedTrip) IsExpired(now time.Time) bool {
+	return now.After(s.expiresAt)
+}
+
+// ViewsExhausted returns true if the share link has used up its view limit.
+func (s *SharedTrip) ViewsExhausted() bool {
+	return s.maxViews > 0 && s.viewCount >= s.maxViews
+}
+
+// IsRevoked returns true if the share link has been revoked.
+func (s *SharedTrip) IsRevoked() bool {
+	return s.revokedAt != nil
 }
 
 // Getters.
@@ -56,6 +97,9 @@ func (s *SharedTrip) BookingID() uuid.UUID  { return s.bookingID }
 func (s *SharedTrip) ShareToken() string    { return s.shareToken }
 func (s *SharedTrip) ExpiresAt() time.Time  { return s.expiresAt }
 func (s *SharedTrip) CreatedAt() time.Time  { return s.createdAt }
+func (s *SharedTrip) RevokedAt() *time.Time { return s.revokedAt }
+func (s *SharedTrip) MaxViews() int         { return s.maxViews }
+func (s *SharedTrip) ViewCount() int        { return s.viewCount }
 
 func generateToken() (string, error) {
 	b := make([]byte, 16)
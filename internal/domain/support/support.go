@@ -0,0 +1,61 @@
+// Package support holds read-only support sessions, in which a support agent views a
+// booking's tracking and chat as its participants see them, and the audit trail of
+// everything viewed in them.
+package support
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audited actions.
+const (
+	ActionSessionOpened = "session_opened"
+	ActionSessionClosed = "session_closed"
+	ActionViewTracking  = "view_tracking"
+	ActionViewChat      = "view_chat"
+	ActionStreamOpened  = "stream_opened"
+)
+
+// Session is a support agent's time-limited, read-only view of one booking.
+type Session struct {
+	ID        uuid.UUID
+	AgentID   uuid.UUID
+	BookingID uuid.UUID
+	// Reason is why the agent opened the session, e.g. a ticket reference.
+	Reason    string
+	OpenedAt  time.Time
+	ExpiresAt time.Time
+	ClosedAt  *time.Time
+}
+
+// IsOpen returns true if the session has neither been closed nor expired at now.
+func (s *Session) IsOpen(now time.Time) bool {
+	return s.ClosedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// AuditEvent records one action taken in a support session.
+type AuditEvent struct {
+	ID        uuid.UUID
+	SessionID uuid.UUID
+	AgentID   uuid.UUID
+	BookingID uuid.UUID
+	Action    string
+	// Detail holds action-specific context such as the chat page viewed.
+	Detail     string
+	SourceIP   string
+	OccurredAt time.Time
+}
+
+// Repository defines persistence operations for support sessions and their audit trail.
+type Repository interface {
+	SaveSession(ctx context.Context, s *Session) error
+	FindSession(ctx context.Context, id uuid.UUID) (*Session, error)
+	// CloseSession sets a session's ClosedAt.
+	CloseSession(ctx context.Context, id uuid.UUID, closedAt time.Time) error
+	AppendAudit(ctx context.Context, e *AuditEvent) error
+	// ListAudit returns a session's audit events, oldest first.
+	ListAudit(ctx context.Context, sessionID uuid.UUID) ([]*AuditEvent, error)
+}
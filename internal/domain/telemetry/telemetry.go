@@ -0,0 +1,89 @@
+// Package telemetry holds the auxiliary readings of pet transport crates, such as
+// temperature and humidity, recorded alongside a trip's GPS positions.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bounds of plausible readings; anything outside is a sensor fault, not a crate condition.
+const (
+	minPlausibleCelsius = -40
+	maxPlausibleCelsius = 80
+)
+
+// ErrInvalidReading is returned when a reading is empty or out of the plausible range.
+var ErrInvalidReading = errors.New("invalid telemetry reading")
+
+// Condition is the result of checking a humidity reading against the safe range.
+type Condition string
+
+// Humidity conditions.
+const (
+	ConditionNormal   Condition = "normal"
+	ConditionTooDry   Condition = "too_dry"
+	ConditionTooHumid Condition = "too_humid"
+)
+
+// Reading is a crate's telemetry at one moment of a trip. Either value may be missing
+// when the crate has no such sensor.
+type Reading struct {
+	ID           uuid.UUID
+	TrackID      uuid.UUID
+	BookingID    uuid.UUID
+	TemperatureC *float64
+	// HumidityPct is the relative humidity in percent.
+	HumidityPct *float64
+	RecordedAt  time.Time
+}
+
+// NewReading validates and creates a reading of a trip.
+func NewReading(trackID, bookingID uuid.UUID, temperatureC, humidityPct *float64, recordedAt time.Time) (*Reading, error) {
+	if temperatureC == nil && humidityPct == nil {
+		return nil, fmt.Errorf("%w: temperature_c or humidity_pct is required", ErrInvalidReading)
+	}
+	if temperatureC != nil && (*temperatureC < minPlausibleCelsius || *temperatureC > maxPlausibleCelsius) {
+		return nil, fmt.Errorf("%w: temperature_c must be within -40 and 80 °C", ErrInvalidReading)
+	}
+	if humidityPct != nil && (*humidityPct < 0 || *humidityPct > 100) {
+		return nil, fmt.Errorf("%w: humidity_pct must be within 0 and 100", ErrInvalidReading)
+	}
+	return &Reading{
+		ID:           uuid.New(),
+		TrackID:      trackID,
+		BookingID:    bookingID,
+		TemperatureC: temperatureC,
+		HumidityPct:  humidityPct,
+		RecordedAt:   recordedAt,
+	}, nil
+}
+
+// HumidityRange is the safe relative humidity inside a crate, in percent.
+type HumidityRange struct {
+	MinPct float64
+	MaxPct float64
+}
+
+// Evaluate checks a humidity reading against the range.
+func (r HumidityRange) Evaluate(pct float64) Condition {
+	switch {
+	case pct < r.MinPct:
+		return ConditionTooDry
+	case pct > r.MaxPct:
+		return ConditionTooHumid
+	default:
+		return ConditionNormal
+	}
+}
+
+// Repository defines persistence operations for crate telemetry.
+type Repository interface {
+	Save(ctx context.Context, readings []*Reading) error
+	// FindByTrackID returns a trip's readings, oldest first.
+	FindByTrackID(ctx context.Context, trackID uuid.UUID) ([]*Reading, error)
+}
@@ -0,0 +1,90 @@
+// Package temperature holds the safe carrier temperature range of each pet species,
+// used to raise alerts from carrier telemetry.
+package temperature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultSpecies is the threshold used for species without their own range.
+const DefaultSpecies = "default"
+
+// Bounds for configurable ranges, well outside anything a carrier should reach.
+const (
+	minSettableCelsius = -20
+	maxSettableCelsius = 60
+	maxSpeciesLength   = 32
+)
+
+// ErrInvalidThreshold is returned when a threshold's species or range is invalid.
+var ErrInvalidThreshold = errors.New("invalid temperature threshold")
+
+// Condition is the result of checking a temperature against a threshold.
+type Condition string
+
+// Temperature conditions.
+const (
+	ConditionNormal  Condition = "normal"
+	ConditionTooCold Condition = "too_cold"
+	ConditionTooHot  Condition = "too_hot"
+	// ConditionUnknown is reported when no threshold applies to the species.
+	ConditionUnknown Condition = "unknown"
+)
+
+// Threshold is the safe carrier temperature range for a species, in degrees Celsius.
+type Threshold struct {
+	Species    string
+	MinCelsius float64
+	MaxCelsius float64
+	UpdatedAt  time.Time
+}
+
+// NewThreshold validates and creates a threshold.
+func NewThreshold(species string, minCelsius, maxCelsius float64) (*Threshold, error) {
+	species = NormalizeSpecies(species)
+	if species == "" || len(species) > maxSpeciesLength {
+		return nil, fmt.Errorf("%w: species must be 1 to 32 characters", ErrInvalidThreshold)
+	}
+	if minCelsius < minSettableCelsius || maxCelsius > maxSettableCelsius {
+		return nil, fmt.Errorf("%w: range must be within -20 and 60 °C", ErrInvalidThreshold)
+	}
+	if minCelsius >= maxCelsius {
+		return nil, fmt.Errorf("%w: min_celsius must be below max_celsius", ErrInvalidThreshold)
+	}
+	return &Threshold{
+		Species:    species,
+		MinCelsius: minCelsius,
+		MaxCelsius: maxCelsius,
+		UpdatedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// Evaluate checks a temperature against the threshold.
+func (t *Threshold) Evaluate(celsius float64) Condition {
+	switch {
+	case celsius < t.MinCelsius:
+		return ConditionTooCold
+	case celsius > t.MaxCelsius:
+		return ConditionTooHot
+	default:
+		return ConditionNormal
+	}
+}
+
+// NormalizeSpecies lower-cases and trims a species name so lookups are case-insensitive.
+func NormalizeSpecies(species string) string {
+	return strings.ToLower(strings.TrimSpace(species))
+}
+
+// Repository defines persistence operations for temperature thresholds.
+type Repository interface {
+	List(ctx context.Context) ([]*Threshold, error)
+	FindBySpecies(ctx context.Context, species string) (*Threshold, error)
+	// Upsert creates or replaces the threshold of a species.
+	Upsert(ctx context.Context, t *Threshold) error
+	Delete(ctx context.Context, species string) error
+}
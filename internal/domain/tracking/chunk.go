@@ -0,0 +1,16 @@
+package tracking
+
+import "time"
+
+// WaypointChunkSize is the number of waypoints stored per chunk. Chunks let time-window
+// and bounding-box reads skip the parts of long trips they do not touch.
+const WaypointChunkSize = 256
+
+// WaypointChunk is a fixed-size run of a track's waypoints with precomputed extents.
+type WaypointChunk struct {
+	Seq       int
+	Bounds    BoundingBox
+	StartedAt time.Time
+	EndedAt   time.Time
+	Waypoints []Waypoint
+}
@@ -0,0 +1,52 @@
+package tracking
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// nullIslandToleranceDeg is how close to (0,0) a fix must be to count as a "null island"
+// fix, which GPS stacks report when they have no position (~11m at the equator).
+const nullIslandToleranceDeg = 0.0001
+
+// IsNullIsland reports whether a coordinate is (0,0) or within a few metres of it.
+func IsNullIsland(lat, lng float64) bool {
+	return math.Abs(lat) < nullIslandToleranceDeg && math.Abs(lng) < nullIslandToleranceDeg
+}
+
+// Contains reports whether a coordinate lies within the box, edges included.
+func (b BoundingBox) Contains(lat, lng float64) bool {
+	return lat >= b.MinLatitude && lat <= b.MaxLatitude &&
+		lng >= b.MinLongitude && lng <= b.MaxLongitude
+}
+
+// ParseBoundingBox parses a box formatted as "minLat,minLng,maxLat,maxLng".
+func ParseBoundingBox(s string) (BoundingBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return BoundingBox{}, fmt.Errorf("bounding box %q must be minLat,minLng,maxLat,maxLng", s)
+	}
+
+	var v [4]float64
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return BoundingBox{}, fmt.Errorf("bounding box %q: invalid number %q", s, part)
+		}
+		v[i] = f
+	}
+
+	box := BoundingBox{MinLatitude: v[0], MinLongitude: v[1], MaxLatitude: v[2], MaxLongitude: v[3]}
+	if _, err := NewLocation(box.MinLatitude, box.MinLongitude); err != nil {
+		return BoundingBox{}, fmt.Errorf("bounding box %q: %w", s, err)
+	}
+	if _, err := NewLocation(box.MaxLatitude, box.MaxLongitude); err != nil {
+		return BoundingBox{}, fmt.Errorf("bounding box %q: %w", s, err)
+	}
+	if box.MinLatitude >= box.MaxLatitude || box.MinLongitude >= box.MaxLongitude {
+		return BoundingBox{}, fmt.Errorf("bounding box %q: minimums must be below maximums", s)
+	}
+	return box, nil
+}
@@ -0,0 +1,39 @@
+package tracking
+
+import (
+	"fmt"
+	"time"
+)
+
+// Network types a runner's phone may report.
+const (
+	NetworkWiFi     = "wifi"
+	NetworkCellular = "cellular"
+	NetworkNone     = "none"
+)
+
+// DeviceStatus is the state of a runner's phone as last reported during a trip.
+type DeviceStatus struct {
+	// BatteryPct is the battery level from 0 to 100, or nil if not reported.
+	BatteryPct *int
+	// Network is the connection type, or "" if not reported.
+	Network    string
+	ReportedAt time.Time
+}
+
+// NewDeviceStatus creates a validated DeviceStatus. It reports false if the report
+// carries neither a battery level nor a network type.
+func NewDeviceStatus(batteryPct *int, network string, reportedAt time.Time) (DeviceStatus, bool, error) {
+	if batteryPct == nil && network == "" {
+		return DeviceStatus{}, false, nil
+	}
+	if batteryPct != nil && (*batteryPct < 0 || *batteryPct > 100) {
+		return DeviceStatus{}, false, fmt.Errorf("battery must be between 0 and 100, got %d", *batteryPct)
+	}
+	switch network {
+	case "", NetworkWiFi, NetworkCellular, NetworkNone:
+	default:
+		return DeviceStatus{}, false, fmt.Errorf("network must be one of %s, %s or %s, got %q", NetworkWiFi, NetworkCellular, NetworkNone, network)
+	}
+	return DeviceStatus{BatteryPct: batteryPct, Network: network, ReportedAt: reportedAt}, true, nil
+}
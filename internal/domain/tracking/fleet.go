@@ -0,0 +1,11 @@
+package tracking
+
+// RegionFleetStats counts the trips in progress in one region. Region is empty for
+// tracks created from the global topics.
+type RegionFleetStats struct {
+	Region       string
+	ActiveTracks int
+	PausedTracks int
+	// ActiveRunners is the number of distinct runners with a trip in progress.
+	ActiveRunners int
+}
@@ -0,0 +1,98 @@
+package tracking
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Geohash precision limits of heatmap cells. A precision 1 cell spans thousands of
+// kilometers and a precision 9 cell a few meters.
+const (
+	MinGeohashPrecision = 1
+	MaxGeohashPrecision = 9
+)
+
+// geohashAlphabet is the base32 alphabet of geohashes.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// HeatmapQuery selects the waypoints binned into a heatmap.
+type HeatmapQuery struct {
+	Bounds BoundingBox
+	// From and To bound the recording time of the waypoints, [From, To).
+	From time.Time
+	To   time.Time
+	// Precision is the geohash length of the cells.
+	Precision int
+	// ExcludeRunners are runners whose trips are left out, such as the probe runner.
+	ExcludeRunners []uuid.UUID
+	// Limit is the most cells returned, densest first.
+	Limit int
+}
+
+// HeatmapCell is the number of waypoints recorded in one geohash cell.
+type HeatmapCell struct {
+	Geohash   string
+	Waypoints int
+	// Trips is the number of distinct trips with a waypoint in the cell.
+	Trips int
+}
+
+// EncodeGeohash returns the geohash of a coordinate with the given number of characters,
+// as PostGIS ST_GeoHash does.
+func EncodeGeohash(lat, lng float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+	var b strings.Builder
+	bit, ch, even := 0, 0, true
+	for b.Len() < precision {
+		// Bits alternate between longitude and latitude, starting with longitude.
+		r, v := &latRange, lat
+		if even {
+			r, v = &lngRange, lng
+		}
+		mid := (r[0] + r[1]) / 2
+		ch <<= 1
+		if v >= mid {
+			ch |= 1
+			r[0] = mid
+		} else {
+			r[1] = mid
+		}
+		even = !even
+		if bit++; bit == 5 {
+			b.WriteByte(geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return b.String()
+}
+
+// GeohashBounds returns the box covered by a geohash cell. Characters outside the
+// geohash alphabet are ignored.
+func GeohashBounds(hash string) BoundingBox {
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+	even := true
+	for _, c := range hash {
+		idx := strings.IndexRune(geohashAlphabet, c)
+		if idx < 0 {
+			continue
+		}
+		for bit := 4; bit >= 0; bit-- {
+			r := &latRange
+			if even {
+				r = &lngRange
+			}
+			mid := (r[0] + r[1]) / 2
+			if idx&(1<<bit) != 0 {
+				r[0] = mid
+			} else {
+				r[1] = mid
+			}
+			even = !even
+		}
+	}
+	return BoundingBox{MinLatitude: latRange[0], MinLongitude: lngRange[0], MaxLatitude: latRange[1], MaxLongitude: lngRange[1]}
+}
@@ -0,0 +1,51 @@
+package tracking
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// PositionAt returns the position at the given moment, linearly interpolated between the
+// waypoints recorded immediately before and after it. Waypoints must be ordered by time.
+// It also returns the bracketing waypoints (equal when a waypoint was recorded exactly at
+// that moment). ok is false if the moment lies outside the recorded waypoints.
+func PositionAt(waypoints []Waypoint, at time.Time) (pos Waypoint, before, after Waypoint, ok bool) {
+	if len(waypoints) == 0 {
+		return Waypoint{}, Waypoint{}, Waypoint{}, false
+	}
+
+	// Index of the first waypoint recorded at or after the moment.
+	i := sort.Search(len(waypoints), func(i int) bool {
+		return !waypoints[i].RecordedAt.Before(at)
+	})
+	if i == len(waypoints) || (i == 0 && !waypoints[0].RecordedAt.Equal(at)) {
+		return Waypoint{}, Waypoint{}, Waypoint{}, false
+	}
+
+	after = waypoints[i]
+	if after.RecordedAt.Equal(at) {
+		return after, after, after, true
+	}
+	before = waypoints[i-1]
+
+	f := float64(at.Sub(before.RecordedAt)) / float64(after.RecordedAt.Sub(before.RecordedAt))
+	pos = Waypoint{
+		Latitude:   lerp(before.Latitude, after.Latitude, f),
+		Longitude:  lerp(before.Longitude, after.Longitude, f),
+		Speed:      lerp(before.Speed, after.Speed, f),
+		Heading:    lerpHeading(before.Heading, after.Heading, f),
+		RecordedAt: at,
+	}
+	return pos, before, after, true
+}
+
+func lerp(a, b, f float64) float64 {
+	return a + (b-a)*f
+}
+
+// lerpHeading interpolates between two headings in degrees along the shorter arc.
+func lerpHeading(a, b, f float64) float64 {
+	delta := math.Mod(b-a+540, 360) - 180
+	return math.Mod(a+delta*f+360, 360)
+}
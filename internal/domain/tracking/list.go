@@ -0,0 +1,93 @@
+package tracking
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ListSort is the field by which List orders trip tracks. Ties are broken by ID.
+type ListSort string
+
+const (
+	// ListSortStartedAt orders tracks by when they started.
+	ListSortStartedAt ListSort = "started_at"
+	// ListSortDistance orders tracks by total distance travelled.
+	ListSortDistance ListSort = "distance"
+)
+
+// ListCursor is the position of the last track of a page, from which the next page
+// continues. Only the field matching the query's sort is compared, together with ID.
+type ListCursor struct {
+	ID         uuid.UUID `json:"id"`
+	StartedAt  time.Time `json:"started_at"`
+	DistanceKm float64   `json:"distance_km"`
+}
+
+// ListQuery selects and orders trip tracks for List. It is built from ListOptions;
+// zero values leave a filter unset.
+type ListQuery struct {
+	Statuses      []TrackingStatus
+	RunnerID      *uuid.UUID
+	StartedFrom   time.Time
+	StartedTo     time.Time
+	MinDistanceKm float64
+	Sort          ListSort
+	Descending    bool
+	After         *ListCursor
+	Limit         int
+}
+
+// ListOption adds a filter, ordering or bound to a ListQuery.
+type ListOption func(*ListQuery)
+
+// NewListQuery applies opts to the default query: all tracks, most recently started
+// first, 50 per page.
+func NewListQuery(opts ...ListOption) ListQuery {
+	q := ListQuery{Sort: ListSortStartedAt, Descending: true, Limit: 50}
+	for _, opt := range opts {
+		opt(&q)
+	}
+	return q
+}
+
+// WithStatuses restricts the query to tracks in any of the given statuses.
+func WithStatuses(statuses ...TrackingStatus) ListOption {
+	return func(q *ListQuery) { q.Statuses = append(q.Statuses, statuses...) }
+}
+
+// WithRunner restricts the query to one runner's tracks.
+func WithRunner(runnerID uuid.UUID) ListOption {
+	return func(q *ListQuery) { q.RunnerID = &runnerID }
+}
+
+// StartedBetween restricts the query to tracks started in [from, to). A zero from or to
+// leaves that side open.
+func StartedBetween(from, to time.Time) ListOption {
+	return func(q *ListQuery) { q.StartedFrom, q.StartedTo = from, to }
+}
+
+// WithMinDistanceKm restricts the query to tracks that travelled at least km.
+func WithMinDistanceKm(km float64) ListOption {
+	return func(q *ListQuery) { q.MinDistanceKm = km }
+}
+
+// SortedBy orders the query by sort, descending if desc is set.
+func SortedBy(sort ListSort, desc bool) ListOption {
+	return func(q *ListQuery) { q.Sort, q.Descending = sort, desc }
+}
+
+// After continues the query from a previous page's cursor.
+func After(cursor ListCursor) ListOption {
+	return func(q *ListQuery) { q.After = &cursor }
+}
+
+// WithLimit caps the number of tracks returned.
+func WithLimit(limit int) ListOption {
+	return func(q *ListQuery) { q.Limit = limit }
+}
+
+// CursorAfter returns the cursor from which the page following track continues.
+func CursorAfter(track *TripTrack) ListCursor {
+	return ListCursor{ID: track.ID(), StartedAt: track.StartedAt(), DistanceKm: track.TotalDistanceKm()}
+}
@@ -0,0 +1,11 @@
+package tracking
+
+import "time"
+
+// Movement is where and when a trip's runner last moved meaningfully, i.e. left the
+// radius around the previous such position. It is used to detect stalled trips.
+type Movement struct {
+	Latitude  float64
+	Longitude float64
+	At        time.Time
+}
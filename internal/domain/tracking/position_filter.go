@@ -0,0 +1,82 @@
+package tracking
+
+import (
+	"math"
+	"time"
+)
+
+// positionFilterResetGap is how long a gap between fixes restarts a PositionFilter from
+// the next fix, since the runner may have moved anywhere in the meantime.
+const positionFilterResetGap = 2 * time.Minute
+
+// PositionFilter smooths a trip's GPS fixes with a Kalman filter, so a stationary
+// runner's position does not jump around with GPS noise. Each fix's reported speed and
+// heading predict where the runner should be at the next fix, so a moving runner's
+// smoothed position does not lag behind. It is not safe for concurrent use.
+type PositionFilter struct {
+	measurementVar float64 // GPS error, m²
+	processNoise   float64 // m/s of unpredictable movement
+
+	lat, lng    float64
+	variance    float64 // of the estimate, m²
+	speed       float64 // m/s
+	heading     float64 // degrees
+	at          time.Time
+	initialized bool
+}
+
+// NewPositionFilter creates a PositionFilter for fixes accurate to about
+// measurementNoiseM meters, from a runner whose movement deviates from its reported
+// speed and heading by about processNoiseMps meters per second.
+func NewPositionFilter(measurementNoiseM, processNoiseMps float64) *PositionFilter {
+	return &PositionFilter{
+		measurementVar: measurementNoiseM * measurementNoiseM,
+		processNoise:   processNoiseMps,
+	}
+}
+
+// Filter returns wp with its position smoothed. A fix that reports its accuracy is
+// weighted by it instead of the filter's default, so imprecise fixes move the estimate
+// less. A fix recorded before the previous one is returned unchanged and does not affect
+// the filter.
+func (f *PositionFilter) Filter(wp Waypoint) Waypoint {
+	if f.initialized && wp.RecordedAt.Before(f.at) {
+		return wp
+	}
+
+	measurementVar := f.measurementVar
+	if wp.Accuracy > 0 {
+		measurementVar = wp.Accuracy * wp.Accuracy
+	}
+
+	dt := wp.RecordedAt.Sub(f.at).Seconds()
+	if !f.initialized || dt > positionFilterResetGap.Seconds() {
+		f.lat, f.lng = wp.Latitude, wp.Longitude
+		f.variance = measurementVar
+	} else {
+		// Predict: move along the last reported course, growing the uncertainty.
+		if dist := f.speed * dt; dist > 0 {
+			rad := f.heading * math.Pi / 180
+			f.lat += dist * math.Cos(rad) / metersPerDegreeLat
+			f.lng += dist * math.Sin(rad) / (metersPerDegreeLat * math.Cos(f.lat*math.Pi/180))
+		}
+		f.variance += dt * f.processNoise * f.processNoise
+
+		// Update: move towards the fix by the Kalman gain.
+		k := f.variance / (f.variance + measurementVar)
+		f.lat += k * (wp.Latitude - f.lat)
+		f.lng += k * (wp.Longitude - f.lng)
+		f.variance *= 1 - k
+	}
+
+	f.speed = wp.Speed / 3.6
+	f.heading = wp.Heading
+	f.at = wp.RecordedAt
+	f.initialized = true
+
+	wp.Latitude, wp.Longitude = f.lat, f.lng
+	return wp
+}
+
+// metersPerDegreeLat is the length of a degree of latitude.
+const metersPerDegreeLat = earthRadiusM * math.Pi / 180
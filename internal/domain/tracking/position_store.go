@@ -0,0 +1,27 @@
+package tracking
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// LatestPosition is the most recent waypoint received for a trip.
+type LatestPosition struct {
+	BookingID uuid.UUID
+	RunnerID  uuid.UUID
+	Waypoint  Waypoint
+}
+
+// LatestPositionStore caches the most recent position per booking and per runner,
+// so readers do not have to scan the waypoints table.
+type LatestPositionStore interface {
+	// Set records pos as the latest position for its booking and runner.
+	Set(ctx context.Context, pos LatestPosition) error
+
+	// GetByBooking returns the latest position for a booking, or domain.ErrNotFound.
+	GetByBooking(ctx context.Context, bookingID uuid.UUID) (*LatestPosition, error)
+
+	// GetByRunner returns the latest position reported by a runner, or domain.ErrNotFound.
+	GetByRunner(ctx context.Context, runnerID uuid.UUID) (*LatestPosition, error)
+}
@@ -2,6 +2,7 @@ package tracking
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,21 +15,133 @@ type TripTrackRepository interface {
 	// FindByBookingID retrieves a trip track by its associated booking identifier.
 	FindByBookingID(ctx context.Context, bookingID uuid.UUID) (*TripTrack, error)
 
-	// FindActiveByRunnerID retrieves the currently active trip track for a runner.
+	// FindActiveByRunnerID retrieves the oldest active trip track for a runner.
 	FindActiveByRunnerID(ctx context.Context, runnerID uuid.UUID) (*TripTrack, error)
 
+	// FindAllActiveByRunnerID retrieves all active trip tracks for a runner, oldest first.
+	FindAllActiveByRunnerID(ctx context.Context, runnerID uuid.UUID) ([]*TripTrack, error)
+
+	// FindByRunnerIDSince retrieves a runner's trip tracks that were active at any time
+	// since the given moment, oldest first.
+	FindByRunnerIDSince(ctx context.Context, runnerID uuid.UUID, since time.Time) ([]*TripTrack, error)
+
+	// FindRunnerIDsActiveBetween returns the runners with a trip track that was active at
+	// any time between from and to.
+	FindRunnerIDsActiveBetween(ctx context.Context, from, to time.Time) ([]uuid.UUID, error)
+
+	// FindActiveBookingIDsByRegion returns the bookings with an active trip track in a region.
+	FindActiveBookingIDsByRegion(ctx context.Context, region string) ([]uuid.UUID, error)
+
+	// List retrieves the trip tracks matching the query built from opts.
+	List(ctx context.Context, opts ...ListOption) ([]*TripTrack, error)
+
 	// Save persists a new trip track.
 	Save(ctx context.Context, track *TripTrack) error
 
 	// Update persists changes to an existing trip track.
 	Update(ctx context.Context, track *TripTrack) error
 
+	// AdvanceDistance adds the distance from a trip in progress' last position to
+	// waypoint to its running total and makes waypoint the last position, returning the
+	// distance added. It reports false and changes nothing when waypoint was recorded before
+	// the last position or the trip is no longer in progress.
+	AdvanceDistance(ctx context.Context, trackID uuid.UUID, waypoint Waypoint) (float64, bool, error)
+
+	// AddDistance adds km to the running total of a trip in progress, for waypoints
+	// inserted before its last position.
+	AddDistance(ctx context.Context, trackID uuid.UUID, km float64) error
+
+	// ResetDistance overwrites a track's running total and last position, e.g. after
+	// its route was rebuilt. A nil last clears the position.
+	ResetDistance(ctx context.Context, trackID uuid.UUID, km float64, last *Waypoint) error
+
+	// UpdateDeviceStatus records the runner's phone status for a trip track, keeping the
+	// stored value of any field status does not report. A status reported before the
+	// stored one is ignored.
+	UpdateDeviceStatus(ctx context.Context, trackID uuid.UUID, status DeviceStatus) error
+
+	// RecordMovement records that the runner moved meaningfully and clears the trip's
+	// stall mark. A movement older than the stored one is ignored.
+	RecordMovement(ctx context.Context, trackID uuid.UUID, m Movement) error
+
+	// MarkStalled marks an active trip whose runner has not moved since movedBefore as
+	// stalled at at. It reports false if the trip was already marked, has moved since
+	// or is no longer active, so only one caller reports each stall.
+	MarkStalled(ctx context.Context, trackID uuid.UUID, at, movedBefore time.Time) (bool, error)
+
+	// FindStallCandidates retrieves the active trip tracks whose runner has not moved
+	// since movedBefore and that are not marked stalled yet.
+	FindStallCandidates(ctx context.Context, movedBefore time.Time) ([]*TripTrack, error)
+
+	// FindStalled retrieves the active trip tracks marked stalled, longest without
+	// movement first.
+	FindStalled(ctx context.Context) ([]*TripTrack, error)
+
+	// FindCompletedSince retrieves up to limit trip tracks completed at or after since,
+	// oldest completion first.
+	FindCompletedSince(ctx context.Context, since time.Time, limit int) ([]*TripTrack, error)
+
+	// GetFleetStats counts the trips in progress per region, ordered by region.
+	GetFleetStats(ctx context.Context) ([]RegionFleetStats, error)
+
+	// Delete removes a trip track and its waypoints.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// MoveWaypoints moves all waypoints of one trip track to another, rebuilding the
+	// target's chunks so they stay in time order.
+	MoveWaypoints(ctx context.Context, fromTrackID, toTrackID uuid.UUID) error
+
+	// EraseLocations deletes a trip track's waypoints and clears the positions kept on
+	// the track itself (last waypoint, destination and last movement), keeping its
+	// distance and timings. It returns how many waypoints were deleted.
+	EraseLocations(ctx context.Context, trackID uuid.UUID) (int, error)
+
+	// CountWaypoints returns the number of waypoints stored for a trip track.
+	CountWaypoints(ctx context.Context, trackID uuid.UUID) (int, error)
+
+	// DownsampleWaypoints thins all but the keepRecent latest waypoints of a trip track
+	// to every factor-th one, always keeping the first.
+	DownsampleWaypoints(ctx context.Context, trackID uuid.UUID, keepRecent, factor int) error
+
 	// AddWaypoint records a new GPS waypoint for a trip track.
 	AddWaypoint(ctx context.Context, trackID uuid.UUID, waypoint Waypoint) error
 
+	// BackfillWaypoints stores waypoints, in time order, that were recorded earlier than
+	// the track's latest waypoint or long enough ago to no longer count as live, marking
+	// them as backfilled.
+	BackfillWaypoints(ctx context.Context, trackID uuid.UUID, waypoints []Waypoint) error
+
 	// GetWaypoints retrieves all waypoints for a trip track ordered by time.
 	GetWaypoints(ctx context.Context, trackID uuid.UUID) ([]Waypoint, error)
 
+	// ListWaypoints retrieves a page of a trip track's waypoints matching q, ordered by
+	// time and then ID.
+	ListWaypoints(ctx context.Context, trackID uuid.UUID, q WaypointPageQuery) ([]Waypoint, error)
+
+	// EachWaypointBatch calls fn with successive batches of at most batchSize waypoints
+	// of a trip track, in time order, stopping at the first error.
+	EachWaypointBatch(ctx context.Context, trackID uuid.UUID, batchSize int, fn func([]Waypoint) error) error
+
+	// GetWaypointsBetween retrieves the waypoints recorded between from and to, plus the
+	// nearest waypoint on either side, ordered by time. Only the chunks covering the
+	// window are read.
+	GetWaypointsBetween(ctx context.Context, trackID uuid.UUID, from, to time.Time) ([]Waypoint, error)
+
+	// GetChunksInBounds retrieves the waypoint chunks whose bounding box intersects box,
+	// ordered by sequence number.
+	GetChunksInBounds(ctx context.Context, trackID uuid.UUID, box BoundingBox) ([]WaypointChunk, error)
+
 	// GetRouteAsGeoJSON returns the trip route as a GeoJSON LineString.
 	GetRouteAsGeoJSON(ctx context.Context, trackID uuid.UUID) (string, error)
+
+	// GetRouteLengthKm returns the geodesic length in kilometers of the route through
+	// the waypoints recorded in [from, to). A zero from or to leaves that side open.
+	GetRouteLengthKm(ctx context.Context, trackID uuid.UUID, from, to time.Time) (float64, error)
+
+	// GetWaypointStats aggregates a trip's waypoints without loading them.
+	GetWaypointStats(ctx context.Context, trackID uuid.UUID, opts WaypointStatsOptions) (*WaypointStats, error)
+
+	// GetWaypointHeatmap bins the waypoints of all trips matching q into geohash cells,
+	// densest first.
+	GetWaypointHeatmap(ctx context.Context, q HeatmapQuery) ([]HeatmapCell, error)
 }
@@ -0,0 +1,102 @@
+package tracking
+
+import (
+	"math"
+	"time"
+)
+
+const earthRadiusM = 6371000.0
+
+// SmoothWaypoints averages each waypoint's position with its neighbours to remove GPS
+// jitter. Waypoints where the course turns by more than maxTurnDeg are kept as
+// recorded, so corners are not cut. The first and last waypoints are always kept.
+func SmoothWaypoints(waypoints []Waypoint, maxTurnDeg float64) []Waypoint {
+	if len(waypoints) <= 2 {
+		return waypoints
+	}
+
+	result := make([]Waypoint, len(waypoints))
+	copy(result, waypoints)
+	for i := 1; i < len(waypoints)-1; i++ {
+		prev, cur, next := waypoints[i-1], waypoints[i], waypoints[i+1]
+		if headingDelta(bearingDeg(prev, cur), bearingDeg(cur, next)) > maxTurnDeg {
+			continue
+		}
+		result[i].Latitude = (prev.Latitude + 2*cur.Latitude + next.Latitude) / 4
+		result[i].Longitude = (prev.Longitude + 2*cur.Longitude + next.Longitude) / 4
+	}
+	return result
+}
+
+// ResampleWaypoints returns points every intervalM meters along the route through
+// waypoints, plus its last waypoint. Positions, speeds and times are interpolated
+// along each segment, and headings are the course of the segment in degrees from
+// north in [0, 360), regardless of the headings reported by the device.
+func ResampleWaypoints(waypoints []Waypoint, intervalM float64) []Waypoint {
+	if len(waypoints) == 0 || intervalM <= 0 {
+		return nil
+	}
+
+	result := []Waypoint{waypoints[0]}
+	if len(waypoints) == 1 {
+		return result
+	}
+
+	// Distance still to travel from the start of the current segment to the next point.
+	next := intervalM
+	heading := 0.0
+	for i := 1; i < len(waypoints); i++ {
+		a, b := waypoints[i-1], waypoints[i]
+		length := DistanceMeters(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+		if length == 0 {
+			continue
+		}
+		heading = bearingDeg(a, b)
+		if len(result) == 1 {
+			result[0].Heading = heading
+		}
+
+		for next <= length {
+			f := next / length
+			result = append(result, Waypoint{
+				Latitude:   lerp(a.Latitude, b.Latitude, f),
+				Longitude:  lerp(a.Longitude, b.Longitude, f),
+				Speed:      lerp(a.Speed, b.Speed, f),
+				Heading:    heading,
+				RecordedAt: a.RecordedAt.Add(time.Duration(f * float64(b.RecordedAt.Sub(a.RecordedAt)))),
+			})
+			next += intervalM
+		}
+		next -= length
+	}
+
+	last := waypoints[len(waypoints)-1]
+	if prev := result[len(result)-1]; prev.Latitude != last.Latitude || prev.Longitude != last.Longitude {
+		last.Heading = heading
+		result = append(result, last)
+	}
+	return result
+}
+
+// DistanceMeters returns the great-circle distance in meters between two coordinates.
+func DistanceMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return earthRadiusM * 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+// bearingDeg returns the initial course from a to b in degrees from north in [0, 360).
+func bearingDeg(a, b Waypoint) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+	y := math.Sin(dLng) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLng)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// headingDelta returns the absolute difference between two headings in degrees, in [0, 180].
+func headingDelta(a, b float64) float64 {
+	return math.Abs(math.Mod(b-a+540, 360) - 180)
+}
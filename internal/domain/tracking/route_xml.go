@@ -0,0 +1,177 @@
+package tracking
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// xmlTimeLayout formats waypoint times in GPX and KML documents.
+const xmlTimeLayout = "2006-01-02T15:04:05.000Z"
+
+type gpxDocument struct {
+	XMLName  xml.Name    `xml:"gpx"`
+	Version  string      `xml:"version,attr"`
+	Creator  string      `xml:"creator,attr"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	XmlnsTPX string      `xml:"xmlns:gpxtpx,attr"`
+	Metadata gpxMetadata `xml:"metadata"`
+	Track    gpxTrack    `xml:"trk"`
+}
+
+type gpxMetadata struct {
+	Name string `xml:"name"`
+	Time string `xml:"time,omitempty"`
+}
+
+type gpxTrack struct {
+	Name     string       `xml:"name"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat        float64          `xml:"lat,attr"`
+	Lon        float64          `xml:"lon,attr"`
+	Time       string           `xml:"time"`
+	Extensions gpxTPXExtensions `xml:"extensions"`
+}
+
+// gpxTPXExtensions holds the Garmin TrackPointExtension v2 speed (m/s) and course.
+type gpxTPXExtensions struct {
+	Speed  float64 `xml:"gpxtpx:TrackPointExtension>gpxtpx:speed"`
+	Course float64 `xml:"gpxtpx:TrackPointExtension>gpxtpx:course"`
+}
+
+// RouteGPX encodes runs of waypoints as a GPX 1.1 track with one segment per run. Each
+// point carries its time and, as Garmin TrackPointExtension v2 extensions, its speed in
+// m/s and heading.
+func RouteGPX(name string, startedAt time.Time, lines [][]Waypoint) ([]byte, error) {
+	doc := gpxDocument{
+		Version:  "1.1",
+		Creator:  "service-tracking",
+		Xmlns:    "http://www.topografix.com/GPX/1/1",
+		XmlnsTPX: "http://www.garmin.com/xmlschemas/TrackPointExtension/v2",
+		Metadata: gpxMetadata{Name: name, Time: startedAt.UTC().Format(xmlTimeLayout)},
+		Track:    gpxTrack{Name: name, Segments: make([]gpxSegment, len(lines))},
+	}
+	for i, line := range lines {
+		points := make([]gpxPoint, len(line))
+		for j, wp := range line {
+			points[j] = gpxPoint{
+				Lat:  wp.Latitude,
+				Lon:  wp.Longitude,
+				Time: wp.RecordedAt.UTC().Format(xmlTimeLayout),
+				Extensions: gpxTPXExtensions{
+					Speed:  math.Round(wp.Speed/3.6*100) / 100,
+					Course: wp.Heading,
+				},
+			}
+		}
+		doc.Track.Segments[i].Points = points
+	}
+	return marshalXMLDocument(doc, "GPX")
+}
+
+type kmlDocument struct {
+	XMLName  xml.Name        `xml:"kml"`
+	Xmlns    string          `xml:"xmlns,attr"`
+	XmlnsGX  string          `xml:"xmlns:gx,attr"`
+	Document kmlDocumentBody `xml:"Document"`
+}
+
+type kmlDocumentBody struct {
+	Name      string       `xml:"name"`
+	Schema    kmlSchema    `xml:"Schema"`
+	Placemark kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlSchema struct {
+	ID     string                `xml:"id,attr"`
+	Fields []kmlSimpleArrayField `xml:"gx:SimpleArrayField"`
+}
+
+type kmlSimpleArrayField struct {
+	Name        string `xml:"name,attr"`
+	Type        string `xml:"type,attr"`
+	DisplayName string `xml:"displayName"`
+}
+
+type kmlPlacemark struct {
+	Name       string     `xml:"name"`
+	MultiTrack []kmlTrack `xml:"gx:MultiTrack>gx:Track"`
+}
+
+// kmlTrack is a gx:Track, which lists all times before all coordinates.
+type kmlTrack struct {
+	When   []string      `xml:"when"`
+	Coords []string      `xml:"gx:coord"`
+	Data   kmlSchemaData `xml:"ExtendedData>SchemaData"`
+}
+
+type kmlSchemaData struct {
+	SchemaURL string               `xml:"schemaUrl,attr"`
+	Arrays    []kmlSimpleArrayData `xml:"gx:SimpleArrayData"`
+}
+
+type kmlSimpleArrayData struct {
+	Name   string   `xml:"name,attr"`
+	Values []string `xml:"gx:value"`
+}
+
+// RouteKML encodes runs of waypoints as a KML 2.2 placemark holding a gx:MultiTrack
+// with one gx:Track per run. Each point carries its time, and its speed in km/h and
+// heading as extended data.
+func RouteKML(name string, lines [][]Waypoint) ([]byte, error) {
+	doc := kmlDocument{
+		Xmlns:   "http://www.opengis.net/kml/2.2",
+		XmlnsGX: "http://www.google.com/kml/ext/2.2",
+		Document: kmlDocumentBody{
+			Name: name,
+			Schema: kmlSchema{
+				ID: "waypoint",
+				Fields: []kmlSimpleArrayField{
+					{Name: "speed", Type: "float", DisplayName: "Speed (km/h)"},
+					{Name: "heading", Type: "float", DisplayName: "Heading (degrees)"},
+				},
+			},
+			Placemark: kmlPlacemark{Name: name, MultiTrack: make([]kmlTrack, len(lines))},
+		},
+	}
+	for i, line := range lines {
+		track := kmlTrack{
+			When:   make([]string, len(line)),
+			Coords: make([]string, len(line)),
+			Data: kmlSchemaData{
+				SchemaURL: "#waypoint",
+				Arrays: []kmlSimpleArrayData{
+					{Name: "speed", Values: make([]string, len(line))},
+					{Name: "heading", Values: make([]string, len(line))},
+				},
+			},
+		}
+		for j, wp := range line {
+			track.When[j] = wp.RecordedAt.UTC().Format(xmlTimeLayout)
+			track.Coords[j] = strconv.FormatFloat(wp.Longitude, 'f', -1, 64) + " " +
+				strconv.FormatFloat(wp.Latitude, 'f', -1, 64) + " 0"
+			track.Data.Arrays[0].Values[j] = strconv.FormatFloat(wp.Speed, 'f', -1, 64)
+			track.Data.Arrays[1].Values[j] = strconv.FormatFloat(wp.Heading, 'f', -1, 64)
+		}
+		doc.Document.Placemark.MultiTrack[i] = track
+	}
+	return marshalXMLDocument(doc, "KML")
+}
+
+// marshalXMLDocument encodes v as an indented XML document with a declaration.
+func marshalXMLDocument(v interface{}, kind string) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", kind, err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,27 @@
+package tracking
+
+import (
+	"context"
+	"time"
+)
+
+// Route is a road route between two points.
+type Route struct {
+	DistanceKm float64
+	// Duration is the provider's estimated travel time along the route.
+	Duration time.Duration
+}
+
+// RoutingProvider computes road routes, so distances and arrival estimates follow the
+// road network instead of a straight line.
+type RoutingProvider interface {
+	RouteBetween(ctx context.Context, fromLat, fromLng, toLat, toLng float64) (*Route, error)
+}
+
+// MapMatcher snaps noisy GPS waypoints to the road network.
+type MapMatcher interface {
+	// MatchWaypoints returns the waypoints, in order, with their coordinates moved onto
+	// the roads they were most likely recorded on. Waypoints that cannot be matched,
+	// typically outliers, are left out.
+	MatchWaypoints(ctx context.Context, waypoints []Waypoint) ([]Waypoint, error)
+}
@@ -0,0 +1,115 @@
+package tracking
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// SimplifyWaypoints reduces a route with the Douglas-Peucker algorithm, dropping
+// waypoints that lie within tolerance (in degrees) of the simplified line. If maxPoints
+// is at least 2 and the result is still larger, the tolerance is doubled until it fits.
+// The first and last waypoints are always kept.
+func SimplifyWaypoints(waypoints []Waypoint, tolerance float64, maxPoints int) []Waypoint {
+	if len(waypoints) <= 2 {
+		return waypoints
+	}
+
+	result := douglasPeucker(waypoints, tolerance)
+	if maxPoints < 2 || len(result) <= maxPoints {
+		return result
+	}
+
+	if tolerance <= 0 {
+		tolerance = 1e-6
+	}
+	for len(result) > maxPoints {
+		tolerance *= 2
+		result = douglasPeucker(waypoints, tolerance)
+	}
+	return result
+}
+
+// douglasPeucker returns the waypoints kept at the given tolerance, in order.
+func douglasPeucker(waypoints []Waypoint, tolerance float64) []Waypoint {
+	keep := make([]bool, len(waypoints))
+	keep[0], keep[len(waypoints)-1] = true, true
+
+	// Iterative to avoid deep recursion on long trips.
+	type span struct{ first, last int }
+	stack := []span{{0, len(waypoints) - 1}}
+	for len(stack) > 0 {
+		sp := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		maxDist, index := 0.0, -1
+		for i := sp.first + 1; i < sp.last; i++ {
+			if d := perpendicularDistance(waypoints[i], waypoints[sp.first], waypoints[sp.last]); d > maxDist {
+				maxDist, index = d, i
+			}
+		}
+
+		if index >= 0 && maxDist > tolerance {
+			keep[index] = true
+			stack = append(stack, span{sp.first, index}, span{index, sp.last})
+		}
+	}
+
+	result := make([]Waypoint, 0, len(waypoints))
+	for i, k := range keep {
+		if k {
+			result = append(result, waypoints[i])
+		}
+	}
+	return result
+}
+
+// perpendicularDistance returns the distance in degrees from p to the segment a-b,
+// treating longitude/latitude as planar coordinates.
+func perpendicularDistance(p, a, b Waypoint) float64 {
+	dx, dy := b.Longitude-a.Longitude, b.Latitude-a.Latitude
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p.Longitude-a.Longitude, p.Latitude-a.Latitude)
+	}
+
+	t := ((p.Longitude-a.Longitude)*dx + (p.Latitude-a.Latitude)*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p.Longitude-(a.Longitude+t*dx), p.Latitude-(a.Latitude+t*dy))
+}
+
+// LineStringGeoJSON encodes waypoints as a GeoJSON LineString.
+func LineStringGeoJSON(waypoints []Waypoint) (string, error) {
+	coordinates := make([][]float64, len(waypoints))
+	for i, wp := range waypoints {
+		coordinates[i] = []float64{wp.Longitude, wp.Latitude}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"type":        "LineString",
+		"coordinates": coordinates,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal GeoJSON: %w", err)
+	}
+	return string(data), nil
+}
+
+// MultiLineStringGeoJSON encodes each run of waypoints as one line of a GeoJSON MultiLineString.
+func MultiLineStringGeoJSON(lines [][]Waypoint) (string, error) {
+	coordinates := make([][][]float64, len(lines))
+	for i, line := range lines {
+		coordinates[i] = make([][]float64, len(line))
+		for j, wp := range line {
+			coordinates[i][j] = []float64{wp.Longitude, wp.Latitude}
+		}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"type":        "MultiLineString",
+		"coordinates": coordinates,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal GeoJSON: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,30 @@
+package tracking
+
+import "time"
+
+// WaypointStats are aggregates over a trip's waypoints, computed by the database.
+type WaypointStats struct {
+	WaypointCount int
+	// FirstAt and LastAt bound the recorded waypoints; both are zero without waypoints.
+	FirstAt time.Time
+	LastAt  time.Time
+	// DistanceKm is the geodesic length of the route through all waypoints.
+	DistanceKm  float64
+	MaxSpeedKmh float64
+	// Idle is the time spent at or below the stop speed, measured from each stationary
+	// waypoint to the next waypoint.
+	Idle time.Duration
+	// LongestStop is the longest run of consecutive stationary waypoints.
+	LongestStop time.Duration
+	// TimeAtPickup is the time from the first waypoint until the runner first moved
+	// beyond the pickup radius of it.
+	TimeAtPickup time.Duration
+}
+
+// WaypointStatsOptions are the thresholds WaypointStats are computed with.
+type WaypointStatsOptions struct {
+	// StopSpeedKmh is the speed at or below which the runner counts as stationary.
+	StopSpeedKmh float64
+	// PickupRadiusMeters is how far from the first waypoint still counts as the pickup.
+	PickupRadiusMeters float64
+}
@@ -13,10 +13,43 @@ type TrackingStatus string
 
 const (
 	TrackingActive    TrackingStatus = "active"
+	TrackingPaused    TrackingStatus = "paused"
 	TrackingCompleted TrackingStatus = "completed"
 	TrackingCancelled TrackingStatus = "cancelled"
 )
 
+// CancellationReason is a structured code explaining why a trip was cancelled.
+type CancellationReason string
+
+const (
+	CancelReasonOwnerNoShow       CancellationReason = "owner_no_show"
+	CancelReasonPetRefused        CancellationReason = "pet_refused_transport"
+	CancelReasonVehicleBreakdown  CancellationReason = "vehicle_breakdown"
+	CancelReasonRunnerUnavailable CancellationReason = "runner_unavailable"
+	CancelReasonSafetyConcern     CancellationReason = "safety_concern"
+	CancelReasonOther             CancellationReason = "other"
+)
+
+// maxCancellationNoteLength caps the free-text note attached to a cancellation.
+const maxCancellationNoteLength = 500
+
+// IsValid returns true if the cancellation reason is recognized.
+func (r CancellationReason) IsValid() bool {
+	switch r {
+	case CancelReasonOwnerNoShow, CancelReasonPetRefused, CancelReasonVehicleBreakdown,
+		CancelReasonRunnerUnavailable, CancelReasonSafetyConcern, CancelReasonOther:
+		return true
+	}
+	return false
+}
+
+// Cancellation records why and when a trip was cancelled.
+type Cancellation struct {
+	Reason      CancellationReason
+	Note        string
+	CancelledAt time.Time
+}
+
 // Waypoint represents a single GPS point recorded during a trip.
 type Waypoint struct {
 	ID         uuid.UUID
@@ -25,10 +58,19 @@ type Waypoint struct {
 	Speed      float64   // km/h
 	Heading    float64   // degrees
 	RecordedAt time.Time
+	// Accuracy is the horizontal accuracy of the fix in meters, or 0 if not reported.
+	Accuracy float64
+	// Altitude is the height above sea level in meters, or nil if not reported.
+	Altitude *float64
+	// Provider is the location source that produced the fix, or "" if not reported.
+	Provider string
+	// Backfilled is set on waypoints a runner uploaded after regaining connectivity.
+	// They complete the route but were never broadcast as live positions.
+	Backfilled bool
 }
 
-// NewWaypoint creates a validated Waypoint with a generated UUID.
-func NewWaypoint(lat, lng, speed, heading float64, recordedAt time.Time) (Waypoint, error) {
+// NewWaypoint creates a validated Waypoint with the given ID.
+func NewWaypoint(id uuid.UUID, lat, lng, speed, heading float64, recordedAt time.Time) (Waypoint, error) {
 	if lat < -90 || lat > 90 {
 		return Waypoint{}, fmt.Errorf("latitude must be between -90 and 90, got %f", lat)
 	}
@@ -42,7 +84,7 @@ func NewWaypoint(lat, lng, speed, heading float64, recordedAt time.Time) (Waypoi
 		heading = 0
 	}
 	return Waypoint{
-		ID:         uuid.New(),
+		ID:         id,
 		Latitude:   lat,
 		Longitude:  lng,
 		Speed:      speed,
@@ -51,27 +93,78 @@ func NewWaypoint(lat, lng, speed, heading float64, recordedAt time.Time) (Waypoi
 	}, nil
 }
 
+// Location providers a runner app may report for a fix.
+const (
+	ProviderGPS     = "gps"
+	ProviderNetwork = "network"
+	ProviderFused   = "fused"
+)
+
+// WithFix returns w with the quality of its fix: horizontal accuracy in meters (0 if
+// unknown), altitude in meters if known and the provider that produced it ("" if unknown).
+func (w Waypoint) WithFix(accuracy float64, altitude *float64, provider string) (Waypoint, error) {
+	if accuracy < 0 {
+		return Waypoint{}, fmt.Errorf("accuracy must not be negative, got %f", accuracy)
+	}
+	switch provider {
+	case "", ProviderGPS, ProviderNetwork, ProviderFused:
+	default:
+		return Waypoint{}, fmt.Errorf("provider must be one of %s, %s or %s, got %q", ProviderGPS, ProviderNetwork, ProviderFused, provider)
+	}
+	w.Accuracy, w.Altitude, w.Provider = accuracy, altitude, provider
+	return w, nil
+}
+
+// Location is a geographic coordinate pair.
+type Location struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// NewLocation creates a validated Location.
+func NewLocation(lat, lng float64) (Location, error) {
+	if lat < -90 || lat > 90 {
+		return Location{}, fmt.Errorf("latitude must be between -90 and 90, got %f", lat)
+	}
+	if lng < -180 || lng > 180 {
+		return Location{}, fmt.Errorf("longitude must be between -180 and 180, got %f", lng)
+	}
+	return Location{Latitude: lat, Longitude: lng}, nil
+}
+
 // TripTrack is the aggregate root for GPS tracking of a single booking trip.
 type TripTrack struct {
 	id              uuid.UUID
 	bookingID       uuid.UUID
 	runnerID        uuid.UUID
+	region          string
 	status          TrackingStatus
 	totalDistanceKm float64
+	destination     *Location
 	startedAt       time.Time
 	completedAt     *time.Time
+	cancellation    *Cancellation
+	weather         *TripWeather
+	pausedAt        *time.Time
+	pausedDuration  time.Duration
+	device          *DeviceStatus
+	movement        *Movement
+	stalledAt       *time.Time
 	version         int64
 	createdAt       time.Time
 	updatedAt       time.Time
 }
 
-// NewTripTrack creates a new active TripTrack for a booking.
-func NewTripTrack(bookingID, runnerID uuid.UUID) *TripTrack {
-	now := time.Now().UTC()
+// NewTripTrack creates a new active TripTrack for a booking, started at now. region
+// identifies the regional Kafka topic the booking arrived on and is empty for the
+// global topic.
+func NewTripTrack(id, bookingID, runnerID uuid.UUID, region string, now time.Time) *TripTrack {
+	now = now.UTC()
 	return &TripTrack{
-		id:              uuid.New(),
+		id:              id,
 		bookingID:       bookingID,
 		runnerID:        runnerID,
+		region:          region,
 		status:          TrackingActive,
 		totalDistanceKm: 0,
 		startedAt:       now,
@@ -93,18 +186,47 @@ func (t *TripTrack) BookingID() uuid.UUID { return t.bookingID }
 // RunnerID returns the associated runner identifier.
 func (t *TripTrack) RunnerID() uuid.UUID { return t.runnerID }
 
+// Region returns the region the trip belongs to (empty for the global topic).
+func (t *TripTrack) Region() string { return t.region }
+
 // Status returns the current tracking status.
 func (t *TripTrack) Status() TrackingStatus { return t.status }
 
 // TotalDistanceKm returns the total distance traveled in kilometers.
 func (t *TripTrack) TotalDistanceKm() float64 { return t.totalDistanceKm }
 
+// Destination returns the trip's drop-off location (nil if not yet known).
+func (t *TripTrack) Destination() *Location { return t.destination }
+
+// DeviceStatus returns the runner's phone status as last reported (nil if never).
+func (t *TripTrack) DeviceStatus() *DeviceStatus { return t.device }
+
+// LastMovement returns where and when the runner last moved meaningfully (nil if no
+// location was recorded yet).
+func (t *TripTrack) LastMovement() *Movement { return t.movement }
+
+// StalledAt returns when the trip was reported stalled, or nil if the runner has moved
+// since.
+func (t *TripTrack) StalledAt() *time.Time { return t.stalledAt }
+
 // StartedAt returns when tracking began.
 func (t *TripTrack) StartedAt() time.Time { return t.startedAt }
 
 // CompletedAt returns when tracking ended (nil if still active).
 func (t *TripTrack) CompletedAt() *time.Time { return t.completedAt }
 
+// Cancellation returns the cancellation details (nil unless cancelled).
+func (t *TripTrack) Cancellation() *Cancellation { return t.cancellation }
+
+// Weather returns the weather captured at the start and end of the trip, or nil.
+func (t *TripTrack) Weather() *TripWeather { return t.weather }
+
+// PausedAt returns when the current pause began (nil unless paused).
+func (t *TripTrack) PausedAt() *time.Time { return t.pausedAt }
+
+// PausedDuration returns the total time spent in completed pauses.
+func (t *TripTrack) PausedDuration() time.Duration { return t.pausedDuration }
+
 // Version returns the version for optimistic locking.
 func (t *TripTrack) Version() int64 { return t.version }
 
@@ -116,12 +238,13 @@ func (t *TripTrack) UpdatedAt() time.Time { return t.updatedAt }
 
 // --- Behavior ---
 
-// Complete transitions the trip track from active to completed.
-func (t *TripTrack) Complete(totalDistanceKm float64) error {
-	if t.status != TrackingActive {
+// Complete transitions the trip track from active or paused to completed at now.
+func (t *TripTrack) Complete(totalDistanceKm float64, now time.Time) error {
+	if !t.IsActive() {
 		return domain.NewInvalidStateError(string(t.status), string(TrackingCompleted))
 	}
-	now := time.Now().UTC()
+	now = now.UTC()
+	t.endPause(now)
 	t.status = TrackingCompleted
 	t.totalDistanceKm = totalDistanceKm
 	t.completedAt = &now
@@ -129,26 +252,141 @@ func (t *TripTrack) Complete(totalDistanceKm float64) error {
 	return nil
 }
 
-// Cancel transitions the trip track from active to cancelled.
-func (t *TripTrack) Cancel() error {
-	if t.status != TrackingActive {
+// Cancel transitions the trip track from active or paused to cancelled at now with a
+// structured reason. A note is required when the reason is CancelReasonOther.
+func (t *TripTrack) Cancel(reason CancellationReason, note string, now time.Time) error {
+	if !t.IsActive() {
 		return domain.NewInvalidStateError(string(t.status), string(TrackingCancelled))
 	}
-	now := time.Now().UTC()
+	if !reason.IsValid() {
+		return fmt.Errorf("invalid cancellation reason: %s", reason)
+	}
+	if reason == CancelReasonOther && note == "" {
+		return fmt.Errorf("a note is required when the cancellation reason is %s", CancelReasonOther)
+	}
+	if len(note) > maxCancellationNoteLength {
+		return fmt.Errorf("cancellation note must be at most %d characters", maxCancellationNoteLength)
+	}
+	now = now.UTC()
+	t.endPause(now)
 	t.status = TrackingCancelled
+	t.cancellation = &Cancellation{
+		Reason:      reason,
+		Note:        note,
+		CancelledAt: now,
+	}
 	t.updatedAt = now
 	return nil
 }
 
+// SetDestination records the drop-off location for an active or paused trip.
+func (t *TripTrack) SetDestination(dest Location, now time.Time) error {
+	if !t.IsActive() {
+		return domain.NewInvalidStateError(string(t.status), string(TrackingActive))
+	}
+	t.destination = &dest
+	t.updatedAt = now.UTC()
+	return nil
+}
+
+// Pause transitions the trip track from active to paused at now, e.g. while the
+// runner takes a break.
+func (t *TripTrack) Pause(now time.Time) error {
+	if t.status != TrackingActive {
+		return domain.NewInvalidStateError(string(t.status), string(TrackingPaused))
+	}
+	now = now.UTC()
+	t.status = TrackingPaused
+	t.pausedAt = &now
+	t.updatedAt = now
+	return nil
+}
+
+// Resume transitions the trip track from paused back to active at now, adding the
+// pause to the paused duration.
+func (t *TripTrack) Resume(now time.Time) error {
+	if t.status != TrackingPaused {
+		return domain.NewInvalidStateError(string(t.status), string(TrackingActive))
+	}
+	now = now.UTC()
+	t.endPause(now)
+	t.status = TrackingActive
+	t.updatedAt = now
+	return nil
+}
+
+// endPause closes the current pause, if any, at now.
+func (t *TripTrack) endPause(now time.Time) {
+	if t.pausedAt == nil {
+		return
+	}
+	if now.After(*t.pausedAt) {
+		t.pausedDuration += now.Sub(*t.pausedAt)
+	}
+	t.pausedAt = nil
+}
+
+// Duration returns how long the trip has been tracked, excluding time spent paused.
+// Ongoing trips and pauses are measured up to now.
+func (t *TripTrack) Duration(now time.Time) time.Duration {
+	end := now.UTC()
+	if t.completedAt != nil {
+		end = *t.completedAt
+	} else if t.cancellation != nil {
+		end = t.cancellation.CancelledAt
+	}
+	paused := t.pausedDuration
+	if t.pausedAt != nil && end.After(*t.pausedAt) {
+		paused += end.Sub(*t.pausedAt)
+	}
+	if d := end.Sub(t.startedAt) - paused; d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Merge absorbs a duplicate track of the same delivery, e.g. one created by a
+// duplicated booking event, after its waypoints were moved to this track. The merged
+// trip starts when the earlier of the two started, travelled totalDistanceKm, and keeps
+// this track's status.
+func (t *TripTrack) Merge(duplicate *TripTrack, totalDistanceKm float64, now time.Time) error {
+	if duplicate.id == t.id {
+		return fmt.Errorf("cannot merge trip track %s into itself", t.id)
+	}
+	if duplicate.runnerID != t.runnerID {
+		return fmt.Errorf("trip tracks %s and %s belong to different runners", t.id, duplicate.id)
+	}
+	if duplicate.startedAt.Before(t.startedAt) {
+		t.startedAt = duplicate.startedAt
+	}
+	if t.destination == nil {
+		t.destination = duplicate.destination
+	}
+	t.totalDistanceKm = totalDistanceKm
+	t.updatedAt = now.UTC()
+	return nil
+}
+
+// RecordWeather stores the weather captured at the start and end of the trip.
+func (t *TripTrack) RecordWeather(w TripWeather, now time.Time) {
+	t.weather = &w
+	t.updatedAt = now.UTC()
+}
+
 // IncrementVersion bumps the version for optimistic locking.
-func (t *TripTrack) IncrementVersion() {
+func (t *TripTrack) IncrementVersion(now time.Time) {
 	t.version++
-	t.updatedAt = time.Now().UTC()
+	t.updatedAt = now.UTC()
 }
 
-// IsActive returns true if the trip track is currently active.
+// IsActive returns true if the trip track is in progress, including while paused.
 func (t *TripTrack) IsActive() bool {
-	return t.status == TrackingActive
+	return t.status == TrackingActive || t.status == TrackingPaused
+}
+
+// IsPaused returns true if the trip track is paused.
+func (t *TripTrack) IsPaused() bool {
+	return t.status == TrackingPaused
 }
 
 // --- Reconstruction from persistence ---
@@ -156,10 +394,19 @@ func (t *TripTrack) IsActive() bool {
 // Reconstruct creates a TripTrack from persisted data (used by repositories).
 func Reconstruct(
 	id, bookingID, runnerID uuid.UUID,
+	region string,
 	status TrackingStatus,
 	totalDistanceKm float64,
+	destination *Location,
 	startedAt time.Time,
 	completedAt *time.Time,
+	cancellation *Cancellation,
+	weather *TripWeather,
+	pausedAt *time.Time,
+	pausedDuration time.Duration,
+	device *DeviceStatus,
+	movement *Movement,
+	stalledAt *time.Time,
 	version int64,
 	createdAt, updatedAt time.Time,
 ) *TripTrack {
@@ -167,10 +414,19 @@ func Reconstruct(
 		id:              id,
 		bookingID:       bookingID,
 		runnerID:        runnerID,
+		region:          region,
 		status:          status,
 		totalDistanceKm: totalDistanceKm,
+		destination:     destination,
 		startedAt:       startedAt,
 		completedAt:     completedAt,
+		cancellation:    cancellation,
+		weather:         weather,
+		pausedAt:        pausedAt,
+		pausedDuration:  pausedDuration,
+		device:          device,
+		movement:        movement,
+		stalledAt:       stalledAt,
 		version:         version,
 		createdAt:       createdAt,
 		updatedAt:       updatedAt,
@@ -0,0 +1,56 @@
+package tracking
+
+import "math"
+
+const (
+	// viewportPaddingRatio is the fraction of each span added on every side of the box.
+	viewportPaddingRatio = 0.1
+
+	// minViewportSpanDeg keeps the box from collapsing to a point (~500m at the equator).
+	minViewportSpanDeg = 0.005
+)
+
+// BoundingBox is a suggested map viewport in WGS84 degrees.
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// ComputeViewport returns a padded bounding box covering all given locations,
+// or false if there are none.
+func ComputeViewport(points []Location) (BoundingBox, bool) {
+	if len(points) == 0 {
+		return BoundingBox{}, false
+	}
+
+	box := BoundingBox{
+		MinLatitude:  points[0].Latitude,
+		MinLongitude: points[0].Longitude,
+		MaxLatitude:  points[0].Latitude,
+		MaxLongitude: points[0].Longitude,
+	}
+	for _, p := range points[1:] {
+		box.MinLatitude = math.Min(box.MinLatitude, p.Latitude)
+		box.MinLongitude = math.Min(box.MinLongitude, p.Longitude)
+		box.MaxLatitude = math.Max(box.MaxLatitude, p.Latitude)
+		box.MaxLongitude = math.Max(box.MaxLongitude, p.Longitude)
+	}
+
+	box.MinLatitude, box.MaxLatitude = padSpan(box.MinLatitude, box.MaxLatitude, -90, 90)
+	box.MinLongitude, box.MaxLongitude = padSpan(box.MinLongitude, box.MaxLongitude, -180, 180)
+	return box, true
+}
+
+// padSpan widens [lo, hi] to at least minViewportSpanDeg, adds padding on both
+// sides and clamps the result to [floor, ceil].
+func padSpan(lo, hi, floor, ceil float64) (float64, float64) {
+	if span := hi - lo; span < minViewportSpanDeg {
+		grow := (minViewportSpanDeg - span) / 2
+		lo -= grow
+		hi += grow
+	}
+	pad := (hi - lo) * viewportPaddingRatio
+	return math.Max(lo-pad, floor), math.Min(hi+pad, ceil)
+}
@@ -0,0 +1,58 @@
+package tracking
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WaypointCursor is the position of the last waypoint of a page, from which the next
+// page continues. Waypoints are ordered by RecordedAt, with ties broken by ID.
+type WaypointCursor struct {
+	ID         uuid.UUID `json:"id"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// WaypointPageQuery selects a page of a trip's waypoints for ListWaypoints. A zero From
+// or To leaves that side of the [From, To) window open, and a nil After starts at the
+// first waypoint in the window. A non-positive Limit returns the whole window.
+type WaypointPageQuery struct {
+	From  time.Time
+	To    time.Time
+	After *WaypointCursor
+	Limit int
+}
+
+// Includes reports whether wp falls inside the query's window and after its cursor.
+func (q WaypointPageQuery) Includes(wp Waypoint) bool {
+	if !q.From.IsZero() && wp.RecordedAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !wp.RecordedAt.Before(q.To) {
+		return false
+	}
+	return q.After == nil || q.After.Before(wp)
+}
+
+// Before reports whether the cursor sorts before wp.
+func (c WaypointCursor) Before(wp Waypoint) bool {
+	if !c.RecordedAt.Equal(wp.RecordedAt) {
+		return c.RecordedAt.Before(wp.RecordedAt)
+	}
+	return bytesLess(c.ID[:], wp.ID[:])
+}
+
+// CursorAfterWaypoint returns the cursor from which the page following wp continues.
+func CursorAfterWaypoint(wp Waypoint) WaypointCursor {
+	return WaypointCursor{ID: wp.ID, RecordedAt: wp.RecordedAt}
+}
+
+// bytesLess compares UUIDs byte by byte, as PostgreSQL orders them.
+func bytesLess(a, b []byte) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package tracking
+
+import (
+	"context"
+	"time"
+)
+
+// Weather is the weather observed near a point of a trip.
+type Weather struct {
+	TemperatureC float64
+	FeelsLikeC   float64
+	HumidityPct  float64
+	// Condition is the provider's short description, e.g. "clear" or "rain".
+	Condition  string
+	ObservedAt time.Time
+}
+
+// TripWeather holds the weather at the start and the end of a trip. Either side is
+// nil if it could not be captured.
+type TripWeather struct {
+	Start *Weather
+	End   *Weather
+}
+
+// WeatherProvider looks up the weather at a location and time.
+type WeatherProvider interface {
+	WeatherAt(ctx context.Context, lat, lng float64, at time.Time) (*Weather, error)
+}
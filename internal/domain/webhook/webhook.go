@@ -0,0 +1,172 @@
+// Package webhook holds the callback URLs partners such as pet hotels and vets register
+// to be told of a booking's tracking milestones, and the deliveries made to them.
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is a tracking milestone delivered to webhooks.
+type Event string
+
+// Webhook events.
+const (
+	// EventStarted is sent when a runner accepts the booking and tracking starts.
+	EventStarted Event = "tracking.started"
+	// EventArrived is sent when the runner enters the pickup or drop-off geofence.
+	EventArrived Event = "tracking.arrived"
+	// EventCompleted is sent when the delivery is confirmed.
+	EventCompleted Event = "tracking.completed"
+)
+
+// Events are all webhook events, which a webhook registered without events receives.
+var Events = []Event{EventStarted, EventArrived, EventCompleted}
+
+// secretPrefix marks webhook signing secrets, so they are recognizable in configs.
+const secretPrefix = "whsec_"
+
+// Webhook is a partner's callback URL for the tracking milestones of one booking or of
+// every booking of an account.
+type Webhook struct {
+	ID uuid.UUID
+	// AccountID owns the webhook. An account webhook covers the bookings AccountID owns.
+	AccountID uuid.UUID
+	// BookingID limits the webhook to one booking; nil for an account webhook.
+	BookingID *uuid.UUID
+	URL       string
+	// Secret signs every delivery, so the partner can verify it came from the service.
+	Secret    string
+	Events    []Event
+	CreatedAt time.Time
+}
+
+// New creates a webhook with a new signing secret. events defaults to all events.
+func New(accountID uuid.UUID, bookingID *uuid.UUID, callbackURL string, events []Event, now time.Time) (*Webhook, error) {
+	u, err := url.Parse(callbackURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("url must be an absolute https URL")
+	}
+	if len(events) == 0 {
+		events = Events
+	}
+	seen := make(map[Event]bool, len(events))
+	unique := make([]Event, 0, len(events))
+	for _, e := range events {
+		if !e.valid() {
+			return nil, fmt.Errorf("unknown event %q", e)
+		}
+		if !seen[e] {
+			seen[e] = true
+			unique = append(unique, e)
+		}
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	return &Webhook{
+		ID:        uuid.New(),
+		AccountID: accountID,
+		BookingID: bookingID,
+		URL:       callbackURL,
+		Secret:    secret,
+		Events:    unique,
+		CreatedAt: now,
+	}, nil
+}
+
+// Subscribes reports whether the webhook receives e.
+func (w *Webhook) Subscribes(e Event) bool {
+	for _, s := range w.Events {
+		if s == e {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Event) valid() bool {
+	for _, v := range Events {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+// DeliveryStatus is the state of a delivery.
+type DeliveryStatus string
+
+// Delivery statuses.
+const (
+	// DeliveryPending is a delivery not yet acknowledged, waiting for its next attempt.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliveryDelivered is a delivery the partner acknowledged with a 2xx response.
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryFailed is a delivery given up on after its last attempt failed.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is one event sent to one webhook, retried until it is acknowledged or runs
+// out of attempts.
+type Delivery struct {
+	ID        uuid.UUID
+	WebhookID uuid.UUID
+	BookingID uuid.UUID
+	Event     Event
+	// Payload is the JSON body posted on every attempt.
+	Payload  []byte
+	Status   DeliveryStatus
+	Attempts int
+	// NextAttemptAt is when a pending delivery is attempted next. While an attempt is
+	// in flight it is pushed out, so no other instance attempts it too.
+	NextAttemptAt time.Time
+	// LastStatusCode is the HTTP status of the last attempt; 0 if it got no response.
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// Repository defines persistence operations for webhooks and their deliveries.
+type Repository interface {
+	Save(ctx context.Context, w *Webhook) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Webhook, error)
+	// FindByAccountID returns the webhooks an account owns, oldest first.
+	FindByAccountID(ctx context.Context, accountID uuid.UUID) ([]*Webhook, error)
+	// FindForBooking returns the webhooks of a booking and, unless ownerID is uuid.Nil,
+	// the account webhooks of its owner.
+	FindForBooking(ctx context.Context, bookingID, ownerID uuid.UUID) ([]*Webhook, error)
+	// Delete removes a webhook and its deliveries, returning domain.ErrNotFound if it
+	// does not exist.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	SaveDelivery(ctx context.Context, d *Delivery) error
+	UpdateDelivery(ctx context.Context, d *Delivery) error
+	// FindDueDeliveries returns up to limit pending deliveries due at now, oldest first.
+	FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*Delivery, error)
+	// ClaimDelivery pushes a due pending delivery's next attempt to until, unless it was
+	// attempted or claimed since it was read with attempts, and reports whether it did.
+	ClaimDelivery(ctx context.Context, id uuid.UUID, attempts int, now, until time.Time) (bool, error)
+	// FindDeliveries returns a webhook's latest deliveries, newest first.
+	FindDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]*Delivery, error)
+	// DeleteFinishedDeliveries removes the delivered and failed deliveries created
+	// before cutoff, returning how many were removed.
+	DeleteFinishedDeliveries(ctx context.Context, cutoff time.Time) (int64, error)
+}
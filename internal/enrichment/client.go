@@ -0,0 +1,74 @@
+// Package enrichment is a client for the enrichment service, which supplies context
+// such as weather conditions for trip locations.
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// Client calls the enrichment service over HTTP.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient creates a Client for the service at baseURL. Each request is bounded by timeout.
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// weatherResponse is the body of GET /v1/weather.
+type weatherResponse struct {
+	TemperatureC float64   `json:"temperature_c"`
+	FeelsLikeC   float64   `json:"feels_like_c"`
+	HumidityPct  float64   `json:"humidity_pct"`
+	Condition    string    `json:"condition"`
+	ObservedAt   time.Time `json:"observed_at"`
+}
+
+// WeatherAt returns the weather at a location and time.
+func (c *Client) WeatherAt(ctx context.Context, lat, lng float64, at time.Time) (*trackingDomain.Weather, error) {
+	q := url.Values{}
+	q.Set("latitude", strconv.FormatFloat(lat, 'f', -1, 64))
+	q.Set("longitude", strconv.FormatFloat(lng, 'f', -1, 64))
+	q.Set("at", at.UTC().Format(time.RFC3339))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v1/weather?"+q.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build weather request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("weather request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("weather request failed: status %d", resp.StatusCode)
+	}
+
+	var body weatherResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode weather response: %w", err)
+	}
+	if body.ObservedAt.IsZero() {
+		body.ObservedAt = at.UTC()
+	}
+
+	w := trackingDomain.Weather(body)
+	return &w, nil
+}
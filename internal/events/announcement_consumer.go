@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	kafkaLib "github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+	kafkaGo "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// AnnouncementConsumer delivers announcements to this instance's WebSocket clients. Its
+// group is unique per instance so every instance receives every announcement, and it
+// starts at the newest offset so a restart does not replay old announcements.
+type AnnouncementConsumer struct {
+	reader  messageReader
+	service *application.AnnouncementService
+	logger  *zap.Logger
+}
+
+// NewAnnouncementConsumer creates a consumer of application.AnnouncementTopic. groupID
+// must be unique to the instance.
+func NewAnnouncementConsumer(brokers []string, groupID string, service *application.AnnouncementService, logger *zap.Logger) *AnnouncementConsumer {
+	return &AnnouncementConsumer{
+		reader: kafkaGo.NewReader(kafkaGo.ReaderConfig{
+			Brokers:     brokers,
+			GroupID:     groupID,
+			Topic:       application.AnnouncementTopic,
+			StartOffset: kafkaGo.LastOffset,
+		}),
+		service: service,
+		logger:  logger.With(zap.String("component", "announcement_consumer")),
+	}
+}
+
+// NewLocalAnnouncementConsumer creates a consumer of application.AnnouncementTopic on a
+// LocalBus, for standalone mode.
+func NewLocalAnnouncementConsumer(bus *LocalBus, service *application.AnnouncementService, logger *zap.Logger) *AnnouncementConsumer {
+	return &AnnouncementConsumer{
+		reader:  bus.Subscribe(application.AnnouncementTopic),
+		service: service,
+		logger:  logger.With(zap.String("component", "announcement_consumer")),
+	}
+}
+
+// Start consumes announcements until ctx is cancelled.
+func (c *AnnouncementConsumer) Start(ctx context.Context) {
+	c.logger.Info("starting announcement consumer")
+	for {
+		msg, err := c.reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Warn("failed to read announcement", zap.Error(err))
+			time.Sleep(time.Second)
+			continue
+		}
+
+		cloudEvent, err := kafkaLib.ParseCloudEvent(msg.Value)
+		if err != nil {
+			c.logger.Error("failed to parse announcement event", zap.Error(err))
+			continue
+		}
+		var announcement application.AnnouncementDTO
+		if err := cloudEvent.ParseData(&announcement); err != nil {
+			c.logger.Error("failed to parse announcement", zap.Error(err))
+			continue
+		}
+		c.service.Deliver(announcement)
+	}
+}
+
+// Close stops the consumer.
+func (c *AnnouncementConsumer) Close() error {
+	return c.reader.Close()
+}
@@ -0,0 +1,160 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// lagTimeout bounds one round of lag measurements.
+const lagTimeout = 10 * time.Second
+
+// LagMonitor periodically measures how far a consumer set's groups are behind their
+// topics and exports it for autoscalers. Every instance of a group reports the same
+// group-wide lag.
+type LagMonitor struct {
+	migrator *GroupMigrator
+	groups   []groupTopic
+	interval time.Duration
+	logger   *zap.Logger
+
+	// catchUpLag and onCatchUp report when a group falls catchUpLag messages behind
+	// and when all of them are back under half of that.
+	catchUpLag int64
+	onCatchUp  func(catchingUp bool)
+	catchingUp bool
+
+	mu  sync.Mutex
+	lag map[groupTopic]map[int]int64 // by partition
+}
+
+// NewLagMonitor creates a LagMonitor for the groups of set, measured every interval.
+func NewLagMonitor(migrator *GroupMigrator, set *ConsumerSet, interval time.Duration, logger *zap.Logger) *LagMonitor {
+	return &LagMonitor{
+		migrator: migrator,
+		groups:   set.groups,
+		interval: interval,
+		logger:   logger,
+		lag:      make(map[groupTopic]map[int]int64),
+	}
+}
+
+// UseCatchUp calls notify with true once any group is threshold messages behind, and
+// with false once every group is back under half of that. Must be called before Run.
+func (m *LagMonitor) UseCatchUp(threshold int64, notify func(catchingUp bool)) {
+	m.catchUpLag = threshold
+	m.onCatchUp = notify
+}
+
+// Run measures lag until ctx is cancelled.
+func (m *LagMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.measure(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// measure refreshes the lag of every group. A group whose lag cannot be read keeps its
+// last value.
+func (m *LagMonitor) measure(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, lagTimeout)
+	defer cancel()
+
+	for _, g := range m.groups {
+		lag, err := m.migrator.PartitionLag(ctx, g.groupID, g.topic)
+		if err != nil {
+			if ctx.Err() == nil {
+				m.logger.Warn("failed to measure consumer lag",
+					zap.String("group_id", g.groupID),
+					zap.String("topic", g.topic),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+		m.mu.Lock()
+		m.lag[g] = lag
+		m.mu.Unlock()
+	}
+	m.checkCatchUp()
+}
+
+// checkCatchUp notifies when the consumers start or finish catching up on a backlog.
+func (m *LagMonitor) checkCatchUp() {
+	if m.onCatchUp == nil {
+		return
+	}
+
+	var worst int64
+	m.mu.Lock()
+	for _, lag := range m.lag {
+		worst = max(worst, sumLag(lag))
+	}
+	m.mu.Unlock()
+
+	switch {
+	case !m.catchingUp && worst >= m.catchUpLag:
+		m.catchingUp = true
+		m.logger.Warn("consumers are catching up on a backlog", zap.Int64("lag", worst))
+	case m.catchingUp && worst < m.catchUpLag/2:
+		m.catchingUp = false
+		m.logger.Info("consumers caught up", zap.Int64("lag", worst))
+	default:
+		return
+	}
+	m.onCatchUp(m.catchingUp)
+}
+
+// sumLag adds up the lag of a group's partitions.
+func sumLag(lag map[int]int64) int64 {
+	var total int64
+	for _, behind := range lag {
+		total += behind
+	}
+	return total
+}
+
+// ServeHTTP exports the lag of each group and each of its partitions in the Prometheus
+// text format.
+func (m *LagMonitor) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP tracking_kafka_consumer_lag Messages a consumer group has yet to consume, summed over partitions.")
+	fmt.Fprintln(w, "# TYPE tracking_kafka_consumer_lag gauge")
+	for _, g := range m.groups {
+		lag, ok := m.lag[g]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "tracking_kafka_consumer_lag{group=%q,topic=%q} %d\n", g.groupID, g.topic, sumLag(lag))
+	}
+
+	fmt.Fprintln(w, "# HELP tracking_kafka_consumer_partition_lag Messages a consumer group has yet to consume from a partition.")
+	fmt.Fprintln(w, "# TYPE tracking_kafka_consumer_partition_lag gauge")
+	for _, g := range m.groups {
+		lag := m.lag[g]
+		partitions := make([]int, 0, len(lag))
+		for p := range lag {
+			partitions = append(partitions, p)
+		}
+		slices.Sort(partitions)
+		for _, p := range partitions {
+			fmt.Fprintf(w, "tracking_kafka_consumer_partition_lag{group=%q,topic=%q,partition=\"%d\"} %d\n", g.groupID, g.topic, p, lag[p])
+		}
+	}
+}
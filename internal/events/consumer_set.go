@@ -0,0 +1,135 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+	"go.uber.org/zap"
+)
+
+// groupTopic pairs a consumer group with the topic it consumes.
+type groupTopic struct {
+	groupID string
+	topic   string
+}
+
+// ConsumerSet is the booking and runner consumers for one consumer group prefix,
+// one pair per region (or a single global pair when no regions are configured).
+type ConsumerSet struct {
+	groupPrefix string
+	booking     []*BookingEventConsumer
+	runner      []*RunnerEventConsumer
+	groups      []groupTopic
+	logger      *zap.Logger
+}
+
+// NewConsumerSet creates booking and runner consumers for every region under groupPrefix.
+// Each runner consumer processes events on runnerWorkers workers.
+func NewConsumerSet(
+	brokers []string,
+	groupPrefix string,
+	regions []string,
+	runnerWorkers int,
+	service *application.TrackingService,
+	logger *zap.Logger,
+) *ConsumerSet {
+	if len(regions) == 0 {
+		regions = []string{""}
+	}
+
+	set := &ConsumerSet{groupPrefix: groupPrefix, logger: logger}
+	for _, region := range regions {
+		groupSuffix := ""
+		if region != "" {
+			groupSuffix = "-" + region
+		}
+
+		bookingGroup := groupPrefix + "-booking-consumer" + groupSuffix
+		set.booking = append(set.booking, NewBookingEventConsumer(brokers, bookingGroup, region, service, logger))
+		set.groups = append(set.groups, groupTopic{bookingGroup, RegionalTopic(events.TopicBookingEvents, region)})
+
+		runnerGroup := groupPrefix + "-runner-consumer" + groupSuffix
+		set.runner = append(set.runner, NewRunnerEventConsumer(brokers, runnerGroup, region, runnerWorkers, service, logger))
+		set.groups = append(set.groups, groupTopic{runnerGroup, RegionalTopic(events.TopicRunnerEvents, region)})
+	}
+	return set
+}
+
+// NewLocalConsumerSet creates booking and runner consumers of the global topics on a
+// LocalBus, for standalone mode.
+func NewLocalConsumerSet(bus *LocalBus, service *application.TrackingService, logger *zap.Logger) *ConsumerSet {
+	set := &ConsumerSet{groupPrefix: "local", logger: logger}
+	set.booking = append(set.booking, &BookingEventConsumer{
+		consumer: bus.Subscribe(events.TopicBookingEvents),
+		service:  service,
+		groupID:  "local-booking-consumer",
+		logger:   logger.With(zap.String("topic", events.TopicBookingEvents)),
+	})
+	set.runner = append(set.runner, &RunnerEventConsumer{
+		consumer: bus.Subscribe(events.TopicRunnerEvents),
+		service:  service,
+		groupID:  "local-runner-consumer",
+		logger:   logger.With(zap.String("topic", events.TopicRunnerEvents)),
+	})
+	return set
+}
+
+// UseDeduplicator shares a Deduplicator across all consumers in the set.
+func (s *ConsumerSet) UseDeduplicator(d *Deduplicator) {
+	for _, c := range s.booking {
+		c.UseDeduplicator(d)
+	}
+	for _, c := range s.runner {
+		c.UseDeduplicator(d)
+	}
+}
+
+// UseDeadLetter makes every consumer in the set dead-letter messages that keep failing.
+func (s *ConsumerSet) UseDeadLetter(p *DeadLetterPublisher) {
+	for _, c := range s.booking {
+		c.UseDeadLetter(p)
+	}
+	for _, c := range s.runner {
+		c.UseDeadLetter(p)
+	}
+}
+
+// Seed commits starting offsets at startAt for every group in the set that has none yet.
+func (s *ConsumerSet) Seed(ctx context.Context, migrator *GroupMigrator, startAt time.Time) error {
+	for _, g := range s.groups {
+		if _, err := migrator.SeedGroup(ctx, g.groupID, g.topic, startAt, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start runs every consumer in its own goroutine until the context is cancelled.
+func (s *ConsumerSet) Start(ctx context.Context) {
+	for _, c := range s.booking {
+		go func() {
+			if err := c.Start(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error("booking event consumer error", zap.Error(err))
+			}
+		}()
+	}
+	for _, c := range s.runner {
+		go func() {
+			if err := c.Start(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error("runner event consumer error", zap.Error(err))
+			}
+		}()
+	}
+}
+
+// Close shuts down every consumer in the set.
+func (s *ConsumerSet) Close() {
+	for _, c := range s.booking {
+		_ = c.Close()
+	}
+	for _, c := range s.runner {
+		_ = c.Close()
+	}
+}
@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	kafkaGo "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// TopicDeadLetter receives messages that could not be processed after all attempts.
+const TopicDeadLetter = "tracking.dlq"
+
+// Headers added to dead-lettered messages, next to the original message's headers.
+const (
+	headerDLQTopic     = "dlq-original-topic"
+	headerDLQPartition = "dlq-original-partition"
+	headerDLQOffset    = "dlq-original-offset"
+	headerDLQGroup     = "dlq-consumer-group"
+	headerDLQError     = "dlq-error"
+	headerDLQAttempts  = "dlq-attempts"
+	headerDLQFailedAt  = "dlq-failed-at"
+)
+
+// deadLetterBackoff is the delay before the second attempt; it doubles per attempt.
+const deadLetterBackoff = 200 * time.Millisecond
+
+// DeadLetterPublisher ships messages that repeatedly fail processing to TopicDeadLetter,
+// so a poison message does not block its partition with endless redelivery.
+type DeadLetterPublisher struct {
+	writer      *kafkaGo.Writer
+	maxAttempts int
+	logger      *zap.Logger
+}
+
+// NewDeadLetterPublisher creates a publisher that dead-letters a message after
+// maxAttempts failed processing attempts.
+func NewDeadLetterPublisher(brokers []string, maxAttempts int, logger *zap.Logger) *DeadLetterPublisher {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &DeadLetterPublisher{
+		writer: &kafkaGo.Writer{
+			Addr:         kafkaGo.TCP(brokers...),
+			Topic:        TopicDeadLetter,
+			Balancer:     &kafkaGo.Hash{},
+			RequiredAcks: kafkaGo.RequireAll,
+		},
+		maxAttempts: maxAttempts,
+		logger:      logger.With(zap.String("component", "dead_letter")),
+	}
+}
+
+// Wrap returns a handler that retries handle up to the configured number of attempts
+// and then publishes the message to the dead-letter topic and reports success, so the
+// consumer moves on. If the dead-letter publish itself fails the error is returned and
+// the message is redelivered. A nil publisher returns handle unchanged.
+func (p *DeadLetterPublisher) Wrap(groupID string, handle func(context.Context, kafkaGo.Message) error) func(context.Context, kafkaGo.Message) error {
+	if p == nil {
+		return handle
+	}
+	return func(ctx context.Context, msg kafkaGo.Message) error {
+		var err error
+		backoff := deadLetterBackoff
+		for attempt := 1; attempt <= p.maxAttempts; attempt++ {
+			if err = handle(ctx, msg); err == nil {
+				return nil
+			}
+			if attempt == p.maxAttempts {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		if dlqErr := p.publish(ctx, groupID, msg, err); dlqErr != nil {
+			p.logger.Error("failed to dead-letter message",
+				zap.String("topic", msg.Topic),
+				zap.Int("partition", msg.Partition),
+				zap.Int64("offset", msg.Offset),
+				zap.Error(dlqErr),
+			)
+			return err
+		}
+
+		p.logger.Warn("message dead-lettered",
+			zap.String("topic", msg.Topic),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.String("group_id", groupID),
+			zap.Int("attempts", p.maxAttempts),
+			zap.Error(err),
+		)
+		return nil
+	}
+}
+
+// publish writes msg to the dead-letter topic with its key, value and headers, plus
+// headers describing where it came from and why it failed.
+func (p *DeadLetterPublisher) publish(ctx context.Context, groupID string, msg kafkaGo.Message, cause error) error {
+	headers := make([]kafkaGo.Header, 0, len(msg.Headers)+7)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafkaGo.Header{Key: headerDLQTopic, Value: []byte(msg.Topic)},
+		kafkaGo.Header{Key: headerDLQPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafkaGo.Header{Key: headerDLQOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafkaGo.Header{Key: headerDLQGroup, Value: []byte(groupID)},
+		kafkaGo.Header{Key: headerDLQError, Value: []byte(cause.Error())},
+		kafkaGo.Header{Key: headerDLQAttempts, Value: []byte(strconv.Itoa(p.maxAttempts))},
+		kafkaGo.Header{Key: headerDLQFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+	)
+
+	if err := p.writer.WriteMessages(ctx, kafkaGo.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}); err != nil {
+		return fmt.Errorf("failed to write to %s: %w", TopicDeadLetter, err)
+	}
+	return nil
+}
+
+// Close flushes and closes the dead-letter writer.
+func (p *DeadLetterPublisher) Close() error {
+	return p.writer.Close()
+}
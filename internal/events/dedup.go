@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// dedupPruneInterval is how often processed event IDs past their retention are deleted.
+const dedupPruneInterval = time.Hour
+
+// ProcessedEventStore durably records the IDs of processed events, so duplicates are
+// detected across restarts, consumer group rebalances and service instances.
+type ProcessedEventStore interface {
+	IsProcessed(ctx context.Context, eventID string) (bool, error)
+	MarkProcessed(ctx context.Context, eventID, eventType string) error
+	DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Deduplicator remembers processed event IDs so that an event delivered more than once,
+// e.g. redelivered after a rebalance or consumed by two consumer groups during a
+// blue/green group migration, is handled once. Recent IDs are cached in memory in front
+// of an optional ProcessedEventStore.
+type Deduplicator struct {
+	ttl  time.Duration
+	mu   sync.Mutex
+	seen map[string]time.Time // event ID -> first seen
+
+	store     ProcessedEventStore
+	retention time.Duration
+	logger    *zap.Logger
+}
+
+// NewDeduplicator creates a Deduplicator that caches event IDs in memory for ttl.
+func NewDeduplicator(ttl time.Duration) *Deduplicator {
+	return &Deduplicator{
+		ttl:  ttl,
+		seen: make(map[string]time.Time),
+	}
+}
+
+// UseStore makes the Deduplicator record processed event IDs in store and keep them
+// for retention. It must be called before the consumers start.
+func (d *Deduplicator) UseStore(store ProcessedEventStore, retention time.Duration, logger *zap.Logger) {
+	d.store = store
+	d.retention = retention
+	d.logger = logger.With(zap.String("component", "event_dedup"))
+}
+
+// Seen reports whether an event ID was marked as processed. If the store cannot be
+// read the event is reported as unseen, so it is processed rather than dropped.
+func (d *Deduplicator) Seen(ctx context.Context, eventID string) bool {
+	if d == nil || eventID == "" {
+		return false
+	}
+
+	d.mu.Lock()
+	at, ok := d.seen[eventID]
+	d.mu.Unlock()
+	if ok && time.Since(at) < d.ttl {
+		return true
+	}
+	if d.store == nil {
+		return false
+	}
+
+	processed, err := d.store.IsProcessed(ctx, eventID)
+	if err != nil {
+		d.logger.Warn("failed to look up processed event", zap.String("id", eventID), zap.Error(err))
+		return false
+	}
+	return processed
+}
+
+// Mark records an event ID as processed. A failure to record it is logged rather than
+// returned, as the event has already been applied.
+func (d *Deduplicator) Mark(ctx context.Context, eventID, eventType string) {
+	if d == nil || eventID == "" {
+		return
+	}
+
+	d.mu.Lock()
+	now := time.Now()
+	d.seen[eventID] = now
+
+	// Opportunistically evict expired entries to bound memory.
+	if len(d.seen)%1024 == 0 {
+		for id, at := range d.seen {
+			if now.Sub(at) >= d.ttl {
+				delete(d.seen, id)
+			}
+		}
+	}
+	d.mu.Unlock()
+
+	if d.store == nil {
+		return
+	}
+	if err := d.store.MarkProcessed(ctx, eventID, eventType); err != nil {
+		d.logger.Warn("failed to record processed event", zap.String("id", eventID), zap.Error(err))
+	}
+}
+
+// Run deletes stored event IDs older than the retention period every hour until ctx is
+// cancelled. It returns immediately when no store is used.
+func (d *Deduplicator) Run(ctx context.Context) {
+	if d == nil || d.store == nil {
+		return
+	}
+
+	ticker := time.NewTicker(dedupPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := d.store.DeleteProcessedBefore(ctx, time.Now().Add(-d.retention))
+			if err != nil {
+				if ctx.Err() == nil {
+					d.logger.Warn("failed to prune processed events", zap.Error(err))
+				}
+				continue
+			}
+			if deleted > 0 {
+				d.logger.Debug("pruned processed events", zap.Int64("deleted", deleted))
+			}
+		}
+	}
+}
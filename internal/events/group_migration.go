@@ -0,0 +1,191 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kafkaGo "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// SeededPartition reports the starting offset committed for one partition.
+type SeededPartition struct {
+	Partition int   `json:"partition"`
+	Offset    int64 `json:"offset"`
+}
+
+// GroupMigrator prepares a new consumer group to take over from an old one by
+// committing starting offsets at a chosen point in time, so the new group neither
+// skips nor replays more than the overlap window.
+type GroupMigrator struct {
+	client *kafkaGo.Client
+	logger *zap.Logger
+}
+
+// NewGroupMigrator creates a new GroupMigrator.
+func NewGroupMigrator(brokers []string, logger *zap.Logger) *GroupMigrator {
+	return &GroupMigrator{
+		client: &kafkaGo.Client{Addr: kafkaGo.TCP(brokers...)},
+		logger: logger,
+	}
+}
+
+// SeedGroup commits, for every partition of topic, the first offset at or after startAt
+// (or the log end if there is none) as groupID's position. Unless force is set, a group
+// that already has committed offsets is left untouched and nil is returned.
+func (m *GroupMigrator) SeedGroup(ctx context.Context, groupID, topic string, startAt time.Time, force bool) ([]SeededPartition, error) {
+	partitions, err := m.partitions(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	if !force {
+		committed, err := m.hasCommittedOffsets(ctx, groupID, topic, partitions)
+		if err != nil {
+			return nil, err
+		}
+		if committed {
+			m.logger.Info("consumer group already has committed offsets, not seeding",
+				zap.String("group_id", groupID),
+				zap.String("topic", topic),
+			)
+			return nil, nil
+		}
+	}
+
+	requests := make([]kafkaGo.OffsetRequest, 0, 2*len(partitions))
+	for _, p := range partitions {
+		requests = append(requests, kafkaGo.TimeOffsetOf(p, startAt), kafkaGo.LastOffsetOf(p))
+	}
+	offsets, err := m.client.ListOffsets(ctx, &kafkaGo.ListOffsetsRequest{
+		Topics: map[string][]kafkaGo.OffsetRequest{topic: requests},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets for %s: %w", topic, err)
+	}
+
+	commits := make([]kafkaGo.OffsetCommit, 0, len(partitions))
+	seeded := make([]SeededPartition, 0, len(partitions))
+	for _, po := range offsets.Topics[topic] {
+		if po.Error != nil {
+			return nil, fmt.Errorf("failed to list offsets for %s/%d: %w", topic, po.Partition, po.Error)
+		}
+		offset := po.LastOffset
+		for o := range po.Offsets {
+			if o >= 0 && o < offset {
+				offset = o
+			}
+		}
+		commits = append(commits, kafkaGo.OffsetCommit{Partition: po.Partition, Offset: offset})
+		seeded = append(seeded, SeededPartition{Partition: po.Partition, Offset: offset})
+	}
+
+	res, err := m.client.OffsetCommit(ctx, &kafkaGo.OffsetCommitRequest{
+		GroupID:      groupID,
+		GenerationID: -1,
+		Topics:       map[string][]kafkaGo.OffsetCommit{topic: commits},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit offsets for group %s: %w", groupID, err)
+	}
+	for _, p := range res.Topics[topic] {
+		if p.Error != nil {
+			return nil, fmt.Errorf("failed to commit offset for group %s on %s/%d: %w", groupID, topic, p.Partition, p.Error)
+		}
+	}
+
+	m.logger.Info("consumer group seeded",
+		zap.String("group_id", groupID),
+		zap.String("topic", topic),
+		zap.Time("start_at", startAt),
+		zap.Int("partitions", len(seeded)),
+	)
+	return seeded, nil
+}
+
+// PartitionLag returns how many messages of each partition of topic groupID has yet to
+// consume. Partitions without a committed offset count from the log start.
+func (m *GroupMigrator) PartitionLag(ctx context.Context, groupID, topic string) (map[int]int64, error) {
+	partitions, err := m.partitions(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]kafkaGo.OffsetRequest, 0, 2*len(partitions))
+	for _, p := range partitions {
+		requests = append(requests, kafkaGo.FirstOffsetOf(p), kafkaGo.LastOffsetOf(p))
+	}
+	offsets, err := m.client.ListOffsets(ctx, &kafkaGo.ListOffsetsRequest{
+		Topics: map[string][]kafkaGo.OffsetRequest{topic: requests},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets for %s: %w", topic, err)
+	}
+	committed, err := m.client.OffsetFetch(ctx, &kafkaGo.OffsetFetchRequest{
+		GroupID: groupID,
+		Topics:  map[string][]int{topic: partitions},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch offsets for group %s: %w", groupID, err)
+	}
+
+	positions := make(map[int]int64, len(partitions))
+	for _, p := range committed.Topics[topic] {
+		if p.Error == nil && p.CommittedOffset >= 0 {
+			positions[p.Partition] = p.CommittedOffset
+		}
+	}
+
+	lag := make(map[int]int64, len(partitions))
+	for _, po := range offsets.Topics[topic] {
+		if po.Error != nil {
+			return nil, fmt.Errorf("failed to list offsets for %s/%d: %w", topic, po.Partition, po.Error)
+		}
+		position, ok := positions[po.Partition]
+		if !ok {
+			position = po.FirstOffset
+		}
+		lag[po.Partition] = max(po.LastOffset-position, 0)
+	}
+	return lag, nil
+}
+
+// partitions returns the partition IDs of a topic.
+func (m *GroupMigrator) partitions(ctx context.Context, topic string) ([]int, error) {
+	meta, err := m.client.Metadata(ctx, &kafkaGo.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metadata for %s: %w", topic, err)
+	}
+	for _, t := range meta.Topics {
+		if t.Name != topic {
+			continue
+		}
+		if t.Error != nil {
+			return nil, fmt.Errorf("failed to load metadata for %s: %w", topic, t.Error)
+		}
+		ids := make([]int, len(t.Partitions))
+		for i, p := range t.Partitions {
+			ids[i] = p.ID
+		}
+		return ids, nil
+	}
+	return nil, fmt.Errorf("topic %s not found", topic)
+}
+
+// hasCommittedOffsets reports whether groupID has committed an offset on any partition of topic.
+func (m *GroupMigrator) hasCommittedOffsets(ctx context.Context, groupID, topic string, partitions []int) (bool, error) {
+	res, err := m.client.OffsetFetch(ctx, &kafkaGo.OffsetFetchRequest{
+		GroupID: groupID,
+		Topics:  map[string][]int{topic: partitions},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch offsets for group %s: %w", groupID, err)
+	}
+	for _, p := range res.Topics[topic] {
+		if p.Error == nil && p.CommittedOffset >= 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -10,31 +10,52 @@ import (
 	"go.uber.org/zap"
 )
 
+// bookingCreated is the booking event that first carries the booking's owner.
+const bookingCreated = "booking.created"
+
+// RegionalTopic returns the region-suffixed name of a topic, e.g. booking-events.id-jkt.
+// An empty region returns the global topic unchanged.
+func RegionalTopic(topic, region string) string {
+	if region == "" {
+		return topic
+	}
+	return topic + "." + region
+}
+
 // BookingEventConsumer consumes booking events and dispatches them to the tracking service.
 type BookingEventConsumer struct {
-	consumer *kafkaLib.Consumer
-	service  *application.TrackingService
-	logger   *zap.Logger
+	consumer   messageConsumer
+	service    *application.TrackingService
+	dedup      *Deduplicator
+	deadLetter *DeadLetterPublisher
+	groupID    string
+	region     string
+	logger     *zap.Logger
 }
 
-// NewBookingEventConsumer creates a new consumer for booking events.
+// NewBookingEventConsumer creates a new consumer for booking events. A non-empty region
+// consumes the region-suffixed topic and tags new tracks with that region.
 func NewBookingEventConsumer(
 	brokers []string,
 	groupID string,
+	region string,
 	service *application.TrackingService,
 	logger *zap.Logger,
 ) *BookingEventConsumer {
-	consumer := kafkaLib.NewConsumer(brokers, groupID, events.TopicBookingEvents, logger)
+	topic := RegionalTopic(events.TopicBookingEvents, region)
+	consumer := kafkaLib.NewConsumer(brokers, groupID, topic, logger)
 	return &BookingEventConsumer{
 		consumer: consumer,
 		service:  service,
-		logger:   logger,
+		groupID:  groupID,
+		region:   region,
+		logger:   logger.With(zap.String("topic", topic)),
 	}
 }
 
 // Start begins consuming booking events. Blocks until the context is cancelled.
 func (c *BookingEventConsumer) Start(ctx context.Context) error {
-	return c.consumer.Consume(ctx, c.handleMessage)
+	return c.consumer.Consume(ctx, c.deadLetter.Wrap(c.groupID, c.handleMessage))
 }
 
 // handleMessage processes a single booking event message.
@@ -53,14 +74,33 @@ func (c *BookingEventConsumer) handleMessage(ctx context.Context, msg kafkaGo.Me
 		zap.String("id", cloudEvent.ID),
 	)
 
+	if c.dedup.Seen(ctx, cloudEvent.ID) {
+		c.logger.Debug("skipping duplicate booking event", zap.String("id", cloudEvent.ID))
+		return nil
+	}
+	if err := c.dispatch(ctx, cloudEvent); err != nil {
+		return err
+	}
+	c.dedup.Mark(ctx, cloudEvent.ID, cloudEvent.Type)
+	return nil
+}
+
+// dispatch routes a booking event to the tracking service by type.
+func (c *BookingEventConsumer) dispatch(ctx context.Context, cloudEvent *kafkaLib.CloudEvent) error {
 	switch cloudEvent.Type {
+	case bookingCreated:
+		return c.recordParticipants(ctx, cloudEvent)
+
 	case events.BookingAccepted:
+		if err := c.recordParticipants(ctx, cloudEvent); err != nil {
+			return err
+		}
 		var evt events.BookingAcceptedEvent
 		if err := cloudEvent.ParseData(&evt); err != nil {
 			c.logger.Error("failed to parse booking accepted event data", zap.Error(err))
 			return err
 		}
-		return c.service.HandleBookingAccepted(ctx, evt)
+		return c.service.HandleBookingAccepted(ctx, evt, c.region)
 
 	case events.BookingDeliveryConfirmed:
 		var evt events.DeliveryConfirmedEvent
@@ -78,6 +118,28 @@ func (c *BookingEventConsumer) handleMessage(ctx context.Context, msg kafkaGo.Me
 	}
 }
 
+// recordParticipants stores the booking owner and runner carried by a booking event.
+func (c *BookingEventConsumer) recordParticipants(ctx context.Context, cloudEvent *kafkaLib.CloudEvent) error {
+	var evt application.BookingParticipantsEvent
+	if err := cloudEvent.ParseData(&evt); err != nil {
+		c.logger.Error("failed to parse booking participants", zap.String("type", cloudEvent.Type), zap.Error(err))
+		return err
+	}
+	return c.service.HandleBookingParticipants(ctx, evt)
+}
+
+// UseDeduplicator makes the consumer skip events already processed, by itself after a
+// redelivery or by another consumer sharing the same Deduplicator.
+func (c *BookingEventConsumer) UseDeduplicator(d *Deduplicator) {
+	c.dedup = d
+}
+
+// UseDeadLetter ships messages that keep failing to the dead-letter topic instead of
+// returning the error to the consumer.
+func (c *BookingEventConsumer) UseDeadLetter(p *DeadLetterPublisher) {
+	c.deadLetter = p
+}
+
 // Close shuts down the booking event consumer.
 func (c *BookingEventConsumer) Close() error {
 	return c.consumer.Close()
@@ -85,29 +147,43 @@ func (c *BookingEventConsumer) Close() error {
 
 // RunnerEventConsumer consumes runner events and dispatches them to the tracking service.
 type RunnerEventConsumer struct {
-	consumer *kafkaLib.Consumer
-	service  *application.TrackingService
-	logger   *zap.Logger
+	consumer   messageConsumer
+	service    *application.TrackingService
+	dedup      *Deduplicator
+	deadLetter *DeadLetterPublisher
+	groupID    string
+	logger     *zap.Logger
 }
 
-// NewRunnerEventConsumer creates a new consumer for runner events.
+// NewRunnerEventConsumer creates a new consumer for runner events. A non-empty region
+// consumes the region-suffixed topic. With more than one worker, events of different
+// runners are processed concurrently; each runner's events stay in order.
 func NewRunnerEventConsumer(
 	brokers []string,
 	groupID string,
+	region string,
+	workers int,
 	service *application.TrackingService,
 	logger *zap.Logger,
 ) *RunnerEventConsumer {
-	consumer := kafkaLib.NewConsumer(brokers, groupID, events.TopicRunnerEvents, logger)
+	topic := RegionalTopic(events.TopicRunnerEvents, region)
+	var consumer messageConsumer
+	if workers > 1 {
+		consumer = NewPooledConsumer(brokers, groupID, topic, workers, logger)
+	} else {
+		consumer = kafkaLib.NewConsumer(brokers, groupID, topic, logger)
+	}
 	return &RunnerEventConsumer{
 		consumer: consumer,
 		service:  service,
-		logger:   logger,
+		groupID:  groupID,
+		logger:   logger.With(zap.String("topic", topic)),
 	}
 }
 
 // Start begins consuming runner events. Blocks until the context is cancelled.
 func (c *RunnerEventConsumer) Start(ctx context.Context) error {
-	return c.consumer.Consume(ctx, c.handleMessage)
+	return c.consumer.Consume(ctx, c.deadLetter.Wrap(c.groupID, c.handleMessage))
 }
 
 // handleMessage processes a single runner event message.
@@ -126,9 +202,22 @@ func (c *RunnerEventConsumer) handleMessage(ctx context.Context, msg kafkaGo.Mes
 		zap.String("id", cloudEvent.ID),
 	)
 
+	if c.dedup.Seen(ctx, cloudEvent.ID) {
+		c.logger.Debug("skipping duplicate runner event", zap.String("id", cloudEvent.ID))
+		return nil
+	}
+	if err := c.dispatch(ctx, cloudEvent); err != nil {
+		return err
+	}
+	c.dedup.Mark(ctx, cloudEvent.ID, cloudEvent.Type)
+	return nil
+}
+
+// dispatch routes a runner event to the tracking service by type.
+func (c *RunnerEventConsumer) dispatch(ctx context.Context, cloudEvent *kafkaLib.CloudEvent) error {
 	switch cloudEvent.Type {
 	case events.RunnerLocationUpdate:
-		var evt events.RunnerLocationUpdateEvent
+		var evt application.RunnerLocationUpdate
 		if err := cloudEvent.ParseData(&evt); err != nil {
 			c.logger.Error("failed to parse runner location update event data", zap.Error(err))
 			return err
@@ -143,6 +232,18 @@ func (c *RunnerEventConsumer) handleMessage(ctx context.Context, msg kafkaGo.Mes
 	}
 }
 
+// UseDeduplicator makes the consumer skip events already processed, by itself after a
+// redelivery or by another consumer sharing the same Deduplicator.
+func (c *RunnerEventConsumer) UseDeduplicator(d *Deduplicator) {
+	c.dedup = d
+}
+
+// UseDeadLetter ships messages that keep failing to the dead-letter topic instead of
+// returning the error to the consumer.
+func (c *RunnerEventConsumer) UseDeadLetter(p *DeadLetterPublisher) {
+	c.deadLetter = p
+}
+
 // Close shuts down the runner event consumer.
 func (c *RunnerEventConsumer) Close() error {
 	return c.consumer.Close()
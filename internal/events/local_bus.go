@@ -0,0 +1,134 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	kafkaLib "github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	kafkaGo "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// localSubscriptionBuffer is how many messages a local subscription holds before
+// publishing to its topic blocks.
+const localSubscriptionBuffer = 256
+
+// messageConsumer is the part of the Kafka consumer the booking and runner consumers use.
+type messageConsumer interface {
+	Consume(ctx context.Context, handler func(context.Context, kafkaGo.Message) error) error
+	Close() error
+}
+
+// messageReader is the part of the Kafka reader the announcement consumer uses.
+type messageReader interface {
+	ReadMessage(ctx context.Context) (kafkaGo.Message, error)
+	Close() error
+}
+
+// LocalBus is an in-process replacement for Kafka used in standalone mode. Published
+// events are encoded as they would be on the wire and delivered to every subscription
+// of their topic; nothing is persisted, so subscribers only see events published after
+// they subscribed.
+type LocalBus struct {
+	mu     sync.RWMutex
+	subs   map[string][]*LocalSubscription
+	offset int64
+	logger *zap.Logger
+}
+
+// NewLocalBus creates an empty LocalBus.
+func NewLocalBus(logger *zap.Logger) *LocalBus {
+	return &LocalBus{
+		subs:   make(map[string][]*LocalSubscription),
+		logger: logger.With(zap.String("component", "local_bus")),
+	}
+}
+
+// PublishEvent delivers a CloudEvent to every subscription of topic. It satisfies
+// application.EventPublisher.
+func (b *LocalBus) PublishEvent(ctx context.Context, topic string, e kafkaLib.CloudEvent) error {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	b.mu.Lock()
+	b.offset++
+	msg := kafkaGo.Message{Topic: topic, Offset: b.offset, Key: []byte(e.ID), Value: value, Time: time.Now().UTC()}
+	subs := b.subs[topic]
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		select {
+		case s.messages <- msg:
+		case <-s.closed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a subscription receiving the events published to topic from now on.
+func (b *LocalBus) Subscribe(topic string) *LocalSubscription {
+	s := &LocalSubscription{
+		topic:    topic,
+		messages: make(chan kafkaGo.Message, localSubscriptionBuffer),
+		closed:   make(chan struct{}),
+		logger:   b.logger.With(zap.String("topic", topic)),
+	}
+	b.mu.Lock()
+	b.subs[topic] = append(b.subs[topic], s)
+	b.mu.Unlock()
+	return s
+}
+
+// LocalSubscription receives the events of one LocalBus topic. It can stand in for both
+// a Kafka consumer and a Kafka reader.
+type LocalSubscription struct {
+	topic     string
+	messages  chan kafkaGo.Message
+	closed    chan struct{}
+	closeOnce sync.Once
+	logger    *zap.Logger
+}
+
+// ReadMessage blocks until the next message arrives, ctx is cancelled or the
+// subscription is closed.
+func (s *LocalSubscription) ReadMessage(ctx context.Context) (kafkaGo.Message, error) {
+	select {
+	case msg := <-s.messages:
+		return msg, nil
+	case <-s.closed:
+		return kafkaGo.Message{}, fmt.Errorf("subscription to %s closed", s.topic)
+	case <-ctx.Done():
+		return kafkaGo.Message{}, ctx.Err()
+	}
+}
+
+// Consume calls handler with each message until ctx is cancelled or the subscription is
+// closed. As there is no offset to retry from, a message whose handler fails is logged
+// and skipped.
+func (s *LocalSubscription) Consume(ctx context.Context, handler func(context.Context, kafkaGo.Message) error) error {
+	for {
+		msg, err := s.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err := handler(ctx, msg); err != nil {
+			s.logger.Error("failed to handle local event", zap.Int64("offset", msg.Offset), zap.Error(err))
+		}
+	}
+}
+
+// Close stops delivering messages to the subscription.
+func (s *LocalSubscription) Close() error {
+	s.closeOnce.Do(func() { close(s.closed) })
+	return nil
+}
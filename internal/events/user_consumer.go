@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+
+	kafkaLib "github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+	kafkaGo "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// UserEventConsumer consumes user events and dispatches data erasure requests to the
+// erasure service.
+type UserEventConsumer struct {
+	consumer   messageConsumer
+	service    *application.ErasureService
+	dedup      *Deduplicator
+	deadLetter *DeadLetterPublisher
+	groupID    string
+	logger     *zap.Logger
+}
+
+// NewUserEventConsumer creates a new consumer of application.UserEventsTopic.
+func NewUserEventConsumer(brokers []string, groupID string, service *application.ErasureService, logger *zap.Logger) *UserEventConsumer {
+	return &UserEventConsumer{
+		consumer: kafkaLib.NewConsumer(brokers, groupID, application.UserEventsTopic, logger),
+		service:  service,
+		groupID:  groupID,
+		logger:   logger.With(zap.String("topic", application.UserEventsTopic)),
+	}
+}
+
+// NewLocalUserEventConsumer creates a consumer of application.UserEventsTopic on a
+// LocalBus, for standalone mode.
+func NewLocalUserEventConsumer(bus *LocalBus, service *application.ErasureService, logger *zap.Logger) *UserEventConsumer {
+	return &UserEventConsumer{
+		consumer: bus.Subscribe(application.UserEventsTopic),
+		service:  service,
+		groupID:  "local-user-consumer",
+		logger:   logger.With(zap.String("topic", application.UserEventsTopic)),
+	}
+}
+
+// Start begins consuming user events. Blocks until the context is cancelled.
+func (c *UserEventConsumer) Start(ctx context.Context) error {
+	return c.consumer.Consume(ctx, c.deadLetter.Wrap(c.groupID, c.handleMessage))
+}
+
+// handleMessage processes a single user event message.
+func (c *UserEventConsumer) handleMessage(ctx context.Context, msg kafkaGo.Message) error {
+	cloudEvent, err := kafkaLib.ParseCloudEvent(msg.Value)
+	if err != nil {
+		c.logger.Error("failed to parse cloud event from user topic",
+			zap.Error(err),
+			zap.Int64("offset", msg.Offset),
+		)
+		return err
+	}
+
+	if cloudEvent.Type != application.EventUserDataErasureRequested {
+		c.logger.Debug("ignoring unhandled user event type", zap.String("type", cloudEvent.Type))
+		return nil
+	}
+	if c.dedup.Seen(ctx, cloudEvent.ID) {
+		c.logger.Debug("skipping duplicate user event", zap.String("id", cloudEvent.ID))
+		return nil
+	}
+
+	var evt application.UserDataErasureRequestedEvent
+	if err := cloudEvent.ParseData(&evt); err != nil {
+		c.logger.Error("failed to parse data erasure requested event data", zap.Error(err))
+		return err
+	}
+	if err := c.service.HandleErasureRequested(ctx, evt); err != nil {
+		return err
+	}
+	c.dedup.Mark(ctx, cloudEvent.ID, cloudEvent.Type)
+	return nil
+}
+
+// UseDeduplicator makes the consumer skip events already processed.
+func (c *UserEventConsumer) UseDeduplicator(d *Deduplicator) {
+	c.dedup = d
+}
+
+// UseDeadLetter ships messages that keep failing to the dead-letter topic instead of
+// returning the error to the consumer.
+func (c *UserEventConsumer) UseDeadLetter(p *DeadLetterPublisher) {
+	c.deadLetter = p
+}
+
+// Close shuts down the user event consumer.
+func (c *UserEventConsumer) Close() error {
+	return c.consumer.Close()
+}
@@ -0,0 +1,189 @@
+package events
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	kafkaGo "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+const (
+	// workerQueueSize is how many messages may wait for each worker before fetching blocks.
+	workerQueueSize = 64
+
+	// workerRetryDelay is how long a worker waits before retrying a message whose
+	// handler failed, e.g. because the dead-letter topic was unavailable.
+	workerRetryDelay = time.Second
+
+	// pooledCommitInterval is how often processed offsets are committed.
+	pooledCommitInterval = time.Second
+)
+
+// messageFetcher is the part of the Kafka reader a PooledConsumer uses.
+type messageFetcher interface {
+	FetchMessage(ctx context.Context) (kafkaGo.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafkaGo.Message) error
+	Close() error
+}
+
+// inflightMessage is a fetched message that may not have been processed yet.
+type inflightMessage struct {
+	msg  kafkaGo.Message
+	done bool
+}
+
+// PooledConsumer consumes a topic on several workers. Messages go to a worker by key, or
+// by partition if they have none, so messages with the same key are processed in the
+// order they were produced; runner events are keyed by runner ID. A message's offset is
+// committed only once every earlier message of its partition has been processed, so a
+// crash redelivers unprocessed messages instead of skipping them.
+type PooledConsumer struct {
+	reader  messageFetcher
+	workers int
+	logger  *zap.Logger
+
+	mu       sync.Mutex
+	inflight map[int][]*inflightMessage // by partition, in fetch order
+}
+
+// NewPooledConsumer creates a consumer of topic in groupID processing messages on
+// workers goroutines.
+func NewPooledConsumer(brokers []string, groupID, topic string, workers int, logger *zap.Logger) *PooledConsumer {
+	return &PooledConsumer{
+		reader: kafkaGo.NewReader(kafkaGo.ReaderConfig{
+			Brokers:        brokers,
+			GroupID:        groupID,
+			Topic:          topic,
+			CommitInterval: pooledCommitInterval,
+		}),
+		workers:  max(workers, 1),
+		logger:   logger.With(zap.String("topic", topic)),
+		inflight: make(map[int][]*inflightMessage),
+	}
+}
+
+// Consume fetches messages and hands them to the workers until ctx is cancelled or
+// fetching fails. A message whose handler fails is retried until it succeeds, holding
+// up the messages behind it on the same worker, as a sequential consumer would.
+func (c *PooledConsumer) Consume(ctx context.Context, handler func(context.Context, kafkaGo.Message) error) error {
+	queues := make([]chan kafkaGo.Message, c.workers)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan kafkaGo.Message, workerQueueSize)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.work(ctx, queues[i], handler)
+		}()
+	}
+	defer func() {
+		for _, q := range queues {
+			close(q)
+		}
+		wg.Wait()
+	}()
+
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		c.track(msg)
+		select {
+		case queues[c.workerFor(msg)] <- msg:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// workerFor picks the worker of a message from its key, or its partition if unkeyed.
+func (c *PooledConsumer) workerFor(msg kafkaGo.Message) int {
+	key := msg.Key
+	if len(key) == 0 {
+		key = strconv.AppendInt(nil, int64(msg.Partition), 10)
+	}
+	f := fnv.New32a()
+	_, _ = f.Write(key)
+	return int(f.Sum32() % uint32(c.workers))
+}
+
+// work processes one worker's messages in order.
+func (c *PooledConsumer) work(ctx context.Context, queue <-chan kafkaGo.Message, handler func(context.Context, kafkaGo.Message) error) {
+	for msg := range queue {
+		for {
+			err := handler(ctx, msg)
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Warn("failed to handle message, retrying",
+				zap.Int("partition", msg.Partition),
+				zap.Int64("offset", msg.Offset),
+				zap.Error(err),
+			)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(workerRetryDelay):
+			}
+		}
+		c.complete(ctx, msg)
+	}
+}
+
+// track records a fetched message as in flight.
+func (c *PooledConsumer) track(msg kafkaGo.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inflight[msg.Partition] = append(c.inflight[msg.Partition], &inflightMessage{msg: msg})
+}
+
+// complete marks a message processed and commits the newest offset of its partition
+// that has no unprocessed messages before it.
+func (c *PooledConsumer) complete(ctx context.Context, msg kafkaGo.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending := c.inflight[msg.Partition]
+	for _, m := range pending {
+		if !m.done && m.msg.Offset == msg.Offset {
+			m.done = true
+			break
+		}
+	}
+
+	n := 0
+	for n < len(pending) && pending[n].done {
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	last := pending[n-1].msg
+	c.inflight[msg.Partition] = pending[n:]
+
+	// With a commit interval, this only queues the offset; it is committed in the background.
+	if err := c.reader.CommitMessages(ctx, last); err != nil && ctx.Err() == nil {
+		c.logger.Warn("failed to commit offset",
+			zap.Int("partition", last.Partition),
+			zap.Int64("offset", last.Offset),
+			zap.Error(err),
+		)
+	}
+}
+
+// Close stops the consumer.
+func (c *PooledConsumer) Close() error {
+	return c.reader.Close()
+}
@@ -0,0 +1,69 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// claimsKey is the context key of the authenticated caller's claims.
+type claimsKey struct{}
+
+// authInterceptor requires every call to carry an access token in the authorization
+// metadata, as "Bearer <token>", and stores its claims in the context.
+func authInterceptor(jwtManager *auth.JWTManager) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var token string
+		for _, v := range md.Get("authorization") {
+			if t, ok := strings.CutPrefix(v, "Bearer "); ok && t != "" {
+				token = t
+				break
+			}
+		}
+		if token == "" {
+			return nil, status.Error(codes.Unauthenticated, "access token is required")
+		}
+
+		claims, err := jwtManager.ValidateAccessToken(token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+		return handler(context.WithValue(ctx, claimsKey{}, claims), req)
+	}
+}
+
+// claimsFrom returns the caller's claims stored by authInterceptor.
+func claimsFrom(ctx context.Context) *auth.Claims {
+	if claims, ok := ctx.Value(claimsKey{}).(*auth.Claims); ok {
+		return claims
+	}
+	return &auth.Claims{}
+}
+
+// isService reports whether the caller is another service or an admin, which may read
+// any booking or runner.
+func isService(claims *auth.Claims) bool {
+	return claims.Role == application.RoleService || claims.Role == auth.RoleAdmin
+}
+
+// authorizeBooking returns PermissionDenied unless the caller is a service, an admin or
+// a participant of the booking.
+func (s *Server) authorizeBooking(ctx context.Context, bookingID uuid.UUID) error {
+	claims := claimsFrom(ctx)
+	if isService(claims) {
+		return nil
+	}
+	if err := s.service.AuthorizeBooking(ctx, bookingID, claims.UserID, claims.Role); err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
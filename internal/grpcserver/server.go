@@ -0,0 +1,191 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+	trackingv1 "github.com/Kilat-Pet-Delivery/service-tracking/proto/tracking/v1"
+)
+
+// Server implements the tracking gRPC API on top of the application TrackingService.
+type Server struct {
+	trackingv1.UnimplementedTrackingServiceServer
+
+	service    *application.TrackingService
+	jwtManager *auth.JWTManager
+	logger     *zap.Logger
+}
+
+// NewServer creates a new Server. Calls are authenticated with jwtManager.
+func NewServer(service *application.TrackingService, jwtManager *auth.JWTManager, logger *zap.Logger) *Server {
+	return &Server{service: service, jwtManager: jwtManager, logger: logger}
+}
+
+// NewGRPCServer creates a grpc.Server with logging, panic recovery and authentication
+// and registers s on it.
+func NewGRPCServer(s *Server) *grpc.Server {
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		recoveryInterceptor(s.logger),
+		loggingInterceptor(s.logger),
+		authInterceptor(s.jwtManager),
+	))
+	trackingv1.RegisterTrackingServiceServer(srv, s)
+	return srv
+}
+
+// GetTracking returns the trip track for a booking.
+func (s *Server) GetTracking(ctx context.Context, req *trackingv1.GetTrackingRequest) (*trackingv1.GetTrackingResponse, error) {
+	bookingID, err := uuid.Parse(req.GetBookingId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid booking ID format")
+	}
+
+	if err := s.authorizeBooking(ctx, bookingID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.service.GetTracking(ctx, bookingID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &trackingv1.GetTrackingResponse{Track: toProtoTrack(result)}, nil
+}
+
+// GetRoute returns the trip route for a booking as GeoJSON.
+func (s *Server) GetRoute(ctx context.Context, req *trackingv1.GetRouteRequest) (*trackingv1.GetRouteResponse, error) {
+	bookingID, err := uuid.Parse(req.GetBookingId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid booking ID format")
+	}
+
+	if req.GetTolerance() < 0 || req.GetMaxPoints() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "tolerance and max_points must not be negative")
+	}
+	if err := s.authorizeBooking(ctx, bookingID); err != nil {
+		return nil, err
+	}
+
+	geoJSON, err := s.service.GetRouteGeoJSON(ctx, bookingID, application.RouteOptions{
+		Tolerance: req.GetTolerance(),
+		MaxPoints: int(req.GetMaxPoints()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &trackingv1.GetRouteResponse{BookingId: bookingID.String(), Geojson: geoJSON}, nil
+}
+
+// GetActiveTrackByRunner returns the runner's current active trip track.
+func (s *Server) GetActiveTrackByRunner(ctx context.Context, req *trackingv1.GetActiveTrackByRunnerRequest) (*trackingv1.GetActiveTrackByRunnerResponse, error) {
+	runnerID, err := uuid.Parse(req.GetRunnerId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid runner ID format")
+	}
+	if claims := claimsFrom(ctx); !isService(claims) && claims.UserID != runnerID {
+		return nil, status.Error(codes.PermissionDenied, "cannot read another runner's track")
+	}
+
+	result, err := s.service.GetActiveTrackByRunner(ctx, runnerID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &trackingv1.GetActiveTrackByRunnerResponse{Track: toProtoTrack(result)}, nil
+}
+
+// toStatus maps catalog errors to gRPC status errors by their HTTP status class.
+func toStatus(err error) error {
+	appErr := apperror.From(err)
+	code := codes.Internal
+	switch appErr.Status() {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	}
+	return status.Error(code, appErr.Detail)
+}
+
+func toProtoTrack(dto *application.TrackingDTO) *trackingv1.Track {
+	track := &trackingv1.Track{
+		Id:              dto.ID.String(),
+		BookingId:       dto.BookingID.String(),
+		RunnerId:        dto.RunnerID.String(),
+		Region:          dto.Region,
+		Status:          dto.Status,
+		TotalDistanceKm: dto.TotalDistanceKm,
+		StartedAt:       timestamppb.New(dto.StartedAt),
+		Waypoints:       make([]*trackingv1.Waypoint, len(dto.Waypoints)),
+	}
+	if dto.CompletedAt != nil {
+		track.CompletedAt = timestamppb.New(*dto.CompletedAt)
+	}
+	if dto.Cancellation != nil {
+		track.Cancellation = &trackingv1.Cancellation{
+			ReasonCode:  dto.Cancellation.Reason,
+			Note:        dto.Cancellation.Note,
+			CancelledAt: timestamppb.New(dto.Cancellation.CancelledAt),
+		}
+	}
+	for i, wp := range dto.Waypoints {
+		track.Waypoints[i] = &trackingv1.Waypoint{
+			Id:             wp.ID.String(),
+			Latitude:       wp.Latitude,
+			Longitude:      wp.Longitude,
+			SpeedKmh:       wp.Speed,
+			HeadingDegrees: wp.Heading,
+			RecordedAt:     timestamppb.New(wp.RecordedAt),
+		}
+	}
+	return track
+}
+
+// loggingInterceptor logs each unary call with its duration and status code.
+func loggingInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logger.Info("grpc request",
+			zap.String("method", info.FullMethod),
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("duration", time.Since(start)),
+		)
+		return resp, err
+	}
+}
+
+// recoveryInterceptor converts panics in handlers into Internal errors.
+func recoveryInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("grpc handler panic", zap.String("method", info.FullMethod), zap.Any("panic", r))
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
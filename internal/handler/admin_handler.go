@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/logcontrol"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/schema"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/slo"
+)
+
+// SeedConsumerGroupRequest is the body for seeding a consumer group's offsets.
+type SeedConsumerGroupRequest struct {
+	GroupID string     `json:"group_id" binding:"required"`
+	Topic   string     `json:"topic" binding:"required"`
+	StartAt *time.Time `json:"start_at"`
+	Force   bool       `json:"force"`
+}
+
+// SetLogLevelRequest is the body for changing the log level.
+type SetLogLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// StartTraceRequest is the body for enabling debug logging for one booking or runner.
+type StartTraceRequest struct {
+	BookingID *uuid.UUID `json:"booking_id"`
+	RunnerID  *uuid.UUID `json:"runner_id"`
+	Every     int        `json:"every"`
+	Duration  string     `json:"duration"`
+}
+
+// LoggingDTO is the current logging configuration.
+type LoggingDTO struct {
+	Level  string             `json:"level"`
+	Traces []logcontrol.Trace `json:"traces"`
+}
+
+// AdminHandler handles operator-only HTTP requests.
+type AdminHandler struct {
+	migrator   *events.GroupMigrator
+	logControl *logcontrol.Controller
+	slo        *slo.Tracker
+	schema     *schema.Checker
+}
+
+// NewAdminHandler creates a new AdminHandler.
+func NewAdminHandler(migrator *events.GroupMigrator, logControl *logcontrol.Controller, sloTracker *slo.Tracker, schemaChecker *schema.Checker) *AdminHandler {
+	return &AdminHandler{migrator: migrator, logControl: logControl, slo: sloTracker, schema: schemaChecker}
+}
+
+// RegisterRoutes registers admin routes on the given router group.
+func (h *AdminHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	admin := r.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(jwtManager), requireRole(auth.RoleAdmin))
+	{
+		admin.POST("/consumer-groups/seed", h.SeedConsumerGroup)
+		admin.GET("/logging", h.GetLogging)
+		admin.PUT("/logging/level", h.SetLogLevel)
+		admin.POST("/logging/traces", h.StartTrace)
+		admin.DELETE("/logging/traces/:id", h.StopTrace)
+		admin.GET("/slo", h.GetSLOStatus)
+		admin.GET("/schema", h.GetSchemaStatus)
+	}
+}
+
+// GetSLOStatus handles GET /api/v1/admin/slo, summarizing each SLO class's error budget.
+func (h *AdminHandler) GetSLOStatus(c *gin.Context) {
+	response.Success(c, h.slo.Statuses())
+}
+
+// GetSchemaStatus handles GET /api/v1/admin/schema, comparing the database's applied
+// migration version with the one this build expects. There is no schema in standalone
+// mode, where schemaChecker is nil.
+func (h *AdminHandler) GetSchemaStatus(c *gin.Context) {
+	if h.schema == nil {
+		apperror.Abort(c, apperror.CodeNotFound, "standalone mode has no database schema")
+		return
+	}
+	status, err := h.schema.Status(c.Request.Context())
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// SeedConsumerGroup handles POST /api/v1/admin/consumer-groups/seed.
+func (h *AdminHandler) SeedConsumerGroup(c *gin.Context) {
+	var req SeedConsumerGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	startAt := time.Now().UTC()
+	if req.StartAt != nil {
+		startAt = *req.StartAt
+	}
+
+	seeded, err := h.migrator.SeedGroup(c.Request.Context(), req.GroupID, req.Topic, startAt, req.Force)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"group_id":   req.GroupID,
+		"topic":      req.Topic,
+		"start_at":   startAt,
+		"partitions": seeded,
+	})
+}
+
+// GetLogging handles GET /api/v1/admin/logging.
+func (h *AdminHandler) GetLogging(c *gin.Context) {
+	response.Success(c, h.logging())
+}
+
+// SetLogLevel handles PUT /api/v1/admin/logging/level.
+func (h *AdminHandler) SetLogLevel(c *gin.Context) {
+	var req SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		apperror.Abort(c, apperror.CodeValidation, err.Error())
+		return
+	}
+
+	h.logControl.SetLevel(level)
+	response.Success(c, h.logging())
+}
+
+// StartTrace handles POST /api/v1/admin/logging/traces, enabling debug logging for
+// exactly one of booking_id or runner_id.
+func (h *AdminHandler) StartTrace(c *gin.Context) {
+	var req StartTraceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	var subject, id string
+	switch {
+	case req.BookingID != nil && req.RunnerID == nil:
+		subject, id = logcontrol.SubjectBooking, req.BookingID.String()
+	case req.RunnerID != nil && req.BookingID == nil:
+		subject, id = logcontrol.SubjectRunner, req.RunnerID.String()
+	default:
+		apperror.Abort(c, apperror.CodeValidation, "exactly one of booking_id or runner_id is required")
+		return
+	}
+
+	var d time.Duration
+	if req.Duration != "" {
+		parsed, err := time.ParseDuration(req.Duration)
+		if err != nil || parsed <= 0 {
+			apperror.Abort(c, apperror.CodeValidation, "duration must be a positive duration such as 30m")
+			return
+		}
+		d = parsed
+	}
+
+	trace, err := h.logControl.StartTrace(subject, id, req.Every, d)
+	if err != nil {
+		apperror.Abort(c, apperror.CodeValidation, err.Error())
+		return
+	}
+
+	response.Created(c, trace)
+}
+
+// StopTrace handles DELETE /api/v1/admin/logging/traces/:id.
+func (h *AdminHandler) StopTrace(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid trace ID")
+		return
+	}
+
+	if !h.logControl.StopTrace(id.String()) {
+		apperror.Abort(c, apperror.CodeNotFound, "no trace for "+id.String())
+		return
+	}
+
+	response.Success(c, h.logging())
+}
+
+// logging returns the current log level and traces.
+func (h *AdminHandler) logging() LoggingDTO {
+	return LoggingDTO{
+		Level:  h.logControl.Level().String(),
+		Traces: h.logControl.Traces(),
+	}
+}
+
+// requireRole aborts with 403 unless the authenticated user has one of the given roles.
+func requireRole(roles ...auth.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userRole, ok := middleware.GetUserRole(c)
+		if ok {
+			for _, role := range roles {
+				if userRole == role {
+					c.Next()
+					return
+				}
+			}
+		}
+		apperror.Abort(c, apperror.CodeForbidden, "insufficient permissions")
+	}
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// AdminTrackingHandler handles operator-only HTTP requests about trip tracks.
+type AdminTrackingHandler struct {
+	trackingService *application.TrackingService
+	mergeService    *application.TrackMergeService
+}
+
+// NewAdminTrackingHandler creates a new AdminTrackingHandler.
+func NewAdminTrackingHandler(trackingService *application.TrackingService, mergeService *application.TrackMergeService) *AdminTrackingHandler {
+	return &AdminTrackingHandler{trackingService: trackingService, mergeService: mergeService}
+}
+
+// RegisterRoutes registers admin tracking routes on the given router group.
+func (h *AdminTrackingHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	admin := r.Group("/admin/tracking")
+	admin.Use(middleware.AuthMiddleware(jwtManager), requireRole(auth.RoleAdmin))
+	{
+		admin.GET("", h.ListTracks)
+		admin.GET("/stalled", h.ListStalledTracks)
+		admin.POST("/merge", h.MergeTracks)
+	}
+}
+
+// ListTracks handles GET /api/v1/admin/tracking?status=&runner_id=&from=&to=&min_distance_km=&sort=&cursor=&limit=.
+func (h *AdminTrackingHandler) ListTracks(c *gin.Context) {
+	result, err := h.trackingService.ListTracks(c.Request.Context(), application.ListTracksRequest{
+		Status:        c.Query("status"),
+		RunnerID:      c.Query("runner_id"),
+		From:          c.Query("from"),
+		To:            c.Query("to"),
+		MinDistanceKm: c.Query("min_distance_km"),
+		Sort:          c.Query("sort"),
+		Cursor:        c.Query("cursor"),
+		Limit:         c.Query("limit"),
+	})
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ListStalledTracks handles GET /api/v1/admin/tracking/stalled, listing the active trips
+// whose runner has stopped moving.
+func (h *AdminTrackingHandler) ListStalledTracks(c *gin.Context) {
+	tracks, err := h.trackingService.ListStalledTracks(c.Request.Context())
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"tracks": tracks})
+}
+
+// MergeTracks handles POST /api/v1/admin/tracking/merge, merging the track of a
+// duplicated booking into the booking's track.
+func (h *AdminTrackingHandler) MergeTracks(c *gin.Context) {
+	var req application.MergeTracksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	adminID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "user not authenticated")
+		return
+	}
+
+	result, err := h.mergeService.Merge(c.Request.Context(), req, adminID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
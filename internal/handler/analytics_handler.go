@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// AnalyticsHandler serves aggregate trip analytics to operators.
+type AnalyticsHandler struct {
+	service *application.AnalyticsService
+}
+
+// NewAnalyticsHandler creates a new AnalyticsHandler.
+func NewAnalyticsHandler(service *application.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{service: service}
+}
+
+// RegisterRoutes registers the analytics routes on the given router group.
+func (h *AnalyticsHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	analytics := r.Group("/admin/analytics")
+	analytics.Use(middleware.AuthMiddleware(jwtManager), requireRole(auth.RoleAdmin))
+	{
+		analytics.GET("/heatmap", h.GetHeatmap)
+	}
+}
+
+// GetHeatmap handles GET /api/v1/admin/analytics/heatmap?bbox=&from=&to=&precision=.
+func (h *AnalyticsHandler) GetHeatmap(c *gin.Context) {
+	bbox := c.Query("bbox")
+	if bbox == "" {
+		apperror.Abort(c, apperror.CodeInvalidRequest, "bbox is required")
+		return
+	}
+	box, err := parseBBox(bbox)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	result, err := h.service.GetHeatmap(c.Request.Context(), application.HeatmapRequest{
+		Bounds:    box,
+		From:      c.Query("from"),
+		To:        c.Query("to"),
+		Precision: c.Query("precision"),
+	})
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// AnnouncementHandler lets admins broadcast announcements into WebSocket rooms.
+type AnnouncementHandler struct {
+	service *application.AnnouncementService
+}
+
+// NewAnnouncementHandler creates a new AnnouncementHandler.
+func NewAnnouncementHandler(service *application.AnnouncementService) *AnnouncementHandler {
+	return &AnnouncementHandler{service: service}
+}
+
+// RegisterRoutes registers the announcement route on the given router group.
+func (h *AnnouncementHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	r.POST("/admin/announcements", middleware.AuthMiddleware(jwtManager), requireRole(auth.RoleAdmin), h.Broadcast)
+}
+
+// Broadcast handles POST /api/v1/admin/announcements.
+func (h *AnnouncementHandler) Broadcast(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req application.BroadcastAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := h.service.Broadcast(c.Request.Context(), userID, req)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Created(c, result)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// AnomalyHandler serves the anomalies detected on trips.
+type AnomalyHandler struct {
+	service *application.AnomalyService
+}
+
+// NewAnomalyHandler creates a new AnomalyHandler.
+func NewAnomalyHandler(service *application.AnomalyService) *AnomalyHandler {
+	return &AnomalyHandler{service: service}
+}
+
+// RegisterRoutes registers the anomaly route on the given router group. Anomalies
+// describe runner behavior and are only served to admins.
+func (h *AnomalyHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	r.GET("/tracking/:bookingId/anomalies", middleware.AuthMiddleware(jwtManager), requireRole(auth.RoleAdmin), h.GetAnomalies)
+}
+
+// GetAnomalies handles GET /api/v1/tracking/:bookingId/anomalies.
+func (h *AnomalyHandler) GetAnomalies(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	result, err := h.service.GetAnomalies(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
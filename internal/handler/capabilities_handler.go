@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// CapabilitiesHandler describes the deployment's optional features to clients.
+type CapabilitiesHandler struct {
+	service *application.CapabilitiesService
+}
+
+// NewCapabilitiesHandler creates a new CapabilitiesHandler.
+func NewCapabilitiesHandler(service *application.CapabilitiesService) *CapabilitiesHandler {
+	return &CapabilitiesHandler{service: service}
+}
+
+// RegisterRoutes registers the public capabilities route on the given router group.
+func (h *CapabilitiesHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/tracking/capabilities", h.GetCapabilities)
+}
+
+// GetCapabilities handles GET /api/v1/tracking/capabilities?region=. It needs no
+// authentication so apps can adapt their UI before sign-in.
+func (h *CapabilitiesHandler) GetCapabilities(c *gin.Context) {
+	result, err := h.service.GetCapabilities(c.Query("region"))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	response.Success(c, result)
+}
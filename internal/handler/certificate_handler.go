@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// CertificateHandler serves signed trip certificates and their verification.
+type CertificateHandler struct {
+	service  *application.CertificationService
+	tracking *application.TrackingService
+}
+
+// NewCertificateHandler creates a new CertificateHandler. The tracking service
+// authorizes who may read a booking's certificate.
+func NewCertificateHandler(service *application.CertificationService, tracking *application.TrackingService) *CertificateHandler {
+	return &CertificateHandler{service: service, tracking: tracking}
+}
+
+// RegisterRoutes registers the certificate routes on the given router group. The
+// verification keys are public so certificates can be checked offline.
+func (h *CertificateHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	r.GET("/tracking/:bookingId/certificate",
+		middleware.AuthMiddleware(jwtManager), requireBookingAccess(h.tracking), h.GetCertificate)
+
+	certificates := r.Group("/certificates")
+	certificates.GET("/keys", h.GetKeys)
+	certificates.POST("/verify", middleware.AuthMiddleware(jwtManager), h.Verify)
+}
+
+// GetCertificate handles GET /api/v1/tracking/:bookingId/certificate.
+func (h *CertificateHandler) GetCertificate(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	certificate, err := h.service.GetCertificate(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, certificate)
+}
+
+// Verify handles POST /api/v1/certificates/verify.
+func (h *CertificateHandler) Verify(c *gin.Context) {
+	var req application.VerifyCertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := h.service.Verify(c.Request.Context(), req)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetKeys handles GET /api/v1/certificates/keys.
+func (h *CertificateHandler) GetKeys(c *gin.Context) {
+	response.Success(c, h.service.PublicKeys())
+}
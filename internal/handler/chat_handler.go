@@ -1,8 +1,8 @@
 package handler
 
 import (
-	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -10,28 +10,36 @@ import (
 	"github.com/Kilat-Pet-Delivery/lib-common/auth"
 	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
 	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
 	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ratelimit"
 )
 
 // ChatHandler handles HTTP requests for chat operations.
 type ChatHandler struct {
-	service *application.ChatService
+	service  *application.ChatService
+	tracking *application.TrackingService
+	limiter  *ratelimit.Limiter
 }
 
-// NewChatHandler creates a new ChatHandler.
-func NewChatHandler(service *application.ChatService) *ChatHandler {
-	return &ChatHandler{service: service}
+// NewChatHandler creates a new ChatHandler. The tracking service authorizes who may
+// take part in a booking's chat, and the limiter caps how fast each user sends.
+func NewChatHandler(service *application.ChatService, tracking *application.TrackingService, limiter *ratelimit.Limiter) *ChatHandler {
+	return &ChatHandler{service: service, tracking: tracking, limiter: limiter}
 }
 
-// RegisterRoutes registers chat routes on the given router group.
+// RegisterRoutes registers chat routes on the given router group. Only the booking's
+// owner and assigned runner may read or post; support agents read chats through
+// support sessions.
 func (h *ChatHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
 	authMW := middleware.AuthMiddleware(jwtManager)
 
 	chat := r.Group("/chat")
-	chat.Use(authMW)
+	chat.Use(authMW, requireBookingParticipant(h.tracking))
 	{
-		chat.POST("/:bookingId/messages", h.SendMessage)
+		chat.POST("/:bookingId/messages", h.limiter.Middleware(ratelimit.ScopeChat), h.SendMessage)
 		chat.GET("/:bookingId/messages", h.GetMessages)
+		chat.POST("/:bookingId/attachments", h.CreateAttachmentUpload)
 	}
 }
 
@@ -39,31 +47,65 @@ func (h *ChatHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTMan
 func (h *ChatHandler) SendMessage(c *gin.Context) {
 	bookingID, err := uuid.Parse(c.Param("bookingId"))
 	if err != nil {
-		response.BadRequest(c, "invalid booking ID")
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID")
 		return
 	}
 
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
 		return
 	}
 
 	role, ok := middleware.GetUserRole(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
 		return
 	}
 
 	var req application.SendMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, err.Error())
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
 		return
 	}
+	if req.Locale == "" {
+		// The first language of e.g. "id-ID,id;q=0.9,en;q=0.8".
+		req.Locale, _, _ = strings.Cut(c.GetHeader("Accept-Language"), ",")
+		req.Locale, _, _ = strings.Cut(req.Locale, ";")
+	}
 
 	result, err := h.service.SendMessage(c.Request.Context(), bookingID, userID, string(role), req)
 	if err != nil {
-		response.Error(c, err)
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Created(c, result)
+}
+
+// CreateAttachmentUpload handles POST /api/v1/chat/:bookingId/attachments.
+func (h *ChatHandler) CreateAttachmentUpload(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
+		return
+	}
+
+	var req application.CreateAttachmentUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := h.service.CreateAttachmentUpload(c.Request.Context(), bookingID, userID, req)
+	if err != nil {
+		apperror.Respond(c, err)
 		return
 	}
 
@@ -74,7 +116,7 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 func (h *ChatHandler) GetMessages(c *gin.Context) {
 	bookingID, err := uuid.Parse(c.Param("bookingId"))
 	if err != nil {
-		response.BadRequest(c, "invalid booking ID")
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID")
 		return
 	}
 
@@ -82,7 +124,7 @@ func (h *ChatHandler) GetMessages(c *gin.Context) {
 
 	messages, total, err := h.service.GetMessages(c.Request.Context(), bookingID, page, limit)
 	if err != nil {
-		response.Error(c, err)
+		apperror.Respond(c, err)
 		return
 	}
 
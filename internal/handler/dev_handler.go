@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// Headers with which standalone requests without a token pick the dev identity.
+const (
+	headerDevUserID = "X-Dev-User-ID"
+	headerDevRole   = "X-Dev-Role"
+)
+
+// DevHandler serves the standalone mode helpers: a dev identity for requests without a
+// token and an endpoint that injects the events other services would publish.
+type DevHandler struct {
+	jwtManager *auth.JWTManager
+	publisher  application.EventPublisher
+	userID     uuid.UUID
+	role       auth.UserRole
+	logger     *zap.Logger
+}
+
+// NewDevHandler creates a new DevHandler acting as userID with role by default.
+func NewDevHandler(jwtManager *auth.JWTManager, publisher application.EventPublisher, userID uuid.UUID, role auth.UserRole, logger *zap.Logger) *DevHandler {
+	return &DevHandler{
+		jwtManager: jwtManager,
+		publisher:  publisher,
+		userID:     userID,
+		role:       role,
+		logger:     logger,
+	}
+}
+
+// RegisterRoutes registers the dev routes on the engine.
+func (h *DevHandler) RegisterRoutes(r *gin.Engine) {
+	dev := r.Group("/dev")
+	dev.GET("/token", h.Token)
+	dev.POST("/events", h.PublishEvent)
+}
+
+// identity returns the dev identity of a request: the configured one, overridden by the
+// X-Dev-User-ID and X-Dev-Role headers or the user_id and role query parameters.
+func (h *DevHandler) identity(c *gin.Context) (uuid.UUID, auth.UserRole, error) {
+	userID, role := h.userID, h.role
+	if v := firstNonEmpty(c.GetHeader(headerDevUserID), c.Query("user_id")); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return uuid.Nil, "", err
+		}
+		userID = id
+	}
+	if v := firstNonEmpty(c.GetHeader(headerDevRole), c.Query("role")); v != "" {
+		role = auth.UserRole(v)
+	}
+	return userID, role, nil
+}
+
+// firstNonEmpty returns the first of values that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Middleware signs requests that carry no token as the dev identity, so the regular
+// JWT checks pass. The token is set as the Authorization header, which WebSocket routes
+// also read. Requests with their own token are untouched.
+func (h *DevHandler) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" || c.Query("token") != "" || wsAccessToken(c.Request, false) != "" {
+			c.Next()
+			return
+		}
+		userID, role, err := h.identity(c)
+		if err != nil {
+			apperror.Abort(c, apperror.CodeInvalidID, "invalid "+headerDevUserID)
+			return
+		}
+		token, err := h.jwtManager.GenerateAccessToken(userID, "", role)
+		if err != nil {
+			h.logger.Error("failed to sign dev token", zap.Error(err))
+			apperror.Abort(c, apperror.CodeInternal, "failed to sign dev token")
+			return
+		}
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+		c.Next()
+	}
+}
+
+// Token handles GET /dev/token, returning an access token for the dev identity, e.g.
+// to paste into a WebSocket client.
+func (h *DevHandler) Token(c *gin.Context) {
+	userID, role, err := h.identity(c)
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid user_id")
+		return
+	}
+	token, err := h.jwtManager.GenerateAccessToken(userID, "", role)
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInternal, "failed to sign dev token")
+		return
+	}
+	response.Success(c, gin.H{"user_id": userID, "role": role, "access_token": token})
+}
+
+// devEventRequest is an event to publish on the local bus.
+type devEventRequest struct {
+	Topic string          `json:"topic" binding:"required"`
+	Type  string          `json:"type" binding:"required"`
+	Data  json.RawMessage `json:"data" binding:"required"`
+}
+
+// PublishEvent handles POST /dev/events, publishing an event on the local bus as the
+// booking or runner service would, e.g. a booking.accepted event to start a trip.
+func (h *DevHandler) PublishEvent(c *gin.Context) {
+	var req devEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+	cloudEvt, err := kafka.NewCloudEvent("service-tracking-dev", req.Type, req.Data)
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+	if err := h.publisher.PublishEvent(c.Request.Context(), req.Topic, cloudEvt); err != nil {
+		h.logger.Error("failed to publish dev event", zap.Error(err))
+		apperror.Abort(c, apperror.CodeInternal, "failed to publish event")
+		return
+	}
+	response.Created(c, gin.H{"id": cloudEvt.ID, "topic": req.Topic, "type": req.Type})
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// DrivingTimeHandler serves runner driving time for the safety team.
+type DrivingTimeHandler struct {
+	service *application.DrivingTimeService
+}
+
+// NewDrivingTimeHandler creates a new DrivingTimeHandler.
+func NewDrivingTimeHandler(service *application.DrivingTimeService) *DrivingTimeHandler {
+	return &DrivingTimeHandler{service: service}
+}
+
+// RegisterRoutes registers the admin driving time route on the given router group.
+func (h *DrivingTimeHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	r.GET("/admin/runners/:runnerId/driving-time",
+		middleware.AuthMiddleware(jwtManager), requireRole(auth.RoleAdmin), h.GetDrivingTime)
+}
+
+// GetDrivingTime handles GET /api/v1/admin/runners/:runnerId/driving-time.
+func (h *DrivingTimeHandler) GetDrivingTime(c *gin.Context) {
+	runnerID, err := uuid.Parse(c.Param("runnerId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid runner ID format")
+		return
+	}
+
+	result, err := h.service.GetDrivingTime(c.Request.Context(), runnerID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// ErasureHandler handles admin requests to erase a user's personal data.
+type ErasureHandler struct {
+	service *application.ErasureService
+}
+
+// NewErasureHandler creates a new ErasureHandler.
+func NewErasureHandler(service *application.ErasureService) *ErasureHandler {
+	return &ErasureHandler{service: service}
+}
+
+// RegisterRoutes registers the erasure routes on the given router group.
+func (h *ErasureHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	erasures := r.Group("/admin/erasures")
+	erasures.Use(middleware.AuthMiddleware(jwtManager), requireRole(auth.RoleAdmin))
+	{
+		erasures.POST("", h.RequestErasure)
+		erasures.GET("/:erasureId", h.GetErasure)
+	}
+}
+
+// RequestErasure handles POST /api/v1/admin/erasures. A dry run returns the report of
+// what would be erased now without recording a request.
+func (h *ErasureHandler) RequestErasure(c *gin.Context) {
+	adminID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req application.RequestErasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := h.service.RequestErasure(c.Request.Context(), adminID, req)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	if req.DryRun {
+		response.Success(c, result)
+		return
+	}
+	response.Created(c, result)
+}
+
+// GetErasure handles GET /api/v1/admin/erasures/:erasureId, returning the request's
+// audit report.
+func (h *ErasureHandler) GetErasure(c *gin.Context) {
+	erasureID, err := uuid.Parse(c.Param("erasureId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid erasure ID format")
+		return
+	}
+
+	result, err := h.service.GetErasure(c.Request.Context(), erasureID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// ETASubscriptionHandler manages other services' subscriptions to booking ETAs.
+type ETASubscriptionHandler struct {
+	service *application.ETASubscriptionService
+}
+
+// NewETASubscriptionHandler creates a new ETASubscriptionHandler.
+func NewETASubscriptionHandler(service *application.ETASubscriptionService) *ETASubscriptionHandler {
+	return &ETASubscriptionHandler{service: service}
+}
+
+// RegisterInternalRoutes registers the subscription routes used by other platform services.
+func (h *ETASubscriptionHandler) RegisterInternalRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	subs := r.Group("/internal/tracking/:bookingId/eta-subscriptions")
+	subs.Use(middleware.AuthMiddleware(jwtManager), requireRole(auth.RoleAdmin, application.RoleService))
+	{
+		subs.POST("", h.Subscribe)
+		subs.GET("", h.ListSubscriptions)
+		subs.DELETE("/:subscriptionId", h.Unsubscribe)
+	}
+}
+
+// Subscribe handles POST /api/v1/internal/tracking/:bookingId/eta-subscriptions.
+func (h *ETASubscriptionHandler) Subscribe(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	var req application.SubscribeETARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := h.service.Subscribe(c.Request.Context(), bookingID, req)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ListSubscriptions handles GET /api/v1/internal/tracking/:bookingId/eta-subscriptions.
+func (h *ETASubscriptionHandler) ListSubscriptions(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	result, err := h.service.ListSubscriptions(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Unsubscribe handles DELETE /api/v1/internal/tracking/:bookingId/eta-subscriptions/:subscriptionId.
+func (h *ETASubscriptionHandler) Unsubscribe(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+	subscriptionID, err := uuid.Parse(c.Param("subscriptionId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid subscription ID format")
+		return
+	}
+
+	if err := h.service.Unsubscribe(c.Request.Context(), bookingID, subscriptionID); err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"id": subscriptionID})
+}
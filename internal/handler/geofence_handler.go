@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// GeofenceHandler handles HTTP requests for booking geofences.
+type GeofenceHandler struct {
+	service  *application.GeofenceService
+	tracking *application.TrackingService
+}
+
+// NewGeofenceHandler creates a new GeofenceHandler.
+func NewGeofenceHandler(service *application.GeofenceService, tracking *application.TrackingService) *GeofenceHandler {
+	return &GeofenceHandler{service: service, tracking: tracking}
+}
+
+// RegisterRoutes registers geofence routes on the given router group.
+func (h *GeofenceHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	tracking := r.Group("/tracking")
+	tracking.Use(middleware.AuthMiddleware(jwtManager), requireBookingAccess(h.tracking))
+	{
+		tracking.POST("/:bookingId/geofences", h.CreateGeofence)
+		tracking.GET("/:bookingId/geofences", h.ListGeofences)
+		tracking.DELETE("/:bookingId/geofences/:geofenceId", h.DeactivateGeofence)
+	}
+}
+
+// CreateGeofence handles POST /api/v1/tracking/:bookingId/geofences.
+func (h *GeofenceHandler) CreateGeofence(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	var req application.CreateGeofenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := h.service.CreateGeofence(c.Request.Context(), bookingID, req)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Created(c, result)
+}
+
+// ListGeofences handles GET /api/v1/tracking/:bookingId/geofences.
+func (h *GeofenceHandler) ListGeofences(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	result, err := h.service.ListGeofences(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// DeactivateGeofence handles DELETE /api/v1/tracking/:bookingId/geofences/:geofenceId.
+func (h *GeofenceHandler) DeactivateGeofence(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+	geofenceID, err := uuid.Parse(c.Param("geofenceId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid geofence ID format")
+		return
+	}
+
+	if err := h.service.DeactivateGeofence(c.Request.Context(), bookingID, geofenceID); err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"id": geofenceID, "active": false})
+}
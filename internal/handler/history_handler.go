@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// HistoryHandler serves the lifecycle history of trips.
+type HistoryHandler struct {
+	history *application.TrackHistory
+}
+
+// NewHistoryHandler creates a new HistoryHandler.
+func NewHistoryHandler(history *application.TrackHistory) *HistoryHandler {
+	return &HistoryHandler{history: history}
+}
+
+// RegisterRoutes registers the history route on the given router group. The history
+// names who changed each trip and why, so it is only served to support agents and admins.
+func (h *HistoryHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	r.GET("/tracking/:bookingId/history", middleware.AuthMiddleware(jwtManager), h.GetHistory)
+}
+
+// GetHistory handles GET /api/v1/tracking/:bookingId/history.
+func (h *HistoryHandler) GetHistory(c *gin.Context) {
+	if role, _ := middleware.GetUserRole(c); role != application.RoleSupport && role != auth.RoleAdmin {
+		apperror.Abort(c, apperror.CodeForbidden, "insufficient permissions")
+		return
+	}
+
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	result, err := h.history.GetHistory(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
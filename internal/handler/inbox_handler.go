@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// InboxHandler serves the authenticated user's undelivered messages.
+type InboxHandler struct {
+	service *application.InboxService
+}
+
+// NewInboxHandler creates a new InboxHandler.
+func NewInboxHandler(service *application.InboxService) *InboxHandler {
+	return &InboxHandler{service: service}
+}
+
+// RegisterRoutes registers the inbox route on the given router group.
+func (h *InboxHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	r.GET("/inbox", middleware.AuthMiddleware(jwtManager), h.Sync)
+}
+
+// Sync handles GET /api/v1/inbox?since=<cursor>&limit=50.
+func (h *InboxHandler) Sync(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "user not authenticated")
+		return
+	}
+
+	since, err := strconv.ParseInt(c.DefaultQuery("since", "0"), 10, 64)
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, "since must be an integer cursor")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(application.DefaultInboxSyncLimit)))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, "limit must be an integer")
+		return
+	}
+
+	result, err := h.service.Sync(c.Request.Context(), userID, since, limit)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
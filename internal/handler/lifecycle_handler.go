@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+// preStopGrace is how long the pre-stop hook waits for connections to close after the
+// last close was sent.
+const preStopGrace = 3 * time.Second
+
+// LifecycleHandler serves the hooks the orchestrator calls around an instance's shutdown.
+type LifecycleHandler struct {
+	hub         *ws.Hub
+	drainPeriod time.Duration
+	logger      *zap.Logger
+}
+
+// NewLifecycleHandler creates a new LifecycleHandler.
+func NewLifecycleHandler(hub *ws.Hub, drainPeriod time.Duration, logger *zap.Logger) *LifecycleHandler {
+	return &LifecycleHandler{hub: hub, drainPeriod: drainPeriod, logger: logger}
+}
+
+// RegisterRoutes registers the lifecycle routes on the engine.
+func (h *LifecycleHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/lifecycle/pre-stop", h.PreStop)
+}
+
+// PreStop handles GET /lifecycle/pre-stop, the pre-stop hook. It drains the instance's
+// WebSocket connections and returns once they have closed, so that clients have moved
+// to other instances before the shutdown signal arrives. Only requests from the
+// instance itself are accepted.
+func (h *LifecycleHandler) PreStop(c *gin.Context) {
+	if ip := net.ParseIP(c.RemoteIP()); ip == nil || !ip.IsLoopback() {
+		apperror.Abort(c, apperror.CodeForbidden, "the pre-stop hook can only be called from the instance itself")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.drainPeriod+preStopGrace)
+	defer cancel()
+
+	started := time.Now()
+	closed := h.hub.Drain(ctx, h.drainPeriod)
+	remaining := h.hub.Connections()
+	h.logger.Info("pre-stop drain finished",
+		zap.Int("closed", closed),
+		zap.Int("remaining", remaining),
+		zap.Duration("took", time.Since(started)),
+	)
+
+	response.Success(c, gin.H{"closed": closed, "remaining": remaining})
+}
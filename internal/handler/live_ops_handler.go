@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/origin"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+// LiveOpsHandler serves the live ops dashboard stream.
+type LiveOpsHandler struct {
+	aggregator  *application.LiveOpsAggregator
+	hub         *ws.Hub
+	jwtManager  *auth.JWTManager
+	upgrader    *websocket.Upgrader
+	queryTokens bool
+	logger      *zap.Logger
+}
+
+// NewLiveOpsHandler creates a new LiveOpsHandler.
+func NewLiveOpsHandler(aggregator *application.LiveOpsAggregator, hub *ws.Hub, jwtManager *auth.JWTManager, origins *origin.Policy, queryTokens bool, logger *zap.Logger) *LiveOpsHandler {
+	return &LiveOpsHandler{aggregator: aggregator, hub: hub, jwtManager: jwtManager, upgrader: newUpgrader(origins), queryTokens: queryTokens, logger: logger}
+}
+
+// RegisterWSRoute registers the live ops stream route on the engine.
+func (h *LiveOpsHandler) RegisterWSRoute(r *gin.Engine) {
+	r.GET("/ws/admin/live", h.HandleWebSocket)
+}
+
+// HandleWebSocket upgrades an admin's connection to stream the aggregated fleet state.
+// The connection joins no booking room; frames sent by the client other than
+// auth_refresh are ignored.
+func (h *LiveOpsHandler) HandleWebSocket(c *gin.Context) {
+	claims, ok := authenticateWebSocket(c, h.jwtManager, h.queryTokens)
+	if !ok {
+		return
+	}
+	if claims.Role != auth.RoleAdmin {
+		apperror.Abort(c, apperror.CodeForbidden, "insufficient permissions")
+		return
+	}
+
+	admission, ok := admitWebSocket(c, h.hub, uuid.Nil, claims.UserID)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		admission.Release()
+		h.logger.Error("failed to upgrade to websocket", zap.Error(err))
+		return
+	}
+
+	validate := func(token string) (time.Time, error) {
+		refreshed, err := h.jwtManager.ValidateAccessToken(token)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if refreshed.UserID != claims.UserID {
+			return time.Time{}, errTokenUserMismatch
+		}
+		return tokenExpiry(refreshed), nil
+	}
+
+	client := ws.NewClient(conn, uuid.Nil, tokenExpiry(claims), validate)
+	client.Hold(admission)
+	client.Role = string(claims.Role)
+	client.UserID = claims.UserID
+	client.OnClose = func() { h.aggregator.Unsubscribe(client) }
+	h.aggregator.Subscribe(client)
+
+	go client.WritePump(h.hub)
+	go client.ReadPump(h.hub)
+}
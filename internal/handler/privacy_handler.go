@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// PrivacyHandler serves runners' location data-window statements.
+type PrivacyHandler struct {
+	service *application.LocationPrivacyService
+}
+
+// NewPrivacyHandler creates a new PrivacyHandler.
+func NewPrivacyHandler(service *application.LocationPrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{service: service}
+}
+
+// RegisterRoutes registers the statement route on the given router group.
+func (h *PrivacyHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	r.GET("/runners/:runnerId/data-windows", middleware.AuthMiddleware(jwtManager), h.GetDataWindows)
+}
+
+// GetDataWindows handles GET /api/v1/runners/:runnerId/data-windows?from=&to=. Runners
+// may read their own statement; admins may read any.
+func (h *PrivacyHandler) GetDataWindows(c *gin.Context) {
+	runnerID, err := uuid.Parse(c.Param("runnerId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid runner ID format")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "user not authenticated")
+		return
+	}
+	if role, _ := middleware.GetUserRole(c); userID != runnerID && role != auth.RoleAdmin {
+		apperror.Abort(c, apperror.CodeForbidden, "cannot read another runner's data windows")
+		return
+	}
+
+	result, err := h.service.GetDataWindowStatement(c.Request.Context(), runnerID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
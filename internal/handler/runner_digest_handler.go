@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// RunnerDigestHandler serves runners' daily digests.
+type RunnerDigestHandler struct {
+	service *application.RunnerDigestService
+}
+
+// NewRunnerDigestHandler creates a new RunnerDigestHandler.
+func NewRunnerDigestHandler(service *application.RunnerDigestService) *RunnerDigestHandler {
+	return &RunnerDigestHandler{service: service}
+}
+
+// RegisterRoutes registers the digest route on the given router group.
+func (h *RunnerDigestHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	r.GET("/runners/:runnerId/digest", middleware.AuthMiddleware(jwtManager), h.GetDailyDigest)
+}
+
+// GetDailyDigest handles GET /api/v1/runners/:runnerId/digest?date=YYYY-MM-DD. Runners
+// may read their own digest; admins may read any.
+func (h *RunnerDigestHandler) GetDailyDigest(c *gin.Context) {
+	runnerID, err := uuid.Parse(c.Param("runnerId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid runner ID format")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "user not authenticated")
+		return
+	}
+	if role, _ := middleware.GetUserRole(c); userID != runnerID && role != auth.RoleAdmin {
+		apperror.Abort(c, apperror.CodeForbidden, "cannot read another runner's digest")
+		return
+	}
+
+	result, err := h.service.GetDailyDigest(c.Request.Context(), runnerID, c.Query("date"))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// SafetyHandler serves the safety alerts raised on trips.
+type SafetyHandler struct {
+	service  *application.SafetyService
+	tracking *application.TrackingService
+}
+
+// NewSafetyHandler creates a new SafetyHandler.
+func NewSafetyHandler(service *application.SafetyService, tracking *application.TrackingService) *SafetyHandler {
+	return &SafetyHandler{service: service, tracking: tracking}
+}
+
+// RegisterRoutes registers the safety alert route on the given router group.
+func (h *SafetyHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	r.GET("/tracking/:bookingId/alerts", middleware.AuthMiddleware(jwtManager), requireBookingAccess(h.tracking), h.GetAlerts)
+}
+
+// GetAlerts handles GET /api/v1/tracking/:bookingId/alerts.
+func (h *SafetyHandler) GetAlerts(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	result, err := h.service.GetAlerts(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -1,25 +1,56 @@
 package handler
 
 import (
-	"net/http"
+	"context"
+	"errors"
+	"io"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
 
 	"github.com/Kilat-Pet-Delivery/lib-common/auth"
 	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
 	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
 	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/origin"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
 )
 
+// shareRevocationCheckPeriod is how often an open shared stream checks whether its link
+// was revoked.
+const shareRevocationCheckPeriod = 30 * time.Second
+
 // ShareHandler handles HTTP requests for trip sharing.
 type ShareHandler struct {
-	service *application.ShareService
+	service  *application.ShareService
+	tracking *application.TrackingService
+	hub      *ws.Hub
+	origins  *origin.Policy
+	upgrader *websocket.Upgrader
+	logger   *zap.Logger
 }
 
-// NewShareHandler creates a new ShareHandler.
-func NewShareHandler(service *application.ShareService) *ShareHandler {
-	return &ShareHandler{service: service}
+// NewShareHandler creates a new ShareHandler. The tracking service authorizes who may
+// share a booking.
+func NewShareHandler(
+	service *application.ShareService,
+	tracking *application.TrackingService,
+	hub *ws.Hub,
+	origins *origin.Policy,
+	logger *zap.Logger,
+) *ShareHandler {
+	return &ShareHandler{
+		service:  service,
+		tracking: tracking,
+		hub:      hub,
+		origins:  origins,
+		upgrader: newUpgrader(origins),
+		logger:   logger,
+	}
 }
 
 // RegisterRoutes registers authenticated share routes.
@@ -27,42 +58,174 @@ func (h *ShareHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTMa
 	authMW := middleware.AuthMiddleware(jwtManager)
 
 	tracking := r.Group("/tracking")
-	tracking.POST("/:bookingId/share", authMW, h.CreateShareLink)
+	tracking.POST("/:bookingId/share", authMW, requireBookingAccess(h.tracking), h.CreateShareLink)
+	tracking.GET("/:bookingId/share", authMW, requireBookingAccess(h.tracking), h.ListShareLinks)
+	tracking.DELETE("/:bookingId/share", authMW, requireBookingAccess(h.tracking), h.RevokeShareLinks)
+
+	// Public route — no auth required, restricted to allowed origins
+	tracking.GET("/shared/:token", h.origins.Middleware(), h.GetSharedTracking)
+}
 
-	// Public route — no auth required
-	tracking.GET("/shared/:token", h.GetSharedTracking)
+// RegisterWSRoute registers the public shared trip WebSocket route on the engine.
+func (h *ShareHandler) RegisterWSRoute(r *gin.Engine) {
+	r.GET("/ws/shared/:token", h.HandleWebSocket)
 }
 
 // CreateShareLink handles POST /api/v1/tracking/:bookingId/share.
 func (h *ShareHandler) CreateShareLink(c *gin.Context) {
 	bookingID, err := uuid.Parse(c.Param("bookingId"))
 	if err != nil {
-		response.BadRequest(c, "invalid booking ID")
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID")
+		return
+	}
+
+	var req application.CreateShareLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
 		return
 	}
 
-	result, err := h.service.CreateShareLink(c.Request.Context(), bookingID)
+	result, err := h.service.CreateShareLink(c.Request.Context(), bookingID, req)
 	if err != nil {
-		response.Error(c, err)
+		apperror.Respond(c, err)
 		return
 	}
 
 	response.Created(c, result)
 }
 
+// ListShareLinks handles GET /api/v1/tracking/:bookingId/share.
+func (h *ShareHandler) ListShareLinks(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID")
+		return
+	}
+
+	result, err := h.service.ListShareLinks(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// RevokeShareLinks handles DELETE /api/v1/tracking/:bookingId/share. All active links
+// are revoked unless the id query parameter names a single one.
+func (h *ShareHandler) RevokeShareLinks(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID")
+		return
+	}
+
+	var shareID *uuid.UUID
+	if v := c.Query("id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			apperror.Abort(c, apperror.CodeInvalidID, "invalid share link ID")
+			return
+		}
+		shareID = &id
+	}
+
+	result, err := h.service.RevokeShareLinks(c.Request.Context(), bookingID, shareID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
 // GetSharedTracking handles GET /api/v1/tracking/shared/:token (public, no auth).
 func (h *ShareHandler) GetSharedTracking(c *gin.Context) {
 	token := c.Param("token")
 	if token == "" {
-		response.BadRequest(c, "token is required")
+		apperror.Abort(c, apperror.CodeInvalidRequest, "token is required")
 		return
 	}
 
 	result, err := h.service.GetSharedTracking(c.Request.Context(), token)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "success": false})
+		apperror.Respond(c, err)
 		return
 	}
 
-	response.Success(c, result)
+	respondViewer(c, result)
+}
+
+// HandleWebSocket handles WS /ws/shared/:token (public, no auth). The connection joins
+// the booking room read-only and counts as one view of the link. It is closed when the
+// link expires or is revoked.
+func (h *ShareHandler) HandleWebSocket(c *gin.Context) {
+	token := c.Param("token")
+
+	stream, err := h.service.OpenSharedStream(c.Request.Context(), token)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	admission, ok := admitWebSocket(c, h.hub, stream.BookingID, uuid.Nil)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		admission.Release()
+		h.logger.Error("failed to upgrade shared websocket", zap.Error(err))
+		return
+	}
+
+	// Share tokens cannot be refreshed; the link's expiry bounds the connection.
+	client := ws.NewClient(conn, stream.BookingID, stream.ExpiresAt, nil)
+	client.Hold(admission)
+	client.ShareID = stream.ShareID
+	client.SnapshotHistory = snapshotHistory(c)
+	resumeFrom(c, client)
+	h.hub.Register(client)
+
+	done := make(chan struct{})
+	go client.WritePump(h.hub)
+	go func() {
+		defer close(done)
+		client.ReadPump(h.hub)
+	}()
+	go h.watchRevocation(client, token, done)
+}
+
+// watchRevocation disconnects a shared stream once its link is revoked. It returns when
+// done is closed.
+func (h *ShareHandler) watchRevocation(client *ws.Client, token string, done <-chan struct{}) {
+	ticker := time.NewTicker(shareRevocationCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			revoked, err := h.service.IsShareLinkRevoked(ctx, token)
+			cancel()
+			if err != nil {
+				h.logger.Warn("failed to check share link revocation",
+					zap.String("share_id", client.ShareID.String()),
+					zap.Error(err),
+				)
+				continue
+			}
+			if revoked {
+				h.logger.Info("closing shared stream of revoked link",
+					zap.String("share_id", client.ShareID.String()),
+					zap.String("booking_id", client.BookingID.String()),
+				)
+				h.hub.Unregister(client)
+				return
+			}
+		}
+	}
 }
@@ -0,0 +1,54 @@
+package handler
+
+import "github.com/Kilat-Pet-Delivery/service-tracking/internal/slo"
+
+// SLO classes of the tracking routes.
+const (
+	// SLOClassCritical covers location ingestion and the reads that show where a pet is.
+	SLOClassCritical = "critical"
+	// SLOClassStandard covers the other interactive reads and writes.
+	SLOClassStandard = "standard"
+)
+
+// ClassifySLORoutes tags the routes measured against an SLO. WebSocket and SSE routes
+// are long-lived and location pings wait for the runner by design, so they are left
+// out, as are admin routes.
+func ClassifySLORoutes(t *slo.Tracker) {
+	t.Classify(SLOClassCritical,
+		"POST /api/v1/tracking/:bookingId/waypoints",
+		"GET /api/v1/tracking/:bookingId",
+		"GET /api/v1/tracking/:bookingId/current",
+		"GET /api/v1/tracking/shared/:token",
+		"GET /api/v1/widget/tracking",
+		"GET /api/v1/widget/tracking/position",
+		"GET /embed/tracking/:token",
+		"POST /api/v1/chat/:bookingId/messages",
+	)
+	t.Classify(SLOClassStandard,
+		"POST /api/v1/tracking/:bookingId/locations",
+		"GET /api/v1/tracking/:bookingId/route",
+		"GET /api/v1/tracking/:bookingId/waypoints",
+		"GET /api/v1/tracking/:bookingId/eta",
+		"GET /api/v1/tracking/:bookingId/position",
+		"GET /api/v1/tracking/:bookingId/segments",
+		"GET /api/v1/tracking/:bookingId/stats",
+		"POST /api/v1/tracking/:bookingId/cancel",
+		"PATCH /api/v1/tracking/:bookingId/pause",
+		"POST /api/v1/tracking/:bookingId/telemetry",
+		"GET /api/v1/widget/tracking/route",
+		"GET /api/v1/chat/:bookingId/messages",
+		"POST /api/v1/chat/:bookingId/attachments",
+		"GET /api/v1/inbox",
+		"GET /api/v1/tracking/:bookingId/certificate",
+		"POST /api/v1/certificates/verify",
+		"PUT /api/v1/internal/tracking/:bookingId/destination",
+		"GET /api/v1/internal/runners/:runnerId/queue",
+		"POST /api/v1/internal/tracking/:bookingId/eta-subscriptions",
+		"GET /api/v1/internal/tracking/:bookingId/eta-subscriptions",
+		"DELETE /api/v1/internal/tracking/:bookingId/eta-subscriptions/:subscriptionId",
+		"POST /api/v1/webhooks",
+		"GET /api/v1/webhooks",
+		"DELETE /api/v1/webhooks/:webhookId",
+		"GET /api/v1/webhooks/:webhookId/deliveries",
+	)
+}
@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/origin"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+// supportSessionCheckPeriod is how often a mirrored support stream checks whether its
+// session was closed.
+const supportSessionCheckPeriod = 30 * time.Second
+
+// SupportHandler handles HTTP requests for read-only support sessions.
+type SupportHandler struct {
+	service     *application.SupportService
+	hub         *ws.Hub
+	jwtManager  *auth.JWTManager
+	upgrader    *websocket.Upgrader
+	queryTokens bool
+	logger      *zap.Logger
+}
+
+// NewSupportHandler creates a new SupportHandler.
+func NewSupportHandler(
+	service *application.SupportService,
+	hub *ws.Hub,
+	jwtManager *auth.JWTManager,
+	origins *origin.Policy,
+	queryTokens bool,
+	logger *zap.Logger,
+) *SupportHandler {
+	return &SupportHandler{
+		service:     service,
+		hub:         hub,
+		jwtManager:  jwtManager,
+		upgrader:    newUpgrader(origins),
+		queryTokens: queryTokens,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registers the support session routes on the given router group. The
+// service checks the agent's role when a session is opened; afterwards only the agent
+// who opened a session may use it.
+func (h *SupportHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	sessions := r.Group("/support/sessions")
+	sessions.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		sessions.POST("", h.OpenSession)
+		sessions.DELETE("/:sessionId", h.CloseSession)
+		sessions.GET("/:sessionId/tracking", h.GetTracking)
+		sessions.GET("/:sessionId/messages", h.GetMessages)
+		sessions.GET("/:sessionId/audit", requireRole(auth.RoleAdmin), h.GetAudit)
+	}
+}
+
+// RegisterWSRoute registers the mirrored support stream route on the engine.
+func (h *SupportHandler) RegisterWSRoute(r *gin.Engine) {
+	r.GET("/ws/support/:sessionId", h.HandleWebSocket)
+}
+
+// OpenSession handles POST /api/v1/support/sessions.
+func (h *SupportHandler) OpenSession(c *gin.Context) {
+	agentID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
+		return
+	}
+	role, _ := middleware.GetUserRole(c)
+
+	var req application.OpenSupportSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	session, err := h.service.OpenSession(c.Request.Context(), agentID, role, c.ClientIP(), req)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Created(c, session)
+}
+
+// CloseSession handles DELETE /api/v1/support/sessions/:sessionId.
+func (h *SupportHandler) CloseSession(c *gin.Context) {
+	sessionID, agentID, ok := h.sessionAndAgent(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CloseSession(c.Request.Context(), sessionID, agentID, c.ClientIP()); err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"id": sessionID, "closed": true})
+}
+
+// GetTracking handles GET /api/v1/support/sessions/:sessionId/tracking.
+func (h *SupportHandler) GetTracking(c *gin.Context) {
+	sessionID, agentID, ok := h.sessionAndAgent(c)
+	if !ok {
+		return
+	}
+
+	tracking, err := h.service.GetTracking(c.Request.Context(), sessionID, agentID, c.ClientIP())
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, tracking)
+}
+
+// GetMessages handles GET /api/v1/support/sessions/:sessionId/messages.
+func (h *SupportHandler) GetMessages(c *gin.Context) {
+	sessionID, agentID, ok := h.sessionAndAgent(c)
+	if !ok {
+		return
+	}
+
+	page, limit := parseChatPagination(c)
+
+	messages, total, err := h.service.GetMessages(c.Request.Context(), sessionID, agentID, c.ClientIP(), page, limit)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Paginated(c, messages, total, page, limit)
+}
+
+// GetAudit handles GET /api/v1/support/sessions/:sessionId/audit.
+func (h *SupportHandler) GetAudit(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("sessionId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid session ID format")
+		return
+	}
+
+	events, err := h.service.GetAudit(c.Request.Context(), sessionID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, events)
+}
+
+// HandleWebSocket handles WS /ws/support/:sessionId. The agent joins the
+// booking room read-only and receives the frames participants see, including chat. The
+// connection is closed when the session expires or is closed.
+func (h *SupportHandler) HandleWebSocket(c *gin.Context) {
+	claims, ok := authenticateWebSocket(c, h.jwtManager, h.queryTokens)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("sessionId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid session ID format")
+		return
+	}
+
+	bookingID, sessionExpiry, err := h.service.AuthorizeStream(c.Request.Context(), sessionID, claims.UserID, c.ClientIP())
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	admission, ok := admitWebSocket(c, h.hub, bookingID, claims.UserID)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		admission.Release()
+		h.logger.Error("failed to upgrade support websocket", zap.Error(err))
+		return
+	}
+
+	// Refreshed tokens never extend the connection past the session's expiry.
+	validate := func(token string) (time.Time, error) {
+		refreshed, err := h.jwtManager.ValidateAccessToken(token)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if refreshed.UserID != claims.UserID {
+			return time.Time{}, errTokenUserMismatch
+		}
+		return earliestExpiry(tokenExpiry(refreshed), sessionExpiry), nil
+	}
+
+	client := ws.NewClient(conn, bookingID, earliestExpiry(tokenExpiry(claims), sessionExpiry), validate)
+	client.Hold(admission)
+	client.SnapshotHistory = snapshotHistory(c)
+	resumeFrom(c, client)
+	client.Role = string(application.RoleSupport)
+	client.Scope = wsScope(application.RoleSupport)
+	h.hub.Register(client)
+
+	done := make(chan struct{})
+	go client.WritePump(h.hub)
+	go func() {
+		defer close(done)
+		client.ReadPump(h.hub)
+	}()
+	go h.watchSession(client, sessionID, done)
+}
+
+// watchSession disconnects a mirrored stream once its session is closed. It returns
+// when done is closed.
+func (h *SupportHandler) watchSession(client *ws.Client, sessionID uuid.UUID, done <-chan struct{}) {
+	ticker := time.NewTicker(supportSessionCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			open, err := h.service.IsSessionOpen(ctx, sessionID)
+			cancel()
+			if err != nil {
+				h.logger.Warn("failed to check support session",
+					zap.String("session_id", sessionID.String()),
+					zap.Error(err),
+				)
+				continue
+			}
+			if !open {
+				h.logger.Info("closing stream of closed support session",
+					zap.String("session_id", sessionID.String()),
+					zap.String("booking_id", client.BookingID.String()),
+				)
+				h.hub.Unregister(client)
+				return
+			}
+		}
+	}
+}
+
+// sessionAndAgent reads the session ID path parameter and the authenticated agent,
+// aborting the request if either is missing.
+func (h *SupportHandler) sessionAndAgent(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	sessionID, err := uuid.Parse(c.Param("sessionId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid session ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+	agentID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return sessionID, agentID, true
+}
+
+// earliestExpiry returns the earlier of two expiries, where zero means none.
+func earliestExpiry(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// TemperatureHandler handles HTTP requests for carrier telemetry and the per-species
+// temperature thresholds it is checked against.
+type TemperatureHandler struct {
+	service  *application.TemperatureService
+	tracking *application.TrackingService
+}
+
+// NewTemperatureHandler creates a new TemperatureHandler.
+func NewTemperatureHandler(service *application.TemperatureService, tracking *application.TrackingService) *TemperatureHandler {
+	return &TemperatureHandler{service: service, tracking: tracking}
+}
+
+// RegisterRoutes registers telemetry and threshold admin routes on the given router group.
+func (h *TemperatureHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	authMW := middleware.AuthMiddleware(jwtManager)
+
+	r.POST("/tracking/:bookingId/telemetry", authMW, h.IngestCarrierTelemetry)
+	r.GET("/tracking/:bookingId/telemetry", authMW, requireBookingAccess(h.tracking), h.GetTelemetry)
+
+	admin := r.Group("/admin/temperature-thresholds")
+	admin.Use(authMW, requireRole(auth.RoleAdmin))
+	{
+		admin.GET("", h.ListThresholds)
+		admin.PUT("/:species", h.SetThreshold)
+		admin.DELETE("/:species", h.DeleteThreshold)
+	}
+}
+
+// IngestCarrierTelemetry handles POST /api/v1/tracking/:bookingId/telemetry.
+func (h *TemperatureHandler) IngestCarrierTelemetry(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "user not authenticated")
+		return
+	}
+
+	var req application.IngestCarrierTelemetryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := h.service.IngestCarrierTelemetry(c.Request.Context(), bookingID, userID, req)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetTelemetry handles GET /api/v1/tracking/:bookingId/telemetry.
+func (h *TemperatureHandler) GetTelemetry(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	result, err := h.service.GetTelemetry(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ListThresholds handles GET /api/v1/admin/temperature-thresholds.
+func (h *TemperatureHandler) ListThresholds(c *gin.Context) {
+	result, err := h.service.ListThresholds(c.Request.Context())
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// SetThreshold handles PUT /api/v1/admin/temperature-thresholds/:species.
+func (h *TemperatureHandler) SetThreshold(c *gin.Context) {
+	var req application.SetTemperatureThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := h.service.SetThreshold(c.Request.Context(), c.Param("species"), req)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// DeleteThreshold handles DELETE /api/v1/admin/temperature-thresholds/:species.
+func (h *TemperatureHandler) DeleteThreshold(c *gin.Context) {
+	species := c.Param("species")
+	if err := h.service.DeleteThreshold(c.Request.Context(), species); err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"species": species, "deleted": true})
+}
@@ -1,7 +1,15 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -11,25 +19,108 @@ import (
 	"github.com/Kilat-Pet-Delivery/lib-common/auth"
 	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
 	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
 	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/origin"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/overload"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ratelimit"
 	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		// In production, restrict to specific origins.
-		return true
-	},
+// errTokenUserMismatch is returned when a WebSocket token refresh is for a different user.
+var errTokenUserMismatch = errors.New("refreshed token belongs to a different user")
+
+// newUpgrader returns a WebSocket upgrader that negotiates permessage-deflate and the
+// ws.Subprotocols with clients that ask for them, and refuses browsers from origins off
+// the allow-list.
+func newUpgrader(origins *origin.Policy) *websocket.Upgrader {
+	return &websocket.Upgrader{
+		ReadBufferSize:    1024,
+		WriteBufferSize:   1024,
+		EnableCompression: true,
+		Subprotocols:      ws.Subprotocols,
+		CheckOrigin:       origins.CheckRequest,
+	}
+}
+
+// admitWebSocket reserves a connection slot for a WebSocket upgrade, aborting with
+// 429 too_many_connections if a connection limit is reached. The admission must be
+// released if the upgrade fails, and held by the client otherwise.
+func admitWebSocket(c *gin.Context, hub *ws.Hub, bookingID, userID uuid.UUID) (*ws.Admission, bool) {
+	admission, err := hub.Admit(bookingID, userID)
+	if err != nil {
+		apperror.Abort(c, apperror.CodeTooManyConnections, err.Error())
+		return nil, false
+	}
+	return admission, true
+}
+
+// wsTokenProtocolPrefix prefixes an access token offered as a Sec-WebSocket-Protocol
+// entry, for browsers, which cannot set the Authorization header on a WebSocket. The
+// entry is never selected, so the token is not echoed in the handshake response.
+const wsTokenProtocolPrefix = "bearer."
+
+// wsAccessToken returns the access token of a WebSocket upgrade, read from the
+// Authorization header, a bearer.<token> subprotocol or, if queryTokens is set, the
+// deprecated token query parameter, which ends up in access and proxy logs.
+func wsAccessToken(r *http.Request, queryTokens bool) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		return token
+	}
+	for _, protocol := range websocket.Subprotocols(r) {
+		if token, ok := strings.CutPrefix(protocol, wsTokenProtocolPrefix); ok && token != "" {
+			return token
+		}
+	}
+	if queryTokens {
+		return r.URL.Query().Get("token")
+	}
+	return ""
+}
+
+// authenticateWebSocket validates the access token of a WebSocket upgrade, aborting
+// with 401 unauthorized if it is missing or invalid. queryTokens is as for
+// wsAccessToken.
+func authenticateWebSocket(c *gin.Context, jwtManager *auth.JWTManager, queryTokens bool) (*auth.Claims, bool) {
+	token := wsAccessToken(c.Request, queryTokens)
+	if token == "" {
+		apperror.Abort(c, apperror.CodeUnauthorized, "access token is required in the Authorization header or a bearer subprotocol")
+		return nil, false
+	}
+
+	claims, err := jwtManager.ValidateAccessToken(token)
+	if err != nil {
+		apperror.Abort(c, apperror.CodeUnauthorized, "invalid or expired token")
+		return nil, false
+	}
+	return claims, true
+}
+
+// wsScope returns the WebSocket scope of an authenticated user's role: admins and
+// support agents are sent every frame, runners and customers only what is meant for
+// them.
+func wsScope(role auth.UserRole) ws.Scope {
+	switch role {
+	case auth.RoleAdmin, application.RoleSupport:
+		return ws.ScopeFull
+	case auth.RoleRunner:
+		return ws.ScopeRunner
+	default:
+		return ws.ScopeCustomer
+	}
 }
 
 // TrackingHandler handles HTTP and WebSocket requests for tracking.
 type TrackingHandler struct {
-	service    *application.TrackingService
-	hub        *ws.Hub
-	jwtManager *auth.JWTManager
-	logger     *zap.Logger
+	service     *application.TrackingService
+	hub         *ws.Hub
+	jwtManager  *auth.JWTManager
+	overload    *overload.Controller
+	limiter     *ratelimit.Limiter
+	upgrader    *websocket.Upgrader
+	queryTokens bool
+	logger      *zap.Logger
 }
 
 // NewTrackingHandler creates a new TrackingHandler.
@@ -37,13 +128,21 @@ func NewTrackingHandler(
 	service *application.TrackingService,
 	hub *ws.Hub,
 	jwtManager *auth.JWTManager,
+	overloadCtl *overload.Controller,
+	limiter *ratelimit.Limiter,
+	origins *origin.Policy,
+	queryTokens bool,
 	logger *zap.Logger,
 ) *TrackingHandler {
 	return &TrackingHandler{
-		service:    service,
-		hub:        hub,
-		jwtManager: jwtManager,
-		logger:     logger,
+		service:     service,
+		hub:         hub,
+		jwtManager:  jwtManager,
+		overload:    overloadCtl,
+		limiter:     limiter,
+		upgrader:    newUpgrader(origins),
+		queryTokens: queryTokens,
+		logger:      logger,
 	}
 }
 
@@ -52,64 +151,651 @@ func (h *TrackingHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JW
 	tracking := r.Group("/tracking")
 	tracking.Use(middleware.AuthMiddleware(jwtManager))
 	{
-		tracking.GET("/:bookingId", h.GetTracking)
-		tracking.GET("/:bookingId/route", h.GetRouteGeoJSON)
+		// Waypoint ingest authorizes the runner itself so mismatches are reported as security events.
+		tracking.POST("/:bookingId/waypoints", h.IngestWaypoint)
+		tracking.POST("/:bookingId/locations", requireRole(auth.RoleRunner), h.limiter.Middleware(ratelimit.ScopeLocations), h.IngestLocations)
+
+		booking := tracking.Group("/:bookingId", requireBookingAccess(h.service))
+		booking.GET("", h.GetTracking)
+		booking.GET("/route", h.overload.Middleware(), h.GetRouteGeoJSON)
+		booking.GET("/waypoints", h.overload.Middleware(), h.ListWaypoints)
+		booking.GET("/waypoints/export", h.overload.Middleware(), h.ExportWaypointsCSV)
+		booking.GET("/eta", h.overload.Middleware(), h.GetETA)
+		booking.GET("/current", h.GetCurrentPosition)
+		booking.POST("/ping", h.RequestLocationPing)
+		booking.GET("/position", h.GetPositionAt)
+		booking.GET("/segments", h.overload.Middleware(), h.GetSegmentStats)
+		booking.GET("/stats", h.overload.Middleware(), h.GetTripStats)
+		booking.GET("/replay", h.overload.Middleware(), h.ReplayTrip)
+		booking.POST("/cancel", h.CancelTracking)
+		booking.PATCH("/pause", requireRole(auth.RoleRunner), h.SetTrackingPaused)
+	}
+}
+
+// RegisterInternalRoutes registers routes used by other platform services such as dispatch.
+func (h *TrackingHandler) RegisterInternalRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	internal := r.Group("/internal")
+	internal.Use(middleware.AuthMiddleware(jwtManager), requireRole(auth.RoleAdmin, application.RoleService))
+	{
+		internal.PUT("/tracking/:bookingId/destination", h.SetDestination)
+		internal.GET("/runners/:runnerId/queue", h.overload.Middleware(), h.GetRunnerQueue)
 	}
 }
 
 // RegisterWSRoute registers the WebSocket route on the engine.
 func (h *TrackingHandler) RegisterWSRoute(r *gin.Engine, jwtManager *auth.JWTManager) {
 	r.GET("/ws/tracking/:bookingId", h.HandleWebSocket)
+	r.GET("/ws/tracking", h.HandleMultiplexWebSocket)
+	r.GET("/ws/runner", h.HandleRunnerWebSocket)
 }
 
-// GetTracking returns the tracking data for a booking.
+// GetTracking returns the tracking data for a booking. Any of from, to, cursor or limit
+// returns a page of the waypoints instead of all of them.
 func (h *TrackingHandler) GetTracking(c *gin.Context) {
 	bookingIDStr := c.Param("bookingId")
 	bookingID, err := uuid.Parse(bookingIDStr)
 	if err != nil {
-		response.BadRequest(c, "invalid booking ID format")
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
 		return
 	}
 
-	tracking, err := h.service.GetTracking(c.Request.Context(), bookingID)
+	var tracking *application.TrackingDTO
+	if req := waypointPageRequest(c); req.IsZero() {
+		tracking, err = h.service.GetTracking(c.Request.Context(), bookingID)
+	} else {
+		tracking, err = h.service.GetTrackingPage(c.Request.Context(), bookingID, req)
+	}
 	if err != nil {
-		response.Error(c, err)
+		apperror.Respond(c, err)
 		return
 	}
 
 	response.Success(c, tracking)
 }
 
-// GetRouteGeoJSON returns the route as GeoJSON for a booking's trip.
+// ListWaypoints handles GET /api/v1/tracking/:bookingId/waypoints?from=&to=&cursor=&limit=.
+func (h *TrackingHandler) ListWaypoints(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	page, err := h.service.ListWaypoints(c.Request.Context(), bookingID, waypointPageRequest(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, page)
+}
+
+// waypointPageRequest reads the waypoint paging parameters of a request.
+func waypointPageRequest(c *gin.Context) application.WaypointPageRequest {
+	return application.WaypointPageRequest{
+		From:   c.Query("from"),
+		To:     c.Query("to"),
+		Cursor: c.Query("cursor"),
+		Limit:  c.Query("limit"),
+	}
+}
+
+// GetRouteGeoJSON returns the route of a booking's trip as GeoJSON, GPX or KML.
 func (h *TrackingHandler) GetRouteGeoJSON(c *gin.Context) {
 	bookingIDStr := c.Param("bookingId")
 	bookingID, err := uuid.Parse(bookingIDStr)
 	if err != nil {
-		response.BadRequest(c, "invalid booking ID format")
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	opts, err := parseRouteOptions(c)
+	if err != nil {
+		apperror.Respond(c, err)
 		return
 	}
 
-	geoJSON, err := h.service.GetRouteGeoJSON(c.Request.Context(), bookingID)
+	doc, err := h.service.ExportRoute(c.Request.Context(), bookingID, opts)
 	if err != nil {
-		response.Error(c, err)
+		apperror.Respond(c, err)
 		return
 	}
 
-	c.Data(http.StatusOK, "application/geo+json", []byte(geoJSON))
+	writeRoute(c, bookingID, doc)
 }
 
-// HandleWebSocket upgrades the connection to WebSocket and subscribes to tracking updates.
-func (h *TrackingHandler) HandleWebSocket(c *gin.Context) {
-	// Validate JWT from query parameter.
-	token := c.Query("token")
-	if token == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "token query parameter is required"})
+// ExportWaypointsCSV handles GET /api/v1/tracking/:bookingId/waypoints/export, streaming
+// the trip's raw waypoints as CSV with chunked transfer encoding.
+func (h *TrackingHandler) ExportWaypointsCSV(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
 		return
 	}
 
-	_, err := h.jwtManager.ValidateAccessToken(token)
+	export, err := h.service.ExportWaypointsCSV(c.Request.Context(), bookingID)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="waypoints-%s.csv"`, bookingID))
+	c.Status(http.StatusOK)
+	if err := export.Write(c.Request.Context(), c.Writer, c.Writer.Flush); err != nil {
+		// The status and earlier rows are already sent, so the error can only be logged.
+		h.logger.Warn("waypoint export failed", zap.String("booking_id", bookingID.String()), zap.Error(err))
+	}
+}
+
+// requireBookingAccess aborts with 403 unless the authenticated user may view the
+// booking in the bookingId path parameter.
+func requireBookingAccess(service *application.TrackingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bookingID, err := uuid.Parse(c.Param("bookingId"))
+		if err != nil {
+			apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+			return
+		}
+
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
+			return
+		}
+		role, _ := middleware.GetUserRole(c)
+
+		if err := service.AuthorizeBooking(c.Request.Context(), bookingID, userID, role); err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireBookingParticipant aborts with 403 unless the authenticated user is the owner
+// or assigned runner of the booking in the bookingId path parameter. Unlike
+// requireBookingAccess, admins are not let through.
+func requireBookingParticipant(service *application.TrackingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bookingID, err := uuid.Parse(c.Param("bookingId"))
+		if err != nil {
+			apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+			return
+		}
+
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
+			return
+		}
+
+		if err := service.AuthorizeParticipant(c.Request.Context(), bookingID, userID); err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// parseRouteOptions reads the optional tolerance, max_points, from, to, bbox, format and
+// interval route parameters.
+func parseRouteOptions(c *gin.Context) (application.RouteOptions, error) {
+	var opts application.RouteOptions
+	if v := c.Query("tolerance"); v != "" {
+		tolerance, err := strconv.ParseFloat(v, 64)
+		if err != nil || tolerance < 0 || tolerance > 1 {
+			return opts, apperror.New(apperror.CodeInvalidRequest, "tolerance must be a number of degrees between 0 and 1")
+		}
+		opts.Tolerance = tolerance
+	}
+	if v := c.Query("max_points"); v != "" {
+		maxPoints, err := strconv.Atoi(v)
+		if err != nil || maxPoints < 2 {
+			return opts, apperror.New(apperror.CodeInvalidRequest, "max_points must be an integer of at least 2")
+		}
+		opts.MaxPoints = maxPoints
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &opts.From}, {"to", &opts.To}} {
+		if v := c.Query(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return opts, apperror.New(apperror.CodeInvalidRequest, "%s must be an RFC 3339 timestamp", p.name)
+			}
+			*p.dst = t.UTC()
+		}
+	}
+	if v := c.Query("bbox"); v != "" {
+		box, err := parseBBox(v)
+		if err != nil {
+			return opts, err
+		}
+		opts.Bounds = &box
+	}
+	format := c.Query("format")
+	if format == "" {
+		format = negotiateRouteFormat(c.GetHeader("Accept"))
+	}
+	switch format {
+	case "", application.RouteFormatGeoJSON, application.RouteFormatResampled, application.RouteFormatGPX, application.RouteFormatKML:
+		opts.Format = format
+	default:
+		return opts, apperror.New(apperror.CodeInvalidRequest, "format must be %s, %s, %s or %s",
+			application.RouteFormatGeoJSON, application.RouteFormatGPX, application.RouteFormatKML, application.RouteFormatResampled)
+	}
+	if v := c.Query("interval"); v != "" {
+		if opts.Format != application.RouteFormatResampled {
+			return opts, apperror.New(apperror.CodeInvalidRequest, "interval requires format=%s", application.RouteFormatResampled)
+		}
+		interval, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return opts, apperror.New(apperror.CodeInvalidRequest, "interval must be a number of meters")
+		}
+		opts.IntervalMeters = interval
+	}
+	if v := c.Query("matched"); v != "" {
+		matched, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, apperror.New(apperror.CodeInvalidRequest, "matched must be true or false")
+		}
+		opts.Matched = matched
+	}
+	return opts, nil
+}
+
+// routeMediaTypes maps Accept header media types to route formats.
+var routeMediaTypes = map[string]string{
+	"application/gpx+xml":                  application.RouteFormatGPX,
+	"application/vnd.google-earth.kml+xml": application.RouteFormatKML,
+	"application/geo+json":                 application.RouteFormatGeoJSON,
+}
+
+// negotiateRouteFormat returns the route format of the first media type in an Accept
+// header that has one, or "" for the default GeoJSON.
+func negotiateRouteFormat(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if format, ok := routeMediaTypes[strings.ToLower(mediaType)]; ok {
+			return format
+		}
+	}
+	return ""
+}
+
+// writeRoute writes an exported route. GPX and KML routes are sent as downloads.
+func writeRoute(c *gin.Context, bookingID uuid.UUID, doc *application.RouteDocument) {
+	c.Header("Vary", "Accept")
+	if doc.Extension == application.RouteFormatGPX || doc.Extension == application.RouteFormatKML {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="route-%s.%s"`, bookingID, doc.Extension))
+	}
+	c.Data(http.StatusOK, doc.ContentType, doc.Body)
+}
+
+// parseBBox parses a bbox query parameter of the form minLng,minLat,maxLng,maxLat.
+func parseBBox(v string) (trackingDomain.BoundingBox, error) {
+	invalid := apperror.New(apperror.CodeInvalidRequest, "bbox must be minLng,minLat,maxLng,maxLat")
+	parts := strings.Split(v, ",")
+	if len(parts) != 4 {
+		return trackingDomain.BoundingBox{}, invalid
+	}
+	var coords [4]float64
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return trackingDomain.BoundingBox{}, invalid
+		}
+		coords[i] = f
+	}
+	box := trackingDomain.BoundingBox{
+		MinLongitude: coords[0],
+		MinLatitude:  coords[1],
+		MaxLongitude: coords[2],
+		MaxLatitude:  coords[3],
+	}
+	if box.MinLatitude < -90 || box.MaxLatitude > 90 || box.MinLongitude < -180 || box.MaxLongitude > 180 ||
+		box.MinLatitude > box.MaxLatitude || box.MinLongitude > box.MaxLongitude {
+		return trackingDomain.BoundingBox{}, invalid
+	}
+	return box, nil
+}
+
+// GetETA returns the estimated arrival time for a booking's active trip.
+func (h *TrackingHandler) GetETA(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	eta, err := h.service.GetETA(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, eta)
+}
+
+// GetCurrentPosition returns the last known position for a booking.
+func (h *TrackingHandler) GetCurrentPosition(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	position, err := h.service.GetCurrentPosition(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, position)
+}
+
+// RequestLocationPing asks the runner for a fresh location and returns the next fix, or
+// a timeout status with the last known position.
+func (h *TrackingHandler) RequestLocationPing(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "user not authenticated")
+		return
+	}
+
+	result, err := h.service.RequestLocationPing(c.Request.Context(), bookingID, userID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetPositionAt returns a booking's interpolated position at the RFC 3339 time in the at query parameter.
+func (h *TrackingHandler) GetPositionAt(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	at, err := time.Parse(time.RFC3339, c.Query("at"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, "at must be an RFC 3339 timestamp")
+		return
+	}
+
+	position, err := h.service.GetPositionAt(c.Request.Context(), bookingID, at.UTC())
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, position)
+}
+
+// replayWriteWait is the time allowed to write one replay frame. It replaces the
+// server's write timeout, which a replay outlasts.
+const replayWriteWait = 10 * time.Second
+
+// ReplayTrip streams a booking's recorded waypoints as server-sent events at the playback
+// speed given by the speed query parameter.
+func (h *TrackingHandler) ReplayTrip(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	speed := application.DefaultReplaySpeed
+	if raw := c.Query("speed"); raw != "" {
+		speed, err = strconv.ParseFloat(raw, 64)
+		if err != nil || speed < 1 || speed > application.MaxReplaySpeed {
+			apperror.Respond(c, apperror.New(apperror.CodeInvalidRequest, "speed must be a number between 1 and %g", application.MaxReplaySpeed))
+			return
+		}
+	}
+
+	replay, err := h.service.LoadReplay(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	rc := http.NewResponseController(c.Writer)
+	err = replay.Play(c.Request.Context(), speed, func(frame application.ReplayFrame) error {
+		if err := rc.SetWriteDeadline(time.Now().Add(replayWriteWait)); err != nil {
+			return err
+		}
+		c.SSEvent(frame.Event, frame.Data)
+		return rc.Flush()
+	})
+	if err != nil && c.Request.Context().Err() == nil {
+		h.logger.Warn("trip replay aborted", zap.String("booking_id", bookingID.String()), zap.Error(err))
+	}
+}
+
+// GetSegmentStats returns per-leg and per-stop statistics for a booking's trip.
+func (h *TrackingHandler) GetSegmentStats(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	stats, err := h.service.GetSegmentStats(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, stats)
+}
+
+// GetTripStats returns distance, duration, speed, stop and idle metrics for a booking's trip.
+func (h *TrackingHandler) GetTripStats(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	stats, err := h.service.GetTripStats(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, stats)
+}
+
+// IngestWaypoint accepts a location submitted by the booking's assigned runner.
+func (h *TrackingHandler) IngestWaypoint(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	runnerID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
+		return
+	}
+
+	var req application.IngestWaypointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := h.service.IngestWaypoint(c.Request.Context(), bookingID, runnerID, c.ClientIP(), req); err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// IngestLocations accepts one location object or a list of them submitted by the
+// booking's assigned runner and reports which points were stored.
+func (h *TrackingHandler) IngestLocations(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	runnerID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+	var points []application.IngestWaypointRequest
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &points)
+	} else {
+		var point application.IngestWaypointRequest
+		err = json.Unmarshal(body, &point)
+		points = []application.IngestWaypointRequest{point}
+	}
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := h.service.IngestLocations(c.Request.Context(), bookingID, runnerID, c.ClientIP(), points)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// CancelTracking cancels a booking's active trip with a structured reason code.
+func (h *TrackingHandler) CancelTracking(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
+		return
+	}
+	role, _ := middleware.GetUserRole(c)
+
+	var req application.CancelTrackingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	tracking, err := h.service.CancelTracking(c.Request.Context(), bookingID, userID, role, req)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, tracking)
+}
+
+// SetTrackingPaused pauses or resumes a booking's trip for its runner.
+func (h *TrackingHandler) SetTrackingPaused(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	runnerID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
+		return
+	}
+
+	var req application.PauseTrackingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	tracking, err := h.service.SetTrackingPaused(c.Request.Context(), bookingID, runnerID, *req.Paused)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, tracking)
+}
+
+// SetDestination records the drop-off location for a booking's active trip.
+func (h *TrackingHandler) SetDestination(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	var req application.SetDestinationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if err := h.service.SetDestination(c.Request.Context(), bookingID, req); err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"booking_id": bookingID})
+}
+
+// GetRunnerQueue returns a runner's active trips in arrival order with ETAs.
+func (h *TrackingHandler) GetRunnerQueue(c *gin.Context) {
+	runnerID, err := uuid.Parse(c.Param("runnerId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid runner ID format")
+		return
+	}
+
+	queue, err := h.service.GetRunnerQueue(c.Request.Context(), runnerID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, queue)
+}
+
+// HandleWebSocket upgrades the connection to WebSocket and subscribes to tracking updates.
+func (h *TrackingHandler) HandleWebSocket(c *gin.Context) {
+	// Validate the JWT from the Authorization header, subprotocol or query parameter.
+	claims, ok := authenticateWebSocket(c, h.jwtManager, h.queryTokens)
+	if !ok {
 		return
 	}
 
@@ -117,26 +803,253 @@ func (h *TrackingHandler) HandleWebSocket(c *gin.Context) {
 	bookingIDStr := c.Param("bookingId")
 	bookingID, err := uuid.Parse(bookingIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking ID format"})
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	if err := h.service.AuthorizeBooking(c.Request.Context(), bookingID, claims.UserID, claims.Role); err != nil {
+		apperror.Respond(c, err)
 		return
 	}
 
 	// Upgrade to WebSocket.
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	admission, ok := admitWebSocket(c, h.hub, bookingID, claims.UserID)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
+		admission.Release()
 		h.logger.Error("failed to upgrade to websocket", zap.Error(err))
 		return
 	}
 
-	client := &ws.Client{
-		Conn:      conn,
-		BookingID: bookingID,
-		Send:      make(chan []byte, 256),
+	// Tokens refreshed over the socket must belong to the user who opened it.
+	validate := func(token string) (time.Time, error) {
+		refreshed, err := h.jwtManager.ValidateAccessToken(token)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if refreshed.UserID != claims.UserID {
+			return time.Time{}, errTokenUserMismatch
+		}
+		return tokenExpiry(refreshed), nil
 	}
 
+	client := ws.NewClient(conn, bookingID, tokenExpiry(claims), validate)
+	client.Hold(admission)
+
+	client.SnapshotHistory = snapshotHistory(c)
+	resumeFrom(c, client)
+	client.Role = string(claims.Role)
+	client.Scope = wsScope(claims.Role)
+	client.UserID = claims.UserID
 	h.hub.Register(client)
 
 	// Start read and write pumps in separate goroutines.
 	go client.WritePump(h.hub)
 	go client.ReadPump(h.hub)
 }
+
+// runnerLocationsFrame is a batch of locations streamed by a runner. Ref is echoed in
+// the reply so the app can match acknowledgements to the points it sent.
+type runnerLocationsFrame struct {
+	Type      string                              `json:"type"`
+	Ref       string                              `json:"ref"`
+	BookingID uuid.UUID                           `json:"booking_id"`
+	Points    []application.IngestWaypointRequest `json:"points"`
+}
+
+// runnerReply is the server's reply to a runner frame.
+type runnerReply struct {
+	Type string      `json:"type"`
+	Ref  string      `json:"ref,omitempty"`
+	Data interface{} `json:"data"`
+}
+
+// maxWSSubscriptions caps the bookings one multiplexed connection may follow at once.
+const maxWSSubscriptions = 200
+
+// HandleMultiplexWebSocket upgrades a connection that follows any number of bookings,
+// such as a dispatch dashboard's, subscribing and unsubscribing with control frames.
+// Every subscription is authorized like a single-booking connection.
+func (h *TrackingHandler) HandleMultiplexWebSocket(c *gin.Context) {
+	claims, ok := authenticateWebSocket(c, h.jwtManager, h.queryTokens)
+	if !ok {
+		return
+	}
+
+	admission, ok := admitWebSocket(c, h.hub, uuid.Nil, claims.UserID)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		admission.Release()
+		h.logger.Error("failed to upgrade to websocket", zap.Error(err))
+		return
+	}
+
+	validate := func(token string) (time.Time, error) {
+		refreshed, err := h.jwtManager.ValidateAccessToken(token)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if refreshed.UserID != claims.UserID {
+			return time.Time{}, errTokenUserMismatch
+		}
+		return tokenExpiry(refreshed), nil
+	}
+
+	// The connection itself is not in a room; each subscription joins one for it.
+	client := ws.NewClient(conn, uuid.Nil, tokenExpiry(claims), validate)
+	client.Hold(admission)
+	client.Role = string(claims.Role)
+	client.Scope = wsScope(claims.Role)
+	client.UserID = claims.UserID
+	authorize := func(ctx context.Context, bookingID uuid.UUID) error {
+		if err := h.service.AuthorizeBooking(ctx, bookingID, claims.UserID, claims.Role); err != nil {
+			return errors.New(apperror.From(err).Detail)
+		}
+		return nil
+	}
+	ws.NewMultiplexer(h.hub, client, authorize, maxWSSubscriptions, h.logger)
+
+	go client.WritePump(h.hub)
+	go client.ReadPump(h.hub)
+}
+
+// runnerReplyError describes why a runner frame was refused as a whole.
+type runnerReplyError struct {
+	Code   apperror.Code `json:"code"`
+	Detail string        `json:"detail"`
+	// RetryAfterSeconds is set when the frame was refused by a rate limit.
+	RetryAfterSeconds float64 `json:"retry_after_seconds,omitempty"`
+}
+
+// runnerFrameTimeout bounds the processing of one runner frame.
+const runnerFrameTimeout = 10 * time.Second
+
+// HandleRunnerWebSocket upgrades a runner's connection for streaming locations upstream.
+// Each locations frame is checked and stored like a REST batch, fanned out to its
+// booking room by the usual pipeline, and answered with locations_ack or
+// locations_error.
+func (h *TrackingHandler) HandleRunnerWebSocket(c *gin.Context) {
+	claims, ok := authenticateWebSocket(c, h.jwtManager, h.queryTokens)
+	if !ok {
+		return
+	}
+	if claims.Role != auth.RoleRunner {
+		apperror.Abort(c, apperror.CodeForbidden, "only runners can stream locations")
+		return
+	}
+
+	admission, ok := admitWebSocket(c, h.hub, uuid.Nil, claims.UserID)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		admission.Release()
+		h.logger.Error("failed to upgrade to websocket", zap.Error(err))
+		return
+	}
+
+	validate := func(token string) (time.Time, error) {
+		refreshed, err := h.jwtManager.ValidateAccessToken(token)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if refreshed.UserID != claims.UserID {
+			return time.Time{}, errTokenUserMismatch
+		}
+		return tokenExpiry(refreshed), nil
+	}
+
+	// The client is not joined to a room; it only sends locations and receives replies.
+	client := ws.NewClient(conn, uuid.Nil, tokenExpiry(claims), validate)
+	client.Hold(admission)
+	client.Role = string(claims.Role)
+	sourceIP := c.ClientIP()
+	client.OnMessage = func(message []byte) {
+		reply := h.handleRunnerFrame(claims.UserID, sourceIP, message)
+		if !client.Reply(reply) {
+			h.logger.Warn("runner websocket reply dropped",
+				zap.String("runner_id", claims.UserID.String()),
+				zap.String("ref", reply.Ref),
+			)
+		}
+	}
+
+	go client.WritePump(h.hub)
+	go client.ReadPump(h.hub)
+}
+
+// handleRunnerFrame stores the locations of one runner frame and builds its reply.
+func (h *TrackingHandler) handleRunnerFrame(runnerID uuid.UUID, sourceIP string, message []byte) runnerReply {
+	var frame runnerLocationsFrame
+	if err := json.Unmarshal(message, &frame); err != nil {
+		return runnerReply{Type: "locations_error", Data: runnerReplyError{Code: apperror.CodeInvalidRequest, Detail: err.Error()}}
+	}
+	if frame.Type != "locations" {
+		return runnerReply{Type: "locations_error", Ref: frame.Ref, Data: runnerReplyError{Code: apperror.CodeInvalidRequest, Detail: fmt.Sprintf("unknown frame type %q", frame.Type)}}
+	}
+
+	// The upgrade request's context ends when the handler returns, so each frame gets its own.
+	ctx, cancel := context.WithTimeout(context.Background(), runnerFrameTimeout)
+	defer cancel()
+
+	if err := h.limiter.Allow(ctx, ratelimit.ScopeLocations, runnerID); err != nil {
+		appErr := apperror.From(err)
+		return runnerReply{Type: "locations_error", Ref: frame.Ref, Data: runnerReplyError{Code: appErr.Code, Detail: appErr.Detail, RetryAfterSeconds: appErr.RetryAfter.Seconds()}}
+	}
+
+	result, err := h.service.IngestStreamedLocations(ctx, frame.BookingID, runnerID, sourceIP, frame.Points)
+	if err != nil {
+		appErr := apperror.From(err)
+		if appErr.Code == apperror.CodeInternal {
+			h.logger.Error("failed to ingest streamed locations",
+				zap.String("runner_id", runnerID.String()),
+				zap.String("booking_id", frame.BookingID.String()),
+				zap.Error(err),
+			)
+		}
+		return runnerReply{Type: "locations_error", Ref: frame.Ref, Data: runnerReplyError{Code: appErr.Code, Detail: appErr.Detail}}
+	}
+	return runnerReply{Type: "locations_ack", Ref: frame.Ref, Data: result}
+}
+
+// snapshotHistory reads the number of recent waypoints a WebSocket client wants in its
+// join snapshot from the history query parameter.
+func snapshotHistory(c *gin.Context) int {
+	n, err := strconv.Atoi(c.Query("history"))
+	if err != nil || n < 0 {
+		return 0
+	}
+	if n > ws.MaxSnapshotHistory {
+		return ws.MaxSnapshotHistory
+	}
+	return n
+}
+
+// resumeFrom sets the cursor a reconnecting WebSocket client passed in the since_seq and
+// epoch query parameters, so it is sent the frames it missed instead of a snapshot.
+func resumeFrom(c *gin.Context, client *ws.Client) {
+	seq, err := strconv.ParseUint(c.Query("since_seq"), 10, 64)
+	if err != nil || c.Query("epoch") == "" {
+		return
+	}
+	client.ResumeSeq = seq
+	client.ResumeEpoch = c.Query("epoch")
+}
+
+// tokenExpiry returns an access token's expiry, or the zero time if it has none.
+func tokenExpiry(claims *auth.Claims) time.Time {
+	if claims.ExpiresAt == nil {
+		return time.Time{}
+	}
+	return claims.ExpiresAt.Time
+}
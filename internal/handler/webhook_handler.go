@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+)
+
+// WebhookHandler manages partners' tracking webhooks.
+type WebhookHandler struct {
+	service *application.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler.
+func NewWebhookHandler(service *application.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// RegisterRoutes registers the webhook routes on the given router group.
+func (h *WebhookHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	webhooks := r.Group("/webhooks")
+	webhooks.Use(middleware.AuthMiddleware(jwtManager))
+	{
+		webhooks.POST("", h.Register)
+		webhooks.GET("", h.List)
+		webhooks.DELETE("/:webhookId", h.Delete)
+		webhooks.GET("/:webhookId/deliveries", h.ListDeliveries)
+	}
+}
+
+// Register handles POST /api/v1/webhooks. The response carries the signing secret,
+// which is not returned again.
+func (h *WebhookHandler) Register(c *gin.Context) {
+	userID, role, ok := webhookCaller(c)
+	if !ok {
+		return
+	}
+
+	var req application.RegisterWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := h.service.Register(c.Request.Context(), userID, role, req)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Created(c, result)
+}
+
+// List handles GET /api/v1/webhooks, listing the caller's webhooks. Admins may list
+// another account's with ?account_id=.
+func (h *WebhookHandler) List(c *gin.Context) {
+	userID, role, ok := webhookCaller(c)
+	if !ok {
+		return
+	}
+
+	accountID := userID
+	if raw := c.Query("account_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			apperror.Abort(c, apperror.CodeInvalidID, "invalid account ID format")
+			return
+		}
+		if id != userID && role != auth.RoleAdmin {
+			apperror.Abort(c, apperror.CodeForbidden, "only admins may list another account's webhooks")
+			return
+		}
+		accountID = id
+	}
+
+	result, err := h.service.List(c.Request.Context(), accountID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Delete handles DELETE /api/v1/webhooks/:webhookId.
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param("webhookId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid webhook ID format")
+		return
+	}
+	userID, role, ok := webhookCaller(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), webhookID, userID, role); err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"id": webhookID})
+}
+
+// ListDeliveries handles GET /api/v1/webhooks/:webhookId/deliveries.
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param("webhookId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid webhook ID format")
+		return
+	}
+	userID, role, ok := webhookCaller(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ListDeliveries(c.Request.Context(), webhookID, userID, role)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// webhookCaller returns the authenticated caller, aborting the request if there is none.
+func webhookCaller(c *gin.Context) (uuid.UUID, auth.UserRole, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
+		return uuid.Nil, "", false
+	}
+	role, ok := middleware.GetUserRole(c)
+	if !ok {
+		apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
+		return uuid.Nil, "", false
+	}
+	return userID, role, true
+}
@@ -0,0 +1,456 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/auth"
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/lib-common/response"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/origin"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/widget"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+const (
+	// widgetBookingIDKey is the gin context key holding the booking a widget token is scoped to.
+	widgetBookingIDKey = "widget_booking_id"
+
+	// widgetExpiresAtKey is the gin context key holding the widget token's expiry.
+	widgetExpiresAtKey = "widget_expires_at"
+
+	// widgetClaimsKey is the gin context key holding the widget token's claims.
+	widgetClaimsKey = "widget_claims"
+
+	// embedRoutePoints caps the route points drawn by an embed page.
+	embedRoutePoints = 500
+)
+
+// Scopes requested when minting a widget token.
+const (
+	widgetScopeRoute    = "route"
+	widgetScopePosition = "position"
+)
+
+// CreateWidgetTokenRequest optionally narrows a widget token. Scope is "route" (the
+// default), for the full route, or "position", for the runner's current position only.
+// Origin is the https origin of the partner site allowed to frame the token's embed.
+type CreateWidgetTokenRequest struct {
+	Scope  string `json:"scope"`
+	Origin string `json:"origin"`
+}
+
+// WidgetTokenDTO is the API response for a newly minted widget token.
+type WidgetTokenDTO struct {
+	Token     string    `json:"token"`
+	BookingID uuid.UUID `json:"booking_id"`
+	Scope     string    `json:"scope"`
+	Origin    string    `json:"origin,omitempty"`
+	EmbedPath string    `json:"embed_path"`
+	ExpiresIn int64     `json:"expires_in_seconds"`
+}
+
+// EmbedDTO is the JSON form of an embed page. Route is a GeoJSON LineString, only
+// included for tokens granting the route. StreamURL is the widget WebSocket to follow
+// the runner live.
+type EmbedDTO struct {
+	BookingID uuid.UUID                       `json:"booking_id"`
+	Scope     string                          `json:"scope"`
+	Position  *application.CurrentPositionDTO `json:"position,omitempty"`
+	Route     json.RawMessage                 `json:"route,omitempty"`
+	StreamURL string                          `json:"stream_url"`
+	ExpiresAt time.Time                       `json:"expires_at"`
+}
+
+// WidgetHandler serves read-only tracking for embedded widgets authenticated by scoped tokens.
+type WidgetHandler struct {
+	service  *application.TrackingService
+	hub      *ws.Hub
+	signer   *widget.Signer
+	origins  *origin.Policy
+	upgrader *websocket.Upgrader
+	logger   *zap.Logger
+}
+
+// NewWidgetHandler creates a new WidgetHandler.
+func NewWidgetHandler(
+	service *application.TrackingService,
+	hub *ws.Hub,
+	signer *widget.Signer,
+	origins *origin.Policy,
+	logger *zap.Logger,
+) *WidgetHandler {
+	return &WidgetHandler{
+		service:  service,
+		hub:      hub,
+		signer:   signer,
+		origins:  origins,
+		upgrader: newUpgrader(origins),
+		logger:   logger,
+	}
+}
+
+// RegisterRoutes registers the token minting route and the widget-scoped read routes.
+func (h *WidgetHandler) RegisterRoutes(r *gin.RouterGroup, jwtManager *auth.JWTManager) {
+	tracking := r.Group("/tracking")
+	tracking.POST("/:bookingId/widget-token", middleware.AuthMiddleware(jwtManager), requireBookingAccess(h.service), h.CreateWidgetToken)
+
+	widgetGroup := r.Group("/widget")
+	widgetGroup.Use(h.origins.Middleware(), WidgetAuthMiddleware(h.signer))
+	{
+		widgetGroup.GET("/tracking", requireFullRoute(), h.GetTracking)
+		widgetGroup.GET("/tracking/route", requireFullRoute(), h.GetRouteGeoJSON)
+		widgetGroup.GET("/tracking/position", h.GetPosition)
+	}
+}
+
+// RegisterEmbedRoute registers the embeddable tracking page on the engine.
+func (h *WidgetHandler) RegisterEmbedRoute(r *gin.Engine) {
+	r.GET("/embed/tracking/:token", h.origins.Middleware(), h.Embed)
+}
+
+// RegisterWSRoute registers the widget WebSocket route on the engine.
+func (h *WidgetHandler) RegisterWSRoute(r *gin.Engine) {
+	r.GET("/ws/widget/tracking", WidgetAuthMiddleware(h.signer), h.HandleWebSocket)
+}
+
+// WidgetAuthMiddleware validates a widget token from the X-Widget-Token header or the
+// token query parameter and stores the scoped booking ID in the context.
+func WidgetAuthMiddleware(signer *widget.Signer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("X-Widget-Token")
+		if token == "" {
+			token = c.Query("token")
+		}
+		if token == "" {
+			apperror.Abort(c, apperror.CodeUnauthorized, "widget token is required")
+			return
+		}
+
+		claims, err := signer.Validate(token)
+		if err != nil {
+			if errors.Is(err, widget.ErrExpiredToken) {
+				apperror.Abort(c, apperror.CodeTokenExpired, "widget token has expired")
+				return
+			}
+			apperror.Abort(c, apperror.CodeUnauthorized, "invalid widget token")
+			return
+		}
+
+		c.Set(widgetBookingIDKey, claims.BookingID)
+		c.Set(widgetExpiresAtKey, time.Unix(claims.ExpiresAt, 0).UTC())
+		c.Set(widgetClaimsKey, claims)
+		c.Next()
+	}
+}
+
+// requireFullRoute rejects widget tokens limited to the runner's position.
+func requireFullRoute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.MustGet(widgetClaimsKey).(*widget.Claims).FullRoute() {
+			apperror.Abort(c, apperror.CodeForbidden, "widget token only grants the current position")
+			return
+		}
+		c.Next()
+	}
+}
+
+// CreateWidgetToken handles POST /api/v1/tracking/:bookingId/widget-token.
+func (h *WidgetHandler) CreateWidgetToken(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("bookingId"))
+	if err != nil {
+		apperror.Abort(c, apperror.CodeInvalidID, "invalid booking ID format")
+		return
+	}
+
+	var req CreateWidgetTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apperror.Abort(c, apperror.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	var scope string
+	switch req.Scope {
+	case "", widgetScopeRoute:
+		req.Scope, scope = widgetScopeRoute, widget.ScopeTrackingRead
+	case widgetScopePosition:
+		scope = widget.ScopePositionRead
+	default:
+		apperror.Abort(c, apperror.CodeInvalidRequest, "scope must be route or position")
+		return
+	}
+	origin, err := parseEmbedOrigin(req.Origin)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	if origin != "" && !h.origins.Allows(origin) {
+		apperror.Abort(c, apperror.CodeForbidden, "origin is not on the service's allowed origins")
+		return
+	}
+
+	token, _, err := h.signer.Issue(bookingID, scope, origin)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	response.Created(c, WidgetTokenDTO{
+		Token:     token,
+		BookingID: bookingID,
+		Scope:     req.Scope,
+		Origin:    origin,
+		EmbedPath: "/embed/tracking/" + token,
+		ExpiresIn: int64(h.signer.TTL().Seconds()),
+	})
+}
+
+// parseEmbedOrigin validates the origin allowed to frame an embed and returns it as
+// scheme://host[:port]. An empty origin is returned as is.
+func parseEmbedOrigin(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil ||
+		(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return "", apperror.New(apperror.CodeInvalidRequest, "origin must be an https origin such as https://partner.example")
+	}
+	return u.Scheme + "://" + strings.ToLower(u.Host), nil
+}
+
+// GetTracking handles GET /api/v1/widget/tracking.
+func (h *WidgetHandler) GetTracking(c *gin.Context) {
+	bookingID := c.MustGet(widgetBookingIDKey).(uuid.UUID)
+
+	tracking, err := h.service.GetTracking(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	respondViewer(c, tracking)
+}
+
+// GetPosition handles GET /api/v1/widget/tracking/position, available to every widget
+// token.
+func (h *WidgetHandler) GetPosition(c *gin.Context) {
+	bookingID := c.MustGet(widgetBookingIDKey).(uuid.UUID)
+
+	position, err := h.service.GetCurrentPosition(c.Request.Context(), bookingID)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	respondViewer(c, position)
+}
+
+// viewerPayload renders a response as public viewers are sent it on the socket, without
+// the fields hidden from ws.ScopeViewer.
+func viewerPayload(v interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	redacted, ok := ws.Redact(ws.ScopeViewer, data)
+	if !ok {
+		return nil, errors.New("failed to render viewer payload")
+	}
+	return json.RawMessage(redacted), nil
+}
+
+// respondViewer responds with a payload rendered for public viewers.
+func respondViewer(c *gin.Context, v interface{}) {
+	payload, err := viewerPayload(v)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	response.Success(c, payload)
+}
+
+// GetRouteGeoJSON handles GET /api/v1/widget/tracking/route.
+func (h *WidgetHandler) GetRouteGeoJSON(c *gin.Context) {
+	bookingID := c.MustGet(widgetBookingIDKey).(uuid.UUID)
+
+	opts, err := parseRouteOptions(c)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	doc, err := h.service.ExportRoute(c.Request.Context(), bookingID, opts)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	writeRoute(c, bookingID, doc)
+}
+
+// HandleWebSocket handles WS /ws/widget/tracking, subscribing to the token's booking room.
+func (h *WidgetHandler) HandleWebSocket(c *gin.Context) {
+	bookingID := c.MustGet(widgetBookingIDKey).(uuid.UUID)
+
+	admission, ok := admitWebSocket(c, h.hub, bookingID, uuid.Nil)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		admission.Release()
+		h.logger.Error("failed to upgrade widget websocket", zap.Error(err))
+		return
+	}
+
+	// Widget tokens can be refreshed over the socket, but only with a token for the same booking.
+	validate := func(token string) (time.Time, error) {
+		claims, err := h.signer.Validate(token)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if claims.BookingID != bookingID {
+			return time.Time{}, widget.ErrInvalidToken
+		}
+		return time.Unix(claims.ExpiresAt, 0).UTC(), nil
+	}
+
+	client := ws.NewClient(conn, bookingID, c.MustGet(widgetExpiresAtKey).(time.Time), validate)
+	client.Hold(admission)
+
+	// Position-only tokens are not replayed past locations, which would draw the route.
+	if c.MustGet(widgetClaimsKey).(*widget.Claims).FullRoute() {
+		client.SnapshotHistory = snapshotHistory(c)
+		resumeFrom(c, client)
+	}
+	h.hub.Register(client)
+
+	go client.WritePump(h.hub)
+	go client.ReadPump(h.hub)
+}
+
+// Embed handles GET /embed/tracking/:token, a page partners can frame to show a booking's
+// live position, and its route if the token grants it. The page loads nothing from
+// elsewhere and runs only its own nonce-tagged script, under a Content-Security-Policy
+// that lets only the token's origin frame it. With ?format=json or an Accept header
+// preferring JSON, the page's data is returned instead.
+func (h *WidgetHandler) Embed(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	token := c.Param("token")
+	claims, err := h.signer.Validate(token)
+	if err != nil {
+		c.Header("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+		if errors.Is(err, widget.ErrExpiredToken) {
+			apperror.Abort(c, apperror.CodeTokenExpired, "widget token has expired")
+			return
+		}
+		apperror.Abort(c, apperror.CodeUnauthorized, "invalid widget token")
+		return
+	}
+
+	ctx := c.Request.Context()
+	scope := widgetScopePosition
+	if claims.FullRoute() {
+		scope = widgetScopeRoute
+	}
+	dto := EmbedDTO{
+		BookingID: claims.BookingID,
+		Scope:     scope,
+		StreamURL: embedStreamURL(c, token),
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0).UTC(),
+	}
+	position, err := h.service.GetCurrentPosition(ctx, claims.BookingID)
+	switch {
+	case err == nil:
+		dto.Position = position
+	case apperror.From(err).Code != apperror.CodePositionUnknown:
+		apperror.Respond(c, err)
+		return
+	}
+	if claims.FullRoute() {
+		route, err := h.service.GetRouteGeoJSON(ctx, claims.BookingID, application.RouteOptions{MaxPoints: embedRoutePoints})
+		if err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+		dto.Route = json.RawMessage(route)
+	}
+
+	payload, err := viewerPayload(dto)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	c.Header("Vary", "Accept")
+	if c.Query("format") == "json" || prefersJSON(c.GetHeader("Accept")) {
+		c.Header("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+		response.Success(c, payload)
+		return
+	}
+
+	nonce, err := widget.NewNonce()
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	frameAncestors := h.origins.FrameAncestors()
+	if claims.Origin != "" {
+		frameAncestors = claims.Origin
+	}
+	c.Header("Content-Security-Policy", fmt.Sprintf(
+		"default-src 'none'; script-src 'nonce-%[1]s'; style-src 'nonce-%[1]s'; connect-src %[2]s; img-src data:; base-uri 'none'; form-action 'none'; frame-ancestors %[3]s",
+		nonce, embedStreamOrigin(c), frameAncestors,
+	))
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := widget.RenderEmbed(c.Writer, widget.EmbedPage{Nonce: nonce, Data: payload}); err != nil {
+		h.logger.Error("failed to render embed page", zap.Error(err))
+	}
+}
+
+// prefersJSON reports whether an Accept header lists application/json before text/html.
+func prefersJSON(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		switch strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0])) {
+		case "application/json":
+			return true
+		case "text/html":
+			return false
+		}
+	}
+	return false
+}
+
+// embedStreamOrigin returns the WebSocket origin of the host serving the request, behind
+// a TLS-terminating proxy too.
+func embedStreamOrigin(c *gin.Context) string {
+	scheme := "ws"
+	if c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https") {
+		scheme = "wss"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// embedStreamURL returns the widget WebSocket URL authenticated by token.
+func embedStreamURL(c *gin.Context, token string) string {
+	return embedStreamOrigin(c) + "/ws/widget/tracking?token=" + url.QueryEscape(token)
+}
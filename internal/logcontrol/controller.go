@@ -0,0 +1,255 @@
+// Package logcontrol changes the service's log level at runtime and turns on debug
+// logging for individual bookings or runners, so a single problematic trip can be traced
+// in production without enabling debug logs for all traffic.
+package logcontrol
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultTraceDuration is how long a trace stays enabled when no duration is given.
+	DefaultTraceDuration = 15 * time.Minute
+
+	// MaxTraceDuration caps how long a trace can stay enabled.
+	MaxTraceDuration = 2 * time.Hour
+)
+
+// Trace subjects, matched against the booking_id and runner_id log fields.
+const (
+	SubjectBooking = "booking"
+	SubjectRunner  = "runner"
+)
+
+// subjectFields maps a trace subject to the log field that identifies it.
+var subjectFields = map[string]string{
+	SubjectBooking: "booking_id",
+	SubjectRunner:  "runner_id",
+}
+
+// Trace describes debug logging enabled for one booking or runner.
+type Trace struct {
+	Subject   string    `json:"subject"`
+	ID        string    `json:"id"`
+	Every     int       `json:"every"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// trace is a Trace with its sampling counter.
+type trace struct {
+	Trace
+	seen atomic.Uint64
+}
+
+// sample reports whether the next matching entry should be written.
+func (t *trace) sample() bool {
+	return (t.seen.Add(1)-1)%uint64(t.Every) == 0
+}
+
+// Controller holds the runtime log level and the active traces.
+type Controller struct {
+	level zap.AtomicLevel
+
+	mu     sync.RWMutex
+	traces map[string]*trace // field value -> trace
+	active atomic.Int32
+}
+
+// NewController creates a Controller starting at the given level.
+func NewController(level zapcore.Level) *Controller {
+	return &Controller{
+		level:  zap.NewAtomicLevelAt(level),
+		traces: make(map[string]*trace),
+	}
+}
+
+// Wrap returns a logger whose output is gated by the controller's level and traces.
+func (c *Controller) Wrap(logger *zap.Logger) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(inner zapcore.Core) zapcore.Core {
+		return &core{Core: inner, ctl: c}
+	}))
+}
+
+// Level returns the current log level.
+func (c *Controller) Level() zapcore.Level {
+	return c.level.Level()
+}
+
+// SetLevel changes the log level.
+func (c *Controller) SetLevel(level zapcore.Level) {
+	c.level.SetLevel(level)
+}
+
+// StartTrace enables debug logging for entries carrying the subject's ID, writing one in
+// every `every` matching entries until the duration elapses. Starting a trace for an ID
+// that is already traced replaces it.
+func (c *Controller) StartTrace(subject, id string, every int, d time.Duration) (Trace, error) {
+	if _, ok := subjectFields[subject]; !ok {
+		return Trace{}, fmt.Errorf("unknown trace subject %q", subject)
+	}
+	if id == "" {
+		return Trace{}, fmt.Errorf("trace id is required")
+	}
+	if every < 1 {
+		every = 1
+	}
+	if d <= 0 {
+		d = DefaultTraceDuration
+	}
+	if d > MaxTraceDuration {
+		d = MaxTraceDuration
+	}
+
+	t := &trace{Trace: Trace{
+		Subject:   subject,
+		ID:        id,
+		Every:     every,
+		ExpiresAt: time.Now().UTC().Add(d),
+	}}
+
+	c.mu.Lock()
+	c.traces[id] = t
+	c.active.Store(int32(len(c.traces)))
+	c.mu.Unlock()
+	return t.Trace, nil
+}
+
+// StopTrace disables the trace for an ID. It reports whether a trace existed.
+func (c *Controller) StopTrace(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.traces[id]
+	delete(c.traces, id)
+	c.active.Store(int32(len(c.traces)))
+	return ok
+}
+
+// Traces returns the unexpired traces ordered by expiry.
+func (c *Controller) Traces() []Trace {
+	c.prune()
+
+	c.mu.RLock()
+	result := make([]Trace, 0, len(c.traces))
+	for _, t := range c.traces {
+		result = append(result, t.Trace)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ExpiresAt.Before(result[j].ExpiresAt) })
+	return result
+}
+
+// prune drops expired traces.
+func (c *Controller) prune() {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, t := range c.traces {
+		if now.After(t.ExpiresAt) {
+			delete(c.traces, id)
+		}
+	}
+	c.active.Store(int32(len(c.traces)))
+}
+
+// tracing reports whether any trace is active.
+func (c *Controller) tracing() bool {
+	return c.active.Load() > 0
+}
+
+// match returns the unexpired trace whose ID appears in the subject fields, if any.
+func (c *Controller) match(fields []zapcore.Field) *trace {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, f := range fields {
+		value, ok := fieldString(f)
+		if !ok {
+			continue
+		}
+		t, ok := c.traces[value]
+		if !ok || subjectFields[t.Subject] != f.Key {
+			continue
+		}
+		if time.Now().After(t.ExpiresAt) {
+			return nil
+		}
+		return t
+	}
+	return nil
+}
+
+// live reports whether t is still registered and unexpired.
+func (c *Controller) live(t *trace) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.traces[t.ID] == t && !time.Now().After(t.ExpiresAt)
+}
+
+// fieldString returns the value of a string-like field.
+func fieldString(f zapcore.Field) (string, bool) {
+	switch f.Type {
+	case zapcore.StringType:
+		return f.String, true
+	case zapcore.StringerType:
+		if s, ok := f.Interface.(fmt.Stringer); ok {
+			return s.String(), true
+		}
+	}
+	return "", false
+}
+
+// core gates entries below the controller's level, letting them through only when they
+// belong to a traced booking or runner.
+type core struct {
+	zapcore.Core
+	ctl *Controller
+
+	// traced is the trace matched by fields added with With, if any.
+	traced *trace
+}
+
+func (c *core) Enabled(level zapcore.Level) bool {
+	return c.ctl.level.Enabled(level) || c.ctl.tracing()
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	traced := c.traced
+	if traced == nil && c.ctl.tracing() {
+		traced = c.ctl.match(fields)
+	}
+	return &core{Core: c.Core.With(fields), ctl: c.ctl, traced: traced}
+}
+
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.ctl.level.Enabled(ent.Level) {
+		if c.Core.Enabled(ent.Level) {
+			return c.Core.Check(ent, ce)
+		}
+		return ce.AddCore(ent, c)
+	}
+	if c.ctl.tracing() {
+		// Whether the entry is traced depends on its fields, which are only known in Write.
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.ctl.level.Enabled(ent.Level) {
+		t := c.traced
+		if t == nil || !c.ctl.live(t) {
+			t = c.ctl.match(fields)
+		}
+		if t == nil || !t.sample() {
+			return nil
+		}
+	}
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,180 @@
+// Package objectstore presigns requests to S3-compatible object storage (AWS S3, MinIO)
+// with Signature Version 4, so clients can upload and download chat attachments
+// directly without the service proxying the bytes.
+package objectstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	amzDateFormat    = "20060102T150405Z"
+
+	// maxExpiry is the longest validity SigV4 allows for a presigned URL.
+	maxExpiry = 7 * 24 * time.Hour
+)
+
+// Config locates a bucket and the credentials to sign requests to it.
+type Config struct {
+	// Endpoint is the base URL of the storage, e.g. https://s3.ap-southeast-3.amazonaws.com
+	// or http://minio:9000. It must be reachable by the clients using the URLs.
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle addresses the bucket as a path (endpoint/bucket/key), as MinIO expects,
+	// instead of as a subdomain (bucket.endpoint/key).
+	PathStyle bool
+}
+
+// Presigner creates presigned object URLs for one bucket.
+type Presigner struct {
+	config Config
+	scheme string
+	host   string
+	now    func() time.Time
+}
+
+// NewPresigner validates cfg and creates a Presigner.
+func NewPresigner(cfg Config) (*Presigner, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("object storage endpoint must be an http(s) URL, got %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("object storage bucket and credentials are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	host := endpoint.Host
+	if !cfg.PathStyle {
+		host = cfg.Bucket + "." + host
+	}
+	return &Presigner{config: cfg, scheme: endpoint.Scheme, host: host, now: time.Now}, nil
+}
+
+// PresignPut returns a URL that uploads an object at key with an HTTP PUT until
+// expiry. The upload must send contentType as its Content-Type header.
+func (p *Presigner) PresignPut(key, contentType string, expiry time.Duration) string {
+	return p.presign("PUT", key, map[string]string{"content-type": contentType}, expiry)
+}
+
+// PresignGet returns a URL that downloads the object at key until expiry.
+func (p *Presigner) PresignGet(key string, expiry time.Duration) string {
+	return p.presign("GET", key, nil, expiry)
+}
+
+// presign signs a request for key with the given headers besides host, which is always
+// signed, and returns its URL with the signature in the query.
+func (p *Presigner) presign(method, key string, headers map[string]string, expiry time.Duration) string {
+	if expiry > maxExpiry {
+		expiry = maxExpiry
+	}
+	now := p.now().UTC()
+	amzDate := now.Format(amzDateFormat)
+	scope := strings.Join([]string{now.Format("20060102"), p.config.Region, "s3", "aws4_request"}, "/")
+
+	path := "/" + uriEncode(key, false)
+	if p.config.PathStyle {
+		path = "/" + uriEncode(p.config.Bucket, true) + path
+	}
+
+	signed := map[string]string{"host": p.host}
+	for name, value := range headers {
+		signed[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     signingAlgorithm,
+		"X-Amz-Credential":    p.config.AccessKeyID + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       strconv.Itoa(int(expiry.Seconds())),
+		"X-Amz-SignedHeaders": signedHeaders,
+	}
+	canonicalQuery := canonicalQueryString(query)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+	stringToSign := strings.Join([]string{signingAlgorithm, amzDate, scope, sha256Hex(canonicalRequest)}, "\n")
+
+	key4 := hmacSHA256([]byte("AWS4"+p.config.SecretAccessKey), now.Format("20060102"))
+	key4 = hmacSHA256(key4, p.config.Region)
+	key4 = hmacSHA256(key4, "s3")
+	key4 = hmacSHA256(key4, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key4, stringToSign))
+
+	return p.scheme + "://" + p.host + path + "?" + canonicalQuery + "&X-Amz-Signature=" + signature
+}
+
+// canonicalQueryString encodes query parameters sorted by name, as SigV4 requires.
+func canonicalQueryString(query map[string]string) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = uriEncode(name, true) + "=" + uriEncode(query[name], true)
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes every byte except the unreserved characters of RFC 3986,
+// and except '/' unless encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
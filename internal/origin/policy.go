@@ -0,0 +1,135 @@
+// Package origin decides which browser origins may open the service's WebSockets, call
+// its public share and widget endpoints and frame its tracking embeds.
+package origin
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+)
+
+// wildcard allows every origin.
+const wildcard = "*"
+
+// pattern is an allowed origin. A host starting with "*." matches any subdomain of the
+// rest, but not the domain itself.
+type pattern struct {
+	scheme string
+	host   string
+	port   string
+}
+
+// Policy is an allow-list of origins. Requests from the service's own host and requests
+// without an Origin header, such as those of the mobile apps, are always allowed.
+type Policy struct {
+	any      bool
+	patterns []pattern
+}
+
+// NewPolicy parses an allow-list. Each entry is "*", an origin such as
+// "https://app.kilat.id" or "http://localhost:3000", or an origin with a wildcard
+// subdomain such as "https://*.kilat.id". An empty list allows only same-host requests.
+func NewPolicy(allowed []string) (*Policy, error) {
+	p := &Policy{}
+	for _, entry := range allowed {
+		if entry == wildcard {
+			p.any = true
+			continue
+		}
+		u, err := url.Parse(entry)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil ||
+			(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+			return nil, fmt.Errorf("invalid allowed origin %q", entry)
+		}
+		host := strings.ToLower(u.Hostname())
+		if strings.Contains(strings.TrimPrefix(host, "*."), "*") || host == "*." {
+			return nil, fmt.Errorf("invalid allowed origin %q: only a leading *. wildcard is supported", entry)
+		}
+		p.patterns = append(p.patterns, pattern{scheme: u.Scheme, host: host, port: u.Port()})
+	}
+	return p, nil
+}
+
+// AllowsAny reports whether every origin is allowed.
+func (p *Policy) AllowsAny() bool { return p.any }
+
+// Allows reports whether an Origin header value is on the allow-list.
+func (p *Policy) Allows(origin string) bool {
+	if p.any {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	scheme, host, port := strings.ToLower(u.Scheme), strings.ToLower(u.Hostname()), u.Port()
+	for _, pt := range p.patterns {
+		if pt.scheme != scheme || pt.port != port {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(pt.host, "*"); ok {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+			continue
+		}
+		if pt.host == host {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRequest reports whether a request may proceed: it has no Origin header, comes
+// from the service's own host or from an allowed origin. It suits
+// websocket.Upgrader.CheckOrigin.
+func (p *Policy) CheckRequest(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || sameHost(origin, r.Host) || p.Allows(origin)
+}
+
+// Middleware rejects requests from origins off the allow-list with 403 forbidden and
+// lets allowed origins read the response.
+func (p *Policy) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !p.CheckRequest(c.Request) {
+			apperror.Abort(c, apperror.CodeForbidden, "origin not allowed")
+			return
+		}
+		if origin := c.GetHeader("Origin"); origin != "" {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Add("Vary", "Origin")
+		}
+		c.Next()
+	}
+}
+
+// FrameAncestors returns the allow-list as a Content-Security-Policy frame-ancestors
+// source list, "'none'" when it is empty.
+func (p *Policy) FrameAncestors() string {
+	if p.any {
+		return wildcard
+	}
+	if len(p.patterns) == 0 {
+		return "'none'"
+	}
+	sources := make([]string, len(p.patterns))
+	for i, pt := range p.patterns {
+		sources[i] = pt.scheme + "://" + pt.host
+		if pt.port != "" {
+			sources[i] += ":" + pt.port
+		}
+	}
+	return strings.Join(sources, " ")
+}
+
+// sameHost reports whether an Origin header value names host, the request's Host.
+func sameHost(origin, host string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, host)
+}
@@ -0,0 +1,134 @@
+package overload
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+)
+
+const (
+	// evaluateInterval is how often load signals are checked.
+	evaluateInterval = time.Second
+
+	// recoveryRatio is the fraction of each threshold load must fall below to leave shedding mode.
+	recoveryRatio = 0.5
+
+	// latencyAlpha is the smoothing factor for the DB latency moving average.
+	latencyAlpha = 0.2
+)
+
+// Config holds the thresholds that switch the service into load-shedding mode.
+type Config struct {
+	// QueueDepthThreshold is the broadcast queue depth that triggers shedding.
+	QueueDepthThreshold int
+
+	// DBLatencyThreshold is the smoothed DB write latency that triggers shedding.
+	DBLatencyThreshold time.Duration
+
+	// RetryAfter is advertised to clients rejected while shedding.
+	RetryAfter time.Duration
+}
+
+// Controller watches broadcast queue depth and DB latency and flips the service into
+// load-shedding mode when either crosses its threshold. While shedding, callers are
+// expected to drop non-critical work so that core location flow stays alive.
+type Controller struct {
+	config     Config
+	queueDepth func() int
+	logger     *zap.Logger
+
+	shedding atomic.Bool
+
+	mu        sync.Mutex
+	dbLatency time.Duration // exponentially weighted moving average
+}
+
+// NewController creates a new Controller. queueDepth reports the current broadcast backlog.
+func NewController(config Config, queueDepth func() int, logger *zap.Logger) *Controller {
+	return &Controller{
+		config:     config,
+		queueDepth: queueDepth,
+		logger:     logger,
+	}
+}
+
+// Run evaluates load signals until the context is cancelled. Should be called in a goroutine.
+func (c *Controller) Run(ctx context.Context) {
+	ticker := time.NewTicker(evaluateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.evaluate()
+		}
+	}
+}
+
+// ObserveDBLatency records the duration of a DB operation on the hot path.
+func (c *Controller) ObserveDBLatency(d time.Duration) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	if c.dbLatency == 0 {
+		c.dbLatency = d
+	} else {
+		c.dbLatency = time.Duration(latencyAlpha*float64(d) + (1-latencyAlpha)*float64(c.dbLatency))
+	}
+	c.mu.Unlock()
+}
+
+// Shedding reports whether the service is currently shedding load. Safe on a nil Controller.
+func (c *Controller) Shedding() bool {
+	return c != nil && c.shedding.Load()
+}
+
+// Middleware rejects requests with 429 and Retry-After while shedding.
+// Apply it only to non-essential endpoints.
+func (c *Controller) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !c.Shedding() {
+			ctx.Next()
+			return
+		}
+		apperror.Respond(ctx, apperror.New(apperror.CodeOverloaded, "service is under heavy load, please retry later").
+			WithRetryAfter(c.config.RetryAfter))
+	}
+}
+
+// evaluate compares current signals with the thresholds and updates the shedding state.
+// Leaving shedding mode requires load to drop below recoveryRatio of each threshold.
+func (c *Controller) evaluate() {
+	depth := c.queueDepth()
+	c.mu.Lock()
+	latency := c.dbLatency
+	c.mu.Unlock()
+
+	overloaded := depth >= c.config.QueueDepthThreshold || latency >= c.config.DBLatencyThreshold
+	recovered := float64(depth) < float64(c.config.QueueDepthThreshold)*recoveryRatio &&
+		float64(latency) < float64(c.config.DBLatencyThreshold)*recoveryRatio
+
+	switch {
+	case !c.shedding.Load() && overloaded:
+		c.shedding.Store(true)
+		c.logger.Warn("entering load-shedding mode",
+			zap.Int("queue_depth", depth),
+			zap.Duration("db_latency", latency),
+		)
+	case c.shedding.Load() && recovered:
+		c.shedding.Store(false)
+		c.logger.Info("leaving load-shedding mode",
+			zap.Int("queue_depth", depth),
+			zap.Duration("db_latency", latency),
+		)
+	}
+}
@@ -0,0 +1,87 @@
+package probe
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// stats accumulates probe results.
+type stats struct {
+	passes        uint64
+	failures      map[string]uint64 // step -> count
+	lastRun       time.Time
+	lastSuccess   time.Time
+	lastDuration  time.Duration
+	lastSucceeded bool
+}
+
+// record stores the result of a probe trip.
+func (p *Prober) record(start time.Time, d time.Duration, step string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stats.lastRun = start
+	p.stats.lastDuration = d
+	p.stats.lastSucceeded = err == nil
+	if err != nil {
+		p.stats.failures[step]++
+		return
+	}
+	p.stats.passes++
+	p.stats.lastSuccess = start
+}
+
+// ServeHTTP writes the probe metrics in the Prometheus text exposition format.
+func (p *Prober) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	p.mu.Lock()
+	s := p.stats
+	steps := make([]string, 0, len(s.failures))
+	failures := make(map[string]uint64, len(s.failures))
+	for step, n := range s.failures {
+		steps = append(steps, step)
+		failures[step] = n
+	}
+	p.mu.Unlock()
+	sort.Strings(steps)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP tracking_probe_runs_total Synthetic probe trips by result and failed step.")
+	fmt.Fprintln(w, "# TYPE tracking_probe_runs_total counter")
+	fmt.Fprintf(w, "tracking_probe_runs_total{result=\"pass\",step=\"\"} %d\n", s.passes)
+	for _, step := range steps {
+		fmt.Fprintf(w, "tracking_probe_runs_total{result=\"fail\",step=%q} %d\n", step, failures[step])
+	}
+
+	fmt.Fprintln(w, "# HELP tracking_probe_up Whether the last synthetic probe trip passed.")
+	fmt.Fprintln(w, "# TYPE tracking_probe_up gauge")
+	fmt.Fprintf(w, "tracking_probe_up %d\n", boolToInt(s.lastSucceeded))
+
+	fmt.Fprintln(w, "# HELP tracking_probe_last_run_timestamp_seconds Start time of the last probe trip.")
+	fmt.Fprintln(w, "# TYPE tracking_probe_last_run_timestamp_seconds gauge")
+	fmt.Fprintf(w, "tracking_probe_last_run_timestamp_seconds %d\n", unixOrZero(s.lastRun))
+
+	fmt.Fprintln(w, "# HELP tracking_probe_last_success_timestamp_seconds Start time of the last passing probe trip.")
+	fmt.Fprintln(w, "# TYPE tracking_probe_last_success_timestamp_seconds gauge")
+	fmt.Fprintf(w, "tracking_probe_last_success_timestamp_seconds %d\n", unixOrZero(s.lastSuccess))
+
+	fmt.Fprintln(w, "# HELP tracking_probe_last_duration_seconds Duration of the last probe trip.")
+	fmt.Fprintln(w, "# TYPE tracking_probe_last_duration_seconds gauge")
+	fmt.Fprintf(w, "tracking_probe_last_duration_seconds %g\n", s.lastDuration.Seconds())
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,345 @@
+// Package probe runs synthetic trips through the tracking pipeline on a schedule and
+// exports the results as metrics, so a silently broken pipeline is noticed before
+// customers notice it.
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	kafkaGo "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	kafkaLib "github.com/Kilat-Pet-Delivery/lib-common/kafka"
+	"github.com/Kilat-Pet-Delivery/lib-proto/events"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/application"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/ws"
+)
+
+// Region is the region probe tracks are tagged with, so they can be told apart from real trips.
+const Region = "synthetic"
+
+// Probe steps, reported as the step label of failures.
+const (
+	StepStart      = "start"
+	StepIngest     = "ingest"
+	StepWSDelivery = "ws_delivery"
+	StepComplete   = "complete"
+	StepSummary    = "summary"
+	StepEvents     = "events"
+)
+
+// script is the route a probe trip follows: a short drive with one waypoint every 30s.
+var script = []struct {
+	lat, lng, speed, heading float64
+}{
+	{-6.20000, 106.81600, 0, 90},
+	{-6.20000, 106.81900, 24, 90},
+	{-6.20050, 106.82200, 26, 100},
+	{-6.20150, 106.82450, 28, 115},
+	{-6.20300, 106.82600, 22, 135},
+	{-6.20450, 106.82650, 10, 170},
+}
+
+// scriptInterval is the time between scripted waypoints.
+const scriptInterval = 30 * time.Second
+
+// expectedEvents are the tracking event types each probe trip must publish.
+var expectedEvents = []string{events.TrackingStarted, events.TrackingUpdated, events.TrackingCompleted}
+
+// Config holds probe settings.
+type Config struct {
+	// Interval is the time between probe trips.
+	Interval time.Duration
+	// Timeout bounds a single probe trip, including waiting for WS frames and events.
+	Timeout time.Duration
+	// RunnerID is the runner probe trips are assigned to. It must not belong to a real runner.
+	RunnerID uuid.UUID
+	// Brokers and GroupID configure the consumer that watches for published tracking events.
+	// The group must be unique per instance.
+	Brokers []string
+	GroupID string
+}
+
+// Prober runs probe trips and records their results.
+type Prober struct {
+	service *application.TrackingService
+	repo    trackingDomain.TripTrackRepository
+	hub     *ws.Hub
+	reader  *kafkaGo.Reader
+	config  Config
+	logger  *zap.Logger
+
+	mu      sync.Mutex
+	waiting map[uuid.UUID]chan string // bookingID -> tracking event types seen
+	stats   stats
+}
+
+// New creates a Prober. Tracks are deleted through repo once each trip finishes.
+func New(
+	service *application.TrackingService,
+	repo trackingDomain.TripTrackRepository,
+	hub *ws.Hub,
+	config Config,
+	logger *zap.Logger,
+) *Prober {
+	return &Prober{
+		service: service,
+		repo:    repo,
+		hub:     hub,
+		reader: kafkaGo.NewReader(kafkaGo.ReaderConfig{
+			Brokers:     config.Brokers,
+			GroupID:     config.GroupID,
+			Topic:       events.TopicTrackingEvents,
+			StartOffset: kafkaGo.LastOffset,
+		}),
+		config:  config,
+		logger:  logger.With(zap.String("component", "probe")),
+		waiting: make(map[uuid.UUID]chan string),
+		stats:   stats{failures: make(map[string]uint64)},
+	}
+}
+
+// Start runs a probe trip immediately and then every interval until ctx is cancelled.
+func (p *Prober) Start(ctx context.Context) {
+	go p.watchEvents(ctx)
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+	for {
+		p.runOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close stops the tracking event consumer.
+func (p *Prober) Close() error {
+	return p.reader.Close()
+}
+
+// runOnce runs one probe trip and records its result.
+func (p *Prober) runOnce(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	step, err := p.trip(ctx)
+	p.record(start, time.Since(start), step, err)
+
+	if err != nil {
+		p.logger.Warn("probe trip failed", zap.String("step", step), zap.Error(err))
+		return
+	}
+	p.logger.Debug("probe trip passed", zap.Duration("duration", time.Since(start)))
+}
+
+// trip drives a synthetic booking through the pipeline. On failure it returns the step
+// that failed.
+func (p *Prober) trip(ctx context.Context) (string, error) {
+	p.removeStaleTracks(ctx)
+
+	bookingID := uuid.New()
+	seen := p.watch(bookingID)
+	defer p.unwatch(bookingID)
+
+	if err := p.service.HandleBookingAccepted(ctx, events.BookingAcceptedEvent{
+		BookingID:  bookingID,
+		RunnerID:   p.config.RunnerID,
+		OccurredAt: time.Now().UTC(),
+	}, Region); err != nil {
+		return StepStart, err
+	}
+	track, err := p.service.GetTracking(ctx, bookingID)
+	if err != nil {
+		return StepStart, err
+	}
+	defer p.cleanup(track.ID)
+
+	client := ws.NewClient(nil, bookingID, time.Time{}, nil)
+	client.Scope = ws.ScopeFull
+	p.hub.Register(client)
+	defer p.hub.Unregister(client)
+
+	// Locations recorded before the trip started would be dropped.
+	base := track.StartedAt
+	for i, pt := range script {
+		if err := p.service.HandleRunnerLocationUpdate(ctx, application.RunnerLocationUpdate{RunnerLocationUpdateEvent: events.RunnerLocationUpdateEvent{
+			RunnerID:  p.config.RunnerID,
+			Latitude:  pt.lat,
+			Longitude: pt.lng,
+			Speed:     pt.speed,
+			Heading:   pt.heading,
+			Timestamp: base.Add(time.Duration(i) * scriptInterval),
+		}}); err != nil {
+			return StepIngest, err
+		}
+	}
+
+	if err := awaitLocationFrames(ctx, client, len(script)); err != nil {
+		return StepWSDelivery, err
+	}
+
+	if err := p.service.HandleDeliveryConfirmed(ctx, events.DeliveryConfirmedEvent{
+		BookingID:  bookingID,
+		OccurredAt: time.Now().UTC(),
+	}); err != nil {
+		return StepComplete, err
+	}
+
+	summary, err := p.service.GetTracking(ctx, bookingID)
+	if err != nil {
+		return StepSummary, err
+	}
+	if err := verifySummary(summary); err != nil {
+		return StepSummary, err
+	}
+
+	if err := awaitEvents(ctx, seen); err != nil {
+		return StepEvents, err
+	}
+	return "", nil
+}
+
+// removeStaleTracks deletes active probe tracks left behind by an interrupted trip, which
+// would otherwise receive this trip's waypoints.
+func (p *Prober) removeStaleTracks(ctx context.Context) {
+	tracks, err := p.repo.FindAllActiveByRunnerID(ctx, p.config.RunnerID)
+	if err != nil {
+		return
+	}
+	for _, t := range tracks {
+		if err := p.repo.Delete(ctx, t.ID()); err != nil {
+			p.logger.Warn("failed to delete stale probe track", zap.String("track_id", t.ID().String()), zap.Error(err))
+		}
+	}
+}
+
+// cleanup deletes a probe track. It uses its own context so tracks are removed even
+// when the trip timed out.
+func (p *Prober) cleanup(trackID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := p.repo.Delete(ctx, trackID); err != nil {
+		p.logger.Warn("failed to delete probe track", zap.String("track_id", trackID.String()), zap.Error(err))
+	}
+}
+
+// awaitLocationFrames waits for n location_update frames on a probe client.
+func awaitLocationFrames(ctx context.Context, client *ws.Client, n int) error {
+	received := 0
+	for received < n {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("received %d of %d location frames: %w", received, n, ctx.Err())
+		case data, ok := <-client.Send:
+			if !ok {
+				return fmt.Errorf("probe client was dropped after %d of %d location frames", received, n)
+			}
+			var frame struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(data, &frame); err == nil && frame.Type == "location_update" {
+				received++
+			}
+		}
+	}
+	return nil
+}
+
+// verifySummary checks the completed probe trip.
+func verifySummary(t *application.TrackingDTO) error {
+	if t.Status != string(trackingDomain.TrackingCompleted) {
+		return fmt.Errorf("status is %q, want %q", t.Status, trackingDomain.TrackingCompleted)
+	}
+	if len(t.Waypoints) != len(script) {
+		return fmt.Errorf("got %d waypoints, want %d", len(t.Waypoints), len(script))
+	}
+	if t.TotalDistanceKm <= 0 {
+		return errors.New("total distance was not computed")
+	}
+	return nil
+}
+
+// awaitEvents waits until every expected tracking event type has been seen.
+func awaitEvents(ctx context.Context, seen <-chan string) error {
+	missing := make(map[string]bool, len(expectedEvents))
+	for _, t := range expectedEvents {
+		missing[t] = true
+	}
+	for len(missing) > 0 {
+		select {
+		case <-ctx.Done():
+			types := make([]string, 0, len(missing))
+			for t := range missing {
+				types = append(types, t)
+			}
+			return fmt.Errorf("events not observed %v: %w", types, ctx.Err())
+		case t := <-seen:
+			delete(missing, t)
+		}
+	}
+	return nil
+}
+
+// watch starts collecting tracking event types published for a booking.
+func (p *Prober) watch(bookingID uuid.UUID) <-chan string {
+	ch := make(chan string, 64)
+	p.mu.Lock()
+	p.waiting[bookingID] = ch
+	p.mu.Unlock()
+	return ch
+}
+
+// unwatch stops collecting events for a booking.
+func (p *Prober) unwatch(bookingID uuid.UUID) {
+	p.mu.Lock()
+	delete(p.waiting, bookingID)
+	p.mu.Unlock()
+}
+
+// watchEvents consumes tracking events and forwards those of watched bookings.
+func (p *Prober) watchEvents(ctx context.Context) {
+	for {
+		msg, err := p.reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			p.logger.Warn("failed to read tracking event", zap.Error(err))
+			time.Sleep(time.Second)
+			continue
+		}
+
+		cloudEvent, err := kafkaLib.ParseCloudEvent(msg.Value)
+		if err != nil {
+			continue
+		}
+		var data struct {
+			BookingID uuid.UUID `json:"booking_id"`
+		}
+		if err := cloudEvent.ParseData(&data); err != nil {
+			continue
+		}
+
+		p.mu.Lock()
+		ch, ok := p.waiting[data.BookingID]
+		p.mu.Unlock()
+		if !ok {
+			continue
+		}
+		select {
+		case ch <- cloudEvent.Type:
+		default:
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/middleware"
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/apperror"
+)
+
+// Scopes are the groups of requests that share a bucket per user.
+const (
+	// ScopeLocations covers location batches, over HTTP and the runner socket.
+	ScopeLocations = "locations"
+	// ScopeChat covers chat messages.
+	ScopeChat = "chat"
+)
+
+// sweepInterval is how often the in-memory store forgets full buckets.
+const sweepInterval = time.Minute
+
+// Limit is a token bucket: Burst requests at once, refilled at Rate per second.
+// A non-positive Rate disables the limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Store takes tokens from buckets. Take removes one token from the bucket at key,
+// returning zero if there was one, or how long until there will be.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (time.Duration, error)
+}
+
+// Limiter enforces a token bucket per user and scope. Buckets live in memory on each
+// instance unless a shared store is used, in which case the in-memory buckets only take
+// over while the shared store is unavailable.
+type Limiter struct {
+	limits map[string]Limit
+	local  *MemoryStore
+	shared Store
+	logger *zap.Logger
+
+	degraded atomic.Bool
+}
+
+// NewLimiter creates a Limiter enforcing limits, keyed by scope.
+func NewLimiter(limits map[string]Limit, logger *zap.Logger) *Limiter {
+	return &Limiter{
+		limits: limits,
+		local:  NewMemoryStore(),
+		logger: logger,
+	}
+}
+
+// UseStore shares buckets with other instances through s. Must be called before
+// requests are served.
+func (l *Limiter) UseStore(s Store) {
+	l.shared = s
+}
+
+// Allow takes a token from the subject's bucket for scope, returning a rate_limited
+// error with the time to wait if it is empty.
+func (l *Limiter) Allow(ctx context.Context, scope string, subject uuid.UUID) error {
+	limit, ok := l.limits[scope]
+	if !ok || limit.Rate <= 0 {
+		return nil
+	}
+
+	key := "ratelimit:" + scope + ":" + subject.String()
+	wait, err := l.take(ctx, key, limit)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return apperror.New(apperror.CodeRateLimited, "at most %g requests per second with bursts of %d", limit.Rate, limit.Burst).
+			WithRetryAfter(wait)
+	}
+	return nil
+}
+
+// take uses the shared store if there is one, falling back to the in-memory buckets
+// while it fails.
+func (l *Limiter) take(ctx context.Context, key string, limit Limit) (time.Duration, error) {
+	if l.shared == nil {
+		return l.local.Take(ctx, key, limit)
+	}
+
+	wait, err := l.shared.Take(ctx, key, limit)
+	if err == nil {
+		if l.degraded.CompareAndSwap(true, false) {
+			l.logger.Info("rate limit store recovered")
+		}
+		return wait, nil
+	}
+	if l.degraded.CompareAndSwap(false, true) {
+		l.logger.Warn("rate limit store failed, limiting per instance", zap.Error(err))
+	}
+	return l.local.Take(ctx, key, limit)
+}
+
+// Middleware limits the authenticated user's requests in scope. It must run after the
+// auth middleware.
+func (l *Limiter) Middleware(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			apperror.Abort(c, apperror.CodeUnauthorized, "authentication required")
+			return
+		}
+		if err := l.Allow(c.Request.Context(), scope, userID); err != nil {
+			apperror.Respond(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// MemoryStore keeps token buckets in process memory.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens   float64
+	updated  time.Time
+	fullTime time.Time // when the bucket is full again if left alone
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+
+	var wait time.Duration
+	if b.tokens >= 1 {
+		b.tokens--
+	} else {
+		wait = time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	}
+	b.fullTime = now.Add(time.Duration((float64(limit.Burst) - b.tokens) / limit.Rate * float64(time.Second)))
+	return wait, nil
+}
+
+// sweep forgets buckets that have refilled, since a new bucket starts out full.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.fullTime) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills and takes from a token bucket stored as a hash, using the Redis
+// server's clock so instances with skewed clocks share buckets fairly. It returns how
+// many milliseconds to wait, or 0 if a token was taken.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1]) / 1000
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+else
+  wait = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate) + 1000)
+return wait
+`)
+
+// RedisStore keeps token buckets in Redis, so a limit holds across instances.
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a RedisStore.
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Take implements Store.
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (time.Duration, error) {
+	ms, err := takeScript.Run(ctx, s.client, []string{key}, limit.Rate, limit.Burst).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	alertDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/alert"
+)
+
+// TrackAlertModel is the GORM model for the track_alerts table.
+type TrackAlertModel struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TripTrackID uuid.UUID `gorm:"type:uuid;not null;index"`
+	BookingID   uuid.UUID `gorm:"type:uuid;not null"`
+	RunnerID    uuid.UUID `gorm:"type:uuid;not null"`
+	Kind        string    `gorm:"type:varchar(32);not null"`
+	Latitude    float64   `gorm:"type:double precision;not null"`
+	Longitude   float64   `gorm:"type:double precision;not null"`
+	Value       float64   `gorm:"type:double precision;not null"`
+	Threshold   float64   `gorm:"type:double precision;not null"`
+	Waypoints   int       `gorm:"not null"`
+	RaisedAt    time.Time `gorm:"type:timestamptz;not null"`
+}
+
+// TableName sets the table name.
+func (TrackAlertModel) TableName() string { return "track_alerts" }
+
+// GormTrackAlertRepository implements alert.Repository using GORM.
+type GormTrackAlertRepository struct {
+	db *gorm.DB
+}
+
+// NewGormTrackAlertRepository creates a new GormTrackAlertRepository.
+func NewGormTrackAlertRepository(db *gorm.DB) *GormTrackAlertRepository {
+	return &GormTrackAlertRepository{db: db}
+}
+
+// Save persists a new track alert.
+func (r *GormTrackAlertRepository) Save(ctx context.Context, a *alertDomain.TrackAlert) error {
+	model := TrackAlertModel{
+		ID:          a.ID,
+		TripTrackID: a.TrackID,
+		BookingID:   a.BookingID,
+		RunnerID:    a.RunnerID,
+		Kind:        string(a.Kind),
+		Latitude:    a.Latitude,
+		Longitude:   a.Longitude,
+		Value:       a.Value,
+		Threshold:   a.Threshold,
+		Waypoints:   a.Waypoints,
+		RaisedAt:    a.RaisedAt,
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+// FindByTrackID returns a trip's alerts, oldest first.
+func (r *GormTrackAlertRepository) FindByTrackID(ctx context.Context, trackID uuid.UUID) ([]*alertDomain.TrackAlert, error) {
+	var models []TrackAlertModel
+	if err := r.db.WithContext(ctx).
+		Where("trip_track_id = ?", trackID).
+		Order("raised_at ASC").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	alerts := make([]*alertDomain.TrackAlert, len(models))
+	for i, m := range models {
+		alerts[i] = &alertDomain.TrackAlert{
+			ID:        m.ID,
+			TrackID:   m.TripTrackID,
+			BookingID: m.BookingID,
+			RunnerID:  m.RunnerID,
+			Kind:      alertDomain.Kind(m.Kind),
+			Latitude:  m.Latitude,
+			Longitude: m.Longitude,
+			Value:     m.Value,
+			Threshold: m.Threshold,
+			Waypoints: m.Waypoints,
+			RaisedAt:  m.RaisedAt,
+		}
+	}
+	return alerts, nil
+}
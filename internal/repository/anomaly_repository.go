@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	anomalyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/anomaly"
+)
+
+// TrackingAnomalyModel is the GORM model for the tracking_anomalies table.
+type TrackingAnomalyModel struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TripTrackID uuid.UUID `gorm:"type:uuid;not null;index"`
+	BookingID   uuid.UUID `gorm:"type:uuid;not null"`
+	RunnerID    uuid.UUID `gorm:"type:uuid;not null"`
+	Kind        string    `gorm:"type:varchar(32);not null"`
+	Latitude    float64   `gorm:"type:double precision;not null"`
+	Longitude   float64   `gorm:"type:double precision;not null"`
+	Value       float64   `gorm:"type:double precision;not null"`
+	Threshold   float64   `gorm:"type:double precision;not null"`
+	DetectedAt  time.Time `gorm:"type:timestamptz;not null"`
+}
+
+// TableName sets the table name.
+func (TrackingAnomalyModel) TableName() string { return "tracking_anomalies" }
+
+// GormAnomalyRepository implements anomaly.Repository using GORM.
+type GormAnomalyRepository struct {
+	db *gorm.DB
+}
+
+// NewGormAnomalyRepository creates a new GormAnomalyRepository.
+func NewGormAnomalyRepository(db *gorm.DB) *GormAnomalyRepository {
+	return &GormAnomalyRepository{db: db}
+}
+
+// Save persists a new anomaly.
+func (r *GormAnomalyRepository) Save(ctx context.Context, a *anomalyDomain.Anomaly) error {
+	model := TrackingAnomalyModel{
+		ID:          a.ID,
+		TripTrackID: a.TrackID,
+		BookingID:   a.BookingID,
+		RunnerID:    a.RunnerID,
+		Kind:        string(a.Kind),
+		Latitude:    a.Latitude,
+		Longitude:   a.Longitude,
+		Value:       a.Value,
+		Threshold:   a.Threshold,
+		DetectedAt:  a.DetectedAt,
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+// FindByTrackID returns a trip's anomalies, oldest first.
+func (r *GormAnomalyRepository) FindByTrackID(ctx context.Context, trackID uuid.UUID) ([]*anomalyDomain.Anomaly, error) {
+	var models []TrackingAnomalyModel
+	if err := r.db.WithContext(ctx).
+		Where("trip_track_id = ?", trackID).
+		Order("detected_at ASC").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	anomalies := make([]*anomalyDomain.Anomaly, len(models))
+	for i, m := range models {
+		anomalies[i] = &anomalyDomain.Anomaly{
+			ID:         m.ID,
+			TrackID:    m.TripTrackID,
+			BookingID:  m.BookingID,
+			RunnerID:   m.RunnerID,
+			Kind:       anomalyDomain.Kind(m.Kind),
+			Latitude:   m.Latitude,
+			Longitude:  m.Longitude,
+			Value:      m.Value,
+			Threshold:  m.Threshold,
+			DetectedAt: m.DetectedAt,
+		}
+	}
+	return anomalies, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	certificateDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/certificate"
+)
+
+// TripCertificateModel is the GORM model for the trip_certificates table.
+type TripCertificateModel struct {
+	TripTrackID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	BookingID   uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+	KeyID       string    `gorm:"type:varchar(64);not null"`
+	Algorithm   string    `gorm:"type:varchar(16);not null"`
+	Payload     string    `gorm:"type:text;not null"`
+	Signature   string    `gorm:"type:text;not null"`
+	IssuedAt    time.Time `gorm:"type:timestamptz;not null"`
+}
+
+// TableName sets the table name.
+func (TripCertificateModel) TableName() string { return "trip_certificates" }
+
+// GormCertificateRepository implements certificate.Repository using GORM.
+type GormCertificateRepository struct {
+	db *gorm.DB
+}
+
+// NewGormCertificateRepository creates a new GormCertificateRepository.
+func NewGormCertificateRepository(db *gorm.DB) *GormCertificateRepository {
+	return &GormCertificateRepository{db: db}
+}
+
+// Save persists a new certificate.
+func (r *GormCertificateRepository) Save(ctx context.Context, c *certificateDomain.Certificate) error {
+	model := TripCertificateModel{
+		TripTrackID: c.TrackID,
+		BookingID:   c.BookingID,
+		KeyID:       c.KeyID,
+		Algorithm:   c.Algorithm,
+		Payload:     string(c.Payload),
+		Signature:   c.Signature,
+		IssuedAt:    c.IssuedAt,
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+// FindByBookingID retrieves the certificate of a booking's trip.
+func (r *GormCertificateRepository) FindByBookingID(ctx context.Context, bookingID uuid.UUID) (*certificateDomain.Certificate, error) {
+	var m TripCertificateModel
+	if err := r.db.WithContext(ctx).Where("booking_id = ?", bookingID).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &certificateDomain.Certificate{
+		TrackID:   m.TripTrackID,
+		BookingID: m.BookingID,
+		KeyID:     m.KeyID,
+		Algorithm: m.Algorithm,
+		Payload:   []byte(m.Payload),
+		Signature: m.Signature,
+		IssuedAt:  m.IssuedAt,
+	}, nil
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	chatDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/chat"
@@ -11,13 +13,18 @@ import (
 
 // ChatMessageModel is the GORM model for the chat_messages table.
 type ChatMessageModel struct {
-	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
-	BookingID  uuid.UUID `gorm:"type:uuid;not null;index"`
-	SenderID   uuid.UUID `gorm:"type:uuid;not null"`
-	SenderRole string    `gorm:"type:varchar(20);not null"`
-	MsgType    string    `gorm:"column:message_type;type:varchar(20);not null"`
-	Content    string    `gorm:"type:text;not null"`
-	CreatedAt  time.Time `gorm:"not null"`
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	BookingID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	SenderID    uuid.UUID `gorm:"type:uuid;not null"`
+	SenderRole  string    `gorm:"type:varchar(20);not null"`
+	MsgType     string    `gorm:"column:message_type;type:varchar(20);not null"`
+	Content     string    `gorm:"type:text;not null"`
+	Attachments string    `gorm:"type:jsonb;not null;default:'[]'"`
+	// ModerationFlags records how content moderation changed the message, for audit.
+	ModerationFlags string    `gorm:"type:jsonb;not null;default:'[]'"`
+	CreatedAt       time.Time `gorm:"not null"`
+	// ErasedAt is set when the message was anonymized for a data erasure request.
+	ErasedAt *time.Time `gorm:"type:timestamptz"`
 }
 
 // TableName sets the table name.
@@ -35,10 +42,51 @@ func NewGormChatRepository(db *gorm.DB) *GormChatRepository {
 
 // Save persists a new chat message.
 func (r *GormChatRepository) Save(ctx context.Context, msg *chatDomain.ChatMessage) error {
-	model := toChatModel(msg)
+	model, err := toChatModel(msg)
+	if err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(&model).Error
 }
 
+// ReassignBooking moves all messages of one booking to another.
+func (r *GormChatRepository) ReassignBooking(ctx context.Context, fromBookingID, toBookingID uuid.UUID) (int, error) {
+	result := r.db.WithContext(ctx).Model(&ChatMessageModel{}).
+		Where("booking_id = ?", fromBookingID).
+		Update("booking_id", toBookingID)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+// EraseByBookingID clears the content and attachments of a booking's messages not yet
+// erased and marks them erased.
+func (r *GormChatRepository) EraseByBookingID(ctx context.Context, bookingID uuid.UUID, at time.Time) (int, error) {
+	result := r.db.WithContext(ctx).Model(&ChatMessageModel{}).
+		Where("booking_id = ? AND erased_at IS NULL", bookingID).
+		UpdateColumns(map[string]interface{}{
+			"content":     "",
+			"attachments": "[]",
+			"erased_at":   at,
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+// CountUnerasedByBookingID returns how many of a booking's messages are not erased.
+func (r *GormChatRepository) CountUnerasedByBookingID(ctx context.Context, bookingID uuid.UUID) (int, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&ChatMessageModel{}).
+		Where("booking_id = ? AND erased_at IS NULL", bookingID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
 // FindByBookingID returns paginated chat messages for a booking.
 func (r *GormChatRepository) FindByBookingID(ctx context.Context, bookingID uuid.UUID, limit, offset int) ([]*chatDomain.ChatMessage, int64, error) {
 	var models []ChatMessageModel
@@ -53,24 +101,60 @@ func (r *GormChatRepository) FindByBookingID(ctx context.Context, bookingID uuid
 
 	messages := make([]*chatDomain.ChatMessage, len(models))
 	for i, m := range models {
-		messages[i] = toChatDomain(&m)
+		msg, err := toChatDomain(&m)
+		if err != nil {
+			return nil, 0, err
+		}
+		messages[i] = msg
 	}
 	return messages, total, nil
 }
 
-func toChatModel(m *chatDomain.ChatMessage) ChatMessageModel {
-	return ChatMessageModel{
-		ID:         m.ID(),
-		BookingID:  m.BookingID(),
-		SenderID:   m.SenderID(),
-		SenderRole: m.SenderRole(),
-		MsgType:    string(m.MessageType()),
-		Content:    m.Content(),
-		CreatedAt:  m.CreatedAt(),
+func toChatModel(m *chatDomain.ChatMessage) (ChatMessageModel, error) {
+	attachments := m.Attachments()
+	if attachments == nil {
+		attachments = []chatDomain.Attachment{}
+	}
+	data, err := json.Marshal(attachments)
+	if err != nil {
+		return ChatMessageModel{}, fmt.Errorf("failed to marshal chat attachments: %w", err)
+	}
+	flags := m.ModerationFlags()
+	if flags == nil {
+		flags = []chatDomain.ModerationFlag{}
 	}
+	flagData, err := json.Marshal(flags)
+	if err != nil {
+		return ChatMessageModel{}, fmt.Errorf("failed to marshal chat moderation flags: %w", err)
+	}
+
+	return ChatMessageModel{
+		ID:              m.ID(),
+		BookingID:       m.BookingID(),
+		SenderID:        m.SenderID(),
+		SenderRole:      m.SenderRole(),
+		MsgType:         string(m.MessageType()),
+		Content:         m.Content(),
+		Attachments:     string(data),
+		ModerationFlags: string(flagData),
+		CreatedAt:       m.CreatedAt(),
+	}, nil
 }
 
-func toChatDomain(m *ChatMessageModel) *chatDomain.ChatMessage {
+func toChatDomain(m *ChatMessageModel) (*chatDomain.ChatMessage, error) {
+	var attachments []chatDomain.Attachment
+	if m.Attachments != "" {
+		if err := json.Unmarshal([]byte(m.Attachments), &attachments); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal chat attachments: %w", err)
+		}
+	}
+	var flags []chatDomain.ModerationFlag
+	if m.ModerationFlags != "" {
+		if err := json.Unmarshal([]byte(m.ModerationFlags), &flags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal chat moderation flags: %w", err)
+		}
+	}
+
 	return chatDomain.Reconstruct(
 		m.ID,
 		m.BookingID,
@@ -78,6 +162,8 @@ func toChatDomain(m *ChatMessageModel) *chatDomain.ChatMessage {
 		m.SenderRole,
 		chatDomain.MessageType(m.MsgType),
 		m.Content,
+		attachments,
+		flags,
 		m.CreatedAt,
-	)
+	), nil
 }
@@ -0,0 +1,450 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// StorageMigrationMode selects how DualWriteTripTrackRepository splits traffic between
+// the current and the new storage backend.
+type StorageMigrationMode string
+
+// Storage migration modes, in the order a migration moves through them.
+const (
+	// MigrationOff uses the current backend only.
+	MigrationOff StorageMigrationMode = "off"
+	// MigrationDualWrite writes to both backends and reads from the current one.
+	MigrationDualWrite StorageMigrationMode = "dual_write"
+	// MigrationShadowRead is MigrationDualWrite plus a background read from the new
+	// backend for each read, compared against the served result.
+	MigrationShadowRead StorageMigrationMode = "shadow_read"
+	// MigrationCutover serves reads from the new backend, still writing to both and
+	// shadow-reading the current backend so the switch can be rolled back.
+	MigrationCutover StorageMigrationMode = "cutover"
+)
+
+// ParseStorageMigrationMode parses a mode name.
+func ParseStorageMigrationMode(s string) (StorageMigrationMode, error) {
+	switch m := StorageMigrationMode(s); m {
+	case MigrationOff, MigrationDualWrite, MigrationShadowRead, MigrationCutover:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown storage migration mode %q", s)
+}
+
+// shadowReadTimeout bounds a background shadow read.
+const shadowReadTimeout = 5 * time.Second
+
+// maxShadowReads bounds concurrent shadow reads; reads beyond it are skipped.
+const maxShadowReads = 32
+
+// DualWriteTripTrackRepository migrates trip track storage to a new backend without
+// downtime. Writes go to the serving backend first and its result is returned; the
+// other backend's failures are only counted and logged. Reads may be mirrored to the
+// other backend and compared.
+type DualWriteTripTrackRepository struct {
+	current trackingDomain.TripTrackRepository
+	next    trackingDomain.TripTrackRepository
+	mode    StorageMigrationMode
+	logger  *zap.Logger
+
+	shadowSlots chan struct{}
+	metrics     *migrationMetrics
+}
+
+// NewDualWriteTripTrackRepository creates a repository migrating from current to next.
+func NewDualWriteTripTrackRepository(current, next trackingDomain.TripTrackRepository, mode StorageMigrationMode, logger *zap.Logger) *DualWriteTripTrackRepository {
+	return &DualWriteTripTrackRepository{
+		current:     current,
+		next:        next,
+		mode:        mode,
+		logger:      logger.With(zap.String("component", "storage_migration"), zap.String("mode", string(mode))),
+		shadowSlots: make(chan struct{}, maxShadowReads),
+		metrics:     newMigrationMetrics(),
+	}
+}
+
+// Mode returns the migration mode.
+func (r *DualWriteTripTrackRepository) Mode() StorageMigrationMode { return r.mode }
+
+// primary returns the backend that serves results and the one that mirrors them.
+func (r *DualWriteTripTrackRepository) primary() (serving, mirror trackingDomain.TripTrackRepository) {
+	if r.mode == MigrationCutover {
+		return r.next, r.current
+	}
+	return r.current, r.next
+}
+
+// write applies op to the serving backend and, unless migration is off, the mirror.
+func (r *DualWriteTripTrackRepository) write(ctx context.Context, op string, fn func(trackingDomain.TripTrackRepository) error) error {
+	serving, mirror := r.primary()
+	if err := fn(serving); err != nil {
+		return err
+	}
+	if r.mode == MigrationOff {
+		return nil
+	}
+	if err := fn(mirror); err != nil {
+		r.metrics.mirrorWriteFailed(op)
+		r.logger.Warn("mirror write failed", zap.String("op", op), zap.Error(err))
+		return nil
+	}
+	r.metrics.mirrorWriteSucceeded(op)
+	return nil
+}
+
+// shadow compares the served result of a read with the mirror's result in the
+// background when shadow reads are enabled.
+func (r *DualWriteTripTrackRepository) shadow(op string, served string, fn func(context.Context, trackingDomain.TripTrackRepository) (string, error)) {
+	if r.mode != MigrationShadowRead && r.mode != MigrationCutover {
+		return
+	}
+	select {
+	case r.shadowSlots <- struct{}{}:
+	default:
+		r.metrics.shadowSkipped(op)
+		return
+	}
+
+	_, mirror := r.primary()
+	go func() {
+		defer func() { <-r.shadowSlots }()
+		ctx, cancel := context.WithTimeout(context.Background(), shadowReadTimeout)
+		defer cancel()
+
+		got, err := fn(ctx, mirror)
+		if err != nil {
+			r.metrics.shadowFailed(op)
+			r.logger.Warn("shadow read failed", zap.String("op", op), zap.Error(err))
+			return
+		}
+		if got != served {
+			r.metrics.shadowMismatched(op)
+			r.logger.Warn("shadow read mismatch",
+				zap.String("op", op),
+				zap.String("served", served),
+				zap.String("shadow", got),
+			)
+			return
+		}
+		r.metrics.shadowMatched(op)
+	}()
+}
+
+// readTrack serves a trip track lookup and shadows it.
+func (r *DualWriteTripTrackRepository) readTrack(ctx context.Context, op string, fn func(context.Context, trackingDomain.TripTrackRepository) (*trackingDomain.TripTrack, error)) (*trackingDomain.TripTrack, error) {
+	serving, _ := r.primary()
+	track, err := fn(ctx, serving)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	r.shadow(op, trackFingerprint(track), func(ctx context.Context, repo trackingDomain.TripTrackRepository) (string, error) {
+		t, err := fn(ctx, repo)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return "", err
+		}
+		return trackFingerprint(t), nil
+	})
+	return track, err
+}
+
+// readWaypoints serves a waypoint lookup and shadows it.
+func (r *DualWriteTripTrackRepository) readWaypoints(ctx context.Context, op string, fn func(context.Context, trackingDomain.TripTrackRepository) ([]trackingDomain.Waypoint, error)) ([]trackingDomain.Waypoint, error) {
+	serving, _ := r.primary()
+	waypoints, err := fn(ctx, serving)
+	if err != nil {
+		return nil, err
+	}
+	r.shadow(op, waypointsFingerprint(waypoints), func(ctx context.Context, repo trackingDomain.TripTrackRepository) (string, error) {
+		w, err := fn(ctx, repo)
+		if err != nil {
+			return "", err
+		}
+		return waypointsFingerprint(w), nil
+	})
+	return waypoints, nil
+}
+
+// trackFingerprint summarizes the fields of a trip track both backends must agree on.
+func trackFingerprint(t *trackingDomain.TripTrack) string {
+	if t == nil {
+		return "not_found"
+	}
+	return fmt.Sprintf("%s status=%s distance=%.3f version=%d", t.ID(), t.Status(), t.TotalDistanceKm(), t.Version())
+}
+
+// waypointsFingerprint summarizes a list of waypoints by count and end points.
+func waypointsFingerprint(w []trackingDomain.Waypoint) string {
+	if len(w) == 0 {
+		return "count=0"
+	}
+	return fmt.Sprintf("count=%d first=%s last=%s", len(w), w[0].ID, w[len(w)-1].ID)
+}
+
+// FindByID retrieves a trip track by its unique identifier.
+func (r *DualWriteTripTrackRepository) FindByID(ctx context.Context, id uuid.UUID) (*trackingDomain.TripTrack, error) {
+	return r.readTrack(ctx, "find_by_id", func(ctx context.Context, repo trackingDomain.TripTrackRepository) (*trackingDomain.TripTrack, error) {
+		return repo.FindByID(ctx, id)
+	})
+}
+
+// FindByBookingID retrieves a trip track by its associated booking identifier.
+func (r *DualWriteTripTrackRepository) FindByBookingID(ctx context.Context, bookingID uuid.UUID) (*trackingDomain.TripTrack, error) {
+	return r.readTrack(ctx, "find_by_booking_id", func(ctx context.Context, repo trackingDomain.TripTrackRepository) (*trackingDomain.TripTrack, error) {
+		return repo.FindByBookingID(ctx, bookingID)
+	})
+}
+
+// FindActiveByRunnerID retrieves the oldest active trip track for a runner.
+func (r *DualWriteTripTrackRepository) FindActiveByRunnerID(ctx context.Context, runnerID uuid.UUID) (*trackingDomain.TripTrack, error) {
+	return r.readTrack(ctx, "find_active_by_runner_id", func(ctx context.Context, repo trackingDomain.TripTrackRepository) (*trackingDomain.TripTrack, error) {
+		return repo.FindActiveByRunnerID(ctx, runnerID)
+	})
+}
+
+// FindAllActiveByRunnerID retrieves all active trip tracks for a runner, oldest first.
+func (r *DualWriteTripTrackRepository) FindAllActiveByRunnerID(ctx context.Context, runnerID uuid.UUID) ([]*trackingDomain.TripTrack, error) {
+	serving, _ := r.primary()
+	return serving.FindAllActiveByRunnerID(ctx, runnerID)
+}
+
+// FindByRunnerIDSince retrieves a runner's trip tracks that were active since a moment, oldest first.
+func (r *DualWriteTripTrackRepository) FindByRunnerIDSince(ctx context.Context, runnerID uuid.UUID, since time.Time) ([]*trackingDomain.TripTrack, error) {
+	serving, _ := r.primary()
+	return serving.FindByRunnerIDSince(ctx, runnerID, since)
+}
+
+// FindRunnerIDsActiveBetween returns the runners with a trip track active between from and to.
+func (r *DualWriteTripTrackRepository) FindRunnerIDsActiveBetween(ctx context.Context, from, to time.Time) ([]uuid.UUID, error) {
+	serving, _ := r.primary()
+	return serving.FindRunnerIDsActiveBetween(ctx, from, to)
+}
+
+// FindActiveBookingIDsByRegion returns the bookings with an active trip track in a region.
+func (r *DualWriteTripTrackRepository) FindActiveBookingIDsByRegion(ctx context.Context, region string) ([]uuid.UUID, error) {
+	serving, _ := r.primary()
+	return serving.FindActiveBookingIDsByRegion(ctx, region)
+}
+
+// List retrieves the trip tracks matching the query built from opts.
+func (r *DualWriteTripTrackRepository) List(ctx context.Context, opts ...trackingDomain.ListOption) ([]*trackingDomain.TripTrack, error) {
+	serving, _ := r.primary()
+	return serving.List(ctx, opts...)
+}
+
+// Save persists a new trip track.
+func (r *DualWriteTripTrackRepository) Save(ctx context.Context, track *trackingDomain.TripTrack) error {
+	return r.write(ctx, "save", func(repo trackingDomain.TripTrackRepository) error {
+		return repo.Save(ctx, track)
+	})
+}
+
+// Update persists changes to an existing trip track.
+func (r *DualWriteTripTrackRepository) Update(ctx context.Context, track *trackingDomain.TripTrack) error {
+	return r.write(ctx, "update", func(repo trackingDomain.TripTrackRepository) error {
+		return repo.Update(ctx, track)
+	})
+}
+
+// Delete removes a trip track and its waypoints.
+func (r *DualWriteTripTrackRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.write(ctx, "delete", func(repo trackingDomain.TripTrackRepository) error {
+		return repo.Delete(ctx, id)
+	})
+}
+
+// MoveWaypoints moves all waypoints of one trip track to another.
+func (r *DualWriteTripTrackRepository) MoveWaypoints(ctx context.Context, fromTrackID, toTrackID uuid.UUID) error {
+	return r.write(ctx, "move_waypoints", func(repo trackingDomain.TripTrackRepository) error {
+		return repo.MoveWaypoints(ctx, fromTrackID, toTrackID)
+	})
+}
+
+// EraseLocations deletes a trip track's waypoints and clears its stored positions.
+func (r *DualWriteTripTrackRepository) EraseLocations(ctx context.Context, trackID uuid.UUID) (int, error) {
+	serving, _ := r.primary()
+	var deleted int
+	err := r.write(ctx, "erase_locations", func(repo trackingDomain.TripTrackRepository) error {
+		n, err := repo.EraseLocations(ctx, trackID)
+		if repo == serving {
+			deleted = n
+		}
+		return err
+	})
+	return deleted, err
+}
+
+// CountWaypoints returns the number of waypoints stored for a trip track.
+func (r *DualWriteTripTrackRepository) CountWaypoints(ctx context.Context, trackID uuid.UUID) (int, error) {
+	serving, _ := r.primary()
+	return serving.CountWaypoints(ctx, trackID)
+}
+
+// DownsampleWaypoints thins the older waypoints of a trip track.
+func (r *DualWriteTripTrackRepository) DownsampleWaypoints(ctx context.Context, trackID uuid.UUID, keepRecent, factor int) error {
+	return r.write(ctx, "downsample_waypoints", func(repo trackingDomain.TripTrackRepository) error {
+		return repo.DownsampleWaypoints(ctx, trackID, keepRecent, factor)
+	})
+}
+
+// EachWaypointBatch reads a trip track's waypoints in batches.
+func (r *DualWriteTripTrackRepository) EachWaypointBatch(ctx context.Context, trackID uuid.UUID, batchSize int, fn func([]trackingDomain.Waypoint) error) error {
+	serving, _ := r.primary()
+	return serving.EachWaypointBatch(ctx, trackID, batchSize, fn)
+}
+
+// AddWaypoint records a new GPS waypoint for a trip track.
+func (r *DualWriteTripTrackRepository) AddWaypoint(ctx context.Context, trackID uuid.UUID, waypoint trackingDomain.Waypoint) error {
+	return r.write(ctx, "add_waypoint", func(repo trackingDomain.TripTrackRepository) error {
+		return repo.AddWaypoint(ctx, trackID, waypoint)
+	})
+}
+
+// AdvanceDistance advances a trip's running distance, returning the serving backend's
+// result.
+func (r *DualWriteTripTrackRepository) AdvanceDistance(ctx context.Context, trackID uuid.UUID, waypoint trackingDomain.Waypoint) (float64, bool, error) {
+	serving, _ := r.primary()
+	var added float64
+	var advanced bool
+	err := r.write(ctx, "advance_distance", func(repo trackingDomain.TripTrackRepository) error {
+		km, ok, err := repo.AdvanceDistance(ctx, trackID, waypoint)
+		if repo == serving {
+			added, advanced = km, ok
+		}
+		return err
+	})
+	return added, advanced, err
+}
+
+// AddDistance adds to a trip's running distance.
+func (r *DualWriteTripTrackRepository) AddDistance(ctx context.Context, trackID uuid.UUID, km float64) error {
+	return r.write(ctx, "add_distance", func(repo trackingDomain.TripTrackRepository) error {
+		return repo.AddDistance(ctx, trackID, km)
+	})
+}
+
+// UpdateDeviceStatus records the runner's phone status for a trip.
+func (r *DualWriteTripTrackRepository) UpdateDeviceStatus(ctx context.Context, trackID uuid.UUID, status trackingDomain.DeviceStatus) error {
+	return r.write(ctx, "update_device_status", func(repo trackingDomain.TripTrackRepository) error {
+		return repo.UpdateDeviceStatus(ctx, trackID, status)
+	})
+}
+
+// RecordMovement records a trip's latest meaningful movement.
+func (r *DualWriteTripTrackRepository) RecordMovement(ctx context.Context, trackID uuid.UUID, m trackingDomain.Movement) error {
+	return r.write(ctx, "record_movement", func(repo trackingDomain.TripTrackRepository) error {
+		return repo.RecordMovement(ctx, trackID, m)
+	})
+}
+
+// MarkStalled marks a trip without recent movement as stalled, reporting the serving
+// store's result.
+func (r *DualWriteTripTrackRepository) MarkStalled(ctx context.Context, trackID uuid.UUID, at, movedBefore time.Time) (bool, error) {
+	serving, _ := r.primary()
+	var marked bool
+	err := r.write(ctx, "mark_stalled", func(repo trackingDomain.TripTrackRepository) error {
+		ok, err := repo.MarkStalled(ctx, trackID, at, movedBefore)
+		if repo == serving {
+			marked = ok
+		}
+		return err
+	})
+	return marked, err
+}
+
+// FindStallCandidates retrieves the active trips without recent movement not yet marked stalled.
+func (r *DualWriteTripTrackRepository) FindStallCandidates(ctx context.Context, movedBefore time.Time) ([]*trackingDomain.TripTrack, error) {
+	serving, _ := r.primary()
+	return serving.FindStallCandidates(ctx, movedBefore)
+}
+
+// FindStalled retrieves the active trips marked stalled.
+func (r *DualWriteTripTrackRepository) FindStalled(ctx context.Context) ([]*trackingDomain.TripTrack, error) {
+	serving, _ := r.primary()
+	return serving.FindStalled(ctx)
+}
+
+// FindCompletedSince retrieves trip tracks completed at or after since.
+func (r *DualWriteTripTrackRepository) FindCompletedSince(ctx context.Context, since time.Time, limit int) ([]*trackingDomain.TripTrack, error) {
+	serving, _ := r.primary()
+	return serving.FindCompletedSince(ctx, since, limit)
+}
+
+// GetFleetStats counts the trips in progress per region.
+func (r *DualWriteTripTrackRepository) GetFleetStats(ctx context.Context) ([]trackingDomain.RegionFleetStats, error) {
+	serving, _ := r.primary()
+	return serving.GetFleetStats(ctx)
+}
+
+// ResetDistance overwrites a trip's running distance and last position.
+func (r *DualWriteTripTrackRepository) ResetDistance(ctx context.Context, trackID uuid.UUID, km float64, last *trackingDomain.Waypoint) error {
+	return r.write(ctx, "reset_distance", func(repo trackingDomain.TripTrackRepository) error {
+		return repo.ResetDistance(ctx, trackID, km, last)
+	})
+}
+
+// BackfillWaypoints stores waypoints uploaded after the fact.
+func (r *DualWriteTripTrackRepository) BackfillWaypoints(ctx context.Context, trackID uuid.UUID, waypoints []trackingDomain.Waypoint) error {
+	return r.write(ctx, "backfill_waypoints", func(repo trackingDomain.TripTrackRepository) error {
+		return repo.BackfillWaypoints(ctx, trackID, waypoints)
+	})
+}
+
+// GetWaypoints retrieves all waypoints for a trip track ordered by time.
+func (r *DualWriteTripTrackRepository) GetWaypoints(ctx context.Context, trackID uuid.UUID) ([]trackingDomain.Waypoint, error) {
+	return r.readWaypoints(ctx, "get_waypoints", func(ctx context.Context, repo trackingDomain.TripTrackRepository) ([]trackingDomain.Waypoint, error) {
+		return repo.GetWaypoints(ctx, trackID)
+	})
+}
+
+// GetWaypointsBetween retrieves the waypoints in a time window.
+func (r *DualWriteTripTrackRepository) GetWaypointsBetween(ctx context.Context, trackID uuid.UUID, from, to time.Time) ([]trackingDomain.Waypoint, error) {
+	return r.readWaypoints(ctx, "get_waypoints_between", func(ctx context.Context, repo trackingDomain.TripTrackRepository) ([]trackingDomain.Waypoint, error) {
+		return repo.GetWaypointsBetween(ctx, trackID, from, to)
+	})
+}
+
+// ListWaypoints retrieves a page of a trip track's waypoints.
+func (r *DualWriteTripTrackRepository) ListWaypoints(ctx context.Context, trackID uuid.UUID, q trackingDomain.WaypointPageQuery) ([]trackingDomain.Waypoint, error) {
+	return r.readWaypoints(ctx, "list_waypoints", func(ctx context.Context, repo trackingDomain.TripTrackRepository) ([]trackingDomain.Waypoint, error) {
+		return repo.ListWaypoints(ctx, trackID, q)
+	})
+}
+
+// GetChunksInBounds retrieves the waypoint chunks intersecting a bounding box.
+func (r *DualWriteTripTrackRepository) GetChunksInBounds(ctx context.Context, trackID uuid.UUID, box trackingDomain.BoundingBox) ([]trackingDomain.WaypointChunk, error) {
+	serving, _ := r.primary()
+	return serving.GetChunksInBounds(ctx, trackID, box)
+}
+
+// GetRouteAsGeoJSON returns the trip route as a GeoJSON LineString.
+func (r *DualWriteTripTrackRepository) GetRouteAsGeoJSON(ctx context.Context, trackID uuid.UUID) (string, error) {
+	serving, _ := r.primary()
+	return serving.GetRouteAsGeoJSON(ctx, trackID)
+}
+
+// GetRouteLengthKm returns the length of the route in a time window.
+func (r *DualWriteTripTrackRepository) GetRouteLengthKm(ctx context.Context, trackID uuid.UUID, from, to time.Time) (float64, error) {
+	serving, _ := r.primary()
+	return serving.GetRouteLengthKm(ctx, trackID, from, to)
+}
+
+// GetWaypointStats aggregates a trip's waypoints.
+func (r *DualWriteTripTrackRepository) GetWaypointStats(ctx context.Context, trackID uuid.UUID, opts trackingDomain.WaypointStatsOptions) (*trackingDomain.WaypointStats, error) {
+	serving, _ := r.primary()
+	return serving.GetWaypointStats(ctx, trackID, opts)
+}
+
+// GetWaypointHeatmap bins the waypoints of all trips matching q into geohash cells.
+func (r *DualWriteTripTrackRepository) GetWaypointHeatmap(ctx context.Context, q trackingDomain.HeatmapQuery) ([]trackingDomain.HeatmapCell, error) {
+	serving, _ := r.primary()
+	return serving.GetWaypointHeatmap(ctx, q)
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// migrationMetrics counts storage migration outcomes per operation.
+type migrationMetrics struct {
+	mu     sync.Mutex
+	counts map[migrationKey]uint64
+}
+
+// migrationKey identifies one counter: a kind (mirror_write, shadow_read), an
+// operation and a result.
+type migrationKey struct {
+	kind, op, result string
+}
+
+func newMigrationMetrics() *migrationMetrics {
+	return &migrationMetrics{counts: make(map[migrationKey]uint64)}
+}
+
+func (m *migrationMetrics) inc(kind, op, result string) {
+	m.mu.Lock()
+	m.counts[migrationKey{kind, op, result}]++
+	m.mu.Unlock()
+}
+
+func (m *migrationMetrics) mirrorWriteSucceeded(op string) { m.inc("mirror_write", op, "ok") }
+func (m *migrationMetrics) mirrorWriteFailed(op string)    { m.inc("mirror_write", op, "error") }
+func (m *migrationMetrics) shadowMatched(op string)        { m.inc("shadow_read", op, "match") }
+func (m *migrationMetrics) shadowMismatched(op string)     { m.inc("shadow_read", op, "mismatch") }
+func (m *migrationMetrics) shadowFailed(op string)         { m.inc("shadow_read", op, "error") }
+func (m *migrationMetrics) shadowSkipped(op string)        { m.inc("shadow_read", op, "skipped") }
+
+// ServeHTTP writes the storage migration metrics in the Prometheus text exposition format.
+func (r *DualWriteTripTrackRepository) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	m := r.metrics
+	m.mu.Lock()
+	keys := make([]migrationKey, 0, len(m.counts))
+	counts := make(map[migrationKey]uint64, len(m.counts))
+	for k, n := range m.counts {
+		keys = append(keys, k)
+		counts[k] = n
+	}
+	m.mu.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.kind != b.kind {
+			return a.kind < b.kind
+		}
+		if a.op != b.op {
+			return a.op < b.op
+		}
+		return a.result < b.result
+	})
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP tracking_storage_migration_mode Active storage migration mode.")
+	fmt.Fprintln(w, "# TYPE tracking_storage_migration_mode gauge")
+	fmt.Fprintf(w, "tracking_storage_migration_mode{mode=%q} 1\n", r.mode)
+
+	for _, kind := range []string{"mirror_write", "shadow_read"} {
+		name := "tracking_storage_migration_" + kind + "s_total"
+		fmt.Fprintf(w, "# HELP %s Storage migration %s results by operation.\n", name, kind)
+		fmt.Fprintf(w, "# TYPE %s counter\n", name)
+		for _, k := range keys {
+			if k.kind == kind {
+				fmt.Fprintf(w, "%s{op=%q,result=%q} %d\n", name, k.op, k.result, counts[k])
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	erasureDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/erasure"
+)
+
+// ErasureRequestModel is the GORM model for the erasure_requests table.
+type ErasureRequestModel struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	RequestedBy *uuid.UUID `gorm:"type:uuid"`
+	Status      string     `gorm:"type:varchar(20);not null;index"`
+	Erased      string     `gorm:"type:jsonb;not null;default:'[]'"`
+	Deferred    string     `gorm:"type:jsonb;not null;default:'[]'"`
+	RequestedAt time.Time  `gorm:"type:timestamptz;not null"`
+	CompletedAt *time.Time `gorm:"type:timestamptz"`
+	UpdatedAt   time.Time  `gorm:"type:timestamptz;not null"`
+}
+
+// TableName sets the table name.
+func (ErasureRequestModel) TableName() string { return "erasure_requests" }
+
+// GormErasureRepository implements erasure.Repository using GORM.
+type GormErasureRepository struct {
+	db *gorm.DB
+}
+
+// NewGormErasureRepository creates a new GormErasureRepository.
+func NewGormErasureRepository(db *gorm.DB) *GormErasureRepository {
+	return &GormErasureRepository{db: db}
+}
+
+// Save persists a new erasure request.
+func (r *GormErasureRepository) Save(ctx context.Context, req *erasureDomain.Request) error {
+	model, err := toErasureModel(req)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+// Update persists the progress of an erasure request.
+func (r *GormErasureRepository) Update(ctx context.Context, req *erasureDomain.Request) error {
+	model, err := toErasureModel(req)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Save(&model).Error
+}
+
+// FindByID returns an erasure request by its ID.
+func (r *GormErasureRepository) FindByID(ctx context.Context, id uuid.UUID) (*erasureDomain.Request, error) {
+	var model ErasureRequestModel
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find erasure request: %w", err)
+	}
+	return toErasureDomain(&model)
+}
+
+// FindPending returns the pending erasure requests, oldest first.
+func (r *GormErasureRepository) FindPending(ctx context.Context) ([]*erasureDomain.Request, error) {
+	var models []ErasureRequestModel
+	if err := r.db.WithContext(ctx).
+		Where("status = ?", string(erasureDomain.StatusPending)).
+		Order("requested_at ASC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find pending erasure requests: %w", err)
+	}
+
+	requests := make([]*erasureDomain.Request, 0, len(models))
+	for i := range models {
+		req, err := toErasureDomain(&models[i])
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+func toErasureModel(r *erasureDomain.Request) (ErasureRequestModel, error) {
+	erased := r.Erased
+	if erased == nil {
+		erased = []erasureDomain.BookingErasure{}
+	}
+	erasedData, err := json.Marshal(erased)
+	if err != nil {
+		return ErasureRequestModel{}, fmt.Errorf("failed to marshal erased bookings: %w", err)
+	}
+	deferred := r.Deferred
+	if deferred == nil {
+		deferred = []erasureDomain.DeferredBooking{}
+	}
+	deferredData, err := json.Marshal(deferred)
+	if err != nil {
+		return ErasureRequestModel{}, fmt.Errorf("failed to marshal deferred bookings: %w", err)
+	}
+
+	return ErasureRequestModel{
+		ID:          r.ID,
+		UserID:      r.UserID,
+		RequestedBy: r.RequestedBy,
+		Status:      string(r.Status),
+		Erased:      string(erasedData),
+		Deferred:    string(deferredData),
+		RequestedAt: r.RequestedAt,
+		CompletedAt: r.CompletedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}, nil
+}
+
+func toErasureDomain(m *ErasureRequestModel) (*erasureDomain.Request, error) {
+	var erased []erasureDomain.BookingErasure
+	if err := json.Unmarshal([]byte(m.Erased), &erased); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal erased bookings: %w", err)
+	}
+	var deferred []erasureDomain.DeferredBooking
+	if err := json.Unmarshal([]byte(m.Deferred), &deferred); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal deferred bookings: %w", err)
+	}
+
+	return &erasureDomain.Request{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		RequestedBy: m.RequestedBy,
+		Status:      erasureDomain.Status(m.Status),
+		Erased:      erased,
+		Deferred:    deferred,
+		RequestedAt: m.RequestedAt,
+		CompletedAt: m.CompletedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	etaDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/eta"
+)
+
+// ETASubscriptionModel is the GORM model for the eta_subscriptions table.
+type ETASubscriptionModel struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	BookingID        uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_eta_subscriptions_booking_subscriber"`
+	Subscriber       string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_eta_subscriptions_booking_subscriber"`
+	ThresholdSeconds int        `gorm:"not null"`
+	CallbackURL      string     `gorm:"type:text;not null;default:''"`
+	LastNotifiedETA  *time.Time `gorm:"column:last_notified_eta;type:timestamptz"`
+	CreatedAt        time.Time  `gorm:"type:timestamptz;not null"`
+}
+
+// TableName sets the table name.
+func (ETASubscriptionModel) TableName() string { return "eta_subscriptions" }
+
+// GormETASubscriptionRepository implements eta.Repository using GORM.
+type GormETASubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewGormETASubscriptionRepository creates a new GormETASubscriptionRepository.
+func NewGormETASubscriptionRepository(db *gorm.DB) *GormETASubscriptionRepository {
+	return &GormETASubscriptionRepository{db: db}
+}
+
+// Save creates or replaces a subscription.
+func (r *GormETASubscriptionRepository) Save(ctx context.Context, s *etaDomain.Subscription) error {
+	model := ETASubscriptionModel{
+		ID:               s.ID(),
+		BookingID:        s.BookingID(),
+		Subscriber:       s.Subscriber(),
+		ThresholdSeconds: int(s.Threshold() / time.Second),
+		CallbackURL:      s.CallbackURL(),
+		LastNotifiedETA:  s.LastNotifiedETA(),
+		CreatedAt:        s.CreatedAt(),
+	}
+	if err := r.db.WithContext(ctx).Save(&model).Error; err != nil {
+		return fmt.Errorf("failed to save eta subscription: %w", err)
+	}
+	return nil
+}
+
+// FindByBookingID returns a booking's subscriptions, oldest first.
+func (r *GormETASubscriptionRepository) FindByBookingID(ctx context.Context, bookingID uuid.UUID) ([]*etaDomain.Subscription, error) {
+	var models []ETASubscriptionModel
+	if err := r.db.WithContext(ctx).Where("booking_id = ?", bookingID).Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find eta subscriptions: %w", err)
+	}
+
+	subs := make([]*etaDomain.Subscription, 0, len(models))
+	for _, m := range models {
+		subs = append(subs, etaDomain.Reconstruct(
+			m.ID, m.BookingID, m.Subscriber,
+			time.Duration(m.ThresholdSeconds)*time.Second,
+			m.CallbackURL, m.LastNotifiedETA, m.CreatedAt,
+		))
+	}
+	return subs, nil
+}
+
+// Delete removes a booking's subscription.
+func (r *GormETASubscriptionRepository) Delete(ctx context.Context, bookingID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND booking_id = ?", id, bookingID).Delete(&ETASubscriptionModel{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete eta subscription: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// UpdateLastNotified records the estimated arrival a subscriber was last notified of.
+func (r *GormETASubscriptionRepository) UpdateLastNotified(ctx context.Context, id uuid.UUID, arrival time.Time) error {
+	err := r.db.WithContext(ctx).Model(&ETASubscriptionModel{}).Where("id = ?", id).Update("last_notified_eta", arrival).Error
+	if err != nil {
+		return fmt.Errorf("failed to update eta subscription: %w", err)
+	}
+	return nil
+}
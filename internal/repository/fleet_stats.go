@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// fleetStatsRow is a row of the fleet stats aggregate.
+type fleetStatsRow struct {
+	Region        string
+	ActiveTracks  int
+	PausedTracks  int
+	ActiveRunners int
+}
+
+// FindCompletedSince retrieves up to limit trip tracks completed at or after since,
+// oldest completion first.
+func (r *GORMTripTrackRepository) FindCompletedSince(ctx context.Context, since time.Time, limit int) ([]*trackingDomain.TripTrack, error) {
+	var models []TripTrackModel
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND completed_at >= ?", string(trackingDomain.TrackingCompleted), since).
+		Order("completed_at ASC, id ASC").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find completed trips: %w", err)
+	}
+
+	tracks := make([]*trackingDomain.TripTrack, len(models))
+	for i := range models {
+		tracks[i] = toDomain(&models[i])
+	}
+	return tracks, nil
+}
+
+// GetFleetStats counts the trips in progress per region, ordered by region.
+func (r *GORMTripTrackRepository) GetFleetStats(ctx context.Context) ([]trackingDomain.RegionFleetStats, error) {
+	var rows []fleetStatsRow
+	if err := r.db.WithContext(ctx).
+		Model(&TripTrackModel{}).
+		Select(`region,
+			COUNT(*) FILTER (WHERE status = ?) AS active_tracks,
+			COUNT(*) FILTER (WHERE status = ?) AS paused_tracks,
+			COUNT(DISTINCT runner_id) AS active_runners`,
+			string(trackingDomain.TrackingActive), string(trackingDomain.TrackingPaused)).
+		Where("status IN ?", inProgressStatuses).
+		Group("region").
+		Order("region ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute fleet stats: %w", err)
+	}
+
+	stats := make([]trackingDomain.RegionFleetStats, len(rows))
+	for i, row := range rows {
+		stats[i] = trackingDomain.RegionFleetStats(row)
+	}
+	return stats, nil
+}
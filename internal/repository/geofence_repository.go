@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	geofenceDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/geofence"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GeofenceModel is the GORM model for the geofences table.
+type GeofenceModel struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey"`
+	BookingID       uuid.UUID `gorm:"type:uuid;not null;index"`
+	Kind            string    `gorm:"type:varchar(20);not null"`
+	Shape           string    `gorm:"type:varchar(20);not null"`
+	CenterLatitude  float64   `gorm:"type:double precision"`
+	CenterLongitude float64   `gorm:"type:double precision"`
+	RadiusMeters    float64   `gorm:"type:double precision"`
+	Vertices        string    `gorm:"type:jsonb;not null;default:'[]'"`
+	Active          bool      `gorm:"not null;default:true"`
+	Inside          bool      `gorm:"not null;default:false"`
+	CreatedAt       time.Time `gorm:"type:timestamptz;not null"`
+	UpdatedAt       time.Time `gorm:"type:timestamptz;not null"`
+}
+
+// TableName sets the table name.
+func (GeofenceModel) TableName() string { return "geofences" }
+
+// GormGeofenceRepository implements GeofenceRepository using GORM.
+type GormGeofenceRepository struct {
+	db *gorm.DB
+}
+
+// NewGormGeofenceRepository creates a new GormGeofenceRepository.
+func NewGormGeofenceRepository(db *gorm.DB) *GormGeofenceRepository {
+	return &GormGeofenceRepository{db: db}
+}
+
+// Save persists a new geofence.
+func (r *GormGeofenceRepository) Save(ctx context.Context, g *geofenceDomain.Geofence) error {
+	model, err := toGeofenceModel(g)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+// Update persists changes to an existing geofence.
+func (r *GormGeofenceRepository) Update(ctx context.Context, g *geofenceDomain.Geofence) error {
+	model, err := toGeofenceModel(g)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Save(&model).Error
+}
+
+// FindByID returns a geofence by its ID.
+func (r *GormGeofenceRepository) FindByID(ctx context.Context, id uuid.UUID) (*geofenceDomain.Geofence, error) {
+	var model GeofenceModel
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find geofence: %w", err)
+	}
+	return toGeofenceDomain(&model)
+}
+
+// FindByBookingID returns all geofences for a booking.
+func (r *GormGeofenceRepository) FindByBookingID(ctx context.Context, bookingID uuid.UUID) ([]*geofenceDomain.Geofence, error) {
+	return r.find(ctx, r.db.WithContext(ctx).Where("booking_id = ?", bookingID))
+}
+
+// FindActiveByBookingID returns the active geofences for a booking.
+func (r *GormGeofenceRepository) FindActiveByBookingID(ctx context.Context, bookingID uuid.UUID) ([]*geofenceDomain.Geofence, error) {
+	return r.find(ctx, r.db.WithContext(ctx).Where("booking_id = ? AND active = ?", bookingID, true))
+}
+
+func (r *GormGeofenceRepository) find(ctx context.Context, query *gorm.DB) ([]*geofenceDomain.Geofence, error) {
+	var models []GeofenceModel
+	if err := query.Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find geofences: %w", err)
+	}
+
+	fences := make([]*geofenceDomain.Geofence, 0, len(models))
+	for i := range models {
+		g, err := toGeofenceDomain(&models[i])
+		if err != nil {
+			return nil, err
+		}
+		fences = append(fences, g)
+	}
+	return fences, nil
+}
+
+func toGeofenceModel(g *geofenceDomain.Geofence) (GeofenceModel, error) {
+	vertices := g.Vertices()
+	if vertices == nil {
+		vertices = []geofenceDomain.Point{}
+	}
+	data, err := json.Marshal(vertices)
+	if err != nil {
+		return GeofenceModel{}, fmt.Errorf("failed to marshal geofence vertices: %w", err)
+	}
+
+	return GeofenceModel{
+		ID:              g.ID(),
+		BookingID:       g.BookingID(),
+		Kind:            string(g.Kind()),
+		Shape:           string(g.Shape()),
+		CenterLatitude:  g.Center().Latitude,
+		CenterLongitude: g.Center().Longitude,
+		RadiusMeters:    g.RadiusMeters(),
+		Vertices:        string(data),
+		Active:          g.IsActive(),
+		Inside:          g.IsInside(),
+		CreatedAt:       g.CreatedAt(),
+		UpdatedAt:       g.UpdatedAt(),
+	}, nil
+}
+
+func toGeofenceDomain(m *GeofenceModel) (*geofenceDomain.Geofence, error) {
+	var vertices []geofenceDomain.Point
+	if err := json.Unmarshal([]byte(m.Vertices), &vertices); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal geofence vertices: %w", err)
+	}
+
+	return geofenceDomain.Reconstruct(
+		m.ID,
+		m.BookingID,
+		geofenceDomain.Kind(m.Kind),
+		geofenceDomain.Shape(m.Shape),
+		geofenceDomain.Point{Latitude: m.CenterLatitude, Longitude: m.CenterLongitude},
+		m.RadiusMeters,
+		vertices,
+		m.Active,
+		m.Inside,
+		m.CreatedAt,
+		m.UpdatedAt,
+	), nil
+}
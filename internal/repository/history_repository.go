@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	historyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/history"
+)
+
+// TrackEventModel is the GORM model for the track_events table.
+type TrackEventModel struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	TripTrackID uuid.UUID  `gorm:"type:uuid;not null"`
+	BookingID   uuid.UUID  `gorm:"type:uuid;not null;index:idx_track_events_booking"`
+	Kind        string     `gorm:"type:varchar(32);not null"`
+	Status      string     `gorm:"type:varchar(20);not null"`
+	ActorType   string     `gorm:"type:varchar(20);not null"`
+	ActorID     *uuid.UUID `gorm:"type:uuid"`
+	Reason      string     `gorm:"type:text;not null;default:''"`
+	OccurredAt  time.Time  `gorm:"type:timestamptz;not null;index:idx_track_events_booking"`
+}
+
+// TableName sets the table name.
+func (TrackEventModel) TableName() string { return "track_events" }
+
+// GormHistoryRepository implements history.Repository using GORM.
+type GormHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewGormHistoryRepository creates a new GormHistoryRepository.
+func NewGormHistoryRepository(db *gorm.DB) *GormHistoryRepository {
+	return &GormHistoryRepository{db: db}
+}
+
+// Append persists a new lifecycle event.
+func (r *GormHistoryRepository) Append(ctx context.Context, e *historyDomain.TrackEvent) error {
+	model := TrackEventModel{
+		ID:          e.ID,
+		TripTrackID: e.TrackID,
+		BookingID:   e.BookingID,
+		Kind:        string(e.Kind),
+		Status:      e.Status,
+		ActorType:   e.Actor.Type,
+		ActorID:     e.Actor.ID,
+		Reason:      e.Reason,
+		OccurredAt:  e.OccurredAt,
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+// FindByBookingID returns a booking's lifecycle events, oldest first.
+func (r *GormHistoryRepository) FindByBookingID(ctx context.Context, bookingID uuid.UUID) ([]*historyDomain.TrackEvent, error) {
+	var models []TrackEventModel
+	if err := r.db.WithContext(ctx).
+		Where("booking_id = ?", bookingID).
+		Order("occurred_at ASC").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*historyDomain.TrackEvent, len(models))
+	for i, m := range models {
+		result[i] = &historyDomain.TrackEvent{
+			ID:         m.ID,
+			TrackID:    m.TripTrackID,
+			BookingID:  m.BookingID,
+			Kind:       historyDomain.Kind(m.Kind),
+			Status:     m.Status,
+			Actor:      historyDomain.Actor{Type: m.ActorType, ID: m.ActorID},
+			Reason:     m.Reason,
+			OccurredAt: m.OccurredAt,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	inboxDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/inbox"
+)
+
+// InboxEntryModel is the GORM model for the inbox_entries table.
+type InboxEntryModel struct {
+	Cursor    int64     `gorm:"column:cursor;primaryKey;autoIncrement"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_inbox_entries_user_message,priority:1"`
+	BookingID uuid.UUID `gorm:"type:uuid;not null"`
+	Kind      string    `gorm:"type:varchar(20);not null"`
+	Type      string    `gorm:"type:varchar(64);not null"`
+	MessageID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_inbox_entries_user_message,priority:2"`
+	Data      string    `gorm:"type:jsonb;not null"`
+	CreatedAt time.Time `gorm:"not null;index"`
+}
+
+// TableName sets the table name.
+func (InboxEntryModel) TableName() string { return "inbox_entries" }
+
+// GormInboxRepository implements inbox.Repository using GORM.
+type GormInboxRepository struct {
+	db *gorm.DB
+}
+
+// NewGormInboxRepository creates a new GormInboxRepository.
+func NewGormInboxRepository(db *gorm.DB) *GormInboxRepository {
+	return &GormInboxRepository{db: db}
+}
+
+// Append inserts entries, skipping messages already in a user's inbox.
+func (r *GormInboxRepository) Append(ctx context.Context, entries []inboxDomain.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	models := make([]InboxEntryModel, len(entries))
+	for i, e := range entries {
+		models[i] = InboxEntryModel{
+			UserID:    e.UserID,
+			BookingID: e.BookingID,
+			Kind:      string(e.Kind),
+			Type:      e.Type,
+			MessageID: e.MessageID,
+			Data:      string(e.Data),
+			CreatedAt: e.CreatedAt,
+		}
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models).Error
+}
+
+// ListAfter returns a user's entries after a cursor, oldest first.
+func (r *GormInboxRepository) ListAfter(ctx context.Context, userID uuid.UUID, cursor int64, limit int) ([]inboxDomain.Entry, error) {
+	var models []InboxEntryModel
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND cursor > ?", userID, cursor).
+		Order("cursor ASC").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]inboxDomain.Entry, len(models))
+	for i, m := range models {
+		entries[i] = inboxDomain.Entry{
+			Cursor:    m.Cursor,
+			UserID:    m.UserID,
+			BookingID: m.BookingID,
+			Kind:      inboxDomain.Kind(m.Kind),
+			Type:      m.Type,
+			MessageID: m.MessageID,
+			Data:      []byte(m.Data),
+			CreatedAt: m.CreatedAt,
+		}
+	}
+	return entries, nil
+}
+
+// DeleteUpTo deletes a user's entries up to and including a cursor.
+func (r *GormInboxRepository) DeleteUpTo(ctx context.Context, userID uuid.UUID, cursor int64) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND cursor <= ?", userID, cursor).
+		Delete(&InboxEntryModel{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteCreatedBefore deletes the entries recorded before a point in time.
+func (r *GormInboxRepository) DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("created_at < ?", before).
+		Delete(&InboxEntryModel{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,1148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	alertDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/alert"
+	anomalyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/anomaly"
+	certificateDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/certificate"
+	chatDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/chat"
+	erasureDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/erasure"
+	etaDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/eta"
+	geofenceDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/geofence"
+	historyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/history"
+	inboxDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/inbox"
+	participantDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/participant"
+	privacyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/privacy"
+	shareDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/share"
+	supportDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/support"
+	telemetryDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/telemetry"
+	temperatureDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/temperature"
+	webhookDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/webhook"
+)
+
+// The in-memory repositories below back standalone mode. Each one mirrors the
+// semantics of its GORM counterpart, storing copies so callers cannot change stored
+// state without saving it.
+
+// MemoryChatRepository implements ChatRepository in memory.
+type MemoryChatRepository struct {
+	mu       sync.Mutex
+	messages []ChatMessageModel
+}
+
+// NewMemoryChatRepository creates an empty MemoryChatRepository.
+func NewMemoryChatRepository() *MemoryChatRepository {
+	return &MemoryChatRepository{}
+}
+
+// Save persists a new chat message.
+func (r *MemoryChatRepository) Save(_ context.Context, msg *chatDomain.ChatMessage) error {
+	model, err := toChatModel(msg)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, model)
+	sort.SliceStable(r.messages, func(i, j int) bool { return r.messages[i].CreatedAt.Before(r.messages[j].CreatedAt) })
+	return nil
+}
+
+// ReassignBooking moves all messages of one booking to another.
+func (r *MemoryChatRepository) ReassignBooking(_ context.Context, fromBookingID, toBookingID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	moved := 0
+	for i := range r.messages {
+		if r.messages[i].BookingID == fromBookingID {
+			r.messages[i].BookingID = toBookingID
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+// EraseByBookingID clears the content and attachments of a booking's messages not yet
+// erased and marks them erased.
+func (r *MemoryChatRepository) EraseByBookingID(_ context.Context, bookingID uuid.UUID, at time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	erased := 0
+	for i := range r.messages {
+		if r.messages[i].BookingID == bookingID && r.messages[i].ErasedAt == nil {
+			erasedAt := at
+			r.messages[i].Content = ""
+			r.messages[i].Attachments = "[]"
+			r.messages[i].ErasedAt = &erasedAt
+			erased++
+		}
+	}
+	return erased, nil
+}
+
+// CountUnerasedByBookingID returns how many of a booking's messages are not erased.
+func (r *MemoryChatRepository) CountUnerasedByBookingID(_ context.Context, bookingID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, m := range r.messages {
+		if m.BookingID == bookingID && m.ErasedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// FindByBookingID returns paginated chat messages for a booking.
+func (r *MemoryChatRepository) FindByBookingID(_ context.Context, bookingID uuid.UUID, limit, offset int) ([]*chatDomain.ChatMessage, int64, error) {
+	r.mu.Lock()
+	var models []ChatMessageModel
+	for _, m := range r.messages {
+		if m.BookingID == bookingID {
+			models = append(models, m)
+		}
+	}
+	r.mu.Unlock()
+
+	total := int64(len(models))
+	models = models[min(offset, len(models)):]
+	if limit >= 0 && limit < len(models) {
+		models = models[:limit]
+	}
+	messages := make([]*chatDomain.ChatMessage, len(models))
+	for i := range models {
+		msg, err := toChatDomain(&models[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		messages[i] = msg
+	}
+	return messages, total, nil
+}
+
+// MemoryGeofenceRepository implements GeofenceRepository in memory.
+type MemoryGeofenceRepository struct {
+	mu        sync.Mutex
+	geofences []GeofenceModel
+}
+
+// NewMemoryGeofenceRepository creates an empty MemoryGeofenceRepository.
+func NewMemoryGeofenceRepository() *MemoryGeofenceRepository {
+	return &MemoryGeofenceRepository{}
+}
+
+// Save persists a new geofence.
+func (r *MemoryGeofenceRepository) Save(ctx context.Context, g *geofenceDomain.Geofence) error {
+	return r.Update(ctx, g)
+}
+
+// Update persists changes to an existing geofence.
+func (r *MemoryGeofenceRepository) Update(_ context.Context, g *geofenceDomain.Geofence) error {
+	model, err := toGeofenceModel(g)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.geofences {
+		if r.geofences[i].ID == model.ID {
+			r.geofences[i] = model
+			return nil
+		}
+	}
+	r.geofences = append(r.geofences, model)
+	return nil
+}
+
+// FindByID returns a geofence by ID.
+func (r *MemoryGeofenceRepository) FindByID(_ context.Context, id uuid.UUID) (*geofenceDomain.Geofence, error) {
+	found, err := r.find(func(m *GeofenceModel) bool { return m.ID == id })
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return found[0], nil
+}
+
+// FindByBookingID returns all geofences of a booking.
+func (r *MemoryGeofenceRepository) FindByBookingID(_ context.Context, bookingID uuid.UUID) ([]*geofenceDomain.Geofence, error) {
+	return r.find(func(m *GeofenceModel) bool { return m.BookingID == bookingID })
+}
+
+// FindActiveByBookingID returns the active geofences of a booking.
+func (r *MemoryGeofenceRepository) FindActiveByBookingID(_ context.Context, bookingID uuid.UUID) ([]*geofenceDomain.Geofence, error) {
+	return r.find(func(m *GeofenceModel) bool { return m.BookingID == bookingID && m.Active })
+}
+
+// find returns the geofences matching match, oldest first.
+func (r *MemoryGeofenceRepository) find(match func(*GeofenceModel) bool) ([]*geofenceDomain.Geofence, error) {
+	r.mu.Lock()
+	var models []GeofenceModel
+	for i := range r.geofences {
+		if match(&r.geofences[i]) {
+			models = append(models, r.geofences[i])
+		}
+	}
+	r.mu.Unlock()
+
+	sort.SliceStable(models, func(i, j int) bool { return models[i].CreatedAt.Before(models[j].CreatedAt) })
+	geofences := make([]*geofenceDomain.Geofence, len(models))
+	for i := range models {
+		g, err := toGeofenceDomain(&models[i])
+		if err != nil {
+			return nil, err
+		}
+		geofences[i] = g
+	}
+	return geofences, nil
+}
+
+// MemorySharedTripRepository implements SharedTripRepository in memory.
+type MemorySharedTripRepository struct {
+	mu    sync.Mutex
+	trips []SharedTripModel
+}
+
+// NewMemorySharedTripRepository creates an empty MemorySharedTripRepository.
+func NewMemorySharedTripRepository() *MemorySharedTripRepository {
+	return &MemorySharedTripRepository{}
+}
+
+// Save persists a new shared trip.
+func (r *MemorySharedTripRepository) Save(_ context.Context, st *shareDomain.SharedTrip) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trips = append(r.trips, toShareModel(st))
+	return nil
+}
+
+// FindByToken returns a shared trip by its token.
+func (r *MemorySharedTripRepository) FindByToken(_ context.Context, token string) (*shareDomain.SharedTrip, error) {
+	found := r.find(func(m *SharedTripModel) bool { return m.ShareToken == token })
+	if len(found) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return found[0], nil
+}
+
+// FindByBookingID returns the newest shared trip of a booking.
+func (r *MemorySharedTripRepository) FindByBookingID(_ context.Context, bookingID uuid.UUID) (*shareDomain.SharedTrip, error) {
+	found := r.find(func(m *SharedTripModel) bool { return m.BookingID == bookingID })
+	if len(found) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return found[0], nil
+}
+
+// FindActiveByBookingID returns the unrevoked, unexpired links of a booking, newest first.
+func (r *MemorySharedTripRepository) FindActiveByBookingID(_ context.Context, bookingID uuid.UUID) ([]*shareDomain.SharedTrip, error) {
+	now := time.Now().UTC()
+	return r.find(func(m *SharedTripModel) bool {
+		return m.BookingID == bookingID && m.RevokedAt == nil && m.ExpiresAt.After(now)
+	}), nil
+}
+
+// find returns the shared trips matching match, newest first.
+func (r *MemorySharedTripRepository) find(match func(*SharedTripModel) bool) []*shareDomain.SharedTrip {
+	r.mu.Lock()
+	var models []SharedTripModel
+	for i := range r.trips {
+		if match(&r.trips[i]) {
+			models = append(models, r.trips[i])
+		}
+	}
+	r.mu.Unlock()
+
+	sort.SliceStable(models, func(i, j int) bool { return models[i].CreatedAt.After(models[j].CreatedAt) })
+	trips := make([]*shareDomain.SharedTrip, len(models))
+	for i := range models {
+		trips[i] = toShareDomain(&models[i])
+	}
+	return trips
+}
+
+// Revoke marks a booking's unrevoked links, or only shareID if given, as revoked.
+func (r *MemorySharedTripRepository) Revoke(_ context.Context, bookingID uuid.UUID, shareID *uuid.UUID, at time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	revoked := 0
+	for i := range r.trips {
+		m := &r.trips[i]
+		if m.BookingID != bookingID || m.RevokedAt != nil || (shareID != nil && m.ID != *shareID) {
+			continue
+		}
+		revokedAt := at
+		m.RevokedAt = &revokedAt
+		revoked++
+	}
+	return revoked, nil
+}
+
+// ReassignBooking moves all links of one booking to another, keeping their tokens.
+func (r *MemorySharedTripRepository) ReassignBooking(_ context.Context, fromBookingID, toBookingID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	moved := 0
+	for i := range r.trips {
+		if r.trips[i].BookingID == fromBookingID {
+			r.trips[i].BookingID = toBookingID
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+// DeleteByBookingID removes all links of a booking.
+func (r *MemorySharedTripRepository) DeleteByBookingID(_ context.Context, bookingID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.trips[:0]
+	for _, t := range r.trips {
+		if t.BookingID != bookingID {
+			kept = append(kept, t)
+		}
+	}
+	deleted := len(r.trips) - len(kept)
+	r.trips = kept
+	return deleted, nil
+}
+
+// CountByBookingID returns how many links a booking has, revoked or not.
+func (r *MemorySharedTripRepository) CountByBookingID(_ context.Context, bookingID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, t := range r.trips {
+		if t.BookingID == bookingID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// RecordView counts one view of a link within its limits, expiring it when the view
+// limit is reached.
+func (r *MemorySharedTripRepository) RecordView(_ context.Context, id uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now().UTC()
+	for i := range r.trips {
+		m := &r.trips[i]
+		if m.ID != id {
+			continue
+		}
+		if m.RevokedAt != nil || !m.ExpiresAt.After(now) || (m.MaxViews > 0 && m.ViewCount >= m.MaxViews) {
+			return false, nil
+		}
+		m.ViewCount++
+		if m.MaxViews > 0 && m.ViewCount >= m.MaxViews {
+			m.ExpiresAt = now
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// MemoryETASubscriptionRepository implements eta.Repository in memory.
+type MemoryETASubscriptionRepository struct {
+	mu            sync.Mutex
+	subscriptions []ETASubscriptionModel
+}
+
+// NewMemoryETASubscriptionRepository creates an empty MemoryETASubscriptionRepository.
+func NewMemoryETASubscriptionRepository() *MemoryETASubscriptionRepository {
+	return &MemoryETASubscriptionRepository{}
+}
+
+// Save creates or replaces a subscription.
+func (r *MemoryETASubscriptionRepository) Save(_ context.Context, s *etaDomain.Subscription) error {
+	model := ETASubscriptionModel{
+		ID:               s.ID(),
+		BookingID:        s.BookingID(),
+		Subscriber:       s.Subscriber(),
+		ThresholdSeconds: int(s.Threshold() / time.Second),
+		CallbackURL:      s.CallbackURL(),
+		LastNotifiedETA:  s.LastNotifiedETA(),
+		CreatedAt:        s.CreatedAt(),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.subscriptions {
+		existing := r.subscriptions[i]
+		if existing.ID == model.ID {
+			r.subscriptions[i] = model
+			return nil
+		}
+		if existing.BookingID == model.BookingID && existing.Subscriber == model.Subscriber {
+			return fmt.Errorf("failed to save eta subscription: %s already subscribed to booking %s", model.Subscriber, model.BookingID)
+		}
+	}
+	r.subscriptions = append(r.subscriptions, model)
+	return nil
+}
+
+// FindByBookingID returns a booking's subscriptions, oldest first.
+func (r *MemoryETASubscriptionRepository) FindByBookingID(_ context.Context, bookingID uuid.UUID) ([]*etaDomain.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	subs := []*etaDomain.Subscription{}
+	for _, m := range r.subscriptions {
+		if m.BookingID == bookingID {
+			subs = append(subs, etaDomain.Reconstruct(
+				m.ID, m.BookingID, m.Subscriber,
+				time.Duration(m.ThresholdSeconds)*time.Second,
+				m.CallbackURL, m.LastNotifiedETA, m.CreatedAt,
+			))
+		}
+	}
+	sort.SliceStable(subs, func(i, j int) bool { return subs[i].CreatedAt().Before(subs[j].CreatedAt()) })
+	return subs, nil
+}
+
+// Delete removes a booking's subscription.
+func (r *MemoryETASubscriptionRepository) Delete(_ context.Context, bookingID, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, m := range r.subscriptions {
+		if m.ID == id && m.BookingID == bookingID {
+			r.subscriptions = append(r.subscriptions[:i], r.subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+// UpdateLastNotified records the estimated arrival a subscriber was last notified of.
+func (r *MemoryETASubscriptionRepository) UpdateLastNotified(_ context.Context, id uuid.UUID, arrival time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.subscriptions {
+		if r.subscriptions[i].ID == id {
+			r.subscriptions[i].LastNotifiedETA = &arrival
+		}
+	}
+	return nil
+}
+
+// MemoryParticipantRepository implements participant.Repository in memory.
+type MemoryParticipantRepository struct {
+	mu           sync.Mutex
+	participants map[uuid.UUID]participantDomain.BookingParticipants
+}
+
+// NewMemoryParticipantRepository creates an empty MemoryParticipantRepository.
+func NewMemoryParticipantRepository() *MemoryParticipantRepository {
+	return &MemoryParticipantRepository{participants: make(map[uuid.UUID]participantDomain.BookingParticipants)}
+}
+
+// Upsert inserts or updates a booking's participants, only overwriting known IDs.
+func (r *MemoryParticipantRepository) Upsert(_ context.Context, p participantDomain.BookingParticipants) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.participants[p.BookingID]
+	stored.BookingID = p.BookingID
+	if p.OwnerID != uuid.Nil {
+		stored.OwnerID = p.OwnerID
+	}
+	if p.RunnerID != uuid.Nil {
+		stored.RunnerID = p.RunnerID
+	}
+	if p.PetSpecies != "" {
+		stored.PetSpecies = p.PetSpecies
+	}
+	stored.UpdatedAt = time.Now().UTC()
+	r.participants[p.BookingID] = stored
+	return nil
+}
+
+// FindByBookingID returns the participants of a booking.
+func (r *MemoryParticipantRepository) FindByBookingID(_ context.Context, bookingID uuid.UUID) (*participantDomain.BookingParticipants, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.participants[bookingID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &p, nil
+}
+
+// FindByUserID returns the participants of every booking the user owns or runs.
+func (r *MemoryParticipantRepository) FindByUserID(_ context.Context, userID uuid.UUID) ([]participantDomain.BookingParticipants, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := []participantDomain.BookingParticipants{}
+	for _, p := range r.participants {
+		if p.Includes(userID) {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// MemoryTemperatureThresholdRepository implements temperature.Repository in memory.
+type MemoryTemperatureThresholdRepository struct {
+	mu         sync.Mutex
+	thresholds map[string]temperatureDomain.Threshold
+}
+
+// NewMemoryTemperatureThresholdRepository creates an empty MemoryTemperatureThresholdRepository.
+func NewMemoryTemperatureThresholdRepository() *MemoryTemperatureThresholdRepository {
+	return &MemoryTemperatureThresholdRepository{thresholds: make(map[string]temperatureDomain.Threshold)}
+}
+
+// List returns all thresholds ordered by species.
+func (r *MemoryTemperatureThresholdRepository) List(_ context.Context) ([]*temperatureDomain.Threshold, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	thresholds := make([]*temperatureDomain.Threshold, 0, len(r.thresholds))
+	for _, t := range r.thresholds {
+		t := t
+		thresholds = append(thresholds, &t)
+	}
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i].Species < thresholds[j].Species })
+	return thresholds, nil
+}
+
+// FindBySpecies returns the threshold of a species.
+func (r *MemoryTemperatureThresholdRepository) FindBySpecies(_ context.Context, species string) (*temperatureDomain.Threshold, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.thresholds[species]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &t, nil
+}
+
+// Upsert creates or replaces the threshold of a species.
+func (r *MemoryTemperatureThresholdRepository) Upsert(_ context.Context, t *temperatureDomain.Threshold) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.thresholds[t.Species] = *t
+	return nil
+}
+
+// Delete removes the threshold of a species.
+func (r *MemoryTemperatureThresholdRepository) Delete(_ context.Context, species string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.thresholds[species]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.thresholds, species)
+	return nil
+}
+
+// MemoryInboxRepository implements inbox.Repository in memory.
+type MemoryInboxRepository struct {
+	mu      sync.Mutex
+	entries []inboxDomain.Entry
+	cursor  int64
+}
+
+// NewMemoryInboxRepository creates an empty MemoryInboxRepository.
+func NewMemoryInboxRepository() *MemoryInboxRepository {
+	return &MemoryInboxRepository{}
+}
+
+// Append inserts entries, skipping messages already in a user's inbox.
+func (r *MemoryInboxRepository) Append(_ context.Context, entries []inboxDomain.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range entries {
+		duplicate := false
+		for _, stored := range r.entries {
+			duplicate = duplicate || (stored.UserID == e.UserID && stored.MessageID == e.MessageID)
+		}
+		if duplicate {
+			continue
+		}
+		r.cursor++
+		e.Cursor = r.cursor
+		r.entries = append(r.entries, e)
+	}
+	return nil
+}
+
+// ListAfter returns a user's entries after a cursor, oldest first.
+func (r *MemoryInboxRepository) ListAfter(_ context.Context, userID uuid.UUID, cursor int64, limit int) ([]inboxDomain.Entry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := []inboxDomain.Entry{}
+	for _, e := range r.entries {
+		if len(entries) == limit {
+			break
+		}
+		if e.UserID == userID && e.Cursor > cursor {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// DeleteUpTo deletes a user's entries up to and including a cursor.
+func (r *MemoryInboxRepository) DeleteUpTo(_ context.Context, userID uuid.UUID, cursor int64) (int64, error) {
+	return r.delete(func(e *inboxDomain.Entry) bool { return e.UserID == userID && e.Cursor <= cursor }), nil
+}
+
+// DeleteCreatedBefore deletes the entries recorded before a point in time.
+func (r *MemoryInboxRepository) DeleteCreatedBefore(_ context.Context, before time.Time) (int64, error) {
+	return r.delete(func(e *inboxDomain.Entry) bool { return e.CreatedAt.Before(before) }), nil
+}
+
+// delete removes the entries matching match and returns how many were removed.
+func (r *MemoryInboxRepository) delete(match func(*inboxDomain.Entry) bool) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.entries[:0]
+	for i := range r.entries {
+		if !match(&r.entries[i]) {
+			kept = append(kept, r.entries[i])
+		}
+	}
+	deleted := int64(len(r.entries) - len(kept))
+	r.entries = kept
+	return deleted
+}
+
+// MemoryAnomalyRepository implements anomaly.Repository in memory.
+type MemoryAnomalyRepository struct {
+	mu        sync.Mutex
+	anomalies []anomalyDomain.Anomaly
+}
+
+// NewMemoryAnomalyRepository creates an empty MemoryAnomalyRepository.
+func NewMemoryAnomalyRepository() *MemoryAnomalyRepository {
+	return &MemoryAnomalyRepository{}
+}
+
+// Save persists a detected anomaly.
+func (r *MemoryAnomalyRepository) Save(_ context.Context, a *anomalyDomain.Anomaly) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.anomalies = append(r.anomalies, *a)
+	return nil
+}
+
+// FindByTrackID returns a trip's anomalies, oldest first.
+func (r *MemoryAnomalyRepository) FindByTrackID(_ context.Context, trackID uuid.UUID) ([]*anomalyDomain.Anomaly, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	anomalies := []*anomalyDomain.Anomaly{}
+	for _, a := range r.anomalies {
+		if a.TrackID == trackID {
+			a := a
+			anomalies = append(anomalies, &a)
+		}
+	}
+	sort.SliceStable(anomalies, func(i, j int) bool { return anomalies[i].DetectedAt.Before(anomalies[j].DetectedAt) })
+	return anomalies, nil
+}
+
+// MemoryTrackAlertRepository implements alert.Repository in memory.
+type MemoryTrackAlertRepository struct {
+	mu     sync.Mutex
+	alerts []alertDomain.TrackAlert
+}
+
+// NewMemoryTrackAlertRepository creates an empty MemoryTrackAlertRepository.
+func NewMemoryTrackAlertRepository() *MemoryTrackAlertRepository {
+	return &MemoryTrackAlertRepository{}
+}
+
+// Save persists a new track alert.
+func (r *MemoryTrackAlertRepository) Save(_ context.Context, a *alertDomain.TrackAlert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, *a)
+	return nil
+}
+
+// FindByTrackID returns a trip's alerts, oldest first.
+func (r *MemoryTrackAlertRepository) FindByTrackID(_ context.Context, trackID uuid.UUID) ([]*alertDomain.TrackAlert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	alerts := []*alertDomain.TrackAlert{}
+	for _, a := range r.alerts {
+		if a.TrackID == trackID {
+			a := a
+			alerts = append(alerts, &a)
+		}
+	}
+	sort.SliceStable(alerts, func(i, j int) bool { return alerts[i].RaisedAt.Before(alerts[j].RaisedAt) })
+	return alerts, nil
+}
+
+// MemoryHistoryRepository implements history.Repository in memory.
+type MemoryHistoryRepository struct {
+	mu     sync.Mutex
+	events []historyDomain.TrackEvent
+}
+
+// NewMemoryHistoryRepository creates an empty MemoryHistoryRepository.
+func NewMemoryHistoryRepository() *MemoryHistoryRepository {
+	return &MemoryHistoryRepository{}
+}
+
+// Append persists a new lifecycle event.
+func (r *MemoryHistoryRepository) Append(_ context.Context, e *historyDomain.TrackEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, *e)
+	return nil
+}
+
+// FindByBookingID returns a booking's lifecycle events, oldest first.
+func (r *MemoryHistoryRepository) FindByBookingID(_ context.Context, bookingID uuid.UUID) ([]*historyDomain.TrackEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := []*historyDomain.TrackEvent{}
+	for _, e := range r.events {
+		if e.BookingID == bookingID {
+			e := e
+			events = append(events, &e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].OccurredAt.Before(events[j].OccurredAt) })
+	return events, nil
+}
+
+// MemoryErasureRepository implements erasure.Repository in memory. Requests are stored
+// in their GORM model form so their reports round-trip through JSON as in the database.
+type MemoryErasureRepository struct {
+	mu       sync.Mutex
+	requests []ErasureRequestModel
+}
+
+// NewMemoryErasureRepository creates an empty MemoryErasureRepository.
+func NewMemoryErasureRepository() *MemoryErasureRepository {
+	return &MemoryErasureRepository{}
+}
+
+// Save persists a new erasure request.
+func (r *MemoryErasureRepository) Save(_ context.Context, req *erasureDomain.Request) error {
+	model, err := toErasureModel(req)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, model)
+	return nil
+}
+
+// Update persists the progress of an erasure request.
+func (r *MemoryErasureRepository) Update(_ context.Context, req *erasureDomain.Request) error {
+	model, err := toErasureModel(req)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.requests {
+		if r.requests[i].ID == model.ID {
+			r.requests[i] = model
+			return nil
+		}
+	}
+	r.requests = append(r.requests, model)
+	return nil
+}
+
+// FindByID returns an erasure request by its ID.
+func (r *MemoryErasureRepository) FindByID(_ context.Context, id uuid.UUID) (*erasureDomain.Request, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.requests {
+		if r.requests[i].ID == id {
+			return toErasureDomain(&r.requests[i])
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// FindPending returns the pending erasure requests, oldest first.
+func (r *MemoryErasureRepository) FindPending(_ context.Context) ([]*erasureDomain.Request, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	requests := []*erasureDomain.Request{}
+	for i := range r.requests {
+		if r.requests[i].Status != string(erasureDomain.StatusPending) {
+			continue
+		}
+		req, err := toErasureDomain(&r.requests[i])
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	sort.SliceStable(requests, func(i, j int) bool { return requests[i].RequestedAt.Before(requests[j].RequestedAt) })
+	return requests, nil
+}
+
+// MemoryTelemetryRepository implements telemetry.Repository in memory.
+type MemoryTelemetryRepository struct {
+	mu       sync.Mutex
+	readings []telemetryDomain.Reading
+}
+
+// NewMemoryTelemetryRepository creates an empty MemoryTelemetryRepository.
+func NewMemoryTelemetryRepository() *MemoryTelemetryRepository {
+	return &MemoryTelemetryRepository{}
+}
+
+// Save persists new readings.
+func (r *MemoryTelemetryRepository) Save(_ context.Context, readings []*telemetryDomain.Reading) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range readings {
+		r.readings = append(r.readings, *t)
+	}
+	return nil
+}
+
+// FindByTrackID returns a trip's readings, oldest first.
+func (r *MemoryTelemetryRepository) FindByTrackID(_ context.Context, trackID uuid.UUID) ([]*telemetryDomain.Reading, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	readings := []*telemetryDomain.Reading{}
+	for _, t := range r.readings {
+		if t.TrackID == trackID {
+			t := t
+			readings = append(readings, &t)
+		}
+	}
+	sort.SliceStable(readings, func(i, j int) bool { return readings[i].RecordedAt.Before(readings[j].RecordedAt) })
+	return readings, nil
+}
+
+// MemorySupportRepository implements support.Repository in memory.
+type MemorySupportRepository struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]supportDomain.Session
+	audit    []supportDomain.AuditEvent
+}
+
+// NewMemorySupportRepository creates an empty MemorySupportRepository.
+func NewMemorySupportRepository() *MemorySupportRepository {
+	return &MemorySupportRepository{sessions: make(map[uuid.UUID]supportDomain.Session)}
+}
+
+// SaveSession persists a new support session.
+func (r *MemorySupportRepository) SaveSession(_ context.Context, s *supportDomain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[s.ID] = *s
+	return nil
+}
+
+// FindSession returns a support session by ID.
+func (r *MemorySupportRepository) FindSession(_ context.Context, id uuid.UUID) (*supportDomain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &s, nil
+}
+
+// CloseSession sets a session's ClosedAt if it is still open.
+func (r *MemorySupportRepository) CloseSession(_ context.Context, id uuid.UUID, closedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.sessions[id]; ok && s.ClosedAt == nil {
+		s.ClosedAt = &closedAt
+		r.sessions[id] = s
+	}
+	return nil
+}
+
+// AppendAudit records an audit event.
+func (r *MemorySupportRepository) AppendAudit(_ context.Context, e *supportDomain.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.audit = append(r.audit, *e)
+	return nil
+}
+
+// ListAudit returns a session's audit events, oldest first.
+func (r *MemorySupportRepository) ListAudit(_ context.Context, sessionID uuid.UUID) ([]*supportDomain.AuditEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := []*supportDomain.AuditEvent{}
+	for _, e := range r.audit {
+		if e.SessionID == sessionID {
+			e := e
+			events = append(events, &e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].OccurredAt.Before(events[j].OccurredAt) })
+	return events, nil
+}
+
+// MemoryCertificateRepository implements certificate.Repository in memory.
+type MemoryCertificateRepository struct {
+	mu           sync.Mutex
+	certificates map[uuid.UUID]certificateDomain.Certificate
+}
+
+// NewMemoryCertificateRepository creates an empty MemoryCertificateRepository.
+func NewMemoryCertificateRepository() *MemoryCertificateRepository {
+	return &MemoryCertificateRepository{certificates: make(map[uuid.UUID]certificateDomain.Certificate)}
+}
+
+// Save persists a new certificate.
+func (r *MemoryCertificateRepository) Save(_ context.Context, c *certificateDomain.Certificate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.certificates[c.BookingID]; ok {
+		return fmt.Errorf("certificate for booking %s already exists", c.BookingID)
+	}
+	r.certificates[c.BookingID] = *c
+	return nil
+}
+
+// FindByBookingID returns the certificate of a booking.
+func (r *MemoryCertificateRepository) FindByBookingID(_ context.Context, bookingID uuid.UUID) (*certificateDomain.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.certificates[bookingID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &c, nil
+}
+
+// MemoryPrivacyRepository implements privacy.Repository in memory.
+type MemoryPrivacyRepository struct {
+	mu    sync.Mutex
+	drops []privacyDomain.DropCount
+}
+
+// NewMemoryPrivacyRepository creates an empty MemoryPrivacyRepository.
+func NewMemoryPrivacyRepository() *MemoryPrivacyRepository {
+	return &MemoryPrivacyRepository{}
+}
+
+// AddDrops adds counts to the stored ones, creating missing rows.
+func (r *MemoryPrivacyRepository) AddDrops(_ context.Context, counts []privacyDomain.DropCount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+next:
+	for _, c := range counts {
+		for i := range r.drops {
+			d := &r.drops[i]
+			if d.RunnerID == c.RunnerID && d.Day.Equal(c.Day) && d.Reason == c.Reason {
+				d.Count += c.Count
+				continue next
+			}
+		}
+		r.drops = append(r.drops, c)
+	}
+	return nil
+}
+
+// ListDrops returns a runner's counts for the days in [from, to), oldest first.
+func (r *MemoryPrivacyRepository) ListDrops(_ context.Context, runnerID uuid.UUID, from, to time.Time) ([]privacyDomain.DropCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	drops := []privacyDomain.DropCount{}
+	for _, d := range r.drops {
+		if d.RunnerID == runnerID && !d.Day.Before(from) && d.Day.Before(to) {
+			drops = append(drops, d)
+		}
+	}
+	sort.Slice(drops, func(i, j int) bool {
+		if !drops[i].Day.Equal(drops[j].Day) {
+			return drops[i].Day.Before(drops[j].Day)
+		}
+		return drops[i].Reason < drops[j].Reason
+	})
+	return drops, nil
+}
+
+// MemoryWebhookRepository implements webhook.Repository in memory.
+type MemoryWebhookRepository struct {
+	mu         sync.Mutex
+	webhooks   []webhookDomain.Webhook
+	deliveries []webhookDomain.Delivery
+}
+
+// NewMemoryWebhookRepository creates an empty MemoryWebhookRepository.
+func NewMemoryWebhookRepository() *MemoryWebhookRepository {
+	return &MemoryWebhookRepository{}
+}
+
+// Save persists a new webhook.
+func (r *MemoryWebhookRepository) Save(_ context.Context, w *webhookDomain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhooks = append(r.webhooks, *w)
+	return nil
+}
+
+// FindByID returns a webhook by its ID.
+func (r *MemoryWebhookRepository) FindByID(_ context.Context, id uuid.UUID) (*webhookDomain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, w := range r.webhooks {
+		if w.ID == id {
+			return &w, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// FindByAccountID returns the webhooks an account owns, oldest first.
+func (r *MemoryWebhookRepository) FindByAccountID(_ context.Context, accountID uuid.UUID) ([]*webhookDomain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhooks := []*webhookDomain.Webhook{}
+	for _, w := range r.webhooks {
+		if w.AccountID == accountID {
+			w := w
+			webhooks = append(webhooks, &w)
+		}
+	}
+	return webhooks, nil
+}
+
+// FindForBooking returns the webhooks of a booking and the account webhooks of its owner.
+func (r *MemoryWebhookRepository) FindForBooking(_ context.Context, bookingID, ownerID uuid.UUID) ([]*webhookDomain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhooks := []*webhookDomain.Webhook{}
+	for _, w := range r.webhooks {
+		if w.BookingID != nil && *w.BookingID == bookingID ||
+			w.BookingID == nil && ownerID != uuid.Nil && w.AccountID == ownerID {
+			w := w
+			webhooks = append(webhooks, &w)
+		}
+	}
+	return webhooks, nil
+}
+
+// Delete removes a webhook and its deliveries.
+func (r *MemoryWebhookRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, w := range r.webhooks {
+		if w.ID != id {
+			continue
+		}
+		r.webhooks = append(r.webhooks[:i], r.webhooks[i+1:]...)
+		kept := r.deliveries[:0]
+		for _, d := range r.deliveries {
+			if d.WebhookID != id {
+				kept = append(kept, d)
+			}
+		}
+		r.deliveries = kept
+		return nil
+	}
+	return domain.ErrNotFound
+}
+
+// SaveDelivery persists a new delivery.
+func (r *MemoryWebhookRepository) SaveDelivery(_ context.Context, d *webhookDomain.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries = append(r.deliveries, *d)
+	return nil
+}
+
+// UpdateDelivery persists the outcome of a delivery attempt.
+func (r *MemoryWebhookRepository) UpdateDelivery(_ context.Context, d *webhookDomain.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.deliveries {
+		if r.deliveries[i].ID == d.ID {
+			r.deliveries[i] = *d
+			return nil
+		}
+	}
+	r.deliveries = append(r.deliveries, *d)
+	return nil
+}
+
+// FindDueDeliveries returns up to limit pending deliveries due at now, oldest first.
+func (r *MemoryWebhookRepository) FindDueDeliveries(_ context.Context, now time.Time, limit int) ([]*webhookDomain.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deliveries := []*webhookDomain.Delivery{}
+	for _, d := range r.deliveries {
+		if d.Status == webhookDomain.DeliveryPending && !d.NextAttemptAt.After(now) {
+			d := d
+			deliveries = append(deliveries, &d)
+		}
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool { return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt) })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// ClaimDelivery pushes a due pending delivery's next attempt to until.
+func (r *MemoryWebhookRepository) ClaimDelivery(_ context.Context, id uuid.UUID, attempts int, now, until time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.deliveries {
+		d := &r.deliveries[i]
+		if d.ID != id {
+			continue
+		}
+		if d.Status != webhookDomain.DeliveryPending || d.Attempts != attempts || d.NextAttemptAt.After(now) {
+			return false, nil
+		}
+		d.NextAttemptAt = until
+		return true, nil
+	}
+	return false, nil
+}
+
+// FindDeliveries returns a webhook's latest deliveries, newest first.
+func (r *MemoryWebhookRepository) FindDeliveries(_ context.Context, webhookID uuid.UUID, limit int) ([]*webhookDomain.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deliveries := []*webhookDomain.Delivery{}
+	for _, d := range r.deliveries {
+		if d.WebhookID == webhookID {
+			d := d
+			deliveries = append(deliveries, &d)
+		}
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// DeleteFinishedDeliveries removes the delivered and failed deliveries created before cutoff.
+func (r *MemoryWebhookRepository) DeleteFinishedDeliveries(_ context.Context, cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var removed int64
+	kept := r.deliveries[:0]
+	for _, d := range r.deliveries {
+		if d.Status != webhookDomain.DeliveryPending && d.CreatedAt.Before(cutoff) {
+			removed++
+			continue
+		}
+		kept = append(kept, d)
+	}
+	r.deliveries = kept
+	return removed, nil
+}
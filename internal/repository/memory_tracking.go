@@ -0,0 +1,773 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// memoryTrack is a stored trip track with its waypoints in recorded_at order.
+type memoryTrack struct {
+	model     TripTrackModel
+	waypoints []WaypointModel
+}
+
+// MemoryTripTrackRepository implements TripTrackRepository in memory for standalone
+// mode. Tracks are stored in their GORM model form, so they round-trip exactly as they
+// would through the database, and chunks are derived from the waypoint order on read.
+type MemoryTripTrackRepository struct {
+	mu     sync.RWMutex
+	tracks map[uuid.UUID]*memoryTrack
+}
+
+// NewMemoryTripTrackRepository creates an empty in-memory trip track repository.
+func NewMemoryTripTrackRepository() *MemoryTripTrackRepository {
+	return &MemoryTripTrackRepository{tracks: make(map[uuid.UUID]*memoryTrack)}
+}
+
+// isInProgress reports whether a stored track has not ended.
+func (t *memoryTrack) isInProgress() bool {
+	for _, s := range inProgressStatuses {
+		if t.model.Status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// activeSince reports whether a stored track was active at any time since the given moment.
+func (t *memoryTrack) activeSince(since time.Time) bool {
+	m := t.model
+	return t.isInProgress() ||
+		(m.CompletedAt != nil && !m.CompletedAt.Before(since)) ||
+		(m.CancelledAt != nil && !m.CancelledAt.Before(since))
+}
+
+// find returns the stored tracks matching match, oldest first.
+func (r *MemoryTripTrackRepository) find(match func(*memoryTrack) bool) []*memoryTrack {
+	var found []*memoryTrack
+	for _, t := range r.tracks {
+		if match(t) {
+			found = append(found, t)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].model.StartedAt.Before(found[j].model.StartedAt) })
+	return found
+}
+
+// toTracks converts stored tracks to domain tracks.
+func toTracks(stored []*memoryTrack) []*trackingDomain.TripTrack {
+	tracks := make([]*trackingDomain.TripTrack, len(stored))
+	for i, t := range stored {
+		model := t.model
+		tracks[i] = toDomain(&model)
+	}
+	return tracks
+}
+
+// FindByID retrieves a trip track by its unique identifier.
+func (r *MemoryTripTrackRepository) FindByID(_ context.Context, id uuid.UUID) (*trackingDomain.TripTrack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tracks[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	model := t.model
+	return toDomain(&model), nil
+}
+
+// FindByBookingID retrieves a trip track by its associated booking identifier.
+func (r *MemoryTripTrackRepository) FindByBookingID(_ context.Context, bookingID uuid.UUID) (*trackingDomain.TripTrack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	found := r.find(func(t *memoryTrack) bool { return t.model.BookingID == bookingID })
+	if len(found) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return toTracks(found)[0], nil
+}
+
+// FindActiveByRunnerID retrieves the oldest active trip track for a runner.
+func (r *MemoryTripTrackRepository) FindActiveByRunnerID(ctx context.Context, runnerID uuid.UUID) (*trackingDomain.TripTrack, error) {
+	tracks, _ := r.FindAllActiveByRunnerID(ctx, runnerID)
+	if len(tracks) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return tracks[0], nil
+}
+
+// FindAllActiveByRunnerID retrieves all active trip tracks for a runner, oldest first.
+func (r *MemoryTripTrackRepository) FindAllActiveByRunnerID(_ context.Context, runnerID uuid.UUID) ([]*trackingDomain.TripTrack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return toTracks(r.find(func(t *memoryTrack) bool {
+		return t.model.RunnerID == runnerID && t.isInProgress()
+	})), nil
+}
+
+// FindByRunnerIDSince retrieves a runner's trip tracks that were active at any time
+// since the given moment, oldest first.
+func (r *MemoryTripTrackRepository) FindByRunnerIDSince(_ context.Context, runnerID uuid.UUID, since time.Time) ([]*trackingDomain.TripTrack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return toTracks(r.find(func(t *memoryTrack) bool {
+		return t.model.RunnerID == runnerID && t.activeSince(since)
+	})), nil
+}
+
+// FindRunnerIDsActiveBetween returns the runners with a trip track that was active at
+// any time between from and to.
+func (r *MemoryTripTrackRepository) FindRunnerIDsActiveBetween(_ context.Context, from, to time.Time) ([]uuid.UUID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	seen := make(map[uuid.UUID]bool)
+	runnerIDs := []uuid.UUID{}
+	for _, t := range r.find(func(t *memoryTrack) bool {
+		return t.model.StartedAt.Before(to) && t.activeSince(from)
+	}) {
+		if !seen[t.model.RunnerID] {
+			seen[t.model.RunnerID] = true
+			runnerIDs = append(runnerIDs, t.model.RunnerID)
+		}
+	}
+	return runnerIDs, nil
+}
+
+// FindActiveBookingIDsByRegion returns the bookings with an active trip track in a region.
+func (r *MemoryTripTrackRepository) FindActiveBookingIDsByRegion(_ context.Context, region string) ([]uuid.UUID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	bookingIDs := []uuid.UUID{}
+	for _, t := range r.find(func(t *memoryTrack) bool { return t.model.Region == region && t.isInProgress() }) {
+		bookingIDs = append(bookingIDs, t.model.BookingID)
+	}
+	return bookingIDs, nil
+}
+
+// List retrieves the trip tracks matching the query built from opts, ordered and paged
+// by (sort field, id) as the GORM repository does.
+func (r *MemoryTripTrackRepository) List(_ context.Context, opts ...trackingDomain.ListOption) ([]*trackingDomain.TripTrack, error) {
+	q := trackingDomain.NewListQuery(opts...)
+	if _, ok := listSortColumns[q.Sort]; !ok {
+		return nil, fmt.Errorf("unknown list sort %q", q.Sort)
+	}
+
+	// compare orders two tracks by the sort field, then by ID.
+	compare := func(started time.Time, km float64, id uuid.UUID, c trackingDomain.ListCursor) int {
+		switch {
+		case q.Sort == trackingDomain.ListSortDistance && km < c.DistanceKm,
+			q.Sort == trackingDomain.ListSortStartedAt && started.Before(c.StartedAt):
+			return -1
+		case q.Sort == trackingDomain.ListSortDistance && km > c.DistanceKm,
+			q.Sort == trackingDomain.ListSortStartedAt && started.After(c.StartedAt):
+			return 1
+		}
+		if id.String() == c.ID.String() {
+			return 0
+		}
+		if id.String() < c.ID.String() {
+			return -1
+		}
+		return 1
+	}
+
+	r.mu.RLock()
+	var matched []TripTrackModel
+	for _, t := range r.tracks {
+		m := t.model
+		if len(q.Statuses) > 0 {
+			ok := false
+			for _, s := range q.Statuses {
+				ok = ok || m.Status == string(s)
+			}
+			if !ok {
+				continue
+			}
+		}
+		if (q.RunnerID != nil && m.RunnerID != *q.RunnerID) ||
+			(!q.StartedFrom.IsZero() && m.StartedAt.Before(q.StartedFrom)) ||
+			(!q.StartedTo.IsZero() && !m.StartedAt.Before(q.StartedTo)) ||
+			(q.MinDistanceKm > 0 && m.TotalDistanceKm < q.MinDistanceKm) {
+			continue
+		}
+		if q.After != nil {
+			cmp := compare(m.StartedAt, m.TotalDistanceKm, m.ID, *q.After)
+			if (q.Descending && cmp >= 0) || (!q.Descending && cmp <= 0) {
+				continue
+			}
+		}
+		matched = append(matched, m)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		cmp := compare(matched[i].StartedAt, matched[i].TotalDistanceKm, matched[i].ID, trackingDomain.ListCursor{
+			ID: matched[j].ID, StartedAt: matched[j].StartedAt, DistanceKm: matched[j].TotalDistanceKm,
+		})
+		if q.Descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+
+	tracks := make([]*trackingDomain.TripTrack, len(matched))
+	for i := range matched {
+		tracks[i] = toDomain(&matched[i])
+	}
+	return tracks, nil
+}
+
+// Save persists a new trip track.
+func (r *MemoryTripTrackRepository) Save(_ context.Context, track *trackingDomain.TripTrack) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	model := toModel(track)
+	for _, t := range r.tracks {
+		if t.model.ID == model.ID || t.model.BookingID == model.BookingID {
+			return fmt.Errorf("failed to save trip track: booking %s is already tracked", model.BookingID)
+		}
+	}
+	r.tracks[model.ID] = &memoryTrack{model: *model}
+	return nil
+}
+
+// Update persists changes to an existing trip track, keeping the running distance of a
+// trip in progress and the last position as the GORM repository does.
+func (r *MemoryTripTrackRepository) Update(_ context.Context, track *trackingDomain.TripTrack) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	model := toModel(track)
+	t, ok := r.tracks[model.ID]
+	if !ok || t.model.Version != model.Version-1 {
+		return domain.ErrOptimisticLock
+	}
+	model.LastLatitude, model.LastLongitude, model.LastRecordedAt = t.model.LastLatitude, t.model.LastLongitude, t.model.LastRecordedAt
+	model.DeviceBattery, model.DeviceNetwork, model.DeviceUpdatedAt = t.model.DeviceBattery, t.model.DeviceNetwork, t.model.DeviceUpdatedAt
+	model.MovedLatitude, model.MovedLongitude, model.MovedAt, model.StalledAt = t.model.MovedLatitude, t.model.MovedLongitude, t.model.MovedAt, t.model.StalledAt
+	if track.IsActive() {
+		model.TotalDistanceKm = t.model.TotalDistanceKm
+	}
+	t.model = *model
+	return nil
+}
+
+// AdvanceDistance adds the distance from the track's last position to waypoint to its
+// running total and makes waypoint the last position.
+func (r *MemoryTripTrackRepository) AdvanceDistance(_ context.Context, trackID uuid.UUID, waypoint trackingDomain.Waypoint) (float64, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tracks[trackID]
+	if !ok || !t.isInProgress() || (t.model.LastRecordedAt != nil && t.model.LastRecordedAt.After(waypoint.RecordedAt)) {
+		return 0, false, nil
+	}
+	var km float64
+	if t.model.LastLatitude != nil && t.model.LastLongitude != nil {
+		km = trackingDomain.DistanceMeters(*t.model.LastLatitude, *t.model.LastLongitude, waypoint.Latitude, waypoint.Longitude) / 1000
+	}
+	lat, lng, at := waypoint.Latitude, waypoint.Longitude, waypoint.RecordedAt
+	t.model.TotalDistanceKm += km
+	t.model.LastLatitude, t.model.LastLongitude, t.model.LastRecordedAt = &lat, &lng, &at
+	return km, true, nil
+}
+
+// AddDistance adds km to the running total of a trip in progress.
+func (r *MemoryTripTrackRepository) AddDistance(_ context.Context, trackID uuid.UUID, km float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.tracks[trackID]; ok && t.isInProgress() {
+		t.model.TotalDistanceKm += km
+	}
+	return nil
+}
+
+// UpdateDeviceStatus records the runner's phone status unless a later one is stored.
+func (r *MemoryTripTrackRepository) UpdateDeviceStatus(_ context.Context, trackID uuid.UUID, status trackingDomain.DeviceStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tracks[trackID]
+	if !ok || (t.model.DeviceUpdatedAt != nil && t.model.DeviceUpdatedAt.After(status.ReportedAt)) {
+		return nil
+	}
+	at := status.ReportedAt
+	t.model.DeviceUpdatedAt = &at
+	if status.BatteryPct != nil {
+		battery := *status.BatteryPct
+		t.model.DeviceBattery = &battery
+	}
+	if status.Network != "" {
+		network := status.Network
+		t.model.DeviceNetwork = &network
+	}
+	return nil
+}
+
+// RecordMovement records the runner's latest meaningful movement and clears the stall
+// mark, unless a later movement is stored.
+func (r *MemoryTripTrackRepository) RecordMovement(_ context.Context, trackID uuid.UUID, m trackingDomain.Movement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tracks[trackID]
+	if !ok || (t.model.MovedAt != nil && t.model.MovedAt.After(m.At)) {
+		return nil
+	}
+	lat, lng, at := m.Latitude, m.Longitude, m.At
+	t.model.MovedLatitude, t.model.MovedLongitude, t.model.MovedAt = &lat, &lng, &at
+	t.model.StalledAt = nil
+	return nil
+}
+
+// MarkStalled marks an active trip without movement since movedBefore as stalled, if
+// it is not marked yet.
+func (r *MemoryTripTrackRepository) MarkStalled(_ context.Context, trackID uuid.UUID, at, movedBefore time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tracks[trackID]
+	if !ok || !t.stallCandidate(movedBefore) {
+		return false, nil
+	}
+	t.model.StalledAt = &at
+	return true, nil
+}
+
+// FindStallCandidates retrieves the active trips without movement since movedBefore
+// that are not marked stalled.
+func (r *MemoryTripTrackRepository) FindStallCandidates(_ context.Context, movedBefore time.Time) ([]*trackingDomain.TripTrack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return toTracks(r.find(func(t *memoryTrack) bool { return t.stallCandidate(movedBefore) })), nil
+}
+
+// FindStalled retrieves the active trips marked stalled, longest without movement first.
+func (r *MemoryTripTrackRepository) FindStalled(_ context.Context) ([]*trackingDomain.TripTrack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	found := r.find(func(t *memoryTrack) bool {
+		return t.model.Status == string(trackingDomain.TrackingActive) && t.model.StalledAt != nil
+	})
+	sort.SliceStable(found, func(i, j int) bool {
+		a, b := found[i].model.MovedAt, found[j].model.MovedAt
+		return a != nil && (b == nil || a.Before(*b))
+	})
+	return toTracks(found), nil
+}
+
+// FindCompletedSince retrieves up to limit trip tracks completed at or after since,
+// oldest completion first.
+func (r *MemoryTripTrackRepository) FindCompletedSince(_ context.Context, since time.Time, limit int) ([]*trackingDomain.TripTrack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	found := r.find(func(t *memoryTrack) bool {
+		return t.model.Status == string(trackingDomain.TrackingCompleted) && t.model.CompletedAt != nil && !t.model.CompletedAt.Before(since)
+	})
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].model.CompletedAt.Before(*found[j].model.CompletedAt)
+	})
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return toTracks(found), nil
+}
+
+// GetFleetStats counts the trips in progress per region, ordered by region.
+func (r *MemoryTripTrackRepository) GetFleetStats(_ context.Context) ([]trackingDomain.RegionFleetStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	byRegion := make(map[string]*trackingDomain.RegionFleetStats)
+	runners := make(map[string]map[uuid.UUID]bool)
+	for _, t := range r.tracks {
+		if !t.isInProgress() {
+			continue
+		}
+		stats, ok := byRegion[t.model.Region]
+		if !ok {
+			stats = &trackingDomain.RegionFleetStats{Region: t.model.Region}
+			byRegion[t.model.Region] = stats
+			runners[t.model.Region] = make(map[uuid.UUID]bool)
+		}
+		if t.model.Status == string(trackingDomain.TrackingPaused) {
+			stats.PausedTracks++
+		} else {
+			stats.ActiveTracks++
+		}
+		runners[t.model.Region][t.model.RunnerID] = true
+	}
+
+	result := make([]trackingDomain.RegionFleetStats, 0, len(byRegion))
+	for region, stats := range byRegion {
+		stats.ActiveRunners = len(runners[region])
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Region < result[j].Region })
+	return result, nil
+}
+
+// stallCandidate reports whether a stored track is active, not marked stalled and
+// without movement since movedBefore.
+func (t *memoryTrack) stallCandidate(movedBefore time.Time) bool {
+	return t.model.Status == string(trackingDomain.TrackingActive) && t.model.StalledAt == nil &&
+		t.model.MovedAt != nil && t.model.MovedAt.Before(movedBefore)
+}
+
+// ResetDistance overwrites a track's running total and last position.
+func (r *MemoryTripTrackRepository) ResetDistance(_ context.Context, trackID uuid.UUID, km float64, last *trackingDomain.Waypoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tracks[trackID]
+	if !ok {
+		return nil
+	}
+	t.model.TotalDistanceKm = km
+	t.model.LastLatitude, t.model.LastLongitude, t.model.LastRecordedAt = nil, nil, nil
+	if last != nil {
+		lat, lng, at := last.Latitude, last.Longitude, last.RecordedAt
+		t.model.LastLatitude, t.model.LastLongitude, t.model.LastRecordedAt = &lat, &lng, &at
+	}
+	return nil
+}
+
+// Delete removes a trip track and its waypoints.
+func (r *MemoryTripTrackRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tracks, id)
+	return nil
+}
+
+// MoveWaypoints moves all waypoints of one trip track to another, keeping the target's
+// waypoints in time order.
+func (r *MemoryTripTrackRepository) MoveWaypoints(_ context.Context, fromTrackID, toTrackID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	from, ok := r.tracks[fromTrackID]
+	if !ok {
+		return fmt.Errorf("failed to move waypoints: %w", domain.ErrNotFound)
+	}
+	to, ok := r.tracks[toTrackID]
+	if !ok {
+		return fmt.Errorf("failed to move waypoints: %w", domain.ErrNotFound)
+	}
+	for _, w := range from.waypoints {
+		w.TripTrackID = toTrackID
+		to.insert(w)
+	}
+	from.waypoints = nil
+	return nil
+}
+
+// EraseLocations deletes a trip track's waypoints and clears the positions stored on
+// the track.
+func (r *MemoryTripTrackRepository) EraseLocations(_ context.Context, trackID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tracks[trackID]
+	if !ok {
+		return 0, nil
+	}
+	deleted := len(t.waypoints)
+	t.waypoints = nil
+	t.model.LastLatitude, t.model.LastLongitude, t.model.LastRecordedAt = nil, nil, nil
+	t.model.DestLatitude, t.model.DestLongitude = nil, nil
+	t.model.MovedLatitude, t.model.MovedLongitude = nil, nil
+	return deleted, nil
+}
+
+// CountWaypoints returns the number of waypoints stored for a trip track.
+func (r *MemoryTripTrackRepository) CountWaypoints(_ context.Context, trackID uuid.UUID) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if t, ok := r.tracks[trackID]; ok {
+		return len(t.waypoints), nil
+	}
+	return 0, nil
+}
+
+// DownsampleWaypoints thins all but the keepRecent latest waypoints of a trip track to
+// every factor-th one, always keeping the first.
+func (r *MemoryTripTrackRepository) DownsampleWaypoints(_ context.Context, trackID uuid.UUID, keepRecent, factor int) error {
+	if factor < 2 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tracks[trackID]
+	if !ok {
+		return fmt.Errorf("failed to downsample waypoints: %w", domain.ErrNotFound)
+	}
+	kept := t.waypoints[:0]
+	for n, w := range t.waypoints {
+		if len(t.waypoints)-n <= keepRecent || n%factor == 0 {
+			kept = append(kept, w)
+		}
+	}
+	t.waypoints = kept
+	return nil
+}
+
+// insert adds a waypoint in recorded_at order, after waypoints recorded at the same time.
+func (t *memoryTrack) insert(w WaypointModel) {
+	i := sort.Search(len(t.waypoints), func(i int) bool { return t.waypoints[i].RecordedAt.After(w.RecordedAt) })
+	t.waypoints = append(t.waypoints, WaypointModel{})
+	copy(t.waypoints[i+1:], t.waypoints[i:])
+	t.waypoints[i] = w
+}
+
+// addWaypoints stores waypoints of a track, assigning IDs to those without one.
+func (r *MemoryTripTrackRepository) addWaypoints(trackID uuid.UUID, waypoints []trackingDomain.Waypoint, backfilled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tracks[trackID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	for _, waypoint := range waypoints {
+		if waypoint.ID == uuid.Nil {
+			waypoint.ID = uuid.New()
+		}
+		waypoint.Backfilled = waypoint.Backfilled || backfilled
+		t.insert(toWaypointModel(trackID, waypoint))
+	}
+	return nil
+}
+
+// AddWaypoint records a new GPS waypoint for a trip track.
+func (r *MemoryTripTrackRepository) AddWaypoint(_ context.Context, trackID uuid.UUID, waypoint trackingDomain.Waypoint) error {
+	if err := r.addWaypoints(trackID, []trackingDomain.Waypoint{waypoint}, false); err != nil {
+		return fmt.Errorf("failed to add waypoint: %w", err)
+	}
+	return nil
+}
+
+// BackfillWaypoints stores waypoints recorded while offline, marking them as backfilled.
+func (r *MemoryTripTrackRepository) BackfillWaypoints(_ context.Context, trackID uuid.UUID, waypoints []trackingDomain.Waypoint) error {
+	if err := r.addWaypoints(trackID, waypoints, true); err != nil {
+		return fmt.Errorf("failed to backfill waypoints: %w", err)
+	}
+	return nil
+}
+
+// waypointsOf returns a copy of a track's waypoints, in time order.
+func (r *MemoryTripTrackRepository) waypointsOf(trackID uuid.UUID) []trackingDomain.Waypoint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if t, ok := r.tracks[trackID]; ok {
+		return toWaypoints(t.waypoints)
+	}
+	return []trackingDomain.Waypoint{}
+}
+
+// GetWaypoints retrieves all waypoints for a trip track ordered by time.
+func (r *MemoryTripTrackRepository) GetWaypoints(_ context.Context, trackID uuid.UUID) ([]trackingDomain.Waypoint, error) {
+	return r.waypointsOf(trackID), nil
+}
+
+// ListWaypoints retrieves a page of a trip track's waypoints matching q, ordered by time
+// and then ID.
+func (r *MemoryTripTrackRepository) ListWaypoints(_ context.Context, trackID uuid.UUID, q trackingDomain.WaypointPageQuery) ([]trackingDomain.Waypoint, error) {
+	var page []trackingDomain.Waypoint
+	for _, wp := range r.waypointsOf(trackID) {
+		if q.Includes(wp) {
+			page = append(page, wp)
+		}
+	}
+	sort.SliceStable(page, func(i, j int) bool {
+		return trackingDomain.CursorAfterWaypoint(page[i]).Before(page[j])
+	})
+	if q.Limit > 0 && len(page) > q.Limit {
+		page = page[:q.Limit]
+	}
+	return page, nil
+}
+
+// EachWaypointBatch calls fn with successive batches of at most batchSize waypoints of
+// a trip track, in time order, stopping at the first error.
+func (r *MemoryTripTrackRepository) EachWaypointBatch(_ context.Context, trackID uuid.UUID, batchSize int, fn func([]trackingDomain.Waypoint) error) error {
+	waypoints := r.waypointsOf(trackID)
+	for start := 0; start < len(waypoints); start += batchSize {
+		if err := fn(waypoints[start:min(start+batchSize, len(waypoints))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetWaypointsBetween retrieves the waypoints recorded between from and to, plus the
+// nearest waypoint on either side, ordered by time.
+func (r *MemoryTripTrackRepository) GetWaypointsBetween(_ context.Context, trackID uuid.UUID, from, to time.Time) ([]trackingDomain.Waypoint, error) {
+	waypoints := r.waypointsOf(trackID)
+	lo := sort.Search(len(waypoints), func(i int) bool { return !waypoints[i].RecordedAt.Before(from) })
+	hi := sort.Search(len(waypoints), func(i int) bool { return waypoints[i].RecordedAt.After(to) })
+	if lo > 0 {
+		lo--
+	}
+	if hi < len(waypoints) {
+		hi++
+	}
+	return waypoints[lo:hi], nil
+}
+
+// GetChunksInBounds retrieves the waypoint chunks whose bounding box intersects box,
+// splitting the track's waypoints into chunks of WaypointChunkSize in time order.
+func (r *MemoryTripTrackRepository) GetChunksInBounds(_ context.Context, trackID uuid.UUID, box trackingDomain.BoundingBox) ([]trackingDomain.WaypointChunk, error) {
+	r.mu.RLock()
+	var models []WaypointModel
+	if t, ok := r.tracks[trackID]; ok {
+		models = append(models, t.waypoints...)
+	}
+	r.mu.RUnlock()
+
+	chunks := []trackingDomain.WaypointChunk{}
+	for start := 0; start < len(models); start += trackingDomain.WaypointChunkSize {
+		part := models[start:min(start+trackingDomain.WaypointChunkSize, len(models))]
+		var c WaypointChunkModel
+		for i := range part {
+			c.extend(&part[i])
+		}
+		if c.MinLatitude > box.MaxLatitude || c.MaxLatitude < box.MinLatitude ||
+			c.MinLongitude > box.MaxLongitude || c.MaxLongitude < box.MinLongitude {
+			continue
+		}
+		chunks = append(chunks, trackingDomain.WaypointChunk{
+			Seq: start / trackingDomain.WaypointChunkSize,
+			Bounds: trackingDomain.BoundingBox{
+				MinLatitude:  c.MinLatitude,
+				MinLongitude: c.MinLongitude,
+				MaxLatitude:  c.MaxLatitude,
+				MaxLongitude: c.MaxLongitude,
+			},
+			StartedAt: c.StartedAt,
+			EndedAt:   c.EndedAt,
+			Waypoints: toWaypoints(part),
+		})
+	}
+	return chunks, nil
+}
+
+// GetRouteAsGeoJSON returns the trip route as a GeoJSON LineString.
+func (r *MemoryTripTrackRepository) GetRouteAsGeoJSON(_ context.Context, trackID uuid.UUID) (string, error) {
+	return trackingDomain.LineStringGeoJSON(r.waypointsOf(trackID))
+}
+
+// routeLengthKm returns the great-circle length in kilometers of the route through waypoints.
+func routeLengthKm(waypoints []trackingDomain.Waypoint) float64 {
+	var meters float64
+	for i := 1; i < len(waypoints); i++ {
+		a, b := waypoints[i-1], waypoints[i]
+		meters += trackingDomain.DistanceMeters(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+	}
+	return meters / 1000
+}
+
+// GetRouteLengthKm returns the length in kilometers of the route through the waypoints
+// recorded in [from, to). A zero from or to leaves that side of the window open.
+func (r *MemoryTripTrackRepository) GetRouteLengthKm(_ context.Context, trackID uuid.UUID, from, to time.Time) (float64, error) {
+	var window []trackingDomain.Waypoint
+	for _, w := range r.waypointsOf(trackID) {
+		if (from.IsZero() || !w.RecordedAt.Before(from)) && (to.IsZero() || w.RecordedAt.Before(to)) {
+			window = append(window, w)
+		}
+	}
+	return routeLengthKm(window), nil
+}
+
+// GetWaypointStats aggregates a trip's waypoints as waypointStatsQuery does.
+func (r *MemoryTripTrackRepository) GetWaypointStats(_ context.Context, trackID uuid.UUID, opts trackingDomain.WaypointStatsOptions) (*trackingDomain.WaypointStats, error) {
+	waypoints := r.waypointsOf(trackID)
+	stats := &trackingDomain.WaypointStats{WaypointCount: len(waypoints)}
+	if len(waypoints) == 0 {
+		return stats, nil
+	}
+	first, last := waypoints[0], waypoints[len(waypoints)-1]
+	stats.FirstAt, stats.LastAt = first.RecordedAt, last.RecordedAt
+	stats.DistanceKm = routeLengthKm(waypoints)
+	stats.TimeAtPickup = last.RecordedAt.Sub(first.RecordedAt)
+
+	var runStart *time.Time
+	leftPickup := false
+	for i, w := range waypoints {
+		stats.MaxSpeedKmh = math.Max(stats.MaxSpeedKmh, w.Speed)
+		if !leftPickup && trackingDomain.DistanceMeters(first.Latitude, first.Longitude, w.Latitude, w.Longitude) > opts.PickupRadiusMeters {
+			leftPickup = true
+			stats.TimeAtPickup = w.RecordedAt.Sub(first.RecordedAt)
+		}
+		if w.Speed > opts.StopSpeedKmh {
+			runStart = nil
+			continue
+		}
+		if i+1 < len(waypoints) {
+			stats.Idle += waypoints[i+1].RecordedAt.Sub(w.RecordedAt)
+		}
+		if runStart == nil {
+			at := w.RecordedAt
+			runStart = &at
+		}
+		if stop := w.RecordedAt.Sub(*runStart); stop > stats.LongestStop {
+			stats.LongestStop = stop
+		}
+	}
+	return stats, nil
+}
+
+// GetWaypointHeatmap bins the waypoints of all trips matching q into geohash cells, as
+// waypointHeatmapQuery does.
+func (r *MemoryTripTrackRepository) GetWaypointHeatmap(_ context.Context, q trackingDomain.HeatmapQuery) ([]trackingDomain.HeatmapCell, error) {
+	excluded := make(map[uuid.UUID]bool, len(q.ExcludeRunners))
+	for _, id := range q.ExcludeRunners {
+		excluded[id] = true
+	}
+
+	r.mu.RLock()
+	cells := make(map[string]*trackingDomain.HeatmapCell)
+	for _, t := range r.tracks {
+		if excluded[t.model.RunnerID] {
+			continue
+		}
+		var trackCells map[string]bool
+		for _, w := range t.waypoints {
+			if w.RecordedAt.Before(q.From) || !w.RecordedAt.Before(q.To) || !q.Bounds.Contains(w.Latitude, w.Longitude) {
+				continue
+			}
+			hash := trackingDomain.EncodeGeohash(w.Latitude, w.Longitude, q.Precision)
+			cell, ok := cells[hash]
+			if !ok {
+				cell = &trackingDomain.HeatmapCell{Geohash: hash}
+				cells[hash] = cell
+			}
+			cell.Waypoints++
+			if trackCells == nil {
+				trackCells = make(map[string]bool)
+			}
+			if !trackCells[hash] {
+				trackCells[hash] = true
+				cell.Trips++
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	result := make([]trackingDomain.HeatmapCell, 0, len(cells))
+	for _, cell := range cells {
+		result = append(result, *cell)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Waypoints != result[j].Waypoints {
+			return result[i].Waypoints > result[j].Waypoints
+		}
+		return result[i].Geohash < result[j].Geohash
+	})
+	if q.Limit > 0 && len(result) > q.Limit {
+		result = result[:q.Limit]
+	}
+	return result, nil
+}
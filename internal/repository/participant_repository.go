@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	participantDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/participant"
+)
+
+// BookingParticipantModel is the GORM model for the booking_participants table.
+type BookingParticipantModel struct {
+	BookingID  uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	OwnerID    *uuid.UUID `gorm:"type:uuid;index"`
+	RunnerID   *uuid.UUID `gorm:"type:uuid;index"`
+	PetSpecies string     `gorm:"type:varchar(32);not null;default:''"`
+	UpdatedAt  time.Time  `gorm:"not null"`
+}
+
+// TableName sets the table name.
+func (BookingParticipantModel) TableName() string { return "booking_participants" }
+
+// GormParticipantRepository implements participant.Repository using GORM.
+type GormParticipantRepository struct {
+	db *gorm.DB
+}
+
+// NewGormParticipantRepository creates a new GormParticipantRepository.
+func NewGormParticipantRepository(db *gorm.DB) *GormParticipantRepository {
+	return &GormParticipantRepository{db: db}
+}
+
+// Upsert inserts or updates a booking's participants, only overwriting known IDs.
+func (r *GormParticipantRepository) Upsert(ctx context.Context, p participantDomain.BookingParticipants) error {
+	model := BookingParticipantModel{
+		BookingID:  p.BookingID,
+		OwnerID:    nullableUUID(p.OwnerID),
+		RunnerID:   nullableUUID(p.RunnerID),
+		PetSpecies: p.PetSpecies,
+		UpdatedAt:  time.Now().UTC(),
+	}
+
+	updates := map[string]interface{}{"updated_at": model.UpdatedAt}
+	if model.OwnerID != nil {
+		updates["owner_id"] = model.OwnerID
+	}
+	if model.RunnerID != nil {
+		updates["runner_id"] = model.RunnerID
+	}
+	if model.PetSpecies != "" {
+		updates["pet_species"] = model.PetSpecies
+	}
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "booking_id"}},
+		DoUpdates: clause.Assignments(updates),
+	}).Create(&model).Error
+}
+
+// FindByBookingID returns the participants of a booking.
+func (r *GormParticipantRepository) FindByBookingID(ctx context.Context, bookingID uuid.UUID) (*participantDomain.BookingParticipants, error) {
+	var model BookingParticipantModel
+	if err := r.db.WithContext(ctx).Where("booking_id = ?", bookingID).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	p := toParticipants(&model)
+	return &p, nil
+}
+
+// FindByUserID returns the participants of every booking the user owns or runs.
+func (r *GormParticipantRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]participantDomain.BookingParticipants, error) {
+	var models []BookingParticipantModel
+	if err := r.db.WithContext(ctx).
+		Where("owner_id = ? OR runner_id = ?", userID, userID).
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]participantDomain.BookingParticipants, len(models))
+	for i := range models {
+		result[i] = toParticipants(&models[i])
+	}
+	return result, nil
+}
+
+func toParticipants(model *BookingParticipantModel) participantDomain.BookingParticipants {
+	p := participantDomain.BookingParticipants{
+		BookingID:  model.BookingID,
+		PetSpecies: model.PetSpecies,
+		UpdatedAt:  model.UpdatedAt,
+	}
+	if model.OwnerID != nil {
+		p.OwnerID = *model.OwnerID
+	}
+	if model.RunnerID != nil {
+		p.RunnerID = *model.RunnerID
+	}
+	return p
+}
+
+// nullableUUID maps uuid.Nil to NULL.
+func nullableUUID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
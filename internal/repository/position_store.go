@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// positionEntry is the JSON value stored for a latest position.
+type positionEntry struct {
+	BookingID  uuid.UUID `json:"booking_id"`
+	RunnerID   uuid.UUID `json:"runner_id"`
+	WaypointID uuid.UUID `json:"waypoint_id"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Speed      float64   `json:"speed_kmh"`
+	Heading    float64   `json:"heading_degrees"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Accuracy   float64   `json:"accuracy_m,omitempty"`
+	Altitude   *float64  `json:"altitude_m,omitempty"`
+	Provider   string    `json:"provider,omitempty"`
+}
+
+// RedisLatestPositionStore implements LatestPositionStore using Redis.
+type RedisLatestPositionStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisLatestPositionStore creates a store whose entries expire after ttl without updates.
+func NewRedisLatestPositionStore(client *redis.Client, ttl time.Duration) *RedisLatestPositionStore {
+	return &RedisLatestPositionStore{client: client, ttl: ttl}
+}
+
+// Set records pos as the latest position for its booking and runner.
+func (s *RedisLatestPositionStore) Set(ctx context.Context, pos trackingDomain.LatestPosition) error {
+	data, err := json.Marshal(positionEntry{
+		BookingID:  pos.BookingID,
+		RunnerID:   pos.RunnerID,
+		WaypointID: pos.Waypoint.ID,
+		Latitude:   pos.Waypoint.Latitude,
+		Longitude:  pos.Waypoint.Longitude,
+		Speed:      pos.Waypoint.Speed,
+		Heading:    pos.Waypoint.Heading,
+		RecordedAt: pos.Waypoint.RecordedAt,
+		Accuracy:   pos.Waypoint.Accuracy,
+		Altitude:   pos.Waypoint.Altitude,
+		Provider:   pos.Waypoint.Provider,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal latest position: %w", err)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, bookingPositionKey(pos.BookingID), data, s.ttl)
+		pipe.Set(ctx, runnerPositionKey(pos.RunnerID), data, s.ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store latest position: %w", err)
+	}
+	return nil
+}
+
+// GetByBooking returns the latest position for a booking.
+func (s *RedisLatestPositionStore) GetByBooking(ctx context.Context, bookingID uuid.UUID) (*trackingDomain.LatestPosition, error) {
+	return s.get(ctx, bookingPositionKey(bookingID))
+}
+
+// GetByRunner returns the latest position reported by a runner.
+func (s *RedisLatestPositionStore) GetByRunner(ctx context.Context, runnerID uuid.UUID) (*trackingDomain.LatestPosition, error) {
+	return s.get(ctx, runnerPositionKey(runnerID))
+}
+
+func (s *RedisLatestPositionStore) get(ctx context.Context, key string) (*trackingDomain.LatestPosition, error) {
+	data, err := s.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get latest position: %w", err)
+	}
+
+	var e positionEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal latest position: %w", err)
+	}
+
+	return &trackingDomain.LatestPosition{
+		BookingID: e.BookingID,
+		RunnerID:  e.RunnerID,
+		Waypoint: trackingDomain.Waypoint{
+			ID:         e.WaypointID,
+			Latitude:   e.Latitude,
+			Longitude:  e.Longitude,
+			Speed:      e.Speed,
+			Heading:    e.Heading,
+			RecordedAt: e.RecordedAt,
+			Accuracy:   e.Accuracy,
+			Altitude:   e.Altitude,
+			Provider:   e.Provider,
+		},
+	}, nil
+}
+
+func bookingPositionKey(bookingID uuid.UUID) string {
+	return "tracking:position:booking:" + bookingID.String()
+}
+
+func runnerPositionKey(runnerID uuid.UUID) string {
+	return "tracking:position:runner:" + runnerID.String()
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	privacyDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/privacy"
+)
+
+// RunnerLocationDropModel is the GORM model for the runner_location_drops table.
+type RunnerLocationDropModel struct {
+	RunnerID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Day      time.Time `gorm:"type:date;primaryKey"`
+	Reason   string    `gorm:"type:varchar(32);primaryKey"`
+	Count    int64     `gorm:"not null;default:0"`
+}
+
+// TableName sets the table name.
+func (RunnerLocationDropModel) TableName() string { return "runner_location_drops" }
+
+// GormPrivacyRepository implements privacy.Repository using GORM.
+type GormPrivacyRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPrivacyRepository creates a new GormPrivacyRepository.
+func NewGormPrivacyRepository(db *gorm.DB) *GormPrivacyRepository {
+	return &GormPrivacyRepository{db: db}
+}
+
+// AddDrops adds counts to the stored ones, creating missing rows.
+func (r *GormPrivacyRepository) AddDrops(ctx context.Context, counts []privacyDomain.DropCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	models := make([]RunnerLocationDropModel, len(counts))
+	for i, c := range counts {
+		models[i] = RunnerLocationDropModel{
+			RunnerID: c.RunnerID,
+			Day:      c.Day,
+			Reason:   c.Reason,
+			Count:    c.Count,
+		}
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "runner_id"}, {Name: "day"}, {Name: "reason"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"count": gorm.Expr("runner_location_drops.count + excluded.count"),
+		}),
+	}).Create(&models).Error
+}
+
+// ListDrops returns a runner's counts for the days in [from, to), oldest first.
+func (r *GormPrivacyRepository) ListDrops(ctx context.Context, runnerID uuid.UUID, from, to time.Time) ([]privacyDomain.DropCount, error) {
+	var models []RunnerLocationDropModel
+	if err := r.db.WithContext(ctx).
+		Where("runner_id = ? AND day >= ? AND day < ?", runnerID, from, to).
+		Order("day ASC, reason ASC").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make([]privacyDomain.DropCount, len(models))
+	for i, m := range models {
+		counts[i] = privacyDomain.DropCount{
+			RunnerID: m.RunnerID,
+			Day:      m.Day,
+			Reason:   m.Reason,
+			Count:    m.Count,
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProcessedEventModel is the GORM model for the processed_events table.
+type ProcessedEventModel struct {
+	EventID     string    `gorm:"type:varchar(255);primaryKey"`
+	EventType   string    `gorm:"type:varchar(128);not null"`
+	ProcessedAt time.Time `gorm:"not null;index"`
+}
+
+// TableName sets the table name.
+func (ProcessedEventModel) TableName() string { return "processed_events" }
+
+// GormProcessedEventRepository implements events.ProcessedEventStore using GORM.
+type GormProcessedEventRepository struct {
+	db *gorm.DB
+}
+
+// NewGormProcessedEventRepository creates a new GormProcessedEventRepository.
+func NewGormProcessedEventRepository(db *gorm.DB) *GormProcessedEventRepository {
+	return &GormProcessedEventRepository{db: db}
+}
+
+// IsProcessed reports whether an event ID has been recorded.
+func (r *GormProcessedEventRepository) IsProcessed(ctx context.Context, eventID string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&ProcessedEventModel{}).
+		Where("event_id = ?", eventID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// MarkProcessed records an event ID. Recording an ID twice keeps the first record.
+func (r *GormProcessedEventRepository) MarkProcessed(ctx context.Context, eventID, eventType string) error {
+	model := ProcessedEventModel{
+		EventID:     eventID,
+		EventType:   eventType,
+		ProcessedAt: time.Now().UTC(),
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model).Error
+}
+
+// DeleteProcessedBefore deletes the event IDs recorded before a point in time and
+// returns how many were deleted.
+func (r *GormProcessedEventRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("processed_at < ?", before).
+		Delete(&ProcessedEventModel{})
+	return result.RowsAffected, result.Error
+}
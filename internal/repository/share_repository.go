@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
 	shareDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/share"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -11,11 +13,14 @@ import (
 
 // SharedTripModel is the GORM model for the shared_trips table.
 type SharedTripModel struct {
-	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
-	BookingID  uuid.UUID `gorm:"type:uuid;not null;index"`
-	ShareToken string    `gorm:"type:varchar(64);uniqueIndex;not null"`
-	ExpiresAt  time.Time `gorm:"not null"`
-	CreatedAt  time.Time `gorm:"not null"`
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	BookingID  uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ShareToken string     `gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt  time.Time  `gorm:"not null"`
+	CreatedAt  time.Time  `gorm:"not null"`
+	RevokedAt  *time.Time `gorm:"type:timestamptz"`
+	MaxViews   int        `gorm:"not null;default:0"`
+	ViewCount  int        `gorm:"not null;default:0"`
 }
 
 // TableName sets the table name.
@@ -41,6 +46,9 @@ func (r *GormSharedTripRepository) Save(ctx context.Context, st *shareDomain.Sha
 func (r *GormSharedTripRepository) FindByToken(ctx context.Context, token string) (*shareDomain.SharedTrip, error) {
 	var model SharedTripModel
 	if err := r.db.WithContext(ctx).Where("share_token = ?", token).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
 		return nil, err
 	}
 	return toShareDomain(&model), nil
@@ -55,6 +63,85 @@ func (r *GormSharedTripRepository) FindByBookingID(ctx context.Context, bookingI
 	return toShareDomain(&model), nil
 }
 
+// FindActiveByBookingID returns the unrevoked, unexpired links of a booking, newest first.
+func (r *GormSharedTripRepository) FindActiveByBookingID(ctx context.Context, bookingID uuid.UUID) ([]*shareDomain.SharedTrip, error) {
+	var models []SharedTripModel
+	if err := r.db.WithContext(ctx).
+		Where("booking_id = ? AND revoked_at IS NULL AND expires_at > ?", bookingID, time.Now().UTC()).
+		Order("created_at DESC").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	trips := make([]*shareDomain.SharedTrip, len(models))
+	for i := range models {
+		trips[i] = toShareDomain(&models[i])
+	}
+	return trips, nil
+}
+
+// Revoke marks a booking's unrevoked links, or only shareID if given, as revoked.
+func (r *GormSharedTripRepository) Revoke(ctx context.Context, bookingID uuid.UUID, shareID *uuid.UUID, at time.Time) (int, error) {
+	q := r.db.WithContext(ctx).Model(&SharedTripModel{}).Where("booking_id = ? AND revoked_at IS NULL", bookingID)
+	if shareID != nil {
+		q = q.Where("id = ?", *shareID)
+	}
+	result := q.Update("revoked_at", at)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+// ReassignBooking moves all links of one booking to another, keeping their tokens.
+func (r *GormSharedTripRepository) ReassignBooking(ctx context.Context, fromBookingID, toBookingID uuid.UUID) (int, error) {
+	result := r.db.WithContext(ctx).Model(&SharedTripModel{}).
+		Where("booking_id = ?", fromBookingID).
+		Update("booking_id", toBookingID)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+// DeleteByBookingID removes all links of a booking.
+func (r *GormSharedTripRepository) DeleteByBookingID(ctx context.Context, bookingID uuid.UUID) (int, error) {
+	result := r.db.WithContext(ctx).Delete(&SharedTripModel{}, "booking_id = ?", bookingID)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+// CountByBookingID returns how many links a booking has, revoked or not.
+func (r *GormSharedTripRepository) CountByBookingID(ctx context.Context, bookingID uuid.UUID) (int, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&SharedTripModel{}).
+		Where("booking_id = ?", bookingID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// RecordView counts one view of a link within its limits, expiring it when the view
+// limit is reached. The checks and the increment are a single UPDATE so concurrent
+// views cannot exceed the limit.
+func (r *GormSharedTripRepository) RecordView(ctx context.Context, id uuid.UUID) (bool, error) {
+	now := time.Now().UTC()
+	result := r.db.WithContext(ctx).Model(&SharedTripModel{}).
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ?", id, now).
+		Where("max_views = 0 OR view_count < max_views").
+		Updates(map[string]interface{}{
+			"view_count": gorm.Expr("view_count + 1"),
+			"expires_at": gorm.Expr("CASE WHEN max_views > 0 AND view_count + 1 >= max_views THEN ? ELSE expires_at END", now),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
 func toShareModel(s *shareDomain.SharedTrip) SharedTripModel {
 	return SharedTripModel{
 		ID:         s.ID(),
@@ -62,6 +149,9 @@ func toShareModel(s *shareDomain.SharedTrip) SharedTripModel {
 		ShareToken: s.ShareToken(),
 		ExpiresAt:  s.ExpiresAt(),
 		CreatedAt:  s.CreatedAt(),
+		RevokedAt:  s.RevokedAt(),
+		MaxViews:   s.MaxViews(),
+		ViewCount:  s.ViewCount(),
 	}
 }
 
@@ -72,5 +162,8 @@ func toShareDomain(m *SharedTripModel) *shareDomain.SharedTrip {
 		m.ShareToken,
 		m.ExpiresAt,
 		m.CreatedAt,
+		m.RevokedAt,
+		m.MaxViews,
+		m.ViewCount,
 	)
 }
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	supportDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/support"
+)
+
+// SupportSessionModel is the GORM model for the support_sessions table.
+type SupportSessionModel struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	AgentID   uuid.UUID  `gorm:"type:uuid;not null;index"`
+	BookingID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Reason    string     `gorm:"type:text;not null"`
+	OpenedAt  time.Time  `gorm:"type:timestamptz;not null"`
+	ExpiresAt time.Time  `gorm:"type:timestamptz;not null"`
+	ClosedAt  *time.Time `gorm:"type:timestamptz"`
+}
+
+// TableName sets the table name.
+func (SupportSessionModel) TableName() string { return "support_sessions" }
+
+// SupportAuditEventModel is the GORM model for the support_audit_events table.
+type SupportAuditEventModel struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	SessionID  uuid.UUID `gorm:"type:uuid;not null;index"`
+	AgentID    uuid.UUID `gorm:"type:uuid;not null"`
+	BookingID  uuid.UUID `gorm:"type:uuid;not null"`
+	Action     string    `gorm:"type:varchar(32);not null"`
+	Detail     string    `gorm:"type:text;not null;default:''"`
+	SourceIP   string    `gorm:"type:varchar(64);not null;default:''"`
+	OccurredAt time.Time `gorm:"type:timestamptz;not null"`
+}
+
+// TableName sets the table name.
+func (SupportAuditEventModel) TableName() string { return "support_audit_events" }
+
+// GormSupportRepository implements support.Repository using GORM.
+type GormSupportRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSupportRepository creates a new GormSupportRepository.
+func NewGormSupportRepository(db *gorm.DB) *GormSupportRepository {
+	return &GormSupportRepository{db: db}
+}
+
+// SaveSession persists a new support session.
+func (r *GormSupportRepository) SaveSession(ctx context.Context, s *supportDomain.Session) error {
+	model := SupportSessionModel{
+		ID:        s.ID,
+		AgentID:   s.AgentID,
+		BookingID: s.BookingID,
+		Reason:    s.Reason,
+		OpenedAt:  s.OpenedAt,
+		ExpiresAt: s.ExpiresAt,
+		ClosedAt:  s.ClosedAt,
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+// FindSession retrieves a support session by ID.
+func (r *GormSupportRepository) FindSession(ctx context.Context, id uuid.UUID) (*supportDomain.Session, error) {
+	var m SupportSessionModel
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &supportDomain.Session{
+		ID:        m.ID,
+		AgentID:   m.AgentID,
+		BookingID: m.BookingID,
+		Reason:    m.Reason,
+		OpenedAt:  m.OpenedAt,
+		ExpiresAt: m.ExpiresAt,
+		ClosedAt:  m.ClosedAt,
+	}, nil
+}
+
+// CloseSession sets a session's closed_at if it is not already closed.
+func (r *GormSupportRepository) CloseSession(ctx context.Context, id uuid.UUID, closedAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&SupportSessionModel{}).
+		Where("id = ? AND closed_at IS NULL", id).
+		Update("closed_at", closedAt).Error
+}
+
+// AppendAudit persists an audit event.
+func (r *GormSupportRepository) AppendAudit(ctx context.Context, e *supportDomain.AuditEvent) error {
+	model := SupportAuditEventModel{
+		ID:         e.ID,
+		SessionID:  e.SessionID,
+		AgentID:    e.AgentID,
+		BookingID:  e.BookingID,
+		Action:     e.Action,
+		Detail:     e.Detail,
+		SourceIP:   e.SourceIP,
+		OccurredAt: e.OccurredAt,
+	}
+	return r.db.WithContext(ctx).Create(&model).Error
+}
+
+// ListAudit returns a session's audit events, oldest first.
+func (r *GormSupportRepository) ListAudit(ctx context.Context, sessionID uuid.UUID) ([]*supportDomain.AuditEvent, error) {
+	var models []SupportAuditEventModel
+	if err := r.db.WithContext(ctx).
+		Where("session_id = ?", sessionID).
+		Order("occurred_at ASC").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*supportDomain.AuditEvent, len(models))
+	for i, m := range models {
+		result[i] = &supportDomain.AuditEvent{
+			ID:         m.ID,
+			SessionID:  m.SessionID,
+			AgentID:    m.AgentID,
+			BookingID:  m.BookingID,
+			Action:     m.Action,
+			Detail:     m.Detail,
+			SourceIP:   m.SourceIP,
+			OccurredAt: m.OccurredAt,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	telemetryDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/telemetry"
+)
+
+// TrackTelemetryModel is the GORM model for the track_telemetry table.
+type TrackTelemetryModel struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TripTrackID  uuid.UUID `gorm:"type:uuid;not null;index"`
+	BookingID    uuid.UUID `gorm:"type:uuid;not null"`
+	TemperatureC *float64  `gorm:"type:double precision"`
+	HumidityPct  *float64  `gorm:"type:double precision"`
+	RecordedAt   time.Time `gorm:"type:timestamptz;not null"`
+}
+
+// TableName sets the table name.
+func (TrackTelemetryModel) TableName() string { return "track_telemetry" }
+
+// GormTelemetryRepository implements telemetry.Repository using GORM.
+type GormTelemetryRepository struct {
+	db *gorm.DB
+}
+
+// NewGormTelemetryRepository creates a new GormTelemetryRepository.
+func NewGormTelemetryRepository(db *gorm.DB) *GormTelemetryRepository {
+	return &GormTelemetryRepository{db: db}
+}
+
+// Save persists new readings.
+func (r *GormTelemetryRepository) Save(ctx context.Context, readings []*telemetryDomain.Reading) error {
+	if len(readings) == 0 {
+		return nil
+	}
+	models := make([]TrackTelemetryModel, len(readings))
+	for i, t := range readings {
+		models[i] = TrackTelemetryModel{
+			ID:           t.ID,
+			TripTrackID:  t.TrackID,
+			BookingID:    t.BookingID,
+			TemperatureC: t.TemperatureC,
+			HumidityPct:  t.HumidityPct,
+			RecordedAt:   t.RecordedAt,
+		}
+	}
+	return r.db.WithContext(ctx).Create(&models).Error
+}
+
+// FindByTrackID returns a trip's readings, oldest first.
+func (r *GormTelemetryRepository) FindByTrackID(ctx context.Context, trackID uuid.UUID) ([]*telemetryDomain.Reading, error) {
+	var models []TrackTelemetryModel
+	if err := r.db.WithContext(ctx).
+		Where("trip_track_id = ?", trackID).
+		Order("recorded_at ASC").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	readings := make([]*telemetryDomain.Reading, len(models))
+	for i, m := range models {
+		readings[i] = &telemetryDomain.Reading{
+			ID:           m.ID,
+			TrackID:      m.TripTrackID,
+			BookingID:    m.BookingID,
+			TemperatureC: m.TemperatureC,
+			HumidityPct:  m.HumidityPct,
+			RecordedAt:   m.RecordedAt,
+		}
+	}
+	return readings, nil
+}
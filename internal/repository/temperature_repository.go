@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	temperatureDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/temperature"
+)
+
+// TemperatureThresholdModel is the GORM model for the temperature_thresholds table.
+type TemperatureThresholdModel struct {
+	Species    string    `gorm:"type:varchar(32);primaryKey"`
+	MinCelsius float64   `gorm:"type:decimal(5,2);not null"`
+	MaxCelsius float64   `gorm:"type:decimal(5,2);not null"`
+	UpdatedAt  time.Time `gorm:"not null"`
+}
+
+// TableName sets the table name.
+func (TemperatureThresholdModel) TableName() string { return "temperature_thresholds" }
+
+// GormTemperatureThresholdRepository implements temperature.Repository using GORM.
+type GormTemperatureThresholdRepository struct {
+	db *gorm.DB
+}
+
+// NewGormTemperatureThresholdRepository creates a new GormTemperatureThresholdRepository.
+func NewGormTemperatureThresholdRepository(db *gorm.DB) *GormTemperatureThresholdRepository {
+	return &GormTemperatureThresholdRepository{db: db}
+}
+
+// List returns all thresholds ordered by species.
+func (r *GormTemperatureThresholdRepository) List(ctx context.Context) ([]*temperatureDomain.Threshold, error) {
+	var models []TemperatureThresholdModel
+	if err := r.db.WithContext(ctx).Order("species ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	thresholds := make([]*temperatureDomain.Threshold, len(models))
+	for i := range models {
+		thresholds[i] = toThresholdDomain(&models[i])
+	}
+	return thresholds, nil
+}
+
+// FindBySpecies returns the threshold of a species.
+func (r *GormTemperatureThresholdRepository) FindBySpecies(ctx context.Context, species string) (*temperatureDomain.Threshold, error) {
+	var model TemperatureThresholdModel
+	if err := r.db.WithContext(ctx).Where("species = ?", species).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return toThresholdDomain(&model), nil
+}
+
+// Upsert creates or replaces the threshold of a species.
+func (r *GormTemperatureThresholdRepository) Upsert(ctx context.Context, t *temperatureDomain.Threshold) error {
+	model := TemperatureThresholdModel{
+		Species:    t.Species,
+		MinCelsius: t.MinCelsius,
+		MaxCelsius: t.MaxCelsius,
+		UpdatedAt:  t.UpdatedAt,
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "species"}},
+		UpdateAll: true,
+	}).Create(&model).Error
+}
+
+// Delete removes the threshold of a species.
+func (r *GormTemperatureThresholdRepository) Delete(ctx context.Context, species string) error {
+	result := r.db.WithContext(ctx).Delete(&TemperatureThresholdModel{}, "species = ?", species)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func toThresholdDomain(m *TemperatureThresholdModel) *temperatureDomain.Threshold {
+	return &temperatureDomain.Threshold{
+		Species:    m.Species,
+		MinCelsius: m.MinCelsius,
+		MaxCelsius: m.MaxCelsius,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
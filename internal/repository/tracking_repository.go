@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/Kilat-Pet-Delivery/lib-common/domain"
 	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
@@ -19,10 +20,30 @@ type TripTrackModel struct {
 	ID              uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
 	BookingID       uuid.UUID  `gorm:"type:uuid;uniqueIndex;not null"`
 	RunnerID        uuid.UUID  `gorm:"type:uuid;index;not null"`
+	Region          string     `gorm:"type:varchar(32);not null;default:'';index"`
 	Status          string     `gorm:"type:varchar(20);not null;default:'active';index"`
 	TotalDistanceKm float64   `gorm:"type:decimal(10,3);default:0"`
+	LastLatitude    *float64   `gorm:"type:double precision"`
+	LastLongitude   *float64   `gorm:"type:double precision"`
+	LastRecordedAt  *time.Time `gorm:"type:timestamptz"`
+	DestLatitude    *float64   `gorm:"type:double precision"`
+	DestLongitude   *float64   `gorm:"type:double precision"`
 	StartedAt       time.Time  `gorm:"type:timestamptz;not null;default:now()"`
 	CompletedAt     *time.Time `gorm:"type:timestamptz"`
+	CancelReason    *string    `gorm:"type:varchar(40)"`
+	CancelNote      *string    `gorm:"type:text"`
+	CancelledAt     *time.Time `gorm:"type:timestamptz"`
+	StartWeather    *string    `gorm:"type:jsonb"`
+	EndWeather      *string    `gorm:"type:jsonb"`
+	PausedAt        *time.Time `gorm:"type:timestamptz"`
+	PausedSeconds   int64      `gorm:"not null;default:0"`
+	DeviceBattery   *int       `gorm:"type:smallint"`
+	DeviceNetwork   *string    `gorm:"type:varchar(16)"`
+	DeviceUpdatedAt *time.Time `gorm:"type:timestamptz"`
+	MovedLatitude   *float64   `gorm:"type:double precision"`
+	MovedLongitude  *float64   `gorm:"type:double precision"`
+	MovedAt         *time.Time `gorm:"type:timestamptz;index"`
+	StalledAt       *time.Time `gorm:"type:timestamptz"`
 	Version         int64      `gorm:"not null;default:1"`
 	CreatedAt       time.Time  `gorm:"type:timestamptz;not null;default:now()"`
 	UpdatedAt       time.Time  `gorm:"type:timestamptz;not null;default:now()"`
@@ -37,12 +58,20 @@ func (TripTrackModel) TableName() string {
 type WaypointModel struct {
 	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
 	TripTrackID uuid.UUID `gorm:"type:uuid;not null;index"`
+	ChunkSeq    int       `gorm:"not null;default:0"`
 	Latitude    float64   `gorm:"type:double precision;not null"`
 	Longitude   float64   `gorm:"type:double precision;not null"`
 	Speed       float64   `gorm:"type:decimal(6,2)"`
 	Heading     float64   `gorm:"type:decimal(5,2)"`
 	RecordedAt  time.Time `gorm:"type:timestamptz;not null"`
+	Backfilled  bool      `gorm:"not null;default:false"`
+	AccuracyM   float64   `gorm:"type:double precision;not null;default:0"`
+	AltitudeM   *float64  `gorm:"type:double precision"`
+	Provider    string    `gorm:"type:varchar(16);not null;default:''"`
 	CreatedAt   time.Time `gorm:"type:timestamptz;not null;default:now()"`
+	// Location is generated by the database from Latitude and Longitude, so it is
+	// neither written nor read through the model.
+	Location    string    `gorm:"type:geography(Point,4326) GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography) STORED;->:false;<-:false"`
 }
 
 // TableName overrides the default table name.
@@ -50,6 +79,9 @@ func (WaypointModel) TableName() string {
 	return "waypoints"
 }
 
+// inProgressStatuses are the statuses of trips that have not ended.
+var inProgressStatuses = []string{string(trackingDomain.TrackingActive), string(trackingDomain.TrackingPaused)}
+
 // GORMTripTrackRepository implements TripTrackRepository using GORM.
 type GORMTripTrackRepository struct {
 	db     *gorm.DB
@@ -88,11 +120,12 @@ func (r *GORMTripTrackRepository) FindByBookingID(ctx context.Context, bookingID
 	return toDomain(&model), nil
 }
 
-// FindActiveByRunnerID retrieves the currently active trip track for a runner.
+// FindActiveByRunnerID retrieves the oldest active trip track for a runner.
 func (r *GORMTripTrackRepository) FindActiveByRunnerID(ctx context.Context, runnerID uuid.UUID) (*trackingDomain.TripTrack, error) {
 	var model TripTrackModel
 	if err := r.db.WithContext(ctx).
-		Where("runner_id = ? AND status = ?", runnerID, string(trackingDomain.TrackingActive)).
+		Where("runner_id = ? AND status IN ?", runnerID, inProgressStatuses).
+		Order("started_at ASC").
 		First(&model).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrNotFound
@@ -102,6 +135,133 @@ func (r *GORMTripTrackRepository) FindActiveByRunnerID(ctx context.Context, runn
 	return toDomain(&model), nil
 }
 
+// FindAllActiveByRunnerID retrieves all active trip tracks for a runner, oldest first.
+func (r *GORMTripTrackRepository) FindAllActiveByRunnerID(ctx context.Context, runnerID uuid.UUID) ([]*trackingDomain.TripTrack, error) {
+	var models []TripTrackModel
+	if err := r.db.WithContext(ctx).
+		Where("runner_id = ? AND status IN ?", runnerID, inProgressStatuses).
+		Order("started_at ASC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find active trip tracks for runner: %w", err)
+	}
+
+	tracks := make([]*trackingDomain.TripTrack, len(models))
+	for i := range models {
+		tracks[i] = toDomain(&models[i])
+	}
+	return tracks, nil
+}
+
+// FindByRunnerIDSince retrieves a runner's trip tracks that were active at any time
+// since the given moment, oldest first.
+func (r *GORMTripTrackRepository) FindByRunnerIDSince(ctx context.Context, runnerID uuid.UUID, since time.Time) ([]*trackingDomain.TripTrack, error) {
+	var models []TripTrackModel
+	if err := r.db.WithContext(ctx).
+		Where("runner_id = ?", runnerID).
+		Where("status IN ? OR completed_at >= ? OR cancelled_at >= ?", inProgressStatuses, since, since).
+		Order("started_at ASC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find trip tracks for runner: %w", err)
+	}
+
+	tracks := make([]*trackingDomain.TripTrack, len(models))
+	for i := range models {
+		tracks[i] = toDomain(&models[i])
+	}
+	return tracks, nil
+}
+
+// FindRunnerIDsActiveBetween returns the runners with a trip track that was active at
+// any time between from and to.
+func (r *GORMTripTrackRepository) FindRunnerIDsActiveBetween(ctx context.Context, from, to time.Time) ([]uuid.UUID, error) {
+	var runnerIDs []uuid.UUID
+	if err := r.db.WithContext(ctx).
+		Model(&TripTrackModel{}).
+		Where("started_at < ?", to).
+		Where("status IN ? OR completed_at >= ? OR cancelled_at >= ?", inProgressStatuses, from, from).
+		Distinct("runner_id").
+		Pluck("runner_id", &runnerIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find active runners: %w", err)
+	}
+	return runnerIDs, nil
+}
+
+// FindActiveBookingIDsByRegion returns the bookings with an active trip track in a region.
+func (r *GORMTripTrackRepository) FindActiveBookingIDsByRegion(ctx context.Context, region string) ([]uuid.UUID, error) {
+	var bookingIDs []uuid.UUID
+	if err := r.db.WithContext(ctx).
+		Model(&TripTrackModel{}).
+		Where("region = ? AND status IN ?", region, inProgressStatuses).
+		Pluck("booking_id", &bookingIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find active bookings in region: %w", err)
+	}
+	return bookingIDs, nil
+}
+
+// listSortColumns maps list sorts to the trip_tracks column they order by.
+var listSortColumns = map[trackingDomain.ListSort]string{
+	trackingDomain.ListSortStartedAt: "started_at",
+	trackingDomain.ListSortDistance:  "total_distance_km",
+}
+
+// List retrieves the trip tracks matching the query built from opts. Pages continue
+// from the cursor by comparing (sort column, id), so tracks added meanwhile do not
+// shift later pages.
+func (r *GORMTripTrackRepository) List(ctx context.Context, opts ...trackingDomain.ListOption) ([]*trackingDomain.TripTrack, error) {
+	q := trackingDomain.NewListQuery(opts...)
+	column, ok := listSortColumns[q.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown list sort %q", q.Sort)
+	}
+
+	db := r.db.WithContext(ctx).Model(&TripTrackModel{})
+	if len(q.Statuses) > 0 {
+		statuses := make([]string, len(q.Statuses))
+		for i, s := range q.Statuses {
+			statuses[i] = string(s)
+		}
+		db = db.Where("status IN ?", statuses)
+	}
+	if q.RunnerID != nil {
+		db = db.Where("runner_id = ?", *q.RunnerID)
+	}
+	if !q.StartedFrom.IsZero() {
+		db = db.Where("started_at >= ?", q.StartedFrom)
+	}
+	if !q.StartedTo.IsZero() {
+		db = db.Where("started_at < ?", q.StartedTo)
+	}
+	if q.MinDistanceKm > 0 {
+		db = db.Where("total_distance_km >= ?", q.MinDistanceKm)
+	}
+
+	direction, cmp := "ASC", ">"
+	if q.Descending {
+		direction, cmp = "DESC", "<"
+	}
+	if q.After != nil {
+		var value interface{} = q.After.StartedAt
+		if q.Sort == trackingDomain.ListSortDistance {
+			value = q.After.DistanceKm
+		}
+		db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, cmp), value, q.After.ID)
+	}
+
+	var models []TripTrackModel
+	if err := db.
+		Order(fmt.Sprintf("%s %s, id %s", column, direction, direction)).
+		Limit(q.Limit).
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list trip tracks: %w", err)
+	}
+
+	tracks := make([]*trackingDomain.TripTrack, len(models))
+	for i := range models {
+		tracks[i] = toDomain(&models[i])
+	}
+	return tracks, nil
+}
+
 // Save persists a new trip track.
 func (r *GORMTripTrackRepository) Save(ctx context.Context, track *trackingDomain.TripTrack) error {
 	model := toModel(track)
@@ -114,7 +274,20 @@ func (r *GORMTripTrackRepository) Save(ctx context.Context, track *trackingDomai
 // Update persists changes to an existing trip track.
 func (r *GORMTripTrackRepository) Update(ctx context.Context, track *trackingDomain.TripTrack) error {
 	model := toModel(track)
+	// The last position is only written by the running distance methods, the device
+	// status by UpdateDeviceStatus and the movement by the stall detection methods. A
+	// trip in progress accumulates its distance in the database as waypoints arrive, so
+	// the copy loaded with the track may already be stale.
+	omit := []string{
+		"last_latitude", "last_longitude", "last_recorded_at",
+		"device_battery", "device_network", "device_updated_at",
+		"moved_latitude", "moved_longitude", "moved_at", "stalled_at",
+	}
+	if track.IsActive() {
+		omit = append(omit, "total_distance_km")
+	}
 	result := r.db.WithContext(ctx).
+		Omit(omit...).
 		Where("id = ? AND version = ?", model.ID, model.Version-1).
 		Save(model)
 
@@ -127,24 +300,391 @@ func (r *GORMTripTrackRepository) Update(ctx context.Context, track *trackingDom
 	return nil
 }
 
+// AdvanceDistance adds the geodesic distance from the track's last position to
+// waypoint to its running total and makes waypoint the last position. The track row
+// is locked while the step is measured, so concurrent writers cannot lose an update.
+func (r *GORMTripTrackRepository) AdvanceDistance(ctx context.Context, trackID uuid.UUID, waypoint trackingDomain.Waypoint) (float64, bool, error) {
+	var steps []float64
+	if err := r.db.WithContext(ctx).Raw(`
+		WITH step AS (
+			SELECT id, COALESCE(ST_Distance(
+				ST_SetSRID(ST_MakePoint(last_longitude, last_latitude), 4326)::geography,
+				ST_SetSRID(ST_MakePoint(@lng, @lat), 4326)::geography
+			) / 1000, 0) AS km
+			FROM trip_tracks
+			WHERE id = @track AND status IN @statuses
+				AND (last_recorded_at IS NULL OR last_recorded_at <= @at)
+			FOR UPDATE
+		)
+		UPDATE trip_tracks t SET
+			total_distance_km = t.total_distance_km + step.km,
+			last_latitude = @lat,
+			last_longitude = @lng,
+			last_recorded_at = @at
+		FROM step
+		WHERE t.id = step.id
+		RETURNING step.km`,
+		map[string]interface{}{
+			"track":    trackID,
+			"lat":      waypoint.Latitude,
+			"lng":      waypoint.Longitude,
+			"at":       waypoint.RecordedAt,
+			"statuses": inProgressStatuses,
+		}).Scan(&steps).Error; err != nil {
+		return 0, false, fmt.Errorf("failed to advance trip distance: %w", err)
+	}
+	if len(steps) == 0 {
+		return 0, false, nil
+	}
+	return steps[0], true, nil
+}
+
+// AddDistance adds km to the running total of a trip in progress.
+func (r *GORMTripTrackRepository) AddDistance(ctx context.Context, trackID uuid.UUID, km float64) error {
+	if err := r.db.WithContext(ctx).
+		Model(&TripTrackModel{}).
+		Where("id = ? AND status IN ?", trackID, inProgressStatuses).
+		UpdateColumn("total_distance_km", gorm.Expr("total_distance_km + ?", km)).Error; err != nil {
+		return fmt.Errorf("failed to add trip distance: %w", err)
+	}
+	return nil
+}
+
+// UpdateDeviceStatus records the runner's phone status unless a later one is stored.
+func (r *GORMTripTrackRepository) UpdateDeviceStatus(ctx context.Context, trackID uuid.UUID, status trackingDomain.DeviceStatus) error {
+	columns := map[string]interface{}{"device_updated_at": status.ReportedAt}
+	if status.BatteryPct != nil {
+		columns["device_battery"] = *status.BatteryPct
+	}
+	if status.Network != "" {
+		columns["device_network"] = status.Network
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&TripTrackModel{}).
+		Where("id = ? AND (device_updated_at IS NULL OR device_updated_at <= ?)", trackID, status.ReportedAt).
+		UpdateColumns(columns).Error; err != nil {
+		return fmt.Errorf("failed to update device status: %w", err)
+	}
+	return nil
+}
+
+// RecordMovement records the runner's latest meaningful movement and clears the stall
+// mark, unless a later movement is stored.
+func (r *GORMTripTrackRepository) RecordMovement(ctx context.Context, trackID uuid.UUID, m trackingDomain.Movement) error {
+	if err := r.db.WithContext(ctx).
+		Model(&TripTrackModel{}).
+		Where("id = ? AND (moved_at IS NULL OR moved_at <= ?)", trackID, m.At).
+		UpdateColumns(map[string]interface{}{
+			"moved_latitude":  m.Latitude,
+			"moved_longitude": m.Longitude,
+			"moved_at":        m.At,
+			"stalled_at":      nil,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to record movement: %w", err)
+	}
+	return nil
+}
+
+// MarkStalled marks an active trip without movement since movedBefore as stalled, if
+// it is not marked yet.
+func (r *GORMTripTrackRepository) MarkStalled(ctx context.Context, trackID uuid.UUID, at, movedBefore time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&TripTrackModel{}).
+		Where("id = ? AND status = ? AND stalled_at IS NULL AND moved_at < ?", trackID, string(trackingDomain.TrackingActive), movedBefore).
+		UpdateColumn("stalled_at", at)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark trip stalled: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindStallCandidates retrieves the active trips without movement since movedBefore
+// that are not marked stalled.
+func (r *GORMTripTrackRepository) FindStallCandidates(ctx context.Context, movedBefore time.Time) ([]*trackingDomain.TripTrack, error) {
+	var models []TripTrackModel
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND stalled_at IS NULL AND moved_at < ?", string(trackingDomain.TrackingActive), movedBefore).
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find stall candidates: %w", err)
+	}
+
+	tracks := make([]*trackingDomain.TripTrack, len(models))
+	for i := range models {
+		tracks[i] = toDomain(&models[i])
+	}
+	return tracks, nil
+}
+
+// FindStalled retrieves the active trips marked stalled, longest without movement first.
+func (r *GORMTripTrackRepository) FindStalled(ctx context.Context) ([]*trackingDomain.TripTrack, error) {
+	var models []TripTrackModel
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND stalled_at IS NOT NULL", string(trackingDomain.TrackingActive)).
+		Order("moved_at ASC, id ASC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find stalled trips: %w", err)
+	}
+
+	tracks := make([]*trackingDomain.TripTrack, len(models))
+	for i := range models {
+		tracks[i] = toDomain(&models[i])
+	}
+	return tracks, nil
+}
+
+// ResetDistance overwrites a track's running total and last position.
+func (r *GORMTripTrackRepository) ResetDistance(ctx context.Context, trackID uuid.UUID, km float64, last *trackingDomain.Waypoint) error {
+	columns := map[string]interface{}{
+		"total_distance_km": km,
+		"last_latitude":     nil,
+		"last_longitude":    nil,
+		"last_recorded_at":  nil,
+	}
+	if last != nil {
+		columns["last_latitude"] = last.Latitude
+		columns["last_longitude"] = last.Longitude
+		columns["last_recorded_at"] = last.RecordedAt
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&TripTrackModel{}).
+		Where("id = ?", trackID).
+		UpdateColumns(columns).Error; err != nil {
+		return fmt.Errorf("failed to reset trip distance: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a trip track and its waypoints.
+func (r *GORMTripTrackRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&WaypointModel{}, "trip_track_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&WaypointChunkModel{}, "trip_track_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&TripTrackModel{}, "id = ?", id).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete trip track: %w", err)
+	}
+	return nil
+}
+
+// MoveWaypoints moves all waypoints of one trip track to another in one transaction.
+// Interleaving two tracks' waypoints breaks the time order of the target's chunks, so
+// its chunks are rebuilt from scratch in recorded_at order.
+func (r *GORMTripTrackRepository) MoveWaypoints(ctx context.Context, fromTrackID, toTrackID uuid.UUID) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock both tracks in a fixed order, as assignChunks does.
+		ids := []uuid.UUID{fromTrackID, toTrackID}
+		if ids[1].String() < ids[0].String() {
+			ids[0], ids[1] = ids[1], ids[0]
+		}
+		for _, id := range ids {
+			var track TripTrackModel
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Select("id").
+				First(&track, "id = ?", id).Error; err != nil {
+				return fmt.Errorf("failed to lock trip track %s: %w", id, err)
+			}
+		}
+
+		if err := tx.Model(&WaypointModel{}).
+			Where("trip_track_id = ?", fromTrackID).
+			Update("trip_track_id", toTrackID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&WaypointChunkModel{}, "trip_track_id = ?", fromTrackID).Error; err != nil {
+			return err
+		}
+		return rebuildChunks(tx, toTrackID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move waypoints: %w", err)
+	}
+	return nil
+}
+
+// EraseLocations deletes a trip track's waypoints and chunks and clears the positions
+// stored on the track in one transaction.
+func (r *GORMTripTrackRepository) EraseLocations(ctx context.Context, trackID uuid.UUID) (int, error) {
+	var deleted int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&WaypointModel{}, "trip_track_id = ?", trackID)
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = int(result.RowsAffected)
+		if err := tx.Delete(&WaypointChunkModel{}, "trip_track_id = ?", trackID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&TripTrackModel{}).
+			Where("id = ?", trackID).
+			UpdateColumns(map[string]interface{}{
+				"last_latitude":    nil,
+				"last_longitude":   nil,
+				"last_recorded_at": nil,
+				"dest_latitude":    nil,
+				"dest_longitude":   nil,
+				"moved_latitude":   nil,
+				"moved_longitude":  nil,
+			}).Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to erase trip track locations: %w", err)
+	}
+	return deleted, nil
+}
+
+// CountWaypoints returns the number of waypoints stored for a trip track, summed from
+// its chunks.
+func (r *GORMTripTrackRepository) CountWaypoints(ctx context.Context, trackID uuid.UUID) (int, error) {
+	var count int
+	if err := r.db.WithContext(ctx).
+		Model(&WaypointChunkModel{}).
+		Where("trip_track_id = ?", trackID).
+		Select("COALESCE(SUM(point_count), 0)").
+		Scan(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count waypoints: %w", err)
+	}
+	return count, nil
+}
+
+// DownsampleWaypoints thins all but the keepRecent latest waypoints of a trip track to
+// every factor-th one, always keeping the first, and rebuilds its chunks.
+func (r *GORMTripTrackRepository) DownsampleWaypoints(ctx context.Context, trackID uuid.UUID, keepRecent, factor int) error {
+	if factor < 2 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var track TripTrackModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&track, "id = ?", trackID).Error; err != nil {
+			return fmt.Errorf("failed to lock trip track %s: %w", trackID, err)
+		}
+
+		if err := tx.Exec(`
+			DELETE FROM waypoints WHERE id IN (
+				SELECT id FROM (
+					SELECT id,
+						ROW_NUMBER() OVER (ORDER BY recorded_at, id) - 1 AS n,
+						ROW_NUMBER() OVER (ORDER BY recorded_at DESC, id DESC) AS from_end
+					FROM waypoints WHERE trip_track_id = @track
+				) w
+				WHERE from_end > @keep AND n % @factor <> 0
+			)`,
+			map[string]interface{}{"track": trackID, "keep": keepRecent, "factor": factor}).Error; err != nil {
+			return err
+		}
+		return rebuildChunks(tx, trackID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to downsample waypoints: %w", err)
+	}
+	return nil
+}
+
+// rebuildChunks reassigns a trip track's waypoints to chunks of WaypointChunkSize in
+// recorded_at order and rewrites the chunk extents, e.g. after waypoints were moved or
+// removed. The track must be locked by the caller.
+func rebuildChunks(tx *gorm.DB, trackID uuid.UUID) error {
+	if err := tx.Delete(&WaypointChunkModel{}, "trip_track_id = ?", trackID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec(`
+		UPDATE waypoints w SET chunk_seq = s.seq
+		FROM (
+			SELECT id, (ROW_NUMBER() OVER (ORDER BY recorded_at, id) - 1) / @size AS seq
+			FROM waypoints WHERE trip_track_id = @track
+		) s
+		WHERE w.id = s.id`,
+		map[string]interface{}{"track": trackID, "size": trackingDomain.WaypointChunkSize}).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`
+		INSERT INTO waypoint_chunks
+			(trip_track_id, seq, point_count, min_latitude, min_longitude, max_latitude, max_longitude, started_at, ended_at)
+		SELECT trip_track_id, chunk_seq, COUNT(*), MIN(latitude), MIN(longitude), MAX(latitude), MAX(longitude),
+			MIN(recorded_at), MAX(recorded_at)
+		FROM waypoints WHERE trip_track_id = ?
+		GROUP BY trip_track_id, chunk_seq`, trackID).Error
+}
+
 // AddWaypoint records a new GPS waypoint for a trip track.
 func (r *GORMTripTrackRepository) AddWaypoint(ctx context.Context, trackID uuid.UUID, waypoint trackingDomain.Waypoint) error {
-	model := &WaypointModel{
-		ID:          waypoint.ID,
-		TripTrackID: trackID,
-		Latitude:    waypoint.Latitude,
-		Longitude:   waypoint.Longitude,
-		Speed:       waypoint.Speed,
-		Heading:     waypoint.Heading,
-		RecordedAt:  waypoint.RecordedAt,
-		CreatedAt:   time.Now().UTC(),
-	}
-	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+	models := []WaypointModel{toWaypointModel(trackID, waypoint)}
+	if err := r.insertWaypoints(ctx, models, 1); err != nil {
 		return fmt.Errorf("failed to add waypoint: %w", err)
 	}
 	return nil
 }
 
+// BackfillWaypoints stores waypoints uploaded after the fact, in time order, marked as
+// backfilled. When they were recorded before the end of the track's latest chunk its
+// chunks are rebuilt so they stay in time order; otherwise they are appended as usual.
+func (r *GORMTripTrackRepository) BackfillWaypoints(ctx context.Context, trackID uuid.UUID, waypoints []trackingDomain.Waypoint) error {
+	if len(waypoints) == 0 {
+		return nil
+	}
+	models := make([]WaypointModel, len(waypoints))
+	for i, w := range waypoints {
+		w.Backfilled = true
+		models[i] = toWaypointModel(trackID, w)
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var track TripTrackModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&track, "id = ?", trackID).Error; err != nil {
+			return fmt.Errorf("failed to lock trip track %s: %w", trackID, err)
+		}
+
+		var endedAt *time.Time
+		if err := tx.Model(&WaypointChunkModel{}).
+			Where("trip_track_id = ?", trackID).
+			Select("MAX(ended_at)").
+			Scan(&endedAt).Error; err != nil {
+			return fmt.Errorf("failed to load latest waypoint chunk: %w", err)
+		}
+		if endedAt == nil || !models[0].RecordedAt.Before(*endedAt) {
+			if err := assignChunks(tx, models); err != nil {
+				return err
+			}
+			return tx.CreateInBatches(models, len(models)).Error
+		}
+
+		if err := tx.CreateInBatches(models, len(models)).Error; err != nil {
+			return err
+		}
+		return rebuildChunks(tx, trackID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to backfill waypoints: %w", err)
+	}
+	return nil
+}
+
+// insertWaypoints assigns waypoints to chunks and writes them using multi-row inserts of
+// at most batchSize rows, in one transaction.
+func (r *GORMTripTrackRepository) insertWaypoints(ctx context.Context, models []WaypointModel, batchSize int) error {
+	if len(models) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := assignChunks(tx, models); err != nil {
+			return err
+		}
+		return tx.CreateInBatches(models, batchSize).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert waypoints: %w", err)
+	}
+	return nil
+}
+
 // GetWaypoints retrieves all waypoints for a trip track ordered by time.
 func (r *GORMTripTrackRepository) GetWaypoints(ctx context.Context, trackID uuid.UUID) ([]trackingDomain.Waypoint, error) {
 	var models []WaypointModel
@@ -155,18 +695,58 @@ func (r *GORMTripTrackRepository) GetWaypoints(ctx context.Context, trackID uuid
 		return nil, fmt.Errorf("failed to get waypoints: %w", err)
 	}
 
-	waypoints := make([]trackingDomain.Waypoint, len(models))
-	for i, m := range models {
-		waypoints[i] = trackingDomain.Waypoint{
-			ID:         m.ID,
-			Latitude:   m.Latitude,
-			Longitude:  m.Longitude,
-			Speed:      m.Speed,
-			Heading:    m.Heading,
-			RecordedAt: m.RecordedAt,
+	return toWaypoints(models), nil
+}
+
+// ListWaypoints retrieves a page of a trip track's waypoints, continuing after the
+// cursor's (recorded_at, id) so each page is an index range scan.
+func (r *GORMTripTrackRepository) ListWaypoints(ctx context.Context, trackID uuid.UUID, q trackingDomain.WaypointPageQuery) ([]trackingDomain.Waypoint, error) {
+	db := r.db.WithContext(ctx).Where("trip_track_id = ?", trackID)
+	if !q.From.IsZero() {
+		db = db.Where("recorded_at >= ?", q.From)
+	}
+	if !q.To.IsZero() {
+		db = db.Where("recorded_at < ?", q.To)
+	}
+	if q.After != nil {
+		db = db.Where("(recorded_at, id) > (?, ?)", q.After.RecordedAt, q.After.ID)
+	}
+
+	if q.Limit > 0 {
+		db = db.Limit(q.Limit)
+	}
+
+	var models []WaypointModel
+	if err := db.Order("recorded_at ASC, id ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list waypoints: %w", err)
+	}
+	return toWaypoints(models), nil
+}
+
+// EachWaypointBatch reads a trip track's waypoints in batches of batchSize, continuing
+// after the last (recorded_at, id) of each batch so every batch is an index range scan.
+func (r *GORMTripTrackRepository) EachWaypointBatch(ctx context.Context, trackID uuid.UUID, batchSize int, fn func([]trackingDomain.Waypoint) error) error {
+	var last *WaypointModel
+	for {
+		q := r.db.WithContext(ctx).Where("trip_track_id = ?", trackID)
+		if last != nil {
+			q = q.Where("(recorded_at, id) > (?, ?)", last.RecordedAt, last.ID)
+		}
+		var models []WaypointModel
+		if err := q.Order("recorded_at ASC, id ASC").Limit(batchSize).Find(&models).Error; err != nil {
+			return fmt.Errorf("failed to get waypoints: %w", err)
+		}
+		if len(models) == 0 {
+			return nil
 		}
+		if err := fn(toWaypoints(models)); err != nil {
+			return err
+		}
+		if len(models) < batchSize {
+			return nil
+		}
+		last = &models[len(models)-1]
 	}
-	return waypoints, nil
 }
 
 // GetRouteAsGeoJSON returns the trip route as a GeoJSON LineString.
@@ -176,7 +756,7 @@ func (r *GORMTripTrackRepository) GetRouteAsGeoJSON(ctx context.Context, trackID
 	var geoJSON string
 	err := r.db.WithContext(ctx).Raw(`
 		SELECT ST_AsGeoJSON(ST_MakeLine(
-			ST_MakePoint(w.longitude, w.latitude) ORDER BY w.recorded_at
+			w.location::geometry ORDER BY w.recorded_at
 		)) FROM waypoints w WHERE w.trip_track_id = ?
 	`, trackID).Scan(&geoJSON).Error
 
@@ -194,42 +774,174 @@ func (r *GORMTripTrackRepository) GetRouteAsGeoJSON(ctx context.Context, trackID
 		return "", err
 	}
 
-	return buildGeoJSONLineString(waypoints)
+	return trackingDomain.LineStringGeoJSON(waypoints)
 }
 
-// buildGeoJSONLineString constructs a GeoJSON LineString from waypoints.
-func buildGeoJSONLineString(waypoints []trackingDomain.Waypoint) (string, error) {
-	if len(waypoints) == 0 {
-		return `{"type":"LineString","coordinates":[]}`, nil
+// GetRouteLengthKm returns the geodesic length in kilometers of the route through the
+// waypoints recorded in [from, to), computed by PostGIS. A zero from or to leaves that
+// side of the window open.
+func (r *GORMTripTrackRepository) GetRouteLengthKm(ctx context.Context, trackID uuid.UUID, from, to time.Time) (float64, error) {
+	query := r.db.WithContext(ctx).Model(&WaypointModel{}).
+		Select("COALESCE(ST_Length(ST_MakeLine(location::geometry ORDER BY recorded_at, id)::geography), 0) / 1000").
+		Where("trip_track_id = ?", trackID)
+	if !from.IsZero() {
+		query = query.Where("recorded_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("recorded_at < ?", to)
 	}
 
-	coordinates := make([][]float64, len(waypoints))
-	for i, wp := range waypoints {
-		coordinates[i] = []float64{wp.Longitude, wp.Latitude}
+	var km float64
+	if err := query.Scan(&km).Error; err != nil {
+		return 0, fmt.Errorf("failed to compute route length: %w", err)
 	}
+	return km, nil
+}
+
+// waypointStatsQuery aggregates a trip's waypoints in one pass. Stationary runs are
+// found as gaps-and-islands: consecutive stationary waypoints share rn minus their
+// rank among stationary waypoints.
+const waypointStatsQuery = `
+WITH w AS (
+	SELECT
+		id, recorded_at, speed, location,
+		speed <= @stop_speed AS stationary,
+		LEAD(recorded_at) OVER (ORDER BY recorded_at, id) AS next_at,
+		ROW_NUMBER() OVER (ORDER BY recorded_at, id) AS rn,
+		FIRST_VALUE(location) OVER (ORDER BY recorded_at, id) AS origin
+	FROM waypoints
+	WHERE trip_track_id = @track_id
+),
+runs AS (
+	SELECT recorded_at, rn - ROW_NUMBER() OVER (ORDER BY rn) AS grp
+	FROM w
+	WHERE stationary
+),
+left_pickup AS (
+	SELECT MIN(recorded_at) AS at FROM w WHERE NOT ST_DWithin(location, origin, @pickup_radius)
+)
+SELECT
+	COUNT(*) AS waypoint_count,
+	MIN(recorded_at) AS first_at,
+	MAX(recorded_at) AS last_at,
+	COALESCE(ST_Length(ST_MakeLine(location::geometry ORDER BY recorded_at, id)::geography), 0) / 1000 AS distance_km,
+	COALESCE(MAX(speed), 0) AS max_speed_kmh,
+	COALESCE(SUM(EXTRACT(EPOCH FROM next_at - recorded_at)) FILTER (WHERE stationary), 0) AS idle_seconds,
+	COALESCE((
+		SELECT MAX(EXTRACT(EPOCH FROM run_end - run_start))
+		FROM (SELECT MIN(recorded_at) AS run_start, MAX(recorded_at) AS run_end FROM runs GROUP BY grp) r
+	), 0) AS longest_stop_seconds,
+	COALESCE(EXTRACT(EPOCH FROM COALESCE((SELECT at FROM left_pickup), MAX(recorded_at)) - MIN(recorded_at)), 0) AS pickup_seconds
+FROM w`
+
+// waypointStatsRow is the result row of waypointStatsQuery.
+type waypointStatsRow struct {
+	WaypointCount      int
+	FirstAt            *time.Time
+	LastAt             *time.Time
+	DistanceKm         float64
+	MaxSpeedKmh        float64
+	IdleSeconds        float64
+	LongestStopSeconds float64
+	PickupSeconds      float64
+}
 
-	geoJSON := map[string]interface{}{
-		"type":        "LineString",
-		"coordinates": coordinates,
+// GetWaypointStats aggregates a trip's waypoints in the database.
+func (r *GORMTripTrackRepository) GetWaypointStats(ctx context.Context, trackID uuid.UUID, opts trackingDomain.WaypointStatsOptions) (*trackingDomain.WaypointStats, error) {
+	var row waypointStatsRow
+	if err := r.db.WithContext(ctx).Raw(waypointStatsQuery, map[string]interface{}{
+		"track_id":      trackID,
+		"stop_speed":    opts.StopSpeedKmh,
+		"pickup_radius": opts.PickupRadiusMeters,
+	}).Scan(&row).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute waypoint stats: %w", err)
 	}
 
-	data, err := json.Marshal(geoJSON)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal GeoJSON: %w", err)
+	stats := &trackingDomain.WaypointStats{
+		WaypointCount: row.WaypointCount,
+		DistanceKm:    row.DistanceKm,
+		MaxSpeedKmh:   row.MaxSpeedKmh,
+		Idle:          secondsToDuration(row.IdleSeconds),
+		LongestStop:   secondsToDuration(row.LongestStopSeconds),
+		TimeAtPickup:  secondsToDuration(row.PickupSeconds),
 	}
-	return string(data), nil
+	if row.FirstAt != nil && row.LastAt != nil {
+		stats.FirstAt, stats.LastAt = *row.FirstAt, *row.LastAt
+	}
+	return stats, nil
+}
+
+// secondsToDuration converts fractional seconds to a Duration.
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
 }
 
 // toDomain converts a GORM model to a domain TripTrack.
 func toDomain(model *TripTrackModel) *trackingDomain.TripTrack {
+	var destination *trackingDomain.Location
+	if model.DestLatitude != nil && model.DestLongitude != nil {
+		destination = &trackingDomain.Location{
+			Latitude:  *model.DestLatitude,
+			Longitude: *model.DestLongitude,
+		}
+	}
+
+	var cancellation *trackingDomain.Cancellation
+	if model.CancelReason != nil && model.CancelledAt != nil {
+		cancellation = &trackingDomain.Cancellation{
+			Reason:      trackingDomain.CancellationReason(*model.CancelReason),
+			CancelledAt: *model.CancelledAt,
+		}
+		if model.CancelNote != nil {
+			cancellation.Note = *model.CancelNote
+		}
+	}
+
+	var weather *trackingDomain.TripWeather
+	if model.StartWeather != nil || model.EndWeather != nil {
+		weather = &trackingDomain.TripWeather{
+			Start: fromWeatherJSON(model.StartWeather),
+			End:   fromWeatherJSON(model.EndWeather),
+		}
+	}
+
+	var device *trackingDomain.DeviceStatus
+	if model.DeviceUpdatedAt != nil {
+		device = &trackingDomain.DeviceStatus{
+			BatteryPct: model.DeviceBattery,
+			ReportedAt: *model.DeviceUpdatedAt,
+		}
+		if model.DeviceNetwork != nil {
+			device.Network = *model.DeviceNetwork
+		}
+	}
+
+	var movement *trackingDomain.Movement
+	if model.MovedAt != nil && model.MovedLatitude != nil && model.MovedLongitude != nil {
+		movement = &trackingDomain.Movement{
+			Latitude:  *model.MovedLatitude,
+			Longitude: *model.MovedLongitude,
+			At:        *model.MovedAt,
+		}
+	}
+
 	return trackingDomain.Reconstruct(
 		model.ID,
 		model.BookingID,
 		model.RunnerID,
+		model.Region,
 		trackingDomain.TrackingStatus(model.Status),
 		model.TotalDistanceKm,
+		destination,
 		model.StartedAt,
 		model.CompletedAt,
+		cancellation,
+		weather,
+		model.PausedAt,
+		time.Duration(model.PausedSeconds)*time.Second,
+		device,
+		movement,
+		model.StalledAt,
 		model.Version,
 		model.CreatedAt,
 		model.UpdatedAt,
@@ -238,16 +950,106 @@ func toDomain(model *TripTrackModel) *trackingDomain.TripTrack {
 
 // toModel converts a domain TripTrack to a GORM model.
 func toModel(track *trackingDomain.TripTrack) *TripTrackModel {
-	return &TripTrackModel{
+	model := &TripTrackModel{
 		ID:              track.ID(),
 		BookingID:       track.BookingID(),
 		RunnerID:        track.RunnerID(),
+		Region:          track.Region(),
 		Status:          string(track.Status()),
 		TotalDistanceKm: track.TotalDistanceKm(),
 		StartedAt:       track.StartedAt(),
 		CompletedAt:     track.CompletedAt(),
+		PausedAt:        track.PausedAt(),
+		PausedSeconds:   int64(track.PausedDuration() / time.Second),
 		Version:         track.Version(),
 		CreatedAt:       track.CreatedAt(),
 		UpdatedAt:       track.UpdatedAt(),
 	}
+	if dest := track.Destination(); dest != nil {
+		model.DestLatitude = &dest.Latitude
+		model.DestLongitude = &dest.Longitude
+	}
+	if c := track.Cancellation(); c != nil {
+		reason := string(c.Reason)
+		model.CancelReason = &reason
+		model.CancelNote = &c.Note
+		model.CancelledAt = &c.CancelledAt
+	}
+	if w := track.Weather(); w != nil {
+		model.StartWeather = toWeatherJSON(w.Start)
+		model.EndWeather = toWeatherJSON(w.End)
+	}
+	return model
+}
+
+// weatherJSON is the stored form of a weather observation.
+type weatherJSON struct {
+	TemperatureC float64   `json:"temperature_c"`
+	FeelsLikeC   float64   `json:"feels_like_c"`
+	HumidityPct  float64   `json:"humidity_pct"`
+	Condition    string    `json:"condition"`
+	ObservedAt   time.Time `json:"observed_at"`
+}
+
+func toWeatherJSON(w *trackingDomain.Weather) *string {
+	if w == nil {
+		return nil
+	}
+	data, err := json.Marshal(weatherJSON(*w))
+	if err != nil {
+		return nil
+	}
+	s := string(data)
+	return &s
+}
+
+// fromWeatherJSON decodes a stored weather observation; unreadable values are dropped.
+func fromWeatherJSON(s *string) *trackingDomain.Weather {
+	if s == nil {
+		return nil
+	}
+	var w weatherJSON
+	if err := json.Unmarshal([]byte(*s), &w); err != nil {
+		return nil
+	}
+	weather := trackingDomain.Weather(w)
+	return &weather
+}
+
+// toWaypoints converts waypoint models to domain waypoints.
+func toWaypoints(models []WaypointModel) []trackingDomain.Waypoint {
+	waypoints := make([]trackingDomain.Waypoint, len(models))
+	for i, m := range models {
+		waypoints[i] = trackingDomain.Waypoint{
+			ID:         m.ID,
+			Latitude:   m.Latitude,
+			Longitude:  m.Longitude,
+			Speed:      m.Speed,
+			Heading:    m.Heading,
+			RecordedAt: m.RecordedAt,
+			Backfilled: m.Backfilled,
+			Accuracy:   m.AccuracyM,
+			Altitude:   m.AltitudeM,
+			Provider:   m.Provider,
+		}
+	}
+	return waypoints
+}
+
+// toWaypointModel converts a domain Waypoint to its GORM model.
+func toWaypointModel(trackID uuid.UUID, waypoint trackingDomain.Waypoint) WaypointModel {
+	return WaypointModel{
+		ID:          waypoint.ID,
+		TripTrackID: trackID,
+		Latitude:    waypoint.Latitude,
+		Longitude:   waypoint.Longitude,
+		Speed:       waypoint.Speed,
+		Heading:     waypoint.Heading,
+		RecordedAt:  waypoint.RecordedAt,
+		Backfilled:  waypoint.Backfilled,
+		AccuracyM:   waypoint.Accuracy,
+		AltitudeM:   waypoint.Altitude,
+		Provider:    waypoint.Provider,
+		CreatedAt:   time.Now().UTC(),
+	}
 }
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// maxPendingBatches bounds the write buffer; once this many batches are pending,
+// AddWaypoint flushes inline so that back-pressure shows up as write latency.
+const maxPendingBatches = 10
+
+// WaypointBatchConfig controls how buffered waypoints are flushed.
+type WaypointBatchConfig struct {
+	Size          int
+	FlushInterval time.Duration
+}
+
+// BufferedTripTrackRepository wraps GORMTripTrackRepository and buffers waypoint
+// writes, flushing them as multi-row inserts when a batch fills or the flush
+// interval elapses. Reads of waypoints flush first so they see buffered points.
+type BufferedTripTrackRepository struct {
+	*GORMTripTrackRepository
+	config WaypointBatchConfig
+
+	mu      sync.Mutex
+	pending []WaypointModel
+
+	flushMu sync.Mutex
+	kick    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewBufferedTripTrackRepository creates a buffered repository around repo.
+func NewBufferedTripTrackRepository(repo *GORMTripTrackRepository, config WaypointBatchConfig) *BufferedTripTrackRepository {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 500 * time.Millisecond
+	}
+	return &BufferedTripTrackRepository{
+		GORMTripTrackRepository: repo,
+		config:                  config,
+		pending:                 make([]WaypointModel, 0, config.Size),
+		kick:                    make(chan struct{}, 1),
+		stop:                    make(chan struct{}),
+		done:                    make(chan struct{}),
+	}
+}
+
+// Start runs the background flush loop until Close is called.
+func (r *BufferedTripTrackRepository) Start() {
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.config.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-r.kick:
+			case <-r.stop:
+				return
+			}
+			if err := r.Flush(context.Background()); err != nil {
+				r.logger.Error("failed to flush waypoint batch", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// Close stops the flush loop and writes any remaining buffered waypoints.
+func (r *BufferedTripTrackRepository) Close(ctx context.Context) error {
+	close(r.stop)
+	<-r.done
+	return r.Flush(ctx)
+}
+
+// AddWaypoint buffers a waypoint for the next batch insert.
+func (r *BufferedTripTrackRepository) AddWaypoint(ctx context.Context, trackID uuid.UUID, waypoint trackingDomain.Waypoint) error {
+	r.mu.Lock()
+	r.pending = append(r.pending, toWaypointModel(trackID, waypoint))
+	n := len(r.pending)
+	r.mu.Unlock()
+
+	if n >= r.config.Size*maxPendingBatches {
+		return r.Flush(ctx)
+	}
+	if n >= r.config.Size {
+		select {
+		case r.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush writes all buffered waypoints. On failure the batch is put back at the
+// front of the buffer unless that would exceed the buffer bound.
+func (r *BufferedTripTrackRepository) Flush(ctx context.Context) error {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = make([]WaypointModel, 0, r.config.Size)
+	r.mu.Unlock()
+
+	err := r.insertWaypoints(ctx, batch, r.config.Size)
+	if err == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	if len(batch)+len(r.pending) <= r.config.Size*maxPendingBatches {
+		r.pending = append(batch, r.pending...)
+	} else {
+		r.logger.Error("dropping waypoint batch after failed flush", zap.Int("count", len(batch)))
+	}
+	r.mu.Unlock()
+	return err
+}
+
+// GetWaypoints flushes buffered waypoints and retrieves all waypoints for a trip track.
+func (r *BufferedTripTrackRepository) GetWaypoints(ctx context.Context, trackID uuid.UUID) ([]trackingDomain.Waypoint, error) {
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return r.GORMTripTrackRepository.GetWaypoints(ctx, trackID)
+}
+
+// GetWaypointsBetween flushes buffered waypoints and retrieves the waypoints in a time window.
+func (r *BufferedTripTrackRepository) GetWaypointsBetween(ctx context.Context, trackID uuid.UUID, from, to time.Time) ([]trackingDomain.Waypoint, error) {
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return r.GORMTripTrackRepository.GetWaypointsBetween(ctx, trackID, from, to)
+}
+
+// GetChunksInBounds flushes buffered waypoints and retrieves the chunks intersecting a bounding box.
+func (r *BufferedTripTrackRepository) GetChunksInBounds(ctx context.Context, trackID uuid.UUID, box trackingDomain.BoundingBox) ([]trackingDomain.WaypointChunk, error) {
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return r.GORMTripTrackRepository.GetChunksInBounds(ctx, trackID, box)
+}
+
+// Delete flushes buffered waypoints before removing the track, so none are written
+// for it afterwards.
+func (r *BufferedTripTrackRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.Flush(ctx); err != nil {
+		return err
+	}
+	return r.GORMTripTrackRepository.Delete(ctx, id)
+}
+
+// MoveWaypoints flushes buffered waypoints and moves all waypoints of one trip track
+// to another.
+func (r *BufferedTripTrackRepository) MoveWaypoints(ctx context.Context, fromTrackID, toTrackID uuid.UUID) error {
+	if err := r.Flush(ctx); err != nil {
+		return err
+	}
+	return r.GORMTripTrackRepository.MoveWaypoints(ctx, fromTrackID, toTrackID)
+}
+
+// BackfillWaypoints flushes buffered waypoints and stores waypoints uploaded after the
+// fact, so the chunks they are merged into are complete.
+func (r *BufferedTripTrackRepository) BackfillWaypoints(ctx context.Context, trackID uuid.UUID, waypoints []trackingDomain.Waypoint) error {
+	if err := r.Flush(ctx); err != nil {
+		return err
+	}
+	return r.GORMTripTrackRepository.BackfillWaypoints(ctx, trackID, waypoints)
+}
+
+// EraseLocations flushes buffered waypoints before erasing a trip track's locations, so
+// none are written for it afterwards.
+func (r *BufferedTripTrackRepository) EraseLocations(ctx context.Context, trackID uuid.UUID) (int, error) {
+	if err := r.Flush(ctx); err != nil {
+		return 0, err
+	}
+	return r.GORMTripTrackRepository.EraseLocations(ctx, trackID)
+}
+
+// CountWaypoints flushes buffered waypoints and counts a trip track's waypoints.
+func (r *BufferedTripTrackRepository) CountWaypoints(ctx context.Context, trackID uuid.UUID) (int, error) {
+	if err := r.Flush(ctx); err != nil {
+		return 0, err
+	}
+	return r.GORMTripTrackRepository.CountWaypoints(ctx, trackID)
+}
+
+// DownsampleWaypoints flushes buffered waypoints and thins the older waypoints of a
+// trip track.
+func (r *BufferedTripTrackRepository) DownsampleWaypoints(ctx context.Context, trackID uuid.UUID, keepRecent, factor int) error {
+	if err := r.Flush(ctx); err != nil {
+		return err
+	}
+	return r.GORMTripTrackRepository.DownsampleWaypoints(ctx, trackID, keepRecent, factor)
+}
+
+// ListWaypoints flushes buffered waypoints and retrieves a page of a trip track's waypoints.
+func (r *BufferedTripTrackRepository) ListWaypoints(ctx context.Context, trackID uuid.UUID, q trackingDomain.WaypointPageQuery) ([]trackingDomain.Waypoint, error) {
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return r.GORMTripTrackRepository.ListWaypoints(ctx, trackID, q)
+}
+
+// EachWaypointBatch flushes buffered waypoints and reads a trip track's waypoints in
+// batches.
+func (r *BufferedTripTrackRepository) EachWaypointBatch(ctx context.Context, trackID uuid.UUID, batchSize int, fn func([]trackingDomain.Waypoint) error) error {
+	if err := r.Flush(ctx); err != nil {
+		return err
+	}
+	return r.GORMTripTrackRepository.EachWaypointBatch(ctx, trackID, batchSize, fn)
+}
+
+// GetRouteLengthKm flushes buffered waypoints and returns the length of the route in a time window.
+func (r *BufferedTripTrackRepository) GetRouteLengthKm(ctx context.Context, trackID uuid.UUID, from, to time.Time) (float64, error) {
+	if err := r.Flush(ctx); err != nil {
+		return 0, err
+	}
+	return r.GORMTripTrackRepository.GetRouteLengthKm(ctx, trackID, from, to)
+}
+
+// GetWaypointStats flushes buffered waypoints and aggregates a trip's waypoints.
+func (r *BufferedTripTrackRepository) GetWaypointStats(ctx context.Context, trackID uuid.UUID, opts trackingDomain.WaypointStatsOptions) (*trackingDomain.WaypointStats, error) {
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return r.GORMTripTrackRepository.GetWaypointStats(ctx, trackID, opts)
+}
+
+// GetWaypointHeatmap flushes buffered waypoints and bins the waypoints of all trips
+// matching q into geohash cells.
+func (r *BufferedTripTrackRepository) GetWaypointHeatmap(ctx context.Context, q trackingDomain.HeatmapQuery) ([]trackingDomain.HeatmapCell, error) {
+	if err := r.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return r.GORMTripTrackRepository.GetWaypointHeatmap(ctx, q)
+}
+
+// GetRouteAsGeoJSON flushes buffered waypoints and returns the trip route as GeoJSON.
+func (r *BufferedTripTrackRepository) GetRouteAsGeoJSON(ctx context.Context, trackID uuid.UUID) (string, error) {
+	if err := r.Flush(ctx); err != nil {
+		return "", err
+	}
+	return r.GORMTripTrackRepository.GetRouteAsGeoJSON(ctx, trackID)
+}
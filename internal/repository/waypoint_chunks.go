@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// WaypointChunkModel is the GORM model for the waypoint_chunks table. Each row describes
+// up to WaypointChunkSize waypoints of a track that share its chunk_seq.
+type WaypointChunkModel struct {
+	TripTrackID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Seq          int       `gorm:"primaryKey"`
+	PointCount   int       `gorm:"not null"`
+	MinLatitude  float64   `gorm:"type:double precision;not null"`
+	MinLongitude float64   `gorm:"type:double precision;not null"`
+	MaxLatitude  float64   `gorm:"type:double precision;not null"`
+	MaxLongitude float64   `gorm:"type:double precision;not null"`
+	StartedAt    time.Time `gorm:"type:timestamptz;not null"`
+	EndedAt      time.Time `gorm:"type:timestamptz;not null"`
+}
+
+// TableName overrides the default table name.
+func (WaypointChunkModel) TableName() string {
+	return "waypoint_chunks"
+}
+
+// extend grows the chunk's extents to include a waypoint.
+func (c *WaypointChunkModel) extend(w *WaypointModel) {
+	if c.PointCount == 0 {
+		c.MinLatitude, c.MaxLatitude = w.Latitude, w.Latitude
+		c.MinLongitude, c.MaxLongitude = w.Longitude, w.Longitude
+		c.StartedAt, c.EndedAt = w.RecordedAt, w.RecordedAt
+	} else {
+		c.MinLatitude = math.Min(c.MinLatitude, w.Latitude)
+		c.MaxLatitude = math.Max(c.MaxLatitude, w.Latitude)
+		c.MinLongitude = math.Min(c.MinLongitude, w.Longitude)
+		c.MaxLongitude = math.Max(c.MaxLongitude, w.Longitude)
+		if w.RecordedAt.Before(c.StartedAt) {
+			c.StartedAt = w.RecordedAt
+		}
+		if w.RecordedAt.After(c.EndedAt) {
+			c.EndedAt = w.RecordedAt
+		}
+	}
+	c.PointCount++
+}
+
+// assignChunks sets the chunk_seq of each waypoint, filling each track's latest chunk
+// before opening a new one, and writes the updated chunk extents. It locks each track
+// row so concurrent writers for the same track cannot assign the same slots.
+func assignChunks(tx *gorm.DB, models []WaypointModel) error {
+	byTrack := make(map[uuid.UUID][]int)
+	for i := range models {
+		byTrack[models[i].TripTrackID] = append(byTrack[models[i].TripTrackID], i)
+	}
+
+	// Lock tracks in a fixed order so concurrent batches cannot deadlock.
+	trackIDs := make([]uuid.UUID, 0, len(byTrack))
+	for id := range byTrack {
+		trackIDs = append(trackIDs, id)
+	}
+	sort.Slice(trackIDs, func(i, j int) bool { return trackIDs[i].String() < trackIDs[j].String() })
+
+	for _, trackID := range trackIDs {
+		var track TripTrackModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&track, "id = ?", trackID).Error; err != nil {
+			return fmt.Errorf("failed to lock trip track %s: %w", trackID, err)
+		}
+
+		var chunk WaypointChunkModel
+		err := tx.Where("trip_track_id = ?", trackID).Order("seq DESC").Limit(1).Find(&chunk).Error
+		if err != nil {
+			return fmt.Errorf("failed to load latest waypoint chunk: %w", err)
+		}
+		if chunk.TripTrackID == uuid.Nil {
+			chunk = WaypointChunkModel{TripTrackID: trackID}
+		}
+
+		touched := []WaypointChunkModel{}
+		for _, i := range byTrack[trackID] {
+			if chunk.PointCount >= trackingDomain.WaypointChunkSize {
+				touched = append(touched, chunk)
+				chunk = WaypointChunkModel{TripTrackID: trackID, Seq: chunk.Seq + 1}
+			}
+			chunk.extend(&models[i])
+			models[i].ChunkSeq = chunk.Seq
+		}
+		touched = append(touched, chunk)
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "trip_track_id"}, {Name: "seq"}},
+			UpdateAll: true,
+		}).Create(&touched).Error; err != nil {
+			return fmt.Errorf("failed to save waypoint chunks: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetWaypointsBetween retrieves the waypoints recorded between from and to, plus the
+// nearest waypoint on either side, reading only the chunks that overlap the window and
+// the chunks immediately before and after it.
+func (r *GORMTripTrackRepository) GetWaypointsBetween(ctx context.Context, trackID uuid.UUID, from, to time.Time) ([]trackingDomain.Waypoint, error) {
+	var seqs []int
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT seq FROM waypoint_chunks c
+		WHERE c.trip_track_id = @track AND (
+			(c.started_at <= @to AND c.ended_at >= @from)
+			OR c.seq = (SELECT MAX(seq) FROM waypoint_chunks WHERE trip_track_id = @track AND ended_at < @from)
+			OR c.seq = (SELECT MIN(seq) FROM waypoint_chunks WHERE trip_track_id = @track AND started_at > @to)
+		)
+	`, map[string]interface{}{"track": trackID, "from": from, "to": to}).Scan(&seqs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find waypoint chunks: %w", err)
+	}
+	if len(seqs) == 0 {
+		return []trackingDomain.Waypoint{}, nil
+	}
+
+	var models []WaypointModel
+	if err := r.db.WithContext(ctx).
+		Where("trip_track_id = ? AND chunk_seq IN ?", trackID, seqs).
+		Order("recorded_at ASC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to get waypoints: %w", err)
+	}
+
+	// Keep the window and one waypoint on either side of it.
+	lo := sort.Search(len(models), func(i int) bool { return !models[i].RecordedAt.Before(from) })
+	hi := sort.Search(len(models), func(i int) bool { return models[i].RecordedAt.After(to) })
+	if lo > 0 {
+		lo--
+	}
+	if hi < len(models) {
+		hi++
+	}
+	return toWaypoints(models[lo:hi]), nil
+}
+
+// GetChunksInBounds retrieves the waypoint chunks whose bounding box intersects box,
+// with their waypoints, ordered by sequence number.
+func (r *GORMTripTrackRepository) GetChunksInBounds(ctx context.Context, trackID uuid.UUID, box trackingDomain.BoundingBox) ([]trackingDomain.WaypointChunk, error) {
+	var chunkModels []WaypointChunkModel
+	if err := r.db.WithContext(ctx).
+		Where("trip_track_id = ?", trackID).
+		Where("min_latitude <= ? AND max_latitude >= ?", box.MaxLatitude, box.MinLatitude).
+		Where("min_longitude <= ? AND max_longitude >= ?", box.MaxLongitude, box.MinLongitude).
+		Order("seq ASC").
+		Find(&chunkModels).Error; err != nil {
+		return nil, fmt.Errorf("failed to find waypoint chunks: %w", err)
+	}
+	if len(chunkModels) == 0 {
+		return []trackingDomain.WaypointChunk{}, nil
+	}
+
+	seqs := make([]int, len(chunkModels))
+	for i, c := range chunkModels {
+		seqs[i] = c.Seq
+	}
+
+	var models []WaypointModel
+	if err := r.db.WithContext(ctx).
+		Where("trip_track_id = ? AND chunk_seq IN ?", trackID, seqs).
+		Order("recorded_at ASC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to get waypoints: %w", err)
+	}
+
+	bySeq := make(map[int][]WaypointModel, len(chunkModels))
+	for _, m := range models {
+		bySeq[m.ChunkSeq] = append(bySeq[m.ChunkSeq], m)
+	}
+
+	chunks := make([]trackingDomain.WaypointChunk, len(chunkModels))
+	for i, c := range chunkModels {
+		chunks[i] = trackingDomain.WaypointChunk{
+			Seq: c.Seq,
+			Bounds: trackingDomain.BoundingBox{
+				MinLatitude:  c.MinLatitude,
+				MinLongitude: c.MinLongitude,
+				MaxLatitude:  c.MaxLatitude,
+				MaxLongitude: c.MaxLongitude,
+			},
+			StartedAt: c.StartedAt,
+			EndedAt:   c.EndedAt,
+			Waypoints: toWaypoints(bySeq[c.Seq]),
+		}
+	}
+	return chunks, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// waypointHeatmapQuery bins waypoints into geohash cells. The chunks whose extents
+// overlap the box and time window are found first, so only their waypoints are read
+// through the (trip_track_id, chunk_seq) index. %s is replaced by the runner exclusion.
+const waypointHeatmapQuery = `
+WITH chunks AS (
+	SELECT c.trip_track_id, c.seq
+	FROM waypoint_chunks c
+	JOIN trip_tracks t ON t.id = c.trip_track_id
+	WHERE c.min_latitude <= @max_lat AND c.max_latitude >= @min_lat
+		AND c.min_longitude <= @max_lng AND c.max_longitude >= @min_lng
+		AND c.started_at < @to AND c.ended_at >= @from%s
+)
+SELECT ST_GeoHash(w.location::geometry, @precision) AS geohash,
+	COUNT(*) AS waypoints,
+	COUNT(DISTINCT w.trip_track_id) AS trips
+FROM chunks c
+JOIN waypoints w ON w.trip_track_id = c.trip_track_id AND w.chunk_seq = c.seq
+WHERE w.latitude BETWEEN @min_lat AND @max_lat
+	AND w.longitude BETWEEN @min_lng AND @max_lng
+	AND w.recorded_at >= @from AND w.recorded_at < @to
+GROUP BY 1
+ORDER BY waypoints DESC, geohash
+LIMIT @limit`
+
+// waypointHeatmapRow is a row of waypointHeatmapQuery.
+type waypointHeatmapRow struct {
+	Geohash   string
+	Waypoints int
+	Trips     int
+}
+
+// GetWaypointHeatmap bins the waypoints of all trips matching q into geohash cells,
+// computed by the database.
+func (r *GORMTripTrackRepository) GetWaypointHeatmap(ctx context.Context, q trackingDomain.HeatmapQuery) ([]trackingDomain.HeatmapCell, error) {
+	params := map[string]interface{}{
+		"min_lat":   q.Bounds.MinLatitude,
+		"min_lng":   q.Bounds.MinLongitude,
+		"max_lat":   q.Bounds.MaxLatitude,
+		"max_lng":   q.Bounds.MaxLongitude,
+		"from":      q.From,
+		"to":        q.To,
+		"precision": q.Precision,
+		"limit":     q.Limit,
+	}
+	// An empty NOT IN list would exclude every trip, so the clause is only added with
+	// runners to exclude.
+	exclude := ""
+	if len(q.ExcludeRunners) > 0 {
+		exclude = "\n\t\tAND t.runner_id NOT IN @exclude"
+		params["exclude"] = q.ExcludeRunners
+	}
+
+	var rows []waypointHeatmapRow
+	if err := r.db.WithContext(ctx).Raw(fmt.Sprintf(waypointHeatmapQuery, exclude), params).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute waypoint heatmap: %w", err)
+	}
+
+	cells := make([]trackingDomain.HeatmapCell, len(rows))
+	for i, row := range rows {
+		cells[i] = trackingDomain.HeatmapCell(row)
+	}
+	return cells, nil
+}
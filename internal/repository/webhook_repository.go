@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Kilat-Pet-Delivery/lib-common/domain"
+	webhookDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/webhook"
+)
+
+// WebhookModel is the GORM model for the webhooks table.
+type WebhookModel struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	AccountID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	BookingID *uuid.UUID `gorm:"type:uuid;index"`
+	URL       string     `gorm:"type:text;not null"`
+	Secret    string     `gorm:"type:varchar(64);not null"`
+	Events    string     `gorm:"type:jsonb;not null;default:'[]'"`
+	CreatedAt time.Time  `gorm:"type:timestamptz;not null"`
+}
+
+// TableName sets the table name.
+func (WebhookModel) TableName() string { return "webhooks" }
+
+// WebhookDeliveryModel is the GORM model for the webhook_deliveries table.
+type WebhookDeliveryModel struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	WebhookID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	BookingID      uuid.UUID  `gorm:"type:uuid;not null"`
+	Event          string     `gorm:"type:varchar(32);not null"`
+	Payload        string     `gorm:"type:jsonb;not null"`
+	Status         string     `gorm:"type:varchar(20);not null;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts       int        `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  `gorm:"type:timestamptz;not null;index:idx_webhook_deliveries_due,priority:2"`
+	LastStatusCode int        `gorm:"not null;default:0"`
+	LastError      string     `gorm:"type:text;not null;default:''"`
+	CreatedAt      time.Time  `gorm:"type:timestamptz;not null"`
+	DeliveredAt    *time.Time `gorm:"type:timestamptz"`
+}
+
+// TableName sets the table name.
+func (WebhookDeliveryModel) TableName() string { return "webhook_deliveries" }
+
+// GormWebhookRepository implements webhook.Repository using GORM.
+type GormWebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewGormWebhookRepository creates a new GormWebhookRepository.
+func NewGormWebhookRepository(db *gorm.DB) *GormWebhookRepository {
+	return &GormWebhookRepository{db: db}
+}
+
+// Save persists a new webhook.
+func (r *GormWebhookRepository) Save(ctx context.Context, w *webhookDomain.Webhook) error {
+	model, err := toWebhookModel(w)
+	if err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return fmt.Errorf("failed to save webhook: %w", err)
+	}
+	return nil
+}
+
+// FindByID returns a webhook by its ID.
+func (r *GormWebhookRepository) FindByID(ctx context.Context, id uuid.UUID) (*webhookDomain.Webhook, error) {
+	var model WebhookModel
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find webhook: %w", err)
+	}
+	return toWebhookDomain(&model)
+}
+
+// FindByAccountID returns the webhooks an account owns, oldest first.
+func (r *GormWebhookRepository) FindByAccountID(ctx context.Context, accountID uuid.UUID) ([]*webhookDomain.Webhook, error) {
+	var models []WebhookModel
+	if err := r.db.WithContext(ctx).Where("account_id = ?", accountID).Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find webhooks: %w", err)
+	}
+	return toWebhookDomains(models)
+}
+
+// FindForBooking returns the webhooks of a booking and the account webhooks of its owner.
+func (r *GormWebhookRepository) FindForBooking(ctx context.Context, bookingID, ownerID uuid.UUID) ([]*webhookDomain.Webhook, error) {
+	q := r.db.WithContext(ctx).Where("booking_id = ?", bookingID)
+	if ownerID != uuid.Nil {
+		q = q.Or("booking_id IS NULL AND account_id = ?", ownerID)
+	}
+	var models []WebhookModel
+	if err := q.Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find booking webhooks: %w", err)
+	}
+	return toWebhookDomains(models)
+}
+
+// Delete removes a webhook and its deliveries.
+func (r *GormWebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&WebhookModel{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete webhook: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+		if err := tx.Where("webhook_id = ?", id).Delete(&WebhookDeliveryModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+		}
+		return nil
+	})
+}
+
+// SaveDelivery persists a new delivery.
+func (r *GormWebhookRepository) SaveDelivery(ctx context.Context, d *webhookDomain.Delivery) error {
+	model := toWebhookDeliveryModel(d)
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// UpdateDelivery persists the outcome of a delivery attempt.
+func (r *GormWebhookRepository) UpdateDelivery(ctx context.Context, d *webhookDomain.Delivery) error {
+	model := toWebhookDeliveryModel(d)
+	if err := r.db.WithContext(ctx).Save(&model).Error; err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// FindDueDeliveries returns up to limit pending deliveries due at now, oldest first.
+func (r *GormWebhookRepository) FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*webhookDomain.Delivery, error) {
+	var models []WebhookDeliveryModel
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", string(webhookDomain.DeliveryPending), now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find due webhook deliveries: %w", err)
+	}
+	return toWebhookDeliveryDomains(models), nil
+}
+
+// ClaimDelivery pushes a due pending delivery's next attempt to until with a
+// conditional update, so only one instance claims it.
+func (r *GormWebhookRepository) ClaimDelivery(ctx context.Context, id uuid.UUID, attempts int, now, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&WebhookDeliveryModel{}).
+		Where("id = ? AND status = ? AND attempts = ? AND next_attempt_at <= ?", id, string(webhookDomain.DeliveryPending), attempts, now).
+		Update("next_attempt_at", until)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindDeliveries returns a webhook's latest deliveries, newest first.
+func (r *GormWebhookRepository) FindDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]*webhookDomain.Delivery, error) {
+	var models []WebhookDeliveryModel
+	if err := r.db.WithContext(ctx).
+		Where("webhook_id = ?", webhookID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find webhook deliveries: %w", err)
+	}
+	return toWebhookDeliveryDomains(models), nil
+}
+
+// DeleteFinishedDeliveries removes the delivered and failed deliveries created before cutoff.
+func (r *GormWebhookRepository) DeleteFinishedDeliveries(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status <> ? AND created_at < ?", string(webhookDomain.DeliveryPending), cutoff).
+		Delete(&WebhookDeliveryModel{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete finished webhook deliveries: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func toWebhookModel(w *webhookDomain.Webhook) (WebhookModel, error) {
+	events, err := json.Marshal(w.Events)
+	if err != nil {
+		return WebhookModel{}, fmt.Errorf("failed to marshal webhook events: %w", err)
+	}
+	return WebhookModel{
+		ID:        w.ID,
+		AccountID: w.AccountID,
+		BookingID: w.BookingID,
+		URL:       w.URL,
+		Secret:    w.Secret,
+		Events:    string(events),
+		CreatedAt: w.CreatedAt,
+	}, nil
+}
+
+func toWebhookDomain(m *WebhookModel) (*webhookDomain.Webhook, error) {
+	var events []webhookDomain.Event
+	if err := json.Unmarshal([]byte(m.Events), &events); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook events: %w", err)
+	}
+	return &webhookDomain.Webhook{
+		ID:        m.ID,
+		AccountID: m.AccountID,
+		BookingID: m.BookingID,
+		URL:       m.URL,
+		Secret:    m.Secret,
+		Events:    events,
+		CreatedAt: m.CreatedAt,
+	}, nil
+}
+
+func toWebhookDomains(models []WebhookModel) ([]*webhookDomain.Webhook, error) {
+	webhooks := make([]*webhookDomain.Webhook, 0, len(models))
+	for i := range models {
+		w, err := toWebhookDomain(&models[i])
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, nil
+}
+
+func toWebhookDeliveryModel(d *webhookDomain.Delivery) WebhookDeliveryModel {
+	return WebhookDeliveryModel{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		BookingID:      d.BookingID,
+		Event:          string(d.Event),
+		Payload:        string(d.Payload),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+func toWebhookDeliveryDomain(m *WebhookDeliveryModel) *webhookDomain.Delivery {
+	return &webhookDomain.Delivery{
+		ID:             m.ID,
+		WebhookID:      m.WebhookID,
+		BookingID:      m.BookingID,
+		Event:          webhookDomain.Event(m.Event),
+		Payload:        []byte(m.Payload),
+		Status:         webhookDomain.DeliveryStatus(m.Status),
+		Attempts:       m.Attempts,
+		NextAttemptAt:  m.NextAttemptAt,
+		LastStatusCode: m.LastStatusCode,
+		LastError:      m.LastError,
+		CreatedAt:      m.CreatedAt,
+		DeliveredAt:    m.DeliveredAt,
+	}
+}
+
+func toWebhookDeliveryDomains(models []WebhookDeliveryModel) []*webhookDomain.Delivery {
+	deliveries := make([]*webhookDomain.Delivery, len(models))
+	for i := range models {
+		deliveries[i] = toWebhookDeliveryDomain(&models[i])
+	}
+	return deliveries
+}
@@ -0,0 +1,184 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+const (
+	// cachePrecision rounds coordinates to three decimal places (about 110 m) for cache
+	// keys, so a runner's successive fixes reuse a route until it has moved on.
+	cachePrecision = 1e3
+
+	// maxCacheEntries bounds the route cache; expired entries are dropped when it is full.
+	maxCacheEntries = 10000
+)
+
+// ErrCircuitOpen is returned without calling the provider while it is considered down.
+var ErrCircuitOpen = errors.New("routing provider circuit open")
+
+// Config controls caching and circuit breaking in front of a provider.
+type Config struct {
+	// CacheTTL is how long a route is reused; zero disables caching.
+	CacheTTL time.Duration
+	// FailureThreshold is how many consecutive failures open the circuit.
+	FailureThreshold int
+	// Cooldown is how long the circuit stays open before a trial request is let through.
+	Cooldown time.Duration
+}
+
+// cacheKey identifies a route by its rounded end points.
+type cacheKey struct {
+	fromLat, fromLng, toLat, toLng int64
+}
+
+type cacheEntry struct {
+	route     trackingDomain.Route
+	expiresAt time.Time
+}
+
+// Client is a RoutingProvider that caches another provider's routes and stops calling
+// it for Cooldown after FailureThreshold consecutive failures. Once the cooldown ends,
+// one trial request decides whether the circuit closes again.
+type Client struct {
+	provider trackingDomain.RoutingProvider
+	config   Config
+	logger   *zap.Logger
+	now      func() time.Time
+
+	mu        sync.Mutex
+	cache     map[cacheKey]cacheEntry
+	failures  int
+	openUntil time.Time
+	trial     bool // a trial request is in flight
+	results   map[string]uint64
+}
+
+// NewClient creates a Client in front of provider.
+func NewClient(provider trackingDomain.RoutingProvider, config Config, logger *zap.Logger) *Client {
+	return &Client{
+		provider: provider,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+		cache:    make(map[cacheKey]cacheEntry),
+		results:  make(map[string]uint64),
+	}
+}
+
+// RouteBetween returns a cached route between two points or asks the provider for one.
+func (c *Client) RouteBetween(ctx context.Context, fromLat, fromLng, toLat, toLng float64) (*trackingDomain.Route, error) {
+	key := cacheKey{round(fromLat), round(fromLng), round(toLat), round(toLng)}
+
+	c.mu.Lock()
+	now := c.now()
+	if e, ok := c.cache[key]; ok && now.Before(e.expiresAt) {
+		c.results["cache_hit"]++
+		c.mu.Unlock()
+		route := e.route
+		return &route, nil
+	}
+	if now.Before(c.openUntil) || c.trial {
+		c.results["circuit_open"]++
+		c.mu.Unlock()
+		return nil, ErrCircuitOpen
+	}
+	trial := c.failures >= c.config.FailureThreshold && c.config.FailureThreshold > 0
+	c.trial = trial
+	c.mu.Unlock()
+
+	route, err := c.provider.RouteBetween(ctx, fromLat, fromLng, toLat, toLng)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trial = false
+	if err != nil && ctx.Err() != nil {
+		// The caller gave up; that says nothing about the provider.
+		return nil, err
+	}
+	if err != nil {
+		c.results["error"]++
+		c.failures++
+		if c.config.FailureThreshold > 0 && c.failures >= c.config.FailureThreshold {
+			if !trial {
+				c.logger.Warn("routing provider failing, falling back to straight-line estimates",
+					zap.Int("failures", c.failures), zap.Error(err))
+			}
+			c.openUntil = c.now().Add(c.config.Cooldown)
+		}
+		return nil, err
+	}
+
+	c.results["ok"]++
+	if trial {
+		c.logger.Info("routing provider recovered")
+	}
+	c.failures = 0
+	c.openUntil = time.Time{}
+	if c.config.CacheTTL > 0 {
+		if len(c.cache) >= maxCacheEntries {
+			c.evictExpired(now)
+		}
+		if len(c.cache) < maxCacheEntries {
+			c.cache[key] = cacheEntry{route: *route, expiresAt: c.now().Add(c.config.CacheTTL)}
+		}
+	}
+	return route, nil
+}
+
+// evictExpired drops cached routes that have expired. The caller must hold mu.
+func (c *Client) evictExpired(now time.Time) {
+	for k, e := range c.cache {
+		if !now.Before(e.expiresAt) {
+			delete(c.cache, k)
+		}
+	}
+}
+
+// ServeHTTP exports routing request outcomes and the circuit state as Prometheus text.
+func (c *Client) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	c.mu.Lock()
+	results := make(map[string]uint64, len(c.results))
+	for k, n := range c.results {
+		results[k] = n
+	}
+	open := c.now().Before(c.openUntil)
+	cached := len(c.cache)
+	c.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP tracking_routing_requests_total Road route lookups by result.")
+	fmt.Fprintln(w, "# TYPE tracking_routing_requests_total counter")
+	for _, result := range []string{"cache_hit", "ok", "error", "circuit_open"} {
+		fmt.Fprintf(w, "tracking_routing_requests_total{result=%q} %d\n", result, results[result])
+	}
+
+	fmt.Fprintln(w, "# HELP tracking_routing_circuit_open Whether calls to the routing provider are suspended.")
+	fmt.Fprintln(w, "# TYPE tracking_routing_circuit_open gauge")
+	fmt.Fprintf(w, "tracking_routing_circuit_open %d\n", boolGauge(open))
+
+	fmt.Fprintln(w, "# HELP tracking_routing_cached_routes Road routes held in the cache.")
+	fmt.Fprintln(w, "# TYPE tracking_routing_cached_routes gauge")
+	fmt.Fprintf(w, "tracking_routing_cached_routes %d\n", cached)
+}
+
+func round(v float64) int64 {
+	return int64(math.Round(v * cachePrecision))
+}
+
+func boolGauge(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
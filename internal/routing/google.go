@@ -0,0 +1,104 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// googleDirectionsURL is the Google Directions API endpoint.
+const googleDirectionsURL = "https://maps.googleapis.com/maps/api/directions/json"
+
+// GoogleClient calls the Google Directions API.
+type GoogleClient struct {
+	apiKey string
+	mode   string
+	http   *http.Client
+}
+
+// NewGoogleClient creates a GoogleClient authenticating with apiKey and routing in mode,
+// e.g. "driving". Each request is bounded by timeout.
+func NewGoogleClient(apiKey, mode string, timeout time.Duration) *GoogleClient {
+	return &GoogleClient{
+		apiKey: apiKey,
+		mode:   mode,
+		http:   &http.Client{Timeout: timeout},
+	}
+}
+
+// googleValue is a distance or duration in a Directions response.
+type googleValue struct {
+	Value float64 `json:"value"` // meters or seconds
+}
+
+// googleResponse is the body of a Directions request.
+type googleResponse struct {
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+	Routes       []struct {
+		Legs []struct {
+			Distance          googleValue  `json:"distance"`
+			Duration          googleValue  `json:"duration"`
+			DurationInTraffic *googleValue `json:"duration_in_traffic"`
+		} `json:"legs"`
+	} `json:"routes"`
+}
+
+// RouteBetween returns the recommended road route between two points, using the travel
+// time in current traffic when Google provides one.
+func (c *GoogleClient) RouteBetween(ctx context.Context, fromLat, fromLng, toLat, toLng float64) (*trackingDomain.Route, error) {
+	q := url.Values{}
+	q.Set("origin", formatCoord(fromLat)+","+formatCoord(fromLng))
+	q.Set("destination", formatCoord(toLat)+","+formatCoord(toLng))
+	q.Set("mode", c.mode)
+	q.Set("departure_time", "now")
+	q.Set("key", c.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleDirectionsURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build directions request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// The URL carries the API key, so do not wrap the *url.Error.
+		return nil, fmt.Errorf("directions request failed: %v", unwrapURLError(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("directions request failed: status %d", resp.StatusCode)
+	}
+
+	var body googleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode directions response: %w", err)
+	}
+	if body.Status != "OK" || len(body.Routes) == 0 || len(body.Routes[0].Legs) == 0 {
+		return nil, fmt.Errorf("directions returned no route: %s %s", body.Status, body.ErrorMessage)
+	}
+
+	leg := body.Routes[0].Legs[0]
+	duration := leg.Duration.Value
+	if leg.DurationInTraffic != nil {
+		duration = leg.DurationInTraffic.Value
+	}
+	return &trackingDomain.Route{
+		DistanceKm: leg.Distance.Value / 1000,
+		Duration:   time.Duration(duration * float64(time.Second)),
+	}, nil
+}
+
+// unwrapURLError strips the request URL from a client error.
+func unwrapURLError(err error) error {
+	if uerr, ok := err.(*url.Error); ok {
+		return uerr.Err
+	}
+	return err
+}
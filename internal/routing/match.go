@@ -0,0 +1,168 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// maxMatchBatch is the most waypoints sent in one map-matching request, the default
+// limit of OSRM's match service and the limit of Google's snapToRoads.
+const maxMatchBatch = 100
+
+// googleSnapToRoadsURL is the Google Roads API snapToRoads endpoint.
+const googleSnapToRoadsURL = "https://roads.googleapis.com/v1/snapToRoads"
+
+// matchInBatches matches waypoints in consecutive batches of at most maxMatchBatch.
+func matchInBatches(ctx context.Context, waypoints []trackingDomain.Waypoint, match func(context.Context, []trackingDomain.Waypoint) ([]trackingDomain.Waypoint, error)) ([]trackingDomain.Waypoint, error) {
+	matched := make([]trackingDomain.Waypoint, 0, len(waypoints))
+	for start := 0; start < len(waypoints); start += maxMatchBatch {
+		batch, err := match(ctx, waypoints[start:min(start+maxMatchBatch, len(waypoints))])
+		if err != nil {
+			return nil, err
+		}
+		matched = append(matched, batch...)
+	}
+	return matched, nil
+}
+
+// snapped returns wp moved to a matched location.
+func snapped(wp trackingDomain.Waypoint, lat, lng float64) trackingDomain.Waypoint {
+	wp.Latitude, wp.Longitude = lat, lng
+	return wp
+}
+
+// osrmMatchResponse is the body of GET /match/v1/{profile}/{coordinates}.
+type osrmMatchResponse struct {
+	Code        string `json:"code"`
+	Tracepoints []*struct {
+		Location [2]float64 `json:"location"` // longitude, latitude
+	} `json:"tracepoints"`
+}
+
+// MatchWaypoints snaps waypoints to roads with OSRM's match service, passing their
+// recording times so the matching can rule out implausible paths.
+func (c *OSRMClient) MatchWaypoints(ctx context.Context, waypoints []trackingDomain.Waypoint) ([]trackingDomain.Waypoint, error) {
+	return matchInBatches(ctx, waypoints, c.matchBatch)
+}
+
+func (c *OSRMClient) matchBatch(ctx context.Context, waypoints []trackingDomain.Waypoint) ([]trackingDomain.Waypoint, error) {
+	coords := make([]string, len(waypoints))
+	timestamps := make([]string, len(waypoints))
+	for i, wp := range waypoints {
+		coords[i] = formatCoord(wp.Longitude) + "," + formatCoord(wp.Latitude)
+		timestamps[i] = strconv.FormatInt(wp.RecordedAt.Unix(), 10)
+	}
+	u := c.baseURL + "/match/v1/" + c.profile + "/" + strings.Join(coords, ";") +
+		"?overview=false&steps=false&tidy=false&gaps=ignore&timestamps=" + strings.Join(timestamps, ";")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OSRM match request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OSRM match request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body osrmMatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode OSRM match response (status %d): %w", resp.StatusCode, err)
+	}
+	switch body.Code {
+	case "Ok":
+	case "NoMatch":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("OSRM match failed: status %d, code %q", resp.StatusCode, body.Code)
+	}
+
+	matched := make([]trackingDomain.Waypoint, 0, len(waypoints))
+	for i, tp := range body.Tracepoints {
+		if tp == nil || i >= len(waypoints) {
+			continue
+		}
+		matched = append(matched, snapped(waypoints[i], tp.Location[1], tp.Location[0]))
+	}
+	return matched, nil
+}
+
+// GoogleRoadsClient snaps waypoints to roads with the Google Roads API.
+type GoogleRoadsClient struct {
+	apiKey string
+	http   *http.Client
+}
+
+// NewGoogleRoadsClient creates a GoogleRoadsClient authenticating with apiKey. Each
+// request is bounded by timeout.
+func NewGoogleRoadsClient(apiKey string, timeout time.Duration) *GoogleRoadsClient {
+	return &GoogleRoadsClient{apiKey: apiKey, http: &http.Client{Timeout: timeout}}
+}
+
+// googleSnapResponse is the body of a snapToRoads request.
+type googleSnapResponse struct {
+	SnappedPoints []struct {
+		Location struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"location"`
+		OriginalIndex *int `json:"originalIndex"`
+	} `json:"snappedPoints"`
+}
+
+// MatchWaypoints snaps waypoints to roads with snapToRoads.
+func (c *GoogleRoadsClient) MatchWaypoints(ctx context.Context, waypoints []trackingDomain.Waypoint) ([]trackingDomain.Waypoint, error) {
+	return matchInBatches(ctx, waypoints, c.matchBatch)
+}
+
+func (c *GoogleRoadsClient) matchBatch(ctx context.Context, waypoints []trackingDomain.Waypoint) ([]trackingDomain.Waypoint, error) {
+	path := make([]string, len(waypoints))
+	for i, wp := range waypoints {
+		path[i] = formatCoord(wp.Latitude) + "," + formatCoord(wp.Longitude)
+	}
+	q := url.Values{}
+	q.Set("path", strings.Join(path, "|"))
+	q.Set("interpolate", "false")
+	q.Set("key", c.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleSnapToRoadsURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build snapToRoads request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// The URL carries the API key, so do not wrap the *url.Error.
+		return nil, fmt.Errorf("snapToRoads request failed: %v", unwrapURLError(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("snapToRoads request failed: status %d", resp.StatusCode)
+	}
+
+	var body googleSnapResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode snapToRoads response: %w", err)
+	}
+
+	matched := make([]trackingDomain.Waypoint, 0, len(waypoints))
+	for _, p := range body.SnappedPoints {
+		if p.OriginalIndex == nil || *p.OriginalIndex >= len(waypoints) {
+			continue
+		}
+		matched = append(matched, snapped(waypoints[*p.OriginalIndex], p.Location.Latitude, p.Location.Longitude))
+	}
+	return matched, nil
+}
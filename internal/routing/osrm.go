@@ -0,0 +1,79 @@
+// Package routing computes road routes for arrival estimates through an OSRM server or
+// the Google Directions API, with caching and a circuit breaker in front of either, and
+// snaps recorded routes to roads through OSRM's match service or the Google Roads API.
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	trackingDomain "github.com/Kilat-Pet-Delivery/service-tracking/internal/domain/tracking"
+)
+
+// OSRMClient calls the route service of an OSRM server over HTTP.
+type OSRMClient struct {
+	baseURL string
+	profile string
+	http    *http.Client
+}
+
+// NewOSRMClient creates an OSRMClient for the server at baseURL routing with profile,
+// e.g. "driving". Each request is bounded by timeout.
+func NewOSRMClient(baseURL, profile string, timeout time.Duration) *OSRMClient {
+	return &OSRMClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		profile: profile,
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// osrmResponse is the body of GET /route/v1/{profile}/{coordinates}.
+type osrmResponse struct {
+	Code   string `json:"code"`
+	Routes []struct {
+		Distance float64 `json:"distance"` // meters
+		Duration float64 `json:"duration"` // seconds
+	} `json:"routes"`
+}
+
+// RouteBetween returns the fastest road route between two points.
+func (c *OSRMClient) RouteBetween(ctx context.Context, fromLat, fromLng, toLat, toLng float64) (*trackingDomain.Route, error) {
+	// OSRM takes coordinates as longitude,latitude pairs.
+	coords := formatCoord(fromLng) + "," + formatCoord(fromLat) + ";" + formatCoord(toLng) + "," + formatCoord(toLat)
+	u := c.baseURL + "/route/v1/" + c.profile + "/" + coords + "?overview=false&alternatives=false&steps=false"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OSRM request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OSRM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body osrmResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode OSRM response (status %d): %w", resp.StatusCode, err)
+	}
+	if body.Code != "Ok" || len(body.Routes) == 0 {
+		return nil, fmt.Errorf("OSRM returned no route: status %d, code %q", resp.StatusCode, body.Code)
+	}
+
+	r := body.Routes[0]
+	return &trackingDomain.Route{
+		DistanceKm: r.Distance / 1000,
+		Duration:   time.Duration(r.Duration * float64(time.Second)),
+	}, nil
+}
+
+func formatCoord(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// ModeMigrations is used when the schema is managed by the SQL migrations.
+	ModeMigrations = "migrations"
+
+	// ModeAutoMigrate is used in development, where GORM creates the schema from the
+	// models and no migration version is recorded.
+	ModeAutoMigrate = "auto_migrate"
+)
+
+// Status compares the schema version this build expects with the one applied to the
+// database.
+type Status struct {
+	Mode            string `json:"mode"`
+	ExpectedVersion uint   `json:"expected_version"`
+	AppliedVersion  *uint  `json:"applied_version,omitempty"`
+	Dirty           bool   `json:"dirty"`
+	Compatible      bool   `json:"compatible"`
+	Problem         string `json:"problem,omitempty"`
+}
+
+// Checker reads the migration version applied to a database, as recorded by the
+// migration runner in schema_migrations.
+type Checker struct {
+	db       *gorm.DB
+	expected uint
+	mode     string
+}
+
+// NewChecker creates a Checker for a database expected to be at version expected.
+func NewChecker(db *gorm.DB, expected uint, mode string) *Checker {
+	return &Checker{db: db, expected: expected, mode: mode}
+}
+
+// Status reports the database's current schema version. Databases created by
+// auto-migrate have no version and are always reported as compatible.
+func (c *Checker) Status(ctx context.Context) (Status, error) {
+	status := Status{Mode: c.mode, ExpectedVersion: c.expected}
+	if c.mode == ModeAutoMigrate {
+		status.Compatible = true
+		return status, nil
+	}
+
+	if !c.db.WithContext(ctx).Migrator().HasTable("schema_migrations") {
+		status.Problem = fmt.Sprintf("database has no schema_migrations table; this build expects schema version %d", c.expected)
+		return status, nil
+	}
+
+	var row struct {
+		Version int64
+		Dirty   bool
+	}
+	result := c.db.WithContext(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&row)
+	if result.Error != nil {
+		return status, fmt.Errorf("read schema_migrations: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		status.Problem = fmt.Sprintf("no migrations have been applied; this build expects schema version %d", c.expected)
+		return status, nil
+	}
+
+	applied := uint(row.Version)
+	status.AppliedVersion = &applied
+	status.Dirty = row.Dirty
+	switch {
+	case row.Dirty:
+		status.Problem = fmt.Sprintf("migration %d failed part-way and left the schema dirty; repair it and force the version before starting", applied)
+	case applied > c.expected:
+		status.Problem = fmt.Sprintf("database schema is at version %d but this build only knows migrations up to %d; deploy a build that includes migration %d or roll the database back", applied, c.expected, applied)
+	case applied < c.expected:
+		status.Problem = fmt.Sprintf("database schema is at version %d but this build expects %d; apply the missing migrations", applied, c.expected)
+	default:
+		status.Compatible = true
+	}
+	return status, nil
+}
+
+// Verify returns an error describing the mismatch if the database's schema version
+// is not the one this build expects.
+func (c *Checker) Verify(ctx context.Context) error {
+	status, err := c.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if !status.Compatible {
+		return fmt.Errorf("incompatible database schema: %s", status.Problem)
+	}
+	return nil
+}
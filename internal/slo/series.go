@@ -0,0 +1,44 @@
+package slo
+
+import "time"
+
+// bucket counts the requests of one time slot.
+type bucket struct {
+	start      int64 // slot start in unix nanoseconds
+	total, bad uint64
+}
+
+// series is a ring of fixed-width time buckets.
+type series struct {
+	width   time.Duration
+	buckets []bucket
+}
+
+func newSeries(width time.Duration, n int) *series {
+	return &series{width: width, buckets: make([]bucket, n)}
+}
+
+// add counts a request at now.
+func (s *series) add(now time.Time, bad bool) {
+	start := now.Truncate(s.width).UnixNano()
+	b := &s.buckets[(start/int64(s.width))%int64(len(s.buckets))]
+	if b.start != start {
+		*b = bucket{start: start}
+	}
+	b.total++
+	if bad {
+		b.bad++
+	}
+}
+
+// sum returns the requests counted in the buckets overlapping the window ending at now.
+func (s *series) sum(now time.Time, window time.Duration) (total, bad uint64) {
+	oldest := now.Add(-window).Truncate(s.width).UnixNano()
+	for _, b := range s.buckets {
+		if b.total > 0 && b.start >= oldest {
+			total += b.total
+			bad += b.bad
+		}
+	}
+	return total, bad
+}
@@ -0,0 +1,285 @@
+// Package slo measures tracking endpoints against service level objectives. Each route
+// is tagged with an SLO class that sets its latency target and the share of requests
+// that must meet it; requests that fail or are too slow spend the class's error budget.
+package slo
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Budget statuses, from the multi-window burn-rate alerting thresholds.
+const (
+	StatusOK       = "ok"
+	StatusWarning  = "warning"
+	StatusCritical = "critical"
+)
+
+const (
+	// fastBurnRate spends 2% of a 30-day budget in an hour; sustained over both the 5m
+	// and 1h windows it is critical.
+	fastBurnRate = 14.4
+	// slowBurnRate spends 5% of a 30-day budget in 6 hours; sustained over the 6h window
+	// it is a warning.
+	slowBurnRate = 6.0
+
+	// minuteBuckets covers the longest burn-rate window, 6 hours.
+	minuteBuckets = 6 * 60
+)
+
+// burnWindows are the windows burn rates are reported over.
+var burnWindows = []struct {
+	name   string
+	length time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+}
+
+// Class is an SLO class.
+type Class struct {
+	Name string
+	// LatencyTarget is the slowest a request may be and still count as good.
+	LatencyTarget time.Duration
+	// Objective is the fraction of requests that must be good, e.g. 0.999.
+	Objective float64
+}
+
+// ParseClasses parses classes from name -> "latency:objective" pairs, such as
+// critical -> "300ms:0.999".
+func ParseClasses(specs map[string]string) ([]Class, error) {
+	classes := make([]Class, 0, len(specs))
+	for name, spec := range specs {
+		latency, objective, ok := strings.Cut(spec, ":")
+		if !ok {
+			return nil, fmt.Errorf("SLO class %s must be latency:objective, got %q", name, spec)
+		}
+		d, err := time.ParseDuration(latency)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("SLO class %s has an invalid latency target %q", name, latency)
+		}
+		o, err := strconv.ParseFloat(objective, 64)
+		if err != nil || o <= 0 || o >= 1 {
+			return nil, fmt.Errorf("SLO class %s objective must be between 0 and 1, got %q", name, objective)
+		}
+		classes = append(classes, Class{Name: name, LatencyTarget: d, Objective: o})
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Name < classes[j].Name })
+	return classes, nil
+}
+
+// Status is the current error budget status of an SLO class.
+type Status struct {
+	Class           string  `json:"class"`
+	LatencyTargetMs int64   `json:"latency_target_ms"`
+	Objective       float64 `json:"objective"`
+	PeriodHours     float64 `json:"period_hours"`
+	Requests        uint64  `json:"requests"`
+	BadRequests     uint64  `json:"bad_requests"`
+	// SLI is the fraction of good requests over the period; 1 without traffic.
+	SLI float64 `json:"sli"`
+	// BudgetRemaining is the fraction of the period's error budget left; negative
+	// once the objective is missed.
+	BudgetRemaining float64            `json:"budget_remaining"`
+	BurnRates       map[string]float64 `json:"burn_rates"`
+	Status          string             `json:"status"`
+	Routes          []string           `json:"routes"`
+}
+
+// classState holds the counters of one class.
+type classState struct {
+	class   Class
+	minutes *series // for burn rates
+	hours   *series // for the budget period
+
+	requests   uint64
+	badLatency uint64
+	badError   uint64
+}
+
+// Tracker records requests per SLO class. Figures are kept in memory per instance;
+// the exported counters can be aggregated across instances by Prometheus.
+type Tracker struct {
+	period time.Duration
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	classes map[string]*classState
+	routes  map[string]string // "METHOD /path" -> class name
+}
+
+// NewTracker creates a Tracker for the given classes, with error budgets over period.
+func NewTracker(classes []Class, period time.Duration, logger *zap.Logger) *Tracker {
+	hours := int(period / time.Hour)
+	if hours < 1 {
+		hours = 1
+	}
+	t := &Tracker{
+		period:  time.Duration(hours) * time.Hour,
+		logger:  logger,
+		classes: make(map[string]*classState, len(classes)),
+		routes:  make(map[string]string),
+	}
+	for _, c := range classes {
+		t.classes[c.Name] = &classState{
+			class:   c,
+			minutes: newSeries(time.Minute, minuteBuckets),
+			hours:   newSeries(time.Hour, hours),
+		}
+	}
+	return t
+}
+
+// Classify tags routes, given as "METHOD /full/path" with gin path parameters, with
+// an SLO class. Routes of unknown classes are ignored with a warning.
+func (t *Tracker) Classify(class string, routes ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.classes[class]; !ok {
+		t.logger.Warn("routes tagged with unknown SLO class", zap.String("class", class), zap.Strings("routes", routes))
+		return
+	}
+	for _, r := range routes {
+		t.routes[r] = class
+	}
+}
+
+// Middleware records the latency and outcome of requests to classified routes. A
+// request is bad if it takes longer than its class's latency target or fails with a
+// 5xx or 429 status; other 4xx responses are the client's fault and count as good.
+func (t *Tracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		state, ok := t.classes[t.routes[c.Request.Method+" "+c.FullPath()]]
+		if !ok {
+			return
+		}
+
+		status := c.Writer.Status()
+		failed := status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+		slow := time.Since(start) > state.class.LatencyTarget
+		bad := failed || slow
+
+		state.requests++
+		switch {
+		case failed:
+			state.badError++
+		case slow:
+			state.badLatency++
+		}
+		now := time.Now()
+		state.minutes.add(now, bad)
+		state.hours.add(now, bad)
+	}
+}
+
+// Statuses returns the current status of every class, ordered by name.
+func (t *Tracker) Statuses() []Status {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	routes := make(map[string][]string)
+	for route, class := range t.routes {
+		routes[class] = append(routes[class], route)
+	}
+
+	result := make([]Status, 0, len(t.classes))
+	for name, state := range t.classes {
+		budget := 1 - state.class.Objective
+		total, bad := state.hours.sum(now, t.period)
+
+		st := Status{
+			Class:           name,
+			LatencyTargetMs: state.class.LatencyTarget.Milliseconds(),
+			Objective:       state.class.Objective,
+			PeriodHours:     t.period.Hours(),
+			Requests:        total,
+			BadRequests:     bad,
+			SLI:             1,
+			BudgetRemaining: 1,
+			BurnRates:       make(map[string]float64, len(burnWindows)),
+			Status:          StatusOK,
+			Routes:          routes[name],
+		}
+		if total > 0 {
+			st.SLI = float64(total-bad) / float64(total)
+			st.BudgetRemaining = 1 - (float64(bad)/float64(total))/budget
+		}
+		for _, w := range burnWindows {
+			st.BurnRates[w.name] = burnRate(state.minutes, now, w.length, budget)
+		}
+		switch {
+		case st.BurnRates["5m"] > fastBurnRate && st.BurnRates["1h"] > fastBurnRate:
+			st.Status = StatusCritical
+		case st.BurnRates["6h"] > slowBurnRate || st.BudgetRemaining <= 0:
+			st.Status = StatusWarning
+		}
+		sort.Strings(st.Routes)
+		result = append(result, st)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Class < result[j].Class })
+	return result
+}
+
+// ServeHTTP exports request counters, burn rates and remaining budgets in the
+// Prometheus text format.
+func (t *Tracker) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	statuses := t.Statuses()
+
+	t.mu.Lock()
+	counters := make(map[string][3]uint64, len(t.classes))
+	for name, state := range t.classes {
+		counters[name] = [3]uint64{state.requests, state.badLatency, state.badError}
+	}
+	t.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP tracking_slo_requests_total Requests to routes with an SLO class.")
+	fmt.Fprintln(w, "# TYPE tracking_slo_requests_total counter")
+	for _, st := range statuses {
+		fmt.Fprintf(w, "tracking_slo_requests_total{class=%q} %d\n", st.Class, counters[st.Class][0])
+	}
+	fmt.Fprintln(w, "# HELP tracking_slo_bad_requests_total Requests that missed their SLO, by reason.")
+	fmt.Fprintln(w, "# TYPE tracking_slo_bad_requests_total counter")
+	for _, st := range statuses {
+		fmt.Fprintf(w, "tracking_slo_bad_requests_total{class=%q,reason=\"latency\"} %d\n", st.Class, counters[st.Class][1])
+		fmt.Fprintf(w, "tracking_slo_bad_requests_total{class=%q,reason=\"error\"} %d\n", st.Class, counters[st.Class][2])
+	}
+	fmt.Fprintln(w, "# HELP tracking_slo_burn_rate Rate the error budget is spent at, relative to the objective.")
+	fmt.Fprintln(w, "# TYPE tracking_slo_burn_rate gauge")
+	for _, st := range statuses {
+		for _, win := range burnWindows {
+			fmt.Fprintf(w, "tracking_slo_burn_rate{class=%q,window=%q} %g\n", st.Class, win.name, st.BurnRates[win.name])
+		}
+	}
+	fmt.Fprintln(w, "# HELP tracking_slo_error_budget_remaining Fraction of the error budget left in the SLO period.")
+	fmt.Fprintln(w, "# TYPE tracking_slo_error_budget_remaining gauge")
+	for _, st := range statuses {
+		fmt.Fprintf(w, "tracking_slo_error_budget_remaining{class=%q} %g\n", st.Class, st.BudgetRemaining)
+	}
+}
+
+// burnRate returns the fraction of bad requests in the window divided by the budget.
+func burnRate(s *series, now time.Time, window time.Duration, budget float64) float64 {
+	total, bad := s.sum(now, window)
+	if total == 0 {
+		return 0
+	}
+	return (float64(bad) / float64(total)) / budget
+}
@@ -0,0 +1,37 @@
+package widget
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/base64"
+	"html/template"
+	"io"
+)
+
+//go:embed embed.html
+var embedHTML string
+
+// embedTemplate renders the embed page: a self-contained map of the runner's position,
+// and route when the token grants it, kept live over the widget WebSocket.
+var embedTemplate = template.Must(template.New("embed").Parse(embedHTML))
+
+// EmbedPage is the data of an embed page. Data is serialized into the page's script as
+// JSON. Nonce must also be allowed by the response's Content-Security-Policy.
+type EmbedPage struct {
+	Nonce string
+	Data  interface{}
+}
+
+// RenderEmbed writes the embed page.
+func RenderEmbed(w io.Writer, page EmbedPage) error {
+	return embedTemplate.Execute(w, page)
+}
+
+// NewNonce returns a random Content-Security-Policy nonce.
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
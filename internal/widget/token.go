@@ -0,0 +1,122 @@
+package widget
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Kilat-Pet-Delivery/service-tracking/internal/clock"
+)
+
+// Widget token scopes. Both are read-only and limited to a single booking.
+const (
+	// ScopeTrackingRead grants the booking's full tracking data, including its route.
+	ScopeTrackingRead = "tracking:read"
+	// ScopePositionRead grants only the runner's current position, without the route
+	// travelled so far.
+	ScopePositionRead = "tracking:position"
+)
+
+var (
+	// ErrInvalidToken is returned when a token is malformed or its signature does not match.
+	ErrInvalidToken = errors.New("invalid widget token")
+
+	// ErrExpiredToken is returned when a token's expiry has passed.
+	ErrExpiredToken = errors.New("widget token has expired")
+)
+
+// Claims is the payload carried by a widget token. Origin, when set, is the only site
+// allowed to frame the token's embed.
+type Claims struct {
+	BookingID uuid.UUID `json:"bid"`
+	Scope     string    `json:"scope"`
+	Origin    string    `json:"origin,omitempty"`
+	ExpiresAt int64     `json:"exp"`
+}
+
+// FullRoute reports whether the token grants the booking's route.
+func (c *Claims) FullRoute() bool { return c.Scope == ScopeTrackingRead }
+
+// Signer mints and validates short-lived, booking-scoped widget tokens.
+// Tokens are HMAC-SHA256 signed and deliberately independent of user JWTs so
+// they can be embedded in partner pages and emails without exposing a session.
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+	clock  clock.Clock
+}
+
+// NewSigner creates a new Signer with the given secret and token lifetime.
+func NewSigner(secret string, ttl time.Duration) *Signer {
+	return &Signer{secret: []byte(secret), ttl: ttl, clock: clock.System}
+}
+
+// UseClock replaces the wall clock, e.g. with a clock.Manual in tests.
+func (s *Signer) UseClock(c clock.Clock) {
+	s.clock = c
+}
+
+// Issue mints a read-only token with the given scope for a booking and returns it with
+// its expiry. origin may be empty.
+func (s *Signer) Issue(bookingID uuid.UUID, scope, origin string) (string, time.Time, error) {
+	expiresAt := s.clock.Now().UTC().Add(s.ttl)
+	claims := Claims{
+		BookingID: bookingID,
+		Scope:     scope,
+		Origin:    origin,
+		ExpiresAt: expiresAt.Unix(),
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.sign(encoded), expiresAt, nil
+}
+
+// Validate verifies a token's signature, scope and expiry and returns its claims.
+func (s *Signer) Validate(token string) (*Claims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok || encoded == "" || sig == "" {
+		return nil, ErrInvalidToken
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(s.sign(encoded))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if (claims.Scope != ScopeTrackingRead && claims.Scope != ScopePositionRead) || claims.BookingID == uuid.Nil {
+		return nil, ErrInvalidToken
+	}
+	if s.clock.Now().UTC().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+// TTL returns the lifetime of newly issued tokens.
+func (s *Signer) TTL() time.Duration { return s.ttl }
+
+func (s *Signer) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
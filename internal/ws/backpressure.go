@@ -0,0 +1,118 @@
+package ws
+
+import (
+	"time"
+)
+
+// msgTypeLagging warns a client that it is not reading its frames fast enough.
+const msgTypeLagging = "lagging"
+
+// SlowClientPolicy decides what happens to clients whose send buffer is full.
+type SlowClientPolicy struct {
+	// CoalesceLocations keeps only the latest location frame for a full client instead
+	// of dropping it, since older positions are superseded anyway.
+	CoalesceLocations bool
+	// LaggingGrace is how long a client may keep dropping frames after it was warned
+	// with a lagging frame before it is disconnected.
+	LaggingGrace time.Duration
+	// MaxDroppedFrames disconnects a lagging client once it has dropped this many frames,
+	// even within the grace period; zero means no limit.
+	MaxDroppedFrames int
+}
+
+// DefaultSlowClientPolicy is the policy of hubs that were not given one.
+var DefaultSlowClientPolicy = SlowClientPolicy{
+	CoalesceLocations: true,
+	LaggingGrace:      10 * time.Second,
+	MaxDroppedFrames:  256,
+}
+
+// laggingStatus is sent to a client when it starts dropping frames.
+type laggingStatus struct {
+	Type          string `json:"type"`
+	DroppedFrames int    `json:"dropped_frames"`
+	// GraceSeconds is how long the client has to catch up before it is disconnected.
+	GraceSeconds float64 `json:"grace_seconds"`
+}
+
+// UseSlowClientPolicy replaces DefaultSlowClientPolicy. Must be called before Run.
+func (h *Hub) UseSlowClientPolicy(p SlowClientPolicy) {
+	h.policy = p
+}
+
+// deliver queues data for a client, applying the hub's slow client policy when its
+// buffer is full. latest marks location frames, which supersede earlier ones. It
+// reports false if the client should be disconnected. Only the shard owning the
+// client's room calls it.
+func (s *shard) deliver(client *Client, data []byte, latest bool) bool {
+	select {
+	case client.Send <- data:
+		if latest {
+			client.clearPending()
+		}
+		if !client.laggingSince.IsZero() && len(client.Send) < cap(client.Send)/2 {
+			client.laggingSince = time.Time{}
+			client.droppedFrames = 0
+		}
+		return true
+	default:
+	}
+
+	policy := s.hub.policy
+	if latest && policy.CoalesceLocations {
+		if client.setPending(data) {
+			s.hub.framesCoalesced.Add(1)
+		}
+		return true
+	}
+
+	s.hub.framesDropped.Add(1)
+	client.droppedFrames++
+	now := time.Now()
+	if client.laggingSince.IsZero() {
+		client.laggingSince = now
+		client.sendControl(laggingStatus{
+			Type:          msgTypeLagging,
+			DroppedFrames: client.droppedFrames,
+			GraceSeconds:  policy.LaggingGrace.Seconds(),
+		})
+		return true
+	}
+	if now.Sub(client.laggingSince) >= policy.LaggingGrace ||
+		(policy.MaxDroppedFrames > 0 && client.droppedFrames >= policy.MaxDroppedFrames) {
+		s.hub.slowDisconnects.Add(1)
+		return false
+	}
+	return true
+}
+
+// setPending stores the latest location frame of a full client, reporting whether it
+// replaced an older one, and wakes the write pump.
+func (c *Client) setPending(data []byte) bool {
+	c.pendingMu.Lock()
+	replaced := c.pending != nil
+	c.pending = data
+	c.pendingMu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+	return replaced
+}
+
+// takePending returns and clears the pending location frame, if any.
+func (c *Client) takePending() []byte {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	data := c.pending
+	c.pending = nil
+	return data
+}
+
+// clearPending drops the pending location frame once a newer one was queued.
+func (c *Client) clearPending() {
+	c.pendingMu.Lock()
+	c.pending = nil
+	c.pendingMu.Unlock()
+}
@@ -0,0 +1,102 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// drainPollInterval is how often Drain checks whether every connection has closed.
+const drainPollInterval = 100 * time.Millisecond
+
+// track records an open connection. Connections opened while draining are closed at once.
+func (h *Hub) track(c *Client) {
+	h.connMu.Lock()
+	h.conns[c] = struct{}{}
+	h.connMu.Unlock()
+
+	if h.draining.Load() {
+		c.closeForDrain()
+	}
+}
+
+// untrack forgets a closed connection and releases its connection slot.
+func (h *Hub) untrack(c *Client) {
+	h.connMu.Lock()
+	delete(h.conns, c)
+	h.connMu.Unlock()
+
+	if c.admission != nil {
+		c.admission.Release()
+	}
+}
+
+// Connections returns the number of open WebSocket connections.
+func (h *Hub) Connections() int {
+	h.connMu.Lock()
+	defer h.connMu.Unlock()
+	return len(h.conns)
+}
+
+// Draining reports whether the hub is closing its connections ahead of shutdown.
+func (h *Hub) Draining() bool {
+	return h.draining.Load()
+}
+
+// Drain closes every connection with close code 1012 (service restart) so clients
+// reconnect to another instance, spacing the closes evenly over spread to avoid a
+// reconnect stampede. Connections opened from now on are closed as soon as they open.
+// Drain returns when every connection has closed or ctx is done, and reports how many
+// connections it closed.
+func (h *Hub) Drain(ctx context.Context, spread time.Duration) int {
+	h.draining.Store(true)
+
+	h.connMu.Lock()
+	clients := make([]*Client, 0, len(h.conns))
+	for c := range h.conns {
+		clients = append(clients, c)
+	}
+	h.connMu.Unlock()
+
+	h.logger.Info("draining websocket connections",
+		zap.Int("connections", len(clients)),
+		zap.Duration("spread", spread),
+	)
+
+	var gap time.Duration
+	if len(clients) > 0 {
+		gap = spread / time.Duration(len(clients))
+	}
+	for i, c := range clients {
+		c.closeForDrain()
+		if gap <= 0 || i == len(clients)-1 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			// Out of time: close the rest at once.
+			for _, rest := range clients[i+1:] {
+				rest.closeForDrain()
+			}
+			return len(clients)
+		case <-time.After(gap):
+		}
+	}
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for h.Connections() > 0 {
+		select {
+		case <-ctx.Done():
+			return len(clients)
+		case <-ticker.C:
+		}
+	}
+	return len(clients)
+}
+
+// closeForDrain asks WritePump to close the connection.
+func (c *Client) closeForDrain() {
+	c.drainOnce.Do(func() { close(c.drain) })
+}
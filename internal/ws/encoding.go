@@ -0,0 +1,76 @@
+package ws
+
+import (
+	"encoding/json"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+
+	trackingv1 "github.com/Kilat-Pet-Delivery/service-tracking/proto/tracking/v1"
+)
+
+// WebSocket subprotocols a client can ask for with Sec-WebSocket-Protocol. Without one,
+// every frame is JSON.
+const (
+	// SubprotocolJSON sends every frame as JSON text.
+	SubprotocolJSON = "tracking.v1.json"
+	// SubprotocolProtobuf sends location updates as binary trackingv1.LocationFrame
+	// messages and every other frame as JSON text.
+	SubprotocolProtobuf = "tracking.v1.protobuf"
+)
+
+// Subprotocols lists the subprotocols the server accepts, in order of preference.
+var Subprotocols = []string{SubprotocolProtobuf, SubprotocolJSON}
+
+// encodeLocation encodes a location update as a LocationFrame, or returns nil if it
+// cannot be encoded, in which case binary clients are sent the JSON frame.
+func (s *shard) encodeLocation(update *TrackingUpdate, seq uint64) []byte {
+	frame := &trackingv1.LocationFrame{
+		Seq:            seq,
+		BookingId:      update.BookingID[:],
+		RunnerId:       update.RunnerID[:],
+		Latitude:       update.Latitude,
+		Longitude:      update.Longitude,
+		SpeedKmh:       float32(update.Speed),
+		HeadingDegrees: float32(update.Heading),
+		TimestampMs:    update.Timestamp.UnixMilli(),
+		Historical:     update.Historical,
+	}
+	if v := update.Viewport; v != nil {
+		frame.Viewport = &trackingv1.Viewport{
+			MinLatitude:  v.MinLatitude,
+			MinLongitude: v.MinLongitude,
+			MaxLatitude:  v.MaxLatitude,
+			MaxLongitude: v.MaxLongitude,
+		}
+	}
+	data, err := proto.Marshal(frame)
+	if err != nil {
+		s.logger.Error("failed to marshal location frame", zap.Error(err))
+		return nil
+	}
+	return data
+}
+
+// decodeLocation reads the update back from a JSON location_update frame relayed by
+// another instance, so it can be encoded for binary clients here.
+func (s *shard) decodeLocation(data []byte) *TrackingUpdate {
+	var frame struct {
+		Data *TrackingUpdate `json:"data"`
+	}
+	if err := json.Unmarshal(data, &frame); err != nil {
+		s.logger.Warn("failed to decode relayed location frame", zap.Error(err))
+		return nil
+	}
+	return frame.Data
+}
+
+// messageType returns the WebSocket message type of a queued frame. Every JSON frame
+// the hub sends is an object, so anything else is a binary message.
+func messageType(data []byte) int {
+	if len(data) > 0 && data[0] == '{' {
+		return websocket.TextMessage
+	}
+	return websocket.BinaryMessage
+}
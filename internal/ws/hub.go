@@ -1,8 +1,12 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
+	"hash/fnv"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,13 +25,88 @@ const (
 	pingPeriod = (pongWait * 9) / 10
 
 	// maxMessageSize is the maximum message size allowed from peer.
-	maxMessageSize = 512
+	// Large enough to carry an auth_refresh control message with a JWT.
+	maxMessageSize = 4096
+
+	// authCheckPeriod is how often a connection's token expiry is checked.
+	authCheckPeriod = 5 * time.Second
+
+	// authExpiryWarning is how long before token expiry clients are asked to refresh.
+	authExpiryWarning = time.Minute
+
+	// closeTokenExpired is the WebSocket close code sent when a token expires unrefreshed.
+	closeTokenExpired = 4001
+
+	// snapshotTimeout bounds how long building a join snapshot may take.
+	snapshotTimeout = 5 * time.Second
+
+	// MaxSnapshotHistory caps the number of recent waypoints a client may request in its snapshot.
+	MaxSnapshotHistory = 100
+
+	// upstreamMessageSize is the maximum message size allowed from peers that send data
+	// upstream, such as runners streaming batches of locations.
+	upstreamMessageSize = 64 * 1024
 )
 
+// Control message types exchanged over the WebSocket connection.
+const (
+	msgTypeAuthRefresh   = "auth_refresh"
+	msgTypeAuthRefreshed = "auth_refreshed"
+	msgTypeAuthExpiring  = "auth_expiring"
+	msgTypeAuthError     = "auth_error"
+)
+
+// TokenValidator validates a token presented over an open connection and returns its expiry.
+type TokenValidator func(token string) (time.Time, error)
+
+// controlMessage is a client-to-server control frame.
+type controlMessage struct {
+	Type  string `json:"type"`
+	Token string `json:"token,omitempty"`
+}
+
+// authStatus is a server-to-client frame describing the connection's auth state.
+type authStatus struct {
+	Type      string     `json:"type"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
 // TrackingUpdate represents a real-time GPS position update sent to WebSocket clients.
 type TrackingUpdate struct {
-	BookingID uuid.UUID `json:"booking_id"`
-	RunnerID  uuid.UUID `json:"runner_id"`
+	BookingID uuid.UUID     `json:"booking_id"`
+	RunnerID  uuid.UUID     `json:"runner_id"`
+	Latitude  float64       `json:"latitude"`
+	Longitude float64       `json:"longitude"`
+	Speed     float64       `json:"speed_kmh"`
+	Heading   float64       `json:"heading_degrees"`
+	Timestamp time.Time     `json:"timestamp"`
+	Viewport  *ViewportHint `json:"viewport,omitempty"`
+	// Historical is set for locations consumed long after they were recorded, while the
+	// service catches up on a backlog; clients should draw them without moving the live marker.
+	Historical bool `json:"historical,omitempty"`
+}
+
+// ViewportHint is a suggested map bounding box so clients can frame the map without geometry math.
+type ViewportHint struct {
+	MinLatitude  float64 `json:"min_latitude"`
+	MinLongitude float64 `json:"min_longitude"`
+	MaxLatitude  float64 `json:"max_latitude"`
+	MaxLongitude float64 `json:"max_longitude"`
+}
+
+// ETAUpdate represents a change in a booking's estimated arrival time.
+type ETAUpdate struct {
+	BookingID          uuid.UUID `json:"booking_id"`
+	RemainingKm        float64   `json:"remaining_km"`
+	SpeedKmh           float64   `json:"speed_kmh"`
+	EstimatedMinutes   float64   `json:"estimated_minutes"`
+	EstimatedArrivalAt time.Time `json:"estimated_arrival_at"`
+	Source             string    `json:"source"`
+}
+
+// Position is a single GPS fix in a snapshot.
+type Position struct {
 	Latitude  float64   `json:"latitude"`
 	Longitude float64   `json:"longitude"`
 	Speed     float64   `json:"speed_kmh"`
@@ -35,159 +114,443 @@ type TrackingUpdate struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// Snapshot is the current state of a booking's trip, sent to a client when it joins the room
+// so the map is populated before the next location update.
+type Snapshot struct {
+	BookingID       uuid.UUID     `json:"booking_id"`
+	RunnerID        uuid.UUID     `json:"runner_id"`
+	Status          string        `json:"status"`
+	TotalDistanceKm float64       `json:"total_distance_km"`
+	LastPosition    *Position     `json:"last_position,omitempty"`
+	RecentWaypoints []Position    `json:"recent_waypoints,omitempty"`
+	Viewport        *ViewportHint `json:"viewport,omitempty"`
+}
+
+// SnapshotFunc builds the snapshot for a booking with up to history recent waypoints.
+// It returns nil if there is nothing to send.
+type SnapshotFunc func(ctx context.Context, bookingID uuid.UUID, history int) *Snapshot
+
+// Notification is a typed event pushed to a booking room, framed as {"type": ..., "data": ...}.
+type Notification struct {
+	BookingID uuid.UUID
+	Type      string
+	Data      interface{}
+}
+
 // Client represents a single WebSocket connection subscribed to a booking's tracking.
 type Client struct {
 	Conn      *websocket.Conn
 	BookingID uuid.UUID
 	Send      chan []byte
+
+	// ValidateToken re-validates tokens sent in auth_refresh messages.
+	// If nil, the connection never expires.
+	ValidateToken TokenValidator
+
+	// SnapshotHistory is how many recent waypoints to include in the snapshot sent on register.
+	SnapshotHistory int
+
+	// ShareID is the share link a public viewer connected with, or uuid.Nil.
+	ShareID uuid.UUID
+
+	// Scope decides which frames and fields the client is sent. NewClient sets
+	// ScopeViewer; authenticated connections must be given the scope of their role
+	// before they are registered.
+	Scope Scope
+
+	// Role is the role of the authenticated user, or empty for public viewers connected
+	// with a share link or widget token. Announcements can be targeted by role.
+	Role string
+
+	// ResumeSeq and ResumeEpoch are the cursor a reconnecting client passed to receive
+	// the frames it missed; an empty epoch means it is not resuming.
+	ResumeSeq   uint64
+	ResumeEpoch string
+
+	// Binary is set for connections that negotiated SubprotocolProtobuf; they are sent
+	// location updates as binary frames, one frame per message.
+	Binary bool
+
+	// UserID is the authenticated user, or uuid.Nil for public viewers. It tells
+	// whether a booking participant is online when deciding on push notifications.
+	UserID uuid.UUID
+
+	// OnMessage, if set, receives every frame other than control frames, for
+	// connections that send data upstream such as runners'. It is called on the read
+	// goroutine, so the next frame is not read until it returns. Such clients are not
+	// registered in a room.
+	OnMessage func(message []byte)
+
+	// OnClose, if set, is called once the connection has closed.
+	OnClose func()
+
+	control   chan []byte  // server-originated frames; never closed by the hub
+	expiresAt atomic.Int64 // token expiry in unix nanoseconds; 0 means no expiry
+	warned    atomic.Bool  // whether auth_expiring was sent for the current token
+
+	drain     chan struct{} // closed to make WritePump close the connection for a drain
+	drainOnce sync.Once
+
+	// pending is the latest location frame coalesced while Send was full; wake tells
+	// WritePump about it.
+	pendingMu sync.Mutex
+	pending   []byte
+	wake      chan struct{}
+
+	// laggingSince and droppedFrames track a full client under the slow client policy.
+	// They are only used by the hub shard owning the client's room.
+	laggingSince  time.Time
+	droppedFrames int
+
+	// admission is the connection slot held under the hub's limits, if any.
+	admission *Admission
 }
 
-// ChatMessage represents a chat message sent via WebSocket.
-type ChatMessage struct {
-	Type       string    `json:"type"` // always "chat_message"
-	BookingID  uuid.UUID `json:"booking_id"`
-	MessageID  uuid.UUID `json:"message_id"`
-	SenderID   uuid.UUID `json:"sender_id"`
-	SenderRole string    `json:"sender_role"`
-	MsgType    string    `json:"message_type"`
-	Content    string    `json:"content"`
-	CreatedAt  time.Time `json:"created_at"`
+// NewClient creates a client for a booking room. expiresAt is the expiry of the
+// token used to open the connection; a zero value disables expiry.
+func NewClient(conn *websocket.Conn, bookingID uuid.UUID, expiresAt time.Time, validate TokenValidator) *Client {
+	c := &Client{
+		Conn:          conn,
+		BookingID:     bookingID,
+		Send:          make(chan []byte, 256),
+		ValidateToken: validate,
+		Scope:         ScopeViewer,
+		control:       make(chan []byte, 8),
+		drain:         make(chan struct{}),
+		wake:          make(chan struct{}, 1),
+	}
+	if conn != nil {
+		c.Binary = conn.Subprotocol() == SubprotocolProtobuf
+	}
+	c.setExpiry(expiresAt)
+	return c
 }
 
-// Hub manages WebSocket connections organized by booking rooms.
-type Hub struct {
-	rooms      map[uuid.UUID]map[*Client]bool // bookingID -> set of clients
-	register   chan *Client
-	unregister chan *Client
-	broadcast  chan *TrackingUpdate
-	chatBcast  chan *ChatMessage
-	mu         sync.RWMutex
-	logger     *zap.Logger
-}
-
-// NewHub creates a new WebSocket hub.
-func NewHub(logger *zap.Logger) *Hub {
-	return &Hub{
-		rooms:      make(map[uuid.UUID]map[*Client]bool),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		broadcast:  make(chan *TrackingUpdate, 256),
-		chatBcast:  make(chan *ChatMessage, 256),
-		logger:     logger,
+// setExpiry records a new token expiry and re-arms the expiry warning.
+func (c *Client) setExpiry(t time.Time) {
+	if t.IsZero() {
+		c.expiresAt.Store(0)
+	} else {
+		c.expiresAt.Store(t.UnixNano())
 	}
+	c.warned.Store(false)
 }
 
-// Run starts the hub's event loop. Should be called in a goroutine.
-func (h *Hub) Run() {
-	for {
-		select {
-		case client := <-h.register:
-			h.mu.Lock()
-			if _, ok := h.rooms[client.BookingID]; !ok {
-				h.rooms[client.BookingID] = make(map[*Client]bool)
-			}
-			h.rooms[client.BookingID][client] = true
-			h.mu.Unlock()
-
-			h.logger.Debug("client registered",
-				zap.String("booking_id", client.BookingID.String()),
-			)
-
-		case client := <-h.unregister:
-			h.mu.Lock()
-			if clients, ok := h.rooms[client.BookingID]; ok {
-				if _, exists := clients[client]; exists {
-					delete(clients, client)
-					close(client.Send)
-					if len(clients) == 0 {
-						delete(h.rooms, client.BookingID)
-					}
-				}
-			}
-			h.mu.Unlock()
+// sendControl queues a server-originated frame, dropping it if the client is backed up.
+func (c *Client) sendControl(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	select {
+	case c.control <- data:
+	default:
+	}
+}
+
+// Reply queues a frame for an upstream client, reporting false if its buffer is full.
+func (c *Client) Reply(v interface{}) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	select {
+	case c.Send <- data:
+		return true
+	default:
+		return false
+	}
+}
 
-			h.logger.Debug("client unregistered",
-				zap.String("booking_id", client.BookingID.String()),
-			)
+// handleControl processes a client-to-server control frame, reporting whether raw was one.
+func (c *Client) handleControl(hub *Hub, raw []byte) bool {
+	var msg controlMessage
+	if err := json.Unmarshal(raw, &msg); err != nil || msg.Type != msgTypeAuthRefresh {
+		return false
+	}
+	if c.ValidateToken == nil {
+		return true
+	}
 
-		case update := <-h.broadcast:
-			data, err := json.Marshal(map[string]interface{}{
-				"type": "location_update",
-				"data": update,
-			})
-			if err != nil {
-				h.logger.Error("failed to marshal tracking update", zap.Error(err))
-				continue
-			}
+	expiresAt, err := c.ValidateToken(msg.Token)
+	if err != nil {
+		hub.logger.Debug("websocket token refresh rejected",
+			zap.String("booking_id", c.BookingID.String()),
+			zap.Error(err),
+		)
+		c.sendControl(authStatus{Type: msgTypeAuthError, Error: "invalid or expired token"})
+		return true
+	}
 
-			h.broadcastToRoom(update.BookingID, data)
+	c.setExpiry(expiresAt)
+	c.sendControl(authStatus{Type: msgTypeAuthRefreshed, ExpiresAt: &expiresAt})
+	return true
+}
 
-		case chatMsg := <-h.chatBcast:
-			data, err := json.Marshal(chatMsg)
-			if err != nil {
-				h.logger.Error("failed to marshal chat message", zap.Error(err))
-				continue
-			}
+// ChatMessage represents a chat message sent via WebSocket.
+type ChatMessage struct {
+	Type        string           `json:"type"` // always "chat_message"
+	BookingID   uuid.UUID        `json:"booking_id"`
+	MessageID   uuid.UUID        `json:"message_id"`
+	SenderID    uuid.UUID        `json:"sender_id"`
+	SenderRole  string           `json:"sender_role"`
+	MsgType     string           `json:"message_type"`
+	Content     string           `json:"content"`
+	Attachments []ChatAttachment `json:"attachments,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// ChatAttachment is a media file referenced by a chat message frame.
+type ChatAttachment struct {
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	MimeType     string `json:"mime_type"`
+	SizeBytes    int64  `json:"size_bytes"`
+}
+
+// RoleViewer is the announcement audience of clients without a Role.
+const RoleViewer = "viewer"
+
+// Announcement is an operational message pushed to booking rooms, framed as
+// {"type": "announcement", "data": ...}.
+type Announcement struct {
+	// BookingIDs are the rooms to send to; empty means every room.
+	BookingIDs []uuid.UUID
+	// Roles limits the announcement to clients with one of these roles (RoleViewer for
+	// public viewers); empty means every client.
+	Roles []string
+	Data  interface{}
+}
 
-			h.broadcastToRoom(chatMsg.BookingID, data)
+// audience reports whether the announcement is for a client.
+func (a *Announcement) audience(c *Client) bool {
+	if len(a.Roles) == 0 {
+		return true
+	}
+	role := c.Role
+	if role == "" {
+		role = RoleViewer
+	}
+	for _, r := range a.Roles {
+		if r == role {
+			return true
 		}
 	}
+	return false
 }
 
-// Register adds a client to the hub.
+// directMessage is a frame addressed to a single client rather than a room.
+type directMessage struct {
+	client *Client
+	data   []byte
+}
+
+// Hub manages WebSocket connections organized by booking rooms. Rooms are spread over
+// shards by booking ID, each with its own event loop, so fanning out a busy booking's
+// frames only delays the rooms on its shard.
+type Hub struct {
+	shards   []*shard
+	snapshot SnapshotFunc
+	logger   *zap.Logger
+
+	// instanceID tells this hub's relayed frames apart from other instances'.
+	instanceID   uuid.UUID
+	relay        Relay
+	relayOut     chan relayedFrame
+	relayDropped atomic.Int64
+
+	limits   ConnectionLimits
+	admitted admissions
+
+	resume ResumeConfig
+
+	policy          SlowClientPolicy
+	framesDropped   atomic.Int64
+	framesCoalesced atomic.Int64
+	slowDisconnects atomic.Int64
+
+	// conns holds every open connection, in rooms or upstream, for metrics and draining.
+	connMu   sync.Mutex
+	conns    map[*Client]struct{}
+	draining atomic.Bool
+}
+
+// NewHub creates a new WebSocket hub with the given number of shards, or one per
+// available CPU if shards is not positive.
+func NewHub(shards int, logger *zap.Logger) *Hub {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	h := &Hub{
+		shards:     make([]*shard, shards),
+		conns:      make(map[*Client]struct{}),
+		logger:     logger,
+		instanceID: uuid.New(),
+		policy:     DefaultSlowClientPolicy,
+		admitted: admissions{
+			perRoom:  make(map[uuid.UUID]int),
+			perUser:  make(map[uuid.UUID]int),
+			rejected: make(map[string]int64),
+		},
+	}
+	for i := range h.shards {
+		h.shards[i] = newShard(h, logger.With(zap.Int("hub_shard", i)))
+	}
+	return h
+}
+
+// Run starts the event loop of every shard, and the relay if one is used, and blocks.
+// Should be called in a goroutine.
+func (h *Hub) Run() {
+	if h.relay != nil {
+		go h.publishRelayed(context.Background())
+		go h.receiveRelayed(context.Background())
+	}
+	for _, s := range h.shards[1:] {
+		go s.run()
+	}
+	h.shards[0].run()
+}
+
+// shardFor returns the shard that owns a booking's room.
+func (h *Hub) shardFor(bookingID uuid.UUID) *shard {
+	if len(h.shards) == 1 {
+		return h.shards[0]
+	}
+	f := fnv.New32a()
+	_, _ = f.Write(bookingID[:])
+	return h.shards[f.Sum32()%uint32(len(h.shards))]
+}
+
+// Register adds a client to the hub and, if a snapshot function is set, sends the client
+// a snapshot of the trip's current state. A client resuming from a cursor the room still
+// has is sent the frames it missed instead.
 func (h *Hub) Register(client *Client) {
-	h.register <- client
+	resumed := make(chan bool, 1)
+	h.shardFor(client.BookingID).register <- registration{client: client, resumed: resumed}
+	if !<-resumed && h.snapshot != nil {
+		h.sendSnapshot(client)
+	}
 }
 
 // Unregister removes a client from the hub.
 func (h *Hub) Unregister(client *Client) {
-	h.unregister <- client
+	h.shardFor(client.BookingID).unregister <- client
 }
 
 // Broadcast sends a tracking update to all clients watching the specified booking.
 func (h *Hub) Broadcast(update *TrackingUpdate) {
-	h.broadcast <- update
+	h.shardFor(update.BookingID).broadcast <- update
 }
 
 // BroadcastChat sends a chat message to all clients watching the specified booking.
 func (h *Hub) BroadcastChat(msg *ChatMessage) {
-	h.chatBcast <- msg
+	h.shardFor(msg.BookingID).chatBcast <- msg
 }
 
-// broadcastToRoom sends raw data to all clients in a booking room.
-func (h *Hub) broadcastToRoom(bookingID uuid.UUID, data []byte) {
-	h.mu.RLock()
-	clients, ok := h.rooms[bookingID]
-	h.mu.RUnlock()
+// BroadcastETA sends an ETA update to all clients watching the specified booking.
+func (h *Hub) BroadcastETA(update *ETAUpdate) {
+	h.shardFor(update.BookingID).etaBcast <- update
+}
+
+// Notify sends a typed notification to all clients watching the specified booking.
+func (h *Hub) Notify(n *Notification) {
+	h.shardFor(n.BookingID).notify <- n
+}
+
+// Announce sends an announcement to the clients it targets. Every shard sends it to the
+// targeted rooms it owns.
+func (h *Hub) Announce(a *Announcement) {
+	for _, s := range h.shards {
+		s.announce <- a
+	}
+}
+
+// SetSnapshotFunc sets the function used to build the snapshot sent to newly registered
+// clients. Must be called before clients connect.
+func (h *Hub) SetSnapshotFunc(fn SnapshotFunc) {
+	h.snapshot = fn
+}
+
+// sendSnapshot builds a snapshot for a newly registered client and sends it to that client only.
+func (h *Hub) sendSnapshot(client *Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+
+	snap := h.snapshot(ctx, client.BookingID, client.SnapshotHistory)
+	if snap == nil {
+		return
+	}
 
+	data, err := json.Marshal(map[string]interface{}{
+		"type": "snapshot",
+		"data": snap,
+	})
+	if err != nil {
+		h.logger.Error("failed to marshal snapshot", zap.Error(err))
+		return
+	}
+	data, ok := Redact(client.Scope, data)
 	if !ok {
 		return
 	}
+	h.shardFor(client.BookingID).direct <- directMessage{client: client, data: data}
+}
 
-	for client := range clients {
-		select {
-		case client.Send <- data:
-		default:
-			h.mu.Lock()
-			delete(clients, client)
-			close(client.Send)
-			if len(clients) == 0 {
-				delete(h.rooms, bookingID)
-			}
-			h.mu.Unlock()
+// QueueDepth returns the number of frames waiting to be fanned out to rooms, over all shards.
+func (h *Hub) QueueDepth() int {
+	depth := 0
+	for _, s := range h.shards {
+		depth += s.queueDepth()
+	}
+	return depth
+}
+
+// IsUserConnected reports whether userID has a connection open in the booking's room.
+func (h *Hub) IsUserConnected(bookingID, userID uuid.UUID) bool {
+	s := h.shardFor(bookingID)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for client := range s.rooms[bookingID] {
+		if client.UserID == userID {
+			return true
 		}
 	}
+	return false
+}
+
+// roomCount returns the number of rooms over all shards.
+func (h *Hub) roomCount() int {
+	rooms := 0
+	for _, s := range h.shards {
+		s.mu.RLock()
+		rooms += len(s.rooms)
+		s.mu.RUnlock()
+	}
+	return rooms
 }
 
 // ReadPump pumps messages from the WebSocket connection to the hub.
-// It reads messages and discards them (clients only receive, they don't send tracking data).
+// Room clients only receive tracking data; the only messages they send are control
+// frames such as auth_refresh, everything else is discarded. Upstream clients pass
+// their other frames to OnMessage.
 func (c *Client) ReadPump(hub *Hub) {
+	hub.track(c)
 	defer func() {
+		hub.untrack(c)
 		hub.Unregister(c)
 		c.Conn.Close()
+		if c.OnClose != nil {
+			c.OnClose()
+		}
 	}()
 
-	c.Conn.SetReadLimit(maxMessageSize)
+	if c.OnMessage != nil {
+		c.Conn.SetReadLimit(upstreamMessageSize)
+	} else {
+		c.Conn.SetReadLimit(maxMessageSize)
+	}
 	_ = c.Conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.Conn.SetPongHandler(func(string) error {
 		_ = c.Conn.SetReadDeadline(time.Now().Add(pongWait))
@@ -195,21 +558,26 @@ func (c *Client) ReadPump(hub *Hub) {
 	})
 
 	for {
-		_, _, err := c.Conn.ReadMessage()
+		_, message, err := c.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
 				hub.logger.Warn("websocket read error", zap.Error(err))
 			}
 			break
 		}
+		if !c.handleControl(hub, message) && c.OnMessage != nil {
+			c.OnMessage(message)
+		}
 	}
 }
 
 // WritePump pumps messages from the hub to the WebSocket connection.
 func (c *Client) WritePump(hub *Hub) {
 	ticker := time.NewTicker(pingPeriod)
+	authTicker := time.NewTicker(authCheckPeriod)
 	defer func() {
 		ticker.Stop()
+		authTicker.Stop()
 		c.Conn.Close()
 	}()
 
@@ -223,23 +591,81 @@ func (c *Client) WritePump(hub *Hub) {
 				return
 			}
 
+			if c.Binary {
+				if err := c.writeSeparately(message); err != nil {
+					return
+				}
+				continue
+			}
+
 			w, err := c.Conn.NextWriter(websocket.TextMessage)
 			if err != nil {
 				return
 			}
 			_, _ = w.Write(message)
 
-			// Drain any queued messages into the current write.
+			// Drain any queued messages into the current write, followed by the latest
+			// location coalesced while the buffer was full.
 			n := len(c.Send)
 			for i := 0; i < n; i++ {
 				_, _ = w.Write([]byte("\n"))
 				_, _ = w.Write(<-c.Send)
 			}
+			if pending := c.takePending(); pending != nil {
+				_, _ = w.Write([]byte("\n"))
+				_, _ = w.Write(pending)
+			}
 
 			if err := w.Close(); err != nil {
 				return
 			}
 
+		case <-c.wake:
+			// Queued frames are older than the pending location; they go first.
+			if len(c.Send) > 0 {
+				continue
+			}
+			pending := c.takePending()
+			if pending == nil {
+				continue
+			}
+			_ = c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.Conn.WriteMessage(messageType(pending), pending); err != nil {
+				return
+			}
+
+		case message := <-c.control:
+			_ = c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+
+		case <-authTicker.C:
+			exp := c.expiresAt.Load()
+			if exp == 0 {
+				continue
+			}
+			expiresAt := time.Unix(0, exp)
+			remaining := time.Until(expiresAt)
+			if remaining <= 0 {
+				hub.logger.Debug("closing websocket with expired token",
+					zap.String("booking_id", c.BookingID.String()),
+				)
+				_ = c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+				_ = c.Conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(closeTokenExpired, "token expired"))
+				return
+			}
+			if remaining <= authExpiryWarning && !c.warned.Swap(true) {
+				c.sendControl(authStatus{Type: msgTypeAuthExpiring, ExpiresAt: &expiresAt})
+			}
+
+		case <-c.drain:
+			_ = c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			_ = c.Conn.WriteMessage(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseServiceRestart, "instance draining"))
+			return
+
 		case <-ticker.C:
 			_ = c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -248,3 +674,22 @@ func (c *Client) WritePump(hub *Hub) {
 		}
 	}
 }
+
+// writeSeparately writes a frame, the frames queued behind it and the pending location
+// as one message each, since binary and text frames cannot share a message.
+func (c *Client) writeSeparately(message []byte) error {
+	if err := c.Conn.WriteMessage(messageType(message), message); err != nil {
+		return err
+	}
+	n := len(c.Send)
+	for i := 0; i < n; i++ {
+		next := <-c.Send
+		if err := c.Conn.WriteMessage(messageType(next), next); err != nil {
+			return err
+		}
+	}
+	if pending := c.takePending(); pending != nil {
+		return c.Conn.WriteMessage(messageType(pending), pending)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package ws
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Connection limit scopes, as reported in LimitError and the rejection metric.
+const (
+	LimitTotal = "total"
+	LimitRoom  = "room"
+	LimitUser  = "user"
+)
+
+// ConnectionLimits caps the WebSocket connections of an instance. Zero means no limit.
+type ConnectionLimits struct {
+	MaxConnections int
+	// MaxPerRoom caps the connections watching one booking.
+	MaxPerRoom int
+	// MaxPerUser caps the connections of one authenticated user.
+	MaxPerUser int
+}
+
+// LimitError is returned by Admit when a connection would exceed a limit.
+type LimitError struct {
+	Scope string
+	Limit int
+}
+
+func (e *LimitError) Error() string {
+	switch e.Scope {
+	case LimitRoom:
+		return fmt.Sprintf("the booking already has %d connections", e.Limit)
+	case LimitUser:
+		return fmt.Sprintf("the user already has %d connections", e.Limit)
+	default:
+		return fmt.Sprintf("the instance already has %d connections", e.Limit)
+	}
+}
+
+// admissions counts the connections admitted against the hub's limits.
+type admissions struct {
+	mu      sync.Mutex
+	total   int
+	perRoom map[uuid.UUID]int
+	perUser map[uuid.UUID]int
+
+	rejected map[string]int64
+}
+
+// Admission is a connection slot reserved by Admit. It must be released when the
+// connection closes, or right away if the upgrade fails.
+type Admission struct {
+	hub       *Hub
+	bookingID uuid.UUID
+	userID    uuid.UUID
+	once      sync.Once
+}
+
+// UseConnectionLimits sets the limits Admit enforces. Must be called before clients connect.
+func (h *Hub) UseConnectionLimits(l ConnectionLimits) {
+	h.limits = l
+}
+
+// Admit reserves a slot for a connection to a booking's room by a user, before the
+// connection is upgraded. bookingID is uuid.Nil for connections outside rooms and userID
+// is uuid.Nil for public viewers; those limits are not applied to them. It returns a
+// *LimitError if a limit is reached.
+func (h *Hub) Admit(bookingID, userID uuid.UUID) (*Admission, error) {
+	a := &h.admitted
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var err *LimitError
+	switch {
+	case h.limits.MaxConnections > 0 && a.total >= h.limits.MaxConnections:
+		err = &LimitError{Scope: LimitTotal, Limit: h.limits.MaxConnections}
+	case h.limits.MaxPerRoom > 0 && bookingID != uuid.Nil && a.perRoom[bookingID] >= h.limits.MaxPerRoom:
+		err = &LimitError{Scope: LimitRoom, Limit: h.limits.MaxPerRoom}
+	case h.limits.MaxPerUser > 0 && userID != uuid.Nil && a.perUser[userID] >= h.limits.MaxPerUser:
+		err = &LimitError{Scope: LimitUser, Limit: h.limits.MaxPerUser}
+	}
+	if err != nil {
+		a.rejected[err.Scope]++
+		return nil, err
+	}
+
+	a.total++
+	if bookingID != uuid.Nil {
+		a.perRoom[bookingID]++
+	}
+	if userID != uuid.Nil {
+		a.perUser[userID]++
+	}
+	return &Admission{hub: h, bookingID: bookingID, userID: userID}, nil
+}
+
+// Release frees the slot. Calling it more than once has no effect.
+func (adm *Admission) Release() {
+	adm.once.Do(func() {
+		a := &adm.hub.admitted
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		a.total--
+		if adm.bookingID != uuid.Nil {
+			if a.perRoom[adm.bookingID]--; a.perRoom[adm.bookingID] <= 0 {
+				delete(a.perRoom, adm.bookingID)
+			}
+		}
+		if adm.userID != uuid.Nil {
+			if a.perUser[adm.userID]--; a.perUser[adm.userID] <= 0 {
+				delete(a.perUser, adm.userID)
+			}
+		}
+	})
+}
+
+// Hold ties an admission to the client, releasing it when the connection closes.
+func (c *Client) Hold(adm *Admission) {
+	c.admission = adm
+}
+
+// rejectedConnections returns the number of connections refused per limit scope.
+func (h *Hub) rejectedConnections() map[string]int64 {
+	a := &h.admitted
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return map[string]int64{
+		LimitTotal: a.rejected[LimitTotal],
+		LimitRoom:  a.rejected[LimitRoom],
+		LimitUser:  a.rejected[LimitUser],
+	}
+}
@@ -0,0 +1,76 @@
+package ws
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// QueueCapacity returns the number of frames the hub can queue before senders block.
+func (h *Hub) QueueCapacity() int {
+	capacity := 0
+	for _, s := range h.shards {
+		capacity += s.queueCapacity()
+	}
+	return capacity
+}
+
+// maxShardSaturation returns the queue saturation of the busiest shard.
+func (h *Hub) maxShardSaturation() float64 {
+	saturation := 0.0
+	for _, s := range h.shards {
+		if v := float64(s.queueDepth()) / float64(s.queueCapacity()); v > saturation {
+			saturation = v
+		}
+	}
+	return saturation
+}
+
+// ServeHTTP exports the connection and queue gauges that autoscalers scale the tracking
+// fleet on, in the Prometheus text format.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	rooms := h.roomCount()
+	depth, capacity := h.QueueDepth(), h.QueueCapacity()
+	draining := 0
+	if h.Draining() {
+		draining = 1
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP tracking_ws_connections Open WebSocket connections on this instance.")
+	fmt.Fprintln(w, "# TYPE tracking_ws_connections gauge")
+	fmt.Fprintf(w, "tracking_ws_connections %d\n", h.Connections())
+	fmt.Fprintln(w, "# HELP tracking_ws_rooms Booking rooms with at least one viewer on this instance.")
+	fmt.Fprintln(w, "# TYPE tracking_ws_rooms gauge")
+	fmt.Fprintf(w, "tracking_ws_rooms %d\n", rooms)
+	fmt.Fprintln(w, "# HELP tracking_ws_broadcast_queue_depth Frames waiting to be fanned out to rooms.")
+	fmt.Fprintln(w, "# TYPE tracking_ws_broadcast_queue_depth gauge")
+	fmt.Fprintf(w, "tracking_ws_broadcast_queue_depth %d\n", depth)
+	fmt.Fprintln(w, "# HELP tracking_ws_broadcast_queue_saturation Broadcast queue depth as a fraction of its capacity.")
+	fmt.Fprintln(w, "# TYPE tracking_ws_broadcast_queue_saturation gauge")
+	fmt.Fprintf(w, "tracking_ws_broadcast_queue_saturation %g\n", float64(depth)/float64(capacity))
+	fmt.Fprintln(w, "# HELP tracking_ws_hub_shard_saturation_max Broadcast queue saturation of the busiest hub shard.")
+	fmt.Fprintln(w, "# TYPE tracking_ws_hub_shard_saturation_max gauge")
+	fmt.Fprintf(w, "tracking_ws_hub_shard_saturation_max %g\n", h.maxShardSaturation())
+	fmt.Fprintln(w, "# HELP tracking_ws_relay_dropped_total Frames that could not be relayed to other instances.")
+	fmt.Fprintln(w, "# TYPE tracking_ws_relay_dropped_total counter")
+	fmt.Fprintf(w, "tracking_ws_relay_dropped_total %d\n", h.relayDropped.Load())
+	fmt.Fprintln(w, "# HELP tracking_ws_frames_dropped_total Frames dropped because a client's send buffer was full.")
+	fmt.Fprintln(w, "# TYPE tracking_ws_frames_dropped_total counter")
+	fmt.Fprintf(w, "tracking_ws_frames_dropped_total %d\n", h.framesDropped.Load())
+	fmt.Fprintln(w, "# HELP tracking_ws_frames_coalesced_total Location frames superseded by a newer one before a slow client could read them.")
+	fmt.Fprintln(w, "# TYPE tracking_ws_frames_coalesced_total counter")
+	fmt.Fprintf(w, "tracking_ws_frames_coalesced_total %d\n", h.framesCoalesced.Load())
+	fmt.Fprintln(w, "# HELP tracking_ws_slow_disconnects_total Clients disconnected for not keeping up with their frames.")
+	fmt.Fprintln(w, "# TYPE tracking_ws_slow_disconnects_total counter")
+	fmt.Fprintf(w, "tracking_ws_slow_disconnects_total %d\n", h.slowDisconnects.Load())
+	fmt.Fprintln(w, "# HELP tracking_ws_connections_rejected_total Connections refused because a connection limit was reached.")
+	fmt.Fprintln(w, "# TYPE tracking_ws_connections_rejected_total counter")
+	rejected := h.rejectedConnections()
+	for _, scope := range []string{LimitTotal, LimitRoom, LimitUser} {
+		fmt.Fprintf(w, "tracking_ws_connections_rejected_total{limit=%q} %d\n", scope, rejected[scope])
+	}
+	fmt.Fprintln(w, "# HELP tracking_ws_draining Whether this instance is draining its connections ahead of shutdown.")
+	fmt.Fprintln(w, "# TYPE tracking_ws_draining gauge")
+	fmt.Fprintf(w, "tracking_ws_draining %d\n", draining)
+}
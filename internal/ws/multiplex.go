@@ -0,0 +1,180 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Subscription control actions and the frames answering them.
+const (
+	actionSubscribe   = "subscribe"
+	actionUnsubscribe = "unsubscribe"
+
+	msgTypeSubscribed        = "subscribed"
+	msgTypeUnsubscribed      = "unsubscribed"
+	msgTypeSubscriptionError = "subscription_error"
+)
+
+// subscriptionFrame is a client-to-server subscription request.
+type subscriptionFrame struct {
+	Action    string    `json:"action"`
+	BookingID uuid.UUID `json:"booking_id"`
+}
+
+// subscriptionStatus answers a subscription request, or reports that the server ended
+// a subscription.
+type subscriptionStatus struct {
+	Type      string    `json:"type"`
+	BookingID uuid.UUID `json:"booking_id"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// SubscriptionAuthorizer decides whether a multiplexed connection may follow a booking.
+type SubscriptionAuthorizer func(ctx context.Context, bookingID uuid.UUID) error
+
+// Multiplexer lets one connection follow many booking rooms, e.g. for a dispatch
+// dashboard, subscribing and unsubscribing with {"action": "subscribe", "booking_id": ...}
+// frames. Every room it follows has a member client of its own, so rooms see no
+// difference; that member's frames are forwarded to the connection wrapped as
+// {"booking_id": ..., "frame": ...}.
+type Multiplexer struct {
+	hub       *Hub
+	conn      *Client
+	authorize SubscriptionAuthorizer
+	max       int
+	logger    *zap.Logger
+
+	mu     sync.Mutex
+	closed bool
+	subs   map[uuid.UUID]*Client
+}
+
+// NewMultiplexer attaches a multiplexer to conn, a client that is not in any room,
+// allowing at most max subscriptions at once. Must be called before conn's pumps start.
+func NewMultiplexer(hub *Hub, conn *Client, authorize SubscriptionAuthorizer, max int, logger *zap.Logger) *Multiplexer {
+	m := &Multiplexer{
+		hub:       hub,
+		conn:      conn,
+		authorize: authorize,
+		max:       max,
+		logger:    logger,
+		subs:      make(map[uuid.UUID]*Client),
+	}
+	conn.OnMessage = m.handle
+	conn.OnClose = m.close
+	return m
+}
+
+// handle processes a subscription request.
+func (m *Multiplexer) handle(message []byte) {
+	var frame subscriptionFrame
+	if err := json.Unmarshal(message, &frame); err != nil || frame.BookingID == uuid.Nil {
+		m.conn.Reply(subscriptionStatus{Type: msgTypeSubscriptionError, Error: "expected an action and a booking_id"})
+		return
+	}
+
+	switch frame.Action {
+	case actionSubscribe:
+		m.subscribe(frame.BookingID)
+	case actionUnsubscribe:
+		m.unsubscribe(frame.BookingID)
+		m.conn.Reply(subscriptionStatus{Type: msgTypeUnsubscribed, BookingID: frame.BookingID})
+	default:
+		m.conn.Reply(subscriptionStatus{Type: msgTypeSubscriptionError, BookingID: frame.BookingID, Error: "unknown action " + frame.Action})
+	}
+}
+
+func (m *Multiplexer) subscribe(bookingID uuid.UUID) {
+	m.mu.Lock()
+	_, exists := m.subs[bookingID]
+	full := len(m.subs) >= m.max
+	m.mu.Unlock()
+	if exists {
+		m.conn.Reply(subscriptionStatus{Type: msgTypeSubscribed, BookingID: bookingID})
+		return
+	}
+	if full {
+		m.conn.Reply(subscriptionStatus{Type: msgTypeSubscriptionError, BookingID: bookingID, Error: "too many subscriptions"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	err := m.authorize(ctx, bookingID)
+	cancel()
+	if err != nil {
+		m.conn.Reply(subscriptionStatus{Type: msgTypeSubscriptionError, BookingID: bookingID, Error: err.Error()})
+		return
+	}
+
+	member := NewClient(nil, bookingID, time.Time{}, nil)
+	member.Role = m.conn.Role
+	member.Scope = m.conn.Scope
+	member.UserID = m.conn.UserID
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.subs[bookingID] = member
+	m.mu.Unlock()
+
+	// Confirm before registering, so the join snapshot follows the confirmation.
+	m.conn.Reply(subscriptionStatus{Type: msgTypeSubscribed, BookingID: bookingID})
+	go m.forward(bookingID, member)
+	m.hub.Register(member)
+}
+
+func (m *Multiplexer) unsubscribe(bookingID uuid.UUID) {
+	m.mu.Lock()
+	member, ok := m.subs[bookingID]
+	delete(m.subs, bookingID)
+	m.mu.Unlock()
+	if ok {
+		m.hub.Unregister(member)
+	}
+}
+
+// forward wraps a member's frames for the connection until the hub closes the member,
+// either because it was unsubscribed or because it fell behind.
+func (m *Multiplexer) forward(bookingID uuid.UUID, member *Client) {
+	prefix := []byte(`{"booking_id":"` + bookingID.String() + `","frame":`)
+	for data := range member.Send {
+		framed := make([]byte, 0, len(prefix)+len(data)+1)
+		framed = append(append(append(framed, prefix...), data...), '}')
+		select {
+		case m.conn.Send <- framed:
+		default:
+			// The connection is backed up; drop the frame rather than stall the others.
+		}
+	}
+
+	m.mu.Lock()
+	dropped := !m.closed && m.subs[bookingID] == member
+	if dropped {
+		delete(m.subs, bookingID)
+	}
+	m.mu.Unlock()
+	if dropped {
+		m.logger.Debug("multiplexed subscription dropped", zap.String("booking_id", bookingID.String()))
+		m.conn.Reply(subscriptionStatus{Type: msgTypeUnsubscribed, BookingID: bookingID, Error: "subscription fell behind"})
+	}
+}
+
+// close ends every subscription once the connection has closed.
+func (m *Multiplexer) close() {
+	m.mu.Lock()
+	m.closed = true
+	members := m.subs
+	m.subs = nil
+	m.mu.Unlock()
+
+	for _, member := range members {
+		m.hub.Unregister(member)
+	}
+}
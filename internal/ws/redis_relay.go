@@ -0,0 +1,49 @@
+package ws
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisRelay implements Relay with Redis pub/sub on a single channel shared by every
+// instance.
+type RedisRelay struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisRelay creates a relay publishing to and subscribing on channel.
+func NewRedisRelay(client *redis.Client, channel string) *RedisRelay {
+	return &RedisRelay{client: client, channel: channel}
+}
+
+// Publish sends payload to every subscribed instance.
+func (r *RedisRelay) Publish(ctx context.Context, payload []byte) error {
+	return r.client.Publish(ctx, r.channel, payload).Err()
+}
+
+// Subscribe calls handle with every payload published on the channel until ctx is done
+// or the connection is closed.
+func (r *RedisRelay) Subscribe(ctx context.Context, handle func(payload []byte)) error {
+	sub := r.client.Subscribe(ctx, r.channel)
+	defer func() { _ = sub.Close() }()
+
+	// Wait for the subscription to be confirmed so errors surface here.
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			handle([]byte(msg.Payload))
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// relayQueueSize is how many frames may wait to be published to other instances
+	// before new ones are dropped.
+	relayQueueSize = 1024
+
+	// relayRetryDelay is how long to wait before resubscribing after the relay failed.
+	relayRetryDelay = time.Second
+)
+
+// Relay carries room frames between the hubs of different instances, so a booking's
+// room spans every instance its viewers are connected to. Payloads are opaque to it.
+type Relay interface {
+	Publish(ctx context.Context, payload []byte) error
+	// Subscribe calls handle with every payload published by any instance, including
+	// this one, until ctx is done or the subscription fails.
+	Subscribe(ctx context.Context, handle func(payload []byte)) error
+}
+
+// relayEnvelope is a room frame as exchanged between instances.
+type relayEnvelope struct {
+	Origin    uuid.UUID `json:"origin"`
+	BookingID uuid.UUID `json:"booking_id"`
+	// MembersOnly frames, such as chat messages, are not sent to public viewers.
+	MembersOnly bool `json:"members_only,omitempty"`
+	// Latest frames are location updates, coalesced for slow clients.
+	Latest bool            `json:"latest,omitempty"`
+	Frame  json.RawMessage `json:"frame"`
+}
+
+// relayedFrame is an encoded frame for the clients of a room on this instance.
+type relayedFrame struct {
+	bookingID   uuid.UUID
+	data        []byte
+	membersOnly bool
+	latest      bool
+}
+
+// UseRelay shares room frames with the hubs of other instances through r: frames sent to
+// a room here are published, and frames published elsewhere are sent to the room's
+// clients here. Join snapshots and announcements stay local. Must be called before Run.
+func (h *Hub) UseRelay(r Relay) {
+	h.relay = r
+	h.relayOut = make(chan relayedFrame, relayQueueSize)
+}
+
+// relayFrame queues a locally originated frame for the other instances, dropping it if
+// the queue is full rather than holding up the shard.
+func (h *Hub) relayFrame(f relayedFrame) {
+	if h.relay == nil {
+		return
+	}
+	select {
+	case h.relayOut <- f:
+	default:
+		h.relayDropped.Add(1)
+	}
+}
+
+// publishRelayed publishes queued frames to the relay.
+func (h *Hub) publishRelayed(ctx context.Context) {
+	for f := range h.relayOut {
+		payload, err := json.Marshal(relayEnvelope{
+			Origin:      h.instanceID,
+			BookingID:   f.bookingID,
+			MembersOnly: f.membersOnly,
+			Latest:      f.latest,
+			Frame:       f.data,
+		})
+		if err != nil {
+			h.logger.Error("failed to marshal relayed frame", zap.Error(err))
+			continue
+		}
+		if err := h.relay.Publish(ctx, payload); err != nil {
+			h.relayDropped.Add(1)
+			h.logger.Warn("failed to relay frame",
+				zap.String("booking_id", f.bookingID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// receiveRelayed sends frames published by other instances to the rooms here,
+// resubscribing whenever the subscription fails.
+func (h *Hub) receiveRelayed(ctx context.Context) {
+	for {
+		err := h.relay.Subscribe(ctx, func(payload []byte) {
+			var env relayEnvelope
+			if err := json.Unmarshal(payload, &env); err != nil {
+				h.logger.Warn("discarding malformed relayed frame", zap.Error(err))
+				return
+			}
+			if env.Origin == h.instanceID {
+				return
+			}
+			h.shardFor(env.BookingID).remote <- relayedFrame{bookingID: env.BookingID, data: env.Frame, membersOnly: env.MembersOnly, latest: env.Latest}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		h.logger.Warn("hub relay subscription ended, resubscribing", zap.Error(err))
+		time.Sleep(relayRetryDelay)
+	}
+}
@@ -0,0 +1,179 @@
+package ws
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// msgTypeResumed confirms that a reconnecting client was sent the frames it missed.
+const msgTypeResumed = "resumed"
+
+// historySweepInterval is how often shards forget the histories of abandoned rooms.
+const historySweepInterval = 30 * time.Second
+
+// ResumeConfig controls the per-room frame history that reconnecting clients resume from.
+type ResumeConfig struct {
+	// BufferSize is how many recent frames each room keeps; zero disables resuming and
+	// sequence numbers.
+	BufferSize int
+	// Retention is how long a room's history is kept after its last client left.
+	Retention time.Duration
+}
+
+// resumeStatus is sent instead of a snapshot when a client resumed.
+type resumeStatus struct {
+	Type     string `json:"type"`
+	Seq      uint64 `json:"seq"`
+	Epoch    string `json:"epoch"`
+	Replayed int    `json:"replayed"`
+}
+
+// roomHistory numbers a room's frames and keeps the most recent ones in a ring. Its
+// epoch changes whenever the history is recreated, so cursors from an earlier history,
+// or another instance, are not mistaken for this one's.
+type roomHistory struct {
+	epoch     string
+	seq       uint64
+	frames    []roomFrame
+	next      int
+	watchedAt time.Time
+}
+
+// UseResume enables sequence numbers and resuming. Must be called before Run.
+func (h *Hub) UseResume(c ResumeConfig) {
+	h.resume = c
+}
+
+// watch returns the room's history, creating it if needed, and records that the room
+// is being watched. It returns nil if resuming is disabled.
+func (s *shard) watch(bookingID uuid.UUID, now time.Time) *roomHistory {
+	if s.hub.resume.BufferSize <= 0 {
+		return nil
+	}
+	hist, ok := s.histories[bookingID]
+	if !ok {
+		hist = &roomHistory{epoch: uuid.NewString(), frames: make([]roomFrame, 0, s.hub.resume.BufferSize)}
+		s.histories[bookingID] = hist
+	}
+	hist.watchedAt = now
+	return hist
+}
+
+// record numbers a room frame and keeps it for replay, adding a "seq" field to data.
+// location is the update a location frame was encoded from, for clients that want it
+// in binary. Unless it is members-only, the frame is also rendered for each scope, or
+// withheld from all but ScopeFull if it cannot be. Frames of rooms without a history
+// are not numbered.
+func (s *shard) record(bookingID uuid.UUID, data []byte, location *TrackingUpdate, membersOnly bool) roomFrame {
+	f := roomFrame{data: data, membersOnly: membersOnly}
+	hist, ok := s.histories[bookingID]
+	if ok {
+		hist.seq++
+		f.seq = hist.seq
+		f.data = stamp(data, `"seq":`+strconv.FormatUint(hist.seq, 10))
+	}
+	if location != nil {
+		f.binary = s.encodeLocation(location, f.seq)
+	}
+	if !membersOnly {
+		encode := func(u *TrackingUpdate) []byte { return s.encodeLocation(u, f.seq) }
+		var rendered bool
+		if f.scoped, rendered = renderScopes(f.data, location, encode); !rendered {
+			s.logger.Warn("withholding unreadable frame from restricted scopes", zap.String("booking_id", bookingID.String()))
+			f.scoped = withheldFromAll()
+		}
+	}
+	if !ok {
+		return f
+	}
+
+	if len(hist.frames) < cap(hist.frames) {
+		hist.frames = append(hist.frames, f)
+	} else {
+		hist.frames[hist.next] = f
+		hist.next = (hist.next + 1) % len(hist.frames)
+	}
+	return f
+}
+
+// stampDirect adds the room's current sequence number and epoch to a frame addressed
+// to one client, such as a snapshot, so the client knows where to resume from.
+func (s *shard) stampDirect(bookingID uuid.UUID, data []byte) []byte {
+	hist, ok := s.histories[bookingID]
+	if !ok {
+		return data
+	}
+	return stamp(data, `"seq":`+strconv.FormatUint(hist.seq, 10)+`,"epoch":"`+hist.epoch+`"`)
+}
+
+// replay sends a newly registered client the frames it missed since its resume cursor,
+// reporting false if it asked for none, they are no longer all available or the client
+// could not take them; it then gets a snapshot instead.
+func (s *shard) replay(client *Client, hist *roomHistory) bool {
+	if hist == nil || client.ResumeEpoch == "" || client.ResumeEpoch != hist.epoch || client.ResumeSeq > hist.seq {
+		return false
+	}
+	// The oldest kept frame must directly follow the cursor, or frames were lost.
+	oldest := hist.seq + 1
+	if len(hist.frames) > 0 {
+		oldest = hist.frames[hist.next%len(hist.frames)].seq
+	}
+	if client.ResumeSeq+1 < oldest {
+		return false
+	}
+
+	replayed := 0
+	for i := range hist.frames {
+		f := hist.frames[(hist.next+i)%len(hist.frames)]
+		if f.seq <= client.ResumeSeq || (f.membersOnly && !isRoomMember(client)) {
+			continue
+		}
+		payload, ok := f.payload(client)
+		if !ok {
+			continue
+		}
+		if !s.deliver(client, payload, false) {
+			return false
+		}
+		replayed++
+	}
+
+	// Sent after the missed frames, so the client can tell when it has caught up.
+	data, err := json.Marshal(resumeStatus{Type: msgTypeResumed, Seq: hist.seq, Epoch: hist.epoch, Replayed: replayed})
+	if err != nil {
+		s.logger.Error("failed to marshal resume status", zap.Error(err))
+		return false
+	}
+	return s.deliver(client, data, false)
+}
+
+// sweepHistories forgets the histories of rooms nobody has watched for the retention period.
+func (s *shard) sweepHistories(now time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for bookingID, hist := range s.histories {
+		if _, watched := s.rooms[bookingID]; watched {
+			hist.watchedAt = now
+		} else if now.Sub(hist.watchedAt) > s.hub.resume.Retention {
+			delete(s.histories, bookingID)
+		}
+	}
+}
+
+// stamp inserts fields at the start of a JSON object frame.
+func stamp(data []byte, fields string) []byte {
+	if len(data) < 2 || data[0] != '{' {
+		return data
+	}
+	out := make([]byte, 0, len(data)+len(fields)+1)
+	out = append(out, '{')
+	out = append(out, fields...)
+	if data[1] != '}' {
+		out = append(out, ',')
+	}
+	return append(out, data[1:]...)
+}
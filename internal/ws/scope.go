@@ -0,0 +1,240 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Scope decides which frames, and which of their fields, a client is sent.
+type Scope string
+
+const (
+	// ScopeFull is for staff: admins and support agents. They are sent every frame as is.
+	ScopeFull Scope = "full"
+	// ScopeCustomer is for the booking's owner and other customer-side users. They are
+	// not sent the frames in customerWithheldFrames, and the fields in
+	// customerHiddenFields are removed from the frames they are sent.
+	ScopeCustomer Scope = "customer"
+	// ScopeRunner is for the booking's runner. They are not sent the frames in
+	// runnerWithheldFrames, and the fields in runnerHiddenFields are removed from the
+	// frames they are sent.
+	ScopeRunner Scope = "runner"
+	// ScopeViewer is for public viewers connected with a share link or widget token.
+	// They are not sent chat messages or the frames in viewerWithheldFrames, and the
+	// fields in viewerHiddenFields are removed from the frames they are sent.
+	ScopeViewer Scope = "viewer"
+)
+
+// customerHiddenFields are removed, at any depth, from the frames sent to customers:
+// the state of the runner's device.
+var customerHiddenFields = map[string]bool{
+	"battery_pct": true,
+	"network":     true,
+}
+
+// customerWithheldFrames are the notification types not sent to customers at all,
+// since they are for the runner and operations rather than about the delivery.
+var customerWithheldFrames = map[string]bool{
+	"safety_alert": true,
+	"low_battery":  true,
+}
+
+// runnerHiddenFields are removed, at any depth, from the frames sent to runners.
+var runnerHiddenFields = map[string]bool{}
+
+// runnerWithheldFrames are the notification types not sent to runners at all, since
+// they are raised to operations about the runner's own driving.
+var runnerWithheldFrames = map[string]bool{
+	"safety_alert": true,
+}
+
+// viewerHiddenFields are removed, at any depth, from the frames sent to viewers.
+var viewerHiddenFields = map[string]bool{
+	"runner_id":   true,
+	"speed_kmh":   true,
+	"battery_pct": true,
+	"network":     true,
+}
+
+// viewerWithheldFrames are the notification types not sent to viewers at all, since
+// they are about the runner rather than the delivery.
+var viewerWithheldFrames = map[string]bool{
+	"safety_alert": true,
+	"low_battery":  true,
+}
+
+// scopeRule is what a scope other than ScopeFull is not sent.
+type scopeRule struct {
+	hiddenFields   map[string]bool
+	withheldFrames map[string]bool
+}
+
+// scopeRules are the rules of the scopes that are not sent every frame as is.
+var scopeRules = map[Scope]scopeRule{
+	ScopeCustomer: {hiddenFields: customerHiddenFields, withheldFrames: customerWithheldFrames},
+	ScopeRunner:   {hiddenFields: runnerHiddenFields, withheldFrames: runnerWithheldFrames},
+	ScopeViewer:   {hiddenFields: viewerHiddenFields, withheldFrames: viewerWithheldFrames},
+}
+
+// Redact returns a JSON document without the fields hidden from a scope, at any depth,
+// or data itself if it has none, so payloads sent outside the socket, such as REST
+// responses, match the scope's frames. It reports false if the document cannot be read.
+func Redact(scope Scope, data []byte) ([]byte, bool) {
+	rule, ok := scopeRules[scope]
+	if !ok || len(rule.hiddenFields) == 0 {
+		return data, true
+	}
+	v, ok := decodeFrame(data)
+	if !ok {
+		return nil, false
+	}
+	if !redact(v, rule.hiddenFields) {
+		return data, true
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// isRoomMember reports whether a client is sent members-only frames such as chat
+// messages, rather than being a public viewer.
+func isRoomMember(c *Client) bool {
+	return c.Scope != ScopeViewer
+}
+
+// scopedFrame is a frame as rendered for one scope.
+type scopedFrame struct {
+	data   []byte
+	binary []byte
+	// withheld is set if the scope is not sent the frame at all.
+	withheld bool
+}
+
+// renderScopes renders a JSON frame for every scope it differs for: scopes it is
+// withheld from, and scopes with fields to hide from it. location is the update a
+// location frame was encoded from, for the scopes' binary encodings. It reports false
+// if the frame cannot be read, in which case it must be withheld from every scope but
+// ScopeFull.
+func renderScopes(data []byte, location *TrackingUpdate, encode func(*TrackingUpdate) []byte) (map[Scope]scopedFrame, bool) {
+	v, ok := decodeFrame(data)
+	if !ok {
+		return nil, false
+	}
+	frame, _ := v.(map[string]interface{})
+	frameType, _ := frame["type"].(string)
+
+	var scoped map[Scope]scopedFrame
+	for scope, rule := range scopeRules {
+		var f scopedFrame
+		switch {
+		case rule.withheldFrames[frameType]:
+			f.withheld = true
+		case hasField(v, rule.hiddenFields):
+			copied, _ := decodeFrame(data)
+			redact(copied, rule.hiddenFields)
+			out, err := json.Marshal(copied)
+			if err != nil {
+				return nil, false
+			}
+			f.data = out
+			if location != nil && encode != nil {
+				f.binary = encode(location.forScope(rule.hiddenFields))
+			}
+		default:
+			continue
+		}
+		if scoped == nil {
+			scoped = make(map[Scope]scopedFrame, len(scopeRules))
+		}
+		scoped[scope] = f
+	}
+	return scoped, true
+}
+
+// withheldFromAll returns the rendering of a frame that no scope but ScopeFull is sent.
+func withheldFromAll() map[Scope]scopedFrame {
+	scoped := make(map[Scope]scopedFrame, len(scopeRules))
+	for scope := range scopeRules {
+		scoped[scope] = scopedFrame{withheld: true}
+	}
+	return scoped
+}
+
+// decodeFrame decodes a JSON document, keeping numbers as they were written.
+func decodeFrame(data []byte) (interface{}, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// hasField reports whether a decoded JSON value has any of the fields, at any depth.
+func hasField(v interface{}, fields map[string]bool) bool {
+	if len(fields) == 0 {
+		return false
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for key, child := range t {
+			if fields[key] || hasField(child, fields) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, child := range t {
+			if hasField(child, fields) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redact removes fields from a decoded JSON value, at any depth, reporting whether any
+// were found.
+func redact(v interface{}, fields map[string]bool) bool {
+	if len(fields) == 0 {
+		return false
+	}
+	removed := false
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for key, child := range t {
+			if fields[key] {
+				delete(t, key)
+				removed = true
+				continue
+			}
+			if redact(child, fields) {
+				removed = true
+			}
+		}
+	case []interface{}:
+		for _, child := range t {
+			if redact(child, fields) {
+				removed = true
+			}
+		}
+	}
+	return removed
+}
+
+// forScope returns a copy of a location update without the hidden fields, for encoding
+// as a binary frame.
+func (u *TrackingUpdate) forScope(hidden map[string]bool) *TrackingUpdate {
+	scoped := *u
+	if hidden["runner_id"] {
+		scoped.RunnerID = uuid.Nil
+	}
+	if hidden["speed_kmh"] {
+		scoped.Speed = 0
+	}
+	return &scoped
+}
@@ -0,0 +1,305 @@
+package ws
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// shard owns a subset of the hub's rooms and fans out their frames on its own event loop.
+type shard struct {
+	hub        *Hub
+	rooms      map[uuid.UUID]map[*Client]bool // bookingID -> set of clients
+	histories  map[uuid.UUID]*roomHistory     // recent frames for resuming; only used by run
+	register   chan registration
+	unregister chan *Client
+	broadcast  chan *TrackingUpdate
+	chatBcast  chan *ChatMessage
+	etaBcast   chan *ETAUpdate
+	notify     chan *Notification
+	announce   chan *Announcement
+	direct     chan directMessage
+	remote     chan relayedFrame // frames relayed from other instances
+	mu         sync.RWMutex
+	logger     *zap.Logger
+}
+
+func newShard(hub *Hub, logger *zap.Logger) *shard {
+	return &shard{
+		hub:        hub,
+		rooms:      make(map[uuid.UUID]map[*Client]bool),
+		histories:  make(map[uuid.UUID]*roomHistory),
+		register:   make(chan registration),
+		unregister: make(chan *Client),
+		broadcast:  make(chan *TrackingUpdate, 256),
+		chatBcast:  make(chan *ChatMessage, 256),
+		etaBcast:   make(chan *ETAUpdate, 256),
+		notify:     make(chan *Notification, 256),
+		announce:   make(chan *Announcement, 16),
+		direct:     make(chan directMessage, 256),
+		remote:     make(chan relayedFrame, 256),
+		logger:     logger,
+	}
+}
+
+// registration is a client joining a room. resumed reports whether the client was sent
+// the frames it missed instead of needing a snapshot.
+type registration struct {
+	client  *Client
+	resumed chan<- bool
+}
+
+// run is the shard's event loop.
+func (s *shard) run() {
+	var sweep <-chan time.Time
+	if s.hub.resume.BufferSize > 0 {
+		ticker := time.NewTicker(historySweepInterval)
+		defer ticker.Stop()
+		sweep = ticker.C
+	}
+
+	for {
+		select {
+		case reg := <-s.register:
+			client := reg.client
+			s.mu.Lock()
+			if _, ok := s.rooms[client.BookingID]; !ok {
+				s.rooms[client.BookingID] = make(map[*Client]bool)
+			}
+			s.rooms[client.BookingID][client] = true
+			s.mu.Unlock()
+
+			reg.resumed <- s.replay(client, s.watch(client.BookingID, time.Now()))
+
+			s.logger.Debug("client registered",
+				zap.String("booking_id", client.BookingID.String()),
+			)
+
+		case client := <-s.unregister:
+			s.mu.Lock()
+			if clients, ok := s.rooms[client.BookingID]; ok {
+				if _, exists := clients[client]; exists {
+					delete(clients, client)
+					close(client.Send)
+					if len(clients) == 0 {
+						delete(s.rooms, client.BookingID)
+					}
+				}
+			}
+			s.mu.Unlock()
+			if hist, ok := s.histories[client.BookingID]; ok {
+				hist.watchedAt = time.Now()
+			}
+
+			s.logger.Debug("client unregistered",
+				zap.String("booking_id", client.BookingID.String()),
+			)
+
+		case update := <-s.broadcast:
+			data, err := json.Marshal(map[string]interface{}{
+				"type": "location_update",
+				"data": update,
+			})
+			if err != nil {
+				s.logger.Error("failed to marshal tracking update", zap.Error(err))
+				continue
+			}
+
+			s.hub.relayFrame(relayedFrame{bookingID: update.BookingID, data: data, latest: true})
+			s.sendToRoom(update.BookingID, s.record(update.BookingID, data, update, false), nil, true)
+
+		case chatMsg := <-s.chatBcast:
+			data, err := json.Marshal(chatMsg)
+			if err != nil {
+				s.logger.Error("failed to marshal chat message", zap.Error(err))
+				continue
+			}
+
+			s.hub.relayFrame(relayedFrame{bookingID: chatMsg.BookingID, data: data, membersOnly: true})
+			s.broadcastToRoomMembers(chatMsg.BookingID, s.record(chatMsg.BookingID, data, nil, true))
+
+		case eta := <-s.etaBcast:
+			data, err := json.Marshal(map[string]interface{}{
+				"type": "eta_update",
+				"data": eta,
+			})
+			if err != nil {
+				s.logger.Error("failed to marshal eta update", zap.Error(err))
+				continue
+			}
+
+			s.hub.relayFrame(relayedFrame{bookingID: eta.BookingID, data: data})
+			s.broadcastToRoom(eta.BookingID, s.record(eta.BookingID, data, nil, false))
+
+		case n := <-s.notify:
+			data, err := json.Marshal(map[string]interface{}{
+				"type": n.Type,
+				"data": n.Data,
+			})
+			if err != nil {
+				s.logger.Error("failed to marshal notification",
+					zap.String("type", n.Type),
+					zap.Error(err),
+				)
+				continue
+			}
+
+			// Scopes a notification type is withheld from are decided when it is recorded.
+			s.hub.relayFrame(relayedFrame{bookingID: n.BookingID, data: data})
+			s.broadcastToRoom(n.BookingID, s.record(n.BookingID, data, nil, false))
+
+		case a := <-s.announce:
+			data, err := json.Marshal(map[string]interface{}{
+				"type": "announcement",
+				"data": a.Data,
+			})
+			if err != nil {
+				s.logger.Error("failed to marshal announcement", zap.Error(err))
+				continue
+			}
+
+			// Rooms owned by other shards are simply not found here.
+			bookingIDs := a.BookingIDs
+			if len(bookingIDs) == 0 {
+				bookingIDs = s.roomIDs()
+			}
+			for _, bookingID := range bookingIDs {
+				s.sendToRoom(bookingID, roomFrame{data: data}, a.audience, false)
+			}
+
+		case m := <-s.direct:
+			s.sendToClient(m.client, s.stampDirect(m.client.BookingID, m.data))
+
+		case f := <-s.remote:
+			var location *TrackingUpdate
+			if f.latest {
+				location = s.decodeLocation(f.data)
+			}
+			frame := s.record(f.bookingID, f.data, location, f.membersOnly)
+			var include func(*Client) bool
+			if frame.membersOnly {
+				include = isRoomMember
+			}
+			s.sendToRoom(f.bookingID, frame, include, f.latest)
+
+		case now := <-sweep:
+			s.sweepHistories(now)
+		}
+	}
+}
+
+// queueDepth returns the number of frames waiting in the shard's queues.
+func (s *shard) queueDepth() int {
+	return len(s.broadcast) + len(s.chatBcast) + len(s.etaBcast) + len(s.notify) + len(s.announce) + len(s.direct) + len(s.remote)
+}
+
+// queueCapacity returns the number of frames the shard can queue before senders block.
+func (s *shard) queueCapacity() int {
+	return cap(s.broadcast) + cap(s.chatBcast) + cap(s.etaBcast) + cap(s.notify) + cap(s.announce) + cap(s.direct) + cap(s.remote)
+}
+
+// roomFrame is an encoded frame for a room's clients, kept for replay if the room is
+// numbered.
+type roomFrame struct {
+	seq         uint64
+	data        []byte
+	binary      []byte // data as a protobuf message, for clients that negotiated it
+	membersOnly bool
+
+	// scoped are the frame as sent to the scopes it differs for, because it is withheld
+	// from them or has fields hidden from them; other scopes are sent data and binary.
+	scoped map[Scope]scopedFrame
+}
+
+// payload returns the encoding of the frame the client asked for, in its scope. It
+// reports false if the frame is withheld from the client's scope.
+func (f roomFrame) payload(c *Client) ([]byte, bool) {
+	data, binary := f.data, f.binary
+	if scoped, ok := f.scoped[c.Scope]; ok {
+		if scoped.withheld {
+			return nil, false
+		}
+		data, binary = scoped.data, scoped.binary
+	}
+	if c.Binary && binary != nil {
+		return binary, true
+	}
+	return data, true
+}
+
+// broadcastToRoom sends a frame to all clients in a booking room.
+func (s *shard) broadcastToRoom(bookingID uuid.UUID, f roomFrame) {
+	s.sendToRoom(bookingID, f, nil, false)
+}
+
+// broadcastToRoomMembers sends a frame to the clients in a booking room that are not
+// public viewers.
+func (s *shard) broadcastToRoomMembers(bookingID uuid.UUID, f roomFrame) {
+	s.sendToRoom(bookingID, f, isRoomMember, false)
+}
+
+// roomIDs returns the booking IDs of the shard's rooms.
+func (s *shard) roomIDs() []uuid.UUID {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]uuid.UUID, 0, len(s.rooms))
+	for id := range s.rooms {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// sendToRoom sends a frame to the clients in a booking room that include accepts, or
+// to all of them if include is nil, disconnecting those the slow client policy gives up
+// on. latest marks location frames.
+func (s *shard) sendToRoom(bookingID uuid.UUID, f roomFrame, include func(*Client) bool, latest bool) {
+	s.mu.RLock()
+	clients, ok := s.rooms[bookingID]
+	s.mu.RUnlock()
+
+	if !ok {
+		return
+	}
+
+	for client := range clients {
+		if include != nil && !include(client) {
+			continue
+		}
+		payload, ok := f.payload(client)
+		if !ok {
+			continue
+		}
+		if !s.deliver(client, payload, latest) {
+			s.mu.Lock()
+			delete(clients, client)
+			close(client.Send)
+			if len(clients) == 0 {
+				delete(s.rooms, bookingID)
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// sendToClient sends raw data to a single client if it is still registered.
+func (s *shard) sendToClient(client *Client, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clients, ok := s.rooms[client.BookingID]
+	if !ok || !clients[client] {
+		return
+	}
+
+	if !s.deliver(client, data, false) {
+		delete(clients, client)
+		close(client.Send)
+		if len(clients) == 0 {
+			delete(s.rooms, client.BookingID)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_trip_tracks_runner_active;
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS dest_longitude;
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS dest_latitude;
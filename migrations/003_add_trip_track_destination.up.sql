@@ -0,0 +1,4 @@
+ALTER TABLE trip_tracks ADD COLUMN dest_latitude DOUBLE PRECISION;
+ALTER TABLE trip_tracks ADD COLUMN dest_longitude DOUBLE PRECISION;
+
+CREATE INDEX idx_trip_tracks_runner_active ON trip_tracks(runner_id, started_at) WHERE status = 'active';
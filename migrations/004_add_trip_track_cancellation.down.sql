@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_trip_tracks_cancel_reason;
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS cancel_note;
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS cancel_reason;
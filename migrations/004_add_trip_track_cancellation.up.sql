@@ -0,0 +1,5 @@
+ALTER TABLE trip_tracks ADD COLUMN cancel_reason VARCHAR(40);
+ALTER TABLE trip_tracks ADD COLUMN cancel_note TEXT;
+ALTER TABLE trip_tracks ADD COLUMN cancelled_at TIMESTAMPTZ;
+
+CREATE INDEX idx_trip_tracks_cancel_reason ON trip_tracks(cancel_reason) WHERE cancel_reason IS NOT NULL;
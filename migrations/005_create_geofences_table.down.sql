@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_geofences_booking_active;
+DROP INDEX IF EXISTS idx_geofences_booking;
+DROP TABLE IF EXISTS geofences;
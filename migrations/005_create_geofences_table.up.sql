@@ -0,0 +1,17 @@
+CREATE TABLE geofences (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    booking_id UUID NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    shape VARCHAR(20) NOT NULL,
+    center_latitude DOUBLE PRECISION,
+    center_longitude DOUBLE PRECISION,
+    radius_meters DOUBLE PRECISION,
+    vertices JSONB NOT NULL DEFAULT '[]',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    inside BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_geofences_booking ON geofences(booking_id);
+CREATE INDEX idx_geofences_booking_active ON geofences(booking_id) WHERE active;
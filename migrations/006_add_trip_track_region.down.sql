@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_trip_tracks_region;
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS region;
@@ -0,0 +1,3 @@
+ALTER TABLE trip_tracks ADD COLUMN region VARCHAR(32) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_trip_tracks_region ON trip_tracks(region);
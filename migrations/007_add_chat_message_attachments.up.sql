@@ -0,0 +1,15 @@
+-- chat_messages was historically created by the dev AutoMigrate only. Create
+-- it here, idempotently, so a fresh database can run the ALTERs below.
+CREATE TABLE IF NOT EXISTS chat_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    booking_id UUID NOT NULL,
+    sender_id UUID NOT NULL,
+    sender_role VARCHAR(20) NOT NULL,
+    message_type VARCHAR(20) NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_chat_messages_booking_id ON chat_messages(booking_id);
+
+ALTER TABLE chat_messages ADD COLUMN attachments JSONB NOT NULL DEFAULT '[]';
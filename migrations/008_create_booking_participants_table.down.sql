@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_booking_participants_runner;
+DROP INDEX IF EXISTS idx_booking_participants_owner;
+DROP TABLE IF EXISTS booking_participants;
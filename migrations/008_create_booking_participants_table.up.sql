@@ -0,0 +1,9 @@
+CREATE TABLE booking_participants (
+    booking_id UUID PRIMARY KEY,
+    owner_id UUID,
+    runner_id UUID,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_booking_participants_owner ON booking_participants(owner_id);
+CREATE INDEX idx_booking_participants_runner ON booking_participants(runner_id);
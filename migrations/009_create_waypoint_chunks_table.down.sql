@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_waypoint_chunks_time;
+DROP TABLE IF EXISTS waypoint_chunks;
+DROP INDEX IF EXISTS idx_waypoints_track_chunk;
+ALTER TABLE waypoints DROP COLUMN IF EXISTS chunk_seq;
@@ -0,0 +1,32 @@
+ALTER TABLE waypoints ADD COLUMN chunk_seq INTEGER NOT NULL DEFAULT 0;
+
+-- Assign existing waypoints to chunks of 256 (WaypointChunkSize) in recording order.
+UPDATE waypoints w
+SET chunk_seq = n.seq
+FROM (
+    SELECT id, (ROW_NUMBER() OVER (PARTITION BY trip_track_id ORDER BY recorded_at, id) - 1) / 256 AS seq
+    FROM waypoints
+) n
+WHERE w.id = n.id;
+
+CREATE INDEX idx_waypoints_track_chunk ON waypoints(trip_track_id, chunk_seq);
+
+CREATE TABLE waypoint_chunks (
+    trip_track_id UUID NOT NULL REFERENCES trip_tracks(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL,
+    point_count INTEGER NOT NULL,
+    min_latitude DOUBLE PRECISION NOT NULL,
+    min_longitude DOUBLE PRECISION NOT NULL,
+    max_latitude DOUBLE PRECISION NOT NULL,
+    max_longitude DOUBLE PRECISION NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (trip_track_id, seq)
+);
+
+INSERT INTO waypoint_chunks (trip_track_id, seq, point_count, min_latitude, min_longitude, max_latitude, max_longitude, started_at, ended_at)
+SELECT trip_track_id, chunk_seq, COUNT(*), MIN(latitude), MIN(longitude), MAX(latitude), MAX(longitude), MIN(recorded_at), MAX(recorded_at)
+FROM waypoints
+GROUP BY trip_track_id, chunk_seq;
+
+CREATE INDEX idx_waypoint_chunks_time ON waypoint_chunks(trip_track_id, started_at, ended_at);
@@ -0,0 +1 @@
+ALTER TABLE shared_trips DROP COLUMN IF EXISTS revoked_at;
@@ -0,0 +1,14 @@
+-- shared_trips was historically created by the dev AutoMigrate only. Create
+-- it here, idempotently, so a fresh database can run the ALTERs below.
+CREATE TABLE IF NOT EXISTS shared_trips (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    booking_id UUID NOT NULL,
+    share_token VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shared_trips_booking_id ON shared_trips(booking_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shared_trips_share_token ON shared_trips(share_token);
+
+ALTER TABLE shared_trips ADD COLUMN revoked_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+ALTER TABLE shared_trips DROP COLUMN IF EXISTS view_count;
+ALTER TABLE shared_trips DROP COLUMN IF EXISTS max_views;
@@ -0,0 +1,2 @@
+ALTER TABLE shared_trips ADD COLUMN max_views INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE shared_trips ADD COLUMN view_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE booking_participants DROP COLUMN IF EXISTS pet_species;
+DROP TABLE IF EXISTS temperature_thresholds;
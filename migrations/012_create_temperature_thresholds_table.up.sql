@@ -0,0 +1,17 @@
+CREATE TABLE temperature_thresholds (
+    species VARCHAR(32) PRIMARY KEY,
+    min_celsius DECIMAL(5,2) NOT NULL,
+    max_celsius DECIMAL(5,2) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (min_celsius < max_celsius)
+);
+
+INSERT INTO temperature_thresholds (species, min_celsius, max_celsius) VALUES
+    ('default', 10, 30),
+    ('dog', 7, 29),
+    ('cat', 10, 30),
+    ('rabbit', 5, 26),
+    ('bird', 18, 30),
+    ('reptile', 22, 32);
+
+ALTER TABLE booking_participants ADD COLUMN pet_species VARCHAR(32) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS end_weather;
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS start_weather;
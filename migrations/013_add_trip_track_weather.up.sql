@@ -0,0 +1,2 @@
+ALTER TABLE trip_tracks ADD COLUMN start_weather JSONB;
+ALTER TABLE trip_tracks ADD COLUMN end_weather JSONB;
@@ -0,0 +1,14 @@
+CREATE TABLE inbox_entries (
+    cursor BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    booking_id UUID NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    type VARCHAR(64) NOT NULL,
+    message_id UUID NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_inbox_entries_user_message ON inbox_entries(user_id, message_id);
+CREATE INDEX idx_inbox_entries_user_cursor ON inbox_entries(user_id, cursor);
+CREATE INDEX idx_inbox_entries_created_at ON inbox_entries(created_at);
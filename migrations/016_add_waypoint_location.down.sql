@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_waypoints_location;
+ALTER TABLE waypoints DROP COLUMN IF EXISTS location;
@@ -0,0 +1,7 @@
+CREATE EXTENSION IF NOT EXISTS postgis;
+
+-- Derived from latitude/longitude so every write path fills it without changes.
+ALTER TABLE waypoints ADD COLUMN location GEOGRAPHY(POINT, 4326)
+    GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography) STORED;
+
+CREATE INDEX idx_waypoints_location ON waypoints USING GIST (location);
@@ -0,0 +1,14 @@
+CREATE TABLE tracking_anomalies (
+    id UUID PRIMARY KEY,
+    trip_track_id UUID NOT NULL REFERENCES trip_tracks(id) ON DELETE CASCADE,
+    booking_id UUID NOT NULL,
+    runner_id UUID NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    detected_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_tracking_anomalies_track ON tracking_anomalies(trip_track_id, detected_at);
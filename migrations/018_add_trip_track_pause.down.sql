@@ -0,0 +1,2 @@
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS paused_seconds;
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS paused_at;
@@ -0,0 +1,2 @@
+ALTER TABLE trip_tracks ADD COLUMN paused_at TIMESTAMPTZ;
+ALTER TABLE trip_tracks ADD COLUMN paused_seconds BIGINT NOT NULL DEFAULT 0;
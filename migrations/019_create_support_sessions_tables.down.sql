@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS support_audit_events;
+DROP TABLE IF EXISTS support_sessions;
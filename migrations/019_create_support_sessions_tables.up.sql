@@ -0,0 +1,25 @@
+CREATE TABLE support_sessions (
+    id UUID PRIMARY KEY,
+    agent_id UUID NOT NULL,
+    booking_id UUID NOT NULL,
+    reason TEXT NOT NULL,
+    opened_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    closed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_support_sessions_agent ON support_sessions(agent_id);
+CREATE INDEX idx_support_sessions_booking ON support_sessions(booking_id);
+
+CREATE TABLE support_audit_events (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES support_sessions(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL,
+    booking_id UUID NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    source_ip VARCHAR(64) NOT NULL DEFAULT '',
+    occurred_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_support_audit_events_session ON support_audit_events(session_id, occurred_at);
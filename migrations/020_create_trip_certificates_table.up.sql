@@ -0,0 +1,9 @@
+CREATE TABLE trip_certificates (
+    trip_track_id UUID PRIMARY KEY REFERENCES trip_tracks(id) ON DELETE CASCADE,
+    booking_id UUID NOT NULL UNIQUE,
+    key_id VARCHAR(64) NOT NULL,
+    algorithm VARCHAR(16) NOT NULL,
+    payload TEXT NOT NULL,
+    signature TEXT NOT NULL,
+    issued_at TIMESTAMPTZ NOT NULL
+);
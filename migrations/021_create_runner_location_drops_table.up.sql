@@ -0,0 +1,7 @@
+CREATE TABLE runner_location_drops (
+    runner_id UUID NOT NULL,
+    day DATE NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (runner_id, day, reason)
+);
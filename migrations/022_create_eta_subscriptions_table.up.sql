@@ -0,0 +1,11 @@
+CREATE TABLE eta_subscriptions (
+    id UUID PRIMARY KEY,
+    booking_id UUID NOT NULL,
+    subscriber VARCHAR(64) NOT NULL,
+    threshold_seconds INTEGER NOT NULL,
+    callback_url TEXT NOT NULL DEFAULT '',
+    last_notified_eta TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_eta_subscriptions_booking_subscriber ON eta_subscriptions(booking_id, subscriber);
@@ -0,0 +1 @@
+ALTER TABLE waypoints DROP COLUMN IF EXISTS backfilled;
@@ -0,0 +1 @@
+ALTER TABLE waypoints ADD COLUMN backfilled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS last_recorded_at;
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS last_longitude;
+ALTER TABLE trip_tracks DROP COLUMN IF EXISTS last_latitude;
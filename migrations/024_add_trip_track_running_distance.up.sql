@@ -0,0 +1,20 @@
+ALTER TABLE trip_tracks ADD COLUMN last_latitude DOUBLE PRECISION;
+ALTER TABLE trip_tracks ADD COLUMN last_longitude DOUBLE PRECISION;
+ALTER TABLE trip_tracks ADD COLUMN last_recorded_at TIMESTAMPTZ;
+
+-- Seed the running distance and last position of trips in progress from their route.
+UPDATE trip_tracks t
+SET total_distance_km = r.km,
+    last_latitude = r.latitude,
+    last_longitude = r.longitude,
+    last_recorded_at = r.recorded_at
+FROM (
+    SELECT trip_track_id,
+        COALESCE(ST_Length(ST_MakeLine(location::geometry ORDER BY recorded_at, id)::geography), 0) / 1000 AS km,
+        (ARRAY_AGG(latitude ORDER BY recorded_at DESC, id DESC))[1] AS latitude,
+        (ARRAY_AGG(longitude ORDER BY recorded_at DESC, id DESC))[1] AS longitude,
+        MAX(recorded_at) AS recorded_at
+    FROM waypoints
+    GROUP BY trip_track_id
+) r
+WHERE t.id = r.trip_track_id AND t.status IN ('active', 'paused');
@@ -0,0 +1,15 @@
+CREATE TABLE track_alerts (
+    id UUID PRIMARY KEY,
+    trip_track_id UUID NOT NULL REFERENCES trip_tracks(id) ON DELETE CASCADE,
+    booking_id UUID NOT NULL,
+    runner_id UUID NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    waypoints INTEGER NOT NULL,
+    raised_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_track_alerts_track ON track_alerts(trip_track_id, raised_at);
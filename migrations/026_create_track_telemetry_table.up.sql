@@ -0,0 +1,10 @@
+CREATE TABLE track_telemetry (
+    id UUID PRIMARY KEY,
+    trip_track_id UUID NOT NULL REFERENCES trip_tracks(id) ON DELETE CASCADE,
+    booking_id UUID NOT NULL,
+    temperature_c DOUBLE PRECISION,
+    humidity_pct DOUBLE PRECISION,
+    recorded_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_track_telemetry_track ON track_telemetry(trip_track_id, recorded_at);
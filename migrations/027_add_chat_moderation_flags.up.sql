@@ -0,0 +1 @@
+ALTER TABLE chat_messages ADD COLUMN moderation_flags JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,4 @@
+ALTER TABLE waypoints
+    DROP COLUMN IF EXISTS accuracy_m,
+    DROP COLUMN IF EXISTS altitude_m,
+    DROP COLUMN IF EXISTS provider;
@@ -0,0 +1,4 @@
+ALTER TABLE waypoints
+    ADD COLUMN accuracy_m DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN altitude_m DOUBLE PRECISION,
+    ADD COLUMN provider VARCHAR(16) NOT NULL DEFAULT '';
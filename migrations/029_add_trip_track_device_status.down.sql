@@ -0,0 +1,4 @@
+ALTER TABLE trip_tracks
+    DROP COLUMN IF EXISTS device_battery,
+    DROP COLUMN IF EXISTS device_network,
+    DROP COLUMN IF EXISTS device_updated_at;
@@ -0,0 +1,4 @@
+ALTER TABLE trip_tracks
+    ADD COLUMN device_battery SMALLINT,
+    ADD COLUMN device_network VARCHAR(16),
+    ADD COLUMN device_updated_at TIMESTAMPTZ;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_trip_tracks_moved_at;
+
+ALTER TABLE trip_tracks
+    DROP COLUMN IF EXISTS moved_latitude,
+    DROP COLUMN IF EXISTS moved_longitude,
+    DROP COLUMN IF EXISTS moved_at,
+    DROP COLUMN IF EXISTS stalled_at;
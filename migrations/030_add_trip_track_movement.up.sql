@@ -0,0 +1,7 @@
+ALTER TABLE trip_tracks
+    ADD COLUMN moved_latitude DOUBLE PRECISION,
+    ADD COLUMN moved_longitude DOUBLE PRECISION,
+    ADD COLUMN moved_at TIMESTAMPTZ,
+    ADD COLUMN stalled_at TIMESTAMPTZ;
+
+CREATE INDEX idx_trip_tracks_moved_at ON trip_tracks(moved_at) WHERE status = 'active';
@@ -0,0 +1,15 @@
+-- track_events is append-only and has no foreign key to trip_tracks, so the log of a
+-- track deleted by a merge is kept.
+CREATE TABLE track_events (
+    id UUID PRIMARY KEY,
+    trip_track_id UUID NOT NULL,
+    booking_id UUID NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    actor_type VARCHAR(20) NOT NULL,
+    actor_id UUID,
+    reason TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_track_events_booking ON track_events(booking_id, occurred_at);
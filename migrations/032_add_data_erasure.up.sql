@@ -0,0 +1,16 @@
+CREATE TABLE erasure_requests (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    requested_by UUID,
+    status VARCHAR(20) NOT NULL,
+    erased JSONB NOT NULL DEFAULT '[]',
+    deferred JSONB NOT NULL DEFAULT '[]',
+    requested_at TIMESTAMPTZ NOT NULL,
+    completed_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_erasure_requests_user ON erasure_requests(user_id);
+CREATE INDEX idx_erasure_requests_status ON erasure_requests(status);
+
+ALTER TABLE chat_messages ADD COLUMN erased_at TIMESTAMPTZ;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_trip_tracks_completed_at;
@@ -0,0 +1 @@
+CREATE INDEX idx_trip_tracks_completed_at ON trip_tracks(completed_at) WHERE status = 'completed';
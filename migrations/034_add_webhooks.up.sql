@@ -0,0 +1,30 @@
+CREATE TABLE webhooks (
+    id UUID PRIMARY KEY,
+    account_id UUID NOT NULL,
+    booking_id UUID,
+    url TEXT NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    events JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_webhooks_account ON webhooks(account_id);
+CREATE INDEX idx_webhooks_booking ON webhooks(booking_id);
+
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY,
+    webhook_id UUID NOT NULL,
+    booking_id UUID NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_status_code INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
@@ -0,0 +1,38 @@
+// Package migrations embeds the SQL migrations so that the binary knows which schema
+// version it was built against.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Version returns the number of the newest up migration shipped with this build.
+func Version() (uint, error) {
+	names, err := fs.Glob(files, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+	var latest uint
+	for _, name := range names {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return 0, fmt.Errorf("migration %s has no version prefix", name)
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("migration %s: invalid version: %w", name, err)
+		}
+		latest = max(latest, uint(version))
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("no migrations embedded")
+	}
+	return latest, nil
+}
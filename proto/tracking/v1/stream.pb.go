@@ -0,0 +1,289 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: proto/tracking/v1/stream.proto
+
+package trackingv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// LocationFrame is a location update sent as a binary WebSocket frame to clients that
+// negotiated the tracking.v1.protobuf subprotocol, in place of the JSON location_update frame.
+type LocationFrame struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Room sequence number, or 0 if the room is not numbered.
+	Seq uint64 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Booking and runner IDs as 16-byte UUIDs.
+	BookingId      []byte  `protobuf:"bytes,2,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	RunnerId       []byte  `protobuf:"bytes,3,opt,name=runner_id,json=runnerId,proto3" json:"runner_id,omitempty"`
+	Latitude       float64 `protobuf:"fixed64,4,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude      float64 `protobuf:"fixed64,5,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	SpeedKmh       float32 `protobuf:"fixed32,6,opt,name=speed_kmh,json=speedKmh,proto3" json:"speed_kmh,omitempty"`
+	HeadingDegrees float32 `protobuf:"fixed32,7,opt,name=heading_degrees,json=headingDegrees,proto3" json:"heading_degrees,omitempty"`
+	// Time of the fix in Unix milliseconds.
+	TimestampMs int64     `protobuf:"varint,8,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
+	Viewport    *Viewport `protobuf:"bytes,9,opt,name=viewport,proto3" json:"viewport,omitempty"`
+	// Set for locations consumed long after they were recorded, while the service catches up.
+	Historical    bool `protobuf:"varint,10,opt,name=historical,proto3" json:"historical,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LocationFrame) Reset() {
+	*x = LocationFrame{}
+	mi := &file_proto_tracking_v1_stream_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LocationFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocationFrame) ProtoMessage() {}
+
+func (x *LocationFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tracking_v1_stream_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocationFrame.ProtoReflect.Descriptor instead.
+func (*LocationFrame) Descriptor() ([]byte, []int) {
+	return file_proto_tracking_v1_stream_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LocationFrame) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *LocationFrame) GetBookingId() []byte {
+	if x != nil {
+		return x.BookingId
+	}
+	return nil
+}
+
+func (x *LocationFrame) GetRunnerId() []byte {
+	if x != nil {
+		return x.RunnerId
+	}
+	return nil
+}
+
+func (x *LocationFrame) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *LocationFrame) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *LocationFrame) GetSpeedKmh() float32 {
+	if x != nil {
+		return x.SpeedKmh
+	}
+	return 0
+}
+
+func (x *LocationFrame) GetHeadingDegrees() float32 {
+	if x != nil {
+		return x.HeadingDegrees
+	}
+	return 0
+}
+
+func (x *LocationFrame) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+func (x *LocationFrame) GetViewport() *Viewport {
+	if x != nil {
+		return x.Viewport
+	}
+	return nil
+}
+
+func (x *LocationFrame) GetHistorical() bool {
+	if x != nil {
+		return x.Historical
+	}
+	return false
+}
+
+// Viewport is a suggested map bounding box.
+type Viewport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinLatitude   float64                `protobuf:"fixed64,1,opt,name=min_latitude,json=minLatitude,proto3" json:"min_latitude,omitempty"`
+	MinLongitude  float64                `protobuf:"fixed64,2,opt,name=min_longitude,json=minLongitude,proto3" json:"min_longitude,omitempty"`
+	MaxLatitude   float64                `protobuf:"fixed64,3,opt,name=max_latitude,json=maxLatitude,proto3" json:"max_latitude,omitempty"`
+	MaxLongitude  float64                `protobuf:"fixed64,4,opt,name=max_longitude,json=maxLongitude,proto3" json:"max_longitude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Viewport) Reset() {
+	*x = Viewport{}
+	mi := &file_proto_tracking_v1_stream_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Viewport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Viewport) ProtoMessage() {}
+
+func (x *Viewport) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tracking_v1_stream_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Viewport.ProtoReflect.Descriptor instead.
+func (*Viewport) Descriptor() ([]byte, []int) {
+	return file_proto_tracking_v1_stream_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Viewport) GetMinLatitude() float64 {
+	if x != nil {
+		return x.MinLatitude
+	}
+	return 0
+}
+
+func (x *Viewport) GetMinLongitude() float64 {
+	if x != nil {
+		return x.MinLongitude
+	}
+	return 0
+}
+
+func (x *Viewport) GetMaxLatitude() float64 {
+	if x != nil {
+		return x.MaxLatitude
+	}
+	return 0
+}
+
+func (x *Viewport) GetMaxLongitude() float64 {
+	if x != nil {
+		return x.MaxLongitude
+	}
+	return 0
+}
+
+var File_proto_tracking_v1_stream_proto protoreflect.FileDescriptor
+
+const file_proto_tracking_v1_stream_proto_rawDesc = "" +
+	"\n" +
+	"\x1eproto/tracking/v1/stream.proto\x12\vtracking.v1\"\xd3\x02\n" +
+	"\rLocationFrame\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x02 \x01(\fR\tbookingId\x12\x1b\n" +
+	"\trunner_id\x18\x03 \x01(\fR\brunnerId\x12\x1a\n" +
+	"\blatitude\x18\x04 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x05 \x01(\x01R\tlongitude\x12\x1b\n" +
+	"\tspeed_kmh\x18\x06 \x01(\x02R\bspeedKmh\x12'\n" +
+	"\x0fheading_degrees\x18\a \x01(\x02R\x0eheadingDegrees\x12!\n" +
+	"\ftimestamp_ms\x18\b \x01(\x03R\vtimestampMs\x121\n" +
+	"\bviewport\x18\t \x01(\v2\x15.tracking.v1.ViewportR\bviewport\x12\x1e\n" +
+	"\n" +
+	"historical\x18\n" +
+	" \x01(\bR\n" +
+	"historical\"\x9a\x01\n" +
+	"\bViewport\x12!\n" +
+	"\fmin_latitude\x18\x01 \x01(\x01R\vminLatitude\x12#\n" +
+	"\rmin_longitude\x18\x02 \x01(\x01R\fminLongitude\x12!\n" +
+	"\fmax_latitude\x18\x03 \x01(\x01R\vmaxLatitude\x12#\n" +
+	"\rmax_longitude\x18\x04 \x01(\x01R\fmaxLongitudeBMZKgithub.com/Kilat-Pet-Delivery/service-tracking/proto/tracking/v1;trackingv1b\x06proto3"
+
+var (
+	file_proto_tracking_v1_stream_proto_rawDescOnce sync.Once
+	file_proto_tracking_v1_stream_proto_rawDescData []byte
+)
+
+func file_proto_tracking_v1_stream_proto_rawDescGZIP() []byte {
+	file_proto_tracking_v1_stream_proto_rawDescOnce.Do(func() {
+		file_proto_tracking_v1_stream_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_tracking_v1_stream_proto_rawDesc), len(file_proto_tracking_v1_stream_proto_rawDesc)))
+	})
+	return file_proto_tracking_v1_stream_proto_rawDescData
+}
+
+var file_proto_tracking_v1_stream_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_tracking_v1_stream_proto_goTypes = []any{
+	(*LocationFrame)(nil), // 0: tracking.v1.LocationFrame
+	(*Viewport)(nil),      // 1: tracking.v1.Viewport
+}
+var file_proto_tracking_v1_stream_proto_depIdxs = []int32{
+	1, // 0: tracking.v1.LocationFrame.viewport:type_name -> tracking.v1.Viewport
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_proto_tracking_v1_stream_proto_init() }
+func file_proto_tracking_v1_stream_proto_init() {
+	if File_proto_tracking_v1_stream_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_tracking_v1_stream_proto_rawDesc), len(file_proto_tracking_v1_stream_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_tracking_v1_stream_proto_goTypes,
+		DependencyIndexes: file_proto_tracking_v1_stream_proto_depIdxs,
+		MessageInfos:      file_proto_tracking_v1_stream_proto_msgTypes,
+	}.Build()
+	File_proto_tracking_v1_stream_proto = out.File
+	file_proto_tracking_v1_stream_proto_goTypes = nil
+	file_proto_tracking_v1_stream_proto_depIdxs = nil
+}
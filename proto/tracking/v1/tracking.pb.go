@@ -0,0 +1,700 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: proto/tracking/v1/tracking.proto
+
+package trackingv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Waypoint struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Latitude       float64                `protobuf:"fixed64,2,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude      float64                `protobuf:"fixed64,3,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	SpeedKmh       float64                `protobuf:"fixed64,4,opt,name=speed_kmh,json=speedKmh,proto3" json:"speed_kmh,omitempty"`
+	HeadingDegrees float64                `protobuf:"fixed64,5,opt,name=heading_degrees,json=headingDegrees,proto3" json:"heading_degrees,omitempty"`
+	RecordedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=recorded_at,json=recordedAt,proto3" json:"recorded_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Waypoint) Reset() {
+	*x = Waypoint{}
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Waypoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Waypoint) ProtoMessage() {}
+
+func (x *Waypoint) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Waypoint.ProtoReflect.Descriptor instead.
+func (*Waypoint) Descriptor() ([]byte, []int) {
+	return file_proto_tracking_v1_tracking_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Waypoint) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Waypoint) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Waypoint) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Waypoint) GetSpeedKmh() float64 {
+	if x != nil {
+		return x.SpeedKmh
+	}
+	return 0
+}
+
+func (x *Waypoint) GetHeadingDegrees() float64 {
+	if x != nil {
+		return x.HeadingDegrees
+	}
+	return 0
+}
+
+func (x *Waypoint) GetRecordedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RecordedAt
+	}
+	return nil
+}
+
+type Cancellation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReasonCode    string                 `protobuf:"bytes,1,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"`
+	Note          string                 `protobuf:"bytes,2,opt,name=note,proto3" json:"note,omitempty"`
+	CancelledAt   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=cancelled_at,json=cancelledAt,proto3" json:"cancelled_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Cancellation) Reset() {
+	*x = Cancellation{}
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cancellation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cancellation) ProtoMessage() {}
+
+func (x *Cancellation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cancellation.ProtoReflect.Descriptor instead.
+func (*Cancellation) Descriptor() ([]byte, []int) {
+	return file_proto_tracking_v1_tracking_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Cancellation) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+func (x *Cancellation) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *Cancellation) GetCancelledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CancelledAt
+	}
+	return nil
+}
+
+type Track struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	BookingId       string                 `protobuf:"bytes,2,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	RunnerId        string                 `protobuf:"bytes,3,opt,name=runner_id,json=runnerId,proto3" json:"runner_id,omitempty"`
+	Region          string                 `protobuf:"bytes,4,opt,name=region,proto3" json:"region,omitempty"`
+	Status          string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	TotalDistanceKm float64                `protobuf:"fixed64,6,opt,name=total_distance_km,json=totalDistanceKm,proto3" json:"total_distance_km,omitempty"`
+	StartedAt       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	Cancellation    *Cancellation          `protobuf:"bytes,9,opt,name=cancellation,proto3" json:"cancellation,omitempty"`
+	Waypoints       []*Waypoint            `protobuf:"bytes,10,rep,name=waypoints,proto3" json:"waypoints,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Track) Reset() {
+	*x = Track{}
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Track) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Track) ProtoMessage() {}
+
+func (x *Track) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Track.ProtoReflect.Descriptor instead.
+func (*Track) Descriptor() ([]byte, []int) {
+	return file_proto_tracking_v1_tracking_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Track) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Track) GetBookingId() string {
+	if x != nil {
+		return x.BookingId
+	}
+	return ""
+}
+
+func (x *Track) GetRunnerId() string {
+	if x != nil {
+		return x.RunnerId
+	}
+	return ""
+}
+
+func (x *Track) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Track) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Track) GetTotalDistanceKm() float64 {
+	if x != nil {
+		return x.TotalDistanceKm
+	}
+	return 0
+}
+
+func (x *Track) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Track) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *Track) GetCancellation() *Cancellation {
+	if x != nil {
+		return x.Cancellation
+	}
+	return nil
+}
+
+func (x *Track) GetWaypoints() []*Waypoint {
+	if x != nil {
+		return x.Waypoints
+	}
+	return nil
+}
+
+type GetTrackingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookingId     string                 `protobuf:"bytes,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTrackingRequest) Reset() {
+	*x = GetTrackingRequest{}
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTrackingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrackingRequest) ProtoMessage() {}
+
+func (x *GetTrackingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrackingRequest.ProtoReflect.Descriptor instead.
+func (*GetTrackingRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tracking_v1_tracking_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetTrackingRequest) GetBookingId() string {
+	if x != nil {
+		return x.BookingId
+	}
+	return ""
+}
+
+type GetTrackingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Track         *Track                 `protobuf:"bytes,1,opt,name=track,proto3" json:"track,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTrackingResponse) Reset() {
+	*x = GetTrackingResponse{}
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTrackingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrackingResponse) ProtoMessage() {}
+
+func (x *GetTrackingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrackingResponse.ProtoReflect.Descriptor instead.
+func (*GetTrackingResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tracking_v1_tracking_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetTrackingResponse) GetTrack() *Track {
+	if x != nil {
+		return x.Track
+	}
+	return nil
+}
+
+type GetRouteRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	BookingId string                 `protobuf:"bytes,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	// Douglas-Peucker tolerance in degrees; 0 keeps every point unless max_points is set.
+	Tolerance float64 `protobuf:"fixed64,2,opt,name=tolerance,proto3" json:"tolerance,omitempty"`
+	// Maximum number of points to return; 0 means no cap.
+	MaxPoints     int32 `protobuf:"varint,3,opt,name=max_points,json=maxPoints,proto3" json:"max_points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRouteRequest) Reset() {
+	*x = GetRouteRequest{}
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRouteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRouteRequest) ProtoMessage() {}
+
+func (x *GetRouteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRouteRequest.ProtoReflect.Descriptor instead.
+func (*GetRouteRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tracking_v1_tracking_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetRouteRequest) GetBookingId() string {
+	if x != nil {
+		return x.BookingId
+	}
+	return ""
+}
+
+func (x *GetRouteRequest) GetTolerance() float64 {
+	if x != nil {
+		return x.Tolerance
+	}
+	return 0
+}
+
+func (x *GetRouteRequest) GetMaxPoints() int32 {
+	if x != nil {
+		return x.MaxPoints
+	}
+	return 0
+}
+
+type GetRouteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookingId     string                 `protobuf:"bytes,1,opt,name=booking_id,json=bookingId,proto3" json:"booking_id,omitempty"`
+	Geojson       string                 `protobuf:"bytes,2,opt,name=geojson,proto3" json:"geojson,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRouteResponse) Reset() {
+	*x = GetRouteResponse{}
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRouteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRouteResponse) ProtoMessage() {}
+
+func (x *GetRouteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRouteResponse.ProtoReflect.Descriptor instead.
+func (*GetRouteResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tracking_v1_tracking_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetRouteResponse) GetBookingId() string {
+	if x != nil {
+		return x.BookingId
+	}
+	return ""
+}
+
+func (x *GetRouteResponse) GetGeojson() string {
+	if x != nil {
+		return x.Geojson
+	}
+	return ""
+}
+
+type GetActiveTrackByRunnerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunnerId      string                 `protobuf:"bytes,1,opt,name=runner_id,json=runnerId,proto3" json:"runner_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetActiveTrackByRunnerRequest) Reset() {
+	*x = GetActiveTrackByRunnerRequest{}
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetActiveTrackByRunnerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetActiveTrackByRunnerRequest) ProtoMessage() {}
+
+func (x *GetActiveTrackByRunnerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetActiveTrackByRunnerRequest.ProtoReflect.Descriptor instead.
+func (*GetActiveTrackByRunnerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tracking_v1_tracking_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetActiveTrackByRunnerRequest) GetRunnerId() string {
+	if x != nil {
+		return x.RunnerId
+	}
+	return ""
+}
+
+type GetActiveTrackByRunnerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Track         *Track                 `protobuf:"bytes,1,opt,name=track,proto3" json:"track,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetActiveTrackByRunnerResponse) Reset() {
+	*x = GetActiveTrackByRunnerResponse{}
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetActiveTrackByRunnerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetActiveTrackByRunnerResponse) ProtoMessage() {}
+
+func (x *GetActiveTrackByRunnerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tracking_v1_tracking_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetActiveTrackByRunnerResponse.ProtoReflect.Descriptor instead.
+func (*GetActiveTrackByRunnerResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tracking_v1_tracking_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetActiveTrackByRunnerResponse) GetTrack() *Track {
+	if x != nil {
+		return x.Track
+	}
+	return nil
+}
+
+var File_proto_tracking_v1_tracking_proto protoreflect.FileDescriptor
+
+const file_proto_tracking_v1_tracking_proto_rawDesc = "" +
+	"\n" +
+	" proto/tracking/v1/tracking.proto\x12\vtracking.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd7\x01\n" +
+	"\bWaypoint\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\blatitude\x18\x02 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x03 \x01(\x01R\tlongitude\x12\x1b\n" +
+	"\tspeed_kmh\x18\x04 \x01(\x01R\bspeedKmh\x12'\n" +
+	"\x0fheading_degrees\x18\x05 \x01(\x01R\x0eheadingDegrees\x12;\n" +
+	"\vrecorded_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"recordedAt\"\x82\x01\n" +
+	"\fCancellation\x12\x1f\n" +
+	"\vreason_code\x18\x01 \x01(\tR\n" +
+	"reasonCode\x12\x12\n" +
+	"\x04note\x18\x02 \x01(\tR\x04note\x12=\n" +
+	"\fcancelled_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vcancelledAt\"\x9d\x03\n" +
+	"\x05Track\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x02 \x01(\tR\tbookingId\x12\x1b\n" +
+	"\trunner_id\x18\x03 \x01(\tR\brunnerId\x12\x16\n" +
+	"\x06region\x18\x04 \x01(\tR\x06region\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12*\n" +
+	"\x11total_distance_km\x18\x06 \x01(\x01R\x0ftotalDistanceKm\x129\n" +
+	"\n" +
+	"started_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12=\n" +
+	"\fcompleted_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12=\n" +
+	"\fcancellation\x18\t \x01(\v2\x19.tracking.v1.CancellationR\fcancellation\x123\n" +
+	"\twaypoints\x18\n" +
+	" \x03(\v2\x15.tracking.v1.WaypointR\twaypoints\"3\n" +
+	"\x12GetTrackingRequest\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\tR\tbookingId\"?\n" +
+	"\x13GetTrackingResponse\x12(\n" +
+	"\x05track\x18\x01 \x01(\v2\x12.tracking.v1.TrackR\x05track\"m\n" +
+	"\x0fGetRouteRequest\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\tR\tbookingId\x12\x1c\n" +
+	"\ttolerance\x18\x02 \x01(\x01R\ttolerance\x12\x1d\n" +
+	"\n" +
+	"max_points\x18\x03 \x01(\x05R\tmaxPoints\"K\n" +
+	"\x10GetRouteResponse\x12\x1d\n" +
+	"\n" +
+	"booking_id\x18\x01 \x01(\tR\tbookingId\x12\x18\n" +
+	"\ageojson\x18\x02 \x01(\tR\ageojson\"<\n" +
+	"\x1dGetActiveTrackByRunnerRequest\x12\x1b\n" +
+	"\trunner_id\x18\x01 \x01(\tR\brunnerId\"J\n" +
+	"\x1eGetActiveTrackByRunnerResponse\x12(\n" +
+	"\x05track\x18\x01 \x01(\v2\x12.tracking.v1.TrackR\x05track2\x9f\x02\n" +
+	"\x0fTrackingService\x12P\n" +
+	"\vGetTracking\x12\x1f.tracking.v1.GetTrackingRequest\x1a .tracking.v1.GetTrackingResponse\x12G\n" +
+	"\bGetRoute\x12\x1c.tracking.v1.GetRouteRequest\x1a\x1d.tracking.v1.GetRouteResponse\x12q\n" +
+	"\x16GetActiveTrackByRunner\x12*.tracking.v1.GetActiveTrackByRunnerRequest\x1a+.tracking.v1.GetActiveTrackByRunnerResponseBMZKgithub.com/Kilat-Pet-Delivery/service-tracking/proto/tracking/v1;trackingv1b\x06proto3"
+
+var (
+	file_proto_tracking_v1_tracking_proto_rawDescOnce sync.Once
+	file_proto_tracking_v1_tracking_proto_rawDescData []byte
+)
+
+func file_proto_tracking_v1_tracking_proto_rawDescGZIP() []byte {
+	file_proto_tracking_v1_tracking_proto_rawDescOnce.Do(func() {
+		file_proto_tracking_v1_tracking_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_tracking_v1_tracking_proto_rawDesc), len(file_proto_tracking_v1_tracking_proto_rawDesc)))
+	})
+	return file_proto_tracking_v1_tracking_proto_rawDescData
+}
+
+var file_proto_tracking_v1_tracking_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_proto_tracking_v1_tracking_proto_goTypes = []any{
+	(*Waypoint)(nil),                       // 0: tracking.v1.Waypoint
+	(*Cancellation)(nil),                   // 1: tracking.v1.Cancellation
+	(*Track)(nil),                          // 2: tracking.v1.Track
+	(*GetTrackingRequest)(nil),             // 3: tracking.v1.GetTrackingRequest
+	(*GetTrackingResponse)(nil),            // 4: tracking.v1.GetTrackingResponse
+	(*GetRouteRequest)(nil),                // 5: tracking.v1.GetRouteRequest
+	(*GetRouteResponse)(nil),               // 6: tracking.v1.GetRouteResponse
+	(*GetActiveTrackByRunnerRequest)(nil),  // 7: tracking.v1.GetActiveTrackByRunnerRequest
+	(*GetActiveTrackByRunnerResponse)(nil), // 8: tracking.v1.GetActiveTrackByRunnerResponse
+	(*timestamppb.Timestamp)(nil),          // 9: google.protobuf.Timestamp
+}
+var file_proto_tracking_v1_tracking_proto_depIdxs = []int32{
+	9,  // 0: tracking.v1.Waypoint.recorded_at:type_name -> google.protobuf.Timestamp
+	9,  // 1: tracking.v1.Cancellation.cancelled_at:type_name -> google.protobuf.Timestamp
+	9,  // 2: tracking.v1.Track.started_at:type_name -> google.protobuf.Timestamp
+	9,  // 3: tracking.v1.Track.completed_at:type_name -> google.protobuf.Timestamp
+	1,  // 4: tracking.v1.Track.cancellation:type_name -> tracking.v1.Cancellation
+	0,  // 5: tracking.v1.Track.waypoints:type_name -> tracking.v1.Waypoint
+	2,  // 6: tracking.v1.GetTrackingResponse.track:type_name -> tracking.v1.Track
+	2,  // 7: tracking.v1.GetActiveTrackByRunnerResponse.track:type_name -> tracking.v1.Track
+	3,  // 8: tracking.v1.TrackingService.GetTracking:input_type -> tracking.v1.GetTrackingRequest
+	5,  // 9: tracking.v1.TrackingService.GetRoute:input_type -> tracking.v1.GetRouteRequest
+	7,  // 10: tracking.v1.TrackingService.GetActiveTrackByRunner:input_type -> tracking.v1.GetActiveTrackByRunnerRequest
+	4,  // 11: tracking.v1.TrackingService.GetTracking:output_type -> tracking.v1.GetTrackingResponse
+	6,  // 12: tracking.v1.TrackingService.GetRoute:output_type -> tracking.v1.GetRouteResponse
+	8,  // 13: tracking.v1.TrackingService.GetActiveTrackByRunner:output_type -> tracking.v1.GetActiveTrackByRunnerResponse
+	11, // [11:14] is the sub-list for method output_type
+	8,  // [8:11] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_proto_tracking_v1_tracking_proto_init() }
+func file_proto_tracking_v1_tracking_proto_init() {
+	if File_proto_tracking_v1_tracking_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_tracking_v1_tracking_proto_rawDesc), len(file_proto_tracking_v1_tracking_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_tracking_v1_tracking_proto_goTypes,
+		DependencyIndexes: file_proto_tracking_v1_tracking_proto_depIdxs,
+		MessageInfos:      file_proto_tracking_v1_tracking_proto_msgTypes,
+	}.Build()
+	File_proto_tracking_v1_tracking_proto = out.File
+	file_proto_tracking_v1_tracking_proto_goTypes = nil
+	file_proto_tracking_v1_tracking_proto_depIdxs = nil
+}